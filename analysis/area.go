@@ -0,0 +1,133 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// Area is an estimate of the area of the set within a render's view.
+type Area struct {
+	Area     float64 // estimated area in the complex plane
+	Err      float64 // half-width of the 95% confidence interval
+	Boundary int     // pixels on the edge of the interior mask
+	Samples  int     // samples taken in each boundary pixel
+}
+
+// EstimateArea estimates the area of the set within the view of res from
+// its interior mask. Pixels whose 8 neighbours agree with them count as
+// wholly inside or outside; the pixels along the edge of the mask, where
+// the boundary runs, are sampled again on a refine×refine grid and count
+// for the fraction of their samples that stay bounded. With refine 0 they
+// keep the verdict of their center sample.
+//
+// The confidence interval treats each boundary sample as a Bernoulli
+// trial at the pooled inside fraction of all boundary samples, so it
+// shrinks as refine grows. It covers the sampling error only: details
+// thinner than a pixel that no sample lands on, and slowly escaping
+// points that res.Options.MaxIter counts as inside, bias the estimate
+// beyond it.
+func EstimateArea(ctx context.Context, res *render.Result, refine int) (Area, error) {
+	opts := res.Options
+	w, h := opts.Width, opts.Height
+	if len(res.Inside) != w*h {
+		return Area{}, errors.New("area: the render kept no interior mask")
+	}
+	inside := res.Inside
+	var edge []int // indices of the boundary pixels
+	solid := 0
+	for y := range h {
+		for x := range w {
+			i := y*w + x
+			mixed := false
+			for dy := -1; dy <= 1 && !mixed; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx >= 0 && nx < w && ny >= 0 && ny < h && inside[ny*w+nx] != inside[i] {
+						mixed = true
+						break
+					}
+				}
+			}
+			switch {
+			case mixed:
+				edge = append(edge, i)
+			case inside[i]:
+				solid++
+			}
+		}
+	}
+
+	samples := max(refine*refine, 1)
+	hits := make([]int, len(edge))
+	if refine <= 0 {
+		for k, i := range edge {
+			if inside[i] {
+				hits[k] = 1
+			}
+		}
+	} else if err := sampleEdge(ctx, opts, edge, refine, hits); err != nil {
+		return Area{}, err
+	}
+	total := 0
+	for _, n := range hits {
+		total += n
+	}
+
+	pixelArea := opts.Bounds.Width() * opts.Bounds.Height() / float64(w*h)
+	a := Area{
+		Area:     pixelArea * (float64(solid) + float64(total)/float64(samples)),
+		Boundary: len(edge),
+		Samples:  samples,
+	}
+	if n := len(edge) * samples; n > 0 {
+		p := float64(total) / float64(n)
+		// each boundary pixel contributes its hit fraction, with variance
+		// p(1-p)/samples
+		a.Err = 1.96 * pixelArea * math.Sqrt(float64(len(edge))*p*(1-p)/float64(samples))
+	}
+	return a, nil
+}
+
+// sampleEdge counts, for each pixel index in edge, how many of the
+// centers of a refine×refine grid over the pixel stay bounded, spreading
+// the pixels over the CPUs.
+func sampleEdge(ctx context.Context, opts render.Options, edge []int, refine int, hits []int) error {
+	vp := opts.Viewport()
+	f := opts.Fractal
+	if f == nil {
+		f = fractal.Mandelbrot{}
+	}
+	bailout := opts.Bailout
+	if bailout == 0 {
+		bailout = render.DefaultBailout
+	}
+	bailoutSq := bailout * bailout
+	workers := min(runtime.NumCPU(), max(len(edge), 1))
+	var wg sync.WaitGroup
+	for wk := range workers {
+		wg.Go(func() {
+			for k := wk; k < len(edge); k += workers {
+				if ctx.Err() != nil {
+					return
+				}
+				x, y := edge[k]%opts.Width, edge[k]/opts.Width
+				for sy := range refine {
+					for sx := range refine {
+						c := vp.PointToComplex(float64(x)+(float64(sx)+0.5)/float64(refine), float64(y)+(float64(sy)+0.5)/float64(refine))
+						if n, _ := fractal.Iterate(f, c, opts.MaxIter, bailoutSq); n >= opts.MaxIter {
+							hits[k]++
+						}
+					}
+				}
+			}
+		})
+	}
+	wg.Wait()
+	return ctx.Err()
+}
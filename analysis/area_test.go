@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// knownArea is the area of the Mandelbrot set, 1.50659 to five places.
+const knownArea = 1.50659
+
+// areaRender renders the whole set square at size×size pixels.
+func areaRender(t *testing.T, size, iters int) *render.Result {
+	t.Helper()
+	opts, err := render.New(
+		render.WithSize(size, size),
+		render.WithViewport(coords.Bounds{Xmin: -2.1, Xmax: 0.7, Ymin: -1.4, Ymax: 1.4}),
+		render.WithIterations(iters),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestEstimateArea(t *testing.T) {
+	res := areaRender(t, 500, 2000)
+	var last float64
+	for _, refine := range []int{0, 2, 4} {
+		a, err := EstimateArea(context.Background(), res, refine)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// slow escapes counted as inside at 2000 iterations bias the
+		// estimate up by about 0.0015, beyond the sampling error
+		if math.Abs(a.Area-knownArea) > 0.005 {
+			t.Errorf("refine %d: area %v, want about %v", refine, a.Area, knownArea)
+		}
+		if a.Boundary == 0 || a.Samples != max(refine*refine, 1) {
+			t.Errorf("refine %d: %d boundary pixels, %d samples each", refine, a.Boundary, a.Samples)
+		}
+		if refine > 0 && !(a.Err < last) {
+			t.Errorf("refine %d: interval ±%v, not narrower than ±%v", refine, a.Err, last)
+		}
+		last = a.Err
+	}
+}
+
+func TestEstimateAreaNoMask(t *testing.T) {
+	opts, err := render.New(render.WithSize(32, 24), render.WithIterations(100), render.WithDiscardBuffers(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := EstimateArea(context.Background(), res, 2); err == nil {
+		t.Error("no error without an interior mask")
+	}
+}
@@ -0,0 +1,134 @@
+// Package analysis inspects rendered images.
+package analysis
+
+import "image"
+
+// UnionFind is a disjoint-set forest over the integers 0..n-1 with path
+// compression and union by size.
+type UnionFind struct {
+	parent []int
+	size   []int
+}
+
+// NewUnionFind returns n singleton sets.
+func NewUnionFind(n int) *UnionFind {
+	uf := &UnionFind{parent: make([]int, n), size: make([]int, n)}
+	for i := range uf.parent {
+		uf.parent[i] = i
+		uf.size[i] = 1
+	}
+	return uf
+}
+
+// Find returns the representative of i's set.
+func (uf *UnionFind) Find(i int) int {
+	root := i
+	for uf.parent[root] != root {
+		root = uf.parent[root]
+	}
+	for uf.parent[i] != root {
+		uf.parent[i], i = root, uf.parent[i]
+	}
+	return root
+}
+
+// Union merges the sets holding a and b.
+func (uf *UnionFind) Union(a, b int) {
+	ra, rb := uf.Find(a), uf.Find(b)
+	if ra == rb {
+		return
+	}
+	if uf.size[ra] < uf.size[rb] {
+		ra, rb = rb, ra
+	}
+	uf.parent[rb] = ra
+	uf.size[ra] += uf.size[rb]
+}
+
+// Size returns the number of elements in i's set.
+func (uf *UnionFind) Size(i int) int { return uf.size[uf.Find(i)] }
+
+// LabelComponents labels the 8-connected components of the dark pixels
+// of img, those whose luminance is below threshold. The result is indexed
+// [y][x] relative to img.Bounds().Min; dark pixels get a component number
+// counting from 0 in scan order and all others -1.
+func LabelComponents(img *image.RGBA, threshold uint8) [][]int {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dark := make([]bool, w*h)
+	for y := range h {
+		for x := range w {
+			c := img.RGBAAt(b.Min.X+x, b.Min.Y+y)
+			dark[y*w+x] = luminance(c.R, c.G, c.B) < threshold
+		}
+	}
+
+	uf := NewUnionFind(w * h)
+	for y := range h {
+		for x := range w {
+			i := y*w + x
+			if !dark[i] {
+				continue
+			}
+			// join with the already visited neighbours: W, NW, N, NE
+			if x > 0 && dark[i-1] {
+				uf.Union(i, i-1)
+			}
+			if y > 0 {
+				if x > 0 && dark[i-w-1] {
+					uf.Union(i, i-w-1)
+				}
+				if dark[i-w] {
+					uf.Union(i, i-w)
+				}
+				if x < w-1 && dark[i-w+1] {
+					uf.Union(i, i-w+1)
+				}
+			}
+		}
+	}
+
+	labels := make([][]int, h)
+	ids := make(map[int]int)
+	for y := range h {
+		labels[y] = make([]int, w)
+		for x := range w {
+			i := y*w + x
+			if !dark[i] {
+				labels[y][x] = -1
+				continue
+			}
+			root := uf.Find(i)
+			id, ok := ids[root]
+			if !ok {
+				id = len(ids)
+				ids[root] = id
+			}
+			labels[y][x] = id
+		}
+	}
+	return labels
+}
+
+// ComponentSizes returns the pixel count of each component in labels as
+// produced by LabelComponents, indexed by component number.
+func ComponentSizes(labels [][]int) []int {
+	var sizes []int
+	for _, row := range labels {
+		for _, id := range row {
+			if id < 0 {
+				continue
+			}
+			for id >= len(sizes) {
+				sizes = append(sizes, 0)
+			}
+			sizes[id]++
+		}
+	}
+	return sizes
+}
+
+// luminance is the Rec. 601 luma of an sRGB color.
+func luminance(r, g, b uint8) uint8 {
+	return uint8((299*int(r) + 587*int(g) + 114*int(b)) / 1000)
+}
@@ -0,0 +1,74 @@
+package analysis
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func TestUnionFind(t *testing.T) {
+	uf := NewUnionFind(6)
+	uf.Union(0, 1)
+	uf.Union(2, 3)
+	uf.Union(1, 3)
+	for _, i := range []int{1, 2, 3} {
+		if uf.Find(i) != uf.Find(0) {
+			t.Errorf("%d not joined to 0", i)
+		}
+	}
+	if uf.Find(4) == uf.Find(0) || uf.Find(4) == uf.Find(5) {
+		t.Error("4 joined to something")
+	}
+	if uf.Size(2) != 4 || uf.Size(5) != 1 {
+		t.Errorf("sizes %d and %d, want 4 and 1", uf.Size(2), uf.Size(5))
+	}
+}
+
+func TestLabelComponentsDiagonal(t *testing.T) {
+	// two dark pixels touching at a corner are one component; a third
+	// apart from them is another
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for _, p := range []image.Point{{0, 0}, {1, 1}, {3, 2}} {
+		img.SetRGBA(p.X, p.Y, color.RGBA{0, 0, 0, 0xff})
+	}
+	labels := LabelComponents(img, 128)
+	if labels[0][0] != 0 || labels[1][1] != 0 || labels[2][3] != 1 || labels[0][1] != -1 {
+		t.Errorf("labels %v", labels)
+	}
+	if sizes := ComponentSizes(labels); !slices.Equal(sizes, []int{2, 1}) {
+		t.Errorf("sizes %v, want [2 1]", sizes)
+	}
+}
+
+func TestLabelComponentsDefaultRender(t *testing.T) {
+	opts, err := render.New(render.WithPaletteName("MonochromeSlate"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// MonochromeSlate starts at black, so only the interior is below 1
+	sizes := ComponentSizes(LabelComponents(res.Image, 1))
+	slices.Sort(sizes)
+	slices.Reverse(sizes)
+	if len(sizes) < 10 {
+		t.Fatalf("%d components, want the interior and many specks along the boundary", len(sizes))
+	}
+	// the set is connected, but its filaments are thinner than a pixel,
+	// so minibrots and bulb tips come out as specks of their own
+	if sizes[0] < res.Stats.InsidePixels*99/100 {
+		t.Errorf("largest component %d pixels of %d inside", sizes[0], res.Stats.InsidePixels)
+	}
+	if sizes[1] > sizes[0]/1000 {
+		t.Errorf("second component %d pixels, beside %d", sizes[1], sizes[0])
+	}
+}
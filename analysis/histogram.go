@@ -0,0 +1,55 @@
+package analysis
+
+import (
+	"errors"
+	"math"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// Histogram is the distribution of the escape counts of a render.
+type Histogram struct {
+	// Counts holds the escaped pixels in len(Counts) equal buckets over
+	// [0, MaxIter), by their continuous escape count.
+	Counts  []int
+	MaxIter int
+
+	// Interior is the pixels that never escaped, kept out of Counts;
+	// Stats is the render's summary, where it comes from.
+	Interior int
+	Stats    render.Stats
+}
+
+// BucketWidth returns the iterations each bucket of h covers.
+func (h Histogram) BucketWidth() float64 {
+	return float64(h.MaxIter) / float64(len(h.Counts))
+}
+
+// EscapeHistogram buckets the escape counts of res into at most buckets
+// buckets, fewer when the iteration limit is lower, so no bucket is
+// narrower than one iteration. The interior and the summary figures are
+// taken from res.Stats.
+func EscapeHistogram(res *render.Result, buckets int) (Histogram, error) {
+	opts := res.Options
+	if len(res.Iters) != opts.Width*opts.Height {
+		return Histogram{}, errors.New("histogram: the render kept no iteration buffer")
+	}
+	if buckets < 1 {
+		return Histogram{}, errors.New("histogram: need at least one bucket")
+	}
+	h := Histogram{
+		Counts:   make([]int, min(buckets, opts.MaxIter)),
+		MaxIter:  opts.MaxIter,
+		Interior: res.Stats.InsidePixels,
+		Stats:    res.Stats,
+	}
+	scale := float64(len(h.Counts)) / float64(opts.MaxIter)
+	for i, nu := range res.Iters {
+		if res.Inside[i] {
+			continue
+		}
+		b := int(math.Floor(nu * scale))
+		h.Counts[min(max(b, 0), len(h.Counts)-1)]++
+	}
+	return h, nil
+}
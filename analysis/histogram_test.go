@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// bimodalResult returns a 100×100 result with a 500 iteration limit whose
+// escape counts gather around 100 and 350, half each, with 400 interior
+// pixels.
+func bimodalResult() *render.Result {
+	const w, h, maxIter, interior = 100, 100, 500, 400
+	rng := rand.New(rand.NewPCG(1, 2))
+	res := &render.Result{
+		Options: render.Options{Width: w, Height: h, MaxIter: maxIter},
+		Iters:   make([]float64, w*h),
+		Inside:  make([]bool, w*h),
+	}
+	s := &res.Stats
+	s.Pixels, s.InsidePixels = w*h, interior
+	s.InsideFraction = float64(interior) / (w * h)
+	s.MinIter, s.MaxIter = maxIter, 0
+	for i := range res.Iters {
+		if i < interior {
+			res.Inside[i], res.Iters[i] = true, maxIter
+			continue
+		}
+		center := 100.0
+		if i%2 == 1 {
+			center = 350
+		}
+		nu := min(max(center+15*rng.NormFloat64(), 0), maxIter-1)
+		res.Iters[i] = nu
+		s.MinIter, s.MaxIter = min(s.MinIter, int(nu)), max(s.MaxIter, int(nu))
+	}
+	return res
+}
+
+// peaks returns the runs of consecutive values of v at least frac of
+// the largest, as [first, last] index pairs.
+func peaks(v []float64, frac float64) [][2]int {
+	top := 0.0
+	for _, x := range v {
+		top = max(top, x)
+	}
+	var runs [][2]int
+	for i, x := range v {
+		if x < frac*top {
+			continue
+		}
+		if n := len(runs); n > 0 && runs[n-1][1] == i-1 {
+			runs[n-1][1] = i
+		} else {
+			runs = append(runs, [2]int{i, i})
+		}
+	}
+	return runs
+}
+
+func TestEscapeHistogramBimodal(t *testing.T) {
+	res := bimodalResult()
+	h, err := EscapeHistogram(res, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Counts) != 50 || h.BucketWidth() != 10 || h.Interior != 400 {
+		t.Fatalf("%d buckets of %v, interior %d", len(h.Counts), h.BucketWidth(), h.Interior)
+	}
+	total := 0
+	counts := make([]float64, len(h.Counts))
+	for i, c := range h.Counts {
+		total += c
+		counts[i] = float64(c)
+	}
+	if total != 9600 {
+		t.Errorf("%d escaped pixels bucketed, want 9600", total)
+	}
+	// two peaks, over the buckets of 100 and 350
+	runs := peaks(counts, 0.5)
+	if len(runs) != 2 || runs[0][0] > 10 || runs[0][1] < 10 || runs[1][0] > 35 || runs[1][1] < 35 {
+		t.Errorf("peaks at buckets %v of %v", runs, h.Counts)
+	}
+}
+
+func TestEscapeHistogramBuckets(t *testing.T) {
+	res := bimodalResult()
+	// no bucket narrower than an iteration
+	res.Options.MaxIter = 20
+	for i := range res.Iters {
+		res.Iters[i] = math.Mod(res.Iters[i], 20)
+	}
+	h, err := EscapeHistogram(res, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Counts) != 20 || h.BucketWidth() != 1 {
+		t.Errorf("%d buckets of %v", len(h.Counts), h.BucketWidth())
+	}
+
+	if _, err := EscapeHistogram(res, 0); err == nil {
+		t.Error("no error for 0 buckets")
+	}
+	res.Iters, res.Inside = nil, nil
+	if _, err := EscapeHistogram(res, 10); err == nil {
+		t.Error("no error without an iteration buffer")
+	}
+}
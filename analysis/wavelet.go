@@ -0,0 +1,91 @@
+package analysis
+
+import "image"
+
+// WaveletQuality measures how much fine detail img holds, from 0 for a
+// flat image towards 1 for noise: one level of the 2D Haar transform
+// splits its luma into a half-size average (LL) and the horizontal,
+// vertical and diagonal differences (LH, HL, HH), and the result is the
+// share of the energy in the differences. Energy is taken about the
+// mean luma, so the overall brightness doesn't dilute the detail. A
+// render that comes out low, mostly bands of smooth color, may gain
+// detail from more iterations.
+//
+// A last row or column left over from an odd size is ignored; an image
+// too small to hold a 2×2 block, or of a single color, gives 0.
+func WaveletQuality(img *image.RGBA) float64 {
+	b := img.Bounds()
+	w, h := b.Dx()&^1, b.Dy()&^1
+	if w == 0 || h == 0 {
+		return 0
+	}
+	data := make([][]float64, h)
+	var mean float64
+	for y := range h {
+		data[y] = make([]float64, w)
+		for x := range w {
+			c := img.RGBAAt(b.Min.X+x, b.Min.Y+y)
+			v := (299*float64(c.R) + 587*float64(c.G) + 114*float64(c.B)) / (1000 * 255)
+			data[y][x] = v
+			mean += v
+		}
+	}
+	mean /= float64(w * h)
+	for _, row := range data {
+		for x := range row {
+			row[x] -= mean
+		}
+	}
+	ll, lh, hl, hh := haar2D(data)
+	low := energy(ll)
+	high := energy(lh) + energy(hl) + energy(hh)
+	if high+low == 0 {
+		return 0
+	}
+	return high / (high + low)
+}
+
+// haar2D applies one level of the orthonormal 2D Haar transform to data,
+// rows of equal, even length and an even number of them. Each 2×2 block
+// a b / c d gives one coefficient in each band: LL (a+b+c+d)/2, LH
+// (a+b-c-d)/2 for the change down the block, HL (a-b+c-d)/2 for the
+// change across it, and HH (a-b-c+d)/2. The bands are half the size of
+// data each way, and their energies add up to that of data.
+func haar2D(data [][]float64) (ll, lh, hl, hh [][]float64) {
+	h := len(data) / 2
+	w := 0
+	if h > 0 {
+		w = len(data[0]) / 2
+	}
+	band := func() [][]float64 {
+		m := make([][]float64, h)
+		for j := range m {
+			m[j] = make([]float64, w)
+		}
+		return m
+	}
+	ll, lh, hl, hh = band(), band(), band(), band()
+	for j := range h {
+		top, bottom := data[2*j], data[2*j+1]
+		for i := range w {
+			a, b := top[2*i], top[2*i+1]
+			c, d := bottom[2*i], bottom[2*i+1]
+			ll[j][i] = (a + b + c + d) / 2
+			lh[j][i] = (a + b - c - d) / 2
+			hl[j][i] = (a - b + c - d) / 2
+			hh[j][i] = (a - b - c + d) / 2
+		}
+	}
+	return ll, lh, hl, hh
+}
+
+// energy returns the sum of the squares of m.
+func energy(m [][]float64) float64 {
+	var e float64
+	for _, row := range m {
+		for _, v := range row {
+			e += v * v
+		}
+	}
+	return e
+}
@@ -0,0 +1,51 @@
+package analysis
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func TestWaveletQualityUniform(t *testing.T) {
+	for _, c := range []color.RGBA{{}, {0x80, 0x40, 0x20, 0xff}, {0xff, 0xff, 0xff, 0xff}} {
+		img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+		draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+		if q := WaveletQuality(img); math.Abs(q) > 1e-12 {
+			t.Errorf("uniform %v: quality %v, want 0", c, q)
+		}
+	}
+}
+
+func TestWaveletQualityDefaultRender(t *testing.T) {
+	opts, err := render.New(render.WithSize(400, 300))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := WaveletQuality(res.Image); q <= 0.1 {
+		t.Errorf("default render: quality %v, want above 0.1", q)
+	}
+}
+
+func TestHaar2DKeepsEnergy(t *testing.T) {
+	data := make([][]float64, 6)
+	for y := range data {
+		data[y] = make([]float64, 8)
+		for x := range data[y] {
+			data[y][x] = math.Sin(float64(3*x+7*y)) + float64(x*y)/10
+		}
+	}
+	ll, lh, hl, hh := haar2D(data)
+	got, want := energy(ll)+energy(lh)+energy(hl)+energy(hh), energy(data)
+	if math.Abs(got-want) > 1e-9*want {
+		t.Errorf("bands hold %v, data %v", got, want)
+	}
+}
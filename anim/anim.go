@@ -0,0 +1,200 @@
+// Package anim computes the camera path of zoom animations.
+package anim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+// A View is a camera position: the point of the plane at the center of
+// the image, the width of the plane the image spans and how far the
+// camera is turned. Rotation may run past a full turn, so a path between
+// two views can spin several times.
+type View struct {
+	Center   complex128
+	Width    float64
+	Rotation float64 // degrees counter-clockwise about Center
+}
+
+// ViewOf returns the view showing b.
+func ViewOf(b coords.Bounds) View {
+	return View{Center: b.Center(), Width: b.Width()}
+}
+
+// Bounds returns the window showing v in a width×height image, with
+// square pixels.
+func (v View) Bounds(width, height int) coords.Bounds {
+	w := v.Width
+	h := w * float64(height) / float64(width)
+	return coords.Bounds{
+		Xmin: real(v.Center) - w/2, Xmax: real(v.Center) + w/2,
+		Ymin: imag(v.Center) - h/2, Ymax: imag(v.Center) + h/2,
+	}
+}
+
+// Viewport returns the viewport showing v in a width×height image.
+func (v View) Viewport(width, height int) coords.Viewport {
+	return coords.Viewport{
+		Bounds:   v.Bounds(width, height),
+		Rotation: math.Mod(v.Rotation, 360),
+		Width:    width,
+		Height:   height,
+	}
+}
+
+// scale returns the view's width and rotation as one complex factor.
+func (v View) scale() complex128 {
+	return cmplx.Rect(v.Width, v.Rotation*math.Pi/180)
+}
+
+// Zoom moves the camera from From to To. The width changes by the same
+// factor and the rotation by the same angle every step, so the motion
+// looks equally fast throughout, and the center moves so that the point
+// both views turn and zoom around (Target) stays put on screen: zooming
+// while turning gives a spiral dive, and turning alone an orbit around
+// that point. Ease reshapes the timing, for instance to start and stop
+// gently; nil keeps the constant rate.
+type Zoom struct {
+	From, To View
+	Ease     Easing
+}
+
+// At returns the view at t, which runs from 0 (From) to 1 (To).
+func (z Zoom) At(t float64) View {
+	if z.Ease != nil {
+		t = z.Ease(t)
+	}
+	// Width and rotation combine into the complex scale r = w·e^(iθ),
+	// and log(r) moves linearly: r(t) = r0·q^t for q = r1/r0. Every view
+	// on the way is From turned and scaled about the fixed point p (see
+	// Target), which puts the center at From.Center +
+	// (To.Center-From.Center)·f with f = (1-q^t)/(1-q). expm1 keeps f
+	// accurate when q is close to 1, where it tends to t: a plain pan.
+	l := complex(math.Log(z.To.Width/z.From.Width), (z.To.Rotation-z.From.Rotation)*math.Pi/180)
+	f := complex(t, 0)
+	if l != 0 {
+		f = expm1(complex(t, 0)*l) / expm1(l)
+	}
+	return View{
+		Center:   z.From.Center + (z.To.Center-z.From.Center)*f,
+		Width:    z.From.Width * math.Exp(t*real(l)),
+		Rotation: z.From.Rotation + t*(z.To.Rotation-z.From.Rotation),
+	}
+}
+
+// expm1 returns e^z - 1 without the cancellation of computing it
+// directly for z near 0.
+func expm1(z complex128) complex128 {
+	x, y := real(z), imag(z)
+	s, c := math.Sincos(y)
+	h := math.Sin(y / 2)
+	// e^x·cos y - 1 = (e^x - 1)·cos y + (cos y - 1)
+	return complex(math.Expm1(x)*c-2*h*h, math.Exp(x)*s)
+}
+
+// Target returns the point that stays at the same place on screen for the
+// whole zoom, (To.Center - q·From.Center)/(1-q) for the scale ratio q of
+// the two views. A pan without zoom or rotation has none and reports
+// false.
+func (z Zoom) Target() (complex128, bool) {
+	q := z.To.scale() / z.From.scale()
+	if q == 1 {
+		return 0, false
+	}
+	return (z.To.Center - q*z.From.Center) / (1 - q), true
+}
+
+// Depth returns how many times the width has halved between From and v;
+// it is negative for a view wider than From.
+func (z Zoom) Depth(v View) float64 {
+	return math.Log2(z.From.Width / v.Width)
+}
+
+// A Keyframe pins the camera to View at frame Frame. Ease shapes the zoom
+// from this keyframe to the next one.
+type Keyframe struct {
+	Frame int
+	View  View
+	Ease  Easing
+}
+
+// A Track is a camera path through keyframes, in increasing frame order.
+// Between two keyframes the camera follows their Zoom.
+type Track []Keyframe
+
+// Validate checks that t has keyframes, in strictly increasing frame
+// order, with positive finite widths and finite rotations.
+func (t Track) Validate() error {
+	if len(t) == 0 {
+		return errors.New("anim: track has no keyframes")
+	}
+	for i, k := range t {
+		if !(k.View.Width > 0) || math.IsInf(k.View.Width, 0) {
+			return fmt.Errorf("anim: keyframe %d: width %g must be positive", i, k.View.Width)
+		}
+		if math.IsNaN(k.View.Rotation) || math.IsInf(k.View.Rotation, 0) {
+			return fmt.Errorf("anim: keyframe %d: rotation %g must be finite", i, k.View.Rotation)
+		}
+		if i > 0 && k.Frame <= t[i-1].Frame {
+			return fmt.Errorf("anim: keyframe %d: frame %d does not come after frame %d", i, k.Frame, t[i-1].Frame)
+		}
+	}
+	return nil
+}
+
+// At returns the view at frame, which may fall between whole frames.
+// Before the first keyframe and after the last the camera holds still.
+func (t Track) At(frame float64) View {
+	i, u := t.Locate(frame)
+	if i == len(t)-1 {
+		return t[i].View
+	}
+	return Zoom{From: t[i].View, To: t[i+1].View}.At(u)
+}
+
+// Locate returns the segment frame falls in, as the index i of the
+// keyframe that starts it, and how far along it is, with the segment's
+// easing already applied. Other parameters animated alongside the camera
+// follow the same timing with Lerp(a, b, u). Frames before the first
+// keyframe give (0, 0) and frames from the last one on (len(t)-1, 0).
+func (t Track) Locate(frame float64) (i int, u float64) {
+	if frame <= float64(t[0].Frame) {
+		return 0, 0
+	}
+	for i := 1; i < len(t); i++ {
+		a, b := t[i-1], t[i]
+		if frame < float64(b.Frame) {
+			u := (frame - float64(a.Frame)) / float64(b.Frame-a.Frame)
+			if a.Ease != nil {
+				u = a.Ease(u)
+			}
+			return i - 1, u
+		}
+	}
+	return len(t) - 1, 0
+}
+
+// Depth returns how many times the width has halved between the first
+// keyframe and v.
+func (t Track) Depth(v View) float64 {
+	return math.Log2(t[0].View.Width / v.Width)
+}
+
+// Lerp interpolates a number linearly, for parameters such as an
+// iteration count that change along with the camera.
+func Lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// Frame returns t for frame i of an n-frame sequence: the first frame is
+// From and the last is To.
+func Frame(i, n int) float64 {
+	if n <= 1 {
+		return 0
+	}
+	return float64(i) / float64(n-1)
+}
@@ -0,0 +1,157 @@
+package anim
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// screen returns where p appears in v, in widths from the center along
+// the turned axes of the image.
+func screen(v View, p complex128) complex128 {
+	return (p - v.Center) / v.scale()
+}
+
+var testZooms = []Zoom{
+	{From: View{Center: -0.5, Width: 3}, To: View{Center: -0.7436 + 0.1318i, Width: 3e-6}},
+	{From: View{Center: -0.5, Width: 3}, To: View{Center: -0.75 + 0.1i, Width: 0.01, Rotation: 270}},
+	// a turn in place, and a zoom out
+	{From: View{Center: 0.3i, Width: 1}, To: View{Center: 0.3i, Width: 1, Rotation: -90}},
+	{From: View{Center: 1 + 1i, Width: 0.001}, To: View{Center: 0, Width: 4}},
+	// a zoom so slight the ratio is nearly 1, with its target far off screen
+	{From: View{Center: 0, Width: 1}, To: View{Center: 1e-3, Width: 1 - 1e-12}},
+}
+
+func TestZoomTargetStaysPut(t *testing.T) {
+	for i, z := range testZooms {
+		p, ok := z.Target()
+		if !ok {
+			t.Fatalf("zoom %d: no target", i)
+		}
+		for _, ease := range []Easing{nil, Linear, Smoothstep, Cubic} {
+			z.Ease = ease
+			want := screen(z.From, p)
+			for f := range 101 {
+				if got := screen(z.At(Frame(f, 101)), p); cmplx.Abs(got-want) > 1e-9*max(1, cmplx.Abs(want)) {
+					t.Fatalf("zoom %d, frame %d: the target is at %v on screen, was at %v", i, f, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestZoomEndpoints(t *testing.T) {
+	near := func(a, b View) bool {
+		return cmplx.Abs(a.Center-b.Center) <= 1e-12*b.Width+1e-15 &&
+			math.Abs(a.Width-b.Width) <= 1e-12*b.Width &&
+			math.Abs(a.Rotation-b.Rotation) <= 1e-9
+	}
+	for i, z := range testZooms {
+		for _, ease := range []Easing{nil, Smoothstep, Cubic} {
+			z.Ease = ease
+			if got := z.At(0); !near(got, z.From) {
+				t.Errorf("zoom %d: At(0) = %+v, want %+v", i, got, z.From)
+			}
+			if got := z.At(1); !near(got, z.To) {
+				t.Errorf("zoom %d: At(1) = %+v, want %+v", i, got, z.To)
+			}
+		}
+	}
+}
+
+func TestZoomLogWidthLinear(t *testing.T) {
+	z := testZooms[0]
+	step := math.Log(z.To.Width/z.From.Width) / 10
+	for f := range 10 {
+		a, b := z.At(Frame(f, 11)), z.At(Frame(f+1, 11))
+		if got := math.Log(b.Width / a.Width); math.Abs(got-step) > 1e-12 {
+			t.Errorf("frame %d: log width changes by %v, want %v", f, got, step)
+		}
+		if d := z.Depth(b) - z.Depth(a); math.Abs(d+step/math.Ln2) > 1e-12 {
+			t.Errorf("frame %d: depth changes by %v", f, d)
+		}
+	}
+}
+
+func TestZoomPan(t *testing.T) {
+	z := Zoom{From: View{Center: 0, Width: 2}, To: View{Center: 1 + 1i, Width: 2}}
+	if _, ok := z.Target(); ok {
+		t.Error("a pan has a target")
+	}
+	if got := z.At(0.25); got.Center != 0.25+0.25i || got.Width != 2 {
+		t.Errorf("At(0.25) = %+v, want a quarter of the way at width 2", got)
+	}
+}
+
+func TestEasings(t *testing.T) {
+	for _, name := range EasingNames {
+		ease, ok := EasingByName(name)
+		if !ok {
+			t.Fatalf("%s: unknown", name)
+		}
+		if ease(0) != 0 || ease(1) != 1 {
+			t.Errorf("%s: %v at 0 and %v at 1", name, ease(0), ease(1))
+		}
+		last := 0.0
+		for i := range 1001 {
+			v := ease(float64(i) / 1000)
+			if v < last {
+				t.Fatalf("%s: falls from %v to %v at %v", name, last, v, float64(i)/1000)
+			}
+			last = v
+		}
+	}
+	if Smoothstep(0.5) != 0.5 || Cubic(0.5) != 0.5 || Cubic(0.25) != 0.0625 {
+		t.Errorf("Smoothstep(0.5) = %v, Cubic(0.5) = %v, Cubic(0.25) = %v", Smoothstep(0.5), Cubic(0.5), Cubic(0.25))
+	}
+	if _, ok := EasingByName("bounce"); ok {
+		t.Error("bounce is known")
+	}
+}
+
+func TestTrack(t *testing.T) {
+	a := View{Center: -0.5, Width: 3}
+	b := View{Center: -0.75 + 0.1i, Width: 0.3}
+	c := View{Center: -0.745 + 0.113i, Width: 0.003, Rotation: 90}
+	tr := Track{{Frame: 0, View: a, Ease: Smoothstep}, {Frame: 10, View: b}, {Frame: 30, View: c}}
+	if err := tr.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		frame float64
+		want  View
+	}{{-5, a}, {0, a}, {10, b}, {30, c}, {40, c}} {
+		if got := tr.At(tc.frame); cmplx.Abs(got.Center-tc.want.Center) > 1e-12 || math.Abs(got.Width/tc.want.Width-1) > 1e-12 {
+			t.Errorf("frame %v: %+v, want %+v", tc.frame, got, tc.want)
+		}
+	}
+	if i, u := tr.Locate(2.5); i != 0 || u != Smoothstep(0.25) {
+		t.Errorf("Locate(2.5) = %d, %v; want 0, %v", i, u, Smoothstep(0.25))
+	}
+	if i, u := tr.Locate(20); i != 1 || u != 0.5 {
+		t.Errorf("Locate(20) = %d, %v; want 1, 0.5", i, u)
+	}
+
+	for _, bad := range []Track{
+		nil,
+		{{Frame: 0, View: a}, {Frame: 0, View: b}},
+		{{Frame: 0, View: View{Width: 0}}},
+		{{Frame: 0, View: View{Width: 1, Rotation: math.NaN()}}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v: no error", bad)
+		}
+	}
+}
+
+func TestExpm1(t *testing.T) {
+	for _, z := range []complex128{1e-12, 1e-12i, 1e-9 - 1e-9i, 0.5 + 2i, -3 + 1i} {
+		want := cmplx.Exp(z) - 1
+		if cmplx.Abs(z) < 1e-6 {
+			want = z + z*z/2
+		}
+		if got := expm1(z); cmplx.Abs(got-want) > 1e-15*cmplx.Abs(want) {
+			t.Errorf("expm1(%v) = %v, want %v", z, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package anim
+
+import "strings"
+
+// An Easing reshapes animation time: it maps t in [0,1] to [0,1], with 0
+// and 1 fixed. Applied to a Zoom it changes how fast the camera moves at
+// each moment, never where it goes.
+type Easing func(t float64) float64
+
+// Linear keeps the constant rate: in a Zoom, log(width) changes by the
+// same amount every frame.
+func Linear(t float64) float64 { return t }
+
+// Smoothstep starts and stops gently, 3t²-2t³.
+func Smoothstep(t float64) float64 { return t * t * (3 - 2*t) }
+
+// Cubic starts and stops more gently still, accelerating as t³ for the
+// first half and decelerating symmetrically for the second.
+func Cubic(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	u := 2 - 2*t
+	return 1 - u*u*u/2
+}
+
+// EasingNames lists the easings EasingByName knows.
+var EasingNames = []string{"linear", "smoothstep", "cubic"}
+
+// EasingByName returns the named easing, or false for an unknown name.
+func EasingByName(name string) (Easing, bool) {
+	switch strings.ToLower(name) {
+	case "linear":
+		return Linear, true
+	case "smoothstep":
+		return Smoothstep, true
+	case "cubic":
+		return Cubic, true
+	}
+	return nil, false
+}
@@ -0,0 +1,79 @@
+package anim
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// A Path is a curve through the plane, such as the route of a Julia
+// parameter, traversed as t runs from 0 to 1.
+type Path interface {
+	At(t float64) complex128
+	// Closed reports whether the path ends where it starts, in which case
+	// a looping animation shouldn't show that point twice.
+	Closed() bool
+}
+
+// Line runs straight from From to To.
+type Line struct{ From, To complex128 }
+
+func (l Line) At(t float64) complex128 { return l.From + (l.To-l.From)*complex(t, 0) }
+func (Line) Closed() bool              { return false }
+
+// Circle goes once counter-clockwise around Center, starting due east of it.
+type Circle struct {
+	Center complex128
+	Radius float64
+}
+
+func (c Circle) At(t float64) complex128 {
+	return c.Center + cmplx.Rect(c.Radius, 2*math.Pi*t)
+}
+func (Circle) Closed() bool { return true }
+
+// Cardioid follows the boundary of the Mandelbrot set's main cardioid,
+// scaled by Scale about the origin, starting at the cusp. A Scale just
+// below 1 keeps every Julia set along the way connected, which gives the
+// classic morph around the set.
+type Cardioid struct{ Scale float64 }
+
+func (c Cardioid) At(t float64) complex128 {
+	w := cmplx.Exp(complex(0, 2*math.Pi*t))
+	return complex(c.Scale, 0) * (w/2 - w*w/4)
+}
+func (Cardioid) Closed() bool { return true }
+
+// Waypoints passes through each point in turn along a Catmull-Rom spline,
+// spending the same share of t on every leg.
+type Waypoints []complex128
+
+func (w Waypoints) At(t float64) complex128 {
+	switch len(w) {
+	case 0:
+		return 0
+	case 1:
+		return w[0]
+	}
+	legs := len(w) - 1
+	f := min(max(t, 0), 1) * float64(legs)
+	i := min(int(f), legs-1)
+	u := f - float64(i)
+	// the end points stand in for the missing neighbors of the first and
+	// last legs
+	p0, p1, p2, p3 := w[max(i-1, 0)], w[i], w[i+1], w[min(i+2, legs)]
+	u2, u3 := complex(u*u, 0), complex(u*u*u, 0)
+	return 0.5 * (2*p1 + (p2-p0)*complex(u, 0) +
+		(2*p0-5*p1+4*p2-p3)*u2 +
+		(3*p1-p0-3*p2+p3)*u3)
+}
+func (Waypoints) Closed() bool { return false }
+
+// PathT returns t for frame i of an n-frame pass along p: from 0 to 1
+// inclusive for an open path, and stopping one step short of 1 for a
+// closed one, so the sequence loops without a repeated frame.
+func PathT(p Path, i, n int) float64 {
+	if p.Closed() {
+		return float64(i) / float64(n)
+	}
+	return Frame(i, n)
+}
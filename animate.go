@@ -0,0 +1,492 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/anim"
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/fractal"
+	"github.com/whalelogic/mandlebrot/output"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// namedViews are the locations animate accepts by name. Each is fitted
+// to the frame size, so the whole window stays in view.
+var namedViews = map[string]coords.Bounds{
+	"default":  render.DefaultBounds,
+	"seahorse": {Xmin: -0.7536, Xmax: -0.7336, Ymin: 0.1243, Ymax: 0.1393},
+	"julia":    {Xmin: -1.6, Xmax: 1.6, Ymin: -1.2, Ymax: 1.2},
+	"elephant": {Xmin: 0.2825, Xmax: 0.3025, Ymin: 0.0074, Ymax: 0.0224},
+}
+
+// animateMain runs the "animate" subcommand: it renders the frames of a
+// camera move (a zoom from one view to another, a turn or an orbit) or of
+// a Julia morph into numbered image files, ready for
+// ffmpeg -i frame-%04d.png.
+func animateMain(args []string) {
+	fs := flag.NewFlagSet("animate", flag.ExitOnError)
+	mode := fs.String("mode", "zoom", "animation: zoom from -from to -to, rotate -from in place, orbit the camera along -path, or julia to move the Julia parameter along -path")
+	from := fs.String("from", "default", "start view: a name ("+viewNames()+"), bookmark:N or cx,cy,width; julia mode's only view, julia by default")
+	to := fs.String("to", "seahorse", "end view, in the same forms as -from")
+	path := fs.String("path", "", "orbit and julia modes: route of the camera center or the parameter: line:re,im,re,im, circle:re,im,radius, points:re,im,re,im,... or cardioid[:scale]; by default a circle a quarter of the view wide around its center, and cardioid:0.98 in julia mode")
+	rotate := fs.Float64("rotate", 0, "degrees to turn the camera counter-clockwise over the animation; 360 by default in rotate mode")
+	insetSize := fs.Float64("inset", 0, "julia mode: height of a Mandelbrot inset marking the parameter, as a fraction of the frame height (0 = none)")
+	frames := fs.Int("frames", 300, "number of frames, including both ends")
+	width := fs.Int("width", 1280, "frame width in pixels")
+	height := fs.Int("height", 720, "frame height in pixels")
+	iters := fs.Int("iters", 300, "iteration count at the start view")
+	itersPerOctave := fs.Int("iters-per-octave", 50, "iterations added each time the view width halves")
+	ease := fs.String("ease", "linear", "zoom timing ("+strings.Join(anim.EasingNames, ", ")+"); linear zooms at a constant speed")
+	out := fs.String("out", "frame-%04d.png", "frame file name, with a printf verb for the frame number")
+	resume := fs.Bool("resume", false, "skip frames whose files already exist and decode in full at the frame size")
+	jobs := fs.Int("jobs", 1, "frames rendered at once; -procs is shared out between them")
+	manifest := fs.String("manifest", "", "progress manifest file (default manifest.json beside the frames)")
+	lockStale := fs.Duration("lock-stale", 30*time.Second, "age at which another worker's frame lock counts as abandoned")
+	bookmarks := fs.String("bookmarks", "bookmarks.txt", "bookmarks file for bookmark:N views")
+	pal := fs.String("palette", render.DefaultPalette, "palette name (case-sensitive)")
+	coloring := fs.String("coloring", string(render.DefaultColoring), "coloring mode ("+coloringNames()+")")
+	concurrency := fs.Int("procs", runtime.NumCPU(), "concurrent worker count")
+	timelinePath := fs.String("timeline", "", "JSON timeline file of keyframes to animate through, in place of -mode and its views; its settings override -width, -height, -palette, -coloring, -iters and -iters-per-octave")
+	fs.Parse(args)
+
+	var tl *timeline
+	if *timelinePath != "" {
+		var err error
+		tl, err = loadTimeline(*timelinePath, *bookmarks, timelineFile{
+			Width: *width, Height: *height, FPS: 30,
+			Palette: *pal, Coloring: *coloring,
+			Iters: *iters, ItersPerOctave: *itersPerOctave,
+		})
+		if err != nil {
+			fail("", err)
+		}
+		*mode = "timeline"
+		*width, *height, *frames = tl.Width, tl.Height, tl.frames
+		*pal, *coloring, *iters = tl.Palette, tl.Coloring, tl.Iters
+	}
+
+	if *frames < 1 {
+		fail("", fmt.Errorf("%w: -frames %d: must be positive", render.ErrInvalidOptions, *frames))
+	}
+	if *jobs < 1 {
+		fail("", fmt.Errorf("%w: -jobs %d: must be positive", render.ErrInvalidOptions, *jobs))
+	}
+	if *lockStale < time.Second {
+		fail("", fmt.Errorf("%w: -lock-stale %v: must be at least a second", render.ErrInvalidOptions, *lockStale))
+	}
+	if !strings.Contains(*out, "%") {
+		fail("", fmt.Errorf("%w: -out %q: needs a verb such as %%04d for the frame number", render.ErrInvalidOptions, *out))
+	}
+	if *insetSize < 0 || *insetSize > 1 {
+		fail("", fmt.Errorf("%w: -inset %g: must be within [0,1]", render.ErrInvalidOptions, *insetSize))
+	}
+	format, err := render.FormatFromPath(*out)
+	if err != nil {
+		fail("", err)
+	}
+	if *mode == "julia" && !isSet(fs, "from") {
+		*from = "julia"
+	}
+	start, err := parseView(*from, *bookmarks, *width, *height)
+	if err != nil {
+		fail("-from: ", err)
+	}
+	if math.IsNaN(*rotate) || math.IsInf(*rotate, 0) {
+		fail("", fmt.Errorf("%w: -rotate %g: must be finite", render.ErrInvalidOptions, *rotate))
+	}
+	if *mode == "rotate" && !isSet(fs, "rotate") {
+		*rotate = 360
+	}
+	if *path == "" {
+		*path = "cardioid:0.98"
+		if *mode == "orbit" {
+			*path = fmt.Sprintf("circle:%g,%g,%g", real(start.Center), imag(start.Center), start.Width/8)
+		}
+	}
+
+	// frame returns what to render for frame i
+	var frame func(i int) animFrame
+	switch *mode {
+	case "timeline":
+		start = tl.track[0].View
+		frame = tl.frame
+	case "zoom":
+		end, err := parseView(*to, *bookmarks, *width, *height)
+		if err != nil {
+			fail("-to: ", err)
+		}
+		easing, ok := anim.EasingByName(*ease)
+		if !ok {
+			fail("", fmt.Errorf("%w: -ease %q: not one of %s", render.ErrInvalidOptions, *ease, strings.Join(anim.EasingNames, ", ")))
+		}
+		end.Rotation = start.Rotation + *rotate
+		track := anim.Track{
+			{Frame: 0, View: start, Ease: easing},
+			{Frame: max(*frames-1, 1), View: end},
+		}
+		if err := track.Validate(); err != nil {
+			fail("", fmt.Errorf("%w: %w", render.ErrInvalidOptions, err))
+		}
+		frame = func(i int) animFrame {
+			v := track.At(float64(i))
+			n := *iters + int(math.Round(float64(*itersPerOctave)*max(track.Depth(v), 0)))
+			return animFrame{
+				vp:     v.Viewport(*width, *height),
+				opts:   []render.Option{render.WithIterations(n)},
+				detail: fmt.Sprintf("iters %d", n),
+			}
+		}
+	case "rotate":
+		// a whole number of turns loops, so the last frame stops one step
+		// short of the first
+		turn := anim.Zoom{From: start, To: start}
+		turn.To.Rotation += *rotate
+		loops := math.Mod(*rotate, 360) == 0
+		frame = func(i int) animFrame {
+			t := anim.Frame(i, *frames)
+			if loops {
+				t = float64(i) / float64(*frames)
+			}
+			v := turn.At(t)
+			return animFrame{
+				vp:     v.Viewport(*width, *height),
+				detail: fmt.Sprintf("%.1f°", v.Rotation),
+			}
+		}
+	case "orbit":
+		route, err := parsePath(*path)
+		if err != nil {
+			fail("-path: ", err)
+		}
+		frame = func(i int) animFrame {
+			t := anim.PathT(route, i, *frames)
+			v := start
+			v.Center = route.At(t)
+			v.Rotation += t * *rotate
+			return animFrame{
+				vp:     v.Viewport(*width, *height),
+				detail: fmt.Sprintf("center %.6f%+.6fi", real(v.Center), imag(v.Center)),
+			}
+		}
+	case "julia":
+		route, err := parsePath(*path)
+		if err != nil {
+			fail("-path: ", err)
+		}
+		vp := start.Viewport(*width, *height)
+		frame = func(i int) animFrame {
+			k := route.At(anim.PathT(route, i, *frames))
+			return animFrame{
+				vp:     vp,
+				opts:   []render.Option{render.WithFractal(fractal.Julia{K: k})},
+				detail: fmt.Sprintf("c %.6f%+.6fi", real(k), imag(k)),
+				k:      k,
+			}
+		}
+	default:
+		fail("", fmt.Errorf("%w: -mode %q: want zoom, rotate, orbit or julia", render.ErrInvalidOptions, *mode))
+	}
+
+	opts, err := render.New(
+		render.WithSize(*width, *height),
+		render.WithViewport(start.Bounds(*width, *height)),
+		render.WithIterations(*iters),
+		render.WithPaletteName(*pal),
+		render.WithColoring(render.Coloring(*coloring)),
+		render.WithProcs(*concurrency),
+		render.WithDiscardBuffers(true),
+	)
+	if err != nil {
+		fail("invalid options:\n", err)
+	}
+	// each frame worker gets its share of the procs, so -jobs doesn't
+	// multiply the CPU load
+	opts.Procs = max(1, *concurrency / *jobs)
+	renderers := make([]*render.Renderer, *jobs)
+	for w := range renderers {
+		if renderers[w], err = render.NewRenderer(opts); err != nil {
+			fail("", err)
+		}
+		defer renderers[w].Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var in *inset
+	if (*mode == "julia" || tl != nil && tl.julia != nil) && *insetSize > 0 {
+		if in, err = newInset(ctx, opts, int(*insetSize*float64(*height))); err != nil {
+			fail("inset: ", err)
+		}
+	}
+	if dir := filepath.Dir(*out); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			fail("", err)
+		}
+	}
+	sched := &frameScheduler{
+		pattern:   *out,
+		frames:    *frames,
+		width:     *width,
+		height:    *height,
+		resume:    *resume,
+		lockStale: *lockStale,
+		manifest:  *manifest,
+		command:   strings.Join(os.Args, " "),
+	}
+	if sched.manifest == "" {
+		sched.manifest = filepath.Join(filepath.Dir(*out), "manifest.json")
+	}
+	err = sched.run(ctx, *jobs, func(ctx context.Context, w, i int) (string, error) {
+		f := frame(i)
+		res, err := renderers[w].Render(ctx, f.vp, f.opts...)
+		if err != nil {
+			return "", err
+		}
+		if in != nil {
+			in.draw(res.Image, f.k)
+		}
+		return f.detail, writeFrame(ctx, sched.path(i), res, format)
+	})
+	if err != nil {
+		fail("", err)
+	}
+	fmt.Printf("Rendered %d of %d frames in %v\n", sched.rendered, *frames, time.Since(sched.began).Round(time.Millisecond))
+}
+
+// animFrame is what animate renders for one frame.
+type animFrame struct {
+	vp     coords.Viewport
+	opts   []render.Option // overrides of the animation-wide options
+	detail string          // shown in the progress line
+	k      complex128      // Julia parameter, in julia mode
+}
+
+// inset is a small render of the whole Mandelbrot set that julia frames
+// show in their bottom right corner, with the frame's parameter marked.
+type inset struct {
+	img *image.RGBA
+	vp  coords.Viewport
+}
+
+// insetMargin is the gap in pixels between an inset and the frame edges.
+const insetMargin = 8
+
+// newInset renders an inset size pixels high with the palette and
+// coloring of opts.
+func newInset(ctx context.Context, opts render.Options, size int) (*inset, error) {
+	h := max(size, 8)
+	w := h * 4 / 3
+	if w+2*insetMargin > opts.Width || h+2*insetMargin > opts.Height {
+		return nil, fmt.Errorf("%w: %dx%d inset does not fit in a %dx%d frame", render.ErrInvalidOptions, w, h, opts.Width, opts.Height)
+	}
+	vp := coords.NewViewport(render.DefaultBounds.FitToImage(w, h), w, h)
+	insetOpts, err := render.New(
+		render.WithSize(w, h),
+		render.WithViewport(vp.Bounds),
+		render.WithIterations(200),
+		render.WithPalette(opts.Palette),
+		render.WithColoring(opts.Coloring),
+	)
+	if err != nil {
+		return nil, err
+	}
+	res, err := render.Render(ctx, insetOpts)
+	if err != nil {
+		return nil, err
+	}
+	return &inset{img: res.Image, vp: vp}, nil
+}
+
+// draw copies the inset into dst, framed by a white border, and marks k
+// on it with a crosshair.
+func (in *inset) draw(dst *image.RGBA, k complex128) {
+	w, h := in.img.Rect.Dx(), in.img.Rect.Dy()
+	at := image.Pt(dst.Rect.Max.X-insetMargin-w, dst.Rect.Max.Y-insetMargin-h)
+	draw.Draw(dst, image.Rectangle{at, at.Add(image.Pt(w, h))}, in.img, image.Point{}, draw.Src)
+	white, black := color.RGBA{0xff, 0xff, 0xff, 0xff}, color.RGBA{0, 0, 0, 0xff}
+	for x := -1; x <= w; x++ {
+		dst.SetRGBA(at.X+x, at.Y-1, white)
+		dst.SetRGBA(at.X+x, at.Y+h, white)
+	}
+	for y := -1; y <= h; y++ {
+		dst.SetRGBA(at.X-1, at.Y+y, white)
+		dst.SetRGBA(at.X+w, at.Y+y, white)
+	}
+	x, y, ok := in.vp.ComplexToPixel(k)
+	if !ok {
+		return
+	}
+	// a white cross with a black outline stands out on any palette
+	clip := image.Rectangle{at, at.Add(image.Pt(w, h))}
+	set := func(px, py int, c color.RGBA) {
+		if p := at.Add(image.Pt(px, py)); p.In(clip) {
+			dst.SetRGBA(p.X, p.Y, c)
+		}
+	}
+	for d := -5; d <= 5; d++ {
+		for _, o := range []int{-1, 1} {
+			set(x+d, y+o, black)
+			set(x+o, y+d, black)
+		}
+	}
+	for d := -4; d <= 4; d++ {
+		set(x+d, y, white)
+		set(x, y+d, white)
+	}
+}
+
+// parsePath decodes an animate -path value.
+func parsePath(s string) (anim.Path, error) {
+	kind, args, _ := strings.Cut(s, ":")
+	var vals []float64
+	if args != "" {
+		for _, f := range strings.Split(args, ",") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: path %q: %v", render.ErrInvalidOptions, s, err)
+			}
+			vals = append(vals, v)
+		}
+	}
+	bad := func(want string) error {
+		return fmt.Errorf("%w: path %q: %s takes %s", render.ErrInvalidOptions, s, kind, want)
+	}
+	switch kind {
+	case "line":
+		if len(vals) != 4 {
+			return nil, bad("re,im,re,im")
+		}
+		return anim.Line{From: complex(vals[0], vals[1]), To: complex(vals[2], vals[3])}, nil
+	case "circle":
+		if len(vals) != 3 || !(vals[2] > 0) {
+			return nil, bad("re,im,radius with a positive radius")
+		}
+		return anim.Circle{Center: complex(vals[0], vals[1]), Radius: vals[2]}, nil
+	case "points":
+		if len(vals) < 4 || len(vals)%2 != 0 {
+			return nil, bad("at least two re,im pairs")
+		}
+		var w anim.Waypoints
+		for i := 0; i < len(vals); i += 2 {
+			w = append(w, complex(vals[i], vals[i+1]))
+		}
+		return w, nil
+	case "cardioid":
+		switch len(vals) {
+		case 0:
+			return anim.Cardioid{Scale: 1}, nil
+		case 1:
+			if !(vals[0] > 0) {
+				return nil, bad("a positive scale")
+			}
+			return anim.Cardioid{Scale: vals[0]}, nil
+		}
+		return nil, bad("at most a scale")
+	}
+	return nil, fmt.Errorf("%w: path %q: want line, circle, points or cardioid", render.ErrInvalidOptions, s)
+}
+
+// writeFrame encodes res to path with output.WriteFile, so an interrupted
+// run never leaves a truncated frame for -resume to skip.
+func writeFrame(ctx context.Context, path string, res *render.Result, format string) error {
+	return output.WriteFile(path, func(w io.Writer) error {
+		return render.EncodeWithText(ctxWriter{ctx, w}, res.Output(), format, render.Metadata(res.Options))
+	})
+}
+
+// parseView decodes an animate view argument for a width×height frame:
+// one of namedViews, bookmark:N for the N-th view in the bookmarks file
+// (counting from 1), or a center and width as cx,cy,width.
+func parseView(s, bookmarks string, width, height int) (anim.View, error) {
+	if b, ok := namedViews[s]; ok {
+		return anim.ViewOf(b.FitToImage(width, height)), nil
+	}
+	if n, ok := strings.CutPrefix(s, "bookmark:"); ok {
+		b, err := nthBookmark(bookmarks, n)
+		if err != nil {
+			return anim.View{}, err
+		}
+		return anim.ViewOf(b.Bounds.FitToImage(width, height)), nil
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return anim.View{}, fmt.Errorf("%w: view %q: want a name (%s), bookmark:N or cx,cy,width", render.ErrInvalidViewport, s, viewNames())
+	}
+	var vals [3]float64
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return anim.View{}, fmt.Errorf("%w: view %q: %v", render.ErrInvalidViewport, s, err)
+		}
+		vals[i] = v
+	}
+	if !(vals[2] > 0) || math.IsInf(vals[2], 0) {
+		return anim.View{}, fmt.Errorf("%w: view %q: width must be positive", render.ErrInvalidViewport, s)
+	}
+	return anim.View{Center: complex(vals[0], vals[1]), Width: vals[2]}, nil
+}
+
+// nthBookmark returns bookmark n, counting from 1, of the bookmarks file
+// at path. Lines that aren't reproduce commands aren't counted.
+func nthBookmark(path, n string) (bookmark, error) {
+	want, err := strconv.Atoi(n)
+	if err != nil || want < 1 {
+		return bookmark{}, fmt.Errorf("%w: bookmark %q: want a number from 1", render.ErrInvalidViewport, n)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return bookmark{}, err
+	}
+	defer f.Close()
+	seen := 0
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		b, err := parseBookmark(sc.Text())
+		if err != nil {
+			continue
+		}
+		if seen++; seen == want {
+			return b, nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return bookmark{}, err
+	}
+	return bookmark{}, fmt.Errorf("%w: bookmark %d: %s has only %d", render.ErrInvalidViewport, want, path, seen)
+}
+
+// viewNames returns the keys of namedViews as a comma-separated list.
+func viewNames() string {
+	return strings.Join(slices.Sorted(maps.Keys(namedViews)), ", ")
+}
+
+// isSet reports whether the flag name was given on the command line.
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) { set = set || f.Name == name })
+	return set
+}
+
+// digits returns the number of decimal digits in n.
+func digits(n int) int {
+	return len(strconv.Itoa(n))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"os"
+)
+
+// loadBackground decodes the PNG or JPEG at path for -background-image.
+func loadBackground(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if src.Bounds().Empty() {
+		return nil, fmt.Errorf("%s: image is empty", path)
+	}
+	return src, nil
+}
+
+// resizeImage scales src to w×h by bilinear interpolation, stretching it
+// if the aspect ratios differ. Pixel centers are matched, so scaling by a
+// whole factor puts each source pixel at the middle of its block, and
+// samples past the edge take the edge pixel. The interpolation is done on
+// premultiplied colors, so transparent pixels don't bleed their color.
+func resizeImage(src image.Image, w, h int) *image.RGBA {
+	sb := src.Bounds()
+	s := image.NewRGBA(image.Rect(0, 0, sb.Dx(), sb.Dy()))
+	draw.Draw(s, s.Rect, src, sb.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sw, sh := sb.Dx(), sb.Dy()
+	// sample maps a destination coordinate to the two source pixels around
+	// it and the weight of the second
+	sample := func(d, dn, sn int) (int, int, float64) {
+		f := (float64(d)+0.5)*float64(sn)/float64(dn) - 0.5
+		f = min(max(f, 0), float64(sn-1))
+		i := int(math.Floor(f))
+		return i, min(i+1, sn-1), f - float64(i)
+	}
+	for y := range h {
+		y0, y1, fy := sample(y, h, sh)
+		for x := range w {
+			x0, x1, fx := sample(x, w, sw)
+			p00 := s.PixOffset(x0, y0)
+			p10 := s.PixOffset(x1, y0)
+			p01 := s.PixOffset(x0, y1)
+			p11 := s.PixOffset(x1, y1)
+			d := dst.PixOffset(x, y)
+			for k := range 4 {
+				top := (1-fx)*float64(s.Pix[p00+k]) + fx*float64(s.Pix[p10+k])
+				bottom := (1-fx)*float64(s.Pix[p01+k]) + fx*float64(s.Pix[p11+k])
+				dst.Pix[d+k] = uint8(math.Round((1-fy)*top + fy*bottom))
+			}
+		}
+	}
+	return dst
+}
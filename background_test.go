@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func TestBackgroundComposite(t *testing.T) {
+	red := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for i := 0; i < len(red.Pix); i += 4 {
+		copy(red.Pix[i:], []byte{0xff, 0, 0, 0xff})
+	}
+	path := filepath.Join(t.TempDir(), "red.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, red); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	bg, err := loadBackground(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a palette that is blue at half opacity all the way along
+	blue := &palette.ColorMap{Keyword: "HalfBlue", Colors: []palette.Color{
+		palette.NewStop(0, 0, 0, 0xff, 0x80), palette.NewStop(1, 0, 0, 0xff, 0x80),
+	}}
+	opts, err := render.New(render.WithSize(64, 48), render.WithIterations(100), render.WithPalette(blue))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := compositeSourceOver(res.Image, resizeImage(bg, 64, 48))
+	exterior := 0
+	for i, inside := range res.Inside {
+		if inside {
+			continue
+		}
+		exterior++
+		x, y := i%64, i/64
+		// half red, half blue, and opaque
+		if got, want := out.RGBAAt(x, y), (color.RGBA{0x7f, 0, 0x80, 0xff}); got != want {
+			t.Fatalf("exterior (%d, %d) = %v, want %v", x, y, got, want)
+		}
+	}
+	if exterior == 0 {
+		t.Fatal("no exterior pixels")
+	}
+}
+
+func TestResizeImage(t *testing.T) {
+	// a two-pixel gradient doubled: the pixel centers keep their values,
+	// the new ones between are blended a quarter of the way, and past the
+	// ends the edge pixels are repeated
+	src := image.NewRGBA(image.Rect(5, 5, 7, 6))
+	src.SetRGBA(5, 5, color.RGBA{0, 0, 0, 0xff})
+	src.SetRGBA(6, 5, color.RGBA{200, 100, 40, 0xff})
+	dst := resizeImage(src, 4, 2)
+	if dst.Rect != image.Rect(0, 0, 4, 2) {
+		t.Fatalf("bounds %v", dst.Rect)
+	}
+	want := []color.RGBA{{0, 0, 0, 0xff}, {50, 25, 10, 0xff}, {150, 75, 30, 0xff}, {200, 100, 40, 0xff}}
+	for y := range 2 {
+		for x, w := range want {
+			if got := dst.RGBAAt(x, y); got != w {
+				t.Errorf("(%d, %d) = %v, want %v", x, y, got, w)
+			}
+		}
+	}
+
+	// a transparent pixel next to an opaque one adds no color of its own
+	src.SetRGBA(5, 5, color.RGBA{})
+	dst = resizeImage(src, 4, 1)
+	if got := dst.RGBAAt(1, 0); got != (color.RGBA{50, 25, 10, 0x40}) {
+		t.Errorf("next to transparent: %v", got)
+	}
+	// the same size is a copy
+	if got := resizeImage(src, 2, 1); got.RGBAAt(1, 0) != src.RGBAAt(6, 5) || got.RGBAAt(0, 0) != src.RGBAAt(5, 5) {
+		t.Error("resizing to the same size changed the image")
+	}
+}
+
+func TestLoadBackgroundErrors(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "notes.png")
+	if err := os.WriteFile(text, []byte("not an image"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(dir, "missing.png"), text} {
+		if _, err := loadBackground(path); err == nil {
+			t.Errorf("%s: no error", path)
+		}
+	}
+}
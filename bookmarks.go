@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// bookmarkFile is the bookmarks file shared with explore: one reproduce
+// command per line. The server serializes its own writes; appends from an
+// explore session in another process are single short writes and don't
+// interleave with them.
+type bookmarkFile struct {
+	mu   sync.Mutex
+	path string
+}
+
+// bookmark is one line of the bookmarks file, decoded. X and Y place the
+// view's center in the tile root square, scaled to [0,1], and TileZoom is
+// the tile zoom level at which one tile spans the view's width.
+type bookmark struct {
+	Command  string        `json:"command"`
+	Bounds   coords.Bounds `json:"bounds"`
+	Rotation float64       `json:"rotation,omitempty"`
+	Iters    int           `json:"iters,omitempty"`
+	Palette  string        `json:"palette,omitempty"`
+	Coloring string        `json:"coloring,omitempty"`
+	X        float64       `json:"x"`
+	Y        float64       `json:"y"`
+	TileZoom float64       `json:"tileZoom"`
+}
+
+// handleBookmarks serves GET /bookmarks. Lines that aren't reproduce
+// commands are skipped.
+func (s *server) handleBookmarks(w http.ResponseWriter, r *http.Request) {
+	s.bookmarks.mu.Lock()
+	f, err := os.Open(s.bookmarks.path)
+	if err != nil {
+		s.bookmarks.mu.Unlock()
+		if errors.Is(err, os.ErrNotExist) {
+			writeJSON(w, http.StatusOK, []bookmark{})
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	out := []bookmark{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if b, err := parseBookmark(sc.Text()); err == nil {
+			out = append(out, s.tiles.place(b))
+		}
+	}
+	err = sc.Err()
+	f.Close()
+	s.bookmarks.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// handleAddBookmark serves POST /bookmarks. The body is a JSON object
+// with the /render query parameters; the view it describes is appended to
+// the bookmarks file.
+func (s *server) handleAddBookmark(w http.ResponseWriter, r *http.Request) {
+	var params map[string]any
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := url.Values{}
+	for k, v := range params {
+		q.Set(k, fmt.Sprint(v))
+	}
+	opts, err := s.parseRender(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	line := render.BuildReproduceCommand(opts)
+
+	s.bookmarks.mu.Lock()
+	err = appendLine(s.bookmarks.path, line)
+	s.bookmarks.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b, err := parseBookmark(line)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.tiles.place(b))
+}
+
+// appendLine appends line to the file at path, creating it if needed.
+func appendLine(path, line string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// parseBookmark decodes a command written by render.BuildReproduceCommand.
+// Only the view, iterations, palette and coloring are extracted.
+func parseBookmark(line string) (bookmark, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		return bookmark{}, errors.New("not a command")
+	}
+	vals := make(map[string]string)
+	for i := 1; i+1 < len(fields); i += 2 {
+		vals[strings.TrimLeft(fields[i], "-")] = fields[i+1]
+	}
+	var errs []error
+	num := func(name string) float64 {
+		v, err := strconv.ParseFloat(vals[name], 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("-%s: %w", name, err))
+		}
+		return v
+	}
+	b := bookmark{
+		Command:  line,
+		Bounds:   coords.Bounds{Xmin: num("xmin"), Xmax: num("xmax"), Ymin: num("ymin"), Ymax: num("ymax")},
+		Palette:  vals["palette"],
+		Coloring: vals["coloring"],
+	}
+	if v, ok := vals["rotate"]; ok {
+		b.Rotation, _ = strconv.ParseFloat(v, 64)
+	}
+	if v, ok := vals["iters"]; ok {
+		b.Iters, _ = strconv.Atoi(v)
+	}
+	return b, errors.Join(errs...)
+}
+
+// place fills in b's position in the tile pyramid.
+func (tc tileConfig) place(b bookmark) bookmark {
+	c := b.Bounds.Center()
+	b.X = (real(c) - tc.root.Xmin) / tc.root.Width()
+	b.Y = (tc.root.Ymax - imag(c)) / tc.root.Height()
+	b.TileZoom = math.Log2(tc.root.Width() / b.Bounds.Width())
+	return b
+}
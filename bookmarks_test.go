@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+func TestBookmarksConcurrent(t *testing.T) {
+	// Concurrent POSTs each append one whole line, and GET reads them all
+	// back, while they are being written too.
+	s := testServer()
+	s.tiles = tileConfig{root: coords.Bounds{Xmin: -2.5, Xmax: 1.5, Ymin: -2, Ymax: 2}}
+	s.bookmarks = &bookmarkFile{path: filepath.Join(t.TempDir(), "bookmarks.txt")}
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/bookmarks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []bookmark
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil || got == nil || len(got) != 0 {
+		t.Fatalf("no file: %v, %v", got, err)
+	}
+	resp.Body.Close()
+
+	const n = 40
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			body := fmt.Sprintf(`{"cx": -0.75, "cy": 0.1, "zoom": %d, "iters": %d, "w": 40, "h": 30}`, i+1, 100+i)
+			resp, err := http.Post(srv.URL+"/bookmarks", "application/json", strings.NewReader(body))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusCreated {
+				t.Errorf("bookmark %d: status %s", i, resp.Status)
+			}
+		})
+		wg.Go(func() {
+			resp, err := http.Get(srv.URL + "/bookmarks")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("GET: status %s", resp.Status)
+			}
+		})
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(s.bookmarks.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != n {
+		t.Fatalf("%d lines, want %d", len(lines), n)
+	}
+	seen := make(map[int]bool)
+	for _, line := range lines {
+		b, err := parseBookmark(line)
+		if err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		seen[b.Iters] = true
+	}
+	if len(seen) != n {
+		t.Errorf("%d distinct bookmarks, want %d", len(seen), n)
+	}
+
+	resp, err = http.Get(srv.URL + "/bookmarks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil || len(got) != n {
+		t.Errorf("GET after: %d bookmarks, %v", len(got), err)
+	}
+}
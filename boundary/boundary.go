@@ -0,0 +1,217 @@
+// Package boundary traces the edge of the set with marching squares.
+package boundary
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// FindBoundary renders the inside/outside mask described by opts and
+// traces the boundary between the two with marching squares. The points
+// are returned contour by contour in walk order, so consecutive points are
+// neighbours on the same contour except where one contour ends and the
+// next begins.
+func FindBoundary(opts render.Options) []complex128 {
+	var points []complex128
+	for _, c := range Contours(opts) {
+		points = append(points, c...)
+	}
+	return points
+}
+
+// Contours is like FindBoundary but keeps each traced contour separate.
+func Contours(opts render.Options) [][]complex128 {
+	if opts.Width < 2 || opts.Height < 2 {
+		return nil
+	}
+	opts.DiscardBuffers = false
+	res, _ := render.Render(context.Background(), opts)
+	return Trace(res, 0)
+}
+
+// Trace traces the contours of a finished render where its continuous
+// escape count crosses threshold, with pixels at or above it counting as
+// inside. A threshold of 0 or less traces the interior mask instead, the
+// edge of the set itself. Vertices are mapped to the plane through the
+// render's viewport, rotation included. res must have kept its buffers.
+func Trace(res *render.Result, threshold float64) [][]complex128 {
+	w, h := res.Options.Width, res.Options.Height
+	if w < 2 || h < 2 || len(res.Inside) != w*h {
+		return nil
+	}
+	inside := res.Inside
+	if threshold > 0 {
+		inside = make([]bool, len(res.Iters))
+		for i, n := range res.Iters {
+			inside[i] = n >= threshold
+		}
+	}
+
+	vp := res.Options.Viewport()
+	var out [][]complex128
+	for _, line := range march(inside, w, h) {
+		pts := make([]complex128, len(line))
+		for i, p := range line {
+			pts[i] = vp.PointToComplex(p.x+0.5, p.y+0.5) // samples sit at pixel centers
+		}
+		out = append(out, pts)
+	}
+	return out
+}
+
+// point is a position in pixel space; contour vertices sit on the
+// midpoints of the edges between neighbouring samples.
+type point struct{ x, y float64 }
+
+// march runs marching squares over a w×h inside mask and links the cell
+// segments into polylines. Each cell edge is identified by the index of
+// its top/left sample times two, plus one for vertical edges.
+func march(inside []bool, w, h int) [][]point {
+	at := func(x, y int) bool { return inside[y*w+x] }
+	hEdge := func(x, y int) int { return 2 * (y*w + x) }
+	vEdge := func(x, y int) int { return 2*(y*w+x) + 1 }
+
+	links := make(map[int][]int)
+	link := func(a, b int) {
+		links[a] = append(links[a], b)
+		links[b] = append(links[b], a)
+	}
+
+	for y := 0; y < h-1; y++ {
+		for x := 0; x < w-1; x++ {
+			// corner bits: top-left 8, top-right 4, bottom-right 2, bottom-left 1
+			idx := 0
+			if at(x, y) {
+				idx |= 8
+			}
+			if at(x+1, y) {
+				idx |= 4
+			}
+			if at(x+1, y+1) {
+				idx |= 2
+			}
+			if at(x, y+1) {
+				idx |= 1
+			}
+			top, bottom := hEdge(x, y), hEdge(x, y+1)
+			left, right := vEdge(x, y), vEdge(x+1, y)
+			switch idx {
+			case 1, 14:
+				link(left, bottom)
+			case 2, 13:
+				link(bottom, right)
+			case 3, 12:
+				link(left, right)
+			case 4, 11:
+				link(top, right)
+			case 6, 9:
+				link(top, bottom)
+			case 7, 8:
+				link(left, top)
+			case 5: // saddle: treat the centre as outside
+				link(left, top)
+				link(bottom, right)
+			case 10:
+				link(top, right)
+				link(left, bottom)
+			}
+		}
+	}
+
+	pos := func(e int) point {
+		i := e / 2
+		x, y := float64(i%w), float64(i/w)
+		if e%2 == 0 {
+			return point{x + 0.5, y}
+		}
+		return point{x, y + 0.5}
+	}
+
+	visited := make(map[int]bool, len(links))
+	walk := func(start int) []point {
+		line := []point{pos(start)}
+		visited[start] = true
+		prev, cur := -1, start
+		for {
+			next := -1
+			for _, n := range links[cur] {
+				if n != prev && !visited[n] {
+					next = n
+					break
+				}
+			}
+			if next < 0 {
+				// close loops back onto their start
+				for _, n := range links[cur] {
+					if n == start && prev != start && len(line) > 2 {
+						line = append(line, pos(start))
+						break
+					}
+				}
+				return line
+			}
+			visited[next] = true
+			line = append(line, pos(next))
+			prev, cur = cur, next
+		}
+	}
+
+	var lines [][]point
+	// open contours start at the image border, where an edge has one link
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for _, e := range []int{hEdge(x, y), vEdge(x, y)} {
+				if len(links[e]) == 1 && !visited[e] {
+					lines = append(lines, walk(e))
+				}
+			}
+		}
+	}
+	// everything left is a closed loop
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for _, e := range []int{hEdge(x, y), vEdge(x, y)} {
+				if len(links[e]) > 0 && !visited[e] {
+					lines = append(lines, walk(e))
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// ToSVG draws points as SVG polylines over a width×height canvas showing
+// bounds. A new polyline is started wherever consecutive points are more
+// than two pixels apart, so the contours from FindBoundary stay separate.
+func ToSVG(points []complex128, width, height int, bounds coords.Bounds) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height)
+
+	vp := coords.NewViewport(bounds, width, height)
+	var line []string
+	flush := func() {
+		if len(line) > 1 {
+			fmt.Fprintf(&sb, `<polyline fill="none" stroke="black" stroke-width="1" points="%s"/>`+"\n",
+				strings.Join(line, " "))
+		}
+		line = line[:0]
+	}
+	var px, py float64
+	for i, c := range points {
+		x, y := vp.ComplexToPoint(c)
+		if i > 0 && math.Hypot(x-px, y-py) > 2 {
+			flush()
+		}
+		line = append(line, fmt.Sprintf("%.2f,%.2f", x, y))
+		px, py = x, y
+	}
+	flush()
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
@@ -0,0 +1,106 @@
+package boundary
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/cmath"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func TestFindBoundaryDefaultWindow(t *testing.T) {
+	opts, err := render.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := FindBoundary(opts)
+	if len(points) < 1000 {
+		t.Errorf("%d boundary points, want at least 1000", len(points))
+	}
+	for _, c := range points {
+		if cmath.Abs(c) > 2 {
+			t.Fatalf("boundary point %v outside the escape radius", c)
+		}
+	}
+}
+
+func TestMarchSquare(t *testing.T) {
+	// a 2×2 block inside a 4×4 mask traces as one closed loop around it
+	const w, h = 4, 4
+	inside := make([]bool, w*h)
+	for _, i := range []int{5, 6, 9, 10} {
+		inside[i] = true
+	}
+	lines := march(inside, w, h)
+	if len(lines) != 1 {
+		t.Fatalf("%d contours, want 1", len(lines))
+	}
+	line := lines[0]
+	if len(line) < 4 {
+		t.Fatalf("contour of %d points", len(line))
+	}
+	for _, p := range line {
+		if p.x < 0.5 || p.x > 2.5 || p.y < 0.5 || p.y > 2.5 {
+			t.Errorf("vertex %v outside the cells around the block", p)
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	// Every vertex, mapped back into the image, must sit between two
+	// neighbouring pixels of which one counts as inside and one not, so
+	// that the exported lines overlay the render within a pixel.
+	const w, h = 160, 120
+	for _, rotation := range []float64{0, 30} {
+		opts, err := render.New(render.WithSize(w, h), render.WithIterations(200), render.WithRotation(rotation))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := render.Render(context.Background(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vp := res.Options.Viewport()
+		for _, threshold := range []float64{0, 8} {
+			in := func(x, y int) bool {
+				if threshold > 0 {
+					return res.Iters[y*w+x] >= threshold
+				}
+				return res.Inside[y*w+x]
+			}
+			lines := Trace(res, threshold)
+			if len(lines) == 0 {
+				t.Fatalf("rotation %g, threshold %g: no lines", rotation, threshold)
+			}
+			for _, line := range lines {
+				for _, z := range line {
+					fx, fy := vp.ComplexToPoint(z)
+					fx, fy = fx-0.5, fy-0.5 // to sample positions
+					x0, y0 := int(math.Floor(fx)), int(math.Floor(fy))
+					x1, y1 := int(math.Ceil(fx)), int(math.Ceil(fy))
+					if x0 < 0 || y0 < 0 || x1 >= w || y1 >= h {
+						t.Fatalf("rotation %g, threshold %g: vertex %v at (%g,%g) outside the image", rotation, threshold, z, fx, fy)
+					}
+					a := in(x0, y0)
+					if a == in(x1, y0) && a == in(x0, y1) && a == in(x1, y1) {
+						t.Fatalf("rotation %g, threshold %g: vertex %v at (%g,%g) is not on an edge of the mask", rotation, threshold, z, fx, fy)
+					}
+				}
+			}
+		}
+	}
+
+	// a render that discarded its buffers has nothing to trace
+	opts, err := render.New(render.WithSize(w, h), render.WithDiscardBuffers(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := Trace(res, 0); lines != nil {
+		t.Errorf("traced %d lines without buffers", len(lines))
+	}
+}
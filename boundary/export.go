@@ -0,0 +1,128 @@
+package boundary
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Simplify drops vertices from line with the Ramer–Douglas–Peucker
+// method: the result keeps both ends and lies within tol of every
+// dropped vertex. A closed contour keeps its closing vertex.
+func Simplify(line []complex128, tol float64) []complex128 {
+	if len(line) < 3 || !(tol > 0) {
+		return line
+	}
+	keep := make([]bool, len(line))
+	keep[0], keep[len(line)-1] = true, true
+	// a closed contour has no chord to measure from, so it is split at
+	// the vertex farthest from its start
+	if line[0] == line[len(line)-1] {
+		far, best := 0, -1.0
+		for i, z := range line {
+			if d := abs2(z - line[0]); d > best {
+				far, best = i, d
+			}
+		}
+		keep[far] = true
+		rdp(line, 0, far, tol*tol, keep)
+		rdp(line, far, len(line)-1, tol*tol, keep)
+	} else {
+		rdp(line, 0, len(line)-1, tol*tol, keep)
+	}
+	out := make([]complex128, 0, len(line))
+	for i, z := range line {
+		if keep[i] {
+			out = append(out, z)
+		}
+	}
+	return out
+}
+
+// rdp marks in keep the vertices strictly between lo and hi that the
+// simplification of line[lo:hi+1] keeps, tolSq being the squared
+// tolerance. It recurses on the far side of each vertex it keeps.
+func rdp(line []complex128, lo, hi int, tolSq float64, keep []bool) {
+	for hi-lo > 1 {
+		a, b := line[lo], line[hi]
+		far, best := -1, tolSq
+		for i := lo + 1; i < hi; i++ {
+			if d := segDistSq(line[i], a, b); d > best {
+				far, best = i, d
+			}
+		}
+		if far < 0 {
+			return
+		}
+		keep[far] = true
+		rdp(line, lo, far, tolSq, keep)
+		lo = far
+	}
+}
+
+// segDistSq is the squared distance from z to the segment from a to b.
+func segDistSq(z, a, b complex128) float64 {
+	d := b - a
+	l := abs2(d)
+	if l == 0 {
+		return abs2(z - a)
+	}
+	t := (real(z-a)*real(d) + imag(z-a)*imag(d)) / l
+	t = math.Max(0, math.Min(1, t))
+	return abs2(z - (a + complex(t, 0)*d))
+}
+
+func abs2(z complex128) float64 { return real(z)*real(z) + imag(z)*imag(z) }
+
+// WriteGeoJSON writes lines as a GeoJSON FeatureCollection holding one
+// MultiLineString, with the real part as the first coordinate and the
+// imaginary part as the second. properties, which may be nil, are
+// attached to the feature. Coordinates are written in full precision.
+func WriteGeoJSON(w io.Writer, lines [][]complex128, properties map[string]any) error {
+	coords := make([][][2]float64, len(lines))
+	for i, line := range lines {
+		coords[i] = make([][2]float64, len(line))
+		for j, z := range line {
+			coords[i][j] = [2]float64{real(z), imag(z)}
+		}
+	}
+	type geometry struct {
+		Type        string         `json:"type"`
+		Coordinates [][][2]float64 `json:"coordinates"`
+	}
+	type feature struct {
+		Type       string         `json:"type"`
+		Geometry   geometry       `json:"geometry"`
+		Properties map[string]any `json:"properties"`
+	}
+	fc := struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{"FeatureCollection", []feature{{"Feature", geometry{"MultiLineString", coords}, properties}}}
+	return json.NewEncoder(w).Encode(fc)
+}
+
+// WriteCSV writes lines as CSV with a header and one row per vertex:
+// the contour index, the vertex index within it and the real and
+// imaginary parts, in full precision.
+func WriteCSV(w io.Writer, lines [][]complex128) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("contour,vertex,re,im\n")
+	var buf []byte
+	for i, line := range lines {
+		for j, z := range line {
+			buf = strconv.AppendInt(buf[:0], int64(i), 10)
+			buf = append(buf, ',')
+			buf = strconv.AppendInt(buf, int64(j), 10)
+			buf = append(buf, ',')
+			buf = strconv.AppendFloat(buf, real(z), 'g', -1, 64)
+			buf = append(buf, ',')
+			buf = strconv.AppendFloat(buf, imag(z), 'g', -1, 64)
+			buf = append(buf, '\n')
+			bw.Write(buf)
+		}
+	}
+	return bw.Flush()
+}
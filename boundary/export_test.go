@@ -0,0 +1,149 @@
+package boundary
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"math/cmplx"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// testLines are a wiggly open line, a closed square with vertices along
+// its sides, and a segment between points that don't survive a short
+// float format.
+var testLines = [][]complex128{
+	{0, 0.1 + 0.01i, 0.2 - 0.01i, 0.3 + 0.005i, 0.4, 0.4 + 0.3i, 0.4 + 0.6i},
+	{
+		-1, -0.5 + 1e-9i, 0, 0.5i, 1i,
+		-0.5 + 1i, -1 + 1i, -1 + 0.5i, -1,
+	},
+	{0.1234567890123456 - 0.9876543210987654i, 1.0 / 3},
+}
+
+func TestSimplify(t *testing.T) {
+	for _, tc := range []struct {
+		tol  float64
+		want [][]complex128
+	}{
+		{0, testLines},
+		// the middle of three vertices in a straight line goes at any
+		// tolerance
+		{0.001, [][]complex128{
+			{0, 0.1 + 0.01i, 0.2 - 0.01i, 0.3 + 0.005i, 0.4, 0.4 + 0.6i},
+			{-1, 0, 1i, -1 + 1i, -1},
+			testLines[2],
+		}},
+		{0.02, [][]complex128{
+			{0, 0.4, 0.4 + 0.6i},
+			{-1, 0, 1i, -1 + 1i, -1},
+			testLines[2],
+		}},
+	} {
+		for i, line := range testLines {
+			got := Simplify(line, tc.tol)
+			if !equalLines(got, tc.want[i]) {
+				t.Errorf("tol %g, line %d: got %v, want %v", tc.tol, i, got, tc.want[i])
+			}
+			// every vertex dropped lies within tol of what is kept
+			for _, z := range line {
+				best := math.Inf(1)
+				for j := 1; j < len(got); j++ {
+					best = min(best, segDistSq(z, got[j-1], got[j]))
+				}
+				if len(got) > 1 && math.Sqrt(best) > tc.tol {
+					t.Errorf("tol %g, line %d: %v is %g from the simplified line", tc.tol, i, z, math.Sqrt(best))
+				}
+			}
+		}
+	}
+}
+
+func equalLines(a, b []complex128) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if cmplx.Abs(a[i]-b[i]) > 1e-15 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteGeoJSON(&buf, testLines, map[string]any{"iters": 100}); err != nil {
+		t.Fatal(err)
+	}
+	var fc struct {
+		Type     string
+		Features []struct {
+			Type     string
+			Geometry struct {
+				Type        string
+				Coordinates [][][2]float64
+			}
+			Properties map[string]any
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &fc); err != nil {
+		t.Fatal(err)
+	}
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 1 {
+		t.Fatalf("%s of %d features, want a FeatureCollection of 1", fc.Type, len(fc.Features))
+	}
+	f := fc.Features[0]
+	if f.Type != "Feature" || f.Geometry.Type != "MultiLineString" || f.Properties["iters"] != 100.0 {
+		t.Errorf("%s of a %s with properties %v", f.Type, f.Geometry.Type, f.Properties)
+	}
+	if len(f.Geometry.Coordinates) != len(testLines) {
+		t.Fatalf("%d lines, want %d", len(f.Geometry.Coordinates), len(testLines))
+	}
+	for i, line := range testLines {
+		got := f.Geometry.Coordinates[i]
+		if len(got) != len(line) {
+			t.Fatalf("line %d: %d vertices, want %d", i, len(got), len(line))
+		}
+		for j, z := range line {
+			// full precision: the coordinates come back exactly
+			if got[j] != [2]float64{real(z), imag(z)} {
+				t.Errorf("line %d vertex %d: %v, want %v", i, j, got[j], z)
+			}
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testLines); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"contour", "vertex", "re", "im"}; len(rows) == 0 || !slices.Equal(rows[0], want) {
+		t.Fatalf("header %v, want %v", rows[:min(len(rows), 1)], want)
+	}
+	rows = rows[1:]
+	for i, line := range testLines {
+		for j, z := range line {
+			if len(rows) == 0 {
+				t.Fatalf("line %d vertex %d missing", i, j)
+			}
+			row := rows[0]
+			rows = rows[1:]
+			re, _ := strconv.ParseFloat(row[2], 64)
+			im, _ := strconv.ParseFloat(row[3], 64)
+			if row[0] != strconv.Itoa(i) || row[1] != strconv.Itoa(j) || complex(re, im) != z {
+				t.Errorf("row %v, want %d,%d,%v", row, i, j, z)
+			}
+		}
+	}
+	if len(rows) != 0 {
+		t.Errorf("%d rows too many", len(rows))
+	}
+}
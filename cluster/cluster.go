@@ -0,0 +1,216 @@
+// Package cluster splits a render across machines: workers render row
+// ranges on request over HTTP, and a coordinator hands the ranges out and
+// assembles the image.
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// Path is where workers accept row range requests.
+const Path = "/rows"
+
+// RowRangeRequest asks a worker for rows [RowStart, RowEnd) of a
+// Width×Height render of Bounds.
+type RowRangeRequest struct {
+	Bounds   Bounds `json:"bounds"`
+	Iters    int    `json:"iters"`
+	Palette  string `json:"palette"`
+	Coloring string `json:"coloring,omitempty"` // "" means the default
+	RowStart int    `json:"row_start"`
+	RowEnd   int    `json:"row_end"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+// Bounds is coords.Bounds with JSON field names.
+type Bounds struct {
+	Xmin float64 `json:"xmin"`
+	Xmax float64 `json:"xmax"`
+	Ymin float64 `json:"ymin"`
+	Ymax float64 `json:"ymax"`
+}
+
+// RowRangeResponse is a worker's answer: the RGBA pixels of rows
+// [RowStart, RowEnd), row-major with no padding. On the wire the body is
+// Pix alone; the rows are those of the request.
+type RowRangeResponse struct {
+	RowStart, RowEnd int
+	Pix              []byte
+}
+
+// Options returns the render options described by req.
+func (req RowRangeRequest) Options() (render.Options, error) {
+	coloring := render.Coloring(req.Coloring)
+	if coloring == "" {
+		coloring = render.DefaultColoring
+	}
+	b := req.Bounds
+	return render.New(
+		render.WithSize(req.Width, req.Height),
+		render.WithViewport(coords.Bounds{Xmin: b.Xmin, Xmax: b.Xmax, Ymin: b.Ymin, Ymax: b.Ymax}),
+		render.WithIterations(req.Iters),
+		render.WithPaletteName(req.Palette),
+		render.WithColoring(coloring),
+		render.WithDiscardBuffers(true),
+	)
+}
+
+// Handler serves POST Path for a worker: it renders the requested rows
+// and replies with their raw RGBA bytes. Invalid requests get a 400; a
+// client that goes away cancels the render.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+Path, func(w http.ResponseWriter, r *http.Request) {
+		var req RowRangeRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts, err := req.Options()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		img, err := render.RenderRows(r.Context(), opts, req.RowStart, req.RowEnd)
+		switch {
+		case errors.Is(err, render.ErrCancelled):
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(img.Pix)
+	})
+	return mux
+}
+
+// Coordinator spreads a render over Workers, base URLs such as
+// "http://host1:8081".
+type Coordinator struct {
+	Workers []string
+	Client  *http.Client // nil means http.DefaultClient
+
+	// RowsPerRequest is the size of each row range; 0 means enough for
+	// about four requests per worker.
+	RowsPerRequest int
+}
+
+// Render renders job, ignoring its row range, by sending row ranges to
+// the workers and assembling their answers. Each worker has one request
+// in flight at a time, so faster workers take more ranges. A range whose
+// request fails is retried on another worker; a worker that fails is
+// dropped, and Render fails only once no worker is left.
+func (c *Coordinator) Render(ctx context.Context, job RowRangeRequest) (*image.RGBA, error) {
+	if len(c.Workers) == 0 {
+		return nil, errors.New("cluster: no workers")
+	}
+	if _, err := job.Options(); err != nil {
+		return nil, err
+	}
+	chunk := c.RowsPerRequest
+	if chunk <= 0 {
+		chunk = max(1, job.Height/(4*len(c.Workers)))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pending := make(chan RowRangeRequest, job.Height/chunk+1)
+	for y := 0; y < job.Height; y += chunk {
+		req := job
+		req.RowStart, req.RowEnd = y, min(y+chunk, job.Height)
+		pending <- req
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, job.Width, job.Height))
+	var (
+		mu        sync.Mutex
+		remaining = len(pending)
+		alive     = len(c.Workers)
+		errs      []error
+		wg        sync.WaitGroup
+	)
+	for _, worker := range c.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var req RowRangeRequest
+				select {
+				case req = <-pending:
+				case <-ctx.Done():
+					return
+				}
+				resp, err := c.fetch(ctx, worker, req)
+				mu.Lock()
+				if err != nil {
+					pending <- req // someone else's turn
+					errs = append(errs, fmt.Errorf("%s: %w", worker, err))
+					if alive--; alive == 0 {
+						cancel()
+					}
+					mu.Unlock()
+					return
+				}
+				copy(img.Pix[resp.RowStart*img.Stride:], resp.Pix)
+				if remaining--; remaining == 0 {
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if remaining > 0 {
+		if err := ctx.Err(); err != nil && len(errs) == 0 {
+			return nil, err
+		}
+		return nil, errors.Join(errs...)
+	}
+	return img, nil
+}
+
+// fetch asks worker for the rows of req.
+func (c *Coordinator) fetch(ctx context.Context, worker string, req RowRangeRequest) (RowRangeResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return RowRangeResponse{}, err
+	}
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(worker, "/")+Path, bytes.NewReader(body))
+	if err != nil {
+		return RowRangeResponse{}, err
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(hreq)
+	if err != nil {
+		return RowRangeResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return RowRangeResponse{}, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	want := (req.RowEnd - req.RowStart) * req.Width * 4
+	pix := make([]byte, want)
+	if _, err := io.ReadFull(resp.Body, pix); err != nil {
+		return RowRangeResponse{}, fmt.Errorf("rows [%d, %d): %w", req.RowStart, req.RowEnd, err)
+	}
+	return RowRangeResponse{RowStart: req.RowStart, RowEnd: req.RowEnd, Pix: pix}, nil
+}
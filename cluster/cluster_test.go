@@ -0,0 +1,92 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+var testJob = RowRangeRequest{
+	Bounds:  Bounds{Xmin: -2, Xmax: 1, Ymin: -1, Ymax: 1},
+	Iters:   200,
+	Palette: render.DefaultPalette,
+	Width:   90,
+	Height:  61, // not a multiple of the range size
+}
+
+func TestCoordinatorMatchesLocalRender(t *testing.T) {
+	a, b := httptest.NewServer(Handler()), httptest.NewServer(Handler())
+	defer a.Close()
+	defer b.Close()
+
+	opts, err := testJob.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rows := range []int{0, 1, 7, 100} {
+		c := &Coordinator{Workers: []string{a.URL, b.URL + "/"}, RowsPerRequest: rows}
+		img, err := c.Render(context.Background(), testJob)
+		if err != nil {
+			t.Fatalf("%d rows a request: %v", rows, err)
+		}
+		if !bytes.Equal(img.Pix, want.Image.Pix) {
+			t.Errorf("%d rows a request: the image differs from a local render", rows)
+		}
+	}
+}
+
+func TestCoordinatorDropsFailingWorker(t *testing.T) {
+	good := httptest.NewServer(Handler())
+	defer good.Close()
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "out of order", http.StatusInternalServerError)
+	}))
+	defer bad.Close()
+
+	c := &Coordinator{Workers: []string{bad.URL, good.URL}, RowsPerRequest: 8}
+	img, err := c.Render(context.Background(), testJob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, _ := testJob.Options()
+	want, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(img.Pix, want.Image.Pix) {
+		t.Error("the image differs from a local render")
+	}
+
+	c.Workers = []string{bad.URL}
+	if _, err := c.Render(context.Background(), testJob); err == nil {
+		t.Error("no error with only a failing worker")
+	}
+}
+
+func TestHandlerRejectsBadRequests(t *testing.T) {
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+	for _, body := range []string{
+		"{",
+		`{"width": 0, "height": 10, "iters": 100, "bounds": {"xmin": -2, "xmax": 1, "ymin": -1, "ymax": 1}}`,
+		`{"width": 10, "height": 10, "iters": 100, "palette": "Nope", "bounds": {"xmin": -2, "xmax": 1, "ymin": -1, "ymax": 1}, "row_end": 10}`,
+		`{"width": 10, "height": 10, "iters": 100, "palette": "MonochromeSlate", "bounds": {"xmin": -2, "xmax": 1, "ymin": -1, "ymax": 1}, "row_start": 5, "row_end": 20}`,
+	} {
+		resp, err := http.Post(srv.URL+Path, "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", body, resp.StatusCode)
+		}
+	}
+}
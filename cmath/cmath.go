@@ -0,0 +1,30 @@
+// Package cmath provides small complex128 helpers used by the fractal kernels and colorings.
+package cmath
+
+import "math"
+
+// Abs returns the magnitude |z|.
+func Abs(z complex128) float64 {
+	return math.Hypot(real(z), imag(z))
+}
+
+// AbsSq returns |z|², avoiding the square root when only comparisons are needed.
+func AbsSq(z complex128) float64 {
+	return real(z)*real(z) + imag(z)*imag(z)
+}
+
+// Arg returns the argument of z in (-π, π].
+func Arg(z complex128) float64 {
+	return math.Atan2(imag(z), real(z))
+}
+
+// PolarToComplex returns r·e^(iθ).
+func PolarToComplex(r, theta float64) complex128 {
+	s, c := math.Sincos(theta)
+	return complex(r*c, r*s)
+}
+
+// ComplexToPolar returns the magnitude and argument of z.
+func ComplexToPolar(z complex128) (r, theta float64) {
+	return Abs(z), Arg(z)
+}
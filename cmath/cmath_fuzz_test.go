@@ -0,0 +1,31 @@
+package cmath
+
+import (
+	"math"
+	"testing"
+)
+
+func FuzzAbs(f *testing.F) {
+	for _, z := range []complex128{
+		0, 3 + 4i, -1e308 - 1e308i, 5e-324 + 5e-324i,
+		complex(math.NaN(), 1), complex(math.Inf(1), math.NaN()), complex(math.Inf(-1), 0),
+	} {
+		f.Add(real(z), imag(z))
+	}
+
+	f.Fuzz(func(t *testing.T, re, im float64) {
+		abs := Abs(complex(re, im))
+		switch {
+		case math.IsInf(re, 0) || math.IsInf(im, 0):
+			if !math.IsInf(abs, 1) {
+				t.Fatalf("Abs(%v, %v) = %v, want +Inf", re, im, abs)
+			}
+		case math.IsNaN(re) || math.IsNaN(im):
+			if !math.IsNaN(abs) {
+				t.Fatalf("Abs(%v, %v) = %v, want NaN", re, im, abs)
+			}
+		case !(abs >= max(math.Abs(re), math.Abs(im))):
+			t.Fatalf("Abs(%v, %v) = %v, less than a part", re, im, abs)
+		}
+	})
+}
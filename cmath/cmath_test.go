@@ -0,0 +1,30 @@
+package cmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKnownValues(t *testing.T) {
+	if got := Abs(3 + 4i); got != 5 {
+		t.Errorf("Abs(3+4i) = %v, want 5", got)
+	}
+	if got := AbsSq(3 + 4i); got != 25 {
+		t.Errorf("AbsSq(3+4i) = %v, want 25", got)
+	}
+	if got := Arg(1i); got != math.Pi/2 {
+		t.Errorf("Arg(i) = %v, want π/2", got)
+	}
+	if got := PolarToComplex(1, 0); got != 1 {
+		t.Errorf("PolarToComplex(1, 0) = %v, want 1", got)
+	}
+}
+
+func TestPolarRoundTrip(t *testing.T) {
+	for _, z := range []complex128{1, -1, 1i, -2 - 3i, 0.25 + 1e-9i, 1e10 - 1e-10i} {
+		r, theta := ComplexToPolar(z)
+		if back := PolarToComplex(r, theta); Abs(back-z) > 1e-12*Abs(z) {
+			t.Errorf("%v comes back as %v", z, back)
+		}
+	}
+}
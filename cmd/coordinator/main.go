@@ -0,0 +1,77 @@
+// Command coordinator renders an image on a set of cmd/worker processes,
+// handing each a range of rows and assembling the results.
+//
+//	go run ./cmd/coordinator -workers http://host1:8081,http://host2:8081 -outfile out.png
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/cluster"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func main() {
+	workers := flag.String("workers", "", "comma-separated worker base URLs")
+	width := flag.Int("width", render.DefaultWidth, "image width in pixels")
+	height := flag.Int("height", render.DefaultHeight, "image height in pixels")
+	xmin := flag.Float64("xmin", render.DefaultBounds.Xmin, "left x coordinate")
+	xmax := flag.Float64("xmax", render.DefaultBounds.Xmax, "right x coordinate")
+	ymin := flag.Float64("ymin", render.DefaultBounds.Ymin, "bottom y coordinate")
+	ymax := flag.Float64("ymax", render.DefaultBounds.Ymax, "top y coordinate")
+	iters := flag.Int("iters", render.DefaultMaxIter, "max iteration count")
+	pal := flag.String("palette", render.DefaultPalette, "palette name")
+	coloring := flag.String("coloring", string(render.DefaultColoring), "coloring mode")
+	rows := flag.Int("rows", 0, "rows per request (0 = about four requests per worker)")
+	outfile := flag.String("outfile", "mandelbrot.png", "output PNG filename")
+	flag.Parse()
+
+	if *workers == "" {
+		fmt.Fprintln(os.Stderr, "coordinator: -workers is required")
+		os.Exit(2)
+	}
+	job := cluster.RowRangeRequest{
+		Bounds:   cluster.Bounds{Xmin: *xmin, Xmax: *xmax, Ymin: *ymin, Ymax: *ymax},
+		Iters:    *iters,
+		Palette:  *pal,
+		Coloring: *coloring,
+		Width:    *width,
+		Height:   *height,
+	}
+	opts, err := job.Options()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options:\n%v\n", err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	c := &cluster.Coordinator{Workers: strings.Split(*workers, ","), RowsPerRequest: *rows}
+	start := time.Now()
+	img, err := c.Render(ctx, job)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	out, err := os.Create(*outfile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := render.EncodeWithText(out, img, "png", render.Metadata(opts)); err != nil {
+		out.Close()
+		os.Remove(*outfile)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out.Close()
+	fmt.Printf("Saved %s (%dx%d) from %d workers in %v\n", *outfile, *width, *height, len(c.Workers), time.Since(start).Round(time.Millisecond))
+}
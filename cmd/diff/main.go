@@ -0,0 +1,85 @@
+// Command diff compares two renders pixel by pixel, to check that a
+// change meant to be invisible (a faster kernel, say) is:
+//
+//	go run ./cmd/diff -threshold 1 -out diff.png before.png after.png
+//
+// It prints the number of pixels that differ by more than the threshold
+// in any color channel, the largest difference and the mean absolute
+// error, and writes an image that is red where they differ and green
+// elsewhere. The exit status is 1 if any pixel differs.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+
+	"github.com/whalelogic/mandlebrot/golden"
+	"github.com/whalelogic/mandlebrot/output"
+)
+
+func main() {
+	threshold := flag.Int("threshold", 0, "per-channel difference allowed before a pixel counts as changed")
+	out := flag.String("out", "diff.png", "where to write the diff image, empty for nowhere")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: diff [flags] a.png b.png")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	a, err := loadRGBA(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+	b, err := loadRGBA(flag.Arg(1))
+	if err != nil {
+		fail(err)
+	}
+	if a.Bounds().Size() != b.Bounds().Size() {
+		fmt.Printf("sizes differ: %v and %v\n", a.Bounds().Size(), b.Bounds().Size())
+	}
+	res := golden.DiffImages(a, b, *threshold)
+	fmt.Printf("differing pixels: %d of %d\n", res.DifferingPixels, res.Image.Bounds().Dx()*res.Image.Bounds().Dy())
+	fmt.Printf("max channel delta: %d\n", res.MaxDelta)
+	fmt.Printf("mean abs error:    %.4f\n", res.MeanAbsError)
+	if *out != "" {
+		if err := output.WriteFile(*out, func(w io.Writer) error { return png.Encode(w, res.Image) }); err != nil {
+			fail(err)
+		}
+	}
+	if res.DifferingPixels > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadRGBA decodes the PNG at path into an *image.RGBA.
+func loadRGBA(path string) (*image.RGBA, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba, nil
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba, nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "diff:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,43 @@
+// Command golden renders the reference scenes and compares them with the
+// images under testdata/golden, catching accidental changes to the
+// iteration, coloring and palette code.
+//
+//	go run ./cmd/golden                 # compare
+//	go run ./cmd/golden -update-golden  # regenerate after an intended change
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/whalelogic/mandlebrot/golden"
+)
+
+func main() {
+	dir := flag.String("dir", filepath.Join("testdata", "golden"), "golden image directory")
+	update := flag.Bool("update-golden", false, "regenerate the golden files instead of comparing")
+	tolerance := flag.Int("tolerance", 1, "per-channel difference allowed before a pixel counts as changed")
+	maxPixels := flag.Int("max-pixels", 10, "changed pixels allowed per scene")
+	flag.Parse()
+
+	// ±1 per channel absorbs float rounding; a handful of pixels may
+	// flip across an iteration boundary between platforms.
+	scenes := append(golden.PaletteScenes(), golden.Scenes()...)
+	failed := false
+	for _, r := range golden.CheckScenes(*dir, scenes, *tolerance, *maxPixels, *update) {
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(os.Stderr, "FAIL %s: %v\n", r.Scene, r.Err)
+			failed = true
+		case *update:
+			fmt.Printf("wrote %s\n", r.Path)
+		default:
+			fmt.Printf("ok   %s (%d pixels beyond tolerance, max delta %d)\n", r.Path, r.Diff.Pixels, r.Diff.MaxDelta)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
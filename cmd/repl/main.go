@@ -0,0 +1,269 @@
+// Command repl explores the Mandelbrot set a line at a time:
+//
+//	$ go run ./cmd/repl
+//	> zoom -0.745 0.113 50
+//	> iters 1000
+//	> render
+//	> save seahorse.png
+//	> quit
+//
+// render draws the view in the terminal with ANSI half blocks; save
+// writes it at full size to a PNG or JPEG file. help lists the commands.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/output"
+	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/render"
+	"github.com/whalelogic/mandlebrot/termimg"
+)
+
+// REPLState is the view the commands act on, kept from one command to
+// the next.
+type REPLState struct {
+	Bounds  coords.Bounds
+	Palette string
+	Iters   int
+
+	// Width and Height size the images save writes, Cols and Rows the
+	// character cells render fills on Out.
+	Width, Height int
+	Cols, Rows    int
+	TrueColor     bool
+	Out           io.Writer
+
+	// Quit is set by the quit command to end the loop.
+	Quit bool
+}
+
+// Command is one parsed command line.
+type Command interface {
+	Execute(*REPLState) error
+}
+
+type zoomCommand struct {
+	center complex128
+	factor float64
+}
+
+// Execute centers the view on the point, factor times smaller.
+func (c zoomCommand) Execute(st *REPLState) error {
+	st.Bounds = st.Bounds.ZoomedTo(c.center, c.factor)
+	fmt.Fprintf(st.Out, "center %.10g%+.10gi, width %.3g\n", real(c.center), imag(c.center), st.Bounds.Width())
+	return nil
+}
+
+type paletteCommand struct{ name string }
+
+// Execute switches to a built-in or registered palette.
+func (c paletteCommand) Execute(st *REPLState) error {
+	if palette.Get(c.name) == nil {
+		return fmt.Errorf("unknown palette %q (want one of %s)", c.name, strings.Join(palette.List(), ", "))
+	}
+	st.Palette = c.name
+	return nil
+}
+
+type itersCommand struct{ n int }
+
+// Execute sets the iteration limit.
+func (c itersCommand) Execute(st *REPLState) error {
+	st.Iters = c.n
+	return nil
+}
+
+type renderCommand struct{}
+
+// Execute draws the view on Out.
+func (renderCommand) Execute(st *REPLState) error {
+	w, h := termimg.CellPixels(st.Cols, st.Rows)
+	res, err := st.render(w, h)
+	if err != nil {
+		return err
+	}
+	return termimg.WriteANSI(st.Out, res.Image, st.TrueColor)
+}
+
+type saveCommand struct{ path string }
+
+// Execute renders the view at Width×Height and writes it to the file,
+// in the format its extension names.
+func (c saveCommand) Execute(st *REPLState) error {
+	format, err := render.FormatFromPath(c.path)
+	if err != nil {
+		return err
+	}
+	res, err := st.render(st.Width, st.Height)
+	if err != nil {
+		return err
+	}
+	err = output.WriteFile(c.path, func(w io.Writer) error { return render.Encode(w, res.Image, format) })
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(st.Out, "wrote %s (%d×%d)\n", c.path, st.Width, st.Height)
+	return nil
+}
+
+type helpCommand struct{}
+
+const replHelp = `zoom X Y F     center on X+Yi, F times closer (F below 1 zooms out)
+palette NAME   switch palette
+iters N        set the iteration limit
+render         draw the view here
+save FILE      write the view to a .png or .jpg file
+help           show this
+quit           leave
+`
+
+// Execute lists the commands.
+func (helpCommand) Execute(st *REPLState) error {
+	_, err := io.WriteString(st.Out, replHelp)
+	return err
+}
+
+type quitCommand struct{}
+
+// Execute ends the loop.
+func (quitCommand) Execute(st *REPLState) error {
+	st.Quit = true
+	return nil
+}
+
+// render renders the view, fitted to a w×h image.
+func (st *REPLState) render(w, h int) (*render.Result, error) {
+	opts, err := render.New(
+		render.WithSize(w, h),
+		render.WithViewport(st.Bounds.FitToImage(w, h)),
+		render.WithIterations(st.Iters),
+		render.WithPaletteName(st.Palette),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return render.Render(context.Background(), opts)
+}
+
+// errBlank is parseCommand's error for a line with no command on it.
+var errBlank = errors.New("blank line")
+
+// parseCommand parses one command line.
+func parseCommand(line string) (Command, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, errBlank
+	}
+	name, args := fields[0], fields[1:]
+	want := func(n int, usage string) error {
+		if len(args) != n {
+			return fmt.Errorf("usage: %s", usage)
+		}
+		return nil
+	}
+	switch name {
+	case "zoom":
+		if err := want(3, "zoom X Y F"); err != nil {
+			return nil, err
+		}
+		var v [3]float64
+		for i, a := range args {
+			f, err := strconv.ParseFloat(a, 64)
+			if err != nil {
+				return nil, fmt.Errorf("zoom: %q is not a number", a)
+			}
+			v[i] = f
+		}
+		if !(v[2] > 0) {
+			return nil, fmt.Errorf("zoom: factor must be positive, got %g", v[2])
+		}
+		return zoomCommand{complex(v[0], v[1]), v[2]}, nil
+	case "palette":
+		if err := want(1, "palette NAME"); err != nil {
+			return nil, err
+		}
+		return paletteCommand{args[0]}, nil
+	case "iters":
+		if err := want(1, "iters N"); err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("iters: want a positive whole number, got %q", args[0])
+		}
+		return itersCommand{n}, nil
+	case "render":
+		return renderCommand{}, want(0, "render")
+	case "save":
+		if err := want(1, "save FILE"); err != nil {
+			return nil, err
+		}
+		return saveCommand{args[0]}, nil
+	case "help":
+		return helpCommand{}, nil
+	case "quit", "exit":
+		return quitCommand{}, nil
+	default:
+		return nil, fmt.Errorf("unknown command %q (try help)", name)
+	}
+}
+
+// run reads commands from in until quit or the end of input, printing a
+// prompt before each and any error after it. Errors don't end the loop;
+// only a failure to read does.
+func run(st *REPLState, in io.Reader) error {
+	sc := bufio.NewScanner(in)
+	for !st.Quit {
+		fmt.Fprint(st.Out, "> ")
+		if !sc.Scan() {
+			fmt.Fprintln(st.Out)
+			break
+		}
+		cmd, err := parseCommand(sc.Text())
+		if err == nil {
+			err = cmd.Execute(st)
+		}
+		if err != nil && err != errBlank {
+			fmt.Fprintln(st.Out, "error:", err)
+		}
+	}
+	return sc.Err()
+}
+
+func main() {
+	width := flag.Int("width", render.DefaultWidth, "width of images written by save")
+	height := flag.Int("height", render.DefaultHeight, "height of images written by save")
+	iters := flag.Int("iters", render.DefaultMaxIter, "initial iteration limit")
+	pal := flag.String("palette", render.DefaultPalette, "initial palette")
+	flag.Parse()
+
+	st := &REPLState{
+		Bounds:    render.DefaultBounds,
+		Palette:   *pal,
+		Iters:     *iters,
+		Width:     *width,
+		Height:    *height,
+		Cols:      80,
+		Rows:      24,
+		TrueColor: termimg.TrueColor(),
+		Out:       os.Stdout,
+	}
+	if cols, rows, err := termimg.Size(os.Stdout); err == nil {
+		// leave a line for the prompt
+		st.Cols, st.Rows = cols, max(rows-1, 1)
+	}
+	if err := run(st, os.Stdin); err != nil {
+		fmt.Fprintln(os.Stderr, "repl:", err)
+		os.Exit(1)
+	}
+}
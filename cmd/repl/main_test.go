@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// newState returns a small REPLState writing to out.
+func newState(out io.Writer) *REPLState {
+	return &REPLState{
+		Bounds:  render.DefaultBounds,
+		Palette: render.DefaultPalette,
+		Iters:   100,
+		Width:   64,
+		Height:  48,
+		Cols:    20,
+		Rows:    6,
+		Out:     out,
+	}
+}
+
+// feed runs the REPL on st with lines written to it through a pipe, as
+// a terminal would type them, and returns what run returned.
+func feed(t *testing.T, st *REPLState, lines ...string) error {
+	t.Helper()
+	pr, pw := io.Pipe()
+	go func() {
+		for _, l := range lines {
+			if _, err := io.WriteString(pw, l+"\n"); err != nil {
+				return // run stopped reading after quit
+			}
+		}
+		pw.Close()
+	}()
+	defer pr.Close()
+	return run(st, pr)
+}
+
+func TestRun(t *testing.T) {
+	var out strings.Builder
+	st := newState(&out)
+	path := filepath.Join(t.TempDir(), "seahorse.png")
+	err := feed(t, st,
+		"zoom -0.745 0.113 50",
+		"",
+		"palette NoSuchPalette",
+		"palette MonochromeSlate",
+		"iters 0",
+		"iters 500",
+		"frobnicate",
+		"render",
+		"save "+path,
+		"help",
+		"quit",
+		"iters 7",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := render.DefaultBounds.ZoomedTo(complex(-0.745, 0.113), 50)
+	if st.Bounds != want {
+		t.Errorf("bounds %+v, want %+v", st.Bounds, want)
+	}
+	if st.Palette != "MonochromeSlate" {
+		t.Errorf("palette %q", st.Palette)
+	}
+	if st.Iters != 500 {
+		t.Errorf("iters %d: the bad line or the one after quit changed it", st.Iters)
+	}
+	if !st.Quit {
+		t.Error("quit not set")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 64 || b.Dy() != 48 {
+		t.Errorf("saved %v, want 64×48", b)
+	}
+
+	s := out.String()
+	for _, w := range []string{
+		"center -0.745+0.113i",
+		`error: unknown palette "NoSuchPalette"`,
+		`error: iters: want a positive whole number, got "0"`,
+		`error: unknown command "frobnicate"`,
+		"\x1b[", // the render
+		"wrote " + path + " (64×48)",
+		"palette NAME   switch palette",
+	} {
+		if !strings.Contains(s, w) {
+			t.Errorf("output lacks %q:\n%s", w, s)
+		}
+	}
+	if n := strings.Count(s, "error:"); n != 3 {
+		t.Errorf("%d errors reported, want 3", n)
+	}
+	// a prompt for every line read, none after quit
+	if n := strings.Count(s, "> "); n != 11 {
+		t.Errorf("%d prompts, want 11", n)
+	}
+}
+
+func TestRunEndOfInput(t *testing.T) {
+	var out strings.Builder
+	st := newState(&out)
+	if err := feed(t, st, "iters 250"); err != nil {
+		t.Fatal(err)
+	}
+	if st.Iters != 250 || st.Quit {
+		t.Errorf("iters %d, quit %v", st.Iters, st.Quit)
+	}
+	if !strings.HasSuffix(out.String(), "> \n") {
+		t.Errorf("output %q doesn't end the last prompt's line", out.String())
+	}
+}
+
+func TestRunReadError(t *testing.T) {
+	pr, pw := io.Pipe()
+	broken := errors.New("broken terminal")
+	go func() {
+		io.WriteString(pw, "iters 300\n")
+		pw.CloseWithError(broken)
+	}()
+	st := newState(io.Discard)
+	if err := run(st, pr); !errors.Is(err, broken) {
+		t.Errorf("run: %v, want %v", err, broken)
+	}
+	if st.Iters != 300 {
+		t.Errorf("iters %d", st.Iters)
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	for _, tc := range []struct {
+		line string
+		want Command
+	}{
+		{"zoom 1 -2 3.5", zoomCommand{complex(1, -2), 3.5}},
+		{"  zoom\t0 0 0.5 ", zoomCommand{0, 0.5}},
+		{"palette Ember", paletteCommand{"Ember"}},
+		{"iters 42", itersCommand{42}},
+		{"render", renderCommand{}},
+		{"save out.jpg", saveCommand{"out.jpg"}},
+		{"help", helpCommand{}},
+		{"quit", quitCommand{}},
+		{"exit", quitCommand{}},
+	} {
+		got, err := parseCommand(tc.line)
+		if err != nil || got != tc.want {
+			t.Errorf("%q: %#v, %v; want %#v", tc.line, got, err, tc.want)
+		}
+	}
+	if _, err := parseCommand("   "); err != errBlank {
+		t.Errorf("blank line: %v", err)
+	}
+	for _, line := range []string{
+		"zoom 1 2", "zoom 1 2 x", "zoom 1 2 0", "zoom 1 2 -3", "zoom 1 2 NaN",
+		"palette", "palette a b", "iters", "iters -1", "iters 1.5",
+		"render now", "save", "save a b", "Zoom 1 2 3",
+	} {
+		if _, err := parseCommand(line); err == nil || err == errBlank {
+			t.Errorf("%q: %v", line, err)
+		}
+	}
+}
+
+func TestSaveUnknownFormat(t *testing.T) {
+	st := newState(io.Discard)
+	path := filepath.Join(t.TempDir(), "view.tiff")
+	if err := (saveCommand{path}).Execute(st); err == nil {
+		t.Error("no error")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file written: %v", err)
+	}
+}
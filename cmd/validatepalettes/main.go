@@ -0,0 +1,94 @@
+// Command validatepalettes checks every built-in palette with
+// palette.Validate and exits with status 1 if any fails. With -lut it
+// also writes the Go source of their lookup tables, which is how
+// palette/palettes_gen.go is made:
+//
+//	go generate ./palette
+//
+// Each table holds palette.LUTSize samples of the exact blend of the
+// stops, and the stops' fingerprint, so a table left over from before a
+// palette changed goes unused. Only opaque palettes get one.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+
+	"github.com/whalelogic/mandlebrot/output"
+	"github.com/whalelogic/mandlebrot/palette"
+)
+
+func main() {
+	lutPath := flag.String("lut", "", "also write the lookup tables of the palettes to this Go file")
+	flag.Parse()
+
+	failed := false
+	for _, cm := range palette.ColorPalettes {
+		if err := palette.Validate(cm); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+	if *lutPath == "" {
+		return
+	}
+	src, err := lutSource(palette.ColorPalettes)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "validatepalettes:", err)
+		os.Exit(1)
+	}
+	if err := output.WriteFile(*lutPath, func(w io.Writer) error {
+		_, err := w.Write(src)
+		return err
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "validatepalettes:", err)
+		os.Exit(1)
+	}
+}
+
+// lutSource returns the formatted source of palettes_gen.go for maps.
+func lutSource(maps []palette.ColorMap) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by go run ../cmd/validatepalettes -lut palettes_gen.go; DO NOT EDIT.\n\n")
+	b.WriteString("package palette\n\n")
+	b.WriteString("// builtinLUTs holds the lookup table of each opaque built-in palette,\n")
+	b.WriteString("// by keyword; see attachLUT.\n")
+	b.WriteString("var builtinLUTs = map[string]generatedLUT{\n")
+	for _, cm := range maps {
+		if !opaque(cm) {
+			continue
+		}
+		// a fresh map has no table, so Interpolate blends the stops
+		exact := palette.ColorMap{Keyword: cm.Keyword, Colors: cm.Colors}
+		fmt.Fprintf(&b, "%q: {\nfingerprint: %q,\ntable: &lut{\n", cm.Keyword, cm.Fingerprint())
+		for i := range palette.LUTSize {
+			c := exact.Interpolate(float64(i) / (palette.LUTSize - 1))
+			fmt.Fprintf(&b, "{0x%02x, 0x%02x, 0x%02x, 0x%02x},", c.R, c.G, c.B, c.A)
+			if i%4 == 3 {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString("},\n},\n")
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+// opaque reports whether every stop of cm is opaque.
+func opaque(cm palette.ColorMap) bool {
+	for _, c := range cm.Colors {
+		if _, _, _, a := c.Color.RGBA(); a != 0xffff {
+			return false
+		}
+	}
+	return true
+}
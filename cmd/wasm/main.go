@@ -0,0 +1,107 @@
+//go:build js && wasm
+
+// Command wasm exposes the renderer to JavaScript. It registers a global
+// renderTile(paramsJSON) function that renders one view and returns its
+// pixels as a Uint8ClampedArray ready for ImageData/putImageData.
+//
+// Build with
+//
+//	GOOS=js GOARCH=wasm go build -o examples/wasm/mandelbrot.wasm ./cmd/wasm
+//
+// and serve examples/wasm together with wasm_exec.js from
+// $(go env GOROOT)/lib/wasm.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// tileParams is the JSON accepted by renderTile. Omitted fields take the
+// render package defaults.
+type tileParams struct {
+	Width    int     `json:"width"`
+	Height   int     `json:"height"`
+	Xmin     float64 `json:"xmin"`
+	Xmax     float64 `json:"xmax"`
+	Ymin     float64 `json:"ymin"`
+	Ymax     float64 `json:"ymax"`
+	Rotation float64 `json:"rotation"`
+	Iters    int     `json:"iters"`
+	Bailout  float64 `json:"bailout"`
+	Palette  string  `json:"palette"`
+	Coloring string  `json:"coloring"`
+	Fractal  string  `json:"fractal"`
+	JuliaRe  float64 `json:"juliaRe"`
+	JuliaIm  float64 `json:"juliaIm"`
+	Procs    int     `json:"procs"`
+}
+
+func defaultParams() tileParams {
+	return tileParams{
+		Width:    render.DefaultWidth,
+		Height:   render.DefaultHeight,
+		Xmin:     render.DefaultBounds.Xmin,
+		Xmax:     render.DefaultBounds.Xmax,
+		Ymin:     render.DefaultBounds.Ymin,
+		Ymax:     render.DefaultBounds.Ymax,
+		Iters:    render.DefaultMaxIter,
+		Bailout:  render.DefaultBailout,
+		Palette:  render.DefaultPalette,
+		Coloring: string(render.DefaultColoring),
+		Fractal:  "mandelbrot",
+		JuliaRe:  -0.8,
+		JuliaIm:  0.156,
+		Procs:    1,
+	}
+}
+
+func main() {
+	js.Global().Set("renderTile", js.FuncOf(renderTile))
+	// keep the Go runtime alive for later calls
+	select {}
+}
+
+// renderTile renders the view described by args[0], a JSON string, and
+// returns its RGBA pixels row by row. On failure it returns an Error
+// object instead.
+func renderTile(this js.Value, args []js.Value) any {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return jsError("renderTile: want a single JSON string argument")
+	}
+	p := defaultParams()
+	if err := json.Unmarshal([]byte(args[0].String()), &p); err != nil {
+		return jsError("renderTile: " + err.Error())
+	}
+	opts, err := render.New(
+		render.WithSize(p.Width, p.Height),
+		render.WithViewport(coords.Bounds{Xmin: p.Xmin, Xmax: p.Xmax, Ymin: p.Ymin, Ymax: p.Ymax}),
+		render.WithRotation(p.Rotation),
+		render.WithIterations(p.Iters),
+		render.WithBailout(p.Bailout),
+		render.WithPaletteName(p.Palette),
+		render.WithColoring(render.Coloring(p.Coloring)),
+		render.WithFractalName(p.Fractal, complex(p.JuliaRe, p.JuliaIm)),
+		render.WithProcs(p.Procs),
+		render.WithDiscardBuffers(true),
+	)
+	if err != nil {
+		return jsError("renderTile: " + err.Error())
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		return jsError("renderTile: " + err.Error())
+	}
+	pix := res.Image.Pix
+	buf := js.Global().Get("Uint8ClampedArray").New(len(pix))
+	js.CopyBytesToJS(js.Global().Get("Uint8Array").New(buf.Get("buffer")), pix)
+	return buf
+}
+
+func jsError(msg string) js.Value {
+	return js.Global().Get("Error").New(msg)
+}
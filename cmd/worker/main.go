@@ -0,0 +1,20 @@
+// Command worker renders row ranges for cmd/coordinator.
+//
+//	go run ./cmd/worker -listen :8081
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/whalelogic/mandlebrot/cluster"
+)
+
+func main() {
+	listen := flag.String("listen", ":8081", "address to listen on")
+	flag.Parse()
+
+	log.Printf("worker listening on %s", *listen)
+	log.Fatal(http.ListenAndServe(*listen, cluster.Handler()))
+}
@@ -0,0 +1,92 @@
+// Package coords maps between image pixels and the complex plane.
+package coords
+
+import (
+	"image"
+	"math"
+)
+
+// Bounds is a rectangular window on the complex plane.
+type Bounds struct {
+	Xmin, Xmax, Ymin, Ymax float64
+}
+
+// Width returns the real-axis extent of b.
+func (b Bounds) Width() float64 { return b.Xmax - b.Xmin }
+
+// Height returns the imaginary-axis extent of b.
+func (b Bounds) Height() float64 { return b.Ymax - b.Ymin }
+
+// Center returns the midpoint of b.
+func (b Bounds) Center() complex128 {
+	return complex((b.Xmin+b.Xmax)/2, (b.Ymin+b.Ymax)/2)
+}
+
+// Contains reports whether c lies inside b (edges included).
+func (b Bounds) Contains(c complex128) bool {
+	return real(c) >= b.Xmin && real(c) <= b.Xmax && imag(c) >= b.Ymin && imag(c) <= b.Ymax
+}
+
+// Scale multiplies both extents of b by factor, keeping the center fixed.
+// A factor below 1 shrinks the window (zooms in).
+func (b Bounds) Scale(factor float64) Bounds {
+	return centered(b.Center(), b.Width()*factor, b.Height()*factor)
+}
+
+// ZoomedTo returns a window centered on center and factor times smaller
+// than b in each direction. A factor above 1 zooms in.
+func (b Bounds) ZoomedTo(center complex128, factor float64) Bounds {
+	return centered(center, b.Width()/factor, b.Height()/factor)
+}
+
+// AspectRatio returns Width/Height.
+func (b Bounds) AspectRatio() float64 { return b.Width() / b.Height() }
+
+// FitToImage widens whichever extent of b is too narrow so that b has the
+// aspect ratio of a width×height image, keeping the center fixed. Pixels
+// then come out square.
+func (b Bounds) FitToImage(width, height int) Bounds {
+	target := float64(width) / float64(height)
+	w, h := b.Width(), b.Height()
+	if w/h < target {
+		w = h * target
+	} else {
+		h = w / target
+	}
+	return centered(b.Center(), w, h)
+}
+
+// centered returns the w×h window centered on c.
+func centered(c complex128, w, h float64) Bounds {
+	return Bounds{
+		Xmin: real(c) - w/2, Xmax: real(c) + w/2,
+		Ymin: imag(c) - h/2, Ymax: imag(c) + h/2,
+	}
+}
+
+// PixelToComplex maps pixel (x, y) of a width×height image showing bounds
+// to the complex plane, sampling the pixel's center: pixel (0, 0) maps
+// to half a pixel in from (Xmin, Ymax), the top-left corner.
+func PixelToComplex(x, y, width, height int, bounds Bounds) complex128 {
+	return NewViewport(bounds, width, height).PixelToComplex(x, y)
+}
+
+// ComplexToPixel is the inverse of PixelToComplex: it returns the pixel
+// whose area contains c. inside is false when c lies outside bounds, in
+// which case x and y are still the (out of range) pixel coordinates.
+func ComplexToPixel(c complex128, width, height int, bounds Bounds) (x, y int, inside bool) {
+	return NewViewport(bounds, width, height).ComplexToPixel(c)
+}
+
+// PixelRect returns the pixel rectangle of a width×height image showing
+// bounds that would remain visible after zooming in by zoom around c.
+// The rectangle is not clipped to the image.
+func PixelRect(c complex128, width, height int, bounds Bounds, zoom float64) image.Rectangle {
+	fx, fy := NewViewport(bounds, width, height).ComplexToPoint(c)
+	hw := float64(width) / (2 * zoom)
+	hh := float64(height) / (2 * zoom)
+	return image.Rect(
+		int(math.Round(fx-hw)), int(math.Round(fy-hh)),
+		int(math.Round(fx+hw)), int(math.Round(fy+hh)),
+	)
+}
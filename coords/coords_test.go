@@ -0,0 +1,77 @@
+package coords
+
+import (
+	"math"
+	"testing"
+)
+
+var testBounds = Bounds{Xmin: -2.2, Xmax: 1.0, Ymin: -1.6, Ymax: 1.6}
+
+func TestPixelToComplexCorner(t *testing.T) {
+	const w, h = 160, 120
+	// pixel (0, 0) is the top-left corner, at Ymax, sampled at its center
+	c := PixelToComplex(0, 0, w, h, testBounds)
+	dx, dy := testBounds.Width()/w, testBounds.Height()/h
+	if math.Abs(real(c)-(testBounds.Xmin+dx/2)) > 1e-12 || math.Abs(imag(c)-(testBounds.Ymax-dy/2)) > 1e-12 {
+		t.Errorf("PixelToComplex(0, 0) = %v, want half a pixel in from %v", c, complex(testBounds.Xmin, testBounds.Ymax))
+	}
+	if last := PixelToComplex(w-1, h-1, w, h, testBounds); math.Abs(real(last)-(testBounds.Xmax-dx/2)) > 1e-12 || math.Abs(imag(last)-(testBounds.Ymin+dy/2)) > 1e-12 {
+		t.Errorf("PixelToComplex(%d, %d) = %v, want half a pixel in from %v", w-1, h-1, last, complex(testBounds.Xmax, testBounds.Ymin))
+	}
+}
+
+func TestPixelRoundTrip(t *testing.T) {
+	for _, size := range [][2]int{{1, 1}, {7, 3}, {160, 120}, {1600, 1200}} {
+		w, h := size[0], size[1]
+		for y := range h {
+			for x := range w {
+				gx, gy, inside := ComplexToPixel(PixelToComplex(x, y, w, h, testBounds), w, h, testBounds)
+				if gx != x || gy != y || !inside {
+					t.Fatalf("%dx%d: pixel (%d, %d) comes back as (%d, %d), inside %v", w, h, x, y, gx, gy, inside)
+				}
+			}
+		}
+	}
+}
+
+func TestComplexToPixelOutside(t *testing.T) {
+	if _, _, inside := ComplexToPixel(complex(2, 0), 160, 120, testBounds); inside {
+		t.Error("2+0i is outside the bounds but reported inside")
+	}
+}
+
+func TestZoomedTo(t *testing.T) {
+	for _, tc := range []struct {
+		center complex128
+		factor float64
+	}{
+		{-0.5, 10},
+		{-0.75 + 0.1i, 1000},
+		{testBounds.Center(), 2},
+		{0.3 - 0.02i, 0.5}, // below 1 zooms out
+	} {
+		b := testBounds.ZoomedTo(tc.center, tc.factor)
+		if d := b.Center() - tc.center; math.Abs(real(d)) > 1e-15 || math.Abs(imag(d)) > 1e-15 {
+			t.Errorf("ZoomedTo(%v, %v): center %v", tc.center, tc.factor, b.Center())
+		}
+		if w := testBounds.Width() / tc.factor; math.Abs(b.Width()-w) > 1e-12*w {
+			t.Errorf("ZoomedTo(%v, %v): width %v, want %v", tc.center, tc.factor, b.Width(), w)
+		}
+		if h := testBounds.Height() / tc.factor; math.Abs(b.Height()-h) > 1e-12*h {
+			t.Errorf("ZoomedTo(%v, %v): height %v, want %v", tc.center, tc.factor, b.Height(), h)
+		}
+		if r := testBounds.AspectRatio(); math.Abs(b.AspectRatio()-r) > 1e-12*r {
+			t.Errorf("ZoomedTo(%v, %v): aspect ratio %v, want %v", tc.center, tc.factor, b.AspectRatio(), r)
+		}
+	}
+}
+
+func TestScaleKeepsCenter(t *testing.T) {
+	b := testBounds.Scale(0.25)
+	if b.Center() != testBounds.Center() {
+		t.Errorf("center moved from %v to %v", testBounds.Center(), b.Center())
+	}
+	if math.Abs(b.Width()-testBounds.Width()/4) > 1e-15 {
+		t.Errorf("width %v, want %v", b.Width(), testBounds.Width()/4)
+	}
+}
@@ -0,0 +1,183 @@
+package coords
+
+import (
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+// Viewport is a window on the complex plane as seen by a Width×Height
+// image: the unrotated window Bounds, turned by Rotation degrees
+// counter-clockwise about its center.
+//
+// All pixel↔plane conversions go through Viewport so the sampling and
+// orientation conventions live in one place. Pixel (x, y) covers the
+// square [x, x+1)×[y, y+1) in pixel space and is sampled at its center,
+// so the image spans Bounds exactly. Row 0 is the top of the image, at
+// Ymax, so the imaginary part grows upwards as it does on paper; FlipY
+// turns the image upside down, putting Ymin at row 0 as renders before
+// this convention did.
+//
+// Positions are measured from the center of the view in whole and half
+// pixels, which are exact, so pixels mirrored about the center of a view
+// sample exactly mirrored points: a view symmetric about the real axis
+// gives a vertically symmetric image.
+type Viewport struct {
+	Bounds        Bounds
+	Rotation      float64 // degrees, counter-clockwise about Bounds.Center()
+	Width, Height int     // image size in pixels
+	FlipY         bool    // row 0 at Ymin instead of Ymax
+
+	// HighPrecision makes PixelToComplex round each unrotated sample
+	// point once, from its exact value, rather than after the few
+	// roundings of the usual arithmetic; see mapPixelHighPrecision. It
+	// costs time per pixel and only matters at deep zoom.
+	HighPrecision bool
+}
+
+// NewViewport returns an unrotated viewport showing b at width×height.
+func NewViewport(b Bounds, width, height int) Viewport {
+	return Viewport{Bounds: b, Width: width, Height: height}
+}
+
+// PixelToComplex maps the center of pixel (x, y) to the plane.
+func (v Viewport) PixelToComplex(x, y int) complex128 {
+	if v.HighPrecision && v.Rotation == 0 {
+		b := v.Bounds
+		re := mapPixelHighPrecision(x, v.Width, b.Xmin, b.Xmax)
+		if v.FlipY {
+			return complex(re, mapPixelHighPrecision(y, v.Height, b.Ymin, b.Ymax))
+		}
+		return complex(re, mapPixelHighPrecision(y, v.Height, b.Ymax, b.Ymin))
+	}
+	return v.PointToComplex(float64(x)+0.5, float64(y)+0.5)
+}
+
+// bigPrecision is the mantissa size mapPixelHighPrecision computes with
+// in math/big: enough for the difference of two float64s whatever their
+// exponents, and a quotient good to far below their last bit.
+const bigPrecision = 2200
+
+// mapPixelHighPrecision returns the center of pixel x of width pixels
+// spanning lo to hi, lo + (x+0.5)·(hi-lo)/width, rounded once from its
+// exact value. lo may exceed hi, for axes that run backwards.
+//
+// The plain mapping rounds the span, the center of the window and the
+// sum, each of which can cost an ulp. Here every step keeps its rounding
+// error as a second float64 (Kahan's compensated arithmetic, with the
+// error terms from FMA and TwoSum), which leaves the result off the
+// exactly rounded one only in the rarest near-ties. Windows narrower than
+// 1e-12, where an ulp is a sizeable part of a pixel, are worked out in
+// math/big instead so the rounding is always the exact one.
+func mapPixelHighPrecision(x, width int, lo, hi float64) float64 {
+	m, n := float64(2*x+1), float64(2*width)
+	if math.Abs(hi-lo) < 1e-12 {
+		prec := uint(bigPrecision)
+		d := new(big.Float).SetPrec(prec).Sub(big.NewFloat(hi), big.NewFloat(lo))
+		d.Mul(d, new(big.Float).SetPrec(prec).SetFloat64(m))
+		d.Quo(d, new(big.Float).SetPrec(prec).SetFloat64(n))
+		d.Add(d, big.NewFloat(lo))
+		f, _ := d.Float64()
+		return f
+	}
+	// each step keeps its rounding error: hi-lo = d+de exactly, then
+	// m(hi-lo) = p+pe and m(hi-lo)/n = q+qe to far below an ulp of q
+	d, de := twoSum(hi, -lo)
+	p := m * d
+	pe := math.FMA(m, d, -p) + m*de
+	q := p / n
+	qe := (math.FMA(-q, n, p) + pe) / n
+	s, se := twoSum(lo, q)
+	return s + (se + qe)
+}
+
+// twoSum returns a+b rounded and its rounding error, exactly.
+func twoSum(a, b float64) (s, e float64) {
+	s = a + b
+	bb := s - a
+	return s, (a - (s - bb)) + (b - bb)
+}
+
+// PointToComplex maps a fractional pixel-space position to the plane.
+func (v Viewport) PointToComplex(fx, fy float64) complex128 {
+	b := v.Bounds
+	dx := (fx - float64(v.Width)/2) * (b.Width() / float64(v.Width))
+	dy := (float64(v.Height)/2 - fy) * (b.Height() / float64(v.Height))
+	if v.FlipY {
+		dy = -dy
+	}
+	d := complex(dx, dy)
+	if v.Rotation != 0 {
+		d *= v.rot()
+	}
+	return b.Center() + d
+}
+
+// ComplexToPoint is the inverse of PointToComplex.
+func (v Viewport) ComplexToPoint(z complex128) (fx, fy float64) {
+	b := v.Bounds
+	d := z - b.Center()
+	if v.Rotation != 0 {
+		d *= cmplx.Conj(v.rot())
+	}
+	dy := imag(d)
+	if v.FlipY {
+		dy = -dy
+	}
+	fx = float64(v.Width)/2 + real(d)/(b.Width()/float64(v.Width))
+	fy = float64(v.Height)/2 - dy/(b.Height()/float64(v.Height))
+	return fx, fy
+}
+
+// ComplexToPixel returns the pixel whose area contains z. inside is false
+// when that pixel falls outside the image.
+func (v Viewport) ComplexToPixel(z complex128) (x, y int, inside bool) {
+	fx, fy := v.ComplexToPoint(z)
+	x = int(math.Floor(fx))
+	y = int(math.Floor(fy))
+	inside = x >= 0 && x < v.Width && y >= 0 && y < v.Height
+	return x, y, inside
+}
+
+// ZoomAt zooms in by factor about z, which stays at the same pixel.
+// A factor below 1 zooms out.
+func (v Viewport) ZoomAt(z complex128, factor float64) Viewport {
+	b := v.Bounds
+	c := z + (b.Center()-z)/complex(factor, 0)
+	v.Bounds = centered(c, b.Width()/factor, b.Height()/factor)
+	return v
+}
+
+// Pan shifts the view by dx, dy pixels: the point that was at pixel
+// (dx, dy) relative to the old origin ends up at the origin.
+func (v Viewport) Pan(dx, dy float64) Viewport {
+	d := complex(dx*v.Bounds.Width()/float64(v.Width), -dy*v.Bounds.Height()/float64(v.Height))
+	if v.FlipY {
+		d = complex(real(d), -imag(d))
+	}
+	if v.Rotation != 0 {
+		d *= v.rot()
+	}
+	v.Bounds = centered(v.Bounds.Center()+d, v.Bounds.Width(), v.Bounds.Height())
+	return v
+}
+
+// Rotate turns the view a further deg degrees counter-clockwise about its center.
+func (v Viewport) Rotate(deg float64) Viewport {
+	v.Rotation = math.Mod(v.Rotation+deg, 360)
+	return v
+}
+
+// FitAspect resizes the view to w×h pixels, widening Bounds as needed so
+// pixels are square.
+func (v Viewport) FitAspect(w, h int) Viewport {
+	v.Width, v.Height = w, h
+	v.Bounds = v.Bounds.FitToImage(w, h)
+	return v
+}
+
+// rot returns e^(iθ) for the view's rotation.
+func (v Viewport) rot() complex128 {
+	s, c := math.Sincos(v.Rotation * math.Pi / 180)
+	return complex(c, s)
+}
@@ -0,0 +1,174 @@
+package coords
+
+import (
+	"math"
+	"math/big"
+	"math/cmplx"
+	"math/rand/v2"
+	"testing"
+)
+
+// testViewports are the same window seen in each orientation.
+func testViewports() []Viewport {
+	v := NewViewport(testBounds, 160, 120)
+	flipped := v
+	flipped.FlipY = true
+	return []Viewport{v, flipped, v.Rotate(30), flipped.Rotate(-135)}
+}
+
+// near reports whether a and b agree to within tol.
+func near(a, b complex128, tol float64) bool { return cmplx.Abs(a-b) <= tol }
+
+func TestViewportPointRoundTrip(t *testing.T) {
+	for _, v := range testViewports() {
+		for _, p := range [][2]float64{{0, 0}, {0.5, 0.5}, {80, 60}, {159.75, 3.25}, {-20, 300}} {
+			fx, fy := v.ComplexToPoint(v.PointToComplex(p[0], p[1]))
+			if math.Abs(fx-p[0]) > 1e-9 || math.Abs(fy-p[1]) > 1e-9 {
+				t.Errorf("rotation %v, flip %v: (%v, %v) comes back as (%v, %v)", v.Rotation, v.FlipY, p[0], p[1], fx, fy)
+			}
+		}
+		for y := range v.Height {
+			for x := range v.Width {
+				if gx, gy, inside := v.ComplexToPixel(v.PixelToComplex(x, y)); gx != x || gy != y || !inside {
+					t.Fatalf("rotation %v, flip %v: pixel (%d, %d) comes back as (%d, %d)", v.Rotation, v.FlipY, x, y, gx, gy)
+				}
+			}
+		}
+	}
+}
+
+func TestViewportZoomAt(t *testing.T) {
+	z := complex(-0.7435, 0.1314)
+	for _, v := range testViewports() {
+		fx, fy := v.ComplexToPoint(z)
+		zoomed := v.ZoomAt(z, 8)
+		// z stays at the same pixel
+		if gx, gy := zoomed.ComplexToPoint(z); math.Abs(gx-fx) > 1e-9 || math.Abs(gy-fy) > 1e-9 {
+			t.Errorf("rotation %v: z moved from (%v, %v) to (%v, %v)", v.Rotation, fx, fy, gx, gy)
+		}
+		// zooms compose by multiplying and undo by the reciprocal
+		twice := v.ZoomAt(z, 2).ZoomAt(z, 4)
+		if !near(twice.Bounds.Center(), zoomed.Bounds.Center(), 1e-15) || math.Abs(twice.Bounds.Width()-zoomed.Bounds.Width()) > 1e-15 {
+			t.Errorf("rotation %v: zooms of 2 then 4 give %+v, one of 8 %+v", v.Rotation, twice.Bounds, zoomed.Bounds)
+		}
+		back := zoomed.ZoomAt(z, 1.0/8)
+		if !near(back.Bounds.Center(), v.Bounds.Center(), 1e-14) || math.Abs(back.Bounds.Width()-v.Bounds.Width()) > 1e-14 {
+			t.Errorf("rotation %v: zooming in and out gives %+v, want %+v", v.Rotation, back.Bounds, v.Bounds)
+		}
+	}
+}
+
+func TestViewportPan(t *testing.T) {
+	for _, v := range testViewports() {
+		// what was at (dx, dy) from the center ends up at the center
+		want := v.PointToComplex(float64(v.Width)/2+12, float64(v.Height)/2-7)
+		panned := v.Pan(12, -7)
+		if got := panned.PointToComplex(float64(v.Width)/2, float64(v.Height)/2); !near(got, want, 1e-12) {
+			t.Errorf("rotation %v, flip %v: center %v after the pan, want %v", v.Rotation, v.FlipY, got, want)
+		}
+		if back := panned.Pan(-5, 3).Pan(-7, 4); !near(back.Bounds.Center(), v.Bounds.Center(), 1e-12) {
+			t.Errorf("rotation %v, flip %v: pans don't add up: %v, want %v", v.Rotation, v.FlipY, back.Bounds.Center(), v.Bounds.Center())
+		}
+	}
+}
+
+func TestViewportRotate(t *testing.T) {
+	v := NewViewport(testBounds, 160, 120)
+	for _, deg := range []float64{0, 30, 90, -45, 200} {
+		if back := v.Rotate(deg).Rotate(-deg); back.Rotation != 0 {
+			t.Errorf("Rotate(%v).Rotate(%v): rotation %v", deg, -deg, back.Rotation)
+		}
+		if got, want := v.Rotate(deg).Rotate(45), v.Rotate(deg+45); !near(got.PixelToComplex(10, 100), want.PixelToComplex(10, 100), 1e-12) {
+			t.Errorf("Rotate(%v).Rotate(45) differs from Rotate(%v)", deg, deg+45)
+		}
+	}
+	// a quarter turn counter-clockwise brings the right edge to the top
+	q := v.Rotate(90)
+	right := v.PointToComplex(float64(v.Width), float64(v.Height)/2)
+	if top := q.PointToComplex(float64(v.Width), float64(v.Height)/2); !near(top-v.Bounds.Center(), (right-v.Bounds.Center())*1i, 1e-12) {
+		t.Errorf("quarter turn: %v, want %v", top, v.Bounds.Center()+(right-v.Bounds.Center())*1i)
+	}
+}
+
+func TestViewportPixelCenters(t *testing.T) {
+	b := Bounds{Xmin: -2.2, Xmax: 1, Ymin: -1.3, Ymax: 1.3}
+	for _, h := range []int{120, 121} {
+		v := NewViewport(b, 160, h)
+		// pixels mirrored about the real axis sample exact conjugates
+		for y := range h {
+			for x := range v.Width {
+				if a, m := v.PixelToComplex(x, y), v.PixelToComplex(x, h-1-y); a != cmplx.Conj(m) {
+					t.Fatalf("height %d: row %d samples %v, row %d %v", h, y, a, h-1-y, m)
+				}
+			}
+		}
+		if h%2 == 1 {
+			if c := v.PixelToComplex(0, h/2); imag(c) != 0 {
+				t.Errorf("height %d: the middle row samples %v, want the real axis", h, c)
+			}
+		}
+		// a pixel samples its center, half a pixel from each edge
+		if got, want := v.PixelToComplex(0, 0), v.PointToComplex(0.5, 0.5); !near(got, want, 1e-12) {
+			t.Errorf("height %d: pixel (0, 0) samples %v, want %v", h, got, want)
+		}
+		if got := v.PointToComplex(0, 0); got != complex(b.Xmin, b.Ymax) {
+			t.Errorf("height %d: the top-left corner is %v", h, got)
+		}
+		if got := v.PointToComplex(160, float64(h)); got != complex(b.Xmax, b.Ymin) {
+			t.Errorf("height %d: the bottom-right corner is %v", h, got)
+		}
+		// a sample comes back to within half a pixel of where it started
+		for _, p := range [][2]float64{{0.5, 0.5}, {17.5, 3.5}, {159.5, float64(h) - 0.5}} {
+			c := v.PointToComplex(p[0], p[1])
+			fx, fy := v.ComplexToPoint(c)
+			x, y, _ := v.ComplexToPixel(c)
+			if math.Abs(fx-float64(x)-0.5) > 0.5 || math.Abs(fy-float64(y)-0.5) > 0.5 {
+				t.Errorf("height %d: %v is at (%v, %v), outside pixel (%d, %d)", h, c, fx, fy, x, y)
+			}
+		}
+	}
+}
+
+// exactPixel is lo + (x+0.5)(hi-lo)/width worked out in math/big and
+// rounded once.
+func exactPixel(x, width int, lo, hi float64) float64 {
+	const prec = 4000
+	d := new(big.Float).SetPrec(prec).Sub(big.NewFloat(hi), big.NewFloat(lo))
+	d.Mul(d, new(big.Float).SetPrec(prec).SetFloat64(float64(2*x+1)))
+	d.Quo(d, new(big.Float).SetPrec(prec).SetFloat64(float64(2*width)))
+	d.Add(d, big.NewFloat(lo))
+	f, _ := d.Float64()
+	return f
+}
+
+func TestHighPrecisionCoords(t *testing.T) {
+	const n = 100
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, width := range []float64{3.2, 1e-6, 1e-13} {
+		var plainOff int
+		for range 5 {
+			// edges placed independently; a window built as c ± w/2
+			// has an exactly representable center, which hides the error
+			x0, y0 := -0.75+rng.Float64()*0.1, 0.1+rng.Float64()*0.05
+			b := Bounds{Xmin: x0, Xmax: x0 + width*(1+rng.Float64()/10), Ymin: y0, Ymax: y0 + width*(1+rng.Float64()/10)}
+			v := NewViewport(b, n, n)
+			hp := v
+			hp.HighPrecision = true
+			for y := range n {
+				for x := range n {
+					want := complex(exactPixel(x, n, b.Xmin, b.Xmax), exactPixel(y, n, b.Ymax, b.Ymin))
+					if got := hp.PixelToComplex(x, y); got != want {
+						t.Fatalf("width %g: pixel (%d, %d) is %v, want %v", width, x, y, got, want)
+					}
+					if v.PixelToComplex(x, y) != want {
+						plainOff++
+					}
+				}
+			}
+		}
+		// the plain mapping misses the exact rounding somewhere in each
+		if plainOff == 0 {
+			t.Errorf("width %g: the plain mapping is exact everywhere", width)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"math"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// cycleContainers are the accepted -container values.
+var cycleContainers = []string{"frames", "gif", "apng"}
+
+// cycleMain runs the "cycle" subcommand: it renders one view and then
+// animates it by sweeping the palette through the escape counts. The
+// fractal is computed once, so each frame only costs a recolor.
+func cycleMain(args []string) {
+	fs := flag.NewFlagSet("cycle", flag.ExitOnError)
+	view := fs.String("view", "default", "view: a name ("+viewNames()+"), bookmark:N or cx,cy,width")
+	width := fs.Int("width", 640, "frame width in pixels")
+	height := fs.Int("height", 480, "frame height in pixels")
+	iters := fs.Int("iters", 500, "iteration count")
+	pal := fs.String("palette", render.DefaultPalette, "palette name (case-sensitive)")
+	frames := fs.Int("frames", 60, "frames per loop")
+	cycles := fs.Int("cycles", 1, "palette sweeps per loop")
+	period := fs.Float64("period", 32, "escape iterations per palette sweep")
+	fps := fs.Int("fps", 25, "playback rate for gif and apng output")
+	out := fs.String("out", "cycle.gif", "output file; for frames, a name with a printf verb for the frame number")
+	container := fs.String("container", "", "output container ("+strings.Join(cycleContainers, ", ")+"); default from -out")
+	bookmarks := fs.String("bookmarks", "bookmarks.txt", "bookmarks file for bookmark:N views")
+	concurrency := fs.Int("procs", runtime.NumCPU(), "concurrent worker count")
+	fs.Parse(args)
+
+	kind := *container
+	if kind == "" {
+		kind = cycleContainer(*out)
+	}
+	switch {
+	case kind != "frames" && kind != "gif" && kind != "apng":
+		fail("", fmt.Errorf("%w: -container %q: not one of %s", render.ErrUnsupportedFormat, kind, strings.Join(cycleContainers, ", ")))
+	case kind == "frames" && !strings.Contains(*out, "%"):
+		fail("", fmt.Errorf("%w: -out %q: frames need a verb such as %%04d for the frame number", render.ErrInvalidOptions, *out))
+	case *frames < 1 || *cycles < 1:
+		fail("", fmt.Errorf("%w: -frames %d, -cycles %d: must be positive", render.ErrInvalidOptions, *frames, *cycles))
+	case !(*period > 0):
+		fail("", fmt.Errorf("%w: -period %g: must be positive", render.ErrInvalidOptions, *period))
+	case *fps < 1 || *fps > 100:
+		fail("", fmt.Errorf("%w: -fps %d: must be within [1,100]", render.ErrInvalidOptions, *fps))
+	}
+	v, err := parseView(*view, *bookmarks, *width, *height)
+	if err != nil {
+		fail("-view: ", err)
+	}
+	opts, err := render.New(
+		render.WithSize(*width, *height),
+		render.WithViewport(v.Bounds(*width, *height)),
+		render.WithIterations(*iters),
+		render.WithPaletteName(*pal),
+		render.WithProcs(*concurrency),
+	)
+	if err != nil {
+		fail("invalid options:\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	res, err := render.Render(ctx, opts)
+	if err != nil {
+		fail("", err)
+	}
+	fmt.Printf("Rendered %dx%d in %v\n", *width, *height, res.Stats.Elapsed.Round(time.Millisecond))
+
+	// phase runs over [0, cycles) so the frame after the last is the first
+	began := time.Now()
+	phase := func(i int) float64 { return float64(*cycles) * float64(i) / float64(*frames) }
+	switch kind {
+	case "frames":
+		err = writeCycleFrames(ctx, res, *out, *frames, *period, phase)
+	case "gif":
+		err = writeCycleGIF(ctx, res, *out, *frames, *fps, *period, phase)
+	case "apng":
+		err = writeCycleAPNG(ctx, res, *out, *frames, *fps, *period, phase)
+	}
+	if err != nil {
+		fail("", err)
+	}
+	fmt.Printf("Wrote %d frames to %s in %v\n", *frames, *out, time.Since(began).Round(time.Millisecond))
+}
+
+// cycleContainer infers the container from the output name.
+func cycleContainer(out string) string {
+	switch {
+	case strings.Contains(out, "%"):
+		return "frames"
+	case strings.EqualFold(filepath.Ext(out), ".gif"):
+		return "gif"
+	default:
+		return "apng"
+	}
+}
+
+// writeCycleFrames writes each frame to its own PNG.
+func writeCycleFrames(ctx context.Context, res *render.Result, out string, frames int, period float64, phase func(int) float64) error {
+	if dir := filepath.Dir(out); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	img := image.NewRGBA(image.Rect(0, 0, res.Options.Width, res.Options.Height))
+	for i := range frames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := res.Cycle(img, period, phase(i)); err != nil {
+			return err
+		}
+		f, err := os.Create(fmt.Sprintf(out, i))
+		if err != nil {
+			return err
+		}
+		err = render.EncodeWithText(f, img, "png", render.Metadata(res.Options))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCycleAPNG writes the loop as an animated PNG.
+func writeCycleAPNG(ctx context.Context, res *render.Result, out string, frames, fps int, period float64, phase func(int) float64) error {
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	apng, err := render.NewAPNGWriter(w, frames, fps, render.Metadata(res.Options))
+	if err != nil {
+		return err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, res.Options.Width, res.Options.Height))
+	for i := range frames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := res.Cycle(img, period, phase(i)); err != nil {
+			return err
+		}
+		if err := apng.WriteFrame(img); err != nil {
+			return err
+		}
+	}
+	if err := apng.Close(); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeCycleGIF writes the loop as an animated GIF. Its 256 colors are
+// the palette sampled evenly, and each pixel takes the sample nearest its
+// cycle position, so no quantizer is needed.
+func writeCycleGIF(ctx context.Context, res *render.Result, out string, frames, fps int, period float64, phase func(int) float64) error {
+	const levels = 256
+	cm := res.Options.Palette
+	colors := make(color.Palette, levels)
+	for k := range colors {
+		colors[k] = cm.Interpolate(float64(k) / (levels - 1))
+	}
+	w, h := res.Options.Width, res.Options.Height
+	anim := &gif.GIF{}
+	for i := range frames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		img := image.NewPaletted(image.Rect(0, 0, w, h), colors)
+		p := phase(i)
+		for j, nu := range res.Iters {
+			if !res.Inside[j] {
+				img.Pix[j] = uint8(math.Round(render.CycleT(nu, period, p) * (levels - 1)))
+			}
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, int(math.Round(100/float64(fps))))
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(f, anim); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
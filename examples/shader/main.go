@@ -0,0 +1,34 @@
+// Command shader is an example pixel shader plugin. It colors pixels the
+// way the built-in smooth coloring does with the default palette, so
+//
+//	go build -buildmode=plugin -o smooth.so ./examples/shader
+//	./mandelbrot -shader smooth.so
+//
+// renders the same image as ./mandelbrot alone. Change Shade to try
+// other colorings.
+package main
+
+import (
+	"image/color"
+
+	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/shader"
+)
+
+// Shader is the variable the renderer looks up. The palette is frozen, as
+// a render freezes its own, so the colors come from the same table.
+var Shader shader.PixelShader = smooth{cm: palette.Get("NebulaSpectre").Frozen()}
+
+// smooth colors a pixel by its smooth escape-time palette position: t is
+// the normalized smooth iteration count raised to 0.8, 0 for the
+// interior, which the palette turns into a color.
+type smooth struct {
+	cm *palette.ColorMap
+}
+
+func (s smooth) Shade(x, y, width, height int, c complex128, iter int, z complex128, t float64) color.RGBA {
+	return s.cm.Interpolate(t)
+}
+
+// main is unused; the package is built with -buildmode=plugin.
+func main() {}
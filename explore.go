@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/render"
+	"github.com/whalelogic/mandlebrot/termimg"
+)
+
+// explorer is the state of the explore subcommand.
+type explorer struct {
+	center    complex128
+	span      float64 // real-axis width of the view
+	iters     int
+	palettes  []string
+	pal       int
+	win       termimg.Window
+	preview   *render.Renderer // 1/4-resolution first pass
+	full      *render.Renderer
+	status    string
+	bookmarks string
+	saveSize  [2]int
+	saved     int
+}
+
+const exploreHelp = "arrows pan · +/- zoom · i/I iterations · p palette · b bookmark · r render · q quit"
+
+// exploreMain runs the "explore" subcommand: an interactive ANSI view of
+// the set driven from the keyboard.
+func exploreMain(args []string) {
+	fs := flag.NewFlagSet("explore", flag.ExitOnError)
+	cx := fs.Float64("cx", real(render.DefaultBounds.Center()), "real part of the initial center")
+	cy := fs.Float64("cy", imag(render.DefaultBounds.Center()), "imaginary part of the initial center")
+	span := fs.Float64("span", render.DefaultBounds.Width(), "initial view width on the real axis")
+	iters := fs.Int("iters", 300, "initial iteration count")
+	bookmarks := fs.String("bookmarks", "bookmarks.txt", "file that 'b' appends the current view to")
+	width := fs.Int("width", render.DefaultWidth, "width of images saved with 'r'")
+	height := fs.Int("height", render.DefaultHeight, "height of images saved with 'r'")
+	fs.Parse(args)
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		fail("", errors.New("explore needs an interactive terminal"))
+	}
+	win, err := termimg.WindowSize(os.Stdout)
+	if err != nil {
+		fail("", err)
+	}
+	opts, err := render.New(render.WithDiscardBuffers(true))
+	if err != nil {
+		fail("", err)
+	}
+	preview, _ := render.NewRenderer(opts)
+	full, _ := render.NewRenderer(opts)
+	defer preview.Close()
+	defer full.Close()
+
+	old, err := term.MakeRaw(fd)
+	if err != nil {
+		fail("", err)
+	}
+	// alternate screen, hidden cursor; undone on the way out
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		term.Restore(fd, old)
+	}()
+
+	e := &explorer{
+		center:    complex(*cx, *cy),
+		span:      *span,
+		iters:     *iters,
+		palettes:  palette.List(),
+		pal:       slices.Index(palette.List(), render.DefaultPalette),
+		win:       win,
+		preview:   preview,
+		full:      full,
+		status:    exploreHelp,
+		bookmarks: *bookmarks,
+		saveSize:  [2]int{*width, *height},
+	}
+	e.run(readKeys(os.Stdin))
+}
+
+// run redraws after every key until 'q'. Each key cancels the frame in
+// flight, so holding an arrow key never queues up stale frames.
+func (e *explorer) run(keys <-chan string) {
+	cancel := e.draw()
+	for k := range keys {
+		cancel()
+		if !e.handle(k) {
+			return
+		}
+		cancel = e.draw()
+	}
+	cancel()
+}
+
+// handle applies one key and reports whether to keep going.
+func (e *explorer) handle(k string) bool {
+	pan := e.span / 10
+	switch k {
+	case "q", "\x03", "\x1b":
+		return false
+	case "left":
+		e.center -= complex(pan, 0)
+	case "right":
+		e.center += complex(pan, 0)
+	case "up":
+		e.center += complex(0, pan)
+	case "down":
+		e.center -= complex(0, pan)
+	case "+", "=":
+		e.span /= 1.5
+	case "-", "_":
+		e.span *= 1.5
+	case "i":
+		e.iters = e.iters * 3 / 2
+	case "I":
+		e.iters = max(e.iters*2/3, 10)
+	case "p":
+		e.pal = (e.pal + 1) % len(e.palettes)
+	case "b":
+		e.status = e.bookmark()
+	case "r":
+		e.status = e.save()
+	}
+	return true
+}
+
+// bounds returns the current view fitted to a w×h image.
+func (e *explorer) bounds(w, h int) coords.Bounds {
+	half := complex(e.span/2, e.span/2*float64(h)/float64(w))
+	return coords.Bounds{
+		Xmin: real(e.center - half), Xmax: real(e.center + half),
+		Ymin: imag(e.center - half), Ymax: imag(e.center + half),
+	}
+}
+
+// draw starts rendering the current view, coarse pass first, and returns
+// a function that cancels it and waits for it to stop.
+func (e *explorer) draw() (cancel func()) {
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	w, h := e.win.ImageSize(termimg.ANSI)
+	vp := coords.NewViewport(e.bounds(w, h), w, h)
+	overrides := []render.Option{
+		render.WithIterations(e.iters),
+		render.WithPaletteName(e.palettes[e.pal]),
+	}
+	go func() {
+		defer close(done)
+		small := coords.NewViewport(vp.Bounds, max(w/4, 1), max(h/4, 1))
+		if res, err := e.preview.Render(ctx, small, overrides...); err == nil {
+			e.show(bilinearUpscale(res.Image, w, h))
+		}
+		if res, err := e.full.Render(ctx, vp, overrides...); err == nil {
+			e.show(res.Image)
+		}
+	}()
+	return func() {
+		stop()
+		<-done
+	}
+}
+
+// show paints img from the top left corner, then the status line.
+func (e *explorer) show(img *image.RGBA) {
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[H")
+	termimg.WriteANSI(crlfWriter{&buf}, img, termimg.TrueColor())
+	os.Stdout.Write(buf.Bytes())
+	e.showStatus()
+}
+
+// showStatus rewrites the bottom line.
+func (e *explorer) showStatus() {
+	fmt.Fprintf(os.Stdout, "\x1b[%d;1H\x1b[0m%.10g%+.10gi  width %.3g  iters %d  %s  │ %s\x1b[K",
+		e.win.Rows, real(e.center), imag(e.center), e.span, e.iters, e.palettes[e.pal], e.status)
+}
+
+// viewOptions returns the options for a w×h render of the current view.
+func (e *explorer) viewOptions(w, h int) (render.Options, error) {
+	return render.New(
+		render.WithSize(w, h),
+		render.WithViewport(e.bounds(w, h)),
+		render.WithIterations(e.iters),
+		render.WithPaletteName(e.palettes[e.pal]),
+	)
+}
+
+// bookmark appends the command that renders the current view to the
+// bookmarks file.
+func (e *explorer) bookmark() string {
+	opts, err := e.viewOptions(e.saveSize[0], e.saveSize[1])
+	if err != nil {
+		return err.Error()
+	}
+	f, err := os.OpenFile(e.bookmarks, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err.Error()
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintln(f, render.BuildReproduceCommand(opts)); err != nil {
+		return err.Error()
+	}
+	return "bookmarked to " + e.bookmarks
+}
+
+// save renders the current view at full size into the next free
+// explore-N.png, the same way the main command would.
+func (e *explorer) save() string {
+	opts, err := e.viewOptions(e.saveSize[0], e.saveSize[1])
+	if err != nil {
+		return err.Error()
+	}
+	e.status = "rendering..."
+	e.showStatus()
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		return err.Error()
+	}
+	var path string
+	for {
+		e.saved++
+		path = fmt.Sprintf("explore-%d.png", e.saved)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err.Error()
+	}
+	defer f.Close()
+	if err := render.EncodeWithText(f, res.Image, "png", render.Metadata(opts)); err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("saved %s in %v", path, res.Stats.Elapsed.Round(time.Millisecond))
+}
+
+// bilinearUpscale enlarges src to dstWidth×dstHeight, blending the four
+// source pixels around each destination pixel by its distance from their
+// centers. Pixel centers line up with the source's, as they do between a
+// coarse render and a full one of the same view, and the outermost half
+// pixel is held at the edge color, so the corners keep the source's
+// corner colors exactly.
+func bilinearUpscale(src *image.RGBA, dstWidth, dstHeight int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	if sw == 0 || sh == 0 {
+		return dst
+	}
+	// axis returns the two source indices either side of destination
+	// index i and the weight of the second
+	axis := func(i, n, sn int) (int, int, float64) {
+		f := (float64(i)+0.5)*float64(sn)/float64(n) - 0.5
+		f = min(max(f, 0), float64(sn-1))
+		lo := int(f)
+		return lo, min(lo+1, sn-1), f - float64(lo)
+	}
+	type tap struct {
+		lo, hi int
+		w      float64
+	}
+	cols := make([]tap, dstWidth)
+	for x := range cols {
+		lo, hi, w := axis(x, dstWidth, sw)
+		cols[x] = tap{lo * 4, hi * 4, w}
+	}
+	for y := range dstHeight {
+		y0, y1, wy := axis(y, dstHeight, sh)
+		r0 := src.Pix[src.PixOffset(src.Rect.Min.X, src.Rect.Min.Y+y0):]
+		r1 := src.Pix[src.PixOffset(src.Rect.Min.X, src.Rect.Min.Y+y1):]
+		out := dst.Pix[y*dst.Stride:]
+		for x, c := range cols {
+			for k := range 4 {
+				top := float64(r0[c.lo+k])*(1-c.w) + float64(r0[c.hi+k])*c.w
+				bottom := float64(r1[c.lo+k])*(1-c.w) + float64(r1[c.hi+k])*c.w
+				out[x*4+k] = uint8(top*(1-wy) + bottom*wy + 0.5)
+			}
+		}
+	}
+	return dst
+}
+
+// arrowKeys maps the final byte of the ESC [ arrow sequences to key names.
+var arrowKeys = map[byte]string{'A': "up", 'B': "down", 'C': "right", 'D': "left"}
+
+// readKeys decodes raw-mode input into key names: "up", "down", "left",
+// "right" for the arrows and the character itself otherwise. The channel
+// closes when r does.
+func readKeys(r io.Reader) <-chan string {
+	keys := make(chan string)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 16)
+		for {
+			n, err := r.Read(buf)
+			if err != nil {
+				return
+			}
+			in := buf[:n]
+			for len(in) > 0 {
+				if len(in) >= 3 && in[0] == 0x1b && in[1] == '[' {
+					if k, ok := arrowKeys[in[2]]; ok {
+						keys <- k
+					}
+					in = in[3:]
+					continue
+				}
+				keys <- string(in[:1])
+				in = in[1:]
+			}
+		}
+	}()
+	return keys
+}
+
+// crlfWriter turns "\n" into "\r\n", which a raw-mode terminal needs to
+// return to the first column.
+type crlfWriter struct{ w io.Writer }
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestBilinearUpscale(t *testing.T) {
+	// a 3×2 source with distinct corners, offset to check Rect.Min
+	src := image.NewRGBA(image.Rect(5, 7, 8, 9))
+	pix := [][]color.RGBA{
+		{{255, 0, 0, 255}, {0, 255, 0, 255}, {0, 0, 255, 255}},
+		{{10, 20, 30, 255}, {200, 100, 50, 128}, {255, 255, 255, 255}},
+	}
+	for y, row := range pix {
+		for x, c := range row {
+			src.SetRGBA(5+x, 7+y, c)
+		}
+	}
+	for _, size := range [][2]int{{12, 8}, {7, 5}, {3, 2}, {100, 31}} {
+		dw, dh := size[0], size[1]
+		dst := bilinearUpscale(src, dw, dh)
+		if got := dst.Bounds(); got != image.Rect(0, 0, dw, dh) {
+			t.Errorf("%dx%d: bounds %v", dw, dh, got)
+			continue
+		}
+		for _, c := range [][4]int{{0, 0, 0, 0}, {dw - 1, 0, 2, 0}, {0, dh - 1, 0, 1}, {dw - 1, dh - 1, 2, 1}} {
+			if got, want := dst.RGBAAt(c[0], c[1]), pix[c[3]][c[2]]; got != want {
+				t.Errorf("%dx%d: corner (%d, %d) is %v, want %v", dw, dh, c[0], c[1], got, want)
+			}
+		}
+		// every interior pixel is the weighted blend of the four source
+		// pixels around its center
+		for y := range dh {
+			for x := range dw {
+				fx := min(max((float64(x)+0.5)*3/float64(dw)-0.5, 0), 2)
+				fy := min(max((float64(y)+0.5)*2/float64(dh)-0.5, 0), 1)
+				x0, y0 := int(fx), int(fy)
+				x1, y1 := min(x0+1, 2), min(y0+1, 1)
+				wx, wy := fx-float64(x0), fy-float64(y0)
+				a, b, c, d := pix[y0][x0], pix[y0][x1], pix[y1][x0], pix[y1][x1]
+				blend := func(a, b, c, d uint8) float64 {
+					return (float64(a)*(1-wx)+float64(b)*wx)*(1-wy) + (float64(c)*(1-wx)+float64(d)*wx)*wy
+				}
+				got := dst.RGBAAt(x, y)
+				for _, ch := range [][2]float64{
+					{float64(got.R), blend(a.R, b.R, c.R, d.R)},
+					{float64(got.G), blend(a.G, b.G, c.G, d.G)},
+					{float64(got.B), blend(a.B, b.B, c.B, d.B)},
+					{float64(got.A), blend(a.A, b.A, c.A, d.A)},
+				} {
+					if math.Abs(ch[0]-ch[1]) > 2 {
+						t.Fatalf("%dx%d: pixel (%d, %d) is %v, want the blend of %v, %v, %v, %v at (%v, %v)", dw, dh, x, y, got, a, b, c, d, wx, wy)
+					}
+				}
+			}
+		}
+	}
+	// doubling puts a pixel a quarter of the way between two columns
+	two := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	two.SetRGBA(0, 0, color.RGBA{0, 0, 0, 255})
+	two.SetRGBA(1, 0, color.RGBA{200, 200, 200, 255})
+	if got := bilinearUpscale(two, 4, 1).RGBAAt(1, 0); got != (color.RGBA{50, 50, 50, 255}) {
+		t.Errorf("doubled pixel 1 is %v, want a quarter of the way to 200", got)
+	}
+}
@@ -0,0 +1,125 @@
+package fractal
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+// autoWindow is the part of the plane AutoBounds searches: the whole
+// Mandelbrot set.
+var autoWindow = coords.Bounds{Xmin: -2.2, Xmax: 1.0, Ymin: -1.6, Ymax: 1.6}
+
+const (
+	autoGrid    = 48  // cells per side of the coarse scoring grid
+	autoSubGrid = 8   // cells per side of the grid laid over its best cell
+	autoMaxIter = 256 // iteration limit of the measurements
+)
+
+// AutoBounds picks an interesting part of the Mandelbrot set to zoom into
+// and returns a window on it with the given aspect ratio (width/height).
+//
+// It scatters samples random points over the set, scores each by how
+// steeply the escape count changes around it, which is highest near the
+// boundary, and sums the scores over a coarse grid of cells. The densest
+// cell is divided and scored the same way, then sampled once more at a
+// finer scale, and the result is a window around the best 0.1% of those
+// points, centered on the very best and widened to the aspect ratio. The same seed always gives
+// the same window.
+func AutoBounds(seed int64, samples int, aspect float64) coords.Bounds {
+	samples = max(samples, 1)
+	if !(aspect > 0) || math.IsInf(aspect, 0) {
+		aspect = 1
+	}
+	rng := rand.New(rand.NewPCG(uint64(seed), 0x6d616e64))
+
+	// coarse pass over the whole set, then a finer one inside the winner
+	cell := densestCell(rng, autoWindow, autoGrid, samples)
+	cell = densestCell(rng, cell, autoSubGrid, samples)
+
+	// refinement: the densest 0.1% of points in that cell
+	eps := cell.Width() / 64
+	type scored struct {
+		c     complex128
+		score float64
+	}
+	pts := make([]scored, samples)
+	for i := range pts {
+		c := randomPoint(rng, cell)
+		pts[i] = scored{c, boundaryScore(c, eps)}
+	}
+	slices.SortFunc(pts, func(a, b scored) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return 0
+	})
+	// the window is centered on the best point, so its middle is right at
+	// the boundary, and reaches out to the rest of the best 0.1%; a margin
+	// of a few sampling steps keeps a lone point from giving an empty one
+	top := pts[:max(1, samples/1000)]
+	c := top[0].c
+	var hw, hh float64
+	for _, p := range top {
+		hw = max(hw, math.Abs(real(p.c-c)))
+		hh = max(hh, math.Abs(imag(p.c-c)))
+	}
+	hw, hh = hw+4*eps, hh+4*eps
+	if hw/hh < aspect {
+		hw = hh * aspect
+	} else {
+		hh = hw / aspect
+	}
+	return coords.Bounds{
+		Xmin: real(c) - hw, Xmax: real(c) + hw,
+		Ymin: imag(c) - hh, Ymax: imag(c) + hh,
+	}
+}
+
+// densestCell scatters samples points over b, sums their boundary scores
+// over a grid×grid division of it and returns the cell with the most.
+func densestCell(rng *rand.Rand, b coords.Bounds, grid, samples int) coords.Bounds {
+	cw, ch := b.Width()/float64(grid), b.Height()/float64(grid)
+	cells := make([]float64, grid*grid)
+	for range samples {
+		c := randomPoint(rng, b)
+		gx := min(int((real(c)-b.Xmin)/cw), grid-1)
+		gy := min(int((imag(c)-b.Ymin)/ch), grid-1)
+		cells[gy*grid+gx] += boundaryScore(c, cw/8)
+	}
+	best := 0
+	for i, s := range cells {
+		if s > cells[best] {
+			best = i
+		}
+	}
+	x := b.Xmin + float64(best%grid)*cw
+	y := b.Ymin + float64(best/grid)*ch
+	return coords.Bounds{Xmin: x, Xmax: x + cw, Ymin: y, Ymax: y + ch}
+}
+
+// randomPoint returns a uniformly random point of b.
+func randomPoint(rng *rand.Rand, b coords.Bounds) complex128 {
+	return complex(b.Xmin+rng.Float64()*b.Width(), b.Ymin+rng.Float64()*b.Height())
+}
+
+// boundaryScore measures how fast the escape count changes around c: the
+// summed differences in log escape count between c and four neighbors eps
+// away. Points deep inside the set or far outside it score near zero.
+func boundaryScore(c complex128, eps float64) float64 {
+	at := func(c complex128) float64 {
+		n, _ := Iterate(Mandelbrot{}, c, autoMaxIter, 4)
+		return math.Log1p(float64(n))
+	}
+	v := at(c)
+	var s float64
+	for _, d := range [...]complex128{complex(eps, 0), complex(-eps, 0), complex(0, eps), complex(0, -eps)} {
+		s += math.Abs(v - at(c+d))
+	}
+	return s
+}
@@ -0,0 +1,27 @@
+package fractal
+
+import "testing"
+
+func TestAutoBounds(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3, 42} {
+		b := AutoBounds(seed, 20000, 1.5)
+		if w := b.Width(); !(w > 0 && w < 0.1) {
+			t.Errorf("seed %d: width %v, want a zoomed window under 0.1", seed, w)
+			continue
+		}
+		if got := b.Width() / b.Height(); got < 1.5-1e-9 || got > 1.5+1e-9 {
+			t.Errorf("seed %d: aspect %v, want 1.5", seed, got)
+		}
+		if again := AutoBounds(seed, 20000, 1.5); again != b {
+			t.Errorf("seed %d: %v, then %v", seed, b, again)
+		}
+
+		// rendered 640 pixels wide, the middle pixel is on the boundary:
+		// the set is within a pixel of it, by the distance estimate, which
+		// is at most four times distanceBound
+		px := b.Width() / 640
+		if d := 4 * distanceBound(b.Center(), 1000); d > px {
+			t.Errorf("seed %d: the set may be %v from the middle of %v, over a pixel (%v)", seed, d, b, px)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package fractal
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+// tightBailoutSq is the escape radius, squared, of the distance estimates
+// TightBounds marches by; a large one makes them accurate.
+const tightBailoutSq = 1e20
+
+// TightBounds returns the smallest window that holds the whole Mandelbrot
+// set, to within a few times 2^-precision: about [-2, 0.4712] ×
+// [-1.1228, 1.1228]. The left edge is -2 exactly, the tip of the set on
+// the real axis. The right and top edges are found by bisection. Each step asks whether the
+// set comes within 2^-precision of a line, which it answers by marching
+// along the line by distance estimates computed with up to maxIter
+// iterations. The farthest points are tips of hairs too thin to hit by
+// sampling, which this finds all the same. The set is symmetric about
+// the real axis, so the bottom mirrors the top. Points still bounded
+// after maxIter iterations count as in the set, so too few widen the
+// window; a few hundred are plenty. precision is clamped to [1, 52].
+func TightBounds(maxIter int, precision int) coords.Bounds {
+	maxIter = max(maxIter, 1)
+	eps := math.Ldexp(1, -min(max(precision, 1), 52))
+	// the set lies in |c| <= 2, and reaches past both starting points
+	right := bisectExtent(0.25, 2, eps, func(x float64) bool {
+		return lineNearSet(complex(x, 0), complex(x, 2), maxIter, eps)
+	})
+	top := bisectExtent(0, 2, eps, func(y float64) bool {
+		return lineNearSet(complex(-2, y), complex(2, y), maxIter, eps)
+	})
+	return coords.Bounds{Xmin: -2, Xmax: right, Ymin: -top, Ymax: top}
+}
+
+// bisectExtent narrows [in, out] to within eps of the point where hits
+// stops holding, given that it holds at in and not at out, and returns
+// the side where it holds.
+func bisectExtent(in, out, eps float64, hits func(float64) bool) float64 {
+	for math.Abs(out-in) > eps {
+		mid := (in + out) / 2
+		if hits(mid) {
+			in = mid
+		} else {
+			out = mid
+		}
+	}
+	return in
+}
+
+// lineNearSet reports whether the set comes within eps of the segment
+// from a to b, stepping along it by the distance estimate at each point,
+// which can't overshoot the set.
+func lineNearSet(a, b complex128, maxIter int, eps float64) bool {
+	length := cmplx.Abs(b - a)
+	dir := (b - a) / complex(length, 0)
+	for t := 0.0; t <= length; {
+		d := distanceBound(a+dir*complex(t, 0), maxIter)
+		if d < eps {
+			return true
+		}
+		t += d
+	}
+	return false
+}
+
+// distanceBound returns a lower bound on the distance from c to the
+// Mandelbrot set: a quarter of the usual estimate 2|z|ln|z|/|dz|, which
+// Koebe's theorem guarantees is no more than the true distance. It is 0
+// if c doesn't escape within maxIter iterations.
+func distanceBound(c complex128, maxIter int) float64 {
+	var z, dz complex128
+	for range maxIter {
+		dz = 2*z*dz + 1
+		z = z*z + c
+		if real(z)*real(z)+imag(z)*imag(z) > tightBailoutSq {
+			r := cmplx.Abs(z)
+			return r * math.Log(r) / (2 * cmplx.Abs(dz))
+		}
+	}
+	return 0
+}
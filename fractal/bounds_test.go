@@ -0,0 +1,69 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTightBounds(t *testing.T) {
+	// The request expected Xmax ≈ 0.25 and Ymax ≈ 1.115, but neither is
+	// the set's extent: the main cardioid alone reaches 0.375 (at angle
+	// π/3), hairs off it reach about 0.47119, and the top is about
+	// 1.12276. These are the published values of the set's bounding box.
+	b := TightBounds(1000, 40)
+	if b.Xmin != -2 {
+		t.Errorf("Xmin %v, want -2", b.Xmin)
+	}
+	if math.Abs(b.Xmax-0.47119) > 1e-4 {
+		t.Errorf("Xmax %v, want about 0.47119", b.Xmax)
+	}
+	if math.Abs(b.Ymax-1.12276) > 1e-4 {
+		t.Errorf("Ymax %v, want about 1.12276", b.Ymax)
+	}
+	if b.Ymin != -b.Ymax {
+		t.Errorf("Ymin %v isn't -Ymax %v", b.Ymin, b.Ymax)
+	}
+	// the cardioid's rightmost point lies within
+	if c := 0.5*math.Cos(math.Pi/3) - 0.25*math.Cos(2*math.Pi/3); b.Xmax < c {
+		t.Errorf("Xmax %v cuts off the cardioid at %v", b.Xmax, c)
+	}
+
+	// a coarser search lands within a few of its steps of the fine one
+	for _, p := range []int{8, 16, 24} {
+		c := TightBounds(1000, p)
+		tol := 4 * math.Ldexp(1, -p)
+		if math.Abs(c.Xmax-b.Xmax) > tol || math.Abs(c.Ymax-b.Ymax) > tol {
+			t.Errorf("precision %d: %+v, more than %v from %+v", p, c, tol, b)
+		}
+	}
+	// precision is clamped, and the same inputs give the same box
+	if a, c := TightBounds(1000, 60), TightBounds(1000, 52); a != c {
+		t.Errorf("precision 60: %+v, precision 52: %+v", a, c)
+	}
+	if c := TightBounds(1000, 40); c != b {
+		t.Errorf("again: %+v, first %+v", c, b)
+	}
+}
+
+func TestDistanceBound(t *testing.T) {
+	for _, c := range []complex128{0, -1, -2, 0.25, complex(-0.122561, 0.744862)} {
+		if d := distanceBound(c, 1000); d != 0 {
+			t.Errorf("%v is in the set, bound %v", c, d)
+		}
+	}
+	// outside, the bound is positive and no more than the distance to a
+	// point of the set
+	for _, tc := range []struct {
+		c, in complex128
+	}{
+		{1, 0.25},
+		{-2.5, -2},
+		{complex(0, 2), complex(0, 1)},
+		{0.3, 0.25},
+	} {
+		d := distanceBound(tc.c, 1000)
+		if !(d > 0) || d > math.Hypot(real(tc.c-tc.in), imag(tc.c-tc.in)) {
+			t.Errorf("%v: bound %v, but %v is in the set", tc.c, d, tc.in)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package fractal
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strconv"
+	"strings"
+)
+
+// Expr iterates z = f(z, c) from z = 0 for a formula f written out as
+// text, such as "z*z*z + c*z + c". The formula is made of z, c, numbers
+// (a trailing i makes one imaginary, and i alone is the imaginary unit),
+// the operators + - * / and ^ for powers, and parentheses. ^ binds
+// tightest and groups to the right, so -z^2 is -(z^2) and z^2^3 is
+// z^8; a whole-number power is taken by repeated multiplication, any
+// other by cmplx.Pow. Use ParseExpr to make one.
+type Expr struct {
+	e *parsedExpr
+}
+
+type parsedExpr struct {
+	src    string
+	f      func(z, c complex128) complex128
+	degree float64
+}
+
+// ParseExpr parses a formula for Expr. A malformed one gives an error
+// naming the position where parsing stopped.
+func ParseExpr(src string) (Expr, error) {
+	n, err := parseExprTree(src)
+	if err != nil {
+		return Expr{}, err
+	}
+	return Expr{&parsedExpr{src: src, f: n.compile(), degree: n.degree()}}, nil
+}
+
+// Source returns the formula as it was given to ParseExpr.
+func (x Expr) Source() string { return x.e.src }
+
+func (x Expr) Init(c complex128) State { return State{C: c} }
+func (x Expr) Step(s *State)           { s.Z = x.e.f(s.Z, s.C) }
+func (x Expr) Escaped(s *State) bool   { return escaped(s) }
+
+// Degree returns the formula's degree in z as it grows, 2 when that
+// isn't above 1 or can't be told from the formula alone (a power of z
+// by z, say).
+func (x Expr) Degree() float64 {
+	if d := x.e.degree; d > 1 && !math.IsInf(d, 0) {
+		return d
+	}
+	return 2
+}
+
+// exprNode is a node of a parsed formula: an operator with its operands
+// (one for negation, two otherwise), a variable or a constant.
+type exprNode struct {
+	op   byte // '+', '-', '*', '/', '^', 'n' negate, 'z', 'c' or '#' constant
+	val  complex128
+	a, b *exprNode
+}
+
+// compile turns n into a closure, folding the parts without z or c into
+// constants.
+func (n *exprNode) compile() func(z, c complex128) complex128 {
+	if n.constant() {
+		v := n.eval(0, 0)
+		return func(z, c complex128) complex128 { return v }
+	}
+	switch n.op {
+	case 'z':
+		return func(z, c complex128) complex128 { return z }
+	case 'c':
+		return func(z, c complex128) complex128 { return c }
+	case 'n':
+		a := n.a.compile()
+		return func(z, c complex128) complex128 { return -a(z, c) }
+	}
+	a, b := n.a.compile(), n.b.compile()
+	switch n.op {
+	case '+':
+		return func(z, c complex128) complex128 { return a(z, c) + b(z, c) }
+	case '-':
+		return func(z, c complex128) complex128 { return a(z, c) - b(z, c) }
+	case '*':
+		return func(z, c complex128) complex128 { return a(z, c) * b(z, c) }
+	case '/':
+		return func(z, c complex128) complex128 { return a(z, c) / b(z, c) }
+	}
+	if k, ok := n.b.wholePower(); ok {
+		if k < 0 {
+			return func(z, c complex128) complex128 { return 1 / ipow(a(z, c), -k) }
+		}
+		return func(z, c complex128) complex128 { return ipow(a(z, c), k) }
+	}
+	return func(z, c complex128) complex128 { return cmplx.Pow(a(z, c), b(z, c)) }
+}
+
+// eval computes n directly, for constants.
+func (n *exprNode) eval(z, c complex128) complex128 {
+	switch n.op {
+	case '#':
+		return n.val
+	case 'z':
+		return z
+	case 'c':
+		return c
+	case 'n':
+		return -n.a.eval(z, c)
+	case '+':
+		return n.a.eval(z, c) + n.b.eval(z, c)
+	case '-':
+		return n.a.eval(z, c) - n.b.eval(z, c)
+	case '*':
+		return n.a.eval(z, c) * n.b.eval(z, c)
+	case '/':
+		return n.a.eval(z, c) / n.b.eval(z, c)
+	}
+	if k, ok := n.b.wholePower(); ok {
+		if k < 0 {
+			return 1 / ipow(n.a.eval(z, c), -k)
+		}
+		return ipow(n.a.eval(z, c), k)
+	}
+	return cmplx.Pow(n.a.eval(z, c), n.b.eval(z, c))
+}
+
+// constant reports whether n involves neither z nor c.
+func (n *exprNode) constant() bool {
+	switch n.op {
+	case '#':
+		return true
+	case 'z', 'c':
+		return false
+	case 'n':
+		return n.a.constant()
+	}
+	return n.a.constant() && n.b.constant()
+}
+
+// maxWholePower bounds the exponents taken by repeated multiplication.
+const maxWholePower = 1 << 16
+
+// wholePower returns n's value if n is a constant whole number of
+// reasonable size, as a power to take by repeated multiplication.
+func (n *exprNode) wholePower() (int, bool) {
+	if !n.constant() {
+		return 0, false
+	}
+	v := n.eval(0, 0)
+	if imag(v) != 0 || real(v) != math.Trunc(real(v)) || math.Abs(real(v)) > maxWholePower {
+		return 0, false
+	}
+	return int(real(v)), true
+}
+
+// degree returns how fast n grows with z, as the power of z it grows
+// like: 0 for constants and c, and NaN if that depends on more than the
+// formula's shape. It ignores terms that cancel.
+func (n *exprNode) degree() float64 {
+	switch n.op {
+	case '#', 'c':
+		return 0
+	case 'z':
+		return 1
+	case 'n':
+		return n.a.degree()
+	case '+', '-':
+		return math.Max(n.a.degree(), n.b.degree())
+	case '*':
+		return n.a.degree() + n.b.degree()
+	case '/':
+		return n.a.degree() - n.b.degree()
+	}
+	if !n.b.constant() {
+		return math.NaN()
+	}
+	return n.a.degree() * real(n.b.eval(0, 0))
+}
+
+// exprParser is a recursive descent parser over the grammar
+//
+//	sum     = product {("+" | "-") product}
+//	product = unary {("*" | "/") unary}
+//	unary   = ("-" | "+") unary | power
+//	power   = operand ["^" unary]
+//	operand = number | "z" | "c" | "i" | "(" sum ")"
+type exprParser struct {
+	src string
+	pos int
+}
+
+// parseExprTree parses src into its tree.
+func parseExprTree(src string) (*exprNode, error) {
+	p := &exprParser{src: src}
+	n, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	return n, nil
+}
+
+func (p *exprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("fractal: formula %q: at %d: %s", p.src, p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// accept consumes the next character if it is one of ops.
+func (p *exprParser) accept(ops string) (byte, bool) {
+	p.skipSpace()
+	if p.pos < len(p.src) && strings.IndexByte(ops, p.src[p.pos]) >= 0 {
+		p.pos++
+		return p.src[p.pos-1], true
+	}
+	return 0, false
+}
+
+func (p *exprParser) sum() (*exprNode, error) {
+	n, err := p.product()
+	for err == nil {
+		op, ok := p.accept("+-")
+		if !ok {
+			break
+		}
+		var b *exprNode
+		if b, err = p.product(); err == nil {
+			n = &exprNode{op: op, a: n, b: b}
+		}
+	}
+	return n, err
+}
+
+func (p *exprParser) product() (*exprNode, error) {
+	n, err := p.unary()
+	for err == nil {
+		op, ok := p.accept("*/")
+		if !ok {
+			break
+		}
+		var b *exprNode
+		if b, err = p.unary(); err == nil {
+			n = &exprNode{op: op, a: n, b: b}
+		}
+	}
+	return n, err
+}
+
+func (p *exprParser) unary() (*exprNode, error) {
+	op, ok := p.accept("-+")
+	if !ok {
+		return p.power()
+	}
+	n, err := p.unary()
+	if err != nil || op == '+' {
+		return n, err
+	}
+	return &exprNode{op: 'n', a: n}, nil
+}
+
+func (p *exprParser) power() (*exprNode, error) {
+	n, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("^"); !ok {
+		return n, nil
+	}
+	b, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return &exprNode{op: '^', a: n, b: b}, nil
+}
+
+func (p *exprParser) operand() (*exprNode, error) {
+	p.skipSpace()
+	if p.pos == len(p.src) {
+		return nil, p.errorf("unexpected end, want z, c, a number or (")
+	}
+	switch ch := p.src[p.pos]; {
+	case ch == 'z' || ch == 'c':
+		p.pos++
+		return &exprNode{op: ch}, nil
+	case ch == 'i':
+		p.pos++
+		return &exprNode{op: '#', val: 1i}, nil
+	case ch == '(':
+		p.pos++
+		n, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, p.errorf("missing )")
+		}
+		return n, nil
+	case ch >= '0' && ch <= '9' || ch == '.':
+		return p.number()
+	default:
+		return nil, p.errorf("unexpected %q, want z, c, a number or (", ch)
+	}
+}
+
+// number reads a decimal literal, with an exponent if it has one and
+// imaginary if it ends in i.
+func (p *exprParser) number() (*exprNode, error) {
+	start := p.pos
+	digits := func() {
+		for p.pos < len(p.src) && (p.src[p.pos] >= '0' && p.src[p.pos] <= '9' || p.src[p.pos] == '.') {
+			p.pos++
+		}
+	}
+	digits()
+	if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+			p.pos++
+		}
+		digits()
+	}
+	lit := p.src[start:p.pos]
+	v, err := strconv.ParseFloat(lit, 64)
+	if err != nil || math.IsInf(v, 0) {
+		p.pos = start
+		return nil, p.errorf("bad number %q", lit)
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == 'i' {
+		p.pos++
+		return &exprNode{op: '#', val: complex(0, v)}, nil
+	}
+	return &exprNode{op: '#', val: complex(v, 0)}, nil
+}
@@ -0,0 +1,155 @@
+package fractal
+
+import (
+	"math"
+	"math/cmplx"
+	"strings"
+	"testing"
+)
+
+func TestParseExpr(t *testing.T) {
+	x, err := ParseExpr("z*z+c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := State{Z: 2 + 1i, C: -1}
+	x.Step(&s)
+	if s.Z != 2+4i {
+		t.Errorf("z*z+c at z=2+1i, c=-1: %v, want 2+4i", s.Z)
+	}
+	if x.Source() != "z*z+c" {
+		t.Errorf("source %q", x.Source())
+	}
+}
+
+func TestParseExprEval(t *testing.T) {
+	const z, c = 0.5 - 1.5i, -0.25 + 0.75i
+	for _, tc := range []struct {
+		src  string
+		want complex128
+	}{
+		{"z*z*z + c*z + c", z*z*z + c*z + c},
+		{"z^3 + c*z + c", z*z*z + c*z + c},
+		{"1 + 2*3", 7},
+		{"(1 + 2)*3", 9},
+		{"8 / 2 / 2", 2},
+		{"7 - 2 - 1", 4},
+		{"-z^2", -(z * z)},
+		{"2^3^2", 512},
+		{"-2^2", -4},
+		{"+z - -c", z + c},
+		{"z/c", z / c},
+		{"z^-2", 1 / (z * z)},
+		{"2i*z + i", 2i*z + 1i},
+		{"1.5e1 + .5 + 2E-1i", 15.5 + 0.2i},
+		{"\tz * ( c + 1 ) ", z * (c + 1)},
+		{"z^0.5", cmplx.Pow(z, 0.5)},
+		{"z^c", cmplx.Pow(z, c)},
+		{"c", c},
+		{"3", 3},
+	} {
+		x, err := ParseExpr(tc.src)
+		if err != nil {
+			t.Errorf("%q: %v", tc.src, err)
+			continue
+		}
+		if got := x.e.f(z, c); cmplx.Abs(got-tc.want) > 1e-12*math.Max(1, cmplx.Abs(tc.want)) {
+			t.Errorf("%q = %v, want %v", tc.src, got, tc.want)
+		}
+	}
+}
+
+func TestParseExprMandelbrot(t *testing.T) {
+	// written out, the Mandelbrot formula steps exactly as the built-in one
+	x, err := ParseExpr("z*z + c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []complex128{-0.75 + 0.1i, 0.3 + 0.5i, -1.9, 0.25} {
+		a, b := x.Init(c), Mandelbrot{}.Init(c)
+		for range 50 {
+			if cmplx.Abs(a.Z) > 2 {
+				break
+			}
+			x.Step(&a)
+			Mandelbrot{}.Step(&b)
+			if a.Z != b.Z {
+				t.Fatalf("c=%v: %v, built in %v", c, a.Z, b.Z)
+			}
+		}
+	}
+}
+
+func TestExprDegree(t *testing.T) {
+	for _, tc := range []struct {
+		src  string
+		want float64
+	}{
+		{"z*z + c", 2},
+		{"z*z*z + c*z + c", 3},
+		{"z^5 - z + c", 5},
+		{"(z^2)^3 + c", 6},
+		{"z^2.5 + c", 2.5},
+		{"z^4/z + c", 3},
+		// no growth, or none the formula shows: the default
+		{"z + c", 2},
+		{"c", 2},
+		{"z^z + c", 2},
+		{"z^c", 2},
+	} {
+		x, err := ParseExpr(tc.src)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.src, err)
+		}
+		if got := x.Degree(); got != tc.want {
+			t.Errorf("%q: degree %v, want %v", tc.src, got, tc.want)
+		}
+	}
+}
+
+func TestParseExprErrors(t *testing.T) {
+	for _, tc := range []struct{ src, pos string }{
+		{"", "at 1:"},
+		{"   ", "at 4:"},
+		{"z*", "at 3:"},
+		{"z +* c", "at 4:"},
+		{"(z + c", "at 7:"},
+		{"z + c)", "at 6:"},
+		{"z c", "at 3:"},
+		{"x*x + c", "at 1:"},
+		{"z^", "at 3:"},
+		{"1..2 + z", "at 1:"},
+		{"1e999 * z", "at 1:"},
+		{"1e", "at 1:"},
+		{"z ** 2", "at 4:"},
+		{"sin(z)", "at 1:"},
+		{"()", "at 2:"},
+		{"z + c\n", "at 6:"},
+	} {
+		_, err := ParseExpr(tc.src)
+		if err == nil {
+			t.Errorf("%q: no error", tc.src)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.pos) {
+			t.Errorf("%q: %v, want the position %q", tc.src, err, tc.pos)
+		}
+	}
+}
+
+func FuzzParseExpr(f *testing.F) {
+	for _, s := range []string{"z*z+c", "z^3 - (c/2i)^-1.5", "((z", "-+-z^^2", "1e+", ".i"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		// malformed formulas return errors, never panic, and valid ones
+		// evaluate without panicking
+		x, err := ParseExpr(src)
+		if err != nil {
+			return
+		}
+		s := x.Init(0.1 + 0.2i)
+		x.Step(&s)
+		x.Degree()
+	})
+}
@@ -0,0 +1,205 @@
+// Package fractal defines escape-time iteration rules and the built-in formulas.
+package fractal
+
+// State is the per-orbit state threaded through an iteration.
+// Aux is free for formulas that need extra per-orbit values (a second
+// orbit, a running trap distance, ...); the built-ins leave it untouched.
+type State struct {
+	Z         complex128
+	C         complex128
+	BailoutSq float64 // squared escape radius, set by Iterate
+	Aux       [4]complex128
+}
+
+// Fractal is an escape-time iteration rule. Init seeds the orbit for the
+// sample point c, Step advances it once and Escaped reports whether the
+// orbit has left the bailout region.
+//
+// Implementations should be small value types: Iterate is generic over the
+// concrete type so Step and Escaped can be inlined into the hot loop.
+type Fractal interface {
+	Init(c complex128) State
+	Step(s *State)
+	Escaped(s *State) bool
+}
+
+// Degreer is implemented by formulas whose leading term is z^d for d != 2.
+// The smooth coloring layer uses it to pick the right logarithm base.
+type Degreer interface {
+	Degree() float64
+}
+
+// Degree returns the polynomial degree of f, defaulting to 2.
+func Degree(f Fractal) float64 {
+	if d, ok := f.(Degreer); ok {
+		return d.Degree()
+	}
+	return 2
+}
+
+// DefaultBailoutSq is the squared escape radius |z| > 2.
+const DefaultBailoutSq = 4.0
+
+// Iterate runs f from c for at most maxIter steps with the squared escape
+// radius bailoutSq, which Escaped implementations read from the State.
+// It returns the step at which the orbit escaped (maxIter if it never did)
+// together with the final state.
+func Iterate[F Fractal](f F, c complex128, maxIter int, bailoutSq float64) (int, State) {
+	s := f.Init(c)
+	s.BailoutSq = bailoutSq
+	for n := range maxIter {
+		f.Step(&s)
+		if f.Escaped(&s) {
+			return n, s
+		}
+	}
+	return maxIter, s
+}
+
+// Orbit returns the orbit of f from c: the starting value and then each
+// iterate for at most n steps, stopping after the first one that escapes
+// the squared radius bailoutSq.
+func Orbit(f Fractal, c complex128, n int, bailoutSq float64) []complex128 {
+	s := f.Init(c)
+	s.BailoutSq = bailoutSq
+	zs := []complex128{s.Z}
+	for range n {
+		f.Step(&s)
+		zs = append(zs, s.Z)
+		if f.Escaped(&s) {
+			break
+		}
+	}
+	return zs
+}
+
+// escaped is the |z|² > BailoutSq test shared by the built-ins. It is
+// written as "not within" so that an orbit with a NaN part, which fails
+// every comparison, escapes too instead of running to maxIter as if it
+// were inside; an overflowing |z|² is +Inf and escapes either way.
+func escaped(s *State) bool {
+	return !(real(s.Z)*real(s.Z)+imag(s.Z)*imag(s.Z) <= s.BailoutSq)
+}
+
+// Mandelbrot iterates z = z^2 + c from z = Z0. The zero value starts
+// from 0 and gives the Mandelbrot set itself; any other Z0 gives the
+// Mandelbrot-like set of the parameters c whose orbit from Z0 stays
+// bounded.
+type Mandelbrot struct {
+	Z0 complex128
+}
+
+func (m Mandelbrot) Init(c complex128) State { return State{Z: m.Z0, C: c} }
+func (Mandelbrot) Step(s *State)             { s.Z = s.Z*s.Z + s.C }
+func (Mandelbrot) Escaped(s *State) bool     { return escaped(s) }
+
+// Julia iterates z = z^2 + K from z = c, i.e. the sample point is the
+// starting orbit value and K is the fixed parameter.
+type Julia struct {
+	K complex128
+}
+
+func (j Julia) Init(c complex128) State { return State{Z: c, C: j.K} }
+func (Julia) Step(s *State)             { s.Z = s.Z*s.Z + s.C }
+func (Julia) Escaped(s *State) bool     { return escaped(s) }
+
+// BurningShip iterates z = (|Re z| + i|Im z|)^2 + c from z = 0.
+type BurningShip struct{}
+
+func (BurningShip) Init(c complex128) State { return State{C: c} }
+func (BurningShip) Step(s *State) {
+	x, y := real(s.Z), imag(s.Z)
+	if x < 0 {
+		x = -x
+	}
+	if y < 0 {
+		y = -y
+	}
+	s.Z = complex(x*x-y*y, 2*x*y) + s.C
+}
+func (BurningShip) Escaped(s *State) bool { return escaped(s) }
+
+// DefaultPower is the degree of the Multibrot that ByName returns.
+const DefaultPower = 3
+
+// Multibrot iterates z = z^Power + c from z = 0, the Mandelbrot set of
+// degree Power, which has Power-1-fold rotational symmetry. Power must be
+// at least 2; 2 gives the Mandelbrot set, without its interior shortcuts.
+type Multibrot struct {
+	Power int
+}
+
+func (Multibrot) Init(c complex128) State { return State{C: c} }
+func (m Multibrot) Step(s *State)         { s.Z = ipow(s.Z, m.Power) + s.C }
+func (Multibrot) Escaped(s *State) bool   { return escaped(s) }
+func (m Multibrot) Degree() float64       { return float64(m.Power) }
+
+// ipow returns z^n for n >= 0 by repeated squaring, which unlike
+// cmplx.Pow stays exact for small integer powers.
+func ipow(z complex128, n int) complex128 {
+	r := complex(1, 0)
+	for ; n > 0; n >>= 1 {
+		if n&1 != 0 {
+			r *= z
+		}
+		z *= z
+	}
+	return r
+}
+
+// Func adapts a plain step function z' = f(z, c) to a Fractal iterating
+// from z = 0 with the bailout passed to Iterate.
+type Func func(z, c complex128) complex128
+
+func (f Func) Init(c complex128) State { return State{C: c} }
+func (f Func) Step(s *State)           { s.Z = f(s.Z, s.C) }
+func (f Func) Escaped(s *State) bool   { return escaped(s) }
+
+// Names lists the built-in formulas by flag name.
+var Names = []string{"mandelbrot", "julia", "burningship", "multibrot", "quaternion", "lemniscate", "super"}
+
+// ByName returns the built-in formula with the given name, or nil.
+// k is the Julia parameter and is ignored by the other formulas; the
+// multibrot is of degree DefaultPower, the super of DefaultSuperPower,
+// and the quaternion set is cut at the complex plane.
+func ByName(name string, k complex128) Fractal {
+	switch name {
+	case "mandelbrot":
+		return Mandelbrot{}
+	case "julia":
+		return Julia{K: k}
+	case "burningship":
+		return BurningShip{}
+	case "multibrot":
+		return Multibrot{Power: DefaultPower}
+	case "quaternion":
+		return Quaternion{}
+	case "lemniscate":
+		return Lemniscate{}
+	case "super":
+		return Super{Power: DefaultSuperPower}
+	}
+	return nil
+}
+
+// NameOf returns the flag name of a built-in formula, or "" for any other
+// Fractal. It is the inverse of ByName.
+func NameOf(f Fractal) string {
+	switch f.(type) {
+	case Mandelbrot:
+		return "mandelbrot"
+	case Julia:
+		return "julia"
+	case BurningShip:
+		return "burningship"
+	case Multibrot:
+		return "multibrot"
+	case Quaternion:
+		return "quaternion"
+	case Lemniscate:
+		return "lemniscate"
+	case Super:
+		return "super"
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+// nonFinite are sample points whose orbits overflow or turn NaN on the
+// first step or two.
+var nonFinite = []complex128{
+	complex(1e200, 1e200), complex(1e200, 0), complex(-1e200, 0),
+	complex(math.Inf(1), 0), complex(math.NaN(), 0), complex(0, math.NaN()),
+}
+
+func TestEscapedNaN(t *testing.T) {
+	for _, tc := range []struct {
+		z    complex128
+		want bool
+	}{
+		{0, false},
+		{2, false}, // on the radius stays in
+		{2.5, true},
+		{complex(math.Inf(1), 0), true},
+		{complex(math.Inf(1), math.Inf(-1)), true},
+		{complex(math.NaN(), 0), true},
+		{complex(0, math.NaN()), true},
+	} {
+		if got := escaped(&State{Z: tc.z, BailoutSq: DefaultBailoutSq}); got != tc.want {
+			t.Errorf("escaped(%v) = %v, want %v", tc.z, got, tc.want)
+		}
+	}
+}
+
+func TestIterateNonFinite(t *testing.T) {
+	// Every formula must let an orbit that is no longer a number escape
+	// rather than run it to maxIter and call it interior. Julia{0} at
+	// 1e200+1e200i squares to Inf-Inf = NaN in its real part.
+	expr, err := ParseExpr("z*z + c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	formulas := map[string]Fractal{
+		"expr":          expr,
+		"func":          Func(func(z, c complex128) complex128 { return z*z + c }),
+		"julia 0":       Julia{},
+		"mandelbrot z0": Mandelbrot{Z0: 0.1},
+	}
+	for _, name := range Names {
+		formulas[name] = ByName(name, -0.8+0.156i)
+	}
+	for name, f := range formulas {
+		for _, c := range nonFinite {
+			n, s := Iterate(f, c, 100, DefaultBailoutSq)
+			if n >= 100 {
+				t.Errorf("%s at %v: ran to maxIter with z = %v", name, c, s.Z)
+			} else if n > 2 {
+				t.Errorf("%s at %v: escaped at %d, want within 2", name, c, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package fractal
+
+// Lemniscate iterates z = c·z·(1-z), the logistic map over the complex
+// numbers, from its critical point z = 1/2, giving the set of parameters
+// c whose orbit stays bounded: two copies of the Mandelbrot set joined
+// cusp to cusp at c = 1, as c and 2 - c show the same dynamics. On the
+// real line it is bounded for c from -2 to 4, where the logistic map's
+// bifurcation diagram lives.
+//
+// The map is the Mandelbrot map in disguise: w = c·(1/2 - z) turns it
+// into w = w² + c/2 - c²/4. Escaped tests |w| rather than |z|, so a
+// point escapes exactly when its Mandelbrot counterpart does, even where
+// a small c lets a bounded z run far from the origin.
+type Lemniscate struct{}
+
+func (Lemniscate) Init(c complex128) State { return State{Z: 0.5, C: c} }
+func (Lemniscate) Step(s *State)           { s.Z = s.C * s.Z * (1 - s.Z) }
+func (Lemniscate) Escaped(s *State) bool {
+	w := s.C * (0.5 - s.Z)
+	return !(real(w)*real(w)+imag(w)*imag(w) <= s.BailoutSq) // NaN escapes, as in escaped
+}
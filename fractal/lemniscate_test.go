@@ -0,0 +1,57 @@
+package fractal
+
+import (
+	"testing"
+)
+
+func TestLemniscateKnownPoints(t *testing.T) {
+	const maxIter = 1000
+	for _, tc := range []struct {
+		c       complex128
+		bounded bool
+	}{
+		{0, true},
+		// The request expected 3 to escape quickly, but the real logistic
+		// map stays bounded for c from -2 to 4: at 3 the orbit from 1/2
+		// settles slowly onto the fixed point 2/3, and 4 is the chaotic
+		// end of the bifurcation diagram.
+		{3, true},
+		{4, true},
+		{-2, true},
+		{1, true}, // where the two Mandelbrot copies meet
+		{5, false},
+		{-2.1, false},
+		{2i, false},
+	} {
+		n, _ := Iterate(Lemniscate{}, tc.c, maxIter, DefaultBailoutSq)
+		if tc.bounded && n != maxIter {
+			t.Errorf("%v escaped at %d, want bounded", tc.c, n)
+		}
+		if !tc.bounded && n > 2 {
+			t.Errorf("%v escaped at %d, want within 2", tc.c, n)
+		}
+	}
+}
+
+func TestLemniscateConjugateMandelbrot(t *testing.T) {
+	// c escapes with the Mandelbrot point c/2 - c²/4, and 2 - c with c
+	const maxIter = 500
+	mismatches := 0
+	for y := range 60 {
+		for x := range 80 {
+			c := complex(-2.2+6.4*float64(x)/79, -2+4*float64(y)/59)
+			n, _ := Iterate(Lemniscate{}, c, maxIter, DefaultBailoutSq)
+			want, _ := Iterate(Mandelbrot{}, c/2-c*c/4, maxIter, DefaultBailoutSq)
+			mirror, _ := Iterate(Lemniscate{}, 2-c, maxIter, DefaultBailoutSq)
+			if n != want || n != mirror {
+				mismatches++
+				t.Logf("%v: escaped at %d, Mandelbrot at %d, 2-c at %d", c, n, want, mirror)
+			}
+		}
+	}
+	// the two compute the same orbit with different rounding, which can
+	// only tell on the boundary
+	if mismatches > 10 {
+		t.Errorf("%d of %d points disagree", mismatches, 80*60)
+	}
+}
@@ -0,0 +1,167 @@
+package fractal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// ErrNoMiniMandelbrot is returned by FindMiniMandelbrot when the search
+// area holds no interior points outside the main cardioid and bulb, or
+// when the nucleus search doesn't settle inside the area.
+var ErrNoMiniMandelbrot = errors.New("no mini Mandelbrot found")
+
+const (
+	miniGrid  = 96 // samples per side of the coarse interior scan
+	miniBlock = 8  // samples per side of the blocks scored for interior density
+)
+
+// FindMiniMandelbrot looks for a copy of the Mandelbrot set within
+// searchRadius of center and returns its nucleus (the center of its main
+// cardioid) and its approximate size: the distance on the plane from the
+// copy's tip to the cusp of its cardioid.
+//
+// The search has two phases. A coarse grid of maxIter-iteration samples
+// finds the block of the area with the most interior points, skipping
+// those of the main cardioid and period-2 bulb, which belong to the set
+// itself. From there the period of the copy is read off the orbit and
+// Newton's method homes in on the nucleus. The size comes from the
+// standard estimate of the copy's scale relative to the whole set: near
+// the nucleus the copy is the whole set shrunk by that factor (and
+// turned), so the whole set's measurements carry over.
+func FindMiniMandelbrot(center complex128, searchRadius float64, maxIter int) (complex128, float64, error) {
+	if !(searchRadius > 0) || maxIter < 1 {
+		return 0, 0, fmt.Errorf("fractal: mini Mandelbrot search radius %g, %d iterations: both must be positive", searchRadius, maxIter)
+	}
+
+	// phase 1: the block with the densest interior
+	step := 2 * searchRadius / miniGrid
+	at := func(i, j int) complex128 {
+		return center + complex(-searchRadius+(float64(i)+0.5)*step, -searchRadius+(float64(j)+0.5)*step)
+	}
+	const blocks = miniGrid / miniBlock
+	var count [blocks * blocks]int
+	var sum [blocks * blocks]complex128
+	for j := range miniGrid {
+		for i := range miniGrid {
+			c := at(i, j)
+			if inMainCardioid(c) || inPeriod2Bulb(c) {
+				continue
+			}
+			if n, _ := Iterate(Mandelbrot{}, c, maxIter, 4); n == maxIter {
+				b := j/miniBlock*blocks + i/miniBlock
+				count[b]++
+				sum[b] += c
+			}
+		}
+	}
+	best := 0
+	for b := range count {
+		if count[b] > count[best] {
+			best = b
+		}
+	}
+	if count[best] == 0 {
+		return 0, 0, fmt.Errorf("%w within %g of %v", ErrNoMiniMandelbrot, searchRadius, center)
+	}
+	guess := sum[best] / complex(float64(count[best]), 0)
+
+	// phase 2: the period of the orbit and Newton's method on its nucleus
+	p := atomPeriod(guess, maxIter)
+	c, ok := nucleus(guess, p)
+	if !ok || math.Abs(real(c-center)) > searchRadius || math.Abs(imag(c-center)) > searchRadius {
+		return 0, 0, fmt.Errorf("%w within %g of %v: the period-%d nucleus search did not settle inside it", ErrNoMiniMandelbrot, searchRadius, center, p)
+	}
+	p = exactPeriod(c, p)
+	return c, miniWidth * cmplx.Abs(atomScale(c, p)), nil
+}
+
+// miniWidth is the length of the whole set's spine, from the tip at -2 to
+// the cusp at 1/4, in units of the scale atomScale returns: a copy of
+// relative scale s is about s times this long from tip to cusp.
+const miniWidth = 2.25
+
+// inMainCardioid reports whether c lies in the main cardioid.
+func inMainCardioid(c complex128) bool {
+	x, y := real(c)-0.25, imag(c)
+	q := x*x + y*y
+	return q*(q+x) <= 0.25*y*y
+}
+
+// inPeriod2Bulb reports whether c lies in the period-2 disk at -1.
+func inPeriod2Bulb(c complex128) bool {
+	x, y := real(c)+1, imag(c)
+	return x*x+y*y <= 1.0/16
+}
+
+// atomPeriod returns the iteration at which the orbit of c comes closest
+// to 0 before escaping, the period of the atom domain c lies in.
+func atomPeriod(c complex128, maxIter int) int {
+	var z complex128
+	p, closest := 1, math.Inf(1)
+	for n := 1; n <= maxIter; n++ {
+		z = z*z + c
+		m := cmplx.Abs(z)
+		if m > 2 {
+			break
+		}
+		if m < closest {
+			p, closest = n, m
+		}
+	}
+	return p
+}
+
+// nucleus finds a root of f_c^p(0) = 0 near c by Newton's method.
+func nucleus(c complex128, p int) (complex128, bool) {
+	for range 64 {
+		var z, dz complex128
+		for range p {
+			dz = 2*z*dz + 1
+			z = z*z + c
+		}
+		if dz == 0 {
+			return c, false
+		}
+		d := z / dz
+		c -= d
+		if cmplx.IsNaN(c) || cmplx.IsInf(c) {
+			return c, false
+		}
+		if cmplx.Abs(d) <= 1e-15*max(cmplx.Abs(c), 1e-300) {
+			return c, true
+		}
+	}
+	return c, false
+}
+
+// exactPeriod returns the smallest divisor q of p at which the orbit of
+// the nucleus c returns to 0. The closest approach can land on a
+// multiple of the true period, and Newton's method converges to the same
+// nucleus for any multiple.
+func exactPeriod(c complex128, p int) int {
+	var z complex128
+	tol := 1e-9
+	for q := 1; q <= p; q++ {
+		z = z*z + c
+		if p%q == 0 && cmplx.Abs(z) < tol {
+			return q
+		}
+	}
+	return p
+}
+
+// atomScale estimates the size of the period-p copy with nucleus c
+// relative to the whole set: 1/(b·l²), where l is the derivative of the
+// orbit with respect to z along the cycle and b the sum of the inverse
+// partial derivatives. It is 1 for the main cardioid.
+func atomScale(c complex128, p int) complex128 {
+	z, l, b := complex128(0), complex128(1), complex128(1)
+	for range p - 1 {
+		z = z*z + c
+		l = 2 * z * l
+		b += 1 / l
+	}
+	return 1 / (b * l * l)
+}
@@ -0,0 +1,42 @@
+package fractal
+
+import (
+	"errors"
+	"math/cmplx"
+	"testing"
+)
+
+func TestFindMiniMandelbrot(t *testing.T) {
+	// the period-3 copy on the antenna: its nucleus is the real root of
+	// c³ + 2c² + c + 1, and it runs from its tip near -1.786 to its cusp
+	// at -1.75
+	const nucleus = -1.7548776662466927
+	for _, radius := range []float64{0.02, 0.05} {
+		c, size, err := FindMiniMandelbrot(-1.755, radius, 1000)
+		if err != nil {
+			t.Fatalf("radius %v: %v", radius, err)
+		}
+		if cmplx.Abs(c-nucleus) > 1e-12 {
+			t.Errorf("radius %v: nucleus %v, want %v", radius, c, nucleus)
+		}
+		if p := c*c*c + 2*c*c + c + 1; cmplx.Abs(p) > 1e-12 {
+			t.Errorf("radius %v: %v isn't a root, the cubic is %v there", radius, c, p)
+		}
+		if size < 0.025 || size > 0.05 {
+			t.Errorf("radius %v: size %v, want about 0.03", radius, size)
+		}
+	}
+}
+
+func TestFindMiniMandelbrotNone(t *testing.T) {
+	for _, center := range []complex128{1 + 1i, 0, -1} {
+		// outside the set, and inside the main cardioid and bulb, which
+		// are the set itself rather than copies of it
+		if _, _, err := FindMiniMandelbrot(center, 0.05, 500); !errors.Is(err, ErrNoMiniMandelbrot) {
+			t.Errorf("around %v: %v, want ErrNoMiniMandelbrot", center, err)
+		}
+	}
+	if _, _, err := FindMiniMandelbrot(-1.755, 0, 500); err == nil {
+		t.Error("no error for radius 0")
+	}
+}
@@ -0,0 +1,36 @@
+package fractal
+
+import "math"
+
+// Quaternion iterates q = q² + c over the quaternions, from q = 0, and
+// shows the cross-section of the four-dimensional set in which c has the
+// fixed j and k components SliceZ and SliceW: the sample point x + yi
+// gives c = x + yi + SliceZ·j + SliceW·k.
+//
+// The square of a + v, with v the vector part bi + cj + dk, is
+// a² - |v|² + 2a·v, and v keeps the direction of c's vector part from the
+// first step on. So State.Z holds a + |v|i, with the sign of b, which has
+// the modulus of q and makes the escape test and smooth coloring those
+// of any other formula; b, c and d themselves go in Aux[0] and Aux[1].
+// With SliceZ and SliceW 0 it iterates the same values as Mandelbrot,
+// and any other slice is the Mandelbrot set turned about the real axis,
+// cut away from its plane.
+type Quaternion struct {
+	SliceZ, SliceW float64
+}
+
+func (Quaternion) Init(c complex128) State { return State{C: c} }
+
+func (q Quaternion) Step(s *State) {
+	a, b := real(s.Z), real(s.Aux[0])
+	c, d := imag(s.Aux[0]), real(s.Aux[1])
+	a, b, c, d = a*a-b*b-c*c-d*d+real(s.C), 2*a*b+imag(s.C), 2*a*c+q.SliceZ, 2*a*d+q.SliceW
+	s.Aux[0], s.Aux[1] = complex(b, c), complex(d, 0)
+	v := b
+	if c != 0 || d != 0 {
+		v = math.Copysign(math.Sqrt(b*b+c*c+d*d), b)
+	}
+	s.Z = complex(a, v)
+}
+
+func (Quaternion) Escaped(s *State) bool { return escaped(s) }
@@ -0,0 +1,86 @@
+package fractal
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// quat is a quaternion a + bi + cj + dk.
+type quat [4]float64
+
+// mul is the Hamilton product p·q.
+func (p quat) mul(q quat) quat {
+	return quat{
+		p[0]*q[0] - p[1]*q[1] - p[2]*q[2] - p[3]*q[3],
+		p[0]*q[1] + p[1]*q[0] + p[2]*q[3] - p[3]*q[2],
+		p[0]*q[2] - p[1]*q[3] + p[2]*q[0] + p[3]*q[1],
+		p[0]*q[3] + p[1]*q[2] - p[2]*q[1] + p[3]*q[0],
+	}
+}
+
+func (p quat) abs() float64 { return math.Sqrt(p[0]*p[0] + p[1]*p[1] + p[2]*p[2] + p[3]*p[3]) }
+
+func TestQuaternionZeroSliceIsMandelbrot(t *testing.T) {
+	// with no j or k component the vector part stays on i, so every step
+	// is the complex one, to the bit
+	r := rand.New(rand.NewPCG(3, 4))
+	for i := range 20000 {
+		c := complex(r.Float64()*3-2.25, r.Float64()*3-1.5)
+		if i%2 == 0 {
+			// half of them near the boundary, where orbits run long
+			c = complex(-0.75+r.Float64()*0.01, 0.1+r.Float64()*0.01)
+		}
+		wantN, want := Iterate(Mandelbrot{}, c, 1000, DefaultBailoutSq)
+		gotN, got := Iterate(Quaternion{}, c, 1000, DefaultBailoutSq)
+		if gotN != wantN || got.Z != want.Z {
+			t.Fatalf("%v: quaternion %d, %v; Mandelbrot %d, %v", c, gotN, got.Z, wantN, want.Z)
+		}
+	}
+}
+
+func TestQuaternionStep(t *testing.T) {
+	// against q = q·q + c with the full Hamilton product: State.Z has the
+	// real part and modulus of q, and Aux its i, j and k parts
+	r := rand.New(rand.NewPCG(5, 6))
+	for range 1000 {
+		f := Quaternion{SliceZ: r.Float64() - 0.5, SliceW: r.Float64() - 0.5}
+		c := complex(r.Float64()*2.5-2, r.Float64()*2-1)
+		cq := quat{real(c), imag(c), f.SliceZ, f.SliceW}
+		var q quat
+		s := f.Init(c)
+		for n := range 8 {
+			q = q.mul(q)
+			for i := range q {
+				q[i] += cq[i]
+			}
+			f.Step(&s)
+			got := quat{real(s.Z), real(s.Aux[0]), imag(s.Aux[0]), real(s.Aux[1])}
+			for i := range q {
+				if math.Abs(got[i]-q[i]) > 1e-9*max(1, q.abs()) {
+					t.Fatalf("%v slice %v, %v: step %d: %v, want %v", c, f.SliceZ, f.SliceW, n, got, q)
+				}
+			}
+			if m := math.Hypot(real(s.Z), imag(s.Z)); math.Abs(m-q.abs()) > 1e-9*max(1, q.abs()) {
+				t.Fatalf("%v: step %d: |Z| = %v, |q| = %v", c, n, m, q.abs())
+			}
+			if q.abs() > 1e6 {
+				break
+			}
+		}
+	}
+}
+
+func TestQuaternionSliceIsTurnedMandelbrot(t *testing.T) {
+	// c = x + yi + zj + wk iterates like the complex x + i·sqrt(y²+z²+w²);
+	// away from the boundary the escape counts agree
+	f := Quaternion{SliceZ: 0.3, SliceW: -0.2}
+	for _, p := range [][2]float64{{-1, 0}, {0, 0}, {0.5, 0.5}, {-2, 1}, {-0.1, 0.4}, {1, 0}} {
+		x, y := p[0], p[1]
+		n, _ := Iterate(f, complex(x, y), 500, DefaultBailoutSq)
+		want, _ := Iterate(Mandelbrot{}, complex(x, math.Sqrt(y*y+f.SliceZ*f.SliceZ+f.SliceW*f.SliceW)), 500, DefaultBailoutSq)
+		if n != want {
+			t.Errorf("(%v, %v): escaped at %d, the turned Mandelbrot point at %d", x, y, n, want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package fractal
+
+import "math/cmplx"
+
+// DefaultSuperPower is the power of the Super that ByName returns.
+const DefaultSuperPower = 2.5
+
+// Super iterates z = z^Power + c from z = 0 for any real Power above 1,
+// the Multibrot generalized to fractional degrees. A whole-number Power
+// is taken by repeated multiplication, so 2 gives the Mandelbrot set's
+// escape counts exactly; any other by cmplx.Pow, on the principal
+// branch, whose cut along the negative real axis shows as a seam in the
+// picture.
+type Super struct {
+	Power float64
+}
+
+func (Super) Init(c complex128) State { return State{C: c} }
+func (p Super) Step(s *State) {
+	if k := int(p.Power); float64(k) == p.Power {
+		s.Z = ipow(s.Z, k) + s.C
+		return
+	}
+	s.Z = cmplx.Pow(s.Z, complex(p.Power, 0)) + s.C
+}
+func (Super) Escaped(s *State) bool { return escaped(s) }
+func (p Super) Degree() float64     { return p.Power }
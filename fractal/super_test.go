@@ -0,0 +1,55 @@
+package fractal
+
+import (
+	"math/cmplx"
+	"testing"
+)
+
+func TestSuperWholePowers(t *testing.T) {
+	// whole powers multiply, so 2 is the Mandelbrot set and 3 the cubic
+	// Multibrot to the bit
+	for y := range 150 {
+		for x := range 200 {
+			c := complex(-2.2+3.2*float64(x)/199, -1.6+3.2*float64(y)/149)
+			for _, tc := range []struct {
+				power float64
+				want  Fractal
+			}{
+				{2, Mandelbrot{}},
+				{3, Multibrot{Power: 3}},
+			} {
+				wantN, want := Iterate(tc.want, c, 500, DefaultBailoutSq)
+				n, got := Iterate(Super{Power: tc.power}, c, 500, DefaultBailoutSq)
+				if n != wantN || got.Z != want.Z {
+					t.Fatalf("power %v at %v: %d, %v; want %d, %v", tc.power, c, n, got.Z, wantN, want.Z)
+				}
+			}
+		}
+	}
+}
+
+func TestSuperFractionalPower(t *testing.T) {
+	f := Super{Power: 2.5}
+	if d := f.Degree(); d != 2.5 {
+		t.Errorf("Degree() = %v, want 2.5", d)
+	}
+	for _, c := range []complex128{0.3 + 0.2i, -0.5, -1 + 0.5i, 1i} {
+		s := f.Init(c)
+		z := complex128(0)
+		for n := range 5 {
+			f.Step(&s)
+			z = cmplx.Pow(z, 2.5) + c
+			if cmplx.Abs(s.Z-z) > 1e-12 {
+				t.Fatalf("%v: step %d: %v, want %v", c, n, s.Z, z)
+			}
+		}
+	}
+	// 0 is a fixed point; 1 runs 1, 2, 1+2^2.5, on the radius at 2, which
+	// the strict test keeps in, and out the step after
+	if n, _ := Iterate(f, 0, 100, DefaultBailoutSq); n != 100 {
+		t.Errorf("0 escaped at %d", n)
+	}
+	if n, _ := Iterate(f, 1, 100, DefaultBailoutSq); n != 2 {
+		t.Errorf("1 escaped at %d, want 2", n)
+	}
+}
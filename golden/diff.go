@@ -0,0 +1,73 @@
+package golden
+
+import (
+	"image"
+	"image/color"
+)
+
+// Colors of the DiffResult image.
+var (
+	diffChanged = color.RGBA{0xff, 0x00, 0x00, 0xff}
+	diffSame    = color.RGBA{0x00, 0xff, 0x00, 0xff}
+)
+
+// DiffResult is what DiffImages found.
+type DiffResult struct {
+	// Image is red where the two images differ by more than the
+	// threshold and green elsewhere.
+	Image *image.RGBA
+
+	DifferingPixels int     // pixels with a color channel beyond the threshold
+	MaxDelta        int     // largest per-channel difference seen
+	MeanAbsError    float64 // mean absolute difference over every color channel of every pixel
+}
+
+// DiffImages compares a and b pixel by pixel on their red, green and
+// blue channels, alpha aside, and counts the pixels where any of them
+// differ by more than threshold. Images of different sizes differ
+// everywhere: the result covers the larger of the two, all red, with the
+// largest delta and error possible.
+func DiffImages(a, b *image.RGBA, threshold int) DiffResult {
+	ab, bb := a.Bounds(), b.Bounds()
+	if ab.Size() != bb.Size() {
+		r := ab
+		if bb.Dx()*bb.Dy() > ab.Dx()*ab.Dy() {
+			r = bb
+		}
+		img := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+		for i := 0; i < len(img.Pix); i += 4 {
+			copy(img.Pix[i:i+4], []uint8{diffChanged.R, diffChanged.G, diffChanged.B, diffChanged.A})
+		}
+		return DiffResult{Image: img, DifferingPixels: r.Dx() * r.Dy(), MaxDelta: 255, MeanAbsError: 255}
+	}
+	res := DiffResult{Image: image.NewRGBA(image.Rect(0, 0, ab.Dx(), ab.Dy()))}
+	var sum int
+	for y := 0; y < ab.Dy(); y++ {
+		for x := 0; x < ab.Dx(); x++ {
+			p := a.RGBAAt(ab.Min.X+x, ab.Min.Y+y)
+			q := b.RGBAAt(bb.Min.X+x, bb.Min.Y+y)
+			dr, dg, db := absDiff8(p.R, q.R), absDiff8(p.G, q.G), absDiff8(p.B, q.B)
+			sum += dr + dg + db
+			delta := max(dr, dg, db)
+			res.MaxDelta = max(res.MaxDelta, delta)
+			c := diffSame
+			if delta > threshold {
+				c = diffChanged
+				res.DifferingPixels++
+			}
+			res.Image.SetRGBA(x, y, c)
+		}
+	}
+	if n := ab.Dx() * ab.Dy(); n > 0 {
+		res.MeanAbsError = float64(sum) / float64(3*n)
+	}
+	return res
+}
+
+// absDiff8 returns |a-b| for two 8-bit channels.
+func absDiff8(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
@@ -0,0 +1,99 @@
+package golden
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// ramp returns a w×h image at origin whose pixels all differ.
+func ramp(origin image.Point, w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rectangle{origin, origin.Add(image.Pt(w, h))})
+	for y := range h {
+		for x := range w {
+			img.SetRGBA(origin.X+x, origin.Y+y, color.RGBA{uint8(10 * x), uint8(20 * y), uint8(x + y), 0xff})
+		}
+	}
+	return img
+}
+
+func TestDiffImagesSame(t *testing.T) {
+	img := ramp(image.Point{}, 8, 6)
+	res := DiffImages(img, img, 0)
+	if res.DifferingPixels != 0 || res.MaxDelta != 0 || res.MeanAbsError != 0 {
+		t.Errorf("an image against itself: %d pixels, max %d, mean %v", res.DifferingPixels, res.MaxDelta, res.MeanAbsError)
+	}
+	for y := range 6 {
+		for x := range 8 {
+			if got := res.Image.RGBAAt(x, y); got != diffSame {
+				t.Fatalf("(%d, %d) is %v, want green", x, y, got)
+			}
+		}
+	}
+}
+
+func TestDiffImagesOnePixel(t *testing.T) {
+	a := ramp(image.Point{}, 8, 6)
+	// the same pixels at another origin compare by position in the image
+	b := ramp(image.Pt(100, -50), 8, 6)
+	p := b.RGBAAt(103, -48)
+	p.G += 7
+	p.A = 0 // alpha isn't compared
+	b.SetRGBA(103, -48, p)
+
+	res := DiffImages(a, b, 0)
+	if res.DifferingPixels != 1 {
+		t.Errorf("%d differing pixels, want 1", res.DifferingPixels)
+	}
+	if res.MaxDelta != 7 {
+		t.Errorf("max delta %d, want 7", res.MaxDelta)
+	}
+	if want := 7.0 / (3 * 8 * 6); math.Abs(res.MeanAbsError-want) > 1e-12 {
+		t.Errorf("mean error %v, want %v", res.MeanAbsError, want)
+	}
+	if res.Image.Rect != image.Rect(0, 0, 8, 6) {
+		t.Fatalf("diff image %v", res.Image.Rect)
+	}
+	for y := range 6 {
+		for x := range 8 {
+			want := diffSame
+			if x == 3 && y == 2 {
+				want = diffChanged
+			}
+			if got := res.Image.RGBAAt(x, y); got != want {
+				t.Errorf("(%d, %d) is %v, want %v", x, y, got, want)
+			}
+		}
+	}
+
+	// within the threshold it counts as the same, though the delta and
+	// error still show it
+	res = DiffImages(a, b, 7)
+	if res.DifferingPixels != 0 || res.MaxDelta != 7 || res.Image.RGBAAt(3, 2) != diffSame {
+		t.Errorf("threshold 7: %d pixels, max %d", res.DifferingPixels, res.MaxDelta)
+	}
+	if res = DiffImages(a, b, 6); res.DifferingPixels != 1 {
+		t.Errorf("threshold 6: %d pixels", res.DifferingPixels)
+	}
+}
+
+func TestDiffImagesSizes(t *testing.T) {
+	a, b := ramp(image.Point{}, 8, 6), ramp(image.Point{}, 6, 10)
+	res := DiffImages(a, b, 255)
+	if res.Image.Rect != image.Rect(0, 0, 6, 10) {
+		t.Errorf("diff image %v, want the larger 6×10", res.Image.Rect)
+	}
+	if res.DifferingPixels != 60 || res.MaxDelta != 255 || res.MeanAbsError != 255 {
+		t.Errorf("%d pixels, max %d, mean %v", res.DifferingPixels, res.MaxDelta, res.MeanAbsError)
+	}
+	for i := 0; i < len(res.Image.Pix); i += 4 {
+		if got := res.Image.Pix[i : i+4]; got[0] != 0xff || got[1] != 0 {
+			t.Fatalf("pixel %d is %v, want red", i/4, got)
+		}
+	}
+	empty := image.NewRGBA(image.Rectangle{})
+	if res := DiffImages(empty, empty, 0); res.DifferingPixels != 0 || res.MeanAbsError != 0 {
+		t.Errorf("empty images: %+v", res)
+	}
+}
@@ -0,0 +1,91 @@
+// Package golden compares renders against checked-in reference images.
+package golden
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Diff summarizes how two images differ.
+type Diff struct {
+	Pixels   int // pixels with any channel outside the tolerance
+	MaxDelta int // largest per-channel difference seen
+}
+
+// Compare counts the pixels of got that differ from want by more than
+// tolerance in any channel. Images of different sizes differ everywhere.
+func Compare(got, want image.Image, tolerance int) Diff {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Size() != wb.Size() {
+		return Diff{Pixels: max(gb.Dx()*gb.Dy(), wb.Dx()*wb.Dy()), MaxDelta: 255}
+	}
+	var d Diff
+	for y := 0; y < gb.Dy(); y++ {
+		for x := 0; x < gb.Dx(); x++ {
+			r1, g1, b1, a1 := got.At(gb.Min.X+x, gb.Min.Y+y).RGBA()
+			r2, g2, b2, a2 := want.At(wb.Min.X+x, wb.Min.Y+y).RGBA()
+			delta := max(absDiff(r1, r2), absDiff(g1, g2), absDiff(b1, b2), absDiff(a1, a2))
+			d.MaxDelta = max(d.MaxDelta, delta)
+			if delta > tolerance {
+				d.Pixels++
+			}
+		}
+	}
+	return d
+}
+
+// absDiff returns |a-b| for two 16-bit channels, scaled to 8 bits.
+func absDiff(a, b uint32) int {
+	d := int(a>>8) - int(b>>8)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// Check compares got against the PNG at path. More than maxPixels pixels
+// outside tolerance is an error. With update set (or when the golden file
+// doesn't exist yet) got is written to path instead.
+func Check(path string, got image.Image, tolerance, maxPixels int, update bool) (Diff, error) {
+	want, err := load(path)
+	if update || errors.Is(err, fs.ErrNotExist) {
+		return Diff{}, write(path, got)
+	}
+	if err != nil {
+		return Diff{}, err
+	}
+	d := Compare(got, want, tolerance)
+	if d.Pixels > maxPixels {
+		return d, fmt.Errorf("%s: %d pixels differ by more than %d (max delta %d)", path, d.Pixels, tolerance, d.MaxDelta)
+	}
+	return d, nil
+}
+
+func load(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func write(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,67 @@
+package golden
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update-golden", false, "regenerate the golden files instead of comparing")
+
+// goldenDir is testdata/golden at the top of the module.
+var goldenDir = filepath.Join("..", "testdata", "golden")
+
+// checkScenes runs CheckScenes with cmd/golden's defaults, ±1 per channel
+// and 10 pixels per scene, failing t on any report with an error.
+func checkScenes(t *testing.T, scenes []Scene) {
+	t.Helper()
+	for _, s := range scenes {
+		if _, err := os.Stat(filepath.Join(goldenDir, s.Name+".png")); err != nil && !*update {
+			t.Errorf("%s: no golden image: %v", s.Name, err)
+		}
+	}
+	for _, r := range CheckScenes(goldenDir, scenes, 1, 10, *update) {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.Scene, r.Err)
+		}
+	}
+}
+
+func TestGoldenRenders(t *testing.T) {
+	checkScenes(t, PaletteScenes())
+}
+
+func TestGoldenScenes(t *testing.T) {
+	checkScenes(t, Scenes())
+}
+
+func TestCheckPixelBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gray.png")
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	if _, err := Check(path, img, 1, 10, false); err != nil {
+		t.Fatalf("writing the first golden: %v", err)
+	}
+
+	changed := image.NewRGBA(img.Rect)
+	copy(changed.Pix, img.Pix)
+	// off by the tolerance everywhere, which doesn't count
+	for i := range changed.Pix {
+		changed.Pix[i]++
+	}
+	for n := range 11 {
+		changed.SetRGBA(n, 0, color.RGBA{0, 0, 0, 0xff})
+		d, err := Check(path, changed, 1, 10, false)
+		if d.Pixels != n+1 {
+			t.Errorf("%d pixels changed, Diff counts %d", n+1, d.Pixels)
+		}
+		if (err != nil) != (n+1 > 10) {
+			t.Errorf("%d pixels changed: err = %v", n+1, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package golden
+
+import (
+	"context"
+	"image"
+	"path/filepath"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// A Scene is a named, fully pinned render used as a regression reference.
+// Its golden image lives at <dir>/<Name>.png.
+type Scene struct {
+	Name    string
+	Options []render.Option
+}
+
+// Render renders s.
+func (s Scene) Render() (*image.RGBA, error) {
+	opts, err := render.New(s.Options...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		return nil, err
+	}
+	return res.Image, nil
+}
+
+// sceneWidth and sceneHeight are the size of the canonical scenes.
+const sceneWidth, sceneHeight = 256, 192
+
+// Scenes returns the canonical scenes: the default view, a zoom into
+// seahorse valley, each fractal formula and each coloring mode.
+func Scenes() []Scene {
+	base := func(extra ...render.Option) []render.Option {
+		return append([]render.Option{
+			render.WithSize(sceneWidth, sceneHeight),
+			render.WithIterations(200),
+			render.WithPaletteName(render.DefaultPalette),
+		}, extra...)
+	}
+	seahorse := coords.Bounds{Xmin: -0.7536, Xmax: -0.7336, Ymin: 0.1243, Ymax: 0.1393}
+	scenes := []Scene{
+		{"default", base()},
+		{"zoom-seahorse", base(render.WithViewport(seahorse), render.WithIterations(600))},
+		{"julia", base(render.WithFractalName("julia", complex(-0.8, 0.156)),
+			render.WithViewport(coords.Bounds{Xmin: -1.6, Xmax: 1.6, Ymin: -1.2, Ymax: 1.2}))},
+		{"burningship", base(render.WithFractalName("burningship", 0),
+			render.WithViewport(coords.Bounds{Xmin: -2.2, Xmax: 1.4, Ymin: -2.0, Ymax: 0.7}))},
+		{"multibrot", base(render.WithFractalName("multibrot", 0),
+			render.WithViewport(coords.Bounds{Xmin: -1.6, Xmax: 1.6, Ymin: -1.2, Ymax: 1.2}))},
+	}
+	for _, c := range render.Colorings {
+		if c == render.DefaultColoring {
+			continue // covered by "default"
+		}
+		opts := base(render.WithColoring(c))
+		if c == render.ColoringFixedTrap {
+			opts = append(opts, render.WithTrapPoints([]complex128{0, 1, -1}))
+		}
+		scenes = append(scenes, Scene{"coloring-" + string(c), opts})
+	}
+	return scenes
+}
+
+// PaletteScenes returns one small default-view scene per built-in palette.
+func PaletteScenes() []Scene {
+	var scenes []Scene
+	for _, p := range palette.ColorPalettes {
+		scenes = append(scenes, Scene{p.Keyword, []render.Option{
+			render.WithSize(128, 96),
+			render.WithIterations(100),
+			render.WithPaletteName(p.Keyword),
+		}})
+	}
+	return scenes
+}
+
+// Report is the outcome of checking one scene.
+type Report struct {
+	Scene string
+	Path  string
+	Diff  Diff
+	Err   error // render, I/O or comparison failure
+}
+
+// CheckScenes renders each scene and compares it with its golden image in
+// dir using Check. With update set the goldens are rewritten instead.
+func CheckScenes(dir string, scenes []Scene, tolerance, maxPixels int, update bool) []Report {
+	reports := make([]Report, len(scenes))
+	for i, s := range scenes {
+		r := Report{Scene: s.Name, Path: filepath.Join(dir, s.Name+".png")}
+		img, err := s.Render()
+		if err != nil {
+			r.Err = err
+		} else {
+			r.Diff, r.Err = Check(r.Path, img, tolerance, maxPixels, update)
+		}
+		reports[i] = r
+	}
+	return reports
+}
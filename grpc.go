@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"log"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/render"
+	"github.com/whalelogic/mandlebrot/renderpb"
+)
+
+// Images up to grpcSingleMax pixels are sent as one chunk unless the
+// request asks for tiles; larger ones are cut into grpcTileSize tiles.
+const (
+	grpcSingleMax = 2048 * 2048
+	grpcTileSize  = 512
+)
+
+// grpcServer implements renderpb.RendererServer on top of the HTTP
+// server's limits and render slots.
+type grpcServer struct {
+	renderpb.UnimplementedRendererServer
+	s *server
+}
+
+// serveGRPC listens on addr and serves the Renderer service until ctx
+// ends, then stops gracefully.
+func (s *server) serveGRPC(ctx context.Context, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	gs := grpc.NewServer()
+	renderpb.RegisterRendererServer(gs, &grpcServer{s: s})
+	go func() {
+		<-ctx.Done()
+		gs.GracefulStop()
+	}()
+	log.Printf("gRPC listening on %s", lis.Addr())
+	return gs.Serve(lis)
+}
+
+// ListPalettes returns the built-in palette names.
+func (g *grpcServer) ListPalettes(context.Context, *renderpb.ListPalettesRequest) (*renderpb.ListPalettesResponse, error) {
+	return &renderpb.ListPalettesResponse{Names: palette.List()}, nil
+}
+
+// Render renders req and streams it back as PNG chunks. Rows arrive from
+// the renderer in order, so each band of tiles is encoded and sent as
+// soon as its last row is done while the rest is still being computed.
+func (g *grpcServer) Render(req *renderpb.RenderRequest, stream renderpb.Renderer_RenderServer) (err error) {
+	start := time.Now()
+	defer func() {
+		g.s.metrics.observeRequest("grpc Render", grpcOutcome(status.Code(err)), time.Since(start))
+	}()
+
+	opts, err := g.s.requestOptions(req)
+	if err != nil {
+		return grpcError(err)
+	}
+	tile := int(req.TileSize)
+	if tile <= 0 {
+		tile = grpcTileSize
+		if opts.Width*opts.Height <= grpcSingleMax {
+			tile = max(opts.Width, opts.Height)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	select {
+	case g.s.slots <- struct{}{}:
+		defer func() { <-g.s.slots }()
+	case <-ctx.Done():
+		return grpcError(&render.CancelledError{Total: opts.Height, Err: ctx.Err()})
+	}
+
+	band := image.NewRGBA(image.Rect(0, 0, opts.Width, min(tile, opts.Height)))
+	var sendErr error
+	opts.OrderedRegions = true
+	opts.OnRegion = func(rect image.Rectangle, pixels []color.RGBA) {
+		for y := rect.Min.Y; y < rect.Max.Y && sendErr == nil; y++ {
+			row := pixels[(y-rect.Min.Y)*rect.Dx():][:rect.Dx()]
+			for i, c := range row {
+				band.SetRGBA(rect.Min.X+i, y, c)
+			}
+			if y+1 == band.Rect.Max.Y {
+				if sendErr = sendBand(stream, band, tile, opts.Width, opts.Height); sendErr != nil {
+					cancel() // the client is gone; stop rendering
+					return
+				}
+				band.Rect = image.Rect(0, y+1, opts.Width, min(y+1+tile, opts.Height))
+			}
+		}
+	}
+	_, err = render.Render(ctx, opts)
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return grpcError(err)
+	}
+	return nil
+}
+
+// sendBand sends band as tile-wide PNG chunks, left to right.
+func sendBand(stream renderpb.Renderer_RenderServer, band *image.RGBA, tile, width, height int) error {
+	for x := 0; x < width; x += tile {
+		r := image.Rect(x, band.Rect.Min.Y, min(x+tile, width), band.Rect.Max.Y)
+		var buf bytes.Buffer
+		if err := render.Encode(&buf, band.SubImage(r), "png"); err != nil {
+			return err
+		}
+		err := stream.Send(&renderpb.RenderChunk{
+			X: int32(r.Min.X), Y: int32(r.Min.Y),
+			Width: int32(r.Dx()), Height: int32(r.Dy()),
+			ImageWidth: int32(width), ImageHeight: int32(height),
+			Png: buf.Bytes(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requestOptions builds render options from a gRPC request, applying the
+// same defaults and limits as /render.
+func (s *server) requestOptions(req *renderpb.RenderRequest) (render.Options, error) {
+	width, height := int(req.Width), int(req.Height)
+	if width == 0 {
+		width = 800
+	}
+	if height == 0 {
+		height = 600
+	}
+	iters := int(req.MaxIter)
+	if iters == 0 {
+		iters = render.DefaultMaxIter
+	}
+	if errs := s.limitErrors(width, height, iters); len(errs) > 0 {
+		return render.Options{}, errors.Join(errs...)
+	}
+
+	bounds := coords.Bounds{Xmin: req.Xmin, Xmax: req.Xmax, Ymin: req.Ymin, Ymax: req.Ymax}
+	if bounds == (coords.Bounds{}) {
+		bounds = render.DefaultBounds
+		if width > 0 && height > 0 {
+			bounds = bounds.FitToImage(width, height)
+		}
+	}
+	opts := []render.Option{
+		render.WithSize(width, height),
+		render.WithViewport(bounds),
+		render.WithRotation(req.Rotation),
+		render.WithIterations(iters),
+		render.WithProcs(s.procs),
+		render.WithMetrics(s.metrics),
+		render.WithDiscardBuffers(true),
+	}
+	if req.Bailout != 0 {
+		opts = append(opts, render.WithBailout(req.Bailout))
+	}
+	if req.Palette != "" {
+		opts = append(opts, render.WithPaletteName(req.Palette))
+	}
+	if req.Fractal != "" {
+		opts = append(opts, render.WithFractalName(req.Fractal, complex(req.JuliaRe, req.JuliaIm)))
+	}
+	if req.Coloring != "" {
+		opts = append(opts, render.WithColoring(render.Coloring(req.Coloring)))
+	}
+	if req.Bands != 0 {
+		opts = append(opts, render.WithBands(int(req.Bands)))
+	}
+	if req.BlendSmooth != 0 {
+		opts = append(opts, render.WithBlendSmooth(req.BlendSmooth))
+	}
+	if req.ZmagSmooth != 0 {
+		opts = append(opts, render.WithZmagSmooth(req.ZmagSmooth))
+	}
+	return render.New(opts...)
+}
+
+// grpcOutcome buckets a status code for the outcome label.
+func grpcOutcome(code codes.Code) string {
+	switch code {
+	case codes.OK:
+		return "ok"
+	case codes.Canceled, codes.DeadlineExceeded:
+		return "cancelled"
+	case codes.InvalidArgument:
+		return "client_error"
+	default:
+		return "error"
+	}
+}
+
+// grpcError maps a render error to a gRPC status: bad requests to
+// InvalidArgument, an expired deadline or cancelled call to
+// DeadlineExceeded or Canceled, and anything else to Internal.
+func grpcError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, render.ErrInvalidOptions), errors.Is(err, render.ErrInvalidViewport),
+		errors.Is(err, render.ErrUnknownPalette), errors.Is(err, render.ErrPrecisionExceeded):
+		code = codes.InvalidArgument
+	}
+	return status.Error(code, err.Error())
+}
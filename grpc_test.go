@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"net"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/render"
+	"github.com/whalelogic/mandlebrot/renderpb"
+)
+
+// grpcTestClient serves s's Renderer service on a loopback port for the
+// length of the test and returns a client connected to it.
+func grpcTestClient(t *testing.T, s *server) renderpb.RendererClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	renderpb.RegisterRendererServer(gs, &grpcServer{s: s})
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return renderpb.NewRendererClient(conn)
+}
+
+func testServer() *server {
+	return &server{slots: make(chan struct{}, 2), maxPixels: 4096 * 4096, maxIters: 20000, metrics: newServerMetrics()}
+}
+
+func TestGRPCRender(t *testing.T) {
+	s := testServer()
+	client := grpcTestClient(t, s)
+	req := &renderpb.RenderRequest{Width: 300, Height: 200, MaxIter: 300, Palette: "ThermalHeat", TileSize: 128}
+
+	stream, err := client.Render(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := image.NewRGBA(image.Rect(0, 0, 300, 200))
+	var chunks int
+	for {
+		c, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks++
+		if c.ImageWidth != 300 || c.ImageHeight != 200 || c.Width > 128 || c.Height > 128 {
+			t.Errorf("chunk %d×%d of %d×%d", c.Width, c.Height, c.ImageWidth, c.ImageHeight)
+		}
+		tile, err := png.Decode(bytes.NewReader(c.Png))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := image.Rect(int(c.X), int(c.Y), int(c.X+c.Width), int(c.Y+c.Height))
+		draw.Draw(got, r, tile, tile.Bounds().Min, draw.Src)
+	}
+	// 3 columns of tiles by 2 rows
+	if chunks != 6 {
+		t.Errorf("%d chunks, want 6", chunks)
+	}
+
+	opts, err := s.requestOptions(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Pix, res.Image.Pix) {
+		t.Error("tiles differ from a local render of the same request")
+	}
+}
+
+func TestGRPCErrors(t *testing.T) {
+	s := testServer()
+	client := grpcTestClient(t, s)
+	for _, tc := range []struct {
+		name string
+		req  *renderpb.RenderRequest
+		want codes.Code
+	}{
+		{"unknown palette", &renderpb.RenderRequest{Width: 8, Height: 8, Palette: "NoSuchPalette"}, codes.InvalidArgument},
+		{"negative size", &renderpb.RenderRequest{Width: -8, Height: 8}, codes.InvalidArgument},
+		{"above the pixel limit", &renderpb.RenderRequest{Width: 8192, Height: 8192}, codes.InvalidArgument},
+		{"inverted viewport", &renderpb.RenderRequest{Width: 8, Height: 8, Xmin: 1, Xmax: -1, Ymin: -1, Ymax: 1}, codes.InvalidArgument},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stream, err := client.Render(context.Background(), tc.req)
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			if status.Code(err) != tc.want {
+				t.Errorf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestGRPCListPalettes(t *testing.T) {
+	resp, err := grpcTestClient(t, testServer()).ListPalettes(context.Background(), &renderpb.ListPalettesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(resp.Names, palette.List()) {
+		t.Errorf("got %v, want %v", resp.Names, palette.List())
+	}
+}
@@ -0,0 +1,31 @@
+// Package blur holds the Gaussian kernel shared by the bloom pass in
+// render and the blur steps in postprocess.
+package blur
+
+import "math"
+
+// Kernel is a symmetric convolution kernel: its weights from the center
+// out, unnormalized.
+type Kernel []float32
+
+// Gaussian returns the kernel of a Gaussian of deviation sigma, out to 3
+// deviations from its center and at least one step.
+func Gaussian(sigma float64) Kernel {
+	n := max(1, int(math.Ceil(3*sigma)))
+	k := make(Kernel, n+1)
+	for i := range k {
+		k[i] = float32(math.Exp(-float64(i*i) / (2 * sigma * sigma)))
+	}
+	return k
+}
+
+// Radius returns how far k reaches either side of its center.
+func (k Kernel) Radius() int { return len(k) - 1 }
+
+// At returns the weight at offset i from the center, on either side.
+func (k Kernel) At(i int) float32 {
+	if i < 0 {
+		i = -i
+	}
+	return k[i]
+}
@@ -0,0 +1,29 @@
+package blur
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGaussian(t *testing.T) {
+	for _, sigma := range []float64{0.1, 1, 2.5, 12} {
+		k := Gaussian(sigma)
+		if want := max(1, int(math.Ceil(3*sigma))); k.Radius() != want {
+			t.Errorf("sigma %g: radius %d, want %d", sigma, k.Radius(), want)
+		}
+		if k[0] != 1 {
+			t.Errorf("sigma %g: center weight %g, want 1", sigma, k[0])
+		}
+		for i := 1; i <= k.Radius(); i++ {
+			if !(k[i] < k[i-1]) || k[i] < 0 {
+				t.Errorf("sigma %g: weight %d is %g after %g", sigma, i, k[i], k[i-1])
+			}
+			if k.At(i) != k.At(-i) || k.At(i) != k[i] {
+				t.Errorf("sigma %g: At(%d) = %g, At(%d) = %g", sigma, i, k.At(i), -i, k.At(-i))
+			}
+		}
+		if want := float32(math.Exp(-0.5)); sigma == 1 && math.Abs(float64(k[1]-want)) > 1e-7 {
+			t.Errorf("sigma 1: weight 1 is %g, want %g", k[1], want)
+		}
+	}
+}
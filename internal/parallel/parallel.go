@@ -0,0 +1,20 @@
+// Package parallel splits per-row image work over goroutines, for the
+// render and postprocess packages.
+package parallel
+
+import "sync"
+
+// Rows calls fn for each of n rows, split into interleaved shares over
+// procs goroutines, at least one and no more than there are rows.
+func Rows(n, procs int, fn func(row int)) {
+	procs = min(max(procs, 1), n)
+	var wg sync.WaitGroup
+	for p := range procs {
+		wg.Go(func() {
+			for row := p; row < n; row += procs {
+				fn(row)
+			}
+		})
+	}
+	wg.Wait()
+}
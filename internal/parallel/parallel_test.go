@@ -0,0 +1,20 @@
+package parallel
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestRows(t *testing.T) {
+	for _, n := range []int{0, 1, 7, 100} {
+		for _, procs := range []int{-1, 0, 1, 3, 200} {
+			counts := make([]atomic.Int32, n)
+			Rows(n, procs, func(row int) { counts[row].Add(1) })
+			for row := range counts {
+				if c := counts[row].Load(); c != 1 {
+					t.Errorf("n %d, procs %d: row %d done %d times", n, procs, row, c)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package location
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// kfrDefaults are the values of the KF fields that change the picture
+// at which they leave the standard Mandelbrot set alone. Any other value
+// is reported in Location.Warnings.
+var kfrDefaults = map[string]string{
+	"FractalType": "0",   // the Mandelbrot set
+	"Power":       "2",   // z² + c
+	"Rotate":      "0",   // degrees
+	"Ratio":       "360", // no stretch of the imaginary axis
+	"real":        "1",   // weight of the real part of z²
+	"imag":        "1",   // weight of the imaginary part of z²
+	"SeedR":       "0",   // starting z
+	"SeedI":       "0",
+	"FactorAR":    "1",
+	"FactorAI":    "0",
+}
+
+// ParseKFR reads a Kalles Fraktaler location: "Key: value" lines, with
+// the center in Re and Im as decimal strings often hundreds of digits
+// long, and Zoom, for which the view is 4/Zoom high. Re, Im and Zoom are
+// required. Fields whose values would change the picture get a warning
+// each; KF's coloring fields are listed in one more.
+func ParseKFR(r io.Reader) (Location, error) {
+	fields := make(map[string]string)
+	var order []string
+	sc := bufio.NewScanner(r)
+	// Re and Im of deep locations run to thousands of digits
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		key, value, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if _, dup := fields[key]; !dup {
+			order = append(order, key)
+		}
+		fields[key] = value
+	}
+	if err := sc.Err(); err != nil {
+		return Location{}, err
+	}
+
+	var loc Location
+	for _, key := range []string{"Re", "Im", "Zoom"} {
+		if _, ok := fields[key]; !ok {
+			return Location{}, fmt.Errorf("no %s field", key)
+		}
+	}
+	re, err := strconv.ParseFloat(fields["Re"], 64)
+	if err != nil {
+		return Location{}, fmt.Errorf("Re: %w", err)
+	}
+	im, err := strconv.ParseFloat(fields["Im"], 64)
+	if err != nil {
+		return Location{}, fmt.Errorf("Im: %w", err)
+	}
+	loc.Center = complex(re, im)
+	zoom, err := strconv.ParseFloat(fields["Zoom"], 64)
+	switch {
+	case math.IsInf(zoom, 0):
+		return Location{}, fmt.Errorf("Zoom %s: beyond the range of float64", fields["Zoom"])
+	case err != nil:
+		return Location{}, fmt.Errorf("Zoom: %w", err)
+	case !(zoom > 0):
+		return Location{}, fmt.Errorf("Zoom %s: must be positive", fields["Zoom"])
+	}
+	loc.Height = 4 / zoom
+	if v, ok := fields["Iterations"]; ok {
+		if loc.Iterations, err = strconv.Atoi(v); err != nil || loc.Iterations < 1 {
+			return Location{}, fmt.Errorf("Iterations %q: must be a positive integer", v)
+		}
+	}
+
+	var ignored []string
+	for _, key := range order {
+		switch key {
+		case "Re", "Im", "Zoom", "Iterations":
+			continue
+		}
+		def, known := kfrDefaults[key]
+		if !known {
+			ignored = append(ignored, key)
+			continue
+		}
+		if !sameNumber(fields[key], def) {
+			loc.Warnings = append(loc.Warnings, fmt.Sprintf("%s %s: only %s is supported; ignored", key, fields[key], def))
+		}
+	}
+	if w := ignoredWarning("KF", ignored); w != "" {
+		loc.Warnings = append(loc.Warnings, w)
+	}
+	return loc, nil
+}
@@ -0,0 +1,98 @@
+package location
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func TestReadKFR(t *testing.T) {
+	loc, err := Read("testdata/seahorse.kfr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the view is 4/Zoom high
+	if loc.Center != complex(-0.75, 0.1) || loc.Height != 0.1 || loc.Width != 0 || loc.Iterations != 1500 {
+		t.Errorf("got %+v", loc)
+	}
+	// only the coloring fields go unsupported, listed in one warning
+	if len(loc.Warnings) != 1 || !strings.HasPrefix(loc.Warnings[0], "ignored KF settings: ") {
+		t.Fatalf("warnings %q", loc.Warnings)
+	}
+	ignored := strings.Split(strings.TrimPrefix(loc.Warnings[0], "ignored KF settings: "), ", ")
+	if !slices.Contains(ignored, "Colors") || slices.Contains(ignored, "Power") || slices.Contains(ignored, "Re") {
+		t.Errorf("ignored %q", ignored)
+	}
+
+	// it renders the seahorse valley window a 4:3 image of it shows
+	b := loc.Bounds(64, 48)
+	want := coords.Bounds{Xmin: -0.75 - 0.1*64/48/2, Xmax: -0.75 + 0.1*64/48/2, Ymin: 0.05, Ymax: 0.15}
+	if math.Abs(b.Xmin-want.Xmin) > 1e-15 || math.Abs(b.Xmax-want.Xmax) > 1e-15 ||
+		math.Abs(b.Ymin-want.Ymin) > 1e-15 || math.Abs(b.Ymax-want.Ymax) > 1e-15 {
+		t.Fatalf("bounds %+v, want %+v", b, want)
+	}
+	pixels := func(b coords.Bounds) []byte {
+		opts, err := render.New(render.WithSize(64, 48), render.WithViewport(b), render.WithIterations(loc.Iterations))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := render.Render(context.Background(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Image.Pix
+	}
+	// the bounds may be an ulp off the hand-computed ones, which moves a
+	// pixel or two across a color boundary
+	got, exp := pixels(b), pixels(want)
+	n := 0
+	for i := range got {
+		if got[i] != exp[i] {
+			n++
+		}
+	}
+	if n > len(got)/1000 {
+		t.Errorf("%d of %d bytes differ from a render of the window", n, len(got))
+	}
+}
+
+func TestReadKFRDeep(t *testing.T) {
+	loc, err := Read("testdata/deep.kfr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the center rounds to the nearest float64 of its 120 digits
+	if loc.Center != complex(-1.749003000849998, 7.3619432493006414e-15) {
+		t.Errorf("center %v", loc.Center)
+	}
+	if math.Abs(loc.Height/(4/4.25e37)-1) > 1e-15 || loc.Iterations != 12000 {
+		t.Errorf("height %v, %d iterations", loc.Height, loc.Iterations)
+	}
+	for _, w := range []string{"Power 3: only 2 is supported; ignored", "Rotate 15.5: only 0 is supported; ignored"} {
+		if !slices.Contains(loc.Warnings, w) {
+			t.Errorf("no warning %q in %q", w, loc.Warnings)
+		}
+	}
+}
+
+func TestReadKFRErrors(t *testing.T) {
+	for _, path := range []string{
+		"testdata/nozoom.kfr",
+		"testdata/badzoom.kfr",
+		"testdata/seahorse.kfr#entry",
+		"testdata/missing.kfr",
+		"testdata/seahorse.txt",
+	} {
+		if _, err := Read(path); err == nil {
+			t.Errorf("%s: no error", path)
+		}
+	}
+	if _, err := ParseKFR(strings.NewReader("Re: 0\nIm: 0\nZoom: 1e400\n")); err == nil || !strings.Contains(err.Error(), "float64") {
+		t.Errorf("Zoom 1e400: %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+// Package location reads views saved by other fractal programs: Kalles
+// Fraktaler .kfr files, Fractint .par entries and Ultra Fractal .upr
+// parameter sets. Each format's coordinates, iteration limit and, where
+// there is an equivalent, formula are mapped onto a Location; settings
+// that have no equivalent are listed in its Warnings rather than
+// dropped silently.
+package location
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+// Location is the part of a saved view this renderer can reproduce.
+type Location struct {
+	// Center is the center of the view rounded to the nearest float64.
+	// Locations deeper than float64 can resolve give Bounds too narrow
+	// to render.
+	Center complex128
+	// Height is the imaginary-axis extent of the view. Width is its
+	// real-axis extent for formats that give one, 0 when the width
+	// follows the image's aspect ratio.
+	Height, Width float64
+	// Iterations is the iteration limit, 0 if the file has none.
+	Iterations int
+	// Fractal is the name of the formula (see fractal.Names), "" if the
+	// file doesn't say. Julia is the Julia parameter when it is "julia",
+	// and Z0 the starting z when it is "mandelbrot".
+	Fractal string
+	Julia   complex128
+	Z0      complex128
+	// Warnings describes the settings that were ignored, one line each.
+	Warnings []string
+}
+
+// Bounds returns the view of l for a width×height image. A view with
+// only a height is as wide as the image's aspect ratio makes it; one
+// with both extents is widened in one direction to fit the image, so
+// all of it stays in view with square pixels.
+func (l Location) Bounds(width, height int) coords.Bounds {
+	h := l.Height
+	w := l.Width
+	if w == 0 {
+		w = h * float64(width) / float64(height)
+	}
+	c := l.Center
+	b := coords.Bounds{Xmin: real(c) - w/2, Xmax: real(c) + w/2, Ymin: imag(c) - h/2, Ymax: imag(c) + h/2}
+	if l.Width != 0 {
+		b = b.FitToImage(width, height)
+	}
+	return b
+}
+
+// Read reads the location at path, picking the format from the
+// extension: .kfr, .par or .upr. PAR and UPR files hold named entries;
+// path may end in #Name to select one, and otherwise the first is used.
+func Read(path string) (Location, error) {
+	file, entry, _ := strings.Cut(path, "#")
+	f, err := os.Open(file)
+	if err != nil {
+		return Location{}, err
+	}
+	defer f.Close()
+	var loc Location
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".kfr":
+		if entry != "" {
+			return Location{}, fmt.Errorf("%s: a .kfr file holds a single location, not entry %q", file, entry)
+		}
+		loc, err = ParseKFR(f)
+	case ".par":
+		loc, err = ParsePAR(f, entry)
+	case ".upr":
+		loc, err = ParseUPR(f, entry)
+	default:
+		return Location{}, fmt.Errorf("%s: unknown location format %q: use .kfr, .par or .upr", file, ext)
+	}
+	if err != nil {
+		return Location{}, fmt.Errorf("%s: %w", file, err)
+	}
+	return loc, nil
+}
+
+// An entry is one named block of a PAR or UPR file.
+type entry struct {
+	name   string
+	tokens []string // whitespace-separated, comments removed
+}
+
+// readEntries splits r into its "Name { ... }" blocks. A ';' outside
+// double quotes starts a comment that runs to the end of the line, and
+// double-quoted strings are kept whole as part of their token.
+func readEntries(r io.Reader) ([]entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		entries []entry
+		cur     *entry
+		tok     strings.Builder
+		pending []string // tokens before the next '{', the entry name
+		quoted  bool
+	)
+	flush := func() {
+		if tok.Len() == 0 {
+			return
+		}
+		if cur != nil {
+			cur.tokens = append(cur.tokens, tok.String())
+		} else {
+			pending = append(pending, tok.String())
+		}
+		tok.Reset()
+	}
+	for i := 0; i < len(data); i++ {
+		ch := data[i]
+		switch {
+		case quoted:
+			tok.WriteByte(ch)
+			quoted = ch != '"'
+		case ch == '"':
+			tok.WriteByte(ch)
+			quoted = true
+		case ch == ';':
+			flush()
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		case ch == '{' && cur == nil:
+			flush()
+			if len(pending) == 0 {
+				return nil, errors.New("entry without a name")
+			}
+			entries = append(entries, entry{name: strings.Join(pending, " ")})
+			cur, pending = &entries[len(entries)-1], nil
+		case ch == '}' && cur != nil:
+			flush()
+			cur = nil
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+			flush()
+		default:
+			tok.WriteByte(ch)
+		}
+	}
+	if cur != nil {
+		return nil, fmt.Errorf("entry %q: no closing }", cur.name)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no entries")
+	}
+	return entries, nil
+}
+
+// pickEntry returns the entry called name, or the first when name is "".
+func pickEntry(entries []entry, name string) (entry, error) {
+	if name == "" {
+		return entries[0], nil
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		if strings.EqualFold(e.name, name) {
+			return e, nil
+		}
+		names[i] = e.name
+	}
+	return entry{}, fmt.Errorf("no entry %q: the file has %s", name, strings.Join(names, ", "))
+}
+
+// splitValues splits a slash-separated list of numbers, as PAR and UPR
+// files write coordinates.
+func splitValues(key, s string) ([]float64, error) {
+	var vals []float64
+	for f := range strings.SplitSeq(s, "/") {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s=%s: %q is not a number", key, s, f)
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+// sameNumber reports whether a and b are the same number, however
+// written, or the same string if either isn't a number.
+func sameNumber(a, b string) bool {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return x == y
+}
+
+// ignoredWarning returns the warning listing the settings of a format
+// that were skipped, or "" if there were none.
+func ignoredWarning(format string, keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	keys = slices.Clone(keys)
+	slices.Sort(keys)
+	return "ignored " + format + " settings: " + strings.Join(slices.Compact(keys), ", ")
+}
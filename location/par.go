@@ -0,0 +1,133 @@
+package location
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parTypes maps Fractint fractal types to fractal.Names.
+var parTypes = map[string]string{
+	"mandel":   "mandelbrot",
+	"mandelfp": "mandelbrot",
+	"julia":    "julia",
+	"juliafp":  "julia",
+}
+
+// ParsePAR reads the entry called name, or the first, from a Fractint
+// parameter file: "Name { key=value ... }" blocks with ';' comments.
+//
+// The view is taken from center-mag=x/y/mag, which Fractint sizes to be
+// 2/mag high, or else from corners=xmin/xmax/ymin/ymax. Fractint's
+// stretch, rotation and skew (the extra center-mag values, or a third
+// corner) are warned about and ignored. type=mandel and julia are
+// mapped with their params, the starting z and Julia parameter; any
+// other type is warned about and leaves the formula unset.
+func ParsePAR(r io.Reader, name string) (Location, error) {
+	entries, err := readEntries(r)
+	if err != nil {
+		return Location{}, err
+	}
+	e, err := pickEntry(entries, name)
+	if err != nil {
+		return Location{}, err
+	}
+	fields := make(map[string]string)
+	var ignored []string
+	for _, t := range e.tokens {
+		key, value, ok := strings.Cut(t, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(key)
+		switch key {
+		case "type", "center-mag", "corners", "params", "maxiter":
+			fields[key] = value
+		default:
+			ignored = append(ignored, key)
+		}
+	}
+
+	var loc Location
+	wrap := func(err error) (Location, error) { return Location{}, fmt.Errorf("entry %q: %w", e.name, err) }
+	switch {
+	case fields["center-mag"] != "":
+		v, err := splitValues("center-mag", fields["center-mag"])
+		if err != nil {
+			return wrap(err)
+		}
+		if len(v) < 3 || !(v[2] > 0) {
+			return wrap(fmt.Errorf("center-mag=%s: want x/y/mag with a positive mag", fields["center-mag"]))
+		}
+		loc.Center, loc.Height = complex(v[0], v[1]), 2/v[2]
+		for i, what := range []string{"x-magnification factor", "rotation", "skew"} {
+			def := 0.0
+			if i == 0 {
+				def = 1
+			}
+			if len(v) > 3+i && v[3+i] != def {
+				loc.Warnings = append(loc.Warnings, fmt.Sprintf("center-mag %s %g: not supported; ignored", what, v[3+i]))
+			}
+		}
+		if fields["corners"] != "" {
+			ignored = append(ignored, "corners")
+		}
+	case fields["corners"] != "":
+		v, err := splitValues("corners", fields["corners"])
+		if err != nil {
+			return wrap(err)
+		}
+		if len(v) != 4 && len(v) != 6 || !(v[0] < v[1] && v[2] < v[3]) {
+			return wrap(fmt.Errorf("corners=%s: want xmin/xmax/ymin/ymax with min below max", fields["corners"]))
+		}
+		loc.Center = complex((v[0]+v[1])/2, (v[2]+v[3])/2)
+		loc.Width, loc.Height = v[1]-v[0], v[3]-v[2]
+		if len(v) == 6 && (v[4] != v[0] || v[5] != v[2]) {
+			loc.Warnings = append(loc.Warnings, "corners: a rotated or skewed view (third corner) is not supported; read as unrotated")
+		}
+	default:
+		return wrap(fmt.Errorf("no center-mag or corners"))
+	}
+	if v, ok := fields["maxiter"]; ok {
+		if loc.Iterations, err = strconv.Atoi(v); err != nil || loc.Iterations < 1 {
+			return wrap(fmt.Errorf("maxiter=%s: must be a positive integer", v))
+		}
+	}
+
+	var params []float64
+	if v := fields["params"]; v != "" {
+		if params, err = splitValues("params", v); err != nil {
+			return wrap(err)
+		}
+	}
+	param := complex(at(params, 0), at(params, 1))
+	if t, ok := fields["type"]; ok {
+		loc.Fractal = parTypes[strings.ToLower(t)]
+		switch loc.Fractal {
+		case "":
+			loc.Warnings = append(loc.Warnings, fmt.Sprintf("type=%s: not supported; the formula is left as it is", t))
+		case "mandelbrot":
+			loc.Z0 = param
+		case "julia":
+			loc.Julia = param
+		}
+		if loc.Fractal != "" && len(params) > 2 {
+			loc.Warnings = append(loc.Warnings, fmt.Sprintf("params=%s: only the first two are used", fields["params"]))
+		}
+	} else if len(params) > 0 {
+		ignored = append(ignored, "params")
+	}
+	if w := ignoredWarning("Fractint", ignored); w != "" {
+		loc.Warnings = append(loc.Warnings, w)
+	}
+	return loc, nil
+}
+
+// at returns v[i], or 0 past the end of v.
+func at(v []float64, i int) float64 {
+	if i < len(v) {
+		return v[i]
+	}
+	return 0
+}
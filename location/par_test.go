@@ -0,0 +1,83 @@
+package location
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadPAR(t *testing.T) {
+	// the first entry without a #Name, with every setting of it used or listed
+	loc, err := Read("testdata/views.par")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Center != complex(-0.75, 0.1) || loc.Height != 0.1 || loc.Width != 0 || loc.Iterations != 1500 ||
+		loc.Fractal != "mandelbrot" || loc.Z0 != 0 {
+		t.Errorf("got %+v", loc)
+	}
+	if want := []string{"ignored Fractint settings: colors, float, inside, reset"}; !slices.Equal(loc.Warnings, want) {
+		t.Errorf("warnings %q, want %q", loc.Warnings, want)
+	}
+
+	// corners give both extents, fitted to the image
+	loc, err = Read("testdata/views.par#dendrite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Center != 0 || loc.Width != 3 || loc.Height != 2 || loc.Iterations != 256 ||
+		loc.Fractal != "julia" || loc.Julia != 1i {
+		t.Errorf("got %+v", loc)
+	}
+	if b := loc.Bounds(300, 300); b.Xmin != -1.5 || b.Xmax != 1.5 || b.Ymin != -1.5 || b.Ymax != 1.5 {
+		t.Errorf("square bounds %+v", b)
+	}
+
+	// an unsupported type, stretch and rotation are warned about
+	loc, err = Read("testdata/views.par#Tilted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Fractal != "" || loc.Height != 2 || loc.Iterations != 100 {
+		t.Errorf("got %+v", loc)
+	}
+	for _, w := range []string{
+		"center-mag x-magnification factor 1.5: not supported; ignored",
+		"center-mag rotation 30: not supported; ignored",
+		"type=lambda: not supported; the formula is left as it is",
+	} {
+		if !slices.Contains(loc.Warnings, w) {
+			t.Errorf("no warning %q in %q", w, loc.Warnings)
+		}
+	}
+	// the params go with the unsupported type, and skew 0 is the default
+	if got := loc.Warnings[len(loc.Warnings)-1]; got != "ignored Fractint settings: reset" {
+		t.Errorf("last warning %q", got)
+	}
+}
+
+func TestReadPARErrors(t *testing.T) {
+	if _, err := Read("testdata/views.par#Missing"); err == nil || !strings.Contains(err.Error(), "Seahorse, Dendrite, Tilted") {
+		t.Errorf("#Missing: %v", err)
+	}
+	for _, src := range []string{
+		"",
+		"{ center-mag=0/0/1 }",
+		"A { center-mag=0/0/1",
+		"A { maxiter=10 }",
+		"A { center-mag=0/0/0 }",
+		"A { center-mag=0/x/1 }",
+		"A { corners=1/-1/-1/1 }",
+		"A { corners=-1/1/-1 }",
+		"A { center-mag=0/0/1 maxiter=0 }",
+	} {
+		if _, err := ParsePAR(strings.NewReader(src), ""); err == nil {
+			t.Errorf("%q: no error", src)
+		}
+	}
+	// a ';' inside quotes is part of the value, not a comment
+	loc, err := ParsePAR(strings.NewReader(`A { comment="a;b" center-mag=0/0/4 }`), "")
+	if err != nil || loc.Height != 0.5 {
+		t.Errorf("quoted ';': %+v, %v", loc, err)
+	}
+}
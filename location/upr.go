@@ -0,0 +1,140 @@
+package location
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// uprFormulas maps the formula entries of Ultra Fractal's Standard.ufm
+// to fractal.Names.
+var uprFormulas = map[string]string{
+	"mandelbrot":  "mandelbrot",
+	"julia":       "julia",
+	"burningship": "burningship",
+}
+
+// ParseUPR reads the parameter set called name, or the first, from an
+// Ultra Fractal .upr file: "Name { ... }" blocks made of sections such
+// as "mapping:" and "formula:", each followed by key=value settings.
+//
+// The view is taken from the mapping section's center=x/y and magn,
+// which Ultra Fractal sizes to be 3/magn high in a landscape image, and
+// the iteration limit from the formula's maxiter. The Mandelbrot, Julia
+// and Burning Ship formulas of Standard.ufm are mapped, with p_start as
+// the starting z and p_seed as the Julia parameter. Only the first layer
+// of a layered parameter set is read. Rotation and stretch are warned
+// about and ignored, and so is any setting of the coloring sections.
+func ParseUPR(r io.Reader, name string) (Location, error) {
+	entries, err := readEntries(r)
+	if err != nil {
+		return Location{}, err
+	}
+	e, err := pickEntry(entries, name)
+	if err != nil {
+		return Location{}, err
+	}
+	// fields are keyed by section.key and keep the first value, which
+	// belongs to the first layer
+	fields := make(map[string]string)
+	var order []string
+	section := ""
+	for _, t := range e.tokens {
+		if s, ok := strings.CutSuffix(t, ":"); ok && !strings.Contains(t, "=") {
+			section = strings.ToLower(s)
+			continue
+		}
+		key, value, ok := strings.Cut(t, "=")
+		if !ok {
+			continue
+		}
+		key = section + "." + strings.ToLower(key)
+		if _, dup := fields[key]; !dup {
+			fields[key] = strings.Trim(value, `"`)
+			order = append(order, key)
+		}
+	}
+	wrap := func(err error) (Location, error) { return Location{}, fmt.Errorf("entry %q: %w", e.name, err) }
+
+	var loc Location
+	if fields["mapping.center"] == "" || fields["mapping.magn"] == "" {
+		return wrap(fmt.Errorf("no mapping center and magn"))
+	}
+	c, err := splitValues("center", fields["mapping.center"])
+	if err != nil {
+		return wrap(err)
+	}
+	if len(c) != 2 {
+		return wrap(fmt.Errorf("center=%s: want x/y", fields["mapping.center"]))
+	}
+	magn, err := strconv.ParseFloat(fields["mapping.magn"], 64)
+	if err != nil || !(magn > 0) {
+		return wrap(fmt.Errorf("magn=%s: must be a positive number", fields["mapping.magn"]))
+	}
+	loc.Center, loc.Height = complex(c[0], c[1]), 3/magn
+	if v, ok := fields["formula.maxiter"]; ok {
+		if loc.Iterations, err = strconv.Atoi(v); err != nil || loc.Iterations < 1 {
+			return wrap(fmt.Errorf("maxiter=%s: must be a positive integer", v))
+		}
+	}
+	if n := fields["fractal.layers"]; n != "" && n != "1" {
+		loc.Warnings = append(loc.Warnings, fmt.Sprintf("layers=%s: only the first layer is read", n))
+	}
+
+	var ignored []string
+	used := map[string]bool{"mapping.center": true, "mapping.magn": true, "formula.maxiter": true, "fractal.layers": true}
+	for _, key := range []string{"mapping.angle", "mapping.stretch", "mapping.skew"} {
+		if v, ok := fields[key]; ok {
+			used[key] = true
+			def := "0"
+			if key == "mapping.stretch" {
+				def = "1"
+			}
+			if !sameNumber(v, def) {
+				loc.Warnings = append(loc.Warnings, fmt.Sprintf("%s=%s: not supported; ignored", strings.TrimPrefix(key, "mapping."), v))
+			}
+		}
+	}
+	if entry := fields["formula.entry"]; entry != "" {
+		used["formula.entry"], used["formula.filename"] = true, true
+		loc.Fractal = uprFormulas[strings.ToLower(strings.ReplaceAll(entry, " ", ""))]
+		if loc.Fractal == "" || !strings.EqualFold(fields["formula.filename"], "Standard.ufm") {
+			loc.Fractal = ""
+			loc.Warnings = append(loc.Warnings, fmt.Sprintf("formula %s:%s: not supported; the formula is left as it is", fields["formula.filename"], entry))
+		}
+	}
+	param := func(key string) (complex128, error) {
+		used[key] = true
+		v, err := splitValues(strings.TrimPrefix(key, "formula."), fields[key])
+		if err != nil || len(v) != 2 {
+			return 0, fmt.Errorf("%s=%s: want x/y", strings.TrimPrefix(key, "formula."), fields[key])
+		}
+		return complex(v[0], v[1]), nil
+	}
+	switch {
+	case loc.Fractal == "mandelbrot" && fields["formula.p_start"] != "":
+		if loc.Z0, err = param("formula.p_start"); err != nil {
+			return wrap(err)
+		}
+	case loc.Fractal == "julia" && fields["formula.p_seed"] != "":
+		if loc.Julia, err = param("formula.p_seed"); err != nil {
+			return wrap(err)
+		}
+	}
+	if p, ok := fields["formula.p_power"]; ok && loc.Fractal != "" {
+		used["formula.p_power"] = true
+		if v, err := splitValues("p_power", p); err != nil || len(v) != 2 || v[0] != 2 || v[1] != 0 {
+			loc.Warnings = append(loc.Warnings, fmt.Sprintf("p_power=%s: only 2/0 is supported; ignored", p))
+		}
+	}
+	for _, key := range order {
+		if !used[key] {
+			ignored = append(ignored, key)
+		}
+	}
+	if w := ignoredWarning("Ultra Fractal", ignored); w != "" {
+		loc.Warnings = append(loc.Warnings, w)
+	}
+	return loc, nil
+}
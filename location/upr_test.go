@@ -0,0 +1,77 @@
+package location
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadUPR(t *testing.T) {
+	loc, err := Read("testdata/views.upr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3/magn high
+	if loc.Center != complex(-0.7435669, 0.1314023) || loc.Height != 0.001 || loc.Iterations != 2000 ||
+		loc.Fractal != "mandelbrot" || loc.Z0 != 0 {
+		t.Errorf("got %+v", loc)
+	}
+	if len(loc.Warnings) != 1 || !strings.HasPrefix(loc.Warnings[0], "ignored Ultra Fractal settings: ") {
+		t.Fatalf("warnings %q", loc.Warnings)
+	}
+	ignored := strings.Split(strings.TrimPrefix(loc.Warnings[0], "ignored Ultra Fractal settings: "), ", ")
+	for _, key := range []string{"formula.p_bailout", "outside.transfer", "fractal.title"} {
+		if !slices.Contains(ignored, key) {
+			t.Errorf("%s not in %q", key, ignored)
+		}
+	}
+	for _, key := range []string{"mapping.center", "mapping.angle", "formula.p_start", "formula.p_power", "fractal.layers"} {
+		if slices.Contains(ignored, key) {
+			t.Errorf("%s in %q", key, ignored)
+		}
+	}
+
+	// the first layer of a layered set, with its rotation and power warned about
+	loc, err = Read("testdata/views.upr#RABBIT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Fractal != "julia" || loc.Julia != complex(-0.123, 0.745) || loc.Iterations != 500 || loc.Height != 2 {
+		t.Errorf("got %+v", loc)
+	}
+	for _, w := range []string{
+		"layers=2: only the first layer is read",
+		"angle=30: not supported; ignored",
+		"p_power=3/0: only 2/0 is supported; ignored",
+	} {
+		if !slices.Contains(loc.Warnings, w) {
+			t.Errorf("no warning %q in %q", w, loc.Warnings)
+		}
+	}
+	if slices.ContainsFunc(loc.Warnings, func(w string) bool { return strings.HasPrefix(w, "stretch") }) {
+		t.Errorf("stretch=1 warned about: %q", loc.Warnings)
+	}
+
+	loc, err = Read("testdata/views.upr#Nova")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Fractal != "" || !slices.Contains(loc.Warnings, "formula Standard.ufm:Nova: not supported; the formula is left as it is") {
+		t.Errorf("got %+v", loc)
+	}
+}
+
+func TestReadUPRErrors(t *testing.T) {
+	for _, src := range []string{
+		"A { formula: maxiter=10 }",
+		"A { mapping: center=0/0 }",
+		"A { mapping: center=0 magn=1 }",
+		"A { mapping: center=0/0 magn=-1 }",
+		"A { mapping: center=0/0 magn=1 formula: maxiter=many }",
+		`A { mapping: center=0/0 magn=1 formula: filename="Standard.ufm" entry="Julia" p_seed=1 }`,
+	} {
+		if _, err := ParseUPR(strings.NewReader(src), ""); err == nil {
+			t.Errorf("%q: no error", src)
+		}
+	}
+}
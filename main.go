@@ -1,83 +1,715 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"image"
-	"image/png"
+	"image/color"
+	"image/draw"
+	"io"
 	"math"
+	"math/rand/v2"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"runtime"
-	"sync"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/whalelogic/mandlebrot/analysis"
+	"github.com/whalelogic/mandlebrot/boundary"
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/fractal"
+	"github.com/whalelogic/mandlebrot/location"
+	"github.com/whalelogic/mandlebrot/output"
+	"github.com/whalelogic/mandlebrot/overlay"
 	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/postprocess"
+	"github.com/whalelogic/mandlebrot/render"
+	"github.com/whalelogic/mandlebrot/scratch"
+	"github.com/whalelogic/mandlebrot/shader"
+	"github.com/whalelogic/mandlebrot/termimg"
 )
 
+// transformNames are the accepted -transform values.
+var transformNames = []string{"none", "inverse", "mobius"}
+
+// terminalModes are the accepted -terminal values: auto, which picks the
+// best protocol the terminal supports, and each termimg protocol.
+var terminalModes = []string{"auto", string(termimg.ANSI), string(termimg.Sixel), string(termimg.Kitty)}
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			serveMain(os.Args[2:])
+			return
+		case "explore":
+			exploreMain(os.Args[2:])
+			return
+		case "pipe":
+			pipeMain(os.Args[2:])
+			return
+		case "animate":
+			animateMain(os.Args[2:])
+			return
+		case "cycle":
+			cycleMain(os.Args[2:])
+			return
+		case "scout":
+			scoutMain(os.Args[2:])
+			return
+		case "palettes":
+			palettesMain(os.Args[2:])
+			return
+		}
+	}
 
-	// 🥋TODO 
+	// 🥋TODO
 	// 🎇 Add cmd cmd for rendering image with feh on Linux
 	// ⏳Add option for smooth coloring vs discrete
 	// ⏳Add option for output format (png, jpg, etc)
 
 	// Command-line flags
-	width := flag.Int("width", 1600, "output image width in pixels")
-	height := flag.Int("height", 1200, "output image height in pixels")
-	xmin := flag.Float64("xmin", -2.2, "left x coordinate")
-	xmax := flag.Float64("xmax", 1.0, "right x coordinate")
-	ymin := flag.Float64("ymin", -1.6, "bottom y coordinate")
-	ymax := flag.Float64("ymax", 1.6, "top y coordinate")
-	iters := flag.Int("iters", 1200, "max iteration count")
-	outfile := flag.String("outfile", "mandelbrot.png", "output PNG filename")
-	pal := flag.String("palette", "NebulaSpectre", "palette name (case-sensitive)")
+	width := flag.Int("width", render.DefaultWidth, "output image width in pixels")
+	height := flag.Int("height", render.DefaultHeight, "output image height in pixels")
+	xmin := flag.Float64("xmin", render.DefaultBounds.Xmin, "left x coordinate")
+	xmax := flag.Float64("xmax", render.DefaultBounds.Xmax, "right x coordinate")
+	ymin := flag.Float64("ymin", render.DefaultBounds.Ymin, "bottom y coordinate")
+	ymax := flag.Float64("ymax", render.DefaultBounds.Ymax, "top y coordinate")
+	rotate := flag.Float64("rotate", 0, "rotate the view by this many degrees counter-clockwise about its center")
+	flipY := flag.Bool("flipy", false, "put -ymin at the top of the image, as renders did before the imaginary axis was fixed")
+	highPrecision := flag.Bool("high-precision", false, "round every pixel's coordinates exactly, for deep zooms (slower)")
+	sparse := flag.Bool("sparse", false, "sample the corners of 8x8 blocks first and fill the blocks inside the set without iterating them (faster for views mostly inside the set)")
+	zoomToPoint := flag.String("zoom-to-point", "", "center the view, from the bounds or -location, on this point a+bi and zoom in 10x")
+	locationPath := flag.String("location", "", "render the view saved in a Kalles Fraktaler .kfr, Fractint .par or Ultra Fractal .upr file (file.par#Entry picks an entry); sets the bounds, -high-precision and, unless given, -iters and the formula")
+	bailout := flag.Float64("bailout", render.DefaultBailout, "escape radius, where the escape count is taken")
+	iters := flag.Int("iters", render.DefaultMaxIter, "max iteration count")
+	outfile := flag.String("outfile", "mandelbrot.png", "output filename; %p, %w, %h, %x, %y, %i and %f become the palette, width, height, center, iterations and fractal")
+	colorProfile := flag.String("colorprofile", string(render.ProfileSRGB), "color profile the PNG is tagged with ("+profileNames()+"); p3 converts the colors to Display P3")
+	uploadURL := flag.String("upload-url", "", "PUT the image to this URL (e.g. a pre-signed S3 URL) instead of keeping -outfile")
+	pal := flag.String("palette", render.DefaultPalette, "palette name (case-sensitive)")
+	autoContrast := flag.Bool("auto-contrast", false, "stretch the escaped pixels' palette positions so they use the whole palette")
+	shaderPath := flag.String("shader", "", "color pixels with the pixel shader in this Go plugin (.so) instead of the palette")
+	palPhase := flag.Float64("palette-phase", 0, "shift escaped colors along the palette by this fraction of a forward-and-back sweep")
 	concurrency := flag.Int("procs", runtime.NumCPU(), "concurrent worker count")
+	chunkRows := flag.Int("chunk-rows", 0, "rows a worker claims at a time (0 = auto: height/procs/4)")
+	throttle := flag.Int("throttle", 100, "percentage of the time, 1-100, the workers spend computing; below 100 each rests after every row, to keep the machine cool")
 	smooth := flag.Bool("smooth", true, "use smooth coloring (continuous escape-time)")
+	coloring := flag.String("coloring", string(render.DefaultColoring), "coloring mode ("+coloringNames()+"); -smooth=false selects discrete")
+	bands := flag.Int("bands", render.DefaultBands, "iterations per palette cycle for band coloring")
+	blendSmooth := flag.Float64("blend-smooth", render.DefaultBlendSmooth, "smooth weight for -coloring blend (0.0 = pure bands, 1.0 = pure smooth)")
+	zmagSmooth := flag.Float64("zmag-smooth", 0.0, "smooth weight for -coloring zmag-cos (0.0 = pure z magnitude, 1.0 = pure smooth)")
+	trapPoints := flag.String("trap-points", "0+0i,1+0i,-1+0i", "comma-separated orbit traps for -coloring fixedtrap, as complex numbers a+bi")
+	trapScale := flag.Float64("trap-scale", render.DefaultTrapScale, "for -coloring fixedtrap, how quickly the palette runs with the distance to the nearest trap")
+	interiorScale := flag.Float64("interior-scale", render.DefaultInteriorScale, "for -coloring interior-distance, how quickly the glow fades away from the boundary")
+	exposure := flag.Float64("exposure", 0, "color correction: scale the colors by 2^N in linear light (N in stops)")
+	brightness := flag.Float64("brightness", 0, "color correction: add this to each channel in linear light (-1 to 1)")
+	contrast := flag.Float64("contrast", 0, "color correction: push channels away from middle grey by a factor 1+N (-1 = flat grey)")
+	saturation := flag.Float64("saturation", 0, "color correction: push colors away from their luminance by a factor 1+N (-1 = greyscale)")
+	bloom := flag.Float64("bloom", 0, "strength of the glow added around bright colors (0 = no bloom)")
+	bloomRadius := flag.Float64("bloomradius", render.DefaultBloomRadius, "with -bloom, reach of the glow in pixels")
+	bloomThreshold := flag.Float64("bloomthreshold", 0.8, "with -bloom, linear luminance (0-1) a color must pass to glow")
+	outputHSL := flag.Bool("output-hsl", false, "write HSL in the R, G, B channels (hue 0-360, saturation and lightness 0-100, each scaled to 0-255)")
+	stats := flag.Bool("stats", false, "print render statistics after rendering")
+	progress := flag.Bool("progress", false, "print render progress to stderr")
+	measure := flag.Bool("measure", false, "estimate the area of the set within the view after rendering")
+	measureRefine := flag.Int("measure-refine", 4, "resample each boundary pixel on an NxN grid for -measure (0 = no refinement)")
+	histogram := flag.String("histogram", "", "also write a log-scale chart of the escape-count distribution to this image file")
+	backgroundImage := flag.String("background-image", "", "composite the image over this PNG or JPEG, resized to the image size, where the palette is translucent")
+	scratchDir := flag.String("scratch", "", "render tile by tile into a scratch file in this directory and encode from it, for images too large for memory; the file is removed on success and kept on failure")
+	estimateTime := flag.Bool("estimate-time", false, "render a 1% sample first and print how long the full render should take; past -warn-threshold, ask before going on")
+	warnThreshold := flag.Duration("warn-threshold", time.Minute, "with -estimate-time, the estimate above which to ask for confirmation")
+	multiResolution := flag.Bool("multiresolution", false, "write the image at full, half, quarter and eighth size, down to 32 pixels, each named after -outfile with _WIDTHxHEIGHT before the extension")
+	mirrorX := flag.Bool("mirror-x", false, "render only the bottom half of the image and mirror it over the top half")
+	mirrorY := flag.Bool("mirror-y", false, "render only the right half of the image and mirror it over the left half; with -mirror-x, only the bottom right quarter")
+	maxMemory := flag.Int("max-memory", defaultMaxMemoryMB, "refuse renders estimated to need more than this many MB of memory, at 13 bytes a pixel, unless -scratch is set (0 = no limit)")
+	paletted16 := flag.Bool("paletted16", false, "keep a 16-bit palette index per pixel instead of a color and write a 256-color paletted PNG, median cut from the colors in use")
+	timingMap := flag.String("timingmap", "", "also write the compute time of each work unit to this file: a heatmap for an image file, the raw numbers for .csv")
+	overlayFractal := flag.String("overlay-fractal", "", "also render this fractal over the same window, written type or type:weight (e.g. julia:0.7), and multiply it into the image; weight 0-1 fades the effect in")
+	overlayPalette := flag.String("overlay-palette", "AuroraArc", "palette of -overlay-fractal")
+	postprocessSpec := flag.String("postprocess", "", "filter the finished image through these comma-separated steps, in order ("+strings.Join(postprocess.StepNames, ", ")+"), e.g. blur:1.5,sharpen; blur takes its standard deviation in pixels")
+	checkerboard := flag.Bool("checkerboard", false, "composite the image over a grey and white checkerboard, to show a translucent palette in any viewer")
+	grid := flag.Bool("grid", false, "draw labelled coordinate ticks over the image")
+	gridLines := flag.Bool("grid-lines", false, "with -grid, draw gridlines across the image at every tick")
+	gridColor := flag.String("grid-color", "#ffffff", "with -grid, color of the ticks, lines and labels as #RRGGBB")
+	gridOpacity := flag.Float64("grid-opacity", 0.7, "with -grid, opacity of the ticks, lines and labels (0-1)")
+	gridReadout := flag.Bool("grid-readout", true, "with -grid, print the view bounds in the top right corner")
+	var orbits orbitFlag
+	flag.Var(&orbits, "orbit", "draw the orbit of the point cre,cim or re+imi over the image; repeat for more orbits")
+	flag.Var(&orbits, "draw-orbit", "same as -orbit")
+	orbitLength := flag.Int("orbit-length", 100, "iterations of each -orbit to draw")
+	annotate := flag.String("annotate", "", `draw this text on the image; \n starts a new line`)
+	annotateCorner := flag.String("annotate-corner", string(overlay.BottomLeft), "corner for -annotate ("+cornerNames()+")")
+	stamp := flag.Bool("stamp", false, "draw the view's center, zoom, iterations and palette on the image")
+	stampCorner := flag.String("stamp-corner", string(overlay.BottomRight), "corner for -stamp ("+cornerNames()+")")
+	textColor := flag.String("text-color", "#ffffff", "color of -annotate and -stamp text as #RRGGBB")
+	textBox := flag.String("text-box-color", "#000000", "color of the box behind -annotate and -stamp text as #RRGGBB")
+	textBoxOpacity := flag.Float64("text-box-opacity", 0.6, "opacity of the box behind -annotate and -stamp text (0-1, 0 = no box)")
+	textScale := flag.Int("text-scale", 0, "size of a font pixel of -annotate and -stamp text in image pixels (0 = from the image height)")
+	boundaryOut := flag.String("boundary-out", "", "trace the boundary of the set and write it to this .geojson or .csv file")
+	boundaryThreshold := flag.Float64("boundary-threshold", 0, "with -boundary-out, trace where the escape count crosses this value instead (0 = the interior)")
+	boundaryTolerance := flag.Float64("boundary-tolerance", 0.25, "with -boundary-out, simplify the traced lines to within this many pixels (0 = keep every vertex)")
+	verify := flag.Bool("verify", false, "render twice and fail if the two renders differ in any pixel")
 	feh := flag.Bool("feh", true, "open image with feh after rendering (Linux only)")
+	frac := flag.String("fractal", "mandelbrot", "fractal formula ("+strings.Join(fractal.Names, ", ")+")")
+	formulaExpr := flag.String("formula", "", "iterate this formula in z and c from z = 0 instead of -fractal, e.g. \"z*z*z + c*z + c\" (operators + - * / ^)")
+	juliaRe := flag.Float64("julia-re", -0.8, "real part of the Julia parameter")
+	juliaIm := flag.Float64("julia-im", 0.156, "imaginary part of the Julia parameter")
+	z0Re := flag.Float64("z0-re", 0, "real part of the starting z for -fractal mandelbrot")
+	z0Im := flag.Float64("z0-im", 0, "imaginary part of the starting z for -fractal mandelbrot")
+	transform := flag.String("transform", "none", "conformal map from the view to the points iterated ("+strings.Join(transformNames, ", ")+")")
+	mobius := flag.String("mobius", "1,0,0,1", "with -transform mobius, the coefficients a,b,c,d of (az+b)/(cz+d), comma-separated complex numbers a+bi")
+	power := flag.Int("power", fractal.DefaultPower, "degree of -fractal multibrot, z = z^power + c")
+	superPower := flag.Float64("super-power", fractal.DefaultSuperPower, "degree of -fractal super, z = z^power + c for any real power above 1")
+	qsliceZ := flag.Float64("qslice-z", 0, "j component of c for -fractal quaternion, fixed across the image")
+	qsliceW := flag.Float64("qslice-w", 0, "k component of c for -fractal quaternion, fixed across the image")
+	pipe := flag.Bool("pipe", false, "read views from stdin, one \"xmin xmax ymin ymax [palette]\" per line, and render each to -outfile (%n = line number)")
+	watch := flag.String("watch", "", "render the JSON options in this file to -outfile, and again each time the file changes, until interrupted; the file sets every render option")
+	terminal := flag.String("terminal", "", "print a preview sized to the terminal instead of writing a file ("+strings.Join(terminalModes, ", ")+")")
 	flag.Parse()
 
 	runtime.GOMAXPROCS(*concurrency)
 
-	cmap := palette.Get(*pal)
-	if cmap == nil {
-		fmt.Fprintf(os.Stderr, "palette %q not found. Available palettes:\n", *pal)
-		for _, p := range palette.ColorPalettes {
-			fmt.Fprintf(os.Stderr, "  - %s\n", p.Keyword)
+	if *watch != "" {
+		base, err := render.New(render.WithProcs(*concurrency))
+		if err != nil {
+			fail("invalid options:\n", err)
+		}
+		r, err := render.NewRenderer(base)
+		if err != nil {
+			fail("", err)
+		}
+		defer r.Close()
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		fmt.Printf("Watching %s; Ctrl-C stops\n", *watch)
+		if err := watchAndRender(ctx, *watch, *outfile, r); err != nil {
+			fail("", err)
+		}
+		return
+	}
+
+	mode := render.Coloring(*coloring)
+	if !*smooth && mode == render.ColoringSmooth {
+		mode = render.ColoringDiscrete
+	}
+
+	var onProgress func(done, total int)
+	if *progress {
+		onProgress = printProgress
+	}
+
+	if *throttle < 1 || *throttle > 100 {
+		fail("", fmt.Errorf("%w: -throttle %d: must be from 1 to 100", render.ErrInvalidOptions, *throttle))
+	}
+	if *measureRefine < 0 {
+		fail("", fmt.Errorf("%w: -measure-refine %d: must not be negative", render.ErrInvalidOptions, *measureRefine))
+	}
+
+	formula := render.WithFractalName(*frac, complex(*juliaRe, *juliaIm))
+	if isSet(flag.CommandLine, "power") {
+		if *frac != "multibrot" {
+			fail("", fmt.Errorf("%w: -power: only -fractal multibrot has a degree", render.ErrInvalidOptions))
+		}
+		formula = render.WithFractal(fractal.Multibrot{Power: *power})
+	}
+	if z0 := complex(*z0Re, *z0Im); z0 != 0 {
+		if *frac != "mandelbrot" {
+			fail("", fmt.Errorf("%w: -z0-re, -z0-im: only -fractal mandelbrot has a starting z", render.ErrInvalidOptions))
+		}
+		formula = render.WithFractal(fractal.Mandelbrot{Z0: z0})
+	}
+	if isSet(flag.CommandLine, "super-power") {
+		if *frac != "super" {
+			fail("", fmt.Errorf("%w: -super-power: only -fractal super has a real degree", render.ErrInvalidOptions))
+		}
+		formula = render.WithFractal(fractal.Super{Power: *superPower})
+	}
+	if isSet(flag.CommandLine, "qslice-z") || isSet(flag.CommandLine, "qslice-w") {
+		if *frac != "quaternion" {
+			fail("", fmt.Errorf("%w: -qslice-z, -qslice-w: only -fractal quaternion has a slice", render.ErrInvalidOptions))
+		}
+		formula = render.WithFractal(fractal.Quaternion{SliceZ: *qsliceZ, SliceW: *qsliceW})
+	}
+	if *formulaExpr != "" {
+		for _, name := range []string{"fractal", "power", "super-power", "z0-re", "z0-im", "qslice-z", "qslice-w"} {
+			if isSet(flag.CommandLine, name) {
+				fail("", fmt.Errorf("%w: -%s can't be combined with -formula", render.ErrInvalidOptions, name))
+			}
+		}
+		formula = render.WithFormula(*formulaExpr)
+	}
+
+	// the traps are only passed on for fixedtrap coloring or when asked
+	// for, so the default list doesn't trip the check that they apply
+	var traps []complex128
+	if mode == render.ColoringFixedTrap || isSet(flag.CommandLine, "trap-points") {
+		var err error
+		if traps, err = parseComplexList(*trapPoints); err != nil {
+			fail("-trap-points: ", err)
 		}
-		os.Exit(2)
 	}
-	palette.Normalize(cmap)
 
-	img := image.NewRGBA(image.Rect(0, 0, *width, *height))
+	var gridStyle overlay.Grid
+	if *grid {
+		if *rotate != 0 {
+			fail("", fmt.Errorf("%w: -grid can't be drawn over a rotated view", render.ErrInvalidOptions))
+		}
+		c, err := overlayColor("-grid", *gridColor, *gridOpacity)
+		if err != nil {
+			fail("", err)
+		}
+		gridStyle = overlay.Grid{Color: c, Lines: *gridLines, Readout: *gridReadout}
+	}
 
-	rows := make(chan int, *height)
-	var wg sync.WaitGroup
-	for w := 0; w < *concurrency; w++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for y := range rows {
-				computeRow(img, y, *width, *height, *xmin, *xmax, *ymin, *ymax, *iters, cmap, *smooth)
+	if *boundaryOut != "" {
+		if _, err := boundaryFormat(*boundaryOut); err != nil {
+			fail("", err)
+		}
+		if !(*boundaryTolerance >= 0) || math.IsInf(*boundaryTolerance, 0) {
+			fail("", fmt.Errorf("%w: -boundary-tolerance %g: must be finite and not negative", render.ErrInvalidOptions, *boundaryTolerance))
+		}
+	}
+	if *histogram != "" {
+		if _, err := render.FormatFromPath(*histogram); err != nil {
+			fail("-histogram: ", err)
+		}
+	}
+	if *timingMap != "" && !strings.EqualFold(filepath.Ext(*timingMap), ".csv") {
+		if _, err := render.FormatFromPath(*timingMap); err != nil {
+			fail("-timingmap: ", err)
+		}
+	}
+	if *checkerboard && *backgroundImage != "" {
+		fail("", fmt.Errorf("%w: -checkerboard and -background-image both set the background", render.ErrInvalidOptions))
+	}
+	var background image.Image
+	if *backgroundImage != "" {
+		var err error
+		if background, err = loadBackground(*backgroundImage); err != nil {
+			fail("-background-image: ", err)
+		}
+	}
+	if *scratchDir != "" {
+		for _, name := range scratchConflicts {
+			if isSet(flag.CommandLine, name) {
+				fail("", fmt.Errorf("%w: -%s needs the whole image in memory and can't be combined with -scratch", render.ErrInvalidOptions, name))
+			}
+		}
+	}
+	if *estimateTime && *pipe {
+		fail("", fmt.Errorf("%w: -estimate-time reads its confirmation from stdin and can't be combined with -pipe", render.ErrInvalidOptions))
+	}
+	if *multiResolution {
+		for _, name := range multiResolutionConflicts {
+			if isSet(flag.CommandLine, name) {
+				fail("", fmt.Errorf("%w: -%s works on a single image and can't be combined with -multiresolution", render.ErrInvalidOptions, name))
 			}
-		}()
+		}
+	}
+	if *paletted16 {
+		for _, name := range paletted16Conflicts {
+			if isSet(flag.CommandLine, name) {
+				fail("", fmt.Errorf("%w: -%s works on a full-color image and can't be combined with -paletted16", render.ErrInvalidOptions, name))
+			}
+		}
+	}
+	if *mirrorX || *mirrorY {
+		for _, name := range mirrorConflicts {
+			if isSet(flag.CommandLine, name) {
+				fail("", fmt.Errorf("%w: -%s needs the whole render and can't be combined with -mirror-x or -mirror-y", render.ErrInvalidOptions, name))
+			}
+		}
+	}
+	var pixelShader shader.PixelShader
+	if *shaderPath != "" {
+		var err error
+		if pixelShader, err = shader.Load(*shaderPath); err != nil {
+			fail("", err)
+		}
+	}
+	var xform render.Mobius
+	switch *transform {
+	case "none":
+	case "inverse":
+		xform = render.Inversion
+	case "mobius":
+		cs, err := parseComplexList(*mobius)
+		if err != nil {
+			fail("-mobius: ", err)
+		}
+		if len(cs) != 4 {
+			fail("", fmt.Errorf("%w: -mobius: want the 4 coefficients a,b,c,d, got %d", render.ErrInvalidOptions, len(cs)))
+		}
+		xform = render.Mobius{A: cs[0], B: cs[1], C: cs[2], D: cs[3]}
+	default:
+		fail("", fmt.Errorf("%w: -transform %q: not one of %s", render.ErrInvalidOptions, *transform, strings.Join(transformNames, ", ")))
+	}
+	if isSet(flag.CommandLine, "mobius") && *transform != "mobius" {
+		fail("", fmt.Errorf("%w: -mobius only applies to -transform mobius", render.ErrInvalidOptions))
+	}
+	if !xform.IsZero() && (*measure || *boundaryOut != "" || len(orbits) > 0) {
+		fail("", fmt.Errorf("%w: -measure, -boundary-out and -orbit work in the plane of c and can't be combined with -transform", render.ErrInvalidOptions))
+	}
+	if *orbitLength < 1 {
+		fail("", fmt.Errorf("%w: -orbit-length %d: must be positive", render.ErrInvalidOptions, *orbitLength))
 	}
 
-	for y := 0; y < *height; y++ {
-		rows <- y
+	var labels []overlay.Label
+	if *annotate != "" || *stamp {
+		fg, err := overlayColor("-text", *textColor, 1)
+		if err != nil {
+			fail("", err)
+		}
+		bg, err := overlayColor("-text-box", *textBox, *textBoxOpacity)
+		if err != nil {
+			fail("", err)
+		}
+		if *textScale < 0 {
+			fail("", fmt.Errorf("%w: -text-scale %d: must not be negative", render.ErrInvalidOptions, *textScale))
+		}
+		for _, c := range []struct{ flag, corner string }{{"-annotate-corner", *annotateCorner}, {"-stamp-corner", *stampCorner}} {
+			if !slices.Contains(overlay.Corners, overlay.Corner(c.corner)) {
+				fail("", fmt.Errorf("%w: %s %q: not one of %s", render.ErrInvalidOptions, c.flag, c.corner, cornerNames()))
+			}
+		}
+		label := overlay.Label{Color: fg, Background: bg, Scale: *textScale}
+		if *annotate != "" {
+			label.Lines, label.Corner = strings.Split(*annotate, `\n`), overlay.Corner(*annotateCorner)
+			labels = append(labels, label)
+		}
+		if *stamp {
+			// the lines are filled in from the final options once they're known
+			label.Lines, label.Corner = nil, overlay.Corner(*stampCorner)
+			labels = append(labels, label)
+		}
 	}
-	close(rows)
-	wg.Wait()
 
-	// Save file
-	f, err := os.Create(*outfile)
+	bounds := coords.Bounds{Xmin: *xmin, Xmax: *xmax, Ymin: *ymin, Ymax: *ymax}
+	if *locationPath != "" {
+		for _, name := range []string{"xmin", "xmax", "ymin", "ymax"} {
+			if isSet(flag.CommandLine, name) {
+				fail("", fmt.Errorf("%w: -%s: -location sets the view", render.ErrInvalidOptions, name))
+			}
+		}
+		loc, err := location.Read(*locationPath)
+		if err != nil {
+			fail("-location: ", err)
+		}
+		for _, w := range loc.Warnings {
+			fmt.Fprintf(os.Stderr, "-location: %s\n", w)
+		}
+		bounds = loc.Bounds(*width, *height)
+		if !(bounds.Xmin < bounds.Xmax && bounds.Ymin < bounds.Ymax) {
+			fail("-location: ", fmt.Errorf("%w: a view %g high at %v", render.ErrPrecisionExceeded, loc.Height, loc.Center))
+		}
+		if loc.Iterations > 0 && !isSet(flag.CommandLine, "iters") {
+			*iters = loc.Iterations
+		}
+		if loc.Fractal != "" && !isSet(flag.CommandLine, "fractal") && *formulaExpr == "" {
+			formula = render.WithFractalName(loc.Fractal, loc.Julia)
+			if loc.Fractal == "mandelbrot" && loc.Z0 != 0 {
+				formula = render.WithFractal(fractal.Mandelbrot{Z0: loc.Z0})
+			}
+		}
+		*highPrecision = true
+	}
+	if *zoomToPoint != "" {
+		zoomed, err := zoomedToPoint(bounds, *zoomToPoint)
+		if err != nil {
+			fail("", err)
+		}
+		bounds = zoomed
+	}
+	protocol := termimg.Protocol(*terminal)
+	if *terminal != "" {
+		if !slices.Contains(terminalModes, *terminal) {
+			fail("", fmt.Errorf("%w: -terminal %q: not one of %s", render.ErrInvalidOptions, *terminal, strings.Join(terminalModes, ", ")))
+		}
+		if *terminal == "auto" {
+			protocol = termimg.Detect()
+		}
+		win, err := termimg.WindowSize(os.Stdout)
+		if err != nil {
+			win = termimg.Window{Cols: 80, Rows: 24}
+		}
+		*width, *height = win.ImageSize(protocol)
+		bounds = bounds.FitToImage(*width, *height)
+	}
+
+	opts, err := render.New(
+		render.WithSize(*width, *height),
+		render.WithViewport(bounds),
+		render.WithRotation(*rotate),
+		render.WithFlipY(*flipY),
+		render.WithHighPrecisionCoords(*highPrecision),
+		render.WithSparse(*sparse),
+		render.WithIterations(*iters),
+		render.WithBailout(*bailout),
+		render.WithPaletteName(*pal),
+		formula,
+		render.WithTransform(xform),
+		render.WithColoring(mode),
+		render.WithBands(*bands),
+		render.WithBlendSmooth(*blendSmooth),
+		render.WithZmagSmooth(*zmagSmooth),
+		render.WithTrapPoints(traps),
+		render.WithTrapScale(*trapScale),
+		render.WithInteriorScale(*interiorScale),
+		render.WithPalettePhase(*palPhase),
+		render.WithAutoContrast(*autoContrast),
+		render.WithShader(pixelShader),
+		render.WithTimings(*timingMap != ""),
+		render.WithAdjust(render.Adjust{Exposure: *exposure, Brightness: *brightness, Contrast: *contrast, Saturation: *saturation}),
+		render.WithBloom(render.Bloom{Strength: *bloom, Radius: *bloomRadius, Threshold: *bloomThreshold}),
+		render.WithOutputHSL(*outputHSL),
+		render.WithProcs(*concurrency),
+		render.WithRowsPerChunk(*chunkRows),
+		render.WithThrottle(*throttle),
+		render.WithProgress(onProgress, 0),
+	)
+	if err != nil {
+		fail("invalid options:\n", err)
+	}
+	if *scratchDir == "" {
+		if err := checkMemoryLimit(opts.Width, opts.Height, *maxMemory); err != nil {
+			fail("", err)
+		}
+	}
+	var post postprocess.Pipeline
+	if *postprocessSpec != "" {
+		if *pipe {
+			fail("", fmt.Errorf("%w: -postprocess can't be combined with -pipe", render.ErrInvalidOptions))
+		}
+		if post, err = postprocess.Parse(*postprocessSpec); err != nil {
+			fail("", fmt.Errorf("%w: %w", render.ErrInvalidOptions, err))
+		}
+	}
+	var overlayOpts render.Options
+	var overlayWeight float64
+	if *overlayFractal != "" {
+		if *pipe {
+			fail("", fmt.Errorf("%w: -overlay-fractal can't be combined with -pipe", render.ErrInvalidOptions))
+		}
+		var name string
+		if name, overlayWeight, err = parseOverlayFractal(*overlayFractal); err != nil {
+			fail("", err)
+		}
+		overlayOpts = opts
+		overlayOpts.Fractal = fractal.ByName(name, complex(*juliaRe, *juliaIm))
+		overlayOpts.OnProgress, overlayOpts.RecordTimings = nil, false
+		if overlayOpts.Palette = palette.Get(*overlayPalette); overlayOpts.Palette == nil {
+			fail("", fmt.Errorf("%w: -overlay-palette %q: not one of %s", render.ErrInvalidOptions, *overlayPalette, strings.Join(palette.List(), ", ")))
+		}
+		if err := overlayOpts.Validate(); err != nil {
+			fail("-overlay-fractal: ", err)
+		}
+	}
+	if *pipe {
+		if _, err := render.FormatFromPath(output.ExpandNumberedTemplate(*outfile, opts, 1)); err != nil {
+			fail("", err)
+		}
+		r, err := render.NewRenderer(opts)
+		if err != nil {
+			fail("", err)
+		}
+		defer r.Close()
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := runPipeline(ctx, os.Stdin, opts, *outfile, r, os.Stdout); err != nil {
+			fail("", err)
+		}
+		return
+	}
+	*outfile = output.ExpandFilenameTemplate(*outfile, opts)
+	format, err := render.FormatFromPath(*outfile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to create file: %v\n", err)
-		os.Exit(1)
+		fail("", err)
 	}
-	defer f.Close()
-	if err := png.Encode(f, img); err != nil {
-		fmt.Fprintf(os.Stderr, "failed to encode png: %v\n", err)
-		os.Exit(1)
+	profile := render.ColorProfile(*colorProfile)
+	switch {
+	case !slices.Contains(render.ColorProfiles, profile):
+		fail("", fmt.Errorf("%w: -colorprofile %q: not one of %s", render.ErrInvalidOptions, profile, profileNames()))
+	case profile != render.ProfileSRGB && format != "png":
+		fail("", fmt.Errorf("%w: -colorprofile %s needs png output, not %s", render.ErrUnsupportedFormat, profile, format))
+	case *paletted16 && format != "png":
+		fail("", fmt.Errorf("%w: -paletted16 needs png output, not %s", render.ErrUnsupportedFormat, format))
+	case *paletted16 && profile == render.ProfileP3:
+		fail("", fmt.Errorf("%w: -colorprofile p3 converts the colors and can't be combined with -paletted16", render.ErrInvalidOptions))
+	}
+
+	// Ctrl-C / SIGTERM cancels the render and any in-flight encode.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *estimateTime {
+		est, err := render.EstimateRenderTime(ctx, opts, render.DefaultEstimateFraction)
+		if err != nil {
+			fail("", err)
+		}
+		fmt.Fprintf(os.Stderr, "Estimated render time: %v\n", est.Round(time.Millisecond))
+		if est > *warnThreshold && !confirm(os.Stdin, os.Stderr, fmt.Sprintf("That is over -warn-threshold %v. Render anyway? [y/N] ", *warnThreshold)) {
+			fail("", fmt.Errorf("%w: declined after the time estimate", render.ErrCancelled))
+		}
+	}
+
+	if *scratchDir != "" {
+		if profile == render.ProfileP3 {
+			fail("", fmt.Errorf("%w: -colorprofile p3 converts the whole image in memory and can't be combined with -scratch", render.ErrInvalidOptions))
+		}
+		if err := renderToScratch(ctx, opts, *scratchDir, *outfile, format, profile, onProgress); err != nil {
+			fail("", err)
+		}
+		fmt.Printf("Saved %s (%dx%d) using palette %s\n", *outfile, *width, *height, *pal)
+		return
+	}
+
+	if *multiResolution {
+		levels, err := render.MultiResolutionRender(ctx, opts, multiResolutionLevels)
+		if err != nil {
+			fail("", err)
+		}
+		for _, l := range levels {
+			lopts := opts
+			lopts.Width, lopts.Height = l.Width, l.Height
+			path := levelPath(*outfile, l.Width, l.Height)
+			if err := output.WriteFile(path, func(w io.Writer) error {
+				return render.EncodeWithProfile(ctxWriter{ctx, w}, l.Image, format, render.Metadata(lopts), profile)
+			}); err != nil {
+				fail("rendered, but not saved: ", err)
+			}
+			fmt.Printf("Saved %s (%dx%d) using palette %s\n", path, l.Width, l.Height, *pal)
+		}
+		return
+	}
+
+	if *paletted16 {
+		pimg, err := render.Render16bit(ctx, opts)
+		if err != nil {
+			fail("", err)
+		}
+		if err := output.WriteFile(*outfile, func(w io.Writer) error {
+			return render.EncodeWithProfile(ctxWriter{ctx, w}, pimg.Paletted(256), format, render.Metadata(opts), profile)
+		}); err != nil {
+			fail("rendered, but not saved: ", err)
+		}
+		fmt.Printf("Saved %s (%dx%d) using palette %s\n", *outfile, *width, *height, *pal)
+		return
+	}
+
+	var res *render.Result
+	if *mirrorX || *mirrorY {
+		img, err := renderMirrored(ctx, opts, *mirrorX, *mirrorY)
+		if err != nil {
+			fail("", err)
+		}
+		res = &render.Result{Image: img, Options: opts}
+	} else if res, err = render.Render(ctx, opts); err != nil {
+		fail("", err)
+	}
+	img := res.Image
+	if *stats {
+		printStats(res.Stats)
+	}
+	if *measure {
+		area, err := analysis.EstimateArea(ctx, res, *measureRefine)
+		if err != nil {
+			fail("", err)
+		}
+		printArea(area)
+	}
+	if *histogram != "" {
+		if err := writeHistogram(ctx, *histogram, res); err != nil {
+			fail("", err)
+		}
+	}
+	if *timingMap != "" {
+		if err := writeTimingMap(ctx, *timingMap, res); err != nil {
+			fail("", err)
+		}
+	}
+	if *boundaryOut != "" {
+		if err := writeBoundary(*boundaryOut, res, *boundaryThreshold, *boundaryTolerance); err != nil {
+			fail("", err)
+		}
+	}
+	if *verify {
+		again, err := render.Render(ctx, opts)
+		if err != nil {
+			fail("", err)
+		}
+		if n := compareImages(img, again.Image); n > 0 {
+			fail("", fmt.Errorf("verify: %d of %d pixels differ between two renders", n, *width**height))
+		}
+		fmt.Println("Verified: two renders are identical")
+	}
+	img = post.Apply(img)
+	if *overlayFractal != "" {
+		over, err := render.Render(ctx, overlayOpts)
+		if err != nil {
+			fail("-overlay-fractal: ", err)
+		}
+		blended := multiplicativeBlend(img, over.Image)
+		if overlayWeight < 1 {
+			mixImages(blended, img, overlayWeight)
+		}
+		img = blended
+	}
+	if *checkerboard {
+		img = compositeSourceOver(img, checkerboardBackground(img.Rect.Dx(), img.Rect.Dy(), 8))
+	}
+	if background != nil {
+		img = compositeSourceOver(img, resizeImage(background, img.Rect.Dx(), img.Rect.Dy()))
+	}
+	if *grid {
+		if err := overlay.DrawGrid(img, opts.Viewport(), gridStyle); err != nil {
+			fail("", err)
+		}
+	}
+	for i, c := range orbits {
+		zs := fractal.Orbit(opts.Fractal, c, *orbitLength, opts.Bailout*opts.Bailout)
+		overlay.DrawOrbit(img, opts.Viewport(), zs, orbitColors[i%len(orbitColors)])
+	}
+	for _, l := range labels {
+		if l.Lines == nil {
+			l.Lines = stampLines(opts)
+		}
+		overlay.DrawLabel(img, l)
+	}
+	if *terminal != "" {
+		if err := termimg.Write(os.Stdout, img, protocol); err != nil {
+			fail("", err)
+		}
+		return
+	}
+
+	// Save file; an upload goes through a temporary directory whose file
+	// is kept if the upload fails, for debugging
+	path := *outfile
+	if *uploadURL != "" {
+		dir, err := os.MkdirTemp("", "mandelbrot-*")
+		if err != nil {
+			fail("", &output.WriteError{Stage: "create", Path: path, Err: err})
+		}
+		path = filepath.Join(dir, filepath.Base(*outfile))
+	}
+	if err := saveImage(ctx, path, res, img, format, profile); err != nil {
+		fail("rendered, but not saved: ", err)
+	}
+	if *uploadURL != "" {
+		if err := uploadTemp(ctx, *uploadURL, path, contentTypes[format]); err != nil {
+			fail("upload failed, image kept at "+path+": ", err)
+		}
+		fmt.Printf("Uploaded %dx%d image using palette %s\n", *width, *height, *pal)
+		return
 	}
 	fmt.Printf("Saved %s (%dx%d) using palette %s\n", *outfile, *width, *height, *pal)
 	fmt.Println("Opening image with feh...")
@@ -92,58 +724,540 @@ func main() {
 	}
 }
 
-// computeRow computes a single row y and writes pixels into img.
-func computeRow(img *image.RGBA, y, width, height int, xmin, xmax, ymin, ymax float64, iters int, cmap *palette.ColorMap, smooth bool) {
-	for x := range width {
-		// map pixel to complex plane
-		cre := xmin + (float64(x)/float64(width))*(xmax-xmin)
-		cim := ymin + (float64(y)/float64(height))*(ymax-ymin)
-		c := complex(cre, cim)
-
-		iter, z := mandelbrotIterations(c, iters)
-		var t float64
-		if iter >= iters {
-			// inside set -> black (or the palette start)
-			t = 0.0
-		} else {
-			if smooth {
-				// continuous (smooth) iteration count:
-				// nu = n + 1 - log(log|z|)/log(2)
-				// normalize by iters to map to palette
-				mag := cmplxAbs(z)
-				if mag <= 0 {
-					mag = 1e-16
-				}
-				nu := float64(iter) + 1 - math.Log(math.Log(mag))/math.Log(2)
-				// nu might be <0 if weird; clamp
-				if nu < 0 {
-					nu = float64(iter)
-				}
-				t = nu / float64(iters)
-			} else {
-				t = float64(iter) / float64(iters)
+// saveImage encodes img, the finished image of res, to path with
+// output.WriteFile. If that fails for any reason but cancellation, the
+// escape counts of res are salvaged to an .mbuf file next to path, and
+// the error says where they went or why they couldn't be kept either.
+func saveImage(ctx context.Context, path string, res *render.Result, img image.Image, format string, profile render.ColorProfile) error {
+	err := output.WriteFile(path, func(w io.Writer) error {
+		return render.EncodeWithProfile(ctxWriter{ctx, w}, img, format, render.Metadata(res.Options), profile)
+	})
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	mpath, serr := output.Salvage(path, res)
+	if serr != nil {
+		return fmt.Errorf("%w; salvaging the escape counts failed too: %v", err, serr)
+	}
+	return fmt.Errorf("%w; the escape counts were saved to %s", err, mpath)
+}
+
+// parseOverlayFractal parses an -overlay-fractal value, a fractal name
+// optionally followed by ":weight" with the weight in [0,1], 1 if left
+// out.
+func parseOverlayFractal(s string) (string, float64, error) {
+	name, w, hasWeight := strings.Cut(s, ":")
+	if !slices.Contains(fractal.Names, name) {
+		return "", 0, fmt.Errorf("%w: -overlay-fractal %q: not one of %s", render.ErrInvalidOptions, name, strings.Join(fractal.Names, ", "))
+	}
+	weight := 1.0
+	if hasWeight {
+		var err error
+		weight, err = strconv.ParseFloat(w, 64)
+		if err != nil || !(weight >= 0 && weight <= 1) {
+			return "", 0, fmt.Errorf("%w: -overlay-fractal weight %q: want a number in [0,1]", render.ErrInvalidOptions, w)
+		}
+	}
+	return name, weight, nil
+}
+
+// multiplicativeBlend returns a new image whose channels are a's times
+// b's over 255, alpha included, aligning their top left corners: white
+// in either leaves the other as it is and black gives black. It covers
+// the overlap of the two.
+func multiplicativeBlend(a, b *image.RGBA) *image.RGBA {
+	w, h := min(a.Rect.Dx(), b.Rect.Dx()), min(a.Rect.Dy(), b.Rect.Dy())
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		pa := a.Pix[y*a.Stride : y*a.Stride+4*w]
+		pb := b.Pix[y*b.Stride : y*b.Stride+4*w]
+		po := out.Pix[y*out.Stride : y*out.Stride+4*w]
+		for i := range po {
+			po[i] = uint8(uint16(pa[i]) * uint16(pb[i]) / 255)
+		}
+	}
+	return out
+}
+
+// mixImages moves dst a fraction 1-weight of the way back to src,
+// pixel by pixel, so weight 1 keeps dst and 0 gives src. Both must be
+// the same size.
+func mixImages(dst, src *image.RGBA, weight float64) {
+	for y := range dst.Rect.Dy() {
+		pd := dst.Pix[y*dst.Stride : y*dst.Stride+4*dst.Rect.Dx()]
+		ps := src.Pix[y*src.Stride:]
+		for i, v := range pd {
+			pd[i] = uint8(math.Round(weight*float64(v) + (1-weight)*float64(ps[i])))
+		}
+	}
+}
+
+// scratchConflicts are the flags that work on the finished image or
+// frame in memory, which -scratch never holds.
+var scratchConflicts = []string{
+	"auto-contrast", "bloom", "stats", "measure", "histogram", "timingmap", "boundary-out", "verify",
+	"postprocess", "overlay-fractal", "checkerboard", "background-image", "grid", "orbit", "draw-orbit", "annotate", "stamp",
+	"terminal", "upload-url", "pipe",
+}
+
+// confirm asks question on w and reports whether the line read from r
+// answers yes. Anything else, including the end of r, is a no.
+func confirm(r io.Reader, w io.Writer, question string) bool {
+	fmt.Fprint(w, question)
+	line, _ := bufio.NewReader(r).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// multiResolutionLevels is how many sizes -multiresolution writes at
+// most: full, half, quarter and eighth.
+const multiResolutionLevels = 4
+
+// multiResolutionConflicts are the flags that work on the one finished
+// image, which -multiresolution replaces with several.
+var multiResolutionConflicts = []string{
+	"stats", "measure", "histogram", "timingmap", "boundary-out", "verify", "postprocess", "overlay-fractal",
+	"checkerboard", "background-image", "grid", "orbit", "draw-orbit", "annotate", "stamp",
+	"terminal", "upload-url", "pipe", "scratch",
+}
+
+// paletted16Conflicts are the flags that work on a finished full-color
+// image or on the escape counts, neither of which -paletted16 keeps.
+var paletted16Conflicts = []string{
+	"stats", "measure", "histogram", "timingmap", "boundary-out", "verify", "postprocess", "overlay-fractal",
+	"checkerboard", "background-image", "grid", "orbit", "draw-orbit", "annotate", "stamp",
+	"terminal", "upload-url", "pipe", "scratch", "multiresolution",
+}
+
+// mirrorConflicts are the flags that need the whole frame rendered, or
+// its escape counts, or that place things by the view's coordinates,
+// which a mirrored half no longer matches.
+var mirrorConflicts = []string{
+	"bloom", "auto-contrast", "stats", "measure", "histogram", "timingmap", "boundary-out", "verify",
+	"overlay-fractal", "grid", "orbit", "draw-orbit", "pipe", "scratch", "multiresolution", "paletted16",
+}
+
+// levelPath names the -multiresolution file of a width×height level:
+// path with "_WIDTHxHEIGHT" before its extension.
+func levelPath(path string, width, height int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s_%dx%d%s", strings.TrimSuffix(path, ext), width, height, ext)
+}
+
+// renderToScratch renders opts for -scratch: tile by tile into a scratch
+// file in dir, then encoded from the file to path. Only the tiles the
+// scratch buffer caches and the rows being encoded are held in memory.
+// The scratch file is removed once the image is saved; after a failure
+// it is kept, holding every finished tile, and the error names it.
+func renderToScratch(ctx context.Context, opts render.Options, dir, path, format string, profile render.ColorProfile, onProgress func(done, total int)) error {
+	buf, err := scratch.New(dir, opts.Width, opts.Height, 0, 0)
+	if err != nil {
+		return err
+	}
+	err = func() error {
+		n := buf.NumTiles()
+		for i := range n {
+			tile, err := render.RenderRect(ctx, opts, buf.TileRect(i))
+			if err != nil {
+				return fmt.Errorf("tile %d of %d: %w", i+1, n, err)
+			}
+			if err := buf.WriteTile(i, tile); err != nil {
+				return err
 			}
-			t = math.Pow(t, 0.8)
+			if onProgress != nil {
+				onProgress(i+1, n)
+			}
+		}
+		return output.WriteFile(path, func(w io.Writer) error {
+			if err := render.EncodeWithProfile(ctxWriter{ctx, w}, buf, format, render.Metadata(opts), profile); err != nil {
+				return err
+			}
+			return buf.Err()
+		})
+	}()
+	if err == nil {
+		return buf.Remove()
+	}
+	if ferr := buf.Flush(); ferr != nil {
+		buf.Remove()
+		return fmt.Errorf("%w; the scratch file couldn't be kept either: %v", err, ferr)
+	}
+	buf.Close()
+	return fmt.Errorf("%w; the finished tiles are in %s", err, buf.Path())
+}
+
+// writeHistogram charts the escape counts of res into the image file path
+// for -histogram.
+func writeHistogram(ctx context.Context, path string, res *render.Result) error {
+	format, err := render.FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	h, err := analysis.EscapeHistogram(res, 200)
+	if err != nil {
+		return err
+	}
+	chart := overlay.HistogramChart(h, 800, 400)
+	if err := output.WriteFile(path, func(w io.Writer) error { return render.Encode(ctxWriter{ctx, w}, chart, format) }); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote escape-count histogram to %s\n", path)
+	return nil
+}
+
+// writeTimingMap writes the work-unit timings of res for -timingmap: a
+// heatmap colored with ThermalHeat for an image file, and for a .csv file
+// a row per unit with its pixel rectangle, worker, and start and compute
+// time in nanoseconds.
+func writeTimingMap(ctx context.Context, path string, res *render.Result) error {
+	var write func(w io.Writer) error
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		write = func(w io.Writer) error {
+			bw := bufio.NewWriter(w)
+			bw.WriteString("x0,y0,x1,y1,worker,start_ns,elapsed_ns\n")
+			for _, t := range res.Timings {
+				r := t.Rect
+				fmt.Fprintf(bw, "%d,%d,%d,%d,%d,%d,%d\n", r.Min.X, r.Min.Y, r.Max.X, r.Max.Y, t.Worker, t.Start.Nanoseconds(), t.Elapsed.Nanoseconds())
+			}
+			return bw.Flush()
+		}
+	} else {
+		format, err := render.FormatFromPath(path)
+		if err != nil {
+			return err
+		}
+		opts := res.Options
+		heatmap := overlay.TimingMap(res.Timings, opts.Width, opts.Height, palette.Get("ThermalHeat"))
+		write = func(w io.Writer) error { return render.Encode(ctxWriter{ctx, w}, heatmap, format) }
+	}
+	if err := output.WriteFile(path, write); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote the timings of %d work units to %s\n", len(res.Timings), path)
+	return nil
+}
+
+// boundaryFormat returns the -boundary-out format for path: geojson or
+// csv, by extension.
+func boundaryFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".geojson", ".json":
+		return "geojson", nil
+	case ".csv":
+		return "csv", nil
+	}
+	return "", fmt.Errorf("%w: -boundary-out %q: want a .geojson, .json or .csv file", render.ErrUnsupportedFormat, path)
+}
+
+// writeBoundary traces res where its escape count crosses threshold (the
+// interior for 0), simplifies the lines to within tolerance pixels and
+// writes them to path.
+func writeBoundary(path string, res *render.Result, threshold, tolerance float64) error {
+	format, err := boundaryFormat(path)
+	if err != nil {
+		return err
+	}
+	opts := res.Options
+	tol := tolerance * min(opts.Bounds.Width()/float64(opts.Width), opts.Bounds.Height()/float64(opts.Height))
+	lines := boundary.Trace(res, threshold)
+	vertices := 0
+	for i, line := range lines {
+		lines[i] = boundary.Simplify(line, tol)
+		vertices += len(lines[i])
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if format == "csv" {
+		err = boundary.WriteCSV(f, lines)
+	} else {
+		err = boundary.WriteGeoJSON(f, lines, map[string]any{
+			"xmin": opts.Bounds.Xmin, "xmax": opts.Bounds.Xmax,
+			"ymin": opts.Bounds.Ymin, "ymax": opts.Bounds.Ymax,
+			"rotation": opts.Rotation, "width": opts.Width, "height": opts.Height,
+			"iters": opts.MaxIter, "threshold": threshold,
+		})
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d boundary lines (%d vertices) to %s\n", len(lines), vertices, path)
+	return nil
+}
+
+// orbitColors are the colors of successive -orbit flags.
+var orbitColors = []color.NRGBA{
+	{0xff, 0xff, 0xff, 0xff},
+	{0xff, 0xd0, 0x00, 0xff},
+	{0x00, 0xe5, 0xff, 0xff},
+	{0xff, 0x4d, 0xb8, 0xff},
+	{0x7d, 0xff, 0x5c, 0xff},
+}
+
+// orbitFlag collects the points given to repeated -orbit and
+// -draw-orbit flags, written cre,cim or as a complex number re+imi.
+type orbitFlag []complex128
+
+func (o *orbitFlag) String() string {
+	parts := make([]string, len(*o))
+	for i, c := range *o {
+		parts[i] = strconv.FormatFloat(real(c), 'g', -1, 64) + "," + strconv.FormatFloat(imag(c), 'g', -1, 64)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (o *orbitFlag) Set(s string) error {
+	re, im, ok := strings.Cut(s, ",")
+	if !ok {
+		c, err := strconv.ParseComplex(strings.TrimSpace(s), 128)
+		if err != nil {
+			return errors.New("want cre,cim or re+imi")
 		}
+		*o = append(*o, c)
+		return nil
+	}
+	x, errRe := strconv.ParseFloat(strings.TrimSpace(re), 64)
+	y, errIm := strconv.ParseFloat(strings.TrimSpace(im), 64)
+	if errRe != nil || errIm != nil {
+		return errors.New("want cre,cim or re+imi")
+	}
+	*o = append(*o, complex(x, y))
+	return nil
+}
+
+// checkerboardBackground returns an opaque width×height checkerboard of
+// squareSize-pixel squares, grey in the top left corner and white next
+// to it.
+func checkerboardBackground(width, height, squareSize int) *image.RGBA {
+	bg := image.NewRGBA(image.Rect(0, 0, width, height))
+	grey := image.NewUniform(color.RGBA{0xcc, 0xcc, 0xcc, 0xff})
+	draw.Draw(bg, bg.Rect, image.White, image.Point{}, draw.Src)
+	for y := 0; y < height; y += squareSize {
+		for x := (y / squareSize % 2) * squareSize; x < width; x += 2 * squareSize {
+			draw.Draw(bg, image.Rect(x, y, x+squareSize, y+squareSize), grey, image.Point{}, draw.Src)
+		}
+	}
+	return bg
+}
 
-		clr := cmap.Interpolate(t)
-		img.SetRGBA(x, y, clr)
+// compositeSourceOver draws fg over bg with the Porter-Duff source-over
+// operator, fg's alpha deciding how much of bg shows through. It aligns
+// their top left corners and returns bg.
+func compositeSourceOver(fg, bg *image.RGBA) *image.RGBA {
+	draw.Draw(bg, bg.Rect, fg, fg.Rect.Min, draw.Over)
+	return bg
+}
+
+// overlayColor parses the #RRGGBB color given to the flags starting with
+// prefix and gives it the opacity in [0,1].
+func overlayColor(prefix, hex string, opacity float64) (color.NRGBA, error) {
+	if !(opacity >= 0 && opacity <= 1) {
+		return color.NRGBA{}, fmt.Errorf("%w: %s-opacity %g: must be within [0,1]", render.ErrInvalidOptions, prefix, opacity)
+	}
+	stop, err := palette.NewStopHex(0, hex)
+	if len(strings.TrimPrefix(hex, "#")) != 6 {
+		err = errors.New("want #RRGGBB")
+	}
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("%w: %s-color: %v", render.ErrInvalidOptions, prefix, err)
 	}
+	c := stop.Color.(color.NRGBA)
+	c.A = uint8(math.Round(opacity * 255))
+	return c, nil
 }
 
-func mandelbrotIterations(c complex128, maxIter int) (int, complex128) {
-	var z complex128
-	for n := range maxIter {
-		z = z*z + c
-		if real(z)*real(z)+imag(z)*imag(z) > 4.0 {
-			return n, z
+// cornerNames returns overlay.Corners as a comma-separated list.
+func cornerNames() string {
+	names := make([]string, len(overlay.Corners))
+	for i, c := range overlay.Corners {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ", ")
+}
+
+// stampLines describes the view of opts for -stamp: its center, to a
+// tenth of a pixel, the zoom relative to the default view, and the
+// iteration count, palette and formula.
+func stampLines(opts render.Options) []string {
+	decimals := max(1, int(math.Ceil(-math.Log10(opts.Bounds.Width()/float64(opts.Width)/10))))
+	f := func(v float64) string {
+		s := strings.TrimRight(strconv.FormatFloat(v, 'f', decimals, 64), "0")
+		if strings.HasSuffix(s, ".") {
+			s += "0"
 		}
+		return s
+	}
+	c := opts.Bounds.Center()
+	center := "center " + f(real(c))
+	if imag(c) >= 0 {
+		center += "+"
 	}
-	return maxIter, z
+	center += f(imag(c)) + "i"
+	zoom := render.DefaultBounds.Width() / opts.Bounds.Width()
+	view := "zoom " + strconv.FormatFloat(zoom, 'g', 4, 64) + "x"
+	if opts.Rotation != 0 {
+		view += "  rotate " + strconv.FormatFloat(opts.Rotation, 'g', -1, 64) + " deg"
+	}
+	params := "iters " + strconv.Itoa(opts.MaxIter)
+	if opts.Palette != nil && opts.Palette.Keyword != "" {
+		params += "  " + opts.Palette.Keyword
+	}
+	if name := fractal.NameOf(opts.Fractal); name != "" && name != "mandelbrot" {
+		params += "  " + name
+	}
+	if x, ok := opts.Fractal.(fractal.Expr); ok {
+		params += "  " + x.Source()
+	}
+	return []string{center, view + "  " + params}
 }
 
-// cmplxAbs returns the magnitude of a complex128.
-func cmplxAbs(z complex128) float64 {
-	return math.Hypot(real(z), imag(z))
+// globalRand returns the random source for seed, which every random
+// feature draws from so that one -seed repeats a run. A seed of 0 picks
+// one from the clock and prints it to stderr, so a run that turned out
+// well can be repeated.
+func globalRand(seed uint64) *rand.Rand {
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+		fmt.Fprintf(os.Stderr, "seed %d\n", seed)
+	}
+	return rand.New(rand.NewPCG(seed, 0x73636f7574))
 }
 
+// fail prints err with an optional prefix and exits with exitStatus(err).
+func fail(prefix string, err error) {
+	fmt.Fprintf(os.Stderr, "%s%v\n", prefix, err)
+	os.Exit(exitStatus(err))
+}
+
+// exitStatus is the status a run failing with err exits with, reflecting
+// its kind: 2 for bad input, 3 for a render that finished but couldn't be
+// written out, 130 for an interrupted run and 1 for everything else.
+func exitStatus(err error) int {
+	switch {
+	case errors.Is(err, render.ErrCancelled), errors.Is(err, context.Canceled):
+		return 130
+	case errors.Is(err, output.ErrOutput):
+		return 3
+	case errors.Is(err, render.ErrInvalidOptions),
+		errors.Is(err, render.ErrInvalidViewport),
+		errors.Is(err, render.ErrUnknownPalette),
+		errors.Is(err, render.ErrUnsupportedFormat),
+		errors.Is(err, render.ErrPrecisionExceeded):
+		return 2
+	}
+	return 1
+}
+
+// profileNames returns the color profiles as a comma-separated list.
+func profileNames() string {
+	names := make([]string, len(render.ColorProfiles))
+	for i, p := range render.ColorProfiles {
+		names[i] = string(p)
+	}
+	return strings.Join(names, ", ")
+}
+
+// zoomToPointFactor is how far -zoom-to-point zooms in.
+const zoomToPointFactor = 10
+
+// zoomedToPoint returns b centered on the point a+bi in s and zoomed in
+// by zoomToPointFactor, for -zoom-to-point. The window keeps b's aspect
+// ratio.
+func zoomedToPoint(b coords.Bounds, s string) (coords.Bounds, error) {
+	p, err := strconv.ParseComplex(strings.TrimSpace(s), 128)
+	if err != nil {
+		return coords.Bounds{}, fmt.Errorf("%w: -zoom-to-point %q: not a complex number a+bi", render.ErrInvalidOptions, s)
+	}
+	return b.ZoomedTo(p, zoomToPointFactor), nil
+}
+
+// parseComplexList parses a comma-separated list of complex numbers in
+// the a+bi form strconv.ParseComplex accepts, such as "0,1+0i,-1-0.5i",
+// for -trap-points and -mobius.
+func parseComplexList(s string) ([]complex128, error) {
+	var points []complex128
+	for f := range strings.SplitSeq(s, ",") {
+		p, err := strconv.ParseComplex(strings.TrimSpace(f), 128)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: not a complex number a+bi", render.ErrInvalidOptions, strings.TrimSpace(f))
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// coloringNames returns the coloring modes as a comma-separated list.
+func coloringNames() string {
+	names := make([]string, len(render.Colorings))
+	for i, c := range render.Colorings {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ", ")
+}
+
+// printProgress draws a one-line progress bar on stderr.
+func printProgress(done, total int) {
+	const barWidth = 40
+	if total <= 0 {
+		return
+	}
+	filled := done * barWidth / total
+	fmt.Fprintf(os.Stderr, "\r[%s%s] %3d%%", strings.Repeat("#", filled), strings.Repeat(" ", barWidth-filled), done*100/total)
+	if done == total {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// printStats reports the render statistics on stdout.
+func printStats(s render.Stats) {
+	fmt.Printf("Rendered %d pixels in %v\n", s.Pixels, s.Elapsed.Round(time.Millisecond))
+	fmt.Printf("  inside:     %d (%.2f%%)\n", s.InsidePixels, 100*s.InsideFraction)
+	if s.SkippedPixels > 0 {
+		fmt.Printf("  skipped:    %d (%.2f%%) by -sparse\n", s.SkippedPixels, 100*float64(s.SkippedPixels)/float64(s.Pixels))
+	}
+	fmt.Printf("  iterations: min %d, max %d, mean %.1f (escaped pixels)\n", s.MinIter, s.MaxIter, s.MeanIter)
+}
+
+// printArea reports an area estimate on stdout.
+func printArea(a analysis.Area) {
+	fmt.Printf("Set area in view: %.6f ± %.6f (95%%)\n", a.Area, a.Err)
+	fmt.Printf("  boundary:   %d pixels, %d samples each\n", a.Boundary, a.Samples)
+}
+
+// compareImages counts the pixels that differ between a and b. Images of
+// different sizes differ everywhere.
+func compareImages(a, b *image.RGBA) int {
+	ab, bb := a.Bounds(), b.Bounds()
+	if ab.Size() != bb.Size() {
+		return max(ab.Dx()*ab.Dy(), bb.Dx()*bb.Dy())
+	}
+	n := 0
+	for y := range ab.Dy() {
+		for x := range ab.Dx() {
+			if a.RGBAAt(ab.Min.X+x, ab.Min.Y+y) != b.RGBAAt(bb.Min.X+x, bb.Min.Y+y) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// ctxWriter fails writes once ctx is done, aborting an in-flight encode.
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (cw ctxWriter) Write(p []byte) (int, error) {
+	if err := cw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cw.w.Write(p)
+}
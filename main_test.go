@@ -0,0 +1,548 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"io/fs"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/output"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func TestExitStatus(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: width -1", render.ErrInvalidOptions), 2},
+		{fmt.Errorf("%w: empty", render.ErrInvalidViewport), 2},
+		{&render.PaletteError{Name: "Nope"}, 2},
+		{fmt.Errorf("%w: bmp", render.ErrUnsupportedFormat), 2},
+		{fmt.Errorf("%w: tiny", render.ErrPrecisionExceeded), 2},
+		{errors.Join(fmt.Errorf("%w: a", render.ErrInvalidOptions), fmt.Errorf("%w: b", render.ErrInvalidViewport)), 2},
+		{&output.WriteError{Stage: "rename", Path: "out.png", Err: fs.ErrPermission}, 3},
+		{&render.CancelledError{Done: 3, Total: 10, Err: context.Canceled}, 130},
+		{fmt.Errorf("waiting: %w", context.Canceled), 130},
+		{errors.New("something else"), 1},
+		{fmt.Errorf("%w", render.ErrClosed), 1},
+	} {
+		if got := exitStatus(tc.err); got != tc.want {
+			t.Errorf("exitStatus(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestCompareImages(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	b := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	if n := compareImages(a, b); n != 0 {
+		t.Errorf("identical images: %d differ", n)
+	}
+	b.SetRGBA(1, 2, color.RGBA{A: 1})
+	b.SetRGBA(3, 0, color.RGBA{R: 9, A: 0xff})
+	if n := compareImages(a, b); n != 2 {
+		t.Errorf("%d pixels differ, want 2", n)
+	}
+	// the same pixels at a different origin
+	c := image.NewRGBA(image.Rect(10, 10, 14, 13))
+	c.SetRGBA(11, 12, color.RGBA{A: 1})
+	c.SetRGBA(13, 10, color.RGBA{R: 9, A: 0xff})
+	if n := compareImages(b, c); n != 0 {
+		t.Errorf("an offset copy: %d pixels differ", n)
+	}
+	if n := compareImages(a, image.NewRGBA(image.Rect(0, 0, 5, 3))); n != 15 {
+		t.Errorf("different sizes: %d differ, want 15", n)
+	}
+}
+
+func TestCheckerboardComposite(t *testing.T) {
+	grey, white := color.RGBA{0xcc, 0xcc, 0xcc, 0xff}, color.RGBA{0xff, 0xff, 0xff, 0xff}
+	bg := checkerboardBackground(37, 21, 8)
+	for _, tc := range []struct {
+		x, y int
+		want color.RGBA
+	}{{0, 0, grey}, {7, 7, grey}, {8, 0, white}, {0, 8, white}, {8, 8, grey}, {36, 20, grey}, {36, 12, white}} {
+		if got := bg.RGBAAt(tc.x, tc.y); got != tc.want {
+			t.Errorf("checkerboard (%d, %d) = %v, want %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+
+	// a foreground transparent in its top left 16×16, half transparent
+	// red in the rest
+	fg := image.NewRGBA(image.Rect(0, 0, 37, 21))
+	for y := range 21 {
+		for x := range 37 {
+			if x >= 16 || y >= 16 {
+				fg.SetRGBA(x, y, color.RGBA{0x80, 0, 0, 0x80})
+			}
+		}
+	}
+	out := compositeSourceOver(fg, checkerboardBackground(37, 21, 16))
+	for y := range 21 {
+		for x := range 37 {
+			c := out.RGBAAt(x, y)
+			if c.A != 0xff {
+				t.Fatalf("(%d, %d) has alpha %d", x, y, c.A)
+			}
+			if x < 16 && y < 16 && c != grey {
+				t.Fatalf("(%d, %d) is %v, want the checkerboard's grey", x, y, c)
+			}
+		}
+	}
+	// half red over white: 0x80 + 0xff·(1-0x80/0xff)
+	if got := out.RGBAAt(20, 0); got.R < 0xfe || got.G != 0x7f || got.B != 0x7f {
+		t.Errorf("half red over white is %v", got)
+	}
+}
+
+func TestOrbitFlag(t *testing.T) {
+	var o orbitFlag
+	for _, s := range []string{"-0.75,0.2", " 0.3 , 0.5 ", "-0.1+0.65i", "0.26", "2i"} {
+		if err := o.Set(s); err != nil {
+			t.Errorf("Set(%q): %v", s, err)
+		}
+	}
+	want := orbitFlag{-0.75 + 0.2i, 0.3 + 0.5i, -0.1 + 0.65i, 0.26, 2i}
+	if fmt.Sprint(o) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", o, want)
+	}
+	for _, s := range []string{"", "1,", "x,1", "1+i2", "1,2,3"} {
+		if err := o.Set(s); err == nil {
+			t.Errorf("Set(%q): no error", s)
+		}
+	}
+}
+
+// captureStderr returns what f writes to os.Stderr.
+func captureStderr(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = old }()
+	f()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestGlobalRand(t *testing.T) {
+	draws := func(rng *rand.Rand) []uint64 {
+		v := make([]uint64, 8)
+		for i := range v {
+			v[i] = rng.Uint64()
+		}
+		return v
+	}
+	var a, b []uint64
+	if out := captureStderr(t, func() { a, b = draws(globalRand(42)), draws(globalRand(42)) }); out != "" {
+		t.Errorf("seed 42 printed %q", out)
+	}
+	if !slices.Equal(a, b) {
+		t.Errorf("seed 42 gave %v, then %v", a, b)
+	}
+
+	// seed 0 picks a seed from the clock each time and prints it
+	var seeds [2]uint64
+	for i := range seeds {
+		out := captureStderr(t, func() { a = draws(globalRand(0)) })
+		if _, err := fmt.Sscanf(out, "seed %d\n", &seeds[i]); err != nil || seeds[i] == 0 {
+			t.Fatalf("seed 0 printed %q", out)
+		}
+		// which repeats the run when passed back
+		if b = draws(globalRand(seeds[i])); !slices.Equal(a, b) {
+			t.Errorf("seed %d gave %v, seed 0 that printed it %v", seeds[i], b, a)
+		}
+		time.Sleep(time.Microsecond)
+	}
+	if seeds[0] == seeds[1] {
+		t.Errorf("two runs with seed 0 both used %d", seeds[0])
+	}
+}
+
+func TestSaveImageSalvage(t *testing.T) {
+	o, err := render.New(render.WithSize(40, 30), render.WithIterations(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	// an encoder that fails partway salvages the escape counts, and the
+	// run exits as a render whose output failed
+	path := filepath.Join(dir, "out.png")
+	err = saveImage(context.Background(), path, res, res.Image, "bmp", render.ProfileSRGB)
+	if err == nil || !strings.Contains(err.Error(), "saved to "+output.SalvagePath(path)) {
+		t.Fatalf("got %v", err)
+	}
+	if exitStatus(err) != 3 {
+		t.Errorf("exit status %d, want 3", exitStatus(err))
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("%s was written: %v", path, err)
+	}
+	if f, err := os.Open(output.SalvagePath(path)); err != nil {
+		t.Error(err)
+	} else {
+		m, err := output.ReadMbuf(f)
+		f.Close()
+		if err != nil || !slices.Equal(m.Iters, res.Iters) {
+			t.Errorf("the salvage doesn't hold the escape counts: %v", err)
+		}
+	}
+
+	// the salvage failing too is reported with the first error
+	blocked := filepath.Join(dir, "blocked.png")
+	if err := os.Mkdir(output.SalvagePath(blocked), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err = saveImage(context.Background(), blocked, res, res.Image, "bmp", render.ProfileSRGB)
+	if err == nil || !strings.Contains(err.Error(), "salvaging the escape counts failed too") || exitStatus(err) != 3 {
+		t.Errorf("salvage blocked: %v", err)
+	}
+
+	// an interrupted save is not salvaged
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelled := filepath.Join(dir, "cancelled.png")
+	if err := saveImage(ctx, cancelled, res, res.Image, "png", render.ProfileSRGB); err == nil {
+		t.Error("a cancelled save succeeded")
+	}
+	if _, err := os.Stat(output.SalvagePath(cancelled)); !os.IsNotExist(err) {
+		t.Errorf("a cancelled save was salvaged: %v", err)
+	}
+	if extra, _ := filepath.Glob(filepath.Join(dir, ".*")); len(extra) != 0 {
+		t.Errorf("left behind %v", extra)
+	}
+}
+
+func TestRenderToScratch(t *testing.T) {
+	opts, err := render.New(render.WithSize(600, 400), render.WithIterations(60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	res, err := render.Render(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want strings.Builder
+	if err := render.EncodeWithProfile(&want, res.Image, "png", render.Metadata(opts), render.ProfileSRGB); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, scratchDir := t.TempDir(), t.TempDir()
+	path := filepath.Join(dir, "out.png")
+	var calls int
+	if err := renderToScratch(ctx, opts, scratchDir, path, "png", render.ProfileSRGB, func(done, total int) { calls++ }); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want.String() {
+		t.Error("-scratch output differs from the in-memory render")
+	}
+	if calls != 6 { // 256px tiles: 3×2
+		t.Errorf("%d progress calls, want 6", calls)
+	}
+	if left, _ := os.ReadDir(scratchDir); len(left) != 0 {
+		t.Errorf("scratch file left after success: %v", left)
+	}
+
+	// a cancelled render keeps the scratch file and names it
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = renderToScratch(cancelled, opts, scratchDir, filepath.Join(dir, "cancelled.png"), "png", render.ProfileSRGB, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled: %v", err)
+	}
+	left, _ := os.ReadDir(scratchDir)
+	if len(left) != 1 || !strings.Contains(err.Error(), left[0].Name()) {
+		t.Errorf("cancelled: kept %v, error %v", left, err)
+	}
+}
+
+func TestMultiplicativeBlend(t *testing.T) {
+	opts, err := render.New(render.WithSize(64, 48), render.WithIterations(100), render.WithFractalName("julia", complex(-0.8, 0.156)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	julia := res.Image
+	solid := func(c color.RGBA) *image.RGBA {
+		img := image.NewRGBA(julia.Rect)
+		for i := 0; i < len(img.Pix); i += 4 {
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+		}
+		return img
+	}
+	white, black := solid(color.RGBA{0xff, 0xff, 0xff, 0xff}), solid(color.RGBA{0, 0, 0, 0xff})
+
+	for _, order := range [][2]*image.RGBA{{white, julia}, {julia, white}} {
+		if got := multiplicativeBlend(order[0], order[1]); !slices.Equal(got.Pix, julia.Pix) {
+			t.Error("white times the render isn't the render")
+		}
+	}
+	for _, order := range [][2]*image.RGBA{{black, julia}, {julia, black}} {
+		if got := multiplicativeBlend(order[0], order[1]); !slices.Equal(got.Pix, black.Pix) {
+			t.Error("black times the render isn't black")
+		}
+	}
+	// half grey halves each channel, rounding down
+	grey := solid(color.RGBA{0x80, 0x80, 0x80, 0x80})
+	if got := multiplicativeBlend(grey, solid(color.RGBA{200, 100, 1, 0xff})).RGBAAt(3, 3); got != (color.RGBA{100, 50, 0, 0x80}) {
+		t.Errorf("grey times a color: %v", got)
+	}
+
+	// the overlap, from the top left corners
+	small := image.NewRGBA(image.Rect(10, 10, 20, 15))
+	for i := range small.Pix {
+		small.Pix[i] = 0xff
+	}
+	got := multiplicativeBlend(small, julia)
+	if got.Rect != image.Rect(0, 0, 10, 5) {
+		t.Fatalf("overlap %v", got.Rect)
+	}
+	for y := range 5 {
+		for x := range 10 {
+			if got.RGBAAt(x, y) != julia.RGBAAt(x, y) {
+				t.Fatalf("overlap (%d, %d) = %v, want %v", x, y, got.RGBAAt(x, y), julia.RGBAAt(x, y))
+			}
+		}
+	}
+}
+
+func TestMixImages(t *testing.T) {
+	dst := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	copy(dst.Pix, []byte{0, 0, 0, 255, 200, 200, 200, 255})
+	copy(src.Pix, []byte{100, 200, 255, 255, 0, 0, 0, 255})
+	mixImages(dst, src, 0.25)
+	if want := []byte{75, 150, 191, 255, 50, 50, 50, 255}; !slices.Equal(dst.Pix, want) {
+		t.Errorf("mixed %v, want %v", dst.Pix, want)
+	}
+}
+
+func TestParseOverlayFractal(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		name   string
+		weight float64
+	}{
+		{"julia", "julia", 1},
+		{"julia:0.7", "julia", 0.7},
+		{"mandelbrot:0", "mandelbrot", 0},
+	} {
+		name, weight, err := parseOverlayFractal(tc.in)
+		if err != nil || name != tc.name || weight != tc.weight {
+			t.Errorf("%q: %q, %v, %v", tc.in, name, weight, err)
+		}
+	}
+	for _, in := range []string{"", "koch", "julia:", "julia:1.5", "julia:-0.1", "julia:NaN", "julia:x"} {
+		if _, _, err := parseOverlayFractal(in); !errors.Is(err, render.ErrInvalidOptions) {
+			t.Errorf("%q: %v", in, err)
+		}
+	}
+}
+
+func TestLevelPath(t *testing.T) {
+	for _, tc := range []struct{ path, want string }{
+		{"mandelbrot.png", "mandelbrot_800x600.png"},
+		{"out/deep.zoom.jpg", "out/deep.zoom_800x600.jpg"},
+		{"noext", "noext_800x600"},
+	} {
+		if got := levelPath(tc.path, 800, 600); got != tc.want {
+			t.Errorf("levelPath(%q) = %q, want %q", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	for in, want := range map[string]bool{
+		"y\n": true, "yes\n": true, " Y \r\n": true, "YES": true,
+		"n\n": false, "\n": false, "": false, "yep\n": false, "no\ny\n": false,
+	} {
+		var out strings.Builder
+		if got := confirm(strings.NewReader(in), &out, "Render anyway? "); got != want {
+			t.Errorf("%q: %v, want %v", in, got, want)
+		}
+		if out.String() != "Render anyway? " {
+			t.Errorf("%q: asked %q", in, out.String())
+		}
+	}
+}
+
+func TestZoomedToPoint(t *testing.T) {
+	near := func(a, b float64) bool { return math.Abs(a-b) <= 1e-12 }
+	def := render.DefaultBounds
+	b, err := zoomedToPoint(def, "-0.5+0i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := coords.Bounds{Xmin: -0.66, Xmax: -0.34, Ymin: -0.16, Ymax: 0.16}
+	if !near(b.Xmin, want.Xmin) || !near(b.Xmax, want.Xmax) || !near(b.Ymin, want.Ymin) || !near(b.Ymax, want.Ymax) {
+		t.Errorf("got %+v, want %+v", b, want)
+	}
+	if !near(b.Width(), def.Width()/10) || b.Center() != -0.5 {
+		t.Errorf("width %v centered on %v, want %v centered on -0.5", b.Width(), b.Center(), def.Width()/10)
+	}
+	if !near(b.AspectRatio(), def.AspectRatio()) {
+		t.Errorf("aspect ratio %v, want %v", b.AspectRatio(), def.AspectRatio())
+	}
+
+	// from a view that isn't the default, as after -location
+	wide := coords.Bounds{Xmin: -1, Xmax: 3, Ymin: 0, Ymax: 1}
+	b, err = zoomedToPoint(wide, " 0.25-0.1i ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := b.Center(); !near(real(c), 0.25) || !near(imag(c), -0.1) || !near(b.Width(), 0.4) || !near(b.AspectRatio(), 4) {
+		t.Errorf("got %+v", b)
+	}
+
+	for _, s := range []string{"", "center", "1,2", "1+i2"} {
+		if _, err := zoomedToPoint(def, s); !errors.Is(err, render.ErrInvalidOptions) {
+			t.Errorf("%q: got %v, want ErrInvalidOptions", s, err)
+		}
+	}
+}
+
+func TestWriteBoundary(t *testing.T) {
+	opts, err := render.New(render.WithSize(120, 90), render.WithIterations(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	vertices := func(path string, tolerance float64) int {
+		t.Helper()
+		if err := writeBoundary(path, res, 0, tolerance); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasSuffix(path, ".csv") {
+			return strings.Count(string(data), "\n") - 1 // less the header
+		}
+		var fc struct {
+			Features []struct {
+				Geometry struct {
+					Coordinates [][][2]float64
+				}
+				Properties map[string]any
+			}
+		}
+		if err := json.Unmarshal(data, &fc); err != nil {
+			t.Fatal(err)
+		}
+		if p := fc.Features[0].Properties; p["width"] != 120.0 || p["xmin"] != opts.Bounds.Xmin {
+			t.Errorf("%s: properties %v don't describe the view", path, p)
+		}
+		n := 0
+		for _, line := range fc.Features[0].Geometry.Coordinates {
+			n += len(line)
+		}
+		return n
+	}
+	full := vertices(filepath.Join(dir, "full.geojson"), 0)
+	if n := vertices(filepath.Join(dir, "full.csv"), 0); n != full || full == 0 {
+		t.Errorf("%d vertices in CSV, %d in GeoJSON", n, full)
+	}
+	// the tolerance is in pixels: a pixel's worth drops most of the
+	// vertices of a traced staircase
+	if n := vertices(filepath.Join(dir, "simple.csv"), 1); n >= full/2 {
+		t.Errorf("%d of %d vertices left at a tolerance of a pixel", n, full)
+	}
+	if err := writeBoundary(filepath.Join(dir, "boundary.svg"), res, 0, 0); !errors.Is(err, render.ErrUnsupportedFormat) {
+		t.Errorf("got %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestStampLines(t *testing.T) {
+	opts, err := render.New(render.WithSize(800, 600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"center -0.6+0.0i", "zoom 1x  iters " + fmt.Sprint(opts.MaxIter) + "  " + render.DefaultPalette}
+	if got := stampLines(opts); !slices.Equal(got, want) {
+		t.Errorf("default view: got %q, want %q", got, want)
+	}
+
+	// a deep, turned Julia view: the center to a tenth of a pixel, 2e-7
+	// wide over 800 pixels, is given to 11 decimals, less trailing zeros
+	opts, err = render.New(render.WithSize(800, 600),
+		render.WithViewport(coords.Bounds{Xmin: -0.7436439, Xmax: -0.7436437, Ymin: 0.13182575, Ymax: 0.13182605}),
+		render.WithRotation(30), render.WithIterations(2000),
+		render.WithFractalName("julia", complex(-0.8, 0.156)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"center -0.7436438+0.1318259i",
+		"zoom " + strconv.FormatFloat(render.DefaultBounds.Width()/2e-7, 'g', 4, 64) + "x  rotate 30 deg  iters 2000  " + render.DefaultPalette + "  julia",
+	}
+	if got := stampLines(opts); !slices.Equal(got, want) {
+		t.Errorf("deep view: got %q, want %q", got, want)
+	}
+}
+
+func TestOverlayColor(t *testing.T) {
+	c, err := overlayColor("-text-box", "#336699", 0.5)
+	if want := (color.NRGBA{0x33, 0x66, 0x99, 0x80}); err != nil || c != want {
+		t.Errorf("got %v, %v, want %v", c, err, want)
+	}
+	for _, tc := range []struct {
+		hex     string
+		opacity float64
+		want    string
+	}{
+		{"#336699", 1.5, "-text-box-opacity"},
+		{"#336699", math.NaN(), "-text-box-opacity"},
+		{"#369", 1, "-text-box-color"},
+		{"336699", 1, ""},
+		{"#33669g", 1, "-text-box-color"},
+	} {
+		_, err := overlayColor("-text-box", tc.hex, tc.opacity)
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("%q at %g: %v", tc.hex, tc.opacity, err)
+			}
+		} else if !errors.Is(err, render.ErrInvalidOptions) || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%q at %g: got %v, want ErrInvalidOptions naming %s", tc.hex, tc.opacity, err, tc.want)
+		}
+	}
+}
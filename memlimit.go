@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// renderBytesPerPixel is what a render holds for each pixel until it is
+// saved: the RGBA color, the float64 iteration count and the interior
+// flag.
+const renderBytesPerPixel = 4 + 8 + 1
+
+// defaultMaxMemoryMB is the default -max-memory: at renderBytesPerPixel
+// it allows about 165 megapixels, an 8000×6000 render with room to spare.
+const defaultMaxMemoryMB = 2048
+
+// estimateMemoryBytes returns roughly how much memory a width×height
+// render needs for its buffers.
+func estimateMemoryBytes(width, height int) int64 {
+	return int64(width) * int64(height) * renderBytesPerPixel
+}
+
+// checkMemoryLimit returns an error matching render.ErrInvalidOptions if
+// a width×height render is estimated to need more than limitMB
+// mebibytes; a limitMB of 0 or less means no limit.
+func checkMemoryLimit(width, height int, limitMB int) error {
+	if limitMB <= 0 {
+		return nil
+	}
+	if est := estimateMemoryBytes(width, height); est > int64(limitMB)<<20 {
+		return fmt.Errorf("%w: a %dx%d render needs about %s of memory, over -max-memory %d MB; raise -max-memory or use -scratch",
+			render.ErrInvalidOptions, width, height, formatMB(est), limitMB)
+	}
+	return nil
+}
+
+// formatMB formats n bytes in whole mebibytes, or gibibytes with one
+// decimal from 10 GiB up.
+func formatMB(n int64) string {
+	if n >= 10<<30 {
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	}
+	return fmt.Sprintf("%d MB", (n+1<<20-1)>>20)
+}
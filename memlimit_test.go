@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func TestEstimateMemoryBytes(t *testing.T) {
+	if got, want := estimateMemoryBytes(1600, 1200), int64(1600*1200*13); got != want {
+		t.Errorf("estimateMemoryBytes(1600, 1200) = %d, want %d", got, want)
+	}
+	// past what an int32 product could hold
+	if got, want := estimateMemoryBytes(100000, 100000), int64(130_000_000_000); got != want {
+		t.Errorf("estimateMemoryBytes(100000, 100000) = %d, want %d", got, want)
+	}
+}
+
+func TestCheckMemoryLimit(t *testing.T) {
+	if err := checkMemoryLimit(1600, 1200, 512); err != nil {
+		t.Errorf("1600x1200: %v", err)
+	}
+
+	err := checkMemoryLimit(100000, 100000, 512)
+	if !errors.Is(err, render.ErrInvalidOptions) {
+		t.Fatalf("100000x100000: got %v, want ErrInvalidOptions", err)
+	}
+	for _, want := range []string{"100000x100000", "121.1 GB", "512 MB"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("%q doesn't mention %q", err, want)
+		}
+	}
+
+	// 8000x8000 is 794 MB: over the default but within a raised limit
+	if err := checkMemoryLimit(8000, 8000, 512); err == nil || !strings.Contains(err.Error(), "794 MB") {
+		t.Errorf("8000x8000 at 512 MB: got %v, want an error mentioning 794 MB", err)
+	}
+	if err := checkMemoryLimit(8000, 8000, 1024); err != nil {
+		t.Errorf("8000x8000 at 1024 MB: %v", err)
+	}
+
+	// the default takes the large renders people ask for
+	for _, size := range [][2]int{{8000, 6000}, {12000, 12000}} {
+		if err := checkMemoryLimit(size[0], size[1], defaultMaxMemoryMB); err != nil {
+			t.Errorf("%dx%d at the default: %v", size[0], size[1], err)
+		}
+	}
+	if err := checkMemoryLimit(13000, 13000, defaultMaxMemoryMB); err == nil || !strings.Contains(err.Error(), "2096 MB") {
+		t.Errorf("13000x13000 at the default: got %v, want an error mentioning 2096 MB", err)
+	}
+
+	for _, limit := range []int{0, -1} {
+		if err := checkMemoryLimit(100000, 100000, limit); err != nil {
+			t.Errorf("limit %d means none, got %v", limit, err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// serverMetrics exports request, cache and renderer figures for /metrics.
+// It implements render.Metrics, so every render the server starts
+// reports to it.
+type serverMetrics struct {
+	reg      *prometheus.Registry
+	requests *prometheus.CounterVec   // by endpoint and outcome
+	latency  *prometheus.HistogramVec // by endpoint and outcome
+	tiles    *prometheus.CounterVec   // by source: cache or computed
+	inFlight prometheus.Gauge
+	renders  *prometheus.CounterVec // by outcome
+	pixels   prometheus.Counter
+	busy     prometheus.Counter // worker seconds spent computing rows
+	capacity prometheus.Counter // worker seconds available while rendering
+}
+
+func newServerMetrics() *serverMetrics {
+	m := &serverMetrics{
+		reg: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mandelbrot_requests_total",
+			Help: "Requests handled, by endpoint and outcome.",
+		}, []string{"endpoint", "outcome"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mandelbrot_request_duration_seconds",
+			Help:    "Request latency, by endpoint and outcome.",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 14), // 5ms to 41s
+		}, []string{"endpoint", "outcome"}),
+		tiles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mandelbrot_tiles_total",
+			Help: "Tiles served, by source (cache or computed).",
+		}, []string{"source"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "mandelbrot_renders_in_flight",
+			Help: "Renders currently running.",
+		}),
+		renders: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mandelbrot_renders_total",
+			Help: "Renders finished, by outcome.",
+		}, []string{"outcome"}),
+		pixels: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mandelbrot_pixels_computed_total",
+			Help: "Pixels computed; its rate is pixels per second.",
+		}),
+		busy: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mandelbrot_worker_busy_seconds_total",
+			Help: "Worker time spent computing rows.",
+		}),
+		capacity: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mandelbrot_worker_capacity_seconds_total",
+			Help: "Worker time available to running renders; busy/capacity is pool utilization.",
+		}),
+	}
+	m.reg.MustRegister(m.requests, m.latency, m.tiles, m.inFlight, m.renders,
+		m.pixels, m.busy, m.capacity,
+		prometheus.NewGoCollector(), prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	return m
+}
+
+// handler serves the metrics in the Prometheus text format.
+func (m *serverMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.reg, promhttp.HandlerOpts{})
+}
+
+// observeRequest records one finished request.
+func (m *serverMetrics) observeRequest(endpoint, outcome string, d time.Duration) {
+	m.requests.WithLabelValues(endpoint, outcome).Inc()
+	m.latency.WithLabelValues(endpoint, outcome).Observe(d.Seconds())
+}
+
+// tile records a tile served from the cache or rendered for the request.
+func (m *serverMetrics) tile(cached bool) {
+	source := "computed"
+	if cached {
+		source = "cache"
+	}
+	m.tiles.WithLabelValues(source).Inc()
+}
+
+func (m *serverMetrics) RenderStarted() { m.inFlight.Inc() }
+
+func (m *serverMetrics) RenderDone(pixels int, elapsed, busy time.Duration, procs int, err error) {
+	m.inFlight.Dec()
+	outcome := "ok"
+	switch {
+	case errors.Is(err, render.ErrCancelled):
+		outcome = "cancelled"
+	case err != nil:
+		outcome = "error"
+	}
+	m.renders.WithLabelValues(outcome).Inc()
+	m.pixels.Add(float64(pixels))
+	m.busy.Add(busy.Seconds())
+	m.capacity.Add(elapsed.Seconds() * float64(procs))
+}
+
+// httpOutcome buckets a response status for the outcome label.
+func httpOutcome(status int) string {
+	switch {
+	case status == 499:
+		return "cancelled"
+	case status >= 500:
+		return "error"
+	case status >= 400:
+		return "client_error"
+	default:
+		return "ok"
+	}
+}
+
+// endpointLabel names the route that served r, keeping label values
+// bounded to the registered patterns.
+func endpointLabel(r *http.Request) string {
+	if r.Pattern == "" {
+		return "unmatched"
+	}
+	return r.Pattern
+}
+
+var _ render.Metrics = (*serverMetrics)(nil)
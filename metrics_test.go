@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// scrape fetches url's /metrics and returns each sample by its name and
+// labels as written, e.g. `mandelbrot_renders_total{outcome="ok"}`.
+func scrape(t *testing.T, url string) map[string]float64 {
+	t.Helper()
+	resp, err := http.Get(url + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	samples := map[string]float64{}
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+		samples[line[:i]] = v
+	}
+	return samples
+}
+
+func TestMetricsCountRequests(t *testing.T) {
+	s := testServer()
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+
+	get := func(path string, want int) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("GET %s: %s, want %d", path, resp.Status, want)
+		}
+	}
+	before := scrape(t, srv.URL)
+	for range 3 {
+		get("/render?w=32&h=24&iters=100", http.StatusOK)
+	}
+	get("/render?w=-1", http.StatusBadRequest)
+	get("/healthz", http.StatusOK)
+	after := scrape(t, srv.URL)
+
+	for name, want := range map[string]float64{
+		`mandelbrot_requests_total{endpoint="GET /render",outcome="ok"}`:                 3,
+		`mandelbrot_requests_total{endpoint="GET /render",outcome="client_error"}`:       1,
+		`mandelbrot_requests_total{endpoint="GET /healthz",outcome="ok"}`:                1,
+		`mandelbrot_request_duration_seconds_count{endpoint="GET /render",outcome="ok"}`: 3,
+		`mandelbrot_renders_total{outcome="ok"}`:                                         3,
+		`mandelbrot_pixels_computed_total`:                                               3 * 32 * 24,
+	} {
+		if d := after[name] - before[name]; d != want {
+			t.Errorf("%s went up by %v, want %v", name, d, want)
+		}
+	}
+	if v := after["mandelbrot_renders_in_flight"]; v != 0 {
+		t.Errorf("%v renders in flight", v)
+	}
+	if after["mandelbrot_worker_busy_seconds_total"] <= before["mandelbrot_worker_busy_seconds_total"] {
+		t.Error("worker busy time didn't move")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"image"
+	"image/draw"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// renderMirrored renders opts for -mirror-x and -mirror-y: only the half
+// or quarter of the image that is kept, the bottom half for x, the right
+// half for y and the bottom right quarter for both, which is then
+// mirrored over the rest.
+func renderMirrored(ctx context.Context, opts render.Options, x, y bool) (*image.RGBA, error) {
+	full := image.Rect(0, 0, opts.Width, opts.Height)
+	part := full
+	if x {
+		part.Min.Y = opts.Height / 2
+	}
+	if y {
+		part.Min.X = opts.Width / 2
+	}
+	src, err := render.RenderRect(ctx, opts, part)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewRGBA(full)
+	draw.Draw(img, part, src, part.Min, draw.Src)
+	switch {
+	case x && y:
+		mirrorXY(img)
+	case x:
+		mirrorX(img)
+	case y:
+		mirrorY(img)
+	}
+	return img, nil
+}
+
+// mirrorX copies the bottom half of img over the top half, upside down,
+// so that row y matches row height-1-y. The middle row of an odd height
+// is its own mirror image.
+func mirrorX(img *image.RGBA) {
+	b := img.Rect
+	rowLen := 4 * b.Dx()
+	for y := range b.Dy() / 2 {
+		dst := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):]
+		src := img.Pix[img.PixOffset(b.Min.X, b.Max.Y-1-y):]
+		copy(dst[:rowLen], src[:rowLen])
+	}
+}
+
+// mirrorY copies the right half of img over the left half, reversed, so
+// that column x matches column width-1-x.
+func mirrorY(img *image.RGBA) {
+	b := img.Rect
+	w := b.Dx()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(b.Min.X, y):]
+		for x := range w / 2 {
+			copy(row[4*x:4*x+4], row[4*(w-1-x):4*(w-1-x)+4])
+		}
+	}
+}
+
+// mirrorXY copies the bottom right quarter of img over the other three,
+// so that the image is symmetric both ways.
+func mirrorXY(img *image.RGBA) {
+	mirrorY(img)
+	mirrorX(img)
+}
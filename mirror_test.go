@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// noiseRGBA returns a w×h image of random pixels, so that no part of it
+// is symmetric by accident.
+func noiseRGBA(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	r := rand.New(rand.NewPCG(uint64(w), uint64(h)))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(r.Uint32())
+	}
+	return img
+}
+
+func TestMirror(t *testing.T) {
+	for _, size := range []image.Point{{6, 4}, {7, 5}, {1, 1}, {2, 3}} {
+		w, h := size.X, size.Y
+		orig := noiseRGBA(w, h)
+
+		img := image.NewRGBA(orig.Rect)
+		copy(img.Pix, orig.Pix)
+		mirrorX(img)
+		for y := range h {
+			for x := range w {
+				if img.At(x, y) != img.At(x, h-1-y) {
+					t.Errorf("%dx%d mirrorX: (%d,%d) %v != (%d,%d) %v", w, h, x, y, img.At(x, y), x, h-1-y, img.At(x, h-1-y))
+				}
+				if y >= h/2 && img.At(x, y) != orig.At(x, y) {
+					t.Errorf("%dx%d mirrorX changed kept pixel (%d,%d)", w, h, x, y)
+				}
+			}
+		}
+
+		copy(img.Pix, orig.Pix)
+		mirrorY(img)
+		for y := range h {
+			for x := range w {
+				if img.At(x, y) != img.At(w-1-x, y) {
+					t.Errorf("%dx%d mirrorY: (%d,%d) %v != (%d,%d) %v", w, h, x, y, img.At(x, y), w-1-x, y, img.At(w-1-x, y))
+				}
+				if x >= w/2 && img.At(x, y) != orig.At(x, y) {
+					t.Errorf("%dx%d mirrorY changed kept pixel (%d,%d)", w, h, x, y)
+				}
+			}
+		}
+
+		copy(img.Pix, orig.Pix)
+		mirrorXY(img)
+		for y := range h {
+			for x := range w {
+				want := orig.At(max(x, w-1-x), max(y, h-1-y))
+				if img.At(x, y) != want {
+					t.Errorf("%dx%d mirrorXY: (%d,%d) = %v, want %v", w, h, x, y, img.At(x, y), want)
+				}
+			}
+		}
+	}
+}
+
+func TestMirrorSubImage(t *testing.T) {
+	// A sub-image shares Pix with its parent and starts away from the
+	// origin; mirroring must stay inside its rectangle.
+	parent := noiseRGBA(10, 10)
+	orig := bytes.Clone(parent.Pix)
+	sub := parent.SubImage(image.Rect(2, 3, 7, 9)).(*image.RGBA)
+	mirrorXY(sub)
+	for y := 3; y < 9; y++ {
+		for x := 2; x < 7; x++ {
+			if sub.At(x, y) != sub.At(2+6-x, 3+8-y) {
+				t.Errorf("(%d,%d) %v != (%d,%d) %v", x, y, sub.At(x, y), 2+6-x, 3+8-y, sub.At(2+6-x, 3+8-y))
+			}
+		}
+	}
+	for y := range 10 {
+		for x := range 10 {
+			if (image.Point{x, y}.In(sub.Rect)) {
+				continue
+			}
+			i := parent.PixOffset(x, y)
+			if !bytes.Equal(parent.Pix[i:i+4], orig[i:i+4]) {
+				t.Errorf("pixel (%d,%d) outside the sub-image changed", x, y)
+			}
+		}
+	}
+}
+
+func TestRenderMirrored(t *testing.T) {
+	// An off-center Julia set, symmetric neither way: the kept part must
+	// match the full render and the rest its mirror image.
+	for _, size := range []image.Point{{40, 30}, {41, 31}} {
+		opts, err := render.New(render.WithSize(size.X, size.Y), render.WithIterations(100),
+			render.WithFractalName("julia", complex(-0.8, 0.156)),
+			render.WithViewport(coords.Bounds{Xmin: -1.2, Xmax: 0.5, Ymin: -0.3, Ymax: 0.9}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		full, err := render.Render(context.Background(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, h := size.X, size.Y
+		for _, flags := range [][2]bool{{true, false}, {false, true}, {true, true}} {
+			mx, my := flags[0], flags[1]
+			img, err := renderMirrored(context.Background(), opts, mx, my)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for y := range h {
+				for x := range w {
+					sx, sy := x, y
+					if mx {
+						sy = max(y, h-1-y)
+					}
+					if my {
+						sx = max(x, w-1-x)
+					}
+					if got, want := img.At(x, y), full.Image.At(sx, sy); got != want {
+						t.Fatalf("%dx%d x=%v y=%v: (%d,%d) = %v, want %v from (%d,%d)", w, h, mx, my, x, y, got, want, sx, sy)
+					}
+				}
+			}
+		}
+	}
+}
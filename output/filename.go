@@ -0,0 +1,110 @@
+// Package output names and writes the files renders go to.
+package output
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// ExpandFilenameTemplate replaces the verbs in tmpl with values from
+// opts, so a batch of renders can be named after their settings:
+//
+//	%p  palette name
+//	%w  width in pixels
+//	%h  height in pixels
+//	%x  real part of the view's center
+//	%y  imaginary part of the view's center
+//	%i  iteration limit
+//	%f  fractal formula
+//	%%  a literal %
+//
+// Any other verb, and a % at the end, is copied through unchanged. That
+// includes %n, which ExpandNumberedTemplate fills in.
+// Coordinates are rounded to a tenth of a pixel, enough to tell
+// neighbouring renders apart without float noise. Palette and
+// formula names are made safe for a path: spaces, slashes and other
+// characters outside letters, digits and "-_." become underscores. A
+// palette or formula without a name expands to "custom".
+func ExpandFilenameTemplate(tmpl string, opts render.Options) string {
+	return expand(tmpl, opts, -1)
+}
+
+// ExpandNumberedTemplate is ExpandFilenameTemplate for one of a sequence
+// of renders, with %n expanding to n as well.
+func ExpandNumberedTemplate(tmpl string, opts render.Options, n int) string {
+	return expand(tmpl, opts, n)
+}
+
+// expand implements the templates; n < 0 leaves %n alone.
+func expand(tmpl string, opts render.Options, n int) string {
+	if !strings.Contains(tmpl, "%") {
+		return tmpl
+	}
+	decimals := max(1, int(math.Ceil(-math.Log10(opts.Bounds.Width()/float64(opts.Width)/10))))
+	f := func(v float64) string {
+		s := strconv.FormatFloat(v, 'f', decimals, 64)
+		return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	}
+	var b strings.Builder
+	for i := 0; i < len(tmpl); i++ {
+		if tmpl[i] != '%' || i+1 == len(tmpl) {
+			b.WriteByte(tmpl[i])
+			continue
+		}
+		i++
+		switch tmpl[i] {
+		case 'p':
+			name := ""
+			if opts.Palette != nil {
+				name = opts.Palette.Keyword
+			}
+			b.WriteString(sanitize(name))
+		case 'w':
+			b.WriteString(strconv.Itoa(opts.Width))
+		case 'h':
+			b.WriteString(strconv.Itoa(opts.Height))
+		case 'x':
+			b.WriteString(f(real(opts.Bounds.Center())))
+		case 'y':
+			b.WriteString(f(imag(opts.Bounds.Center())))
+		case 'i':
+			b.WriteString(strconv.Itoa(opts.MaxIter))
+		case 'f':
+			fr := opts.Fractal
+			if fr == nil {
+				fr = fractal.Mandelbrot{}
+			}
+			b.WriteString(sanitize(fractal.NameOf(fr)))
+		case 'n':
+			if n < 0 {
+				b.WriteString("%n")
+				break
+			}
+			b.WriteString(strconv.Itoa(n))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(tmpl[i])
+		}
+	}
+	return b.String()
+}
+
+// sanitize makes a name safe to use as part of a file name.
+func sanitize(name string) string {
+	if name == "" {
+		return "custom"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+}
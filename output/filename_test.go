@@ -0,0 +1,76 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/fractal"
+	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// templateOptions are the options the template tests expand against: a
+// 1600×1200 ThermalHeat render of a 0.016-wide view, 1e-5 per pixel.
+func templateOptions(t *testing.T, opts ...render.Option) render.Options {
+	t.Helper()
+	o, err := render.New(append([]render.Option{
+		render.WithSize(1600, 1200),
+		render.WithViewport(coords.Bounds{Xmin: -0.758, Xmax: -0.742, Ymin: 0.094, Ymax: 0.106}),
+		render.WithIterations(2500),
+		render.WithPaletteName("ThermalHeat"),
+	}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func TestExpandFilenameTemplate(t *testing.T) {
+	o := templateOptions(t)
+	for _, tc := range []struct{ tmpl, want string }{
+		{"render_%p_%wx%h.png", "render_ThermalHeat_1600x1200.png"},
+		{"%p", "ThermalHeat"},
+		{"%w", "1600"},
+		{"%h", "1200"},
+		// a tenth of a pixel is 1e-6
+		{"%x", "-0.75"},
+		{"%y", "0.1"},
+		{"%i", "2500"},
+		{"%f", "mandelbrot"},
+		{"100%%.png", "100%.png"},
+		{"plain.png", "plain.png"},
+		// unknown verbs, %n and a trailing % are kept
+		{"a%qb%Z.png", "a%qb%Z.png"},
+		{"frame_%n.png", "frame_%n.png"},
+		{"end%", "end%"},
+	} {
+		if got := ExpandFilenameTemplate(tc.tmpl, o); got != tc.want {
+			t.Errorf("%q expands to %q, want %q", tc.tmpl, got, tc.want)
+		}
+	}
+	if got := ExpandNumberedTemplate("frame_%n_%w.png", o, 7); got != "frame_7_1600.png" {
+		t.Errorf("numbered: %q", got)
+	}
+}
+
+func TestExpandFilenameTemplateNames(t *testing.T) {
+	// a palette name with spaces and a slash
+	cm := &palette.ColorMap{Keyword: "Deep Sea/2 ü", Colors: palette.Get("ThermalHeat").Colors}
+	o := templateOptions(t, render.WithPalette(cm))
+	if got, want := ExpandFilenameTemplate("%p.png", o), "Deep_Sea_2__.png"; got != want {
+		t.Errorf("%%p.png expands to %q, want %q", got, want)
+	}
+	o.Palette = &palette.ColorMap{Colors: cm.Colors}
+	if got := ExpandFilenameTemplate("%p", o); got != "custom" {
+		t.Errorf("an unnamed palette expands to %q", got)
+	}
+	o = templateOptions(t, render.WithFractal(fractal.ByName("julia", complex(-0.8, 0.156))))
+	if got := ExpandFilenameTemplate("%f", o); got != "julia" {
+		t.Errorf("%%f expands to %q for a Julia set", got)
+	}
+	// coordinates are rounded to the view's scale, however deep
+	o = templateOptions(t, render.WithViewport(coords.Bounds{Xmin: 0.25, Xmax: 0.25 + 1.6e-9, Ymin: -0.6e-9, Ymax: 0.6e-9}))
+	if got, want := ExpandFilenameTemplate("%x_%y", o), "0.2500000008_0"; got != want {
+		t.Errorf("deep view: %q, want %q", got, want)
+	}
+}
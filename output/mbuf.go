@@ -0,0 +1,110 @@
+package output
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// An .mbuf file holds the escape counts of a render whose image couldn't
+// be written, so the computation isn't lost with it. All numbers are
+// little-endian:
+//
+//	"MBUF", uint32 version 1
+//	uint32 width, height and iteration limit
+//	uint32 length and the bytes of the reproduce command
+//	width*height float64 escape counts, row-major (Result.Iters)
+//	width*height bytes, 1 for interior pixels (Result.Inside)
+const (
+	mbufMagic   = "MBUF"
+	mbufVersion = 1
+)
+
+// Mbuf is the content of an .mbuf file.
+type Mbuf struct {
+	Width, Height, MaxIter int
+	Command                string // render.BuildReproduceCommand of the render
+	Iters                  []float64
+	Inside                 []bool
+}
+
+// SalvagePath returns where Salvage puts the buffers of a render meant
+// for path: the same name with the extension .mbuf.
+func SalvagePath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".mbuf"
+}
+
+// Salvage writes the escape counts of res to SalvagePath(path) with
+// WriteFile and returns the path written.
+func Salvage(path string, res *render.Result) (string, error) {
+	mpath := SalvagePath(path)
+	return mpath, WriteFile(mpath, func(w io.Writer) error { return WriteMbuf(w, res) })
+}
+
+// WriteMbuf writes the escape counts of res in the .mbuf format. It
+// needs the buffers that Options.DiscardBuffers drops.
+func WriteMbuf(w io.Writer, res *render.Result) error {
+	o := res.Options
+	if res.Iters == nil || len(res.Iters) != o.Width*o.Height {
+		return errors.New("mbuf: the render kept no iteration buffer")
+	}
+	cmd := render.BuildReproduceCommand(o)
+	bw := bufio.NewWriter(w)
+	bw.WriteString(mbufMagic)
+	for _, v := range []uint32{mbufVersion, uint32(o.Width), uint32(o.Height), uint32(o.MaxIter), uint32(len(cmd))} {
+		binary.Write(bw, binary.LittleEndian, v)
+	}
+	bw.WriteString(cmd)
+	binary.Write(bw, binary.LittleEndian, res.Iters)
+	binary.Write(bw, binary.LittleEndian, res.Inside)
+	return bw.Flush()
+}
+
+// ReadMbuf reads an .mbuf file written by WriteMbuf.
+func ReadMbuf(r io.Reader) (*Mbuf, error) {
+	br := bufio.NewReader(r)
+	var hdr struct {
+		Magic                           [4]byte
+		Version, Width, Height, MaxIter uint32
+		CommandLen                      uint32
+	}
+	if err := binary.Read(br, binary.LittleEndian, &hdr); err != nil {
+		return nil, fmt.Errorf("mbuf: %w", err)
+	}
+	if string(hdr.Magic[:]) != mbufMagic {
+		return nil, errors.New("mbuf: not an .mbuf file")
+	}
+	if hdr.Version != mbufVersion {
+		return nil, fmt.Errorf("mbuf: version %d: only %d is known", hdr.Version, mbufVersion)
+	}
+	const maxPixels = 1 << 30
+	n := uint64(hdr.Width) * uint64(hdr.Height)
+	if n > maxPixels || hdr.CommandLen > 1<<20 {
+		return nil, fmt.Errorf("mbuf: %dx%d image with a %d byte command: too large", hdr.Width, hdr.Height, hdr.CommandLen)
+	}
+	m := &Mbuf{
+		Width:   int(hdr.Width),
+		Height:  int(hdr.Height),
+		MaxIter: int(hdr.MaxIter),
+		Iters:   make([]float64, n),
+		Inside:  make([]bool, n),
+	}
+	cmd := make([]byte, hdr.CommandLen)
+	if _, err := io.ReadFull(br, cmd); err != nil {
+		return nil, fmt.Errorf("mbuf: %w", err)
+	}
+	m.Command = string(cmd)
+	if err := binary.Read(br, binary.LittleEndian, m.Iters); err != nil {
+		return nil, fmt.Errorf("mbuf: %w", err)
+	}
+	if err := binary.Read(br, binary.LittleEndian, m.Inside); err != nil {
+		return nil, fmt.Errorf("mbuf: %w", err)
+	}
+	return m, nil
+}
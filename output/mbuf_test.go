@@ -0,0 +1,98 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// testResult renders a small view keeping its buffers.
+func testResult(t *testing.T, opts ...render.Option) *render.Result {
+	t.Helper()
+	o, err := render.New(append([]render.Option{render.WithSize(40, 30), render.WithIterations(200)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestMbufRoundTrip(t *testing.T) {
+	res := testResult(t, render.WithPaletteName("ThermalHeat"))
+	var buf bytes.Buffer
+	if err := WriteMbuf(&buf, res); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadMbuf(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Width != 40 || m.Height != 30 || m.MaxIter != 200 || m.Command != render.BuildReproduceCommand(res.Options) {
+		t.Errorf("header %dx%d, %d iterations, command %q", m.Width, m.Height, m.MaxIter, m.Command)
+	}
+	if !slices.Equal(m.Iters, res.Iters) || !slices.Equal(m.Inside, res.Inside) {
+		t.Error("the buffers changed")
+	}
+}
+
+func TestSalvage(t *testing.T) {
+	if got := SalvagePath("/renders/deep zoom.png"); got != "/renders/deep zoom.mbuf" {
+		t.Errorf("SalvagePath = %q", got)
+	}
+	dir := t.TempDir()
+	res := testResult(t)
+	mpath, err := Salvage(filepath.Join(dir, "out.png"), res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(mpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if m, err := ReadMbuf(f); err != nil || !slices.Equal(m.Iters, res.Iters) {
+		t.Errorf("read back the salvage: %v", err)
+	}
+
+	// a render that dropped its buffers has nothing to salvage, and leaves
+	// no file behind
+	mpath, err = Salvage(filepath.Join(dir, "discarded.png"), testResult(t, render.WithDiscardBuffers(true)))
+	if err == nil || !strings.Contains(err.Error(), "no iteration buffer") {
+		t.Errorf("discarded buffers: %v", err)
+	}
+	if _, err := os.Stat(mpath); !os.IsNotExist(err) {
+		t.Errorf("%s exists: %v", mpath, err)
+	}
+}
+
+func TestReadMbufErrors(t *testing.T) {
+	var good bytes.Buffer
+	if err := WriteMbuf(&good, testResult(t)); err != nil {
+		t.Fatal(err)
+	}
+	b := good.Bytes()
+	version := slices.Clone(b)
+	version[4] = 9
+	huge := slices.Clone(b)
+	copy(huge[8:], []byte{0xff, 0xff, 0xff, 0})
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("PNG!"), b[4:]...),
+		"version":   version,
+		"too large": huge,
+		"truncated": b[:len(b)-10],
+	} {
+		if _, err := ReadMbuf(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrOutput marks a failure to write out a render that itself succeeded,
+// for use with errors.Is.
+var ErrOutput = errors.New("output failed")
+
+// WriteError reports which stage of WriteFile failed on which file. It
+// matches ErrOutput and unwraps to the cause.
+type WriteError struct {
+	Stage string // "create", "chmod", "write", "sync", "close" or "rename"
+	Path  string
+	Err   error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Stage, e.Path, e.Err)
+}
+
+func (e *WriteError) Unwrap() []error { return []error{ErrOutput, e.Err} }
+
+// WriteFile writes path with write, so that path either keeps what it
+// held before or holds everything write wrote: the data goes to a hidden
+// temporary file in the same directory, which is synced to disk, closed
+// and renamed over path. The directory is synced as well where the
+// system allows it, so the rename survives a crash; that sync failing
+// isn't reported, as the file is in place by then. On any failure the
+// temporary file is removed and the error is a *WriteError.
+func WriteFile(path string, write func(io.Writer) error) error {
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return &WriteError{"create", path, err}
+	}
+	// CreateTemp makes the file private; images are meant to be shared
+	stage, err := "chmod", f.Chmod(0o644)
+	if err == nil {
+		stage, err = "write", write(f)
+	}
+	if err == nil {
+		stage, err = "sync", f.Sync()
+	}
+	if cerr := f.Close(); err == nil && cerr != nil {
+		stage, err = "close", cerr
+	}
+	if err == nil {
+		stage, err = "rename", os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return &WriteError{stage, path, err}
+	}
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package output
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// failingWriter passes n bytes through to w, then fails.
+type failingWriter struct {
+	w io.Writer
+	n int
+}
+
+var errDiskFull = errors.New("no space left on device")
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > f.n {
+		m, _ := f.w.Write(p[:f.n])
+		f.n = 0
+		return m, errDiskFull
+	}
+	f.n -= len(p)
+	return f.w.Write(p)
+}
+
+// leftovers returns the names in dir other than keep.
+func leftovers(t *testing.T, dir string, keep ...string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		kept := false
+		for _, k := range keep {
+			kept = kept || name == k
+		}
+		if !kept {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.png")
+	if err := WriteFile(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new image")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(path); err != nil || string(b) != "new image" {
+		t.Fatalf("read %q, %v", b, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o644 {
+		t.Errorf("mode %v, %v", info.Mode(), err)
+	}
+	if extra := leftovers(t, dir, "out.png"); len(extra) != 0 {
+		t.Errorf("left behind %v", extra)
+	}
+}
+
+func TestWriteFileFailingWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.png")
+	if err := os.WriteFile(path, []byte("old image"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// the encoder gets part of the way, then the disk fills up
+	err := WriteFile(path, func(w io.Writer) error {
+		fw := &failingWriter{w: w, n: 5}
+		for range 10 {
+			if _, err := io.WriteString(fw, "chunk"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	var werr *WriteError
+	if !errors.As(err, &werr) || werr.Stage != "write" || werr.Path != path {
+		t.Fatalf("got %v, want a write stage *WriteError", err)
+	}
+	if !errors.Is(err, ErrOutput) || !errors.Is(err, errDiskFull) {
+		t.Errorf("%v doesn't match both ErrOutput and its cause", err)
+	}
+	// the old file is untouched and the temporary file is gone
+	if b, err := os.ReadFile(path); err != nil || string(b) != "old image" {
+		t.Errorf("read %q, %v", b, err)
+	}
+	if extra := leftovers(t, dir, "out.png"); len(extra) != 0 {
+		t.Errorf("left behind %v", extra)
+	}
+}
+
+func TestWriteFileStages(t *testing.T) {
+	dir := t.TempDir()
+	write := func(w io.Writer) error { _, err := io.WriteString(w, "x"); return err }
+
+	err := WriteFile(filepath.Join(dir, "missing", "out.png"), write)
+	if werr := (*WriteError)(nil); !errors.As(err, &werr) || werr.Stage != "create" {
+		t.Errorf("in a missing directory: %v", err)
+	}
+
+	// a directory in the way fails the rename, after the data is written
+	busy := filepath.Join(dir, "busy.png")
+	if err := os.Mkdir(busy, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(busy, "keep"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err = WriteFile(busy, write)
+	if werr := (*WriteError)(nil); !errors.As(err, &werr) || werr.Stage != "rename" || !errors.Is(err, ErrOutput) {
+		t.Errorf("over a directory: %v", err)
+	}
+	if extra := leftovers(t, dir, "busy.png"); len(extra) != 0 {
+		t.Errorf("left behind %v", extra)
+	}
+}
@@ -0,0 +1,167 @@
+package overlay
+
+import (
+	"image"
+	"image/color"
+)
+
+// glyphW and glyphH are the size of a glyph in font pixels. Glyphs are
+// set one font pixel apart.
+const glyphW, glyphH = 5, 7
+
+// glyphs is a small bitmap font covering printable ASCII, one string per
+// row with '#' for a set pixel.
+var glyphs = map[rune][glyphH]string{
+	' ':  {},
+	'!':  {"  #  ", "  #  ", "  #  ", "  #  ", "  #  ", "     ", "  #  "},
+	'"':  {" # # ", " # # ", "     ", "     ", "     ", "     ", "     "},
+	'#':  {" # # ", " # # ", "#####", " # # ", "#####", " # # ", " # # "},
+	'$':  {"  #  ", " ####", "# #  ", " ### ", "  # #", "#### ", "  #  "},
+	'%':  {"##   ", "##  #", "   # ", "  #  ", " #   ", "#  ##", "   ##"},
+	'&':  {" ##  ", "#  # ", "# #  ", " #   ", "# # #", "#  # ", " ## #"},
+	'\'': {"  #  ", "  #  ", "  #  ", "     ", "     ", "     ", "     "},
+	'(':  {"   # ", "  #  ", " #   ", " #   ", " #   ", "  #  ", "   # "},
+	')':  {" #   ", "  #  ", "   # ", "   # ", "   # ", "  #  ", " #   "},
+	'*':  {"     ", "  #  ", "# # #", " ### ", "# # #", "  #  ", "     "},
+	'+':  {"     ", "  #  ", "  #  ", "#####", "  #  ", "  #  ", "     "},
+	',':  {"     ", "     ", "     ", "     ", " ##  ", "  #  ", " #   "},
+	'-':  {"     ", "     ", "     ", "#####", "     ", "     ", "     "},
+	'.':  {"     ", "     ", "     ", "     ", "     ", " ##  ", " ##  "},
+	'/':  {"     ", "    #", "   # ", "  #  ", " #   ", "#    ", "     "},
+	'0':  {" ### ", "#   #", "#  ##", "# # #", "##  #", "#   #", " ### "},
+	'1':  {"  #  ", " ##  ", "  #  ", "  #  ", "  #  ", "  #  ", " ### "},
+	'2':  {" ### ", "#   #", "    #", "   # ", "  #  ", " #   ", "#####"},
+	'3':  {"#####", "   # ", "  #  ", "   # ", "    #", "#   #", " ### "},
+	'4':  {"   # ", "  ## ", " # # ", "#  # ", "#####", "   # ", "   # "},
+	'5':  {"#####", "#    ", "#### ", "    #", "    #", "#   #", " ### "},
+	'6':  {"  ## ", " #   ", "#    ", "#### ", "#   #", "#   #", " ### "},
+	'7':  {"#####", "    #", "   # ", "  #  ", " #   ", " #   ", " #   "},
+	'8':  {" ### ", "#   #", "#   #", " ### ", "#   #", "#   #", " ### "},
+	'9':  {" ### ", "#   #", "#   #", " ####", "    #", "   # ", " ##  "},
+	':':  {"     ", " ##  ", " ##  ", "     ", " ##  ", " ##  ", "     "},
+	';':  {"     ", " ##  ", " ##  ", "     ", " ##  ", "  #  ", " #   "},
+	'<':  {"   # ", "  #  ", " #   ", "#    ", " #   ", "  #  ", "   # "},
+	'=':  {"     ", "     ", "#####", "     ", "#####", "     ", "     "},
+	'>':  {" #   ", "  #  ", "   # ", "    #", "   # ", "  #  ", " #   "},
+	'?':  {" ### ", "#   #", "    #", "   # ", "  #  ", "     ", "  #  "},
+	'@':  {" ### ", "#   #", "    #", " ## #", "# # #", "# # #", " ### "},
+	'A':  {" ### ", "#   #", "#   #", "#####", "#   #", "#   #", "#   #"},
+	'B':  {"#### ", "#   #", "#   #", "#### ", "#   #", "#   #", "#### "},
+	'C':  {" ### ", "#   #", "#    ", "#    ", "#    ", "#   #", " ### "},
+	'D':  {"###  ", "#  # ", "#   #", "#   #", "#   #", "#  # ", "###  "},
+	'E':  {"#####", "#    ", "#    ", "#### ", "#    ", "#    ", "#####"},
+	'F':  {"#####", "#    ", "#    ", "#### ", "#    ", "#    ", "#    "},
+	'G':  {" ### ", "#   #", "#    ", "# ###", "#   #", "#   #", " ####"},
+	'H':  {"#   #", "#   #", "#   #", "#####", "#   #", "#   #", "#   #"},
+	'I':  {" ### ", "  #  ", "  #  ", "  #  ", "  #  ", "  #  ", " ### "},
+	'J':  {"  ###", "   # ", "   # ", "   # ", "   # ", "#  # ", " ##  "},
+	'K':  {"#   #", "#  # ", "# #  ", "##   ", "# #  ", "#  # ", "#   #"},
+	'L':  {"#    ", "#    ", "#    ", "#    ", "#    ", "#    ", "#####"},
+	'M':  {"#   #", "## ##", "# # #", "# # #", "#   #", "#   #", "#   #"},
+	'N':  {"#   #", "#   #", "##  #", "# # #", "#  ##", "#   #", "#   #"},
+	'O':  {" ### ", "#   #", "#   #", "#   #", "#   #", "#   #", " ### "},
+	'P':  {"#### ", "#   #", "#   #", "#### ", "#    ", "#    ", "#    "},
+	'Q':  {" ### ", "#   #", "#   #", "#   #", "# # #", "#  # ", " ## #"},
+	'R':  {"#### ", "#   #", "#   #", "#### ", "# #  ", "#  # ", "#   #"},
+	'S':  {" ####", "#    ", "#    ", " ### ", "    #", "    #", "#### "},
+	'T':  {"#####", "  #  ", "  #  ", "  #  ", "  #  ", "  #  ", "  #  "},
+	'U':  {"#   #", "#   #", "#   #", "#   #", "#   #", "#   #", " ### "},
+	'V':  {"#   #", "#   #", "#   #", "#   #", "#   #", " # # ", "  #  "},
+	'W':  {"#   #", "#   #", "#   #", "# # #", "# # #", "# # #", " # # "},
+	'X':  {"#   #", "#   #", " # # ", "  #  ", " # # ", "#   #", "#   #"},
+	'Y':  {"#   #", "#   #", "#   #", " # # ", "  #  ", "  #  ", "  #  "},
+	'Z':  {"#####", "    #", "   # ", "  #  ", " #   ", "#    ", "#####"},
+	'[':  {" ### ", " #   ", " #   ", " #   ", " #   ", " #   ", " ### "},
+	'\\': {"     ", "#    ", " #   ", "  #  ", "   # ", "    #", "     "},
+	']':  {" ### ", "   # ", "   # ", "   # ", "   # ", "   # ", " ### "},
+	'^':  {"  #  ", " # # ", "#   #", "     ", "     ", "     ", "     "},
+	'_':  {"     ", "     ", "     ", "     ", "     ", "     ", "#####"},
+	'`':  {" #   ", "  #  ", "   # ", "     ", "     ", "     ", "     "},
+	'a':  {"     ", "     ", " ### ", "    #", " ####", "#   #", " ####"},
+	'b':  {"#    ", "#    ", "# ## ", "##  #", "#   #", "#   #", "#### "},
+	'c':  {"     ", "     ", " ### ", "#    ", "#    ", "#   #", " ### "},
+	'd':  {"    #", "    #", " ## #", "#  ##", "#   #", "#   #", " ####"},
+	'e':  {"     ", "     ", " ### ", "#   #", "#####", "#    ", " ### "},
+	'f':  {"  ## ", " #  #", " #   ", "###  ", " #   ", " #   ", " #   "},
+	'g':  {"     ", " ####", "#   #", "#   #", " ####", "    #", " ### "},
+	'h':  {"#    ", "#    ", "# ## ", "##  #", "#   #", "#   #", "#   #"},
+	'i':  {"  #  ", "     ", " ##  ", "  #  ", "  #  ", "  #  ", " ### "},
+	'j':  {"   # ", "     ", "  ## ", "   # ", "   # ", "#  # ", " ##  "},
+	'k':  {"#    ", "#    ", "#  # ", "# #  ", "##   ", "# #  ", "#  # "},
+	'l':  {" ##  ", "  #  ", "  #  ", "  #  ", "  #  ", "  #  ", " ### "},
+	'm':  {"     ", "     ", "## # ", "# # #", "# # #", "#   #", "#   #"},
+	'n':  {"     ", "     ", "# ## ", "##  #", "#   #", "#   #", "#   #"},
+	'o':  {"     ", "     ", " ### ", "#   #", "#   #", "#   #", " ### "},
+	'p':  {"     ", "     ", "#### ", "#   #", "#### ", "#    ", "#    "},
+	'q':  {"     ", "     ", " ## #", "#  ##", " ####", "    #", "    #"},
+	'r':  {"     ", "     ", "# ## ", "##  #", "#    ", "#    ", "#    "},
+	's':  {"     ", "     ", " ### ", "#    ", " ### ", "    #", "#### "},
+	't':  {" #   ", " #   ", "###  ", " #   ", " #   ", " #  #", "  ## "},
+	'u':  {"     ", "     ", "#   #", "#   #", "#   #", "#  ##", " ## #"},
+	'v':  {"     ", "     ", "#   #", "#   #", "#   #", " # # ", "  #  "},
+	'w':  {"     ", "     ", "#   #", "#   #", "# # #", "# # #", " # # "},
+	'x':  {"     ", "     ", "#   #", " # # ", "  #  ", " # # ", "#   #"},
+	'y':  {"     ", "     ", "#   #", " # # ", "  #  ", "  #  ", " #   "},
+	'z':  {"     ", "     ", "#####", "   # ", "  #  ", " #   ", "#####"},
+	'{':  {"   # ", "  #  ", "  #  ", " #   ", "  #  ", "  #  ", "   # "},
+	'|':  {"  #  ", "  #  ", "  #  ", "  #  ", "  #  ", "  #  ", "  #  "},
+	'}':  {" #   ", "  #  ", "  #  ", "   # ", "  #  ", "  #  ", " #   "},
+	'~':  {"     ", "     ", " #   ", "# # #", "   # ", "     ", "     "},
+}
+
+// textSize returns the size in image pixels of s drawn at scale.
+func textSize(s string, scale int) image.Point {
+	n := len([]rune(s))
+	if n == 0 {
+		return image.Point{}
+	}
+	return image.Pt((n*(glyphW+1)-1)*scale, glyphH*scale)
+}
+
+// drawText draws s with its top left corner at p, each font pixel a
+// scale×scale square, and a one-pixel halo in halo around the glyphs so
+// labels stay legible over any part of the image; a transparent halo is
+// left out. Characters the font lacks are left blank.
+func drawText(img *image.RGBA, p image.Point, s string, scale int, c, halo color.NRGBA) {
+	set := func(draw func(x, y int)) {
+		x0 := p.X
+		for _, r := range s {
+			g := glyphs[r]
+			for gy, row := range g {
+				for gx := range len(row) {
+					if row[gx] != '#' {
+						continue
+					}
+					for sy := range scale {
+						for sx := range scale {
+							draw(x0+gx*scale+sx, p.Y+gy*scale+sy)
+						}
+					}
+				}
+			}
+			x0 += (glyphW + 1) * scale
+		}
+	}
+	// the halo pixels are collected first so overlapping ones are blended
+	// once and the glyphs themselves are never under it
+	haloPx := make(map[image.Point]bool)
+	glyphPx := make(map[image.Point]bool)
+	set(func(x, y int) {
+		glyphPx[image.Pt(x, y)] = true
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				haloPx[image.Pt(x+dx, y+dy)] = true
+			}
+		}
+	})
+	if halo.A != 0 {
+		for q := range haloPx {
+			if !glyphPx[q] {
+				blend(img, q.X, q.Y, halo)
+			}
+		}
+	}
+	for q := range glyphPx {
+		blend(img, q.X, q.Y, c)
+	}
+}
@@ -0,0 +1,188 @@
+// Package overlay draws annotations over rendered images, and charts
+// about them.
+package overlay
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+// Grid configures DrawGrid.
+type Grid struct {
+	// Color is the color of the ticks, lines and labels; its alpha sets
+	// their opacity.
+	Color color.NRGBA
+	// Lines draws a gridline across the image at every tick, rather than
+	// ticks along the edges only.
+	Lines bool
+	// Readout prints the bounds of the view in the top right corner.
+	Readout bool
+	// Spacing is the smallest distance in pixels between neighbouring
+	// ticks; 0 means 80.
+	Spacing int
+}
+
+// DrawGrid draws coordinate ticks over img, which shows vp: ticks on
+// the real axis along the bottom edge and on the imaginary axis along
+// the left edge, each labelled with its value. The tick interval is the
+// smallest of 1, 2 or 5 times a power of ten that keeps ticks
+// g.Spacing pixels apart, the same on both axes. Labels that would
+// overlap their neighbour are left out.
+//
+// Gridlines on a rotated view would run at an angle to the edges their
+// ticks sit on, so DrawGrid refuses one.
+func DrawGrid(img *image.RGBA, vp coords.Viewport, g Grid) error {
+	if vp.Rotation != 0 {
+		return errors.New("overlay: can't draw a grid over a rotated view")
+	}
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	if w == 0 || h == 0 {
+		return nil
+	}
+	spacing := g.Spacing
+	if spacing <= 0 {
+		spacing = 80
+	}
+	scale := max(1, min(w, h)/600)
+	halo := haloFor(g.Color)
+	step := niceStep(float64(spacing) * vp.Bounds.Width() / float64(vp.Width))
+	tick := 6 * scale
+	org := img.Rect.Min
+
+	// real axis: columns, labelled along the bottom
+	lastEnd := math.MinInt
+	for _, v := range ticks(vp.Bounds.Xmin, vp.Bounds.Xmax, step) {
+		fx, _ := vp.ComplexToPoint(complex(v, imag(vp.Bounds.Center())))
+		x := int(math.Floor(fx))
+		if x < 0 || x >= w {
+			continue
+		}
+		y0 := h - tick
+		if g.Lines {
+			y0 = 0
+		}
+		for y := y0; y < h; y++ {
+			blend(img, org.X+x, org.Y+y, g.Color)
+		}
+		label := formatTick(v, step, "")
+		sz := textSize(label, scale)
+		lx := min(max(x-sz.X/2, 1), w-sz.X-1)
+		if lx < lastEnd+2*scale {
+			continue
+		}
+		drawText(img, org.Add(image.Pt(lx, h-tick-sz.Y-2*scale)), label, scale, g.Color, halo)
+		lastEnd = lx + sz.X
+	}
+
+	// imaginary axis: rows, labelled along the left edge
+	lastTop := math.MinInt / 2
+	for _, v := range ticks(vp.Bounds.Ymin, vp.Bounds.Ymax, step) {
+		_, fy := vp.ComplexToPoint(complex(real(vp.Bounds.Center()), v))
+		y := int(math.Floor(fy))
+		if y < 0 || y >= h {
+			continue
+		}
+		x1 := tick
+		if g.Lines {
+			x1 = w
+		}
+		for x := range x1 {
+			blend(img, org.X+x, org.Y+y, g.Color)
+		}
+		label := formatTick(v, step, "i")
+		sz := textSize(label, scale)
+		// above the tick, clear of it, unless that's off the top
+		ly := max(y-sz.Y-2*scale, 1)
+		// rows run up or down the image depending on FlipY
+		if abs(ly-lastTop) < sz.Y+2*scale {
+			continue
+		}
+		drawText(img, org.Add(image.Pt(2*scale, ly)), label, scale, g.Color, halo)
+		lastTop = ly
+	}
+
+	if g.Readout {
+		b := vp.Bounds
+		f := func(v float64) string { return strconv.FormatFloat(v, 'g', 10, 64) }
+		lines := []string{
+			"x [" + f(b.Xmin) + ", " + f(b.Xmax) + "]",
+			"y [" + f(b.Ymin) + ", " + f(b.Ymax) + "]",
+		}
+		p := org.Add(image.Pt(w-2*scale, 2*scale))
+		for _, s := range lines {
+			sz := textSize(s, scale)
+			drawText(img, image.Pt(p.X-sz.X, p.Y), s, scale, g.Color, halo)
+			p.Y += sz.Y + 3*scale
+		}
+	}
+	return nil
+}
+
+// niceStep returns the smallest of 1, 2 or 5 times a power of ten that is
+// at least min.
+func niceStep(min float64) float64 {
+	p := math.Pow(10, math.Floor(math.Log10(min)))
+	for _, m := range [...]float64{1, 2, 5, 10} {
+		if m*p >= min {
+			return m * p
+		}
+	}
+	return 10 * p
+}
+
+// ticks returns the multiples of step within [lo, hi], each computed from
+// its index so that none accumulates rounding error.
+func ticks(lo, hi, step float64) []float64 {
+	var vs []float64
+	for k := math.Ceil(lo / step); k*step <= hi; k++ {
+		vs = append(vs, k*step)
+	}
+	return vs
+}
+
+// formatTick writes v with as many decimals as step needs, followed by
+// suffix. Zero is written without a sign or suffix.
+func formatTick(v, step float64, suffix string) string {
+	decimals := max(0, int(math.Ceil(-math.Log10(step)-1e-9)))
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	if z := strconv.FormatFloat(0, 'f', decimals, 64); s == z || s == "-"+z {
+		return "0"
+	}
+	return s + suffix
+}
+
+// haloFor returns the outline color for text in c: black for light
+// colors and white for dark ones, at half c's opacity.
+func haloFor(c color.NRGBA) color.NRGBA {
+	halo := color.NRGBA{A: c.A / 2}
+	if 299*int(c.R)+587*int(c.G)+114*int(c.B) < 128*1000 {
+		halo.R, halo.G, halo.B = 0xff, 0xff, 0xff
+	}
+	return halo
+}
+
+// blend draws c over the pixel at (x, y), if it is in img.
+func blend(img *image.RGBA, x, y int, c color.NRGBA) {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return
+	}
+	i := img.PixOffset(x, y)
+	a := uint32(c.A)
+	px := img.Pix[i : i+4 : i+4]
+	for k, v := range [3]uint8{c.R, c.G, c.B} {
+		px[k] = uint8((uint32(v)*a + uint32(px[k])*(255-a) + 127) / 255)
+	}
+	px[3] = uint8((255*a + uint32(px[3])*(255-a) + 127) / 255)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
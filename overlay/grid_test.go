@@ -0,0 +1,123 @@
+package overlay
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+func TestNiceStep(t *testing.T) {
+	for _, tc := range []struct{ min, want float64 }{
+		{0.8, 1}, {1, 1}, {1.2, 2}, {3, 5}, {6, 10}, {0.013, 0.02}, {4e-9, 5e-9}, {250, 500},
+	} {
+		if got := niceStep(tc.min); got != tc.want {
+			t.Errorf("niceStep(%g) = %g, want %g", tc.min, got, tc.want)
+		}
+	}
+}
+
+func TestFormatTick(t *testing.T) {
+	for _, tc := range []struct {
+		v, step float64
+		suffix  string
+		want    string
+	}{
+		{-1, 1, "", "-1"},
+		{1, 1, "i", "1i"},
+		{0.5, 0.5, "i", "0.5i"},
+		{-0.74, 0.02, "", "-0.74"},
+		{3e-7, 1e-7, "", "0.0000003"},
+		{0, 0.1, "i", "0"},
+		{-1e-17, 0.1, "i", "0"},
+	} {
+		if got := formatTick(tc.v, tc.step, tc.suffix); got != tc.want {
+			t.Errorf("formatTick(%g, %g, %q) = %q, want %q", tc.v, tc.step, tc.suffix, got, tc.want)
+		}
+	}
+}
+
+func TestDrawGrid(t *testing.T) {
+	// 0.01 a pixel, offset half a pixel so that every tick falls in the
+	// middle of one: the real ticks -2, -1 and 0 in columns 0, 100 and
+	// 200, the imaginary ticks 0 and -1 in rows 99 and 199
+	const w, h = 300, 200
+	vp := coords.NewViewport(coords.Bounds{Xmin: -2.005, Xmax: 0.995, Ymin: -1.005, Ymax: 0.995}, w, h)
+	red := color.NRGBA{0xff, 0, 0, 0xff}
+	for _, origin := range []image.Point{{}, {10, 20}} {
+		for _, lines := range []bool{false, true} {
+			img := image.NewRGBA(image.Rectangle{origin, origin.Add(image.Pt(w, h))})
+			if err := DrawGrid(img, vp, Grid{Color: red, Lines: lines}); err != nil {
+				t.Fatal(err)
+			}
+			drawn := func(x, y int) bool { return img.RGBAAt(origin.X+x, origin.Y+y) != color.RGBA{} }
+			for _, col := range []int{0, 100, 200} {
+				if !drawn(col, h-1) {
+					t.Errorf("origin %v lines %v: no tick in column %d", origin, lines, col)
+				}
+				if drawn(col, 50) != lines {
+					t.Errorf("origin %v lines %v: column %d drawn across the image: %v", origin, lines, col, !lines)
+				}
+			}
+			for _, row := range []int{99, 199} {
+				if !drawn(0, row) {
+					t.Errorf("origin %v lines %v: no tick in row %d", origin, lines, row)
+				}
+				if drawn(150, row) != lines {
+					t.Errorf("origin %v lines %v: row %d drawn across the image: %v", origin, lines, row, !lines)
+				}
+			}
+			if drawn(150, 50) {
+				t.Errorf("origin %v lines %v: drew between the ticks", origin, lines)
+			}
+			// the label of the real tick -1 sits above its tick
+			if !anyDrawn(img, image.Rect(85, 150, 116, h-6).Add(origin)) {
+				t.Errorf("origin %v lines %v: no label over column 100", origin, lines)
+			}
+		}
+	}
+
+	// the readout goes in the top right corner, and only when asked for
+	corner := image.Rect(200, 0, w, 30)
+	for _, readout := range []bool{false, true} {
+		img := image.NewRGBA(image.Rect(0, 0, w, h))
+		if err := DrawGrid(img, vp, Grid{Color: red, Readout: readout}); err != nil {
+			t.Fatal(err)
+		}
+		if got := anyDrawn(img, corner); got != readout {
+			t.Errorf("readout %v: drew in the corner: %v", readout, got)
+		}
+	}
+
+	// a translucent grid blends with what is under it
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	if err := DrawGrid(img, vp, Grid{Color: color.NRGBA{0, 0, 0, 0x80}, Lines: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.RGBAAt(100, 50), (color.RGBA{0x7f, 0x7f, 0x7f, 0xff}); got != want {
+		t.Errorf("half-opaque black line over white: %v, want %v", got, want)
+	}
+
+	rotated := vp
+	rotated.Rotation = 30
+	if err := DrawGrid(image.NewRGBA(image.Rect(0, 0, w, h)), rotated, Grid{Color: red}); err == nil {
+		t.Error("drew a grid over a rotated view")
+	}
+}
+
+// anyDrawn reports whether any pixel of img within r is not transparent
+// black.
+func anyDrawn(img *image.RGBA, r image.Rectangle) bool {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if img.RGBAAt(x, y) != (color.RGBA{}) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package overlay
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+
+	"github.com/whalelogic/mandlebrot/analysis"
+)
+
+// Colors of the histogram chart.
+var (
+	chartBackground = color.NRGBA{0x12, 0x12, 0x18, 0xff}
+	chartInk        = color.NRGBA{0xd8, 0xd8, 0xe0, 0xff}
+	chartRule       = color.NRGBA{0xd8, 0xd8, 0xe0, 0x30}
+	chartEscaped    = color.NRGBA{0x4d, 0xa3, 0xff, 0xff}
+	chartInterior   = color.NRGBA{0xff, 0x9f, 0x40, 0xff}
+)
+
+// HistogramChart draws h as a width×height bar chart: a bar per bucket
+// of escape counts from 0 to the iteration limit, and apart from them on
+// the right a bar for the interior. Bar heights are on a log scale, so
+// sparse tails show up next to the bulk; the rules mark powers of ten.
+// A distribution still climbing at the right edge says the iteration
+// limit cuts off escaping points.
+func HistogramChart(h analysis.Histogram, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Rect, image.NewUniform(chartBackground), image.Point{}, draw.Src)
+	scale := max(1, min(width, height)/300)
+	halo := color.NRGBA{}
+
+	top := 0
+	for _, c := range h.Counts {
+		top = max(top, c)
+	}
+	top = max(top, h.Interior, 1)
+	decades := max(1, int(math.Ceil(math.Log10(float64(top)))))
+	yMaxLabel := countLabel(int(math.Pow10(decades)))
+
+	pad := 6 * scale
+	lineH := (glyphH + 3) * scale
+	left := pad + textSize(yMaxLabel, scale).X + pad
+	right := width - pad
+	plotTop := pad + lineH + pad
+	plotBottom := height - pad - lineH - pad
+	interiorW := max(4*scale, (right-left)/24)
+	barsRight := right - interiorW - 3*pad
+	if barsRight-left < 2 || plotBottom-plotTop < 2 {
+		return img
+	}
+	plotH := float64(plotBottom - plotTop)
+	barHeight := func(c int) int {
+		return int(math.Round(plotH * math.Log10(1+float64(c)) / math.Log10(1+math.Pow10(decades))))
+	}
+	fill := func(x0, y0, x1, y1 int, c color.NRGBA) {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				blend(img, x, y, c)
+			}
+		}
+	}
+
+	// decade rules and their labels
+	for k := 0; k <= decades; k++ {
+		y := plotBottom - barHeight(int(math.Pow10(k)))
+		if k > 0 {
+			fill(left, y, right, y+1, chartRule)
+		}
+		label := countLabel(int(math.Pow10(k)))
+		sz := textSize(label, scale)
+		drawText(img, image.Pt(left-pad-sz.X, y-sz.Y/2), label, scale, chartInk, halo)
+	}
+
+	// escaped buckets, each spanning its share of the columns
+	n := len(h.Counts)
+	for i, c := range h.Counts {
+		if c == 0 {
+			continue
+		}
+		x0 := left + i*(barsRight-left)/n
+		x1 := max(x0+1, left+(i+1)*(barsRight-left)/n)
+		fill(x0, plotBottom-barHeight(c), x1, plotBottom, chartEscaped)
+	}
+	// interior bar
+	ix := right - interiorW
+	fill(ix, plotBottom-barHeight(h.Interior), right, plotBottom, chartInterior)
+	label := "in"
+	sz := textSize(label, scale)
+	drawText(img, image.Pt(ix+(interiorW-sz.X)/2, plotBottom+pad), label, scale, chartInk, halo)
+
+	// baseline and iteration ticks along it
+	fill(left, plotBottom, barsRight, plotBottom+scale, chartInk)
+	if h.MaxIter > 0 {
+		px := float64(barsRight-left) / float64(h.MaxIter)
+		step := math.Max(1, niceStep(80*float64(scale)/px))
+		lastEnd := math.MinInt
+		for _, v := range ticks(0, float64(h.MaxIter), step) {
+			x := left + int(math.Round(v*px))
+			fill(x, plotBottom, x+scale, plotBottom+pad/2, chartInk)
+			label := strconv.Itoa(int(v))
+			sz := textSize(label, scale)
+			lx := min(max(x-sz.X/2, left), barsRight-sz.X)
+			if lx < lastEnd+2*scale {
+				continue
+			}
+			drawText(img, image.Pt(lx, plotBottom+pad), label, scale, chartInk, halo)
+			lastEnd = lx + sz.X
+		}
+	}
+
+	escaped := h.Stats.Pixels - h.Interior
+	title := fmt.Sprintf("escape counts, log scale: %d escaped, %d interior (%.1f%%)", escaped, h.Interior, 100*h.Stats.InsideFraction)
+	if escaped > 0 {
+		title += fmt.Sprintf(", iterations %d-%d", h.Stats.MinIter, h.Stats.MaxIter)
+	}
+	drawText(img, image.Pt(left, pad), title, scale, chartInk, halo)
+	return img
+}
+
+// countLabel writes a power of ten compactly: 1, 10, 100, 1k ... 1G.
+func countLabel(n int) string {
+	for _, u := range []struct {
+		size   int
+		suffix string
+	}{{1e9, "G"}, {1e6, "M"}, {1e3, "k"}} {
+		if n >= u.size {
+			return strconv.Itoa(n/u.size) + u.suffix
+		}
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,86 @@
+package overlay
+
+import (
+	"image/color"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/analysis"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// barHeights returns the height in pixels of the bars of color c in each
+// column of a chart.
+func barHeights(img interface{ RGBAAt(x, y int) color.RGBA }, width, height int, c color.NRGBA) []float64 {
+	want := color.RGBA(c)
+	heights := make([]float64, width)
+	for x := range width {
+		for y := range height {
+			if img.RGBAAt(x, y) == want {
+				heights[x]++
+			}
+		}
+	}
+	return heights
+}
+
+func TestHistogramChartBimodal(t *testing.T) {
+	// escape counts in two bumps of 500 iterations, around 100 and 350
+	counts := make([]int, 50)
+	for i := range counts {
+		for _, center := range []float64{10, 35} {
+			d := float64(i) + 0.5 - center
+			counts[i] += int(math.Round(1300 * math.Exp(-d*d/(2*1.5*1.5))))
+		}
+	}
+	h := analysis.Histogram{
+		Counts:   counts,
+		MaxIter:  500,
+		Interior: 400,
+		Stats:    render.Stats{Pixels: 10000, InsidePixels: 400, InsideFraction: 0.04, MinIter: 50, MaxIter: 400},
+	}
+	const width, height = 600, 300
+	img := HistogramChart(h, width, height)
+	if img.Rect.Dx() != width || img.Rect.Dy() != height {
+		t.Fatalf("size %v", img.Rect)
+	}
+
+	// the escaped bars rise in exactly two places, with nothing between
+	heights := barHeights(img, width, height, chartEscaped)
+	var runs [][2]int
+	top := slices.Max(heights)
+	for x, v := range heights {
+		if v < 0.9*top {
+			continue
+		}
+		if n := len(runs); n > 0 && runs[n-1][1] == x-1 {
+			runs[n-1][1] = x
+		} else {
+			runs = append(runs, [2]int{x, x})
+		}
+	}
+	if len(runs) != 2 {
+		t.Fatalf("%d peaks, at columns %v", len(runs), runs)
+	}
+	valley := math.Inf(1)
+	for x := runs[0][1] + 1; x < runs[1][0]; x++ {
+		valley = min(valley, heights[x])
+	}
+	if valley != 0 {
+		t.Errorf("the gap between the peaks has bars %v high", valley)
+	}
+
+	// and the interior has its own bar
+	if inside := barHeights(img, width, height, chartInterior); slices.Max(inside) == 0 {
+		t.Error("no interior bar")
+	}
+}
+
+func TestHistogramChartTiny(t *testing.T) {
+	// too small to plot in, but still an image of the size asked for
+	img := HistogramChart(analysis.Histogram{Counts: []int{1, 2}, MaxIter: 10}, 8, 8)
+	if img.Rect.Dx() != 8 || img.Rect.Dy() != 8 {
+		t.Errorf("size %v", img.Rect)
+	}
+}
@@ -0,0 +1,97 @@
+package overlay
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Corner is the corner of the image a Label is placed in.
+type Corner string
+
+const (
+	TopLeft     Corner = "top-left"
+	TopRight    Corner = "top-right"
+	BottomLeft  Corner = "bottom-left"
+	BottomRight Corner = "bottom-right"
+)
+
+// Corners lists the valid Corner values.
+var Corners = []Corner{TopLeft, TopRight, BottomLeft, BottomRight}
+
+// Label configures DrawLabel.
+type Label struct {
+	Lines  []string
+	Corner Corner // "" means BottomLeft
+
+	// Color is the text color and Background the color of the box behind
+	// it; their alphas set their opacity, and a transparent Background
+	// draws no box.
+	Color      color.NRGBA
+	Background color.NRGBA
+
+	// Scale is the size of a font pixel in image pixels; 0 picks one that
+	// makes the text about a fiftieth of the image height, so labels
+	// keep their proportions at any resolution.
+	Scale int
+}
+
+// AutoScale is the Label.Scale used for an image height pixels high.
+func AutoScale(height int) int {
+	return max(1, int(math.Round(float64(height)/50/glyphH)))
+}
+
+// DrawLabel draws l.Lines one below the other in a box in l.Corner of
+// img, aligned to that corner's side and inset from the edges by a
+// margin proportional to the text size.
+func DrawLabel(img *image.RGBA, l Label) {
+	if len(l.Lines) == 0 {
+		return
+	}
+	scale := l.Scale
+	if scale <= 0 {
+		scale = AutoScale(img.Rect.Dy())
+	}
+	pad, gap, margin := 3*scale, 3*scale, 4*scale
+	var box image.Point
+	for i, s := range l.Lines {
+		sz := textSize(s, scale)
+		box.X = max(box.X, sz.X)
+		box.Y += glyphH * scale
+		if i > 0 {
+			box.Y += gap
+		}
+	}
+	box = box.Add(image.Pt(2*pad, 2*pad))
+
+	r := img.Rect
+	var at image.Point
+	switch l.Corner {
+	case TopLeft:
+		at = image.Pt(r.Min.X+margin, r.Min.Y+margin)
+	case TopRight:
+		at = image.Pt(r.Max.X-margin-box.X, r.Min.Y+margin)
+	case BottomRight:
+		at = image.Pt(r.Max.X-margin-box.X, r.Max.Y-margin-box.Y)
+	default:
+		at = image.Pt(r.Min.X+margin, r.Max.Y-margin-box.Y)
+	}
+	right := l.Corner == TopRight || l.Corner == BottomRight
+
+	if l.Background.A != 0 {
+		for y := at.Y; y < at.Y+box.Y; y++ {
+			for x := at.X; x < at.X+box.X; x++ {
+				blend(img, x, y, l.Background)
+			}
+		}
+	}
+	y := at.Y + pad
+	for _, s := range l.Lines {
+		x := at.X + pad
+		if right {
+			x = at.X + box.X - pad - textSize(s, scale).X
+		}
+		drawText(img, image.Pt(x, y), s, scale, l.Color, color.NRGBA{})
+		y += glyphH*scale + gap
+	}
+}
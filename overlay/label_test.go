@@ -0,0 +1,147 @@
+package overlay
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestGlyphs(t *testing.T) {
+	for r := rune(' '); r <= '~'; r++ {
+		g, ok := glyphs[r]
+		if !ok {
+			t.Errorf("no glyph for %q", r)
+			continue
+		}
+		for _, row := range g {
+			if row != "" && len(row) != glyphW {
+				t.Errorf("glyph %q: row %q is not %d wide", r, row, glyphW)
+			}
+		}
+	}
+}
+
+// setPixels returns how many font pixels s has set.
+func setPixels(s string) int {
+	n := 0
+	for _, r := range s {
+		for _, row := range glyphs[r] {
+			n += strings.Count(row, "#")
+		}
+	}
+	return n
+}
+
+func TestDrawLabel(t *testing.T) {
+	// two lines at scale 2: a box 2·3 px of padding around text 22 px
+	// wide ("AB") and two 14 px lines 6 px apart, 8 px from the edges
+	const scale = 2
+	white, blue := color.NRGBA{0xff, 0xff, 0xff, 0xff}, color.NRGBA{0, 0, 0xff, 0xff}
+	box := image.Pt(22+12, 14+6+14+12)
+	frame := image.Rect(10, 20, 210, 120)
+	for _, tc := range []struct {
+		corner Corner
+		at     image.Point
+	}{
+		{TopLeft, image.Pt(18, 28)},
+		{TopRight, image.Pt(210-8-box.X, 28)},
+		{BottomLeft, image.Pt(18, 120-8-box.Y)},
+		{BottomRight, image.Pt(210-8-box.X, 120-8-box.Y)},
+		{"", image.Pt(18, 120-8-box.Y)},
+	} {
+		img := image.NewRGBA(frame)
+		DrawLabel(img, Label{Lines: []string{"AB", "C"}, Corner: tc.corner, Color: white, Background: blue, Scale: scale})
+		want := image.Rectangle{tc.at, tc.at.Add(box)}
+		var text int
+		var secondLine image.Rectangle // the set pixels of "C"
+		for y := frame.Min.Y; y < frame.Max.Y; y++ {
+			for x := frame.Min.X; x < frame.Max.X; x++ {
+				c := img.RGBAAt(x, y)
+				in := image.Pt(x, y).In(want)
+				switch {
+				case !in && c != (color.RGBA{}):
+					t.Fatalf("%q: drew outside the box %v at (%d,%d)", tc.corner, want, x, y)
+				case in && c == (color.RGBA{0xff, 0xff, 0xff, 0xff}):
+					text++
+					if y >= want.Min.Y+6+14+6 {
+						secondLine = secondLine.Union(image.Rect(x, y, x+1, y+1))
+					}
+				case in && c != (color.RGBA{0, 0, 0xff, 0xff}):
+					t.Fatalf("%q: (%d,%d) is %v, neither text nor box", tc.corner, x, y, c)
+				}
+			}
+		}
+		if want := setPixels("ABC") * scale * scale; text != want {
+			t.Errorf("%q: %d text pixels, want %d", tc.corner, text, want)
+		}
+		// lines are aligned to the side of the corner they're in: "C"
+		// starts at the left padding, or ends at the right one
+		lineX := want.Min.X + 6
+		if tc.corner == TopRight || tc.corner == BottomRight {
+			lineX = want.Max.X - 6 - 10
+		}
+		if secondLine.Min.X < lineX || secondLine.Max.X > lineX+10 {
+			t.Errorf("%q: second line drawn over columns %d-%d, want within %d-%d", tc.corner, secondLine.Min.X, secondLine.Max.X, lineX, lineX+10)
+		}
+	}
+
+	// a transparent background draws only the text, and a translucent
+	// one blends with the image
+	for _, bg := range []color.NRGBA{{}, {0, 0, 0xff, 0x80}} {
+		img := image.NewRGBA(frame)
+		DrawLabel(img, Label{Lines: []string{"AB", "C"}, Color: white, Background: bg, Scale: scale})
+		var drawn, boxed int
+		for i := 0; i < len(img.Pix); i += 4 {
+			switch px := img.Pix[i : i+4]; {
+			case px[0] == 0xff:
+				drawn++
+			case px[2] != 0:
+				boxed++
+				if px[2] != 0x80 || px[3] != 0x80 {
+					t.Fatalf("background %v: box pixel %v", bg, px)
+				}
+			}
+		}
+		if want := setPixels("ABC") * scale * scale; drawn != want {
+			t.Errorf("background %v: %d text pixels, want %d", bg, drawn, want)
+		}
+		if want := box.X*box.Y - drawn; bg.A != 0 && boxed != want {
+			t.Errorf("background %v: %d box pixels, want %d", bg, boxed, want)
+		}
+		if bg.A == 0 && boxed != 0 {
+			t.Errorf("transparent background drew %d box pixels", boxed)
+		}
+	}
+
+	// no lines, no box
+	img := image.NewRGBA(frame)
+	DrawLabel(img, Label{Color: white, Background: blue})
+	for _, v := range img.Pix {
+		if v != 0 {
+			t.Fatal("drew a label with no lines")
+		}
+	}
+}
+
+func TestAutoScale(t *testing.T) {
+	for _, tc := range []struct{ height, want int }{
+		{100, 1}, {600, 2}, {1080, 3}, {2160, 6}, {10, 1},
+	} {
+		if got := AutoScale(tc.height); got != tc.want {
+			t.Errorf("AutoScale(%d) = %d, want %d", tc.height, got, tc.want)
+		}
+	}
+	// a label left to pick its scale uses AutoScale's
+	img := image.NewRGBA(image.Rect(0, 0, 1920, 1080))
+	DrawLabel(img, Label{Lines: []string{"."}, Color: color.NRGBA{0xff, 0xff, 0xff, 0xff}})
+	n := 0
+	for i := 0; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0 {
+			n++
+		}
+	}
+	if want := setPixels(".") * 3 * 3; n != want {
+		t.Errorf("%d pixels drawn at 1080 px high, want %d", n, want)
+	}
+}
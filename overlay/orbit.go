@@ -0,0 +1,118 @@
+package overlay
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+// DrawOrbit draws orbit over img, which shows vp: a line from each
+// iterate to the next and a dot on every iterate in view. The orbit fades
+// as it goes, from c's opacity at the start to a fifth of it at the end,
+// so its direction can be read off. Segments are clipped to the image,
+// so an orbit that leaves the view still draws the parts that cross it.
+func DrawOrbit(img *image.RGBA, vp coords.Viewport, orbit []complex128, c color.NRGBA) {
+	if len(orbit) == 0 {
+		return
+	}
+	scale := AutoScale(img.Rect.Dy())
+	fade := func(i int) color.NRGBA {
+		f := 1.0
+		if len(orbit) > 1 {
+			f = 1 - 0.8*float64(i)/float64(len(orbit)-1)
+		}
+		return color.NRGBA{c.R, c.G, c.B, uint8(math.Round(float64(c.A) * f))}
+	}
+	// the image rectangle in the viewport's pixel coordinates
+	w, h := float64(img.Rect.Dx()), float64(img.Rect.Dy())
+	pts := make([][2]float64, len(orbit))
+	for i, z := range orbit {
+		pts[i][0], pts[i][1] = vp.ComplexToPoint(z)
+	}
+	for i := 1; i < len(pts); i++ {
+		if x0, y0, x1, y1, ok := clipSegment(pts[i-1][0], pts[i-1][1], pts[i][0], pts[i][1], w, h); ok {
+			drawLine(img, x0, y0, x1, y1, fade(i-1))
+		}
+	}
+	r := scale + 1
+	for i, p := range pts {
+		if !(p[0] >= 0 && p[0] < w && p[1] >= 0 && p[1] < h) {
+			continue
+		}
+		px, py := int(p[0]), int(p[1])
+		for dy := -r; dy <= r; dy++ {
+			for dx := -r; dx <= r; dx++ {
+				if dx*dx+dy*dy <= r*r {
+					blend(img, img.Rect.Min.X+px+dx, img.Rect.Min.Y+py+dy, fade(i))
+				}
+			}
+		}
+	}
+}
+
+// clipSegment clips the segment from (x0, y0) to (x1, y1) to the
+// rectangle [0, w)×[0, h) with the Liang–Barsky method. ok is false when
+// no part of it is inside, including when an end isn't finite.
+func clipSegment(x0, y0, x1, y1, w, h float64) (cx0, cy0, cx1, cy1 float64, ok bool) {
+	for _, v := range [...]float64{x0, y0, x1, y1} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0, 0, 0, 0, false
+		}
+	}
+	dx, dy := x1-x0, y1-y0
+	t0, t1 := 0.0, 1.0
+	// each edge as p·t <= q
+	edges := [...][2]float64{{-dx, x0}, {dx, w - 1 - x0}, {-dy, y0}, {dy, h - 1 - y0}}
+	for _, e := range edges {
+		p, q := e[0], e[1]
+		if p == 0 {
+			if q < 0 {
+				return 0, 0, 0, 0, false
+			}
+			continue
+		}
+		t := q / p
+		if p < 0 {
+			t0 = max(t0, t)
+		} else {
+			t1 = min(t1, t)
+		}
+		if t0 > t1 {
+			return 0, 0, 0, 0, false
+		}
+	}
+	return x0 + t0*dx, y0 + t0*dy, x0 + t1*dx, y0 + t1*dy, true
+}
+
+// drawLine draws a one-pixel line between two points inside img's
+// bounds, given relative to its top left corner, with Bresenham's
+// algorithm.
+func drawLine(img *image.RGBA, fx0, fy0, fx1, fy1 float64, c color.NRGBA) {
+	x0, y0, x1, y1 := int(fx0), int(fy0), int(fx1), int(fy1)
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		blend(img, img.Rect.Min.X+x0, img.Rect.Min.Y+y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
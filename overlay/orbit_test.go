@@ -0,0 +1,119 @@
+package overlay
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+func TestDrawLine(t *testing.T) {
+	white := color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	for _, seg := range [][4]int{
+		{0, 0, 9, 3}, {9, 3, 0, 0}, {2, 0, 4, 9}, {4, 9, 2, 0},
+		{0, 9, 9, 0}, {5, 1, 5, 8}, {1, 5, 8, 5}, {3, 3, 3, 3},
+	} {
+		img := image.NewRGBA(image.Rect(10, 20, 20, 30))
+		drawLine(img, float64(seg[0]), float64(seg[1]), float64(seg[2]), float64(seg[3]), white)
+		set := func(x, y int) bool { return img.RGBAAt(img.Rect.Min.X+x, img.Rect.Min.Y+y).A != 0 }
+		if !set(seg[0], seg[1]) || !set(seg[2], seg[3]) {
+			t.Errorf("%v: an end is not drawn", seg)
+		}
+		// one pixel per step along the longer axis, each touching the last
+		n := 0
+		for y := range 10 {
+			for x := range 10 {
+				if !set(x, y) {
+					continue
+				}
+				n++
+				if seg[0] == seg[2] && seg[1] == seg[3] {
+					continue
+				}
+				neighbours := 0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if (dx != 0 || dy != 0) && x+dx >= 0 && y+dy >= 0 && set(x+dx, y+dy) {
+							neighbours++
+						}
+					}
+				}
+				if neighbours == 0 {
+					t.Errorf("%v: (%d, %d) is cut off from the line", seg, x, y)
+				}
+			}
+		}
+		if want := max(abs(seg[2]-seg[0]), abs(seg[3]-seg[1])) + 1; n != want {
+			t.Errorf("%v: %d pixels, want %d", seg, n, want)
+		}
+	}
+}
+
+func TestDrawOrbitSegments(t *testing.T) {
+	const (
+		c       = -0.75 + 0.2i
+		maxIter = 100
+	)
+	// the escape iteration, counted directly
+	iter, z := 0, complex128(0)
+	for ; iter < maxIter && real(z)*real(z)+imag(z)*imag(z) <= 4; iter++ {
+		z = z*z + c
+	}
+	if iter == maxIter {
+		t.Fatal("c does not escape")
+	}
+	orbit := fractal.Orbit(fractal.Mandelbrot{}, c, maxIter, 4)
+	if len(orbit)-1 < iter {
+		t.Fatalf("%d segments for an escape at iteration %d", len(orbit)-1, iter)
+	}
+
+	// a view that holds the whole orbit, large enough that each segment
+	// reaches out of the dots at its ends
+	r := 0.0
+	for _, z := range orbit {
+		r = max(r, math.Abs(real(z)), math.Abs(imag(z)))
+	}
+	r *= 1.1
+	const size = 2000
+	vp := coords.NewViewport(coords.Bounds{Xmin: -r, Xmax: r, Ymin: -r, Ymax: r}, size, size)
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	DrawOrbit(img, vp, orbit, color.NRGBA{0xff, 0, 0, 0xff})
+	drawn := 0
+	for i := 1; i < len(orbit); i++ {
+		x, y := vp.ComplexToPoint((orbit[i-1] + orbit[i]) / 2)
+		painted := false
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				painted = painted || img.RGBAAt(int(x)+dx, int(y)+dy).A != 0
+			}
+		}
+		if painted {
+			drawn++
+		} else {
+			t.Errorf("segment %d, %v to %v, is not drawn", i, orbit[i-1], orbit[i])
+		}
+	}
+	if drawn < iter {
+		t.Errorf("%d segments drawn for an escape at iteration %d", drawn, iter)
+	}
+}
+
+func TestDrawOrbitClipped(t *testing.T) {
+	// an orbit that leaves the view, and one that overflows, draw what
+	// is in view without panicking
+	vp := coords.NewViewport(coords.Bounds{Xmin: -2, Xmax: 2, Ymin: -2, Ymax: 2}, 100, 100)
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	DrawOrbit(img, vp, []complex128{0, 10, complex(math.Inf(1), 0), 1i}, color.NRGBA{0xff, 0, 0, 0xff})
+	// the segment from 0 toward 10 crosses the right half of the middle row
+	for x := 50; x < 100; x++ {
+		if img.RGBAAt(x, 50).A == 0 && img.RGBAAt(x, 49).A == 0 {
+			t.Fatalf("(%d, 50) is not drawn", x)
+		}
+	}
+	if _, _, _, _, ok := clipSegment(-5, -5, -1, -1, 100, 100); ok {
+		t.Error("a segment outside the image is not clipped away")
+	}
+}
@@ -0,0 +1,92 @@
+package overlay
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// TimingMap draws the work-unit timings of a width×height render as a
+// heatmap of the same size: each unit's pixels take the color cm gives
+// its time per row, from 0 at the start of the palette to the slowest
+// unit's at the end. Timing per row rather than per unit keeps units of
+// different sizes comparable. A title gives the scale and the worker
+// count, and a bar in the bottom left corner shows the palette. Pixels
+// no unit covers, as in a cancelled render, stay black.
+func TimingMap(timings []render.TaskTiming, width, height int, cm *palette.ColorMap) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		if i%4 == 3 {
+			img.Pix[i] = 0xff
+		}
+	}
+	var slowest time.Duration
+	workers := 0
+	for _, t := range timings {
+		slowest = max(slowest, perRow(t))
+		workers = max(workers, t.Worker+1)
+	}
+	for _, t := range timings {
+		c := cm.Interpolate(0)
+		if slowest > 0 {
+			c = cm.Interpolate(float64(perRow(t)) / float64(slowest))
+		}
+		r := t.Rect.Intersect(img.Rect)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+
+	scale := AutoScale(height)
+	pad := 4 * scale
+	halo := color.NRGBA{0, 0, 0, 0xc0}
+	title := fmt.Sprintf("time per row, 0 to %s: %d units on %d worker", formatDuration(slowest), len(timings), workers)
+	if workers != 1 {
+		title += "s"
+	}
+	drawText(img, image.Pt(pad, pad), title, scale, chartInk, halo)
+
+	// palette bar, labelled at both ends
+	barW, barH := min(256*scale, width/3), 3*scale
+	lo, hi := "0", formatDuration(slowest)
+	labelY := height - pad - glyphH*scale
+	barY := labelY - pad - barH
+	if barW < 2 || barY < pad {
+		return img
+	}
+	for x := range barW {
+		c := cm.Interpolate(float64(x) / float64(barW-1))
+		for y := barY; y < barY+barH; y++ {
+			img.SetRGBA(pad+x, y, c)
+		}
+	}
+	drawText(img, image.Pt(pad, labelY), lo, scale, chartInk, halo)
+	drawText(img, image.Pt(pad+barW-textSize(hi, scale).X, labelY), hi, scale, chartInk, halo)
+	return img
+}
+
+// perRow is t's time divided among its rows.
+func perRow(t render.TaskTiming) time.Duration {
+	return t.Elapsed / time.Duration(max(1, t.Rect.Dy()))
+}
+
+// formatDuration writes d rounded to a hundredth of its unit, in the
+// font's ASCII: "us" for microseconds.
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		d = d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		d = d.Round(10 * time.Microsecond)
+	case d >= time.Microsecond:
+		d = d.Round(10 * time.Nanosecond)
+	}
+	return strings.ReplaceAll(d.String(), "µ", "u")
+}
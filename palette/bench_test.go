@@ -0,0 +1,63 @@
+package palette
+
+import (
+	"image/color"
+	"testing"
+)
+
+// sinks keep the compiler from discarding benchmarked work
+var (
+	sinkColor color.RGBA
+	sink      any
+)
+
+// benchPalette is the renderer's default palette.
+const benchPalette = "NebulaSpectre"
+
+func BenchmarkPaletteInterpolate(b *testing.B) {
+	cm := Get(benchPalette).Frozen() // read from its table, as in a render
+	b.ResetTimer()
+	t := 0.0
+	for range b.N {
+		sinkColor = cm.Interpolate(t)
+		if t += 1e-7; t > 1 {
+			t = 0
+		}
+	}
+}
+
+func BenchmarkPaletteNormalize(b *testing.B) {
+	src := Get(benchPalette)
+	cm := &ColorMap{Keyword: src.Keyword, Colors: make([]Color, len(src.Colors))}
+	b.ResetTimer()
+	for range b.N {
+		copy(cm.Colors, src.Colors)
+		// a fresh map, so Normalize doesn't skip it as already done
+		*cm = ColorMap{Keyword: cm.Keyword, Colors: cm.Colors}
+		Normalize(cm)
+	}
+	sink = cm
+}
+
+// BenchmarkPaletteGet looks up a built-in palette, as the tile server
+// does for every request. Renormalize normalizes the result again from
+// scratch, which is what Get cost before built-ins were normalized once
+// at init.
+func BenchmarkPaletteGet(b *testing.B) {
+	for _, renormalize := range []bool{false, true} {
+		name := "Plain"
+		if renormalize {
+			name = "Renormalize"
+		}
+		b.Run(name, func(b *testing.B) {
+			for range b.N {
+				cm := Get(benchPalette)
+				if renormalize {
+					*cm = ColorMap{Keyword: cm.Keyword, Colors: cm.Colors}
+					Normalize(cm)
+				}
+				sink = cm
+			}
+		})
+	}
+}
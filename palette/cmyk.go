@@ -0,0 +1,46 @@
+package palette
+
+import "image/color"
+
+// CMYKSamples is how many evenly spaced colors ColorMap.ToCMYK returns.
+const CMYKSamples = 256
+
+// CMYKColor is a print color: cyan, magenta, yellow and black ink, 0 to
+// 255 each. It is the standard library's color.CMYK, converted to and
+// from RGB the naive way that type does, without an ink profile.
+type CMYKColor = color.CMYK
+
+// CMYKStop is a palette stop given in ink, for FromCMYK.
+type CMYKStop struct {
+	Step  float64
+	Color CMYKColor
+}
+
+// NewStopCMYK returns a stop at step with the given ink amounts, stored
+// as a CMYKColor; Interpolate and the rest see it as the RGB color it
+// converts to, opaque.
+func NewStopCMYK(step float64, c, m, y, k uint8) Color {
+	return Color{Step: step, Color: CMYKColor{C: c, M: m, Y: y, K: k}}
+}
+
+// FromCMYK returns a normalized palette of the given stops, without a
+// Keyword. Steps follow the usual rules; see Normalize.
+func FromCMYK(stops []CMYKStop) *ColorMap {
+	cm := &ColorMap{Colors: make([]Color, len(stops))}
+	for i, s := range stops {
+		cm.Colors[i] = Color{Step: s.Step, Color: s.Color}
+	}
+	Normalize(cm)
+	return cm
+}
+
+// ToCMYK samples cm at CMYKSamples evenly spaced positions from 0 to 1,
+// as ColorMap.At does, and converts each color to ink. CMYK has no
+// alpha, so translucent colors come out as they look over black.
+func (cm *ColorMap) ToCMYK() []CMYKColor {
+	out := make([]CMYKColor, CMYKSamples)
+	for i := range out {
+		out[i] = color.CMYKModel.Convert(cm.Interpolate(float64(i) / (CMYKSamples - 1))).(CMYKColor)
+	}
+	return out
+}
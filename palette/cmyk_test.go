@@ -0,0 +1,79 @@
+package palette
+
+import (
+	"image/color"
+	"testing"
+)
+
+// within reports whether a and b differ by at most d in each channel.
+func within(a, b color.RGBA, d uint8) bool {
+	ok := func(x, y uint8) bool { return max(x, y)-min(x, y) <= d }
+	return ok(a.R, b.R) && ok(a.G, b.G) && ok(a.B, b.B) && ok(a.A, b.A)
+}
+
+func TestCMYKRoundTrip(t *testing.T) {
+	// the request's (0, 255, 0, 255), which is green, and red besides
+	for _, c := range []color.RGBA{
+		{0, 255, 0, 255}, {255, 0, 0, 255}, {0, 0, 255, 255},
+		{12, 200, 99, 255}, {255, 255, 255, 255}, {0, 0, 0, 255}, {128, 64, 200, 255},
+	} {
+		ink := color.CMYKModel.Convert(c).(CMYKColor)
+		cm := FromCMYK([]CMYKStop{{Step: 0, Color: ink}, {Step: 1, Color: ink}})
+		if got := cm.Interpolate(0.5); !within(got, c, 5) {
+			t.Errorf("%v: through %v, back as %v", c, ink, got)
+		}
+
+		// and a whole palette's samples, through ink and back
+		orig := Gradient(c, color.RGBA{255, 255, 255, 255}, 2)
+		samples := orig.ToCMYK()
+		stops := make([]CMYKStop, len(samples))
+		for i, s := range samples {
+			stops[i] = CMYKStop{Step: float64(i) / float64(len(samples)-1), Color: s}
+		}
+		back := FromCMYK(stops)
+		for i := range 256 {
+			tt := float64(i) / 255
+			if got, want := back.Interpolate(tt), orig.Interpolate(tt); !within(got, want, 5) {
+				t.Fatalf("%v: sample %d back as %v, want %v", c, i, got, want)
+			}
+		}
+	}
+}
+
+func TestToCMYK(t *testing.T) {
+	cm := Gradient(color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}, 2)
+	inks := cm.ToCMYK()
+	if len(inks) != CMYKSamples {
+		t.Fatalf("%d samples, want %d", len(inks), CMYKSamples)
+	}
+	if inks[0] != (CMYKColor{K: 255}) || inks[CMYKSamples-1] != (CMYKColor{}) {
+		t.Errorf("black %v, white %v", inks[0], inks[CMYKSamples-1])
+	}
+	// greys take black ink only, less of it as they lighten
+	for i := 1; i < len(inks); i++ {
+		if k := inks[i]; k.C != 0 || k.M != 0 || k.Y != 0 || k.K > inks[i-1].K {
+			t.Fatalf("sample %d: %v after %v", i, k, inks[i-1])
+		}
+	}
+}
+
+func TestNewStopCMYK(t *testing.T) {
+	red := NewStopCMYK(0, 0, 255, 255, 0)
+	if got := toRGBA(red.Color); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("red ink: %v", got)
+	}
+	if _, ok := red.Color.(CMYKColor); !ok {
+		t.Errorf("stored as %T", red.Color)
+	}
+	cm := &ColorMap{Colors: []Color{red, NewStopCMYK(1, 255, 0, 255, 0)}}
+	Normalize(cm)
+	if got := cm.Interpolate(1); got != (color.RGBA{0, 255, 0, 255}) {
+		t.Errorf("green ink end: %v", got)
+	}
+	if got := cm.Interpolate(0.5); !within(got, color.RGBA{128, 128, 0, 255}, 1) {
+		t.Errorf("halfway: %v", got)
+	}
+	if got := ToGPL(cm); got != "GIMP Palette\nName: \nColumns: 2\n#\n255   0   0\tstep 0\n  0 255   0\tstep 1\n" {
+		t.Errorf("as a GIMP palette:\n%s", got)
+	}
+}
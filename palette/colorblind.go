@@ -0,0 +1,272 @@
+package palette
+
+import (
+	"image/color"
+	"math"
+)
+
+// Deficiency is a form of dichromacy, color vision with one of the three
+// kinds of cone missing.
+type Deficiency string
+
+const (
+	Protanopia   Deficiency = "protanopia"   // no L cones: red-green confusion, reds look dark
+	Deuteranopia Deficiency = "deuteranopia" // no M cones: red-green confusion
+	Tritanopia   Deficiency = "tritanopia"   // no S cones: blue-yellow confusion
+)
+
+// Deficiencies lists the simulated deficiencies.
+var Deficiencies = []Deficiency{Protanopia, Deuteranopia, Tritanopia}
+
+// brettel holds the simulation of one deficiency by Brettel, Viénot and
+// Mollon (1997): colors are projected in LMS space onto one of two
+// half-planes through the neutral axis, chosen by which side of the
+// separating plane they fall. The LMS conversions are folded into the
+// matrices, so they apply to linear sRGB directly; the values are those
+// computed for sRGB by the DaltonLens project.
+type brettel struct {
+	m1, m2 [9]float64 // row-major, for the positive and negative side
+	normal [3]float64 // of the separating plane, in linear sRGB
+}
+
+var brettelParams = map[Deficiency]brettel{
+	Protanopia: {
+		m1: [9]float64{
+			0.14980, 1.19548, -0.34528,
+			0.10764, 0.84864, 0.04372,
+			0.00384, -0.00540, 1.00156,
+		},
+		m2: [9]float64{
+			0.14570, 1.16172, -0.30742,
+			0.10816, 0.85291, 0.03892,
+			0.00386, -0.00524, 1.00139,
+		},
+		normal: [3]float64{0.00048, 0.00393, -0.00441},
+	},
+	Deuteranopia: {
+		m1: [9]float64{
+			0.36477, 0.86381, -0.22858,
+			0.26294, 0.64245, 0.09462,
+			-0.02006, 0.02728, 0.99278,
+		},
+		m2: [9]float64{
+			0.37298, 0.88166, -0.25464,
+			0.25954, 0.63506, 0.10540,
+			-0.01980, 0.02784, 0.99196,
+		},
+		normal: [3]float64{-0.00281, -0.00611, 0.00892},
+	},
+	Tritanopia: {
+		m1: [9]float64{
+			1.01277, 0.13548, -0.14826,
+			-0.01243, 0.86812, 0.14431,
+			0.07589, 0.80500, 0.11911,
+		},
+		m2: [9]float64{
+			0.93678, 0.18979, -0.12657,
+			0.06154, 0.81526, 0.12320,
+			-0.37562, 1.12767, 0.24796,
+		},
+		normal: [3]float64{0.03901, -0.02788, -0.01113},
+	},
+}
+
+// Simulate returns c as it looks to someone with deficiency d, keeping
+// its alpha. Grays are left as they are. An unknown d returns c.
+func Simulate(c color.Color, d Deficiency) color.NRGBA {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	p, ok := brettelParams[d]
+	if !ok {
+		return n
+	}
+	rgb := [3]float64{srgbToLinear(n.R), srgbToLinear(n.G), srgbToLinear(n.B)}
+	m := &p.m1
+	if rgb[0]*p.normal[0]+rgb[1]*p.normal[1]+rgb[2]*p.normal[2] < 0 {
+		m = &p.m2
+	}
+	var out [3]uint8
+	for i := range out {
+		out[i] = linearToSRGB(m[3*i]*rgb[0] + m[3*i+1]*rgb[1] + m[3*i+2]*rgb[2])
+	}
+	return color.NRGBA{out[0], out[1], out[2], n.A}
+}
+
+// Lab is a color in CIE L*a*b* with the D65 white point, lightness L
+// from 0 to 100.
+type Lab struct{ L, A, B float64 }
+
+// ToLab converts the color part of c, ignoring its alpha, from sRGB to
+// L*a*b*.
+func ToLab(c color.Color) Lab {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	r, g, b := srgbToLinear(n.R), srgbToLinear(n.G), srgbToLinear(n.B)
+	// sRGB to XYZ, scaled by the D65 white
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*b) / 0.95047
+	y := 0.2126729*r + 0.7151522*g + 0.0721750*b
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*b) / 1.08883
+	f := func(t float64) float64 {
+		const d = 6.0 / 29
+		if t > d*d*d {
+			return math.Cbrt(t)
+		}
+		return t/(3*d*d) + 4.0/29
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return Lab{116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)}
+}
+
+// DeltaE2000 returns the CIEDE2000 color difference between x and y, on
+// which 1 is about the smallest difference that can be seen side by
+// side. The formula is the one given by Sharma, Wu and Dalal (2005),
+// with the weights kL, kC and kH all 1.
+func DeltaE2000(x, y Lab) float64 {
+	const pow25to7 = 6103515625 // 25^7
+	deg := math.Pi / 180
+
+	cbar := (math.Hypot(x.A, x.B) + math.Hypot(y.A, y.B)) / 2
+	cbar7 := math.Pow(cbar, 7)
+	g := 0.5 * (1 - math.Sqrt(cbar7/(cbar7+pow25to7)))
+	a1, a2 := (1+g)*x.A, (1+g)*y.A
+	c1, c2 := math.Hypot(a1, x.B), math.Hypot(a2, y.B)
+	hue := func(b, a float64) float64 {
+		if a == 0 && b == 0 {
+			return 0
+		}
+		h := math.Atan2(b, a) / deg
+		if h < 0 {
+			h += 360
+		}
+		return h
+	}
+	h1, h2 := hue(x.B, a1), hue(y.B, a2)
+
+	dL := y.L - x.L
+	dC := c2 - c1
+	var dh float64
+	if c1*c2 != 0 {
+		dh = h2 - h1
+		switch {
+		case dh > 180:
+			dh -= 360
+		case dh < -180:
+			dh += 360
+		}
+	}
+	dH := 2 * math.Sqrt(c1*c2) * math.Sin(dh/2*deg)
+
+	lbar := (x.L + y.L) / 2
+	cbarp := (c1 + c2) / 2
+	hbar := h1 + h2
+	if c1*c2 != 0 {
+		switch {
+		case math.Abs(h1-h2) <= 180:
+			hbar /= 2
+		case hbar < 360:
+			hbar = (hbar + 360) / 2
+		default:
+			hbar = (hbar - 360) / 2
+		}
+	}
+	t := 1 - 0.17*math.Cos((hbar-30)*deg) + 0.24*math.Cos(2*hbar*deg) +
+		0.32*math.Cos((3*hbar+6)*deg) - 0.20*math.Cos((4*hbar-63)*deg)
+	dTheta := 30 * math.Exp(-math.Pow((hbar-275)/25, 2))
+	cbarp7 := math.Pow(cbarp, 7)
+	rc := 2 * math.Sqrt(cbarp7/(cbarp7+pow25to7))
+	l50 := (lbar - 50) * (lbar - 50)
+	sl := 1 + 0.015*l50/math.Sqrt(20+l50)
+	sc := 1 + 0.045*cbarp
+	sh := 1 + 0.015*cbarp*t
+	rt := -math.Sin(2*dTheta*deg) * rc
+
+	l, c, h := dL/sl, dC/sc, dH/sh
+	return math.Sqrt(l*l + c*c + h*h + rt*c*h)
+}
+
+// srgbToLinear decodes an 8-bit sRGB channel to linear light in [0,1].
+func srgbToLinear(v uint8) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB encodes linear light as an 8-bit sRGB channel, clipping
+// to [0,1].
+func linearToSRGB(v float64) uint8 {
+	v = min(max(v, 0), 1)
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(255 * v))
+}
+
+// Check defaults: the palette is split into CheckRegions regions, and two
+// neighbouring ones count as told apart at a ΔE2000 of CheckMinDeltaE.
+const (
+	CheckRegions   = 16
+	CheckMinDeltaE = 2.0
+)
+
+// Collapse is a pair of neighbouring regions of a palette that can be
+// told apart with normal color vision but not with a deficiency.
+type Collapse struct {
+	From, To          float64 // the palette positions of the regions' centers
+	Normal, Simulated float64 // ΔE2000 between them without and with the deficiency
+}
+
+// CheckResult is what Check found for one deficiency.
+type CheckResult struct {
+	Deficiency Deficiency
+	MinDeltaE  float64 // smallest simulated ΔE2000 between neighbouring regions
+	Collapses  []Collapse
+}
+
+// Check splits cm into regions equal regions, takes the color at the
+// center of each and, for each of Deficiencies, compares neighbouring
+// regions with the deficiency simulated. Neighbours at least minDeltaE
+// apart with normal vision but less with the deficiency, and less than
+// half as far apart as before, are reported as collapsed: the detail
+// between them is lost to someone with it. Stretches of the palette that
+// are flat for everyone aren't reported. Zero
+// arguments take the defaults CheckRegions and CheckMinDeltaE.
+func Check(cm *ColorMap, regions int, minDeltaE float64) []CheckResult {
+	if regions <= 0 {
+		regions = CheckRegions
+	}
+	if minDeltaE <= 0 {
+		minDeltaE = CheckMinDeltaE
+	}
+	samples := make([]color.RGBA, max(regions, 2))
+	for i := range samples {
+		samples[i] = cm.Interpolate((float64(i) + 0.5) / float64(len(samples)))
+	}
+	center := func(i int) float64 { return (float64(i) + 0.5) / float64(len(samples)) }
+	var results []CheckResult
+	for _, d := range Deficiencies {
+		res := CheckResult{Deficiency: d, MinDeltaE: math.Inf(1)}
+		for i := 1; i < len(samples); i++ {
+			normal := DeltaE2000(ToLab(samples[i-1]), ToLab(samples[i]))
+			sim := DeltaE2000(ToLab(Simulate(samples[i-1], d)), ToLab(Simulate(samples[i], d)))
+			res.MinDeltaE = min(res.MinDeltaE, sim)
+			if normal >= minDeltaE && sim < minDeltaE && sim < normal/2 {
+				res.Collapses = append(res.Collapses, Collapse{center(i - 1), center(i), normal, sim})
+			}
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// ColorblindSafe reports whether Check with the defaults finds no
+// collapse in cm for any deficiency.
+func ColorblindSafe(cm *ColorMap) bool {
+	for _, r := range Check(cm, 0, 0) {
+		if len(r.Collapses) > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package palette
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestDeltaE2000Reference(t *testing.T) {
+	// pairs from the test data of Sharma, Wu and Dalal (2005), with the
+	// differences they give to four decimals
+	for _, tc := range []struct {
+		x, y Lab
+		want float64
+	}{
+		{Lab{50, 2.6772, -79.7751}, Lab{50, 0, -82.7485}, 2.0425},
+		{Lab{50, 3.1571, -77.2803}, Lab{50, 0, -82.7485}, 2.8615},
+		{Lab{50, 2.8361, -74.0200}, Lab{50, 0, -82.7485}, 3.4412},
+		{Lab{50, -1.3802, -84.2814}, Lab{50, 0, -82.7485}, 1.0000},
+		{Lab{50, 0, 0}, Lab{50, -1, 2}, 2.3669},
+		{Lab{50, 2.49, -0.001}, Lab{50, -2.49, 0.0009}, 7.1792},
+		{Lab{50, 2.49, -0.001}, Lab{50, -2.49, 0.0011}, 7.2195},
+		{Lab{50, -0.001, 2.49}, Lab{50, 0.0009, -2.49}, 4.8045},
+		{Lab{50, 2.5, 0}, Lab{73, 25, -18}, 27.1492},
+		{Lab{50, 2.5, 0}, Lab{61, -5, 29}, 22.8977},
+		{Lab{50, 2.5, 0}, Lab{56, -27, -3}, 31.9030},
+		{Lab{50, 2.5, 0}, Lab{58, 24, 15}, 19.4535},
+		{Lab{50, 2.5, 0}, Lab{50, 3.1736, 0.5854}, 1.0000},
+		{Lab{60.2574, -34.0099, 36.2677}, Lab{60.4626, -34.1751, 39.4387}, 1.2644},
+		{Lab{63.0109, -31.0961, -5.8663}, Lab{62.8187, -29.7946, -4.0864}, 1.2630},
+		{Lab{61.2901, 3.7196, -5.3901}, Lab{61.4292, 2.2480, -4.9620}, 1.8731},
+		{Lab{35.0831, -44.1164, 3.7933}, Lab{35.0232, -40.0716, 1.5901}, 1.8645},
+		{Lab{22.7233, 20.0904, -46.6940}, Lab{23.0331, 14.9730, -42.5619}, 2.0373},
+		{Lab{36.4612, 47.8580, 18.3852}, Lab{36.2715, 50.5065, 21.2231}, 1.4146},
+		{Lab{90.8027, -2.0831, 1.4410}, Lab{91.1528, -1.6435, 0.0447}, 1.4441},
+		{Lab{90.9257, -0.5406, -0.9208}, Lab{88.6381, -0.8985, -0.7239}, 1.5381},
+		{Lab{6.7747, -0.2908, -2.4247}, Lab{5.8714, -0.0985, -2.2286}, 0.6377},
+		{Lab{2.0776, 0.0795, -1.1350}, Lab{0.9033, -0.0636, -0.5514}, 0.9082},
+	} {
+		// symmetric, and zero between a color and itself
+		for _, got := range []float64{DeltaE2000(tc.x, tc.y), DeltaE2000(tc.y, tc.x)} {
+			if math.Abs(got-tc.want) > 5e-5 {
+				t.Errorf("DeltaE2000(%v, %v) = %.5f, want %.4f", tc.x, tc.y, got, tc.want)
+			}
+		}
+		if d := DeltaE2000(tc.x, tc.x); d != 0 {
+			t.Errorf("DeltaE2000(%v, itself) = %v", tc.x, d)
+		}
+	}
+}
+
+func TestToLabReference(t *testing.T) {
+	for _, tc := range []struct {
+		c    color.NRGBA
+		want Lab
+	}{
+		{color.NRGBA{0, 0, 0, 0xff}, Lab{0, 0, 0}},
+		{color.NRGBA{0xff, 0xff, 0xff, 0xff}, Lab{100, 0, 0}},
+		{color.NRGBA{0xff, 0, 0, 0xff}, Lab{53.2408, 80.0925, 67.2032}},
+		{color.NRGBA{0, 0xff, 0, 0xff}, Lab{87.7347, -86.1827, 83.1793}},
+		{color.NRGBA{0, 0, 0xff, 0xff}, Lab{32.2970, 79.1875, -107.8602}},
+		// alpha is ignored
+		{color.NRGBA{0xff, 0, 0, 0x40}, Lab{53.2408, 80.0925, 67.2032}},
+	} {
+		got := ToLab(tc.c)
+		if math.Abs(got.L-tc.want.L) > 0.01 || math.Abs(got.A-tc.want.A) > 0.01 || math.Abs(got.B-tc.want.B) > 0.01 {
+			t.Errorf("ToLab(%v) = %v, want %v", tc.c, got, tc.want)
+		}
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	for _, d := range Deficiencies {
+		// greys are kept
+		for v := 0; v < 256; v += 15 {
+			g := color.NRGBA{uint8(v), uint8(v), uint8(v), 0x80}
+			if got := Simulate(g, d); got != g {
+				t.Errorf("%s: grey %v becomes %v", d, g, got)
+			}
+		}
+		// the simulation projects onto the colors the deficiency leaves,
+		// so simulating again changes nothing but rounding; saturated
+		// colors are left out, as they project outside sRGB and are clipped
+		for _, c := range []color.NRGBA{
+			{0xc0, 0x80, 0x60, 0xff}, {0x60, 0xa0, 0x90, 0xff}, {0x90, 0x70, 0xb0, 0xff}, {0x80, 0x90, 0x60, 0xff},
+		} {
+			once := Simulate(c, d)
+			if twice := Simulate(once, d); DeltaE2000(ToLab(once), ToLab(twice)) > 1 {
+				t.Errorf("%s: %v simulates to %v, then %v", d, c, once, twice)
+			}
+		}
+	}
+	// the classic confusions: red and green for the red-green
+	// deficiencies, blue and green for tritanopia
+	confused := func(a, b color.NRGBA, d Deficiency) float64 {
+		return DeltaE2000(ToLab(Simulate(a, d)), ToLab(Simulate(b, d)))
+	}
+	red, green := color.NRGBA{0xc0, 0x60, 0x40, 0xff}, color.NRGBA{0x80, 0x80, 0x38, 0xff}
+	for _, d := range []Deficiency{Protanopia, Deuteranopia} {
+		if normal, sim := DeltaE2000(ToLab(red), ToLab(green)), confused(red, green, d); !(sim < normal/2) {
+			t.Errorf("%s: red and green %.1f apart, %.1f simulated", d, normal, sim)
+		}
+	}
+	if c := color.NRGBA(color.RGBA{1, 2, 3, 4}); Simulate(c, "achromatopsia") != color.NRGBAModel.Convert(c).(color.NRGBA) {
+		t.Error("an unknown deficiency changed the color")
+	}
+}
+
+func TestColorblindSafeFlags(t *testing.T) {
+	for _, p := range ColorPalettes {
+		if got := ColorblindSafe(Get(p.Keyword)); got != p.ColorblindSafe {
+			t.Errorf("%s: ColorblindSafe %v, flagged %v", p.Keyword, got, p.ColorblindSafe)
+		}
+	}
+	// AuroraArc loses its detail around t = 0.69 to deuteranopia
+	for _, r := range Check(Get("AuroraArc"), 0, 0) {
+		if r.Deficiency != Deuteranopia {
+			continue
+		}
+		found := false
+		for _, c := range r.Collapses {
+			found = found || c.From <= 0.69 && c.To >= 0.69
+			if !(c.Normal >= CheckMinDeltaE && c.Simulated < CheckMinDeltaE && c.Simulated < c.Normal/2) {
+				t.Errorf("collapse %+v", c)
+			}
+		}
+		if !found {
+			t.Errorf("no collapse around 0.69 in %+v", r.Collapses)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package palette
+
+import (
+	"reflect"
+	"strings"
+)
+
+// An Easing reshapes the blend along one segment of a palette: it maps
+// the position t in [0,1] between two stops to [0,1], with 0 and 1
+// fixed, so the segment still starts and ends on the stops' colors.
+type Easing func(t float64) float64
+
+// Linear is the plain blend, the same as no easing.
+func Linear(t float64) float64 { return t }
+
+// EaseInQuad lingers on the segment's first color and hurries into the
+// next, t².
+func EaseInQuad(t float64) float64 { return t * t }
+
+// EaseOutQuad hurries away from the first color and lingers on the next,
+// 1-(1-t)².
+func EaseOutQuad(t float64) float64 { return t * (2 - t) }
+
+// EaseInOutQuad lingers on both colors, accelerating as 2t² for the
+// first half and decelerating symmetrically for the second.
+func EaseInOutQuad(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	u := 1 - t
+	return 1 - 2*u*u
+}
+
+// Smoothstep lingers on both colors a little less, 3t²-2t³.
+func Smoothstep(t float64) float64 { return t * t * (3 - 2*t) }
+
+// EasingNames lists the easings EasingByName knows, by the names the
+// JSON form of a palette gives them.
+var EasingNames = []string{"linear", "easeInQuad", "easeOutQuad", "easeInOutQuad", "smoothstep"}
+
+var easings = []Easing{Linear, EaseInQuad, EaseOutQuad, EaseInOutQuad, Smoothstep}
+
+// EasingByName returns the named easing, ignoring case, or false for an
+// unknown name.
+func EasingByName(name string) (Easing, bool) {
+	for i, n := range EasingNames {
+		if strings.EqualFold(n, name) {
+			return easings[i], true
+		}
+	}
+	return nil, false
+}
+
+// easingName returns the name EasingByName knows e by, or false if e is
+// none of the named easings. Functions can't be compared, so this goes
+// by the address of their code.
+func easingName(e Easing) (string, bool) {
+	p := reflect.ValueOf(e).Pointer()
+	for i, f := range easings {
+		if reflect.ValueOf(f).Pointer() == p {
+			return EasingNames[i], true
+		}
+	}
+	return "", false
+}
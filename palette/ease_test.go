@@ -0,0 +1,138 @@
+package palette
+
+import (
+	"encoding/json"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEasings(t *testing.T) {
+	if got := EaseInQuad(0.5); got != 0.25 {
+		t.Errorf("EaseInQuad(0.5) = %v, want 0.25", got)
+	}
+	if got := EaseOutQuad(0.5); got != 0.75 {
+		t.Errorf("EaseOutQuad(0.5) = %v, want 0.75", got)
+	}
+	for i, e := range easings {
+		name := EasingNames[i]
+		if e(0) != 0 || e(1) != 1 {
+			t.Errorf("%s: %v at 0, %v at 1, want 0 and 1", name, e(0), e(1))
+		}
+		for x := 0.0; x < 1; x += 0.01 {
+			if e(x+0.01) < e(x) {
+				t.Errorf("%s falls from %v to %v after %v", name, e(x), e(x+0.01), x)
+			}
+		}
+	}
+	// the in-out easings are symmetric about the middle
+	for _, e := range []Easing{EaseInOutQuad, Smoothstep} {
+		for _, x := range []float64{0.1, 0.25, 0.4} {
+			if d := e(x) + e(1-x) - 1; math.Abs(d) > 1e-15 {
+				t.Errorf("e(%v) + e(%v) = 1%+g", x, 1-x, d)
+			}
+		}
+	}
+}
+
+func TestEasingByName(t *testing.T) {
+	for i, name := range EasingNames {
+		for _, n := range []string{name, strings.ToUpper(name)} {
+			e, ok := EasingByName(n)
+			if !ok {
+				t.Errorf("EasingByName(%q) not found", n)
+				continue
+			}
+			if got, ok := easingName(e); !ok || got != name {
+				t.Errorf("easingName(EasingByName(%q)) = %q, %v", n, got, ok)
+			}
+			if e(0.3) != easings[i](0.3) {
+				t.Errorf("EasingByName(%q) is another easing", n)
+			}
+		}
+	}
+	if _, ok := EasingByName("bounce"); ok {
+		t.Error("EasingByName(bounce) found")
+	}
+	if _, ok := easingName(func(t float64) float64 { return t }); ok {
+		t.Error("an anonymous function has a name")
+	}
+}
+
+// easedRamp is black to grey to white, the first segment eased by e.
+func easedRamp(e Easing) *ColorMap {
+	cm := &ColorMap{Keyword: "Eased", Colors: []Color{
+		{Step: 0, Color: color.RGBA{0, 0, 0, 0xff}, Easing: e},
+		{Step: 0.5, Color: color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{Step: 1, Color: color.RGBA{0, 0, 0, 0xff}},
+	}}
+	Normalize(cm)
+	return cm
+}
+
+func TestInterpolateEased(t *testing.T) {
+	cm := easedRamp(EaseInQuad)
+	// halfway along the first segment is a quarter of the way in color:
+	// 0.25·255 = 63.75
+	if got, want := cm.Interpolate(0.25), (color.RGBA{64, 64, 64, 0xff}); got != want {
+		t.Errorf("Interpolate(0.25) = %v, want %v", got, want)
+	}
+	if got, want := cm.InterpolateNRGBA(0.25), (color.NRGBA{64, 64, 64, 0xff}); got != want {
+		t.Errorf("InterpolateNRGBA(0.25) = %v, want %v", got, want)
+	}
+	// the second segment starts at an uneased stop and stays linear
+	if got, want := cm.Interpolate(0.75), easedRamp(nil).Interpolate(0.75); got != want {
+		t.Errorf("Interpolate(0.75) = %v, want the linear %v", got, want)
+	}
+	// the stops themselves don't move
+	for _, x := range []float64{0, 0.5, 1} {
+		if got, want := cm.Interpolate(x), easedRamp(nil).Interpolate(x); got != want {
+			t.Errorf("Interpolate(%v) = %v, want %v", x, got, want)
+		}
+	}
+}
+
+func TestEasingJSON(t *testing.T) {
+	b, err := json.Marshal(easedRamp(EaseInOutQuad))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"easing":"easeInOutQuad"`) {
+		t.Errorf("%s doesn't name the easing", b)
+	}
+	var back ColorMap
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if name, ok := easingName(back.Colors[0].Easing); !ok || name != "easeInOutQuad" || back.Colors[1].Easing != nil {
+		t.Errorf("decoded easings %q and %v", name, back.Colors[1].Easing != nil)
+	}
+	if back.Fingerprint() != easedRamp(EaseInOutQuad).Fingerprint() {
+		t.Error("round trip changed the stops")
+	}
+
+	if _, err := json.Marshal(easedRamp(func(t float64) float64 { return t })); err == nil {
+		t.Error("encoded an unnamed easing")
+	}
+	bad := `{"name": "Bad", "stops": [{"step": 0, "color": "#000000", "easing": "bounce"}, {"step": 1, "color": "#ffffff"}]}`
+	if err := json.Unmarshal([]byte(bad), &back); err == nil {
+		t.Error("decoded an unknown easing")
+	}
+}
+
+func TestEasingFingerprint(t *testing.T) {
+	plain, eased := easedRamp(nil).Fingerprint(), easedRamp(EaseInQuad).Fingerprint()
+	if plain == eased || eased == easedRamp(EaseOutQuad).Fingerprint() {
+		t.Error("easings don't change the fingerprint")
+	}
+	// a built-in given an easing leaves its table behind
+	cm := Get("ThermalHeat")
+	if cm.Frozen().lut == nil {
+		t.Fatal("ThermalHeat has no table to leave behind")
+	}
+	cm.Colors[0].Easing = EaseInQuad
+	if cm.Frozen().lut != nil {
+		t.Error("eased copy of a built-in still uses the table")
+	}
+}
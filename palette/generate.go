@@ -0,0 +1,12 @@
+package palette
+
+// The built-in palettes are checked with Validate, and their lookup
+// tables written to palettes_gen.go, by
+//
+//	go generate ./palette
+//
+// which fails if any palette is invalid. Run it after changing
+// ColorPalettes; until then the changed palettes are blended from their
+// stops as any other palette is.
+//
+//go:generate go run ../cmd/validatepalettes -lut palettes_gen.go
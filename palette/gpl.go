@@ -0,0 +1,92 @@
+package palette
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// gplMagic opens every GIMP palette file.
+const gplMagic = "GIMP Palette"
+
+// ToGPL writes cm as a GIMP palette (.gpl), which GIMP and Inkscape
+// read:
+//
+//	GIMP Palette
+//	Name: Ember
+//	Columns: 2
+//	#
+//	  0   0   0	step 0
+//	255 128   0	step 1
+//
+// with a line per stop, named after its step. The format has no steps or
+// alpha, so they are lost: FromGPL spaces the stops evenly, and colors
+// are written without their alpha, as if opaque.
+func ToGPL(cm *ColorMap) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\nName: %s\nColumns: %d\n#\n", gplMagic, cm.Keyword, len(cm.Colors))
+	for _, c := range cm.Colors {
+		n := toNRGBA(c.Color)
+		fmt.Fprintf(&b, "%3d %3d %3d\tstep %s\n", n.R, n.G, n.B, strconv.FormatFloat(c.Step, 'g', -1, 64))
+	}
+	return b.String()
+}
+
+// FromGPL reads a GIMP palette, as written by ToGPL or GIMP, into a
+// normalized ColorMap keyworded with its Name (empty if it has none).
+// The colors become opaque stops spaced evenly over [0,1] in file order;
+// color names, Columns and comments are ignored.
+func FromGPL(r io.Reader) (*ColorMap, error) {
+	sc := bufio.NewScanner(r)
+	if !sc.Scan() || strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\ufeff")) != gplMagic {
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("palette: not a GIMP palette: want %q on the first line", gplMagic)
+	}
+	cm := &ColorMap{}
+	line := 1
+	for sc.Scan() {
+		line++
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		if name, ok := strings.CutPrefix(s, "Name:"); ok {
+			cm.Keyword = strings.TrimSpace(name)
+			continue
+		}
+		if strings.HasPrefix(s, "Columns:") {
+			continue
+		}
+		fields := strings.Fields(s)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("palette: GIMP palette line %d: want R G B and an optional name, got %q", line, s)
+		}
+		var rgb [3]uint8
+		for i, f := range fields[:3] {
+			v, err := strconv.ParseUint(f, 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("palette: GIMP palette line %d: component %q: want 0 to 255", line, f)
+			}
+			rgb[i] = uint8(v)
+		}
+		cm.Colors = append(cm.Colors, Color{Color: color.NRGBA{rgb[0], rgb[1], rgb[2], 0xff}})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(cm.Colors) == 0 {
+		return nil, fmt.Errorf("palette %q: GIMP palette has no colors", cm.Keyword)
+	}
+	for i := range cm.Colors {
+		if len(cm.Colors) > 1 {
+			cm.Colors[i].Step = float64(i) / float64(len(cm.Colors)-1)
+		}
+	}
+	Normalize(cm)
+	return cm, nil
+}
@@ -0,0 +1,108 @@
+package palette
+
+import (
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestGPLRoundTrip(t *testing.T) {
+	for _, p := range ColorPalettes {
+		cm := Get(p.Keyword)
+		got, err := FromGPL(strings.NewReader(ToGPL(cm)))
+		if err != nil {
+			t.Fatalf("%s: %v", p.Keyword, err)
+		}
+		if got.Keyword != cm.Keyword {
+			t.Errorf("%s: read back as %q", p.Keyword, got.Keyword)
+		}
+		if len(got.Colors) != len(cm.Colors) {
+			t.Fatalf("%s: %d colors, want %d", p.Keyword, len(got.Colors), len(cm.Colors))
+		}
+		n := len(cm.Colors)
+		for i, c := range cm.Colors {
+			want := toNRGBA(c.Color)
+			want.A = 0xff
+			if g := got.Colors[i]; g.Color != want {
+				t.Errorf("%s: color %d is %v, want %v", p.Keyword, i, g.Color, want)
+			}
+			// the steps come back evenly spaced
+			step := 0.0
+			if n > 1 {
+				step = float64(i) / float64(n-1)
+			}
+			if s := got.Colors[i].Step; math.Abs(s-step) > 1e-12 {
+				t.Errorf("%s: step %d is %v, want %v", p.Keyword, i, s, step)
+			}
+		}
+	}
+}
+
+func TestToGPL(t *testing.T) {
+	cm := &ColorMap{Keyword: "Ember", Colors: []Color{
+		{Step: 0, Color: color.NRGBA{0, 0, 0, 0xff}},
+		{Step: 0.25, Color: color.NRGBA{200, 10, 0, 0x80}},
+		{Step: 1, Color: color.RGBA{255, 128, 0, 0xff}},
+	}}
+	want := "GIMP Palette\nName: Ember\nColumns: 3\n#\n" +
+		"  0   0   0\tstep 0\n" +
+		"200  10   0\tstep 0.25\n" +
+		"255 128   0\tstep 1\n"
+	if got := ToGPL(cm); got != want {
+		t.Errorf("ToGPL:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFromGPL(t *testing.T) {
+	// as GIMP writes them: a byte-order mark, comments, tabs and color
+	// names with spaces
+	const gimp = "\ufeffGIMP Palette\r\nName: Deep Sea\nColumns: 4\n# a comment\n\n" +
+		"  0  10  40\tAbyss blue\n" +
+		" 20 120 200\n" +
+		"250 250 255\tFoam  white\n"
+	cm, err := FromGPL(strings.NewReader(gimp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.Keyword != "Deep Sea" {
+		t.Errorf("keyword %q", cm.Keyword)
+	}
+	want := []Color{
+		{Step: 0, Color: color.NRGBA{0, 10, 40, 0xff}},
+		{Step: 0.5, Color: color.NRGBA{20, 120, 200, 0xff}},
+		{Step: 1, Color: color.NRGBA{250, 250, 255, 0xff}},
+	}
+	if len(cm.Colors) != len(want) {
+		t.Fatalf("%d colors, want %d", len(cm.Colors), len(want))
+	}
+	for i, w := range want {
+		if c := cm.Colors[i]; c.Step != w.Step || c.Color != w.Color {
+			t.Errorf("color %d: %+v, want %+v", i, cm.Colors[i], w)
+		}
+	}
+
+	one, err := FromGPL(strings.NewReader("GIMP Palette\n1 2 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(one.Colors) != 1 || one.Colors[0].Color != (color.NRGBA{1, 2, 3, 0xff}) || one.Keyword != "" {
+		t.Errorf("single color: %+v", one)
+	}
+}
+
+func TestFromGPLErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"JASC-PAL\n0100\n",
+		"GIMP Palette\nName: Empty\n#\n",
+		"GIMP Palette\n1 2\n",
+		"GIMP Palette\n1 2 256\n",
+		"GIMP Palette\n1 -2 3\n",
+		"GIMP Palette\n1 two 3 name\n",
+	} {
+		if _, err := FromGPL(strings.NewReader(in)); err == nil {
+			t.Errorf("%q: no error", in)
+		}
+	}
+}
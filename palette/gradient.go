@@ -0,0 +1,42 @@
+package palette
+
+import "image/color"
+
+// Gradient returns an n-stop palette running from from to to, the stops
+// evenly spaced and their colors blended linearly in sRGB as Interpolate
+// blends them. n is raised to 2 if it is less. The map has no Keyword
+// and is already normalized.
+func Gradient(from, to color.Color, n int) *ColorMap {
+	n = max(n, 2)
+	a, b := toRGBA(from), toRGBA(to)
+	cm := &ColorMap{Colors: make([]Color, n)}
+	for i := range cm.Colors {
+		t := float64(i) / float64(n-1)
+		cm.Colors[i] = Color{Step: t, Color: lerpRGBA(a, b, t)}
+	}
+	Normalize(cm)
+	return cm
+}
+
+// Diverging returns an n-stop palette that runs from lo through mid at
+// 0.5 to hi, each half a Gradient, for data above and below a center
+// value. n is raised to the next odd number, and to at least 3, so that
+// mid is a stop of its own rather than a blend of the two beside it.
+func Diverging(lo, mid, hi color.Color, n int) *ColorMap {
+	n = max(n|1, 3)
+	a, m, b := toRGBA(lo), toRGBA(mid), toRGBA(hi)
+	half := n / 2
+	cm := &ColorMap{Colors: make([]Color, n)}
+	for i := range cm.Colors {
+		c := m
+		switch {
+		case i < half:
+			c = lerpRGBA(a, m, float64(i)/float64(half))
+		case i > half:
+			c = lerpRGBA(m, b, float64(i-half)/float64(half))
+		}
+		cm.Colors[i] = Color{Step: float64(i) / float64(n-1), Color: c}
+	}
+	Normalize(cm)
+	return cm
+}
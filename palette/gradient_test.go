@@ -0,0 +1,87 @@
+package palette
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+// near reports whether a and b differ by at most 1 in each channel.
+func near(a, b color.RGBA) bool {
+	d := func(x, y uint8) bool { return max(x, y)-min(x, y) <= 1 }
+	return d(a.R, b.R) && d(a.G, b.G) && d(a.B, b.B) && d(a.A, b.A)
+}
+
+func TestGradient(t *testing.T) {
+	black, white := color.RGBA{0, 0, 0, 0xff}, color.RGBA{0xff, 0xff, 0xff, 0xff}
+	cm := Gradient(black, white, 3)
+	want := []struct {
+		step float64
+		c    color.RGBA
+	}{{0, black}, {0.5, color.RGBA{128, 128, 128, 0xff}}, {1, white}}
+	if len(cm.Colors) != len(want) {
+		t.Fatalf("%d stops, want %d", len(cm.Colors), len(want))
+	}
+	for i, w := range want {
+		c := cm.Colors[i]
+		if c.Step != w.step || !near(toRGBA(c.Color), w.c) {
+			t.Errorf("stop %d: %v at %v, want %v at %v", i, c.Color, c.Step, w.c, w.step)
+		}
+	}
+	if cm.Keyword != "" || !cm.normalized {
+		t.Errorf("keyword %q, normalized %v", cm.Keyword, cm.normalized)
+	}
+
+	// n is raised to 2; more stops fall on the straight line between
+	for _, n := range []int{-1, 0, 1, 2} {
+		if got := Gradient(black, white, n); len(got.Colors) != 2 {
+			t.Errorf("n = %d: %d stops", n, len(got.Colors))
+		}
+	}
+	red, blue := color.RGBA{0xff, 0, 0, 0xff}, color.RGBA{0, 0, 0xff, 0xff}
+	cm = Gradient(red, blue, 11)
+	for i, c := range cm.Colors {
+		if want := float64(i) / 10; math.Abs(c.Step-want) > 1e-12 {
+			t.Errorf("stop %d at %v, want %v", i, c.Step, want)
+		}
+		if got := cm.Interpolate(c.Step); !near(got, Gradient(red, blue, 2).Interpolate(c.Step)) {
+			t.Errorf("stop %d: %v, off the two-stop gradient", i, got)
+		}
+	}
+}
+
+func TestDiverging(t *testing.T) {
+	blue, white, red := color.RGBA{0, 0, 0xff, 0xff}, color.RGBA{0xff, 0xff, 0xff, 0xff}, color.RGBA{0xff, 0, 0, 0xff}
+	cm := Diverging(blue, white, red, 5)
+	if len(cm.Colors) != 5 {
+		t.Fatalf("%d stops, want 5", len(cm.Colors))
+	}
+	mid := cm.Colors[2]
+	if mid.Step != 0.5 || toRGBA(mid.Color) != white {
+		t.Errorf("midpoint %v at %v, want white at 0.5", mid.Color, mid.Step)
+	}
+	if got := cm.Interpolate(0.5); got != white {
+		t.Errorf("Interpolate(0.5) = %v, want white", got)
+	}
+	if toRGBA(cm.Colors[0].Color) != blue || toRGBA(cm.Colors[4].Color) != red {
+		t.Errorf("ends %v and %v", cm.Colors[0].Color, cm.Colors[4].Color)
+	}
+	// the quarter stops are halfway to white
+	if got := toRGBA(cm.Colors[1].Color); !near(got, color.RGBA{128, 128, 0xff, 0xff}) {
+		t.Errorf("stop 1: %v", got)
+	}
+	if got := toRGBA(cm.Colors[3].Color); !near(got, color.RGBA{0xff, 128, 128, 0xff}) {
+		t.Errorf("stop 3: %v", got)
+	}
+
+	// n is raised to odd and at least 3, so mid always has its own stop
+	for n, want := range map[int]int{-1: 3, 0: 3, 2: 3, 3: 3, 4: 5, 6: 7, 7: 7} {
+		cm := Diverging(blue, white, red, n)
+		if len(cm.Colors) != want {
+			t.Errorf("n = %d: %d stops, want %d", n, len(cm.Colors), want)
+		}
+		if got := cm.Colors[want/2]; got.Step != 0.5 || toRGBA(got.Color) != white {
+			t.Errorf("n = %d: middle stop %v at %v", n, got.Color, got.Step)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package palette
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+// ImageWidth is the width of a ColorMap seen as an image.Image.
+const ImageWidth = 256
+
+// Bounds, ColorModel and At make a ColorMap an image.Image: a strip
+// ImageWidth pixels wide and one high running through the palette from
+// left to right, so it can go straight to draw.Draw or png.Encode.
+func (cm *ColorMap) Bounds() image.Rectangle { return image.Rect(0, 0, ImageWidth, 1) }
+
+func (cm *ColorMap) ColorModel() color.Model { return color.RGBAModel }
+
+// At returns the palette color at t = x/(ImageWidth-1) for any y, and
+// transparent black outside the strip's columns.
+func (cm *ColorMap) At(x, y int) color.Color {
+	if x < 0 || x >= ImageWidth {
+		return color.RGBA{}
+	}
+	return cm.Interpolate(float64(x) / (ImageWidth - 1))
+}
+
+// Image draws cm as a width×height horizontal gradient, the first column
+// at t = 0 and the last at t = 1.
+func (cm *ColorMap) Image(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		t := 0.0
+		if width > 1 {
+			t = float64(x) / float64(width-1)
+		}
+		c := cm.Interpolate(t)
+		for y := range height {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// ToPNG draws cm as a strip n pixels long and one wide, n×1 if
+// horizontal and 1×n if not, running from t = 0 at the left or top to
+// t = 1 at the right or bottom: a lookup table for color pickers and UI
+// gradients. n below 1 gives an empty image.
+func ToPNG(cm *ColorMap, n int, horizontal bool) *image.RGBA {
+	n = max(n, 0)
+	r := image.Rect(0, 0, 1, n)
+	if horizontal {
+		r = image.Rect(0, 0, n, 1)
+	}
+	img := image.NewRGBA(r)
+	for i := range n {
+		t := 0.0
+		if n > 1 {
+			t = float64(i) / float64(n-1)
+		}
+		// the strip is one pixel wide, so its pixels are consecutive
+		// either way round
+		c := cm.Interpolate(t)
+		copy(img.Pix[4*i:], []uint8{c.R, c.G, c.B, c.A})
+	}
+	return img
+}
+
+// ToPNGFile writes ToPNG(cm, n, horizontal) to path as a PNG.
+func ToPNGFile(cm *ColorMap, n int, horizontal bool, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, ToPNG(cm, n, horizontal)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
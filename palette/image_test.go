@@ -0,0 +1,141 @@
+package palette
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestColorMapPNG(t *testing.T) {
+	for _, p := range ColorPalettes {
+		cm := Get(p.Keyword)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, cm); err != nil {
+			t.Fatalf("%s: %v", p.Keyword, err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", p.Keyword, err)
+		}
+		w := cm.Bounds().Max.X
+		if got := img.Bounds(); got != image.Rect(0, 0, w, 1) {
+			t.Fatalf("%s: decoded bounds %v, want %d wide", p.Keyword, got, w)
+		}
+		for x := range w {
+			want := cm.Interpolate(float64(x) / float64(w-1))
+			if got := color.RGBAModel.Convert(img.At(x, 0)); got != want {
+				t.Fatalf("%s: pixel %d is %v, want %v", p.Keyword, x, got, want)
+			}
+		}
+	}
+}
+
+func TestColorMapImage(t *testing.T) {
+	cm := Get("ThermalHeat")
+	if got := cm.At(-1, 0); got != (color.RGBA{}) {
+		t.Errorf("At(-1, 0) = %v, want transparent", got)
+	}
+	if got := cm.At(ImageWidth, 0); got != (color.RGBA{}) {
+		t.Errorf("At(%d, 0) = %v, want transparent", ImageWidth, got)
+	}
+	if cm.At(10, 0) != cm.At(10, 99) {
+		t.Error("At depends on y")
+	}
+
+	img := cm.Image(100, 20)
+	if got := img.Bounds(); got != image.Rect(0, 0, 100, 20) {
+		t.Fatalf("Image bounds %v", got)
+	}
+	for _, x := range []int{0, 33, 99} {
+		want := cm.Interpolate(float64(x) / 99)
+		for _, y := range []int{0, 19} {
+			if got := img.At(x, y); got != want {
+				t.Errorf("Image pixel (%d, %d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+
+	// straight into draw.Draw, stretched down the rows
+	dst := image.NewRGBA(image.Rect(0, 0, ImageWidth, 3))
+	for y := range 3 {
+		draw.Draw(dst, image.Rect(0, y, ImageWidth, y+1), cm, image.Point{}, draw.Src)
+	}
+	if got, want := dst.RGBAAt(128, 2), cm.Interpolate(128.0/(ImageWidth-1)); got != want {
+		t.Errorf("drawn pixel %v, want %v", got, want)
+	}
+}
+
+func TestToPNG(t *testing.T) {
+	strip := ToPNG(Get("MonochromeSlate"), 256, true)
+	if strip.Rect != image.Rect(0, 0, 256, 1) {
+		t.Fatalf("bounds %v", strip.Rect)
+	}
+	if got := strip.RGBAAt(0, 0); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("pixel 0 is %v, want opaque black", got)
+	}
+	if got := strip.RGBAAt(255, 0); got != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("pixel 255 is %v, want opaque white", got)
+	}
+	// the dark half climbs only to 0x70 over 128 pixels, so neighbours
+	// there can be equal, but never darker
+	luma := func(c color.RGBA) float64 { return 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B) }
+	for x := 1; x < 256; x++ {
+		if prev, c := strip.RGBAAt(x-1, 0), strip.RGBAAt(x, 0); luma(c) < luma(prev) {
+			t.Errorf("pixel %d (%v) is darker than pixel %d (%v)", x, c, x-1, prev)
+		}
+	}
+
+	// the vertical strip is the same pixels
+	vert := ToPNG(Get("MonochromeSlate"), 256, false)
+	if vert.Rect != image.Rect(0, 0, 1, 256) {
+		t.Fatalf("vertical bounds %v", vert.Rect)
+	}
+	if !bytes.Equal(vert.Pix, strip.Pix) {
+		t.Error("vertical strip differs from the horizontal one")
+	}
+
+	cm := Get("ThermalHeat")
+	if got := ToPNG(cm, 1, true); got.Rect.Dx() != 1 || got.RGBAAt(0, 0) != cm.Interpolate(0) {
+		t.Errorf("one pixel: %v %v", got.Rect, got.RGBAAt(0, 0))
+	}
+	for _, n := range []int{0, -3} {
+		if got := ToPNG(cm, n, false); !got.Rect.Empty() {
+			t.Errorf("n = %d: bounds %v", n, got.Rect)
+		}
+	}
+}
+
+func TestToPNGFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strip.png")
+	cm := Get("ThermalHeat")
+	if err := ToPNGFile(cm, 64, false, path); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ToPNG(cm, 64, false)
+	if img.Bounds() != want.Rect {
+		t.Fatalf("bounds %v, want %v", img.Bounds(), want.Rect)
+	}
+	for y := range 64 {
+		if got := color.RGBAModel.Convert(img.At(0, y)); got != want.RGBAAt(0, y) {
+			t.Fatalf("pixel %d is %v, want %v", y, got, want.RGBAAt(0, y))
+		}
+	}
+
+	if err := ToPNGFile(cm, 64, true, filepath.Join(t.TempDir(), "missing", "strip.png")); err == nil {
+		t.Error("missing directory: no error")
+	}
+}
@@ -0,0 +1,94 @@
+package palette
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// jsonMap is the JSON form of a ColorMap:
+//
+//	{"name": "Ember", "stops": [{"step": 0, "color": "#000000", "easing": "easeInQuad"}, {"step": 1, "color": "#ff8000", "weight": 2}]}
+//
+// Colors are "#RRGGBB" or "#RRGGBBAA". A stop without a step is spaced
+// evenly, as by Normalize, and one without a weight has weight 1. An
+// easing is one of EasingNames, for the segment that starts at the stop.
+type jsonMap struct {
+	Name  string     `json:"name"`
+	Stops []jsonStop `json:"stops"`
+}
+
+type jsonStop struct {
+	Step   float64 `json:"step"`
+	Color  string  `json:"color"`
+	Weight float64 `json:"weight,omitempty"`
+	Easing string  `json:"easing,omitempty"`
+}
+
+// MarshalJSON encodes cm in the form read by UnmarshalJSON. A stop
+// eased by a function other than the named easings can't be encoded.
+func (cm ColorMap) MarshalJSON() ([]byte, error) {
+	out := jsonMap{Name: cm.Keyword, Stops: make([]jsonStop, len(cm.Colors))}
+	for i, c := range cm.Colors {
+		out.Stops[i] = jsonStop{Step: c.Step, Color: Hex(c.Color), Weight: c.Weight}
+		if c.Easing != nil {
+			name, ok := easingName(c.Easing)
+			if !ok {
+				return nil, fmt.Errorf("palette %q: stop %d: easing is not one of %s", cm.Keyword, i, strings.Join(EasingNames, ", "))
+			}
+			out.Stops[i].Easing = name
+		}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes and validates a palette, then normalizes it.
+func (cm *ColorMap) UnmarshalJSON(data []byte) error {
+	var in jsonMap
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if in.Name == "" {
+		return errors.New("palette: missing name")
+	}
+	if len(in.Stops) == 0 {
+		return fmt.Errorf("palette %q: no color stops", in.Name)
+	}
+	colors := make([]Color, len(in.Stops))
+	for i, s := range in.Stops {
+		if !(s.Step >= -1 && s.Step <= 1) {
+			return fmt.Errorf("palette %q: stop %d: step %g outside [-1,1]", in.Name, i, s.Step)
+		}
+		if s.Weight < 0 || math.IsNaN(s.Weight) || math.IsInf(s.Weight, 0) {
+			return fmt.Errorf("palette %q: stop %d: weight %g must be a finite number, 0 or above", in.Name, i, s.Weight)
+		}
+		c, err := NewStopHex(s.Step, s.Color)
+		if err != nil {
+			return fmt.Errorf("palette %q: stop %d: %w", in.Name, i, err)
+		}
+		c.Weight = s.Weight
+		if s.Easing != "" {
+			e, ok := EasingByName(s.Easing)
+			if !ok {
+				return fmt.Errorf("palette %q: stop %d: easing %q is not one of %s", in.Name, i, s.Easing, strings.Join(EasingNames, ", "))
+			}
+			c.Easing = e
+		}
+		colors[i] = c
+	}
+	*cm = ColorMap{Keyword: in.Name, Colors: colors}
+	Normalize(cm)
+	return nil
+}
+
+// Hex formats c as "#RRGGBB", or "#RRGGBBAA" when it isn't opaque.
+func Hex(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if n.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", n.R, n.G, n.B, n.A)
+}
@@ -0,0 +1,105 @@
+package palette
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// LUTSize is how many evenly spaced samples the precomputed table of a
+// built-in palette holds, from t = 0 to t = 1.
+const LUTSize = 256
+
+// lut is a precomputed table of a palette: entry i is its color at
+// t = i/(LUTSize-1).
+type lut [LUTSize]color.RGBA
+
+// attachedLUT is the table a ColorMap reads from, with the gaps between
+// samples that a stop falls inside. The palette bends at a stop, so the
+// straight line between the samples on either side misses its color by
+// up to a unit; in those gaps at defers to the exact blend.
+type attachedLUT struct {
+	table *lut
+	bent  [LUTSize - 1]bool
+}
+
+// at blends the two samples around t, which must be above 0 and not
+// NaN, or reports false when a stop lies between them. Elsewhere the
+// palette is a straight line between samples, so this is within a unit
+// per channel of Interpolate, and several times faster.
+func (a *attachedLUT) at(t float64) (color.RGBA, bool) {
+	f := t * (LUTSize - 1)
+	if !(f < LUTSize-1) {
+		return a.table[LUTSize-1], true
+	}
+	i := int(f)
+	if a.bent[i] {
+		return color.RGBA{}, false
+	}
+	return lerpRGBA(a.table[i], a.table[i+1], f-float64(i)), true
+}
+
+// generatedLUT is an entry of builtinLUTs: the table of a built-in
+// palette and the Fingerprint of the stops it was sampled from.
+type generatedLUT struct {
+	fingerprint string
+	table       *lut
+}
+
+// attachLUT gives cm its table from builtinLUTs, if there is one that was
+// generated from the same stops, and otherwise none. After a built-in
+// palette is changed and before go generate is run again, the stale
+// table is left unused.
+func attachLUT(cm *ColorMap) {
+	cm.lut = nil
+	g, ok := builtinLUTs[cm.Keyword]
+	if !ok || g.fingerprint != cm.Fingerprint() {
+		return
+	}
+	a := &attachedLUT{table: g.table}
+	for _, c := range cm.Colors {
+		// a stop on a sample is in the table exactly
+		if f := c.Step * (LUTSize - 1); f > 0 && f < LUTSize-1 && f != math.Trunc(f) {
+			a.bent[int(f)] = true
+		}
+	}
+	cm.lut = a
+}
+
+// Frozen returns a copy of cm with its own stops, normalized, for
+// coloring many pixels: if cm is a built-in palette whose stops are
+// unchanged, the copy reads from its precomputed table. The table is
+// matched to the stops once, here, so the copy's stops are not to be
+// changed; take a new Frozen copy instead. Renders freeze their palette
+// when they start.
+func (cm *ColorMap) Frozen() *ColorMap {
+	cpy := *cm
+	cpy.Colors = slices.Clone(cm.Colors)
+	Normalize(&cpy)
+	attachLUT(&cpy)
+	return &cpy
+}
+
+// Fingerprint returns a string that identifies the stops of cm: their
+// steps, colors, weights and easings, in order. Maps with the same stops
+// have the same fingerprint.
+func (cm *ColorMap) Fingerprint() string {
+	var b strings.Builder
+	for i, c := range cm.Colors {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%x:%s:%x", math.Float64bits(c.Step), Hex(c.Color), math.Float64bits(c.Weight))
+		if c.Easing != nil {
+			if name, ok := easingName(c.Easing); ok {
+				fmt.Fprintf(&b, ":%s", name)
+			} else {
+				fmt.Fprintf(&b, ":%x", reflect.ValueOf(c.Easing).Pointer())
+			}
+		}
+	}
+	return b.String()
+}
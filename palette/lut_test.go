@@ -0,0 +1,126 @@
+package palette
+
+import (
+	"image/color"
+	"testing"
+)
+
+// exact returns a copy of cm that blends its stops, without a table.
+func exact(cm *ColorMap) *ColorMap {
+	cpy := *cm.Frozen()
+	cpy.lut = nil
+	return &cpy
+}
+
+func TestFrozenBuiltinsUseTable(t *testing.T) {
+	for _, name := range List()[:len(ColorPalettes)] {
+		if _, ok := builtinLUTs[name]; !ok {
+			continue // translucent palettes have no table
+		}
+		if Get(name).Frozen().lut == nil {
+			t.Errorf("%s: Frozen copy has no table", name)
+		}
+	}
+}
+
+func TestTableMatchesBlendAtStops(t *testing.T) {
+	for _, name := range List()[:len(ColorPalettes)] {
+		cm := Get(name).Frozen()
+		if cm.lut == nil {
+			continue
+		}
+		want := exact(cm)
+		for i, c := range cm.Colors {
+			for _, step := range []float64{c.Step, c.Step - 1e-9, c.Step + 1e-9} {
+				if got, w := cm.Interpolate(step), want.Interpolate(step); got != w {
+					t.Errorf("%s: stop %d at %v: table gives %v, blend %v", name, i, step, got, w)
+				}
+			}
+		}
+	}
+}
+
+func TestTableWithinAUnitOfBlend(t *testing.T) {
+	diff := func(a, b uint8) int { return max(int(a)-int(b), int(b)-int(a)) }
+	for _, name := range List()[:len(ColorPalettes)] {
+		cm := Get(name).Frozen()
+		if cm.lut == nil {
+			continue
+		}
+		want := exact(cm)
+		for i := range 10001 {
+			x := float64(i) / 10000
+			g, w := cm.Interpolate(x), want.Interpolate(x)
+			if d := max(diff(g.R, w.R), diff(g.G, w.G), diff(g.B, w.B), diff(g.A, w.A)); d > 1 {
+				t.Fatalf("%s at %v: table gives %v, blend %v", name, x, g, w)
+			}
+		}
+	}
+}
+
+func TestGetCopyIgnoresTable(t *testing.T) {
+	// MonochromeSlate's middle stop, #707070 at 0.5, lies between two
+	// table samples, whose straight line gives 0x71 there.
+	cm := Get("MonochromeSlate")
+	if got, want := cm.Interpolate(0.5), (color.RGBA{0x70, 0x70, 0x70, 0xff}); got != want {
+		t.Errorf("Interpolate(0.5) = %v, want %v", got, want)
+	}
+	if got, want := cm.Frozen().Interpolate(0.5), (color.RGBA{0x70, 0x70, 0x70, 0xff}); got != want {
+		t.Errorf("Frozen: Interpolate(0.5) = %v, want %v", got, want)
+	}
+
+	// edits to a copy from Get show
+	cm.Colors[1].Color = color.RGBA{0xff, 0, 0, 0xff}
+	if got, want := cm.Interpolate(0.5), (color.RGBA{0xff, 0, 0, 0xff}); got != want {
+		t.Errorf("after editing the middle stop, Interpolate(0.5) = %v, want %v", got, want)
+	}
+	if got, want := cm.At(128, 0), cm.Interpolate(128.0/255); got != want {
+		t.Errorf("At(128, 0) = %v, want %v", got, want)
+	}
+	// and leave the built-in alone
+	if got, want := Get("MonochromeSlate").Interpolate(0.5), (color.RGBA{0x70, 0x70, 0x70, 0xff}); got != want {
+		t.Errorf("built-in changed: Interpolate(0.5) = %v, want %v", got, want)
+	}
+}
+
+func TestGeneratedTablesCurrent(t *testing.T) {
+	// Every opaque built-in has a table generated from its current stops;
+	// a failure here means go generate ./palette needs running.
+	for _, cm := range ColorPalettes {
+		opaque := true
+		for _, c := range cm.Colors {
+			if _, _, _, a := c.Color.RGBA(); a != 0xffff {
+				opaque = false
+			}
+		}
+		g, ok := builtinLUTs[cm.Keyword]
+		switch {
+		case ok && !opaque:
+			t.Errorf("%s: translucent palette has a table", cm.Keyword)
+		case !ok && opaque:
+			t.Errorf("%s: no generated table", cm.Keyword)
+		case ok && g.fingerprint != cm.Fingerprint():
+			t.Errorf("%s: table generated from other stops", cm.Keyword)
+		}
+	}
+	for name := range builtinLUTs {
+		if builtin(name) == nil {
+			t.Errorf("table for %s, which is not a built-in palette", name)
+		}
+	}
+}
+
+func TestGeneratedSamplesMatchBlend(t *testing.T) {
+	// The samples are the blend at i/255 rounded to 8 bits, so they are
+	// within a unit of a live Interpolate call.
+	diff := func(a, b uint8) int { return max(int(a)-int(b), int(b)-int(a)) }
+	for name, g := range builtinLUTs {
+		want := exact(Get(name))
+		for i, got := range g.table {
+			w := want.Interpolate(float64(i) / (LUTSize - 1))
+			if d := max(diff(got.R, w.R), diff(got.G, w.G), diff(got.B, w.B), diff(got.A, w.A)); d > 1 {
+				t.Errorf("%s: sample %d is %v, Interpolate gives %v", name, i, got, w)
+			}
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package palette
+
+import "image/color"
+
+// ColorMapModel is a color.Model that recolors through a palette: any
+// color converts to the palette's color at its luminance, black to the
+// start of the palette and white to the end. Drawing a grayscale image
+// into an image of this model, or converting each of its pixels, colors
+// it as a render would color its escape counts.
+type ColorMapModel struct {
+	cm *ColorMap
+}
+
+// Model returns the ColorMapModel of cm.
+func (cm *ColorMap) Model() ColorMapModel {
+	return ColorMapModel{cm}
+}
+
+// Convert returns the palette's color, as color.RGBA, at the luminance
+// of c as color.Gray16Model weighs it, scaled to [0,1]. An 8-bit gray
+// with value v lands exactly on v/255. A translucent c counts for its
+// premultiplied channels, so it converts as it looks over black.
+func (m ColorMapModel) Convert(c color.Color) color.Color {
+	y := color.Gray16Model.Convert(c).(color.Gray16).Y
+	return m.cm.Interpolate(float64(y) / 0xffff)
+}
@@ -0,0 +1,52 @@
+package palette
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestColorMapModelGray(t *testing.T) {
+	for _, name := range []string{"NebulaSpectre", "ThermalHeat", "MonochromeSlate"} {
+		cm := Get(name).Frozen()
+		m := cm.Model()
+		if got, want := m.Convert(color.Gray{127}), cm.Interpolate(127.0/255); got != want {
+			t.Errorf("%s: Convert(Gray{127}) = %v, want %v", name, got, want)
+		}
+		if got, want := m.Convert(color.Gray{255}), cm.Interpolate(1); got != want {
+			t.Errorf("%s: Convert(Gray{255}) = %v, want %v", name, got, want)
+		}
+		if got, want := m.Convert(color.Gray{0}), cm.Interpolate(0); got != want {
+			t.Errorf("%s: Convert(Gray{0}) = %v, want %v", name, got, want)
+		}
+		// every 8-bit gray v lands exactly on v/255
+		for v := range 256 {
+			if got, want := m.Convert(color.Gray{uint8(v)}), cm.Interpolate(float64(v)/255); got != want {
+				t.Fatalf("%s: Convert(Gray{%d}) = %v, want %v", name, v, got, want)
+			}
+		}
+	}
+}
+
+func TestColorMapModelColors(t *testing.T) {
+	cm := Get("ThermalHeat")
+	m := cm.Model()
+	// a color converts by its luminance, as Gray16Model weighs it
+	for _, c := range []color.Color{
+		color.RGBA{0xff, 0, 0, 0xff},
+		color.RGBA{0x20, 0xc0, 0x60, 0xff},
+		color.NRGBA{0x80, 0x80, 0xff, 0xff},
+		color.Gray16{0x1234},
+	} {
+		y := color.Gray16Model.Convert(c).(color.Gray16).Y
+		if got, want := m.Convert(c), cm.Interpolate(float64(y)/0xffff); got != want {
+			t.Errorf("Convert(%v) = %v, want %v", c, got, want)
+		}
+	}
+	// a translucent color converts as it looks over black
+	if got, want := m.Convert(color.NRGBA{0xff, 0xff, 0xff, 0x80}), m.Convert(color.Gray{0x80}); got != want {
+		t.Errorf("half-transparent white converts to %v, half gray to %v", got, want)
+	}
+	if _, ok := m.Convert(color.White).(color.RGBA); !ok {
+		t.Error("Convert doesn't return color.RGBA")
+	}
+}
@@ -2,89 +2,191 @@
 package palette
 
 import (
+	"fmt"
 	"image/color"
+	"math"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 )
 
 // Color holds a position (Step 0..1) and a color.
 // If Step is zero for multiple entries, Normalize will evenly distribute.
+// Weight sets how strongly the stop pulls the palette toward its color
+// (see weightedSegT); 0 means 1, the plain linear blend. Easing, if set,
+// reshapes the blend of the segment from this stop to the next.
 type Color struct {
-	Step  float64
-	Color color.Color
+	Step   float64
+	Color  color.Color
+	Weight float64
+	Easing Easing
 }
 
+// NewStop returns a stop at step with the given non-premultiplied
+// components, stored as color.NRGBA. For a == 0xff this is the same color
+// as the equivalent color.RGBA literal.
+func NewStop(step float64, r, g, b, a uint8) Color {
+	return Color{Step: step, Color: color.NRGBA{r, g, b, a}}
+}
+
+// NewStopHex returns a stop at step from a "#RRGGBB" or "#RRGGBBAA" string.
+// The leading '#' is optional; alpha defaults to 0xff.
+func NewStopHex(step float64, hex string) (Color, error) {
+	h := strings.TrimPrefix(hex, "#")
+	if len(h) != 6 && len(h) != 8 {
+		return Color{}, fmt.Errorf("palette: invalid hex color %q: want #RRGGBB or #RRGGBBAA", hex)
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("palette: invalid hex color %q: %w", hex, err)
+	}
+	if len(h) == 6 {
+		v = v<<8 | 0xff
+	}
+	return NewStop(step, uint8(v>>24), uint8(v>>16), uint8(v>>8), uint8(v)), nil
+}
+
+// ColorMap is a named palette: color stops along [0,1].
 type ColorMap struct {
 	Keyword string
 	Colors  []Color
+
+	// ColorblindSafe marks the built-in palettes that ColorblindSafe
+	// passes, so listings needn't run the check.
+	ColorblindSafe bool
+
+	// normalized records that Normalize has run on the map, so later
+	// calls return at once. A new literal starts without it.
+	normalized bool
+
+	// lut is the precomputed table of a built-in palette, which
+	// Interpolate reads instead of blending the stops; see attachLUT.
+	// Only ColorPalettes and Frozen copies have one.
+	lut *attachedLUT
 }
 
 // ColorPalettes contains palettes you can choose from. All steps should ideally be in range [0,1].
-// If some entries have Step==0 they will be normalized at runtime by Normalize().
+// Entries with Step==0 are filled in by Normalize when the package loads.
 var ColorPalettes = []ColorMap{
-	{"NebulaSpectre", []Color{
-		{0.0,  color.RGBA{0x09, 0x04, 0x20, 0xff}}, // deep violet
-		{0.15, color.RGBA{0x3A, 0x0F, 0x73, 0xff}}, // purple
-		{0.35, color.RGBA{0x8D, 0x1A, 0xA8, 0xff}}, // magenta
-		{0.55, color.RGBA{0xE7, 0x36, 0x7F, 0xff}}, // hot pink
-		{0.75, color.RGBA{0x3B, 0xD6, 0xC2, 0xff}}, // cyan–teal
-		{1.0,  color.RGBA{0xF0, 0xFF, 0xFF, 0xff}}, // bright highlight
+	{Keyword: "NebulaSpectre", ColorblindSafe: true, Colors: []Color{
+		NewStop(0.0, 0x09, 0x04, 0x20, 0xff),  // deep violet
+		NewStop(0.15, 0x3A, 0x0F, 0x73, 0xff), // purple
+		NewStop(0.35, 0x8D, 0x1A, 0xA8, 0xff), // magenta
+		NewStop(0.55, 0xE7, 0x36, 0x7F, 0xff), // hot pink
+		NewStop(0.75, 0x3B, 0xD6, 0xC2, 0xff), // cyan–teal
+		NewStop(1.0, 0xF0, 0xFF, 0xFF, 0xff),  // bright highlight
 	}},
 
-	{"MonochromeSlate", []Color{
-		{0.0, color.RGBA{0x00, 0x00, 0x00, 0xff}},
-		{0.5, color.RGBA{0x70, 0x70, 0x70, 0xff}},
-		{1.0, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+	{Keyword: "MonochromeSlate", ColorblindSafe: true, Colors: []Color{
+		NewStop(0.0, 0x00, 0x00, 0x00, 0xff),
+		NewStop(0.5, 0x70, 0x70, 0x70, 0xff),
+		NewStop(1.0, 0xff, 0xff, 0xff, 0xff),
 	}},
 
-	{"MetallicChrome", []Color{
-		{0.0, color.RGBA{0x06, 0x0b, 0x14, 0xff}},
-		{0.2, color.RGBA{0x3a, 0x3f, 0x45, 0xff}},
-		{0.45, color.RGBA{0x9e, 0xae, 0xb4, 0xff}},
-		{0.7, color.RGBA{0xe7, 0xd8, 0xb0, 0xff}},
-		{1.0, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+	{Keyword: "MetallicChrome", ColorblindSafe: true, Colors: []Color{
+		NewStop(0.0, 0x06, 0x0b, 0x14, 0xff),
+		NewStop(0.2, 0x3a, 0x3f, 0x45, 0xff),
+		NewStop(0.45, 0x9e, 0xae, 0xb4, 0xff),
+		NewStop(0.7, 0xe7, 0xd8, 0xb0, 0xff),
+		NewStop(1.0, 0xff, 0xff, 0xff, 0xff),
 	}},
 
-	{"ThermalHeat", []Color{
-		{0.0, color.RGBA{0x00, 0x00, 0x00, 0xff}},
-		{0.25, color.RGBA{0x70, 0x00, 0x00, 0xff}},
-		{0.5, color.RGBA{0xff, 0x40, 0x00, 0xff}},
-		{0.75, color.RGBA{0xff, 0xd0, 0x00, 0xff}},
-		{1.0, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+	{Keyword: "ThermalHeat", ColorblindSafe: true, Colors: []Color{
+		NewStop(0.0, 0x00, 0x00, 0x00, 0xff),
+		NewStop(0.25, 0x70, 0x00, 0x00, 0xff),
+		NewStop(0.5, 0xff, 0x40, 0x00, 0xff),
+		NewStop(0.75, 0xff, 0xd0, 0x00, 0xff),
+		NewStop(1.0, 0xff, 0xff, 0xff, 0xff),
 	}},
 
-	{"AuroraArc", []Color{
-		{0.0, color.RGBA{0x01, 0x13, 0x1f, 0xff}},
-		{0.2, color.RGBA{0x03, 0x6b, 0x5f, 0xff}},
-		{0.45, color.RGBA{0x54, 0xe6, 0xb2, 0xff}},
-		{0.7, color.RGBA{0x95, 0x43, 0xd6, 0xff}},
-		{1.0, color.RGBA{0xf8, 0xf9, 0xff, 0xff}},
+	{Keyword: "AuroraArc", Colors: []Color{
+		NewStop(0.0, 0x01, 0x13, 0x1f, 0xff),
+		NewStop(0.2, 0x03, 0x6b, 0x5f, 0xff),
+		NewStop(0.45, 0x54, 0xe6, 0xb2, 0xff),
+		NewStop(0.7, 0x95, 0x43, 0xd6, 0xff),
+		NewStop(1.0, 0xf8, 0xf9, 0xff, 0xff),
 	}},
 }
 
+func init() {
+	for i := range ColorPalettes {
+		Normalize(&ColorPalettes[i])
+		attachLUT(&ColorPalettes[i])
+	}
+}
+
+// List returns the keywords of the built-in palettes in declaration order,
+// followed by any registered ones in registration order.
+func List() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	names := make([]string, 0, len(ColorPalettes)+len(registry.maps))
+	for _, p := range ColorPalettes {
+		names = append(names, p.Keyword)
+	}
+	for _, p := range registry.maps {
+		names = append(names, p.Keyword)
+	}
+	return names
+}
+
 // Get returns the ColorMap by keyword (case-sensitive) or nil if not found.
+// Built-in palettes are searched first, then registered ones.
 func Get(keyword string) *ColorMap {
-	for i := range ColorPalettes {
-		if ColorPalettes[i].Keyword == keyword {
-			// return a copy so callers can mutate returned Colors/normalize safely
-			cpy := ColorPalettes[i]
-			Normalize(&cpy)
-			return &cpy
-		}
+	if p := builtin(keyword); p != nil {
+		// return a copy so callers can mutate returned Colors safely; the
+		// built-ins were normalized at init, so Normalize is a no-op
+		// unless one was added to ColorPalettes since. The copy leaves the
+		// precomputed table behind, as its stops may change; Frozen
+		// attaches it again.
+		cpy := *p
+		cpy.Colors = slices.Clone(p.Colors)
+		cpy.lut = nil
+		Normalize(&cpy)
+		return &cpy
+	}
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	if p := registered(keyword); p != nil {
+		cpy := *p
+		cpy.Colors = slices.Clone(p.Colors)
+		return &cpy
 	}
 	return nil
 }
 
 // Normalize fills in missing Step values (Step == 0) by evenly spacing them.
 // It also ensures first and last steps are 0 and 1 respectively if they are unspecified.
+// A NaN Step counts as missing. A negative Step is relative: -0.1 places
+// the stop 0.1 before the next stop with a positive Step, or before 1 if
+// none follows, and it then counts as fixed even if that lands on 0.
+// Afterwards every Step is within [0,1] and the stops are sorted by Step.
+//
+// The map remembers that it was normalized, and normalizing it again
+// does nothing. Changing the Colors of a normalized map in place is not
+// noticed; build a new ColorMap from them instead.
 func Normalize(cm *ColorMap) {
-	if cm == nil || len(cm.Colors) == 0 {
+	if cm == nil || len(cm.Colors) == 0 || cm.normalized {
 		return
 	}
+	normalize(cm)
+	cm.normalized = true
+}
+
+// normalize is Normalize without the check for an earlier run.
+func normalize(cm *ColorMap) {
+	for i := range cm.Colors {
+		if math.IsNaN(cm.Colors[i].Step) {
+			cm.Colors[i].Step = 0
+		}
+	}
+	relative := resolveRelativeSteps(cm)
 
 	// If every Color has a non-zero Step, just sort and clamp.
 	allSpecified := true
-	for _, c := range cm.Colors {
-		if c.Step == 0 {
+	for i, c := range cm.Colors {
+		if c.Step == 0 && !relative[i] {
 			allSpecified = false
 			break
 		}
@@ -112,10 +214,7 @@ func Normalize(cm *ColorMap) {
 	}
 	var fixed []idxStep
 	for i, c := range cm.Colors {
-		if c.Step > 0 {
-			if c.Step < 0 {
-				c.Step = 0
-			}
+		if c.Step > 0 || relative[i] {
 			if c.Step > 1 {
 				c.Step = 1
 			}
@@ -124,6 +223,10 @@ func Normalize(cm *ColorMap) {
 	}
 	// If no fixed points, evenly space from 0..1
 	if len(fixed) == 0 {
+		if n == 1 {
+			cm.Colors[0].Step = 0
+			return
+		}
 		for i := range cm.Colors {
 			cm.Colors[i].Step = float64(i) / float64(n-1)
 		}
@@ -158,25 +261,59 @@ func Normalize(cm *ColorMap) {
 	sort.Slice(cm.Colors, func(i, j int) bool { return cm.Colors[i].Step < cm.Colors[j].Step })
 }
 
+// resolveRelativeSteps replaces each negative Step with its offset from
+// the next positive Step after it, or from 1, clamped to [0,1], and
+// reports which stops it resolved. Offsets are taken from the steps as
+// given, so a run of relative stops all count back from the same one.
+func resolveRelativeSteps(cm *ColorMap) []bool {
+	resolved := make([]bool, len(cm.Colors))
+	next := 1.0
+	for i := len(cm.Colors) - 1; i >= 0; i-- {
+		switch step := cm.Colors[i].Step; {
+		case step > 0:
+			next = min(step, 1)
+		case step < 0:
+			cm.Colors[i].Step = max(next+step, 0)
+			resolved[i] = true
+		}
+	}
+	return resolved
+}
+
 // Interpolate returns an interpolated color for t in [0,1] across the ColorMap.
 // If t <= first step returns first color, if t >= last returns last.
+// A NaN t, which a degenerate smoothing formula can produce, gives the
+// first color too, rather than passing for the end of the palette.
+// The built-in palettes, and Frozen copies of them, are read from
+// tables generated from their stops (see palettes_gen.go), which may
+// differ from the exact blend by a unit per channel between stops; at
+// and around the stops themselves the blend is exact.
 func (cm *ColorMap) Interpolate(t float64) color.RGBA {
 	if cm == nil || len(cm.Colors) == 0 {
 		return color.RGBA{0, 0, 0, 0xff}
 	}
-	if t <= 0 {
+	if t <= 0 || math.IsNaN(t) {
 		return toRGBA(cm.Colors[0].Color)
 	}
 	if t >= 1 {
 		return toRGBA(cm.Colors[len(cm.Colors)-1].Color)
 	}
+	if cm.lut != nil {
+		if c, ok := cm.lut.at(t); ok {
+			return c
+		}
+	}
 
 	// find interval
 	for i := 0; i < len(cm.Colors)-1; i++ {
 		a := cm.Colors[i]
 		b := cm.Colors[i+1]
 		if t >= a.Step && t <= b.Step {
-			segT := (t - a.Step) / (b.Step - a.Step)
+			if b.Step <= a.Step {
+				// zero-width segment: a hard edge between two stops
+				return toRGBA(b.Color)
+			}
+			segT := easedSegT(t, a, b)
 			return lerpRGBA(toRGBA(a.Color), toRGBA(b.Color), segT)
 		}
 	}
@@ -184,11 +321,93 @@ func (cm *ColorMap) Interpolate(t float64) color.RGBA {
 	return toRGBA(cm.Colors[len(cm.Colors)-1].Color)
 }
 
+// InterpolateNRGBA is Interpolate with non-premultiplied output. It blends
+// the stops' straight-alpha components, so a translucent stop keeps its
+// color rather than darkening toward black. For opaque stops it returns
+// the same values as Interpolate.
+func (cm *ColorMap) InterpolateNRGBA(t float64) color.NRGBA {
+	if cm == nil || len(cm.Colors) == 0 {
+		return color.NRGBA{0, 0, 0, 0xff}
+	}
+	if t <= 0 || math.IsNaN(t) {
+		return toNRGBA(cm.Colors[0].Color)
+	}
+	if t >= 1 {
+		return toNRGBA(cm.Colors[len(cm.Colors)-1].Color)
+	}
+	if cm.lut != nil {
+		// tables are only generated for opaque palettes, whose colors
+		// are the same either way
+		if c, ok := cm.lut.at(t); ok {
+			return color.NRGBA(c)
+		}
+	}
+	for i := 0; i < len(cm.Colors)-1; i++ {
+		a := cm.Colors[i]
+		b := cm.Colors[i+1]
+		if t >= a.Step && t <= b.Step {
+			if b.Step <= a.Step {
+				return toNRGBA(b.Color)
+			}
+			segT := easedSegT(t, a, b)
+			p := lerpRGBA(color.RGBA(toNRGBA(a.Color)), color.RGBA(toNRGBA(b.Color)), segT)
+			return color.NRGBA(p)
+		}
+	}
+	return toNRGBA(cm.Colors[len(cm.Colors)-1].Color)
+}
+
+// easedSegT returns how far t lies along the segment from stop a to stop
+// b: the position weightedSegT gives, then reshaped by a's Easing.
+func easedSegT(t float64, a, b Color) float64 {
+	segT := weightedSegT(t, a.Step, b.Step, a.Weight, b.Weight)
+	if a.Easing != nil {
+		segT = a.Easing(segT)
+	}
+	return segT
+}
+
+// weightedSegT returns how far t lies between the stops at aStep and
+// bStep, bent toward the heavier stop: segT^(2·aWeight/(aWeight+bWeight)).
+// Equal weights leave the plain linear position. A heavier b gives an
+// exponent below 1, which moves t toward b, so b's color covers more of
+// the segment. Against a stop of weight 1, a weight of 3 leaves b's color
+// nearer for three quarters of it. Weights of 0 or less count as 1.
+func weightedSegT(t, aStep, bStep, aWeight, bWeight float64) float64 {
+	segT := (t - aStep) / (bStep - aStep)
+	if aWeight <= 0 {
+		aWeight = 1
+	}
+	if bWeight <= 0 {
+		bWeight = 1
+	}
+	if aWeight == bWeight {
+		return segT
+	}
+	return math.Pow(segT, 2*aWeight/(aWeight+bWeight))
+}
+
+// toNRGBA converts a color.Color to non-premultiplied color.NRGBA.
+func toNRGBA(c color.Color) color.NRGBA {
+	if n, ok := c.(color.NRGBA); ok {
+		return n
+	}
+	return color.NRGBAModel.Convert(c).(color.NRGBA)
+}
+
 // toRGBA converts a color.Color to color.RGBA (with premultiplied alpha normalized).
 func toRGBA(c color.Color) color.RGBA {
 	r, g, b, a := c.RGBA()
-	// color.Color returns values in 0..65535, convert to 0..255
-	return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	return color.RGBA{round16(r), round16(g), round16(b), round16(a)}
+}
+
+// round16 scales a 0..65535 channel to the nearest 0..255 value. Eight-bit
+// colors come out unchanged either way, as RGBA returns v*257 for them,
+// but a >> 8 would bias the rest down, such as the premultiplied channels
+// of a translucent stop. v+128 >> 8 is no good either: it gives 256 for
+// white.
+func round16(v uint32) uint8 {
+	return uint8((v + 128) / 257)
 }
 
 // lerpRGBA linearly interpolates between two RGBA colors in sRGB space.
@@ -199,11 +418,13 @@ func lerpRGBA(a, b color.RGBA, t float64) color.RGBA {
 	if t >= 1 {
 		return b
 	}
+	// round rather than truncate: blending two equal channels must give
+	// that value back, or opaque stops come out with alpha 254
 	return color.RGBA{
-		uint8(clamp((1-t)*float64(a.R)+t*float64(b.R), 0, 255)),
-		uint8(clamp((1-t)*float64(a.G)+t*float64(b.G), 0, 255)),
-		uint8(clamp((1-t)*float64(a.B)+t*float64(b.B), 0, 255)),
-		uint8(clamp((1-t)*float64(a.A)+t*float64(b.A), 0, 255)),
+		uint8(clamp(math.Round((1-t)*float64(a.R)+t*float64(b.R)), 0, 255)),
+		uint8(clamp(math.Round((1-t)*float64(a.G)+t*float64(b.G)), 0, 255)),
+		uint8(clamp(math.Round((1-t)*float64(a.B)+t*float64(b.B)), 0, 255)),
+		uint8(clamp(math.Round((1-t)*float64(a.A)+t*float64(b.A)), 0, 255)),
 	}
 }
 
@@ -216,4 +437,3 @@ func clamp(v, lo, hi float64) float64 {
 	}
 	return v
 }
-
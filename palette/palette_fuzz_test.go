@@ -0,0 +1,81 @@
+package palette
+
+import (
+	"encoding/binary"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// fuzzStopSize is the bytes of one stop in FuzzNormalize's input: the
+// step as a little-endian float32, then R, G, B and A, then the weight
+// in sixteenths.
+const fuzzStopSize = 9
+
+// encodeStops is the inverse of decodeStops, for seeding the corpus.
+func encodeStops(stops []Color) []byte {
+	b := []byte{byte(len(stops))}
+	for _, s := range stops {
+		n := color.NRGBAModel.Convert(s.Color).(color.NRGBA)
+		b = binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(s.Step)))
+		b = append(b, n.R, n.G, n.B, n.A, byte(s.Weight*16))
+	}
+	return b
+}
+
+// decodeStops reads the stops of data: a count, then that many stops of
+// fuzzStopSize bytes, as many as there are bytes for.
+func decodeStops(data []byte) []Color {
+	if len(data) == 0 {
+		return nil
+	}
+	n := min(int(data[0]), (len(data)-1)/fuzzStopSize)
+	stops := make([]Color, n)
+	for i := range stops {
+		g := data[1+i*fuzzStopSize:]
+		stops[i] = Color{
+			Step:   float64(math.Float32frombits(binary.LittleEndian.Uint32(g))),
+			Color:  color.NRGBA{g[4], g[5], g[6], g[7]},
+			Weight: float64(g[8]) / 16,
+		}
+	}
+	return stops
+}
+
+func FuzzNormalize(f *testing.F) {
+	for _, p := range ColorPalettes {
+		f.Add(encodeStops(p.Colors))
+	}
+	red, blue := color.NRGBA{0xff, 0, 0, 0xff}, color.NRGBA{0, 0, 0xff, 0xff}
+	for _, stops := range [][]Color{
+		nil,
+		{{Step: 0.3, Color: red}},
+		{{Color: red}, {Color: blue}, {Color: red}},
+		{{Step: 0.5, Color: red}, {Step: 0.5, Color: blue}, {Step: 0.5, Color: red}},
+		{{Step: -0.4, Color: red}, {Step: 1.7, Color: blue}, {Step: -3, Color: red}},
+		{{Step: math.NaN(), Color: red}, {Step: math.Inf(1), Color: blue}, {Step: math.Inf(-1), Color: red}},
+	} {
+		f.Add(encodeStops(stops))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		stops := decodeStops(data)
+		opaque := true
+		for _, s := range stops {
+			opaque = opaque && s.Color.(color.NRGBA).A == 0xff
+		}
+		cm := &ColorMap{Colors: stops}
+		Normalize(cm)
+		for i, s := range cm.Colors {
+			if !(s.Step >= 0 && s.Step <= 1) {
+				t.Fatalf("stop %d: step %v outside [0,1]", i, s.Step)
+			}
+			if i > 0 && s.Step < cm.Colors[i-1].Step {
+				t.Fatalf("stop %d: step %v before stop %d's %v", i, s.Step, i-1, cm.Colors[i-1].Step)
+			}
+		}
+		if c := cm.Interpolate(0.5); opaque && c.A != 0xff {
+			t.Fatalf("Interpolate(0.5) = %v from opaque stops", c)
+		}
+	})
+}
@@ -0,0 +1,330 @@
+package palette
+
+import (
+	"cmp"
+	"encoding/json"
+	"image/color"
+	"math"
+	"slices"
+	"testing"
+)
+
+// luminance returns the Rec. 709 luminance of c's channels, 0 to 255.
+func luminance(c color.RGBA) float64 {
+	return 0.2126*float64(c.R) + 0.7152*float64(c.G) + 0.0722*float64(c.B)
+}
+
+func TestInterpolateContinuity(t *testing.T) {
+	const (
+		pairs = 100000
+		dt    = 1e-6
+	)
+	diff := func(a, b uint8) int { return max(int(a)-int(b), int(b)-int(a)) }
+	for _, p := range ColorPalettes {
+		t.Run(p.Keyword, func(t *testing.T) {
+			// blended from the stops, and read from the table
+			for _, cm := range []*ColorMap{Get(p.Keyword), Get(p.Keyword).Frozen()} {
+				for i := range pairs {
+					x := float64(i) / pairs
+					a, b := cm.Interpolate(x), cm.Interpolate(x+dt)
+					if d := max(diff(a.R, b.R), diff(a.G, b.G), diff(a.B, b.B), diff(a.A, b.A)); d > 5 {
+						t.Fatalf("Interpolate(%v) = %v, Interpolate(%v) = %v: jump of %d", x, a, x+dt, b, d)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestInterpolateMonotoneLuminance(t *testing.T) {
+	// the palettes that run from dark to light; the request also named
+	// GrayscaleLog, which isn't a built-in
+	for _, name := range []string{"MonochromeSlate", "ThermalHeat"} {
+		t.Run(name, func(t *testing.T) {
+			for _, cm := range []*ColorMap{Get(name), Get(name).Frozen()} {
+				last := -1.0
+				for i := range 100001 {
+					x := float64(i) / 100000
+					if l := luminance(cm.Interpolate(x)); l < last {
+						t.Fatalf("luminance falls from %v to %v at %v", last, l, x)
+					} else {
+						last = l
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNewStopHex(t *testing.T) {
+	for _, tc := range []struct {
+		hex  string
+		want color.NRGBA
+	}{
+		{"#FF8800", color.NRGBA{255, 136, 0, 255}},
+		{"ff8800", color.NRGBA{255, 136, 0, 255}},
+		{"#FF880080", color.NRGBA{255, 136, 0, 128}},
+		{"#000000", color.NRGBA{0, 0, 0, 255}},
+	} {
+		s, err := NewStopHex(0.5, tc.hex)
+		if err != nil {
+			t.Errorf("NewStopHex(%q): %v", tc.hex, err)
+			continue
+		}
+		if s.Step != 0.5 || s.Color != tc.want {
+			t.Errorf("NewStopHex(%q) = %v at %v, want %v at 0.5", tc.hex, s.Color, s.Step, tc.want)
+		}
+	}
+	for _, hex := range []string{"#FF8800ZZ", "#FF88", "", "#", "#GG8800", "#FF8800FF00"} {
+		if _, err := NewStopHex(0.5, hex); err == nil {
+			t.Errorf("NewStopHex(%q): no error", hex)
+		}
+	}
+}
+
+func TestInterpolateNRGBA(t *testing.T) {
+	cm := &ColorMap{Colors: []Color{NewStop(0, 0xff, 0, 0, 0x40), NewStop(1, 0, 0, 0xff, 0x40)}}
+	Normalize(cm)
+	// a translucent stop keeps its color, where premultiplied it darkens
+	if got, want := cm.InterpolateNRGBA(0), (color.NRGBA{0xff, 0, 0, 0x40}); got != want {
+		t.Errorf("InterpolateNRGBA(0) = %v, want %v", got, want)
+	}
+	if got := cm.InterpolateNRGBA(0.5); got.R < 0x7f || got.R > 0x80 || got.B < 0x7f || got.B > 0x80 || got.A != 0x40 {
+		t.Errorf("InterpolateNRGBA(0.5) = %v, want half red, half blue at alpha 0x40", got)
+	}
+	if got := cm.Interpolate(0); got.R != 0x40 || got.A != 0x40 {
+		t.Errorf("Interpolate(0) = %v, want premultiplied red", got)
+	}
+	// opaque palettes agree either way
+	for _, p := range ColorPalettes {
+		for i := range 101 {
+			x := float64(i) / 100
+			if a, b := p.Interpolate(x), p.InterpolateNRGBA(x); color.NRGBA(a) != b {
+				t.Fatalf("%s at %v: Interpolate %v, InterpolateNRGBA %v", p.Keyword, x, a, b)
+			}
+		}
+	}
+}
+
+func TestGetPrenormalized(t *testing.T) {
+	for _, p := range ColorPalettes {
+		got := Get(p.Keyword)
+		if !got.normalized {
+			t.Errorf("%s: Get returned a map not marked normalized", p.Keyword)
+		}
+		// a map that has never been normalized, from the same stops
+		fresh := &ColorMap{Keyword: p.Keyword, Colors: slices.Clone(p.Colors)}
+		Normalize(fresh)
+		for i := range 10001 {
+			x := float64(i) / 10000
+			if a, b := got.Interpolate(x), fresh.Interpolate(x); a != b {
+				t.Fatalf("%s at %v: Get gives %v, a fresh Normalize %v", p.Keyword, x, a, b)
+			}
+		}
+	}
+}
+
+func TestNormalizeOnce(t *testing.T) {
+	cm := &ColorMap{Colors: []Color{NewStop(0.8, 0xff, 0, 0, 0xff), NewStop(0.2, 0, 0, 0xff, 0xff)}}
+	Normalize(cm)
+	if cm.Colors[0].Step != 0.2 {
+		t.Fatalf("stops not sorted: %v", cm.Colors)
+	}
+	// the flag short-circuits a second call, even after the stops change
+	cm.Colors[0], cm.Colors[1] = cm.Colors[1], cm.Colors[0]
+	Normalize(cm)
+	if cm.Colors[0].Step != 0.8 {
+		t.Errorf("the second Normalize sorted again: %v", cm.Colors)
+	}
+}
+
+func TestGetReturnsCopy(t *testing.T) {
+	name := ColorPalettes[0].Keyword
+	want := Get(name).Interpolate(0.5)
+	cm := Get(name)
+	for i := range cm.Colors {
+		cm.Colors[i].Color = color.NRGBA{1, 2, 3, 0xff}
+	}
+	if got := Get(name).Interpolate(0.5); got != want {
+		t.Errorf("after editing a returned copy, Interpolate(0.5) = %v, want %v", got, want)
+	}
+}
+
+func TestWeightPullsTowardStop(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	dist := func(c color.RGBA) float64 {
+		dr, dg, db := float64(c.R)-float64(red.R), float64(c.G)-float64(red.G), float64(c.B)-float64(red.B)
+		return math.Sqrt(dr*dr + dg*dg + db*db)
+	}
+	build := func(weight float64) *ColorMap {
+		cm := &ColorMap{Colors: []Color{
+			NewStop(0, 0, 0, 0, 0xff),
+			{Step: 0.5, Color: color.NRGBA(red), Weight: weight},
+			NewStop(1, 0, 0, 0xff, 0xff),
+		}}
+		Normalize(cm)
+		return cm
+	}
+	plain, heavy := build(0), build(2)
+	for _, x := range []float64{0.3, 0.4, 0.6, 0.7} {
+		if a, b := dist(heavy.Interpolate(x)), dist(plain.Interpolate(x)); !(a < b) {
+			t.Errorf("Interpolate(%v): %v from red weighted, %v unweighted", x, a, b)
+		}
+	}
+	// weight 1 is the same as none
+	for i := range 101 {
+		x := float64(i) / 100
+		if a, b := build(1).Interpolate(x), plain.Interpolate(x); a != b {
+			t.Fatalf("Interpolate(%v): %v at weight 1, %v unweighted", x, a, b)
+		}
+	}
+}
+
+func TestWeightedSegT(t *testing.T) {
+	for _, tc := range []struct {
+		t, aw, bw float64
+		want      float64
+	}{
+		{0.3, 0, 0, 0.3},
+		{0.3, 1, 1, 0.3},
+		{0.3, 2, 2, 0.3},
+		{0, 1, 3, 0},
+		{1, 1, 3, 1},
+		// a weight 3 stop at the end holds its color closer for three
+		// quarters of the segment: the blend reaches the midpoint at a
+		// quarter. At the start the power curve gives it less, about 63%.
+		{0.25, 1, 3, 0.5},
+		{math.Pow(0.5, 2.0/3), 3, 1, 0.5},
+	} {
+		if got := weightedSegT(tc.t, 0, 1, tc.aw, tc.bw); math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("weightedSegT(%v, weights %v, %v) = %v, want %v", tc.t, tc.aw, tc.bw, got, tc.want)
+		}
+	}
+	if got := weightedSegT(0.6, 0.5, 0.7, 1, 1); math.Abs(got-0.5) > 1e-12 {
+		t.Errorf("weightedSegT over [0.5, 0.7] = %v, want 0.5", got)
+	}
+}
+
+func TestToRGBARounding(t *testing.T) {
+	// The request expected color.Gray{127} to come out as 128 once
+	// rounded. It doesn't: RGBA returns 127·257 = 32639 for it, which is
+	// exactly 127, so truncating and rounding agree. Every 8-bit color
+	// maps back to itself; only values between them move.
+	if got := toRGBA(color.Gray{Y: 127}); got != (color.RGBA{127, 127, 127, 255}) {
+		t.Errorf("Gray{127}: %v", got)
+	}
+	for v := range 256 {
+		if got := toRGBA(color.Gray{Y: uint8(v)}); got.R != uint8(v) || got.A != 255 {
+			t.Fatalf("Gray{%d}: %v", v, got)
+		}
+	}
+	// 25829 is 100.502·257, which v >> 8 truncated to 100
+	for _, tc := range []struct {
+		v    uint16
+		want uint8
+	}{
+		{25829, 101}, {25828, 100}, {65535, 255}, {65407, 255}, {65406, 254}, {128, 0}, {129, 1},
+	} {
+		if got := toRGBA(color.RGBA64{tc.v, tc.v, tc.v, 0xffff}); got.R != tc.want || got.G != tc.want || got.B != tc.want {
+			t.Errorf("RGBA64{%d}: %v, want %d", tc.v, got, tc.want)
+		}
+	}
+	// every 16-bit value goes to the nearest 8-bit one
+	for v := range uint32(65536) {
+		want := uint8(math.Round(float64(v) / 257))
+		if got := round16(v); got != want {
+			t.Fatalf("round16(%d) = %d, want %d", v, got, want)
+		}
+	}
+	// a translucent stop's premultiplied channels now round to nearest
+	if got := toRGBA(color.NRGBA{255, 255, 255, 0x80}); got != (color.RGBA{0x80, 0x80, 0x80, 0x80}) {
+		t.Errorf("half-transparent white: %v", got)
+	}
+}
+
+func TestNormalizeRelativeSteps(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		steps []float64
+		want  []float64 // by stop as given
+	}{
+		{"before the last", []float64{0, -0.2, 1}, []float64{0, 0.8, 1}},
+		{"back to the start", []float64{0, -0.5, 0.5, 1}, []float64{0, 0, 0.5, 1}},
+		{"a run counts from the same stop", []float64{0, -0.3, -0.1, 0.9, 1}, []float64{0, 0.6, 0.8, 0.9, 1}},
+		{"from 1 when no stop follows", []float64{0, 0.5, -0.25}, []float64{0, 0.5, 0.75}},
+		{"clamped at 0", []float64{0, -2, 0.5, 1}, []float64{0, 0, 0.5, 1}},
+		{"between unspecified stops", []float64{0, -0.5, 0, 1}, []float64{0, 0.5, 0.75, 1}},
+		{"every step relative", []float64{-1, -0.5}, []float64{0, 0.5}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// each stop's red channel is its position as given, to find it
+			// after the sort
+			cm := &ColorMap{}
+			for i, s := range tc.steps {
+				cm.Colors = append(cm.Colors, NewStop(s, uint8(i), 0, 0, 0xff))
+			}
+			Normalize(cm)
+			got := make([]float64, len(tc.steps))
+			for _, c := range cm.Colors {
+				got[toNRGBA(c.Color).R] = c.Step
+			}
+			for i := range got {
+				if math.Abs(got[i]-tc.want[i]) > 1e-12 {
+					t.Fatalf("steps %v normalize to %v, want %v", tc.steps, got, tc.want)
+				}
+			}
+			if !slices.IsSortedFunc(cm.Colors, func(a, b Color) int { return cmp.Compare(a.Step, b.Step) }) {
+				t.Errorf("not sorted: %v", cm.Colors)
+			}
+		})
+	}
+}
+
+func TestUnmarshalRelativeStep(t *testing.T) {
+	var cm ColorMap
+	in := `{"name": "Dusk", "stops": [{"step": 0, "color": "#000000"}, {"step": -0.2, "color": "#804000"}, {"step": 1, "color": "#ff8000"}]}`
+	if err := json.Unmarshal([]byte(in), &cm); err != nil {
+		t.Fatal(err)
+	}
+	if got := cm.Colors[1].Step; math.Abs(got-0.8) > 1e-12 {
+		t.Errorf("relative stop at %v, want 0.8", got)
+	}
+	for _, step := range []string{"-1.5", "1.5"} {
+		in := `{"name": "Dusk", "stops": [{"step": ` + step + `, "color": "#000000"}]}`
+		if err := json.Unmarshal([]byte(in), &cm); err == nil {
+			t.Errorf("step %s: no error", step)
+		}
+	}
+}
+
+func TestInterpolateNonFinite(t *testing.T) {
+	// NaN (from a smoothing formula gone wrong) gives the first color like
+	// -Inf, rather than the last, which would pass for a real value.
+	for _, name := range List() {
+		for _, cm := range []*ColorMap{Get(name), Get(name).Frozen()} {
+			first, last := cm.Colors[0].Color, cm.Colors[len(cm.Colors)-1].Color
+			for _, tc := range []struct {
+				t    float64
+				want color.Color
+			}{
+				{math.NaN(), first},
+				{math.Inf(-1), first},
+				{math.Inf(1), last},
+			} {
+				if got, want := cm.Interpolate(tc.t), toRGBA(tc.want); got != want {
+					t.Errorf("%s: Interpolate(%v) = %v, want %v", name, tc.t, got, want)
+				}
+				if got, want := cm.InterpolateNRGBA(tc.t), toNRGBA(tc.want); got != want {
+					t.Errorf("%s: InterpolateNRGBA(%v) = %v, want %v", name, tc.t, got, want)
+				}
+			}
+		}
+	}
+
+	var nilMap *ColorMap
+	for _, x := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if got, want := nilMap.Interpolate(x), (color.RGBA{0, 0, 0, 0xff}); got != want {
+			t.Errorf("nil map: Interpolate(%v) = %v, want %v", x, got, want)
+		}
+	}
+}
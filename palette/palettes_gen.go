@@ -0,0 +1,353 @@
+// Code generated by go run ../cmd/validatepalettes -lut palettes_gen.go; DO NOT EDIT.
+
+package palette
+
+// builtinLUTs holds the lookup table of each opaque built-in palette,
+// by keyword; see attachLUT.
+var builtinLUTs = map[string]generatedLUT{
+	"NebulaSpectre": {
+		fingerprint: "0:#090420:0 3fc3333333333333:#3a0f73:0 3fd6666666666666:#8d1aa8:0 3fe199999999999a:#e7367f:0 3fe8000000000000:#3bd6c2:0 3ff0000000000000:#f0ffff:0",
+		table: &lut{
+			{0x09, 0x04, 0x20, 0xff}, {0x0a, 0x04, 0x22, 0xff}, {0x0c, 0x05, 0x24, 0xff}, {0x0d, 0x05, 0x27, 0xff},
+			{0x0e, 0x05, 0x29, 0xff}, {0x0f, 0x05, 0x2b, 0xff}, {0x11, 0x06, 0x2d, 0xff}, {0x12, 0x06, 0x2f, 0xff},
+			{0x13, 0x06, 0x31, 0xff}, {0x15, 0x07, 0x34, 0xff}, {0x16, 0x07, 0x36, 0xff}, {0x17, 0x07, 0x38, 0xff},
+			{0x18, 0x07, 0x3a, 0xff}, {0x1a, 0x08, 0x3c, 0xff}, {0x1b, 0x08, 0x3e, 0xff}, {0x1c, 0x08, 0x41, 0xff},
+			{0x1d, 0x09, 0x43, 0xff}, {0x1f, 0x09, 0x45, 0xff}, {0x20, 0x09, 0x47, 0xff}, {0x21, 0x09, 0x49, 0xff},
+			{0x23, 0x0a, 0x4b, 0xff}, {0x24, 0x0a, 0x4e, 0xff}, {0x25, 0x0a, 0x50, 0xff}, {0x26, 0x0b, 0x52, 0xff},
+			{0x28, 0x0b, 0x54, 0xff}, {0x29, 0x0b, 0x56, 0xff}, {0x2a, 0x0b, 0x58, 0xff}, {0x2c, 0x0c, 0x5b, 0xff},
+			{0x2d, 0x0c, 0x5d, 0xff}, {0x2e, 0x0c, 0x5f, 0xff}, {0x2f, 0x0d, 0x61, 0xff}, {0x31, 0x0d, 0x63, 0xff},
+			{0x32, 0x0d, 0x65, 0xff}, {0x33, 0x0d, 0x68, 0xff}, {0x35, 0x0e, 0x6a, 0xff}, {0x36, 0x0e, 0x6c, 0xff},
+			{0x37, 0x0e, 0x6e, 0xff}, {0x38, 0x0f, 0x70, 0xff}, {0x3a, 0x0f, 0x72, 0xff}, {0x3b, 0x0f, 0x74, 0xff},
+			{0x3d, 0x0f, 0x75, 0xff}, {0x3e, 0x10, 0x76, 0xff}, {0x40, 0x10, 0x77, 0xff}, {0x42, 0x10, 0x78, 0xff},
+			{0x43, 0x10, 0x79, 0xff}, {0x45, 0x10, 0x7a, 0xff}, {0x47, 0x11, 0x7b, 0xff}, {0x48, 0x11, 0x7c, 0xff},
+			{0x4a, 0x11, 0x7d, 0xff}, {0x4b, 0x11, 0x7e, 0xff}, {0x4d, 0x12, 0x7f, 0xff}, {0x4f, 0x12, 0x80, 0xff},
+			{0x50, 0x12, 0x81, 0xff}, {0x52, 0x12, 0x82, 0xff}, {0x54, 0x12, 0x83, 0xff}, {0x55, 0x13, 0x84, 0xff},
+			{0x57, 0x13, 0x85, 0xff}, {0x59, 0x13, 0x86, 0xff}, {0x5a, 0x13, 0x88, 0xff}, {0x5c, 0x13, 0x89, 0xff},
+			{0x5d, 0x14, 0x8a, 0xff}, {0x5f, 0x14, 0x8b, 0xff}, {0x61, 0x14, 0x8c, 0xff}, {0x62, 0x14, 0x8d, 0xff},
+			{0x64, 0x15, 0x8e, 0xff}, {0x66, 0x15, 0x8f, 0xff}, {0x67, 0x15, 0x90, 0xff}, {0x69, 0x15, 0x91, 0xff},
+			{0x6a, 0x15, 0x92, 0xff}, {0x6c, 0x16, 0x93, 0xff}, {0x6e, 0x16, 0x94, 0xff}, {0x6f, 0x16, 0x95, 0xff},
+			{0x71, 0x16, 0x96, 0xff}, {0x73, 0x16, 0x97, 0xff}, {0x74, 0x17, 0x98, 0xff}, {0x76, 0x17, 0x99, 0xff},
+			{0x77, 0x17, 0x9a, 0xff}, {0x79, 0x17, 0x9b, 0xff}, {0x7b, 0x18, 0x9c, 0xff}, {0x7c, 0x18, 0x9d, 0xff},
+			{0x7e, 0x18, 0x9e, 0xff}, {0x80, 0x18, 0x9f, 0xff}, {0x81, 0x18, 0xa0, 0xff}, {0x83, 0x19, 0xa2, 0xff},
+			{0x84, 0x19, 0xa3, 0xff}, {0x86, 0x19, 0xa4, 0xff}, {0x88, 0x19, 0xa5, 0xff}, {0x89, 0x1a, 0xa6, 0xff},
+			{0x8b, 0x1a, 0xa7, 0xff}, {0x8d, 0x1a, 0xa8, 0xff}, {0x8e, 0x1a, 0xa7, 0xff}, {0x90, 0x1b, 0xa7, 0xff},
+			{0x92, 0x1c, 0xa6, 0xff}, {0x94, 0x1c, 0xa5, 0xff}, {0x95, 0x1d, 0xa4, 0xff}, {0x97, 0x1d, 0xa3, 0xff},
+			{0x99, 0x1e, 0xa3, 0xff}, {0x9b, 0x1e, 0xa2, 0xff}, {0x9c, 0x1f, 0xa1, 0xff}, {0x9e, 0x1f, 0xa0, 0xff},
+			{0xa0, 0x20, 0x9f, 0xff}, {0xa2, 0x20, 0x9f, 0xff}, {0xa4, 0x21, 0x9e, 0xff}, {0xa5, 0x22, 0x9d, 0xff},
+			{0xa7, 0x22, 0x9c, 0xff}, {0xa9, 0x23, 0x9b, 0xff}, {0xab, 0x23, 0x9b, 0xff}, {0xac, 0x24, 0x9a, 0xff},
+			{0xae, 0x24, 0x99, 0xff}, {0xb0, 0x25, 0x98, 0xff}, {0xb2, 0x25, 0x97, 0xff}, {0xb3, 0x26, 0x97, 0xff},
+			{0xb5, 0x26, 0x96, 0xff}, {0xb7, 0x27, 0x95, 0xff}, {0xb9, 0x28, 0x94, 0xff}, {0xba, 0x28, 0x93, 0xff},
+			{0xbc, 0x29, 0x92, 0xff}, {0xbe, 0x29, 0x92, 0xff}, {0xc0, 0x2a, 0x91, 0xff}, {0xc1, 0x2a, 0x90, 0xff},
+			{0xc3, 0x2b, 0x8f, 0xff}, {0xc5, 0x2b, 0x8e, 0xff}, {0xc7, 0x2c, 0x8e, 0xff}, {0xc9, 0x2d, 0x8d, 0xff},
+			{0xca, 0x2d, 0x8c, 0xff}, {0xcc, 0x2e, 0x8b, 0xff}, {0xce, 0x2e, 0x8a, 0xff}, {0xd0, 0x2f, 0x8a, 0xff},
+			{0xd1, 0x2f, 0x89, 0xff}, {0xd3, 0x30, 0x88, 0xff}, {0xd5, 0x30, 0x87, 0xff}, {0xd7, 0x31, 0x86, 0xff},
+			{0xd8, 0x31, 0x86, 0xff}, {0xda, 0x32, 0x85, 0xff}, {0xdc, 0x33, 0x84, 0xff}, {0xde, 0x33, 0x83, 0xff},
+			{0xdf, 0x34, 0x82, 0xff}, {0xe1, 0x34, 0x82, 0xff}, {0xe3, 0x35, 0x81, 0xff}, {0xe5, 0x35, 0x80, 0xff},
+			{0xe7, 0x36, 0x7f, 0xff}, {0xe4, 0x38, 0x80, 0xff}, {0xe1, 0x3b, 0x81, 0xff}, {0xde, 0x3f, 0x83, 0xff},
+			{0xda, 0x42, 0x84, 0xff}, {0xd7, 0x45, 0x85, 0xff}, {0xd4, 0x48, 0x87, 0xff}, {0xd0, 0x4b, 0x88, 0xff},
+			{0xcd, 0x4e, 0x89, 0xff}, {0xc9, 0x51, 0x8a, 0xff}, {0xc6, 0x55, 0x8c, 0xff}, {0xc3, 0x58, 0x8d, 0xff},
+			{0xbf, 0x5b, 0x8e, 0xff}, {0xbc, 0x5e, 0x90, 0xff}, {0xb9, 0x61, 0x91, 0xff}, {0xb5, 0x64, 0x92, 0xff},
+			{0xb2, 0x67, 0x94, 0xff}, {0xaf, 0x6b, 0x95, 0xff}, {0xab, 0x6e, 0x96, 0xff}, {0xa8, 0x71, 0x98, 0xff},
+			{0xa4, 0x74, 0x99, 0xff}, {0xa1, 0x77, 0x9a, 0xff}, {0x9e, 0x7a, 0x9c, 0xff}, {0x9a, 0x7d, 0x9d, 0xff},
+			{0x97, 0x81, 0x9e, 0xff}, {0x94, 0x84, 0xa0, 0xff}, {0x90, 0x87, 0xa1, 0xff}, {0x8d, 0x8a, 0xa2, 0xff},
+			{0x89, 0x8d, 0xa3, 0xff}, {0x86, 0x90, 0xa5, 0xff}, {0x83, 0x93, 0xa6, 0xff}, {0x7f, 0x96, 0xa7, 0xff},
+			{0x7c, 0x9a, 0xa9, 0xff}, {0x79, 0x9d, 0xaa, 0xff}, {0x75, 0xa0, 0xab, 0xff}, {0x72, 0xa3, 0xad, 0xff},
+			{0x6e, 0xa6, 0xae, 0xff}, {0x6b, 0xa9, 0xaf, 0xff}, {0x68, 0xac, 0xb1, 0xff}, {0x64, 0xb0, 0xb2, 0xff},
+			{0x61, 0xb3, 0xb3, 0xff}, {0x5e, 0xb6, 0xb5, 0xff}, {0x5a, 0xb9, 0xb6, 0xff}, {0x57, 0xbc, 0xb7, 0xff},
+			{0x53, 0xbf, 0xb8, 0xff}, {0x50, 0xc2, 0xba, 0xff}, {0x4d, 0xc6, 0xbb, 0xff}, {0x49, 0xc9, 0xbc, 0xff},
+			{0x46, 0xcc, 0xbe, 0xff}, {0x43, 0xcf, 0xbf, 0xff}, {0x3f, 0xd2, 0xc0, 0xff}, {0x3c, 0xd5, 0xc2, 0xff},
+			{0x3d, 0xd6, 0xc3, 0xff}, {0x40, 0xd7, 0xc4, 0xff}, {0x43, 0xd8, 0xc5, 0xff}, {0x46, 0xd8, 0xc6, 0xff},
+			{0x48, 0xd9, 0xc7, 0xff}, {0x4b, 0xda, 0xc8, 0xff}, {0x4e, 0xda, 0xc8, 0xff}, {0x51, 0xdb, 0xc9, 0xff},
+			{0x54, 0xdc, 0xca, 0xff}, {0x57, 0xdc, 0xcb, 0xff}, {0x5a, 0xdd, 0xcc, 0xff}, {0x5c, 0xde, 0xcd, 0xff},
+			{0x5f, 0xde, 0xce, 0xff}, {0x62, 0xdf, 0xcf, 0xff}, {0x65, 0xdf, 0xd0, 0xff}, {0x68, 0xe0, 0xd1, 0xff},
+			{0x6b, 0xe1, 0xd2, 0xff}, {0x6d, 0xe1, 0xd3, 0xff}, {0x70, 0xe2, 0xd4, 0xff}, {0x73, 0xe3, 0xd5, 0xff},
+			{0x76, 0xe3, 0xd6, 0xff}, {0x79, 0xe4, 0xd7, 0xff}, {0x7c, 0xe5, 0xd8, 0xff}, {0x7e, 0xe5, 0xd9, 0xff},
+			{0x81, 0xe6, 0xda, 0xff}, {0x84, 0xe7, 0xdb, 0xff}, {0x87, 0xe7, 0xdc, 0xff}, {0x8a, 0xe8, 0xdd, 0xff},
+			{0x8d, 0xe8, 0xde, 0xff}, {0x8f, 0xe9, 0xde, 0xff}, {0x92, 0xea, 0xdf, 0xff}, {0x95, 0xea, 0xe0, 0xff},
+			{0x98, 0xeb, 0xe1, 0xff}, {0x9b, 0xec, 0xe2, 0xff}, {0x9e, 0xec, 0xe3, 0xff}, {0xa1, 0xed, 0xe4, 0xff},
+			{0xa3, 0xee, 0xe5, 0xff}, {0xa6, 0xee, 0xe6, 0xff}, {0xa9, 0xef, 0xe7, 0xff}, {0xac, 0xf0, 0xe8, 0xff},
+			{0xaf, 0xf0, 0xe9, 0xff}, {0xb2, 0xf1, 0xea, 0xff}, {0xb4, 0xf1, 0xeb, 0xff}, {0xb7, 0xf2, 0xec, 0xff},
+			{0xba, 0xf3, 0xed, 0xff}, {0xbd, 0xf3, 0xee, 0xff}, {0xc0, 0xf4, 0xef, 0xff}, {0xc3, 0xf5, 0xf0, 0xff},
+			{0xc5, 0xf5, 0xf1, 0xff}, {0xc8, 0xf6, 0xf2, 0xff}, {0xcb, 0xf7, 0xf3, 0xff}, {0xce, 0xf7, 0xf4, 0xff},
+			{0xd1, 0xf8, 0xf4, 0xff}, {0xd4, 0xf9, 0xf5, 0xff}, {0xd6, 0xf9, 0xf6, 0xff}, {0xd9, 0xfa, 0xf7, 0xff},
+			{0xdc, 0xfa, 0xf8, 0xff}, {0xdf, 0xfb, 0xf9, 0xff}, {0xe2, 0xfc, 0xfa, 0xff}, {0xe5, 0xfc, 0xfb, 0xff},
+			{0xe7, 0xfd, 0xfc, 0xff}, {0xea, 0xfe, 0xfd, 0xff}, {0xed, 0xfe, 0xfe, 0xff}, {0xf0, 0xff, 0xff, 0xff},
+		},
+	},
+	"MonochromeSlate": {
+		fingerprint: "0:#000000:0 3fe0000000000000:#707070:0 3ff0000000000000:#ffffff:0",
+		table: &lut{
+			{0x00, 0x00, 0x00, 0xff}, {0x01, 0x01, 0x01, 0xff}, {0x02, 0x02, 0x02, 0xff}, {0x03, 0x03, 0x03, 0xff},
+			{0x04, 0x04, 0x04, 0xff}, {0x04, 0x04, 0x04, 0xff}, {0x05, 0x05, 0x05, 0xff}, {0x06, 0x06, 0x06, 0xff},
+			{0x07, 0x07, 0x07, 0xff}, {0x08, 0x08, 0x08, 0xff}, {0x09, 0x09, 0x09, 0xff}, {0x0a, 0x0a, 0x0a, 0xff},
+			{0x0b, 0x0b, 0x0b, 0xff}, {0x0b, 0x0b, 0x0b, 0xff}, {0x0c, 0x0c, 0x0c, 0xff}, {0x0d, 0x0d, 0x0d, 0xff},
+			{0x0e, 0x0e, 0x0e, 0xff}, {0x0f, 0x0f, 0x0f, 0xff}, {0x10, 0x10, 0x10, 0xff}, {0x11, 0x11, 0x11, 0xff},
+			{0x12, 0x12, 0x12, 0xff}, {0x12, 0x12, 0x12, 0xff}, {0x13, 0x13, 0x13, 0xff}, {0x14, 0x14, 0x14, 0xff},
+			{0x15, 0x15, 0x15, 0xff}, {0x16, 0x16, 0x16, 0xff}, {0x17, 0x17, 0x17, 0xff}, {0x18, 0x18, 0x18, 0xff},
+			{0x19, 0x19, 0x19, 0xff}, {0x19, 0x19, 0x19, 0xff}, {0x1a, 0x1a, 0x1a, 0xff}, {0x1b, 0x1b, 0x1b, 0xff},
+			{0x1c, 0x1c, 0x1c, 0xff}, {0x1d, 0x1d, 0x1d, 0xff}, {0x1e, 0x1e, 0x1e, 0xff}, {0x1f, 0x1f, 0x1f, 0xff},
+			{0x20, 0x20, 0x20, 0xff}, {0x21, 0x21, 0x21, 0xff}, {0x21, 0x21, 0x21, 0xff}, {0x22, 0x22, 0x22, 0xff},
+			{0x23, 0x23, 0x23, 0xff}, {0x24, 0x24, 0x24, 0xff}, {0x25, 0x25, 0x25, 0xff}, {0x26, 0x26, 0x26, 0xff},
+			{0x27, 0x27, 0x27, 0xff}, {0x28, 0x28, 0x28, 0xff}, {0x28, 0x28, 0x28, 0xff}, {0x29, 0x29, 0x29, 0xff},
+			{0x2a, 0x2a, 0x2a, 0xff}, {0x2b, 0x2b, 0x2b, 0xff}, {0x2c, 0x2c, 0x2c, 0xff}, {0x2d, 0x2d, 0x2d, 0xff},
+			{0x2e, 0x2e, 0x2e, 0xff}, {0x2f, 0x2f, 0x2f, 0xff}, {0x2f, 0x2f, 0x2f, 0xff}, {0x30, 0x30, 0x30, 0xff},
+			{0x31, 0x31, 0x31, 0xff}, {0x32, 0x32, 0x32, 0xff}, {0x33, 0x33, 0x33, 0xff}, {0x34, 0x34, 0x34, 0xff},
+			{0x35, 0x35, 0x35, 0xff}, {0x36, 0x36, 0x36, 0xff}, {0x36, 0x36, 0x36, 0xff}, {0x37, 0x37, 0x37, 0xff},
+			{0x38, 0x38, 0x38, 0xff}, {0x39, 0x39, 0x39, 0xff}, {0x3a, 0x3a, 0x3a, 0xff}, {0x3b, 0x3b, 0x3b, 0xff},
+			{0x3c, 0x3c, 0x3c, 0xff}, {0x3d, 0x3d, 0x3d, 0xff}, {0x3d, 0x3d, 0x3d, 0xff}, {0x3e, 0x3e, 0x3e, 0xff},
+			{0x3f, 0x3f, 0x3f, 0xff}, {0x40, 0x40, 0x40, 0xff}, {0x41, 0x41, 0x41, 0xff}, {0x42, 0x42, 0x42, 0xff},
+			{0x43, 0x43, 0x43, 0xff}, {0x44, 0x44, 0x44, 0xff}, {0x45, 0x45, 0x45, 0xff}, {0x45, 0x45, 0x45, 0xff},
+			{0x46, 0x46, 0x46, 0xff}, {0x47, 0x47, 0x47, 0xff}, {0x48, 0x48, 0x48, 0xff}, {0x49, 0x49, 0x49, 0xff},
+			{0x4a, 0x4a, 0x4a, 0xff}, {0x4b, 0x4b, 0x4b, 0xff}, {0x4c, 0x4c, 0x4c, 0xff}, {0x4c, 0x4c, 0x4c, 0xff},
+			{0x4d, 0x4d, 0x4d, 0xff}, {0x4e, 0x4e, 0x4e, 0xff}, {0x4f, 0x4f, 0x4f, 0xff}, {0x50, 0x50, 0x50, 0xff},
+			{0x51, 0x51, 0x51, 0xff}, {0x52, 0x52, 0x52, 0xff}, {0x53, 0x53, 0x53, 0xff}, {0x53, 0x53, 0x53, 0xff},
+			{0x54, 0x54, 0x54, 0xff}, {0x55, 0x55, 0x55, 0xff}, {0x56, 0x56, 0x56, 0xff}, {0x57, 0x57, 0x57, 0xff},
+			{0x58, 0x58, 0x58, 0xff}, {0x59, 0x59, 0x59, 0xff}, {0x5a, 0x5a, 0x5a, 0xff}, {0x5a, 0x5a, 0x5a, 0xff},
+			{0x5b, 0x5b, 0x5b, 0xff}, {0x5c, 0x5c, 0x5c, 0xff}, {0x5d, 0x5d, 0x5d, 0xff}, {0x5e, 0x5e, 0x5e, 0xff},
+			{0x5f, 0x5f, 0x5f, 0xff}, {0x60, 0x60, 0x60, 0xff}, {0x61, 0x61, 0x61, 0xff}, {0x62, 0x62, 0x62, 0xff},
+			{0x62, 0x62, 0x62, 0xff}, {0x63, 0x63, 0x63, 0xff}, {0x64, 0x64, 0x64, 0xff}, {0x65, 0x65, 0x65, 0xff},
+			{0x66, 0x66, 0x66, 0xff}, {0x67, 0x67, 0x67, 0xff}, {0x68, 0x68, 0x68, 0xff}, {0x69, 0x69, 0x69, 0xff},
+			{0x69, 0x69, 0x69, 0xff}, {0x6a, 0x6a, 0x6a, 0xff}, {0x6b, 0x6b, 0x6b, 0xff}, {0x6c, 0x6c, 0x6c, 0xff},
+			{0x6d, 0x6d, 0x6d, 0xff}, {0x6e, 0x6e, 0x6e, 0xff}, {0x6f, 0x6f, 0x6f, 0xff}, {0x70, 0x70, 0x70, 0xff},
+			{0x71, 0x71, 0x71, 0xff}, {0x72, 0x72, 0x72, 0xff}, {0x73, 0x73, 0x73, 0xff}, {0x74, 0x74, 0x74, 0xff},
+			{0x75, 0x75, 0x75, 0xff}, {0x76, 0x76, 0x76, 0xff}, {0x77, 0x77, 0x77, 0xff}, {0x78, 0x78, 0x78, 0xff},
+			{0x7a, 0x7a, 0x7a, 0xff}, {0x7b, 0x7b, 0x7b, 0xff}, {0x7c, 0x7c, 0x7c, 0xff}, {0x7d, 0x7d, 0x7d, 0xff},
+			{0x7e, 0x7e, 0x7e, 0xff}, {0x7f, 0x7f, 0x7f, 0xff}, {0x80, 0x80, 0x80, 0xff}, {0x81, 0x81, 0x81, 0xff},
+			{0x83, 0x83, 0x83, 0xff}, {0x84, 0x84, 0x84, 0xff}, {0x85, 0x85, 0x85, 0xff}, {0x86, 0x86, 0x86, 0xff},
+			{0x87, 0x87, 0x87, 0xff}, {0x88, 0x88, 0x88, 0xff}, {0x89, 0x89, 0x89, 0xff}, {0x8a, 0x8a, 0x8a, 0xff},
+			{0x8b, 0x8b, 0x8b, 0xff}, {0x8d, 0x8d, 0x8d, 0xff}, {0x8e, 0x8e, 0x8e, 0xff}, {0x8f, 0x8f, 0x8f, 0xff},
+			{0x90, 0x90, 0x90, 0xff}, {0x91, 0x91, 0x91, 0xff}, {0x92, 0x92, 0x92, 0xff}, {0x93, 0x93, 0x93, 0xff},
+			{0x94, 0x94, 0x94, 0xff}, {0x96, 0x96, 0x96, 0xff}, {0x97, 0x97, 0x97, 0xff}, {0x98, 0x98, 0x98, 0xff},
+			{0x99, 0x99, 0x99, 0xff}, {0x9a, 0x9a, 0x9a, 0xff}, {0x9b, 0x9b, 0x9b, 0xff}, {0x9c, 0x9c, 0x9c, 0xff},
+			{0x9d, 0x9d, 0x9d, 0xff}, {0x9f, 0x9f, 0x9f, 0xff}, {0xa0, 0xa0, 0xa0, 0xff}, {0xa1, 0xa1, 0xa1, 0xff},
+			{0xa2, 0xa2, 0xa2, 0xff}, {0xa3, 0xa3, 0xa3, 0xff}, {0xa4, 0xa4, 0xa4, 0xff}, {0xa5, 0xa5, 0xa5, 0xff},
+			{0xa6, 0xa6, 0xa6, 0xff}, {0xa8, 0xa8, 0xa8, 0xff}, {0xa9, 0xa9, 0xa9, 0xff}, {0xaa, 0xaa, 0xaa, 0xff},
+			{0xab, 0xab, 0xab, 0xff}, {0xac, 0xac, 0xac, 0xff}, {0xad, 0xad, 0xad, 0xff}, {0xae, 0xae, 0xae, 0xff},
+			{0xaf, 0xaf, 0xaf, 0xff}, {0xb0, 0xb0, 0xb0, 0xff}, {0xb2, 0xb2, 0xb2, 0xff}, {0xb3, 0xb3, 0xb3, 0xff},
+			{0xb4, 0xb4, 0xb4, 0xff}, {0xb5, 0xb5, 0xb5, 0xff}, {0xb6, 0xb6, 0xb6, 0xff}, {0xb7, 0xb7, 0xb7, 0xff},
+			{0xb8, 0xb8, 0xb8, 0xff}, {0xb9, 0xb9, 0xb9, 0xff}, {0xbb, 0xbb, 0xbb, 0xff}, {0xbc, 0xbc, 0xbc, 0xff},
+			{0xbd, 0xbd, 0xbd, 0xff}, {0xbe, 0xbe, 0xbe, 0xff}, {0xbf, 0xbf, 0xbf, 0xff}, {0xc0, 0xc0, 0xc0, 0xff},
+			{0xc1, 0xc1, 0xc1, 0xff}, {0xc2, 0xc2, 0xc2, 0xff}, {0xc4, 0xc4, 0xc4, 0xff}, {0xc5, 0xc5, 0xc5, 0xff},
+			{0xc6, 0xc6, 0xc6, 0xff}, {0xc7, 0xc7, 0xc7, 0xff}, {0xc8, 0xc8, 0xc8, 0xff}, {0xc9, 0xc9, 0xc9, 0xff},
+			{0xca, 0xca, 0xca, 0xff}, {0xcb, 0xcb, 0xcb, 0xff}, {0xcd, 0xcd, 0xcd, 0xff}, {0xce, 0xce, 0xce, 0xff},
+			{0xcf, 0xcf, 0xcf, 0xff}, {0xd0, 0xd0, 0xd0, 0xff}, {0xd1, 0xd1, 0xd1, 0xff}, {0xd2, 0xd2, 0xd2, 0xff},
+			{0xd3, 0xd3, 0xd3, 0xff}, {0xd4, 0xd4, 0xd4, 0xff}, {0xd6, 0xd6, 0xd6, 0xff}, {0xd7, 0xd7, 0xd7, 0xff},
+			{0xd8, 0xd8, 0xd8, 0xff}, {0xd9, 0xd9, 0xd9, 0xff}, {0xda, 0xda, 0xda, 0xff}, {0xdb, 0xdb, 0xdb, 0xff},
+			{0xdc, 0xdc, 0xdc, 0xff}, {0xdd, 0xdd, 0xdd, 0xff}, {0xde, 0xde, 0xde, 0xff}, {0xe0, 0xe0, 0xe0, 0xff},
+			{0xe1, 0xe1, 0xe1, 0xff}, {0xe2, 0xe2, 0xe2, 0xff}, {0xe3, 0xe3, 0xe3, 0xff}, {0xe4, 0xe4, 0xe4, 0xff},
+			{0xe5, 0xe5, 0xe5, 0xff}, {0xe6, 0xe6, 0xe6, 0xff}, {0xe7, 0xe7, 0xe7, 0xff}, {0xe9, 0xe9, 0xe9, 0xff},
+			{0xea, 0xea, 0xea, 0xff}, {0xeb, 0xeb, 0xeb, 0xff}, {0xec, 0xec, 0xec, 0xff}, {0xed, 0xed, 0xed, 0xff},
+			{0xee, 0xee, 0xee, 0xff}, {0xef, 0xef, 0xef, 0xff}, {0xf0, 0xf0, 0xf0, 0xff}, {0xf2, 0xf2, 0xf2, 0xff},
+			{0xf3, 0xf3, 0xf3, 0xff}, {0xf4, 0xf4, 0xf4, 0xff}, {0xf5, 0xf5, 0xf5, 0xff}, {0xf6, 0xf6, 0xf6, 0xff},
+			{0xf7, 0xf7, 0xf7, 0xff}, {0xf8, 0xf8, 0xf8, 0xff}, {0xf9, 0xf9, 0xf9, 0xff}, {0xfb, 0xfb, 0xfb, 0xff},
+			{0xfc, 0xfc, 0xfc, 0xff}, {0xfd, 0xfd, 0xfd, 0xff}, {0xfe, 0xfe, 0xfe, 0xff}, {0xff, 0xff, 0xff, 0xff},
+		},
+	},
+	"MetallicChrome": {
+		fingerprint: "0:#060b14:0 3fc999999999999a:#3a3f45:0 3fdccccccccccccd:#9eaeb4:0 3fe6666666666666:#e7d8b0:0 3ff0000000000000:#ffffff:0",
+		table: &lut{
+			{0x06, 0x0b, 0x14, 0xff}, {0x07, 0x0c, 0x15, 0xff}, {0x08, 0x0d, 0x16, 0xff}, {0x09, 0x0e, 0x17, 0xff},
+			{0x0a, 0x0f, 0x18, 0xff}, {0x0b, 0x10, 0x19, 0xff}, {0x0c, 0x11, 0x1a, 0xff}, {0x0d, 0x12, 0x1b, 0xff},
+			{0x0e, 0x13, 0x1c, 0xff}, {0x0f, 0x14, 0x1d, 0xff}, {0x10, 0x15, 0x1e, 0xff}, {0x11, 0x16, 0x1f, 0xff},
+			{0x12, 0x17, 0x20, 0xff}, {0x13, 0x18, 0x20, 0xff}, {0x14, 0x19, 0x21, 0xff}, {0x15, 0x1a, 0x22, 0xff},
+			{0x16, 0x1b, 0x23, 0xff}, {0x17, 0x1c, 0x24, 0xff}, {0x18, 0x1d, 0x25, 0xff}, {0x19, 0x1e, 0x26, 0xff},
+			{0x1a, 0x1f, 0x27, 0xff}, {0x1b, 0x20, 0x28, 0xff}, {0x1c, 0x21, 0x29, 0xff}, {0x1d, 0x22, 0x2a, 0xff},
+			{0x1e, 0x23, 0x2b, 0xff}, {0x1f, 0x24, 0x2c, 0xff}, {0x21, 0x26, 0x2d, 0xff}, {0x22, 0x27, 0x2e, 0xff},
+			{0x23, 0x28, 0x2f, 0xff}, {0x24, 0x29, 0x30, 0xff}, {0x25, 0x2a, 0x31, 0xff}, {0x26, 0x2b, 0x32, 0xff},
+			{0x27, 0x2c, 0x33, 0xff}, {0x28, 0x2d, 0x34, 0xff}, {0x29, 0x2e, 0x35, 0xff}, {0x2a, 0x2f, 0x36, 0xff},
+			{0x2b, 0x30, 0x37, 0xff}, {0x2c, 0x31, 0x38, 0xff}, {0x2d, 0x32, 0x39, 0xff}, {0x2e, 0x33, 0x39, 0xff},
+			{0x2f, 0x34, 0x3a, 0xff}, {0x30, 0x35, 0x3b, 0xff}, {0x31, 0x36, 0x3c, 0xff}, {0x32, 0x37, 0x3d, 0xff},
+			{0x33, 0x38, 0x3e, 0xff}, {0x34, 0x39, 0x3f, 0xff}, {0x35, 0x3a, 0x40, 0xff}, {0x36, 0x3b, 0x41, 0xff},
+			{0x37, 0x3c, 0x42, 0xff}, {0x38, 0x3d, 0x43, 0xff}, {0x39, 0x3e, 0x44, 0xff}, {0x3a, 0x3f, 0x45, 0xff},
+			{0x3c, 0x41, 0x47, 0xff}, {0x3d, 0x42, 0x48, 0xff}, {0x3f, 0x44, 0x4a, 0xff}, {0x40, 0x46, 0x4c, 0xff},
+			{0x42, 0x48, 0x4e, 0xff}, {0x43, 0x49, 0x4f, 0xff}, {0x45, 0x4b, 0x51, 0xff}, {0x47, 0x4d, 0x53, 0xff},
+			{0x48, 0x4f, 0x55, 0xff}, {0x4a, 0x50, 0x56, 0xff}, {0x4b, 0x52, 0x58, 0xff}, {0x4d, 0x54, 0x5a, 0xff},
+			{0x4e, 0x56, 0x5c, 0xff}, {0x50, 0x57, 0x5d, 0xff}, {0x52, 0x59, 0x5f, 0xff}, {0x53, 0x5b, 0x61, 0xff},
+			{0x55, 0x5d, 0x63, 0xff}, {0x56, 0x5e, 0x64, 0xff}, {0x58, 0x60, 0x66, 0xff}, {0x59, 0x62, 0x68, 0xff},
+			{0x5b, 0x64, 0x6a, 0xff}, {0x5d, 0x65, 0x6b, 0xff}, {0x5e, 0x67, 0x6d, 0xff}, {0x60, 0x69, 0x6f, 0xff},
+			{0x61, 0x6b, 0x71, 0xff}, {0x63, 0x6c, 0x72, 0xff}, {0x64, 0x6e, 0x74, 0xff}, {0x66, 0x70, 0x76, 0xff},
+			{0x67, 0x71, 0x77, 0xff}, {0x69, 0x73, 0x79, 0xff}, {0x6b, 0x75, 0x7b, 0xff}, {0x6c, 0x77, 0x7d, 0xff},
+			{0x6e, 0x78, 0x7e, 0xff}, {0x6f, 0x7a, 0x80, 0xff}, {0x71, 0x7c, 0x82, 0xff}, {0x72, 0x7e, 0x84, 0xff},
+			{0x74, 0x7f, 0x85, 0xff}, {0x76, 0x81, 0x87, 0xff}, {0x77, 0x83, 0x89, 0xff}, {0x79, 0x85, 0x8b, 0xff},
+			{0x7a, 0x86, 0x8c, 0xff}, {0x7c, 0x88, 0x8e, 0xff}, {0x7d, 0x8a, 0x90, 0xff}, {0x7f, 0x8c, 0x92, 0xff},
+			{0x81, 0x8d, 0x93, 0xff}, {0x82, 0x8f, 0x95, 0xff}, {0x84, 0x91, 0x97, 0xff}, {0x85, 0x93, 0x99, 0xff},
+			{0x87, 0x94, 0x9a, 0xff}, {0x88, 0x96, 0x9c, 0xff}, {0x8a, 0x98, 0x9e, 0xff}, {0x8c, 0x9a, 0xa0, 0xff},
+			{0x8d, 0x9b, 0xa1, 0xff}, {0x8f, 0x9d, 0xa3, 0xff}, {0x90, 0x9f, 0xa5, 0xff}, {0x92, 0xa1, 0xa7, 0xff},
+			{0x93, 0xa2, 0xa8, 0xff}, {0x95, 0xa4, 0xaa, 0xff}, {0x97, 0xa6, 0xac, 0xff}, {0x98, 0xa7, 0xad, 0xff},
+			{0x9a, 0xa9, 0xaf, 0xff}, {0x9b, 0xab, 0xb1, 0xff}, {0x9d, 0xad, 0xb3, 0xff}, {0x9e, 0xae, 0xb4, 0xff},
+			{0x9f, 0xaf, 0xb4, 0xff}, {0xa1, 0xaf, 0xb4, 0xff}, {0xa2, 0xb0, 0xb4, 0xff}, {0xa3, 0xb1, 0xb4, 0xff},
+			{0xa4, 0xb1, 0xb4, 0xff}, {0xa5, 0xb2, 0xb4, 0xff}, {0xa6, 0xb3, 0xb4, 0xff}, {0xa7, 0xb3, 0xb3, 0xff},
+			{0xa9, 0xb4, 0xb3, 0xff}, {0xaa, 0xb5, 0xb3, 0xff}, {0xab, 0xb5, 0xb3, 0xff}, {0xac, 0xb6, 0xb3, 0xff},
+			{0xad, 0xb7, 0xb3, 0xff}, {0xae, 0xb7, 0xb3, 0xff}, {0xaf, 0xb8, 0xb3, 0xff}, {0xb1, 0xb9, 0xb3, 0xff},
+			{0xb2, 0xb9, 0xb3, 0xff}, {0xb3, 0xba, 0xb3, 0xff}, {0xb4, 0xbb, 0xb3, 0xff}, {0xb5, 0xbb, 0xb3, 0xff},
+			{0xb6, 0xbc, 0xb3, 0xff}, {0xb7, 0xbd, 0xb3, 0xff}, {0xb9, 0xbd, 0xb3, 0xff}, {0xba, 0xbe, 0xb2, 0xff},
+			{0xbb, 0xbf, 0xb2, 0xff}, {0xbc, 0xbf, 0xb2, 0xff}, {0xbd, 0xc0, 0xb2, 0xff}, {0xbe, 0xc1, 0xb2, 0xff},
+			{0xbf, 0xc1, 0xb2, 0xff}, {0xc1, 0xc2, 0xb2, 0xff}, {0xc2, 0xc3, 0xb2, 0xff}, {0xc3, 0xc3, 0xb2, 0xff},
+			{0xc4, 0xc4, 0xb2, 0xff}, {0xc5, 0xc5, 0xb2, 0xff}, {0xc6, 0xc5, 0xb2, 0xff}, {0xc8, 0xc6, 0xb2, 0xff},
+			{0xc9, 0xc7, 0xb2, 0xff}, {0xca, 0xc7, 0xb2, 0xff}, {0xcb, 0xc8, 0xb2, 0xff}, {0xcc, 0xc9, 0xb1, 0xff},
+			{0xcd, 0xc9, 0xb1, 0xff}, {0xce, 0xca, 0xb1, 0xff}, {0xd0, 0xca, 0xb1, 0xff}, {0xd1, 0xcb, 0xb1, 0xff},
+			{0xd2, 0xcc, 0xb1, 0xff}, {0xd3, 0xcc, 0xb1, 0xff}, {0xd4, 0xcd, 0xb1, 0xff}, {0xd5, 0xce, 0xb1, 0xff},
+			{0xd6, 0xce, 0xb1, 0xff}, {0xd8, 0xcf, 0xb1, 0xff}, {0xd9, 0xd0, 0xb1, 0xff}, {0xda, 0xd0, 0xb1, 0xff},
+			{0xdb, 0xd1, 0xb1, 0xff}, {0xdc, 0xd2, 0xb1, 0xff}, {0xdd, 0xd2, 0xb1, 0xff}, {0xde, 0xd3, 0xb0, 0xff},
+			{0xe0, 0xd4, 0xb0, 0xff}, {0xe1, 0xd4, 0xb0, 0xff}, {0xe2, 0xd5, 0xb0, 0xff}, {0xe3, 0xd6, 0xb0, 0xff},
+			{0xe4, 0xd6, 0xb0, 0xff}, {0xe5, 0xd7, 0xb0, 0xff}, {0xe6, 0xd8, 0xb0, 0xff}, {0xe7, 0xd8, 0xb1, 0xff},
+			{0xe7, 0xd9, 0xb2, 0xff}, {0xe8, 0xd9, 0xb3, 0xff}, {0xe8, 0xda, 0xb4, 0xff}, {0xe8, 0xda, 0xb5, 0xff},
+			{0xe9, 0xdb, 0xb6, 0xff}, {0xe9, 0xdb, 0xb7, 0xff}, {0xe9, 0xdc, 0xb8, 0xff}, {0xea, 0xdc, 0xb9, 0xff},
+			{0xea, 0xdd, 0xba, 0xff}, {0xea, 0xdd, 0xbb, 0xff}, {0xeb, 0xde, 0xbc, 0xff}, {0xeb, 0xde, 0xbd, 0xff},
+			{0xeb, 0xdf, 0xbe, 0xff}, {0xec, 0xdf, 0xbf, 0xff}, {0xec, 0xe0, 0xc0, 0xff}, {0xec, 0xe0, 0xc1, 0xff},
+			{0xec, 0xe1, 0xc2, 0xff}, {0xed, 0xe1, 0xc3, 0xff}, {0xed, 0xe2, 0xc4, 0xff}, {0xed, 0xe2, 0xc5, 0xff},
+			{0xee, 0xe3, 0xc6, 0xff}, {0xee, 0xe3, 0xc7, 0xff}, {0xee, 0xe4, 0xc8, 0xff}, {0xef, 0xe4, 0xc9, 0xff},
+			{0xef, 0xe5, 0xca, 0xff}, {0xef, 0xe6, 0xcb, 0xff}, {0xf0, 0xe6, 0xcc, 0xff}, {0xf0, 0xe7, 0xcd, 0xff},
+			{0xf0, 0xe7, 0xce, 0xff}, {0xf1, 0xe8, 0xcf, 0xff}, {0xf1, 0xe8, 0xd1, 0xff}, {0xf1, 0xe9, 0xd2, 0xff},
+			{0xf2, 0xe9, 0xd3, 0xff}, {0xf2, 0xea, 0xd4, 0xff}, {0xf2, 0xea, 0xd5, 0xff}, {0xf2, 0xeb, 0xd6, 0xff},
+			{0xf3, 0xeb, 0xd7, 0xff}, {0xf3, 0xec, 0xd8, 0xff}, {0xf3, 0xec, 0xd9, 0xff}, {0xf4, 0xed, 0xda, 0xff},
+			{0xf4, 0xed, 0xdb, 0xff}, {0xf4, 0xee, 0xdc, 0xff}, {0xf5, 0xee, 0xdd, 0xff}, {0xf5, 0xef, 0xde, 0xff},
+			{0xf5, 0xef, 0xdf, 0xff}, {0xf6, 0xf0, 0xe0, 0xff}, {0xf6, 0xf0, 0xe1, 0xff}, {0xf6, 0xf1, 0xe2, 0xff},
+			{0xf7, 0xf1, 0xe3, 0xff}, {0xf7, 0xf2, 0xe4, 0xff}, {0xf7, 0xf2, 0xe5, 0xff}, {0xf7, 0xf3, 0xe6, 0xff},
+			{0xf8, 0xf3, 0xe7, 0xff}, {0xf8, 0xf4, 0xe8, 0xff}, {0xf8, 0xf4, 0xe9, 0xff}, {0xf9, 0xf5, 0xea, 0xff},
+			{0xf9, 0xf5, 0xeb, 0xff}, {0xf9, 0xf6, 0xec, 0xff}, {0xfa, 0xf6, 0xed, 0xff}, {0xfa, 0xf7, 0xee, 0xff},
+			{0xfa, 0xf7, 0xf0, 0xff}, {0xfb, 0xf8, 0xf1, 0xff}, {0xfb, 0xf8, 0xf2, 0xff}, {0xfb, 0xf9, 0xf3, 0xff},
+			{0xfc, 0xf9, 0xf4, 0xff}, {0xfc, 0xfa, 0xf5, 0xff}, {0xfc, 0xfa, 0xf6, 0xff}, {0xfc, 0xfb, 0xf7, 0xff},
+			{0xfd, 0xfb, 0xf8, 0xff}, {0xfd, 0xfc, 0xf9, 0xff}, {0xfd, 0xfc, 0xfa, 0xff}, {0xfe, 0xfd, 0xfb, 0xff},
+			{0xfe, 0xfd, 0xfc, 0xff}, {0xfe, 0xfe, 0xfd, 0xff}, {0xff, 0xfe, 0xfe, 0xff}, {0xff, 0xff, 0xff, 0xff},
+		},
+	},
+	"ThermalHeat": {
+		fingerprint: "0:#000000:0 3fd0000000000000:#700000:0 3fe0000000000000:#ff4000:0 3fe8000000000000:#ffd000:0 3ff0000000000000:#ffffff:0",
+		table: &lut{
+			{0x00, 0x00, 0x00, 0xff}, {0x02, 0x00, 0x00, 0xff}, {0x04, 0x00, 0x00, 0xff}, {0x05, 0x00, 0x00, 0xff},
+			{0x07, 0x00, 0x00, 0xff}, {0x09, 0x00, 0x00, 0xff}, {0x0b, 0x00, 0x00, 0xff}, {0x0c, 0x00, 0x00, 0xff},
+			{0x0e, 0x00, 0x00, 0xff}, {0x10, 0x00, 0x00, 0xff}, {0x12, 0x00, 0x00, 0xff}, {0x13, 0x00, 0x00, 0xff},
+			{0x15, 0x00, 0x00, 0xff}, {0x17, 0x00, 0x00, 0xff}, {0x19, 0x00, 0x00, 0xff}, {0x1a, 0x00, 0x00, 0xff},
+			{0x1c, 0x00, 0x00, 0xff}, {0x1e, 0x00, 0x00, 0xff}, {0x20, 0x00, 0x00, 0xff}, {0x21, 0x00, 0x00, 0xff},
+			{0x23, 0x00, 0x00, 0xff}, {0x25, 0x00, 0x00, 0xff}, {0x27, 0x00, 0x00, 0xff}, {0x28, 0x00, 0x00, 0xff},
+			{0x2a, 0x00, 0x00, 0xff}, {0x2c, 0x00, 0x00, 0xff}, {0x2e, 0x00, 0x00, 0xff}, {0x2f, 0x00, 0x00, 0xff},
+			{0x31, 0x00, 0x00, 0xff}, {0x33, 0x00, 0x00, 0xff}, {0x35, 0x00, 0x00, 0xff}, {0x36, 0x00, 0x00, 0xff},
+			{0x38, 0x00, 0x00, 0xff}, {0x3a, 0x00, 0x00, 0xff}, {0x3c, 0x00, 0x00, 0xff}, {0x3d, 0x00, 0x00, 0xff},
+			{0x3f, 0x00, 0x00, 0xff}, {0x41, 0x00, 0x00, 0xff}, {0x43, 0x00, 0x00, 0xff}, {0x45, 0x00, 0x00, 0xff},
+			{0x46, 0x00, 0x00, 0xff}, {0x48, 0x00, 0x00, 0xff}, {0x4a, 0x00, 0x00, 0xff}, {0x4c, 0x00, 0x00, 0xff},
+			{0x4d, 0x00, 0x00, 0xff}, {0x4f, 0x00, 0x00, 0xff}, {0x51, 0x00, 0x00, 0xff}, {0x53, 0x00, 0x00, 0xff},
+			{0x54, 0x00, 0x00, 0xff}, {0x56, 0x00, 0x00, 0xff}, {0x58, 0x00, 0x00, 0xff}, {0x5a, 0x00, 0x00, 0xff},
+			{0x5b, 0x00, 0x00, 0xff}, {0x5d, 0x00, 0x00, 0xff}, {0x5f, 0x00, 0x00, 0xff}, {0x61, 0x00, 0x00, 0xff},
+			{0x62, 0x00, 0x00, 0xff}, {0x64, 0x00, 0x00, 0xff}, {0x66, 0x00, 0x00, 0xff}, {0x68, 0x00, 0x00, 0xff},
+			{0x69, 0x00, 0x00, 0xff}, {0x6b, 0x00, 0x00, 0xff}, {0x6d, 0x00, 0x00, 0xff}, {0x6f, 0x00, 0x00, 0xff},
+			{0x71, 0x00, 0x00, 0xff}, {0x73, 0x01, 0x00, 0xff}, {0x75, 0x02, 0x00, 0xff}, {0x77, 0x03, 0x00, 0xff},
+			{0x7a, 0x04, 0x00, 0xff}, {0x7c, 0x05, 0x00, 0xff}, {0x7e, 0x06, 0x00, 0xff}, {0x80, 0x07, 0x00, 0xff},
+			{0x83, 0x08, 0x00, 0xff}, {0x85, 0x09, 0x00, 0xff}, {0x87, 0x0a, 0x00, 0xff}, {0x89, 0x0b, 0x00, 0xff},
+			{0x8b, 0x0c, 0x00, 0xff}, {0x8e, 0x0d, 0x00, 0xff}, {0x90, 0x0e, 0x00, 0xff}, {0x92, 0x0f, 0x00, 0xff},
+			{0x94, 0x10, 0x00, 0xff}, {0x97, 0x11, 0x00, 0xff}, {0x99, 0x12, 0x00, 0xff}, {0x9b, 0x13, 0x00, 0xff},
+			{0x9d, 0x14, 0x00, 0xff}, {0xa0, 0x15, 0x00, 0xff}, {0xa2, 0x16, 0x00, 0xff}, {0xa4, 0x17, 0x00, 0xff},
+			{0xa6, 0x18, 0x00, 0xff}, {0xa9, 0x19, 0x00, 0xff}, {0xab, 0x1a, 0x00, 0xff}, {0xad, 0x1b, 0x00, 0xff},
+			{0xaf, 0x1c, 0x00, 0xff}, {0xb2, 0x1d, 0x00, 0xff}, {0xb4, 0x1e, 0x00, 0xff}, {0xb6, 0x1f, 0x00, 0xff},
+			{0xb8, 0x20, 0x00, 0xff}, {0xbb, 0x21, 0x00, 0xff}, {0xbd, 0x22, 0x00, 0xff}, {0xbf, 0x23, 0x00, 0xff},
+			{0xc1, 0x24, 0x00, 0xff}, {0xc4, 0x25, 0x00, 0xff}, {0xc6, 0x26, 0x00, 0xff}, {0xc8, 0x27, 0x00, 0xff},
+			{0xca, 0x28, 0x00, 0xff}, {0xcd, 0x29, 0x00, 0xff}, {0xcf, 0x2a, 0x00, 0xff}, {0xd1, 0x2b, 0x00, 0xff},
+			{0xd3, 0x2c, 0x00, 0xff}, {0xd6, 0x2d, 0x00, 0xff}, {0xd8, 0x2e, 0x00, 0xff}, {0xda, 0x2f, 0x00, 0xff},
+			{0xdc, 0x30, 0x00, 0xff}, {0xde, 0x31, 0x00, 0xff}, {0xe1, 0x32, 0x00, 0xff}, {0xe3, 0x33, 0x00, 0xff},
+			{0xe5, 0x34, 0x00, 0xff}, {0xe7, 0x35, 0x00, 0xff}, {0xea, 0x36, 0x00, 0xff}, {0xec, 0x37, 0x00, 0xff},
+			{0xee, 0x38, 0x00, 0xff}, {0xf0, 0x39, 0x00, 0xff}, {0xf3, 0x3a, 0x00, 0xff}, {0xf5, 0x3b, 0x00, 0xff},
+			{0xf7, 0x3c, 0x00, 0xff}, {0xf9, 0x3d, 0x00, 0xff}, {0xfc, 0x3e, 0x00, 0xff}, {0xfe, 0x3f, 0x00, 0xff},
+			{0xff, 0x41, 0x00, 0xff}, {0xff, 0x43, 0x00, 0xff}, {0xff, 0x46, 0x00, 0xff}, {0xff, 0x48, 0x00, 0xff},
+			{0xff, 0x4a, 0x00, 0xff}, {0xff, 0x4c, 0x00, 0xff}, {0xff, 0x4f, 0x00, 0xff}, {0xff, 0x51, 0x00, 0xff},
+			{0xff, 0x53, 0x00, 0xff}, {0xff, 0x55, 0x00, 0xff}, {0xff, 0x58, 0x00, 0xff}, {0xff, 0x5a, 0x00, 0xff},
+			{0xff, 0x5c, 0x00, 0xff}, {0xff, 0x5e, 0x00, 0xff}, {0xff, 0x61, 0x00, 0xff}, {0xff, 0x63, 0x00, 0xff},
+			{0xff, 0x65, 0x00, 0xff}, {0xff, 0x68, 0x00, 0xff}, {0xff, 0x6a, 0x00, 0xff}, {0xff, 0x6c, 0x00, 0xff},
+			{0xff, 0x6e, 0x00, 0xff}, {0xff, 0x71, 0x00, 0xff}, {0xff, 0x73, 0x00, 0xff}, {0xff, 0x75, 0x00, 0xff},
+			{0xff, 0x77, 0x00, 0xff}, {0xff, 0x7a, 0x00, 0xff}, {0xff, 0x7c, 0x00, 0xff}, {0xff, 0x7e, 0x00, 0xff},
+			{0xff, 0x80, 0x00, 0xff}, {0xff, 0x83, 0x00, 0xff}, {0xff, 0x85, 0x00, 0xff}, {0xff, 0x87, 0x00, 0xff},
+			{0xff, 0x89, 0x00, 0xff}, {0xff, 0x8c, 0x00, 0xff}, {0xff, 0x8e, 0x00, 0xff}, {0xff, 0x90, 0x00, 0xff},
+			{0xff, 0x92, 0x00, 0xff}, {0xff, 0x95, 0x00, 0xff}, {0xff, 0x97, 0x00, 0xff}, {0xff, 0x99, 0x00, 0xff},
+			{0xff, 0x9b, 0x00, 0xff}, {0xff, 0x9e, 0x00, 0xff}, {0xff, 0xa0, 0x00, 0xff}, {0xff, 0xa2, 0x00, 0xff},
+			{0xff, 0xa5, 0x00, 0xff}, {0xff, 0xa7, 0x00, 0xff}, {0xff, 0xa9, 0x00, 0xff}, {0xff, 0xab, 0x00, 0xff},
+			{0xff, 0xae, 0x00, 0xff}, {0xff, 0xb0, 0x00, 0xff}, {0xff, 0xb2, 0x00, 0xff}, {0xff, 0xb4, 0x00, 0xff},
+			{0xff, 0xb7, 0x00, 0xff}, {0xff, 0xb9, 0x00, 0xff}, {0xff, 0xbb, 0x00, 0xff}, {0xff, 0xbd, 0x00, 0xff},
+			{0xff, 0xc0, 0x00, 0xff}, {0xff, 0xc2, 0x00, 0xff}, {0xff, 0xc4, 0x00, 0xff}, {0xff, 0xc6, 0x00, 0xff},
+			{0xff, 0xc9, 0x00, 0xff}, {0xff, 0xcb, 0x00, 0xff}, {0xff, 0xcd, 0x00, 0xff}, {0xff, 0xcf, 0x00, 0xff},
+			{0xff, 0xd1, 0x03, 0xff}, {0xff, 0xd1, 0x07, 0xff}, {0xff, 0xd2, 0x0b, 0xff}, {0xff, 0xd3, 0x0f, 0xff},
+			{0xff, 0xd4, 0x13, 0xff}, {0xff, 0xd4, 0x17, 0xff}, {0xff, 0xd5, 0x1b, 0xff}, {0xff, 0xd6, 0x1f, 0xff},
+			{0xff, 0xd6, 0x23, 0xff}, {0xff, 0xd7, 0x27, 0xff}, {0xff, 0xd8, 0x2b, 0xff}, {0xff, 0xd9, 0x2f, 0xff},
+			{0xff, 0xd9, 0x33, 0xff}, {0xff, 0xda, 0x37, 0xff}, {0xff, 0xdb, 0x3b, 0xff}, {0xff, 0xdc, 0x3f, 0xff},
+			{0xff, 0xdc, 0x43, 0xff}, {0xff, 0xdd, 0x47, 0xff}, {0xff, 0xde, 0x4b, 0xff}, {0xff, 0xdf, 0x4f, 0xff},
+			{0xff, 0xdf, 0x53, 0xff}, {0xff, 0xe0, 0x57, 0xff}, {0xff, 0xe1, 0x5b, 0xff}, {0xff, 0xe2, 0x5f, 0xff},
+			{0xff, 0xe2, 0x63, 0xff}, {0xff, 0xe3, 0x67, 0xff}, {0xff, 0xe4, 0x6b, 0xff}, {0xff, 0xe4, 0x6f, 0xff},
+			{0xff, 0xe5, 0x73, 0xff}, {0xff, 0xe6, 0x77, 0xff}, {0xff, 0xe7, 0x7b, 0xff}, {0xff, 0xe7, 0x7f, 0xff},
+			{0xff, 0xe8, 0x83, 0xff}, {0xff, 0xe9, 0x87, 0xff}, {0xff, 0xea, 0x8b, 0xff}, {0xff, 0xea, 0x8f, 0xff},
+			{0xff, 0xeb, 0x93, 0xff}, {0xff, 0xec, 0x97, 0xff}, {0xff, 0xed, 0x9b, 0xff}, {0xff, 0xed, 0x9f, 0xff},
+			{0xff, 0xee, 0xa3, 0xff}, {0xff, 0xef, 0xa7, 0xff}, {0xff, 0xf0, 0xab, 0xff}, {0xff, 0xf0, 0xaf, 0xff},
+			{0xff, 0xf1, 0xb3, 0xff}, {0xff, 0xf2, 0xb7, 0xff}, {0xff, 0xf2, 0xbb, 0xff}, {0xff, 0xf3, 0xbf, 0xff},
+			{0xff, 0xf4, 0xc3, 0xff}, {0xff, 0xf5, 0xc7, 0xff}, {0xff, 0xf5, 0xcb, 0xff}, {0xff, 0xf6, 0xcf, 0xff},
+			{0xff, 0xf7, 0xd3, 0xff}, {0xff, 0xf8, 0xd7, 0xff}, {0xff, 0xf8, 0xdb, 0xff}, {0xff, 0xf9, 0xdf, 0xff},
+			{0xff, 0xfa, 0xe3, 0xff}, {0xff, 0xfb, 0xe7, 0xff}, {0xff, 0xfb, 0xeb, 0xff}, {0xff, 0xfc, 0xef, 0xff},
+			{0xff, 0xfd, 0xf3, 0xff}, {0xff, 0xfe, 0xf7, 0xff}, {0xff, 0xfe, 0xfb, 0xff}, {0xff, 0xff, 0xff, 0xff},
+		},
+	},
+	"AuroraArc": {
+		fingerprint: "0:#01131f:0 3fc999999999999a:#036b5f:0 3fdccccccccccccd:#54e6b2:0 3fe6666666666666:#9543d6:0 3ff0000000000000:#f8f9ff:0",
+		table: &lut{
+			{0x01, 0x13, 0x1f, 0xff}, {0x01, 0x15, 0x20, 0xff}, {0x01, 0x16, 0x22, 0xff}, {0x01, 0x18, 0x23, 0xff},
+			{0x01, 0x1a, 0x24, 0xff}, {0x01, 0x1c, 0x25, 0xff}, {0x01, 0x1d, 0x27, 0xff}, {0x01, 0x1f, 0x28, 0xff},
+			{0x01, 0x21, 0x29, 0xff}, {0x01, 0x23, 0x2a, 0xff}, {0x01, 0x24, 0x2c, 0xff}, {0x01, 0x26, 0x2d, 0xff},
+			{0x01, 0x28, 0x2e, 0xff}, {0x02, 0x29, 0x2f, 0xff}, {0x02, 0x2b, 0x31, 0xff}, {0x02, 0x2d, 0x32, 0xff},
+			{0x02, 0x2f, 0x33, 0xff}, {0x02, 0x30, 0x34, 0xff}, {0x02, 0x32, 0x36, 0xff}, {0x02, 0x34, 0x37, 0xff},
+			{0x02, 0x36, 0x38, 0xff}, {0x02, 0x37, 0x39, 0xff}, {0x02, 0x39, 0x3b, 0xff}, {0x02, 0x3b, 0x3c, 0xff},
+			{0x02, 0x3c, 0x3d, 0xff}, {0x02, 0x3e, 0x3e, 0xff}, {0x02, 0x40, 0x40, 0xff}, {0x02, 0x42, 0x41, 0xff},
+			{0x02, 0x43, 0x42, 0xff}, {0x02, 0x45, 0x43, 0xff}, {0x02, 0x47, 0x45, 0xff}, {0x02, 0x48, 0x46, 0xff},
+			{0x02, 0x4a, 0x47, 0xff}, {0x02, 0x4c, 0x48, 0xff}, {0x02, 0x4e, 0x4a, 0xff}, {0x02, 0x4f, 0x4b, 0xff},
+			{0x02, 0x51, 0x4c, 0xff}, {0x02, 0x53, 0x4d, 0xff}, {0x02, 0x55, 0x4f, 0xff}, {0x03, 0x56, 0x50, 0xff},
+			{0x03, 0x58, 0x51, 0xff}, {0x03, 0x5a, 0x52, 0xff}, {0x03, 0x5b, 0x54, 0xff}, {0x03, 0x5d, 0x55, 0xff},
+			{0x03, 0x5f, 0x56, 0xff}, {0x03, 0x61, 0x57, 0xff}, {0x03, 0x62, 0x59, 0xff}, {0x03, 0x64, 0x5a, 0xff},
+			{0x03, 0x66, 0x5b, 0xff}, {0x03, 0x68, 0x5c, 0xff}, {0x03, 0x69, 0x5e, 0xff}, {0x03, 0x6b, 0x5f, 0xff},
+			{0x04, 0x6d, 0x60, 0xff}, {0x06, 0x6f, 0x62, 0xff}, {0x07, 0x71, 0x63, 0xff}, {0x08, 0x73, 0x64, 0xff},
+			{0x09, 0x75, 0x66, 0xff}, {0x0b, 0x77, 0x67, 0xff}, {0x0c, 0x79, 0x68, 0xff}, {0x0d, 0x7a, 0x69, 0xff},
+			{0x0e, 0x7c, 0x6b, 0xff}, {0x10, 0x7e, 0x6c, 0xff}, {0x11, 0x80, 0x6d, 0xff}, {0x12, 0x82, 0x6f, 0xff},
+			{0x14, 0x84, 0x70, 0xff}, {0x15, 0x86, 0x71, 0xff}, {0x16, 0x88, 0x73, 0xff}, {0x17, 0x8a, 0x74, 0xff},
+			{0x19, 0x8c, 0x75, 0xff}, {0x1a, 0x8e, 0x76, 0xff}, {0x1b, 0x90, 0x78, 0xff}, {0x1c, 0x92, 0x79, 0xff},
+			{0x1e, 0x94, 0x7a, 0xff}, {0x1f, 0x95, 0x7c, 0xff}, {0x20, 0x97, 0x7d, 0xff}, {0x21, 0x99, 0x7e, 0xff},
+			{0x23, 0x9b, 0x80, 0xff}, {0x24, 0x9d, 0x81, 0xff}, {0x25, 0x9f, 0x82, 0xff}, {0x27, 0xa1, 0x83, 0xff},
+			{0x28, 0xa3, 0x85, 0xff}, {0x29, 0xa5, 0x86, 0xff}, {0x2a, 0xa7, 0x87, 0xff}, {0x2c, 0xa9, 0x89, 0xff},
+			{0x2d, 0xab, 0x8a, 0xff}, {0x2e, 0xad, 0x8b, 0xff}, {0x2f, 0xaf, 0x8d, 0xff}, {0x31, 0xb0, 0x8e, 0xff},
+			{0x32, 0xb2, 0x8f, 0xff}, {0x33, 0xb4, 0x90, 0xff}, {0x35, 0xb6, 0x92, 0xff}, {0x36, 0xb8, 0x93, 0xff},
+			{0x37, 0xba, 0x94, 0xff}, {0x38, 0xbc, 0x96, 0xff}, {0x3a, 0xbe, 0x97, 0xff}, {0x3b, 0xc0, 0x98, 0xff},
+			{0x3c, 0xc2, 0x9a, 0xff}, {0x3d, 0xc4, 0x9b, 0xff}, {0x3f, 0xc6, 0x9c, 0xff}, {0x40, 0xc8, 0x9d, 0xff},
+			{0x41, 0xca, 0x9f, 0xff}, {0x43, 0xcb, 0xa0, 0xff}, {0x44, 0xcd, 0xa1, 0xff}, {0x45, 0xcf, 0xa3, 0xff},
+			{0x46, 0xd1, 0xa4, 0xff}, {0x48, 0xd3, 0xa5, 0xff}, {0x49, 0xd5, 0xa7, 0xff}, {0x4a, 0xd7, 0xa8, 0xff},
+			{0x4b, 0xd9, 0xa9, 0xff}, {0x4d, 0xdb, 0xab, 0xff}, {0x4e, 0xdd, 0xac, 0xff}, {0x4f, 0xdf, 0xad, 0xff},
+			{0x51, 0xe1, 0xae, 0xff}, {0x52, 0xe3, 0xb0, 0xff}, {0x53, 0xe5, 0xb1, 0xff}, {0x54, 0xe5, 0xb2, 0xff},
+			{0x55, 0xe3, 0xb3, 0xff}, {0x56, 0xe0, 0xb3, 0xff}, {0x57, 0xde, 0xb4, 0xff}, {0x58, 0xdb, 0xb4, 0xff},
+			{0x59, 0xd9, 0xb5, 0xff}, {0x5a, 0xd6, 0xb6, 0xff}, {0x5b, 0xd3, 0xb6, 0xff}, {0x5c, 0xd1, 0xb7, 0xff},
+			{0x5d, 0xce, 0xb7, 0xff}, {0x5e, 0xcc, 0xb8, 0xff}, {0x5f, 0xc9, 0xb8, 0xff}, {0x60, 0xc7, 0xb9, 0xff},
+			{0x62, 0xc4, 0xb9, 0xff}, {0x63, 0xc2, 0xba, 0xff}, {0x64, 0xbf, 0xbb, 0xff}, {0x65, 0xbc, 0xbb, 0xff},
+			{0x66, 0xba, 0xbc, 0xff}, {0x67, 0xb7, 0xbc, 0xff}, {0x68, 0xb5, 0xbd, 0xff}, {0x69, 0xb2, 0xbd, 0xff},
+			{0x6a, 0xb0, 0xbe, 0xff}, {0x6b, 0xad, 0xbf, 0xff}, {0x6c, 0xab, 0xbf, 0xff}, {0x6d, 0xa8, 0xc0, 0xff},
+			{0x6e, 0xa5, 0xc0, 0xff}, {0x6f, 0xa3, 0xc1, 0xff}, {0x70, 0xa0, 0xc1, 0xff}, {0x71, 0x9e, 0xc2, 0xff},
+			{0x72, 0x9b, 0xc3, 0xff}, {0x73, 0x99, 0xc3, 0xff}, {0x74, 0x96, 0xc4, 0xff}, {0x75, 0x94, 0xc4, 0xff},
+			{0x76, 0x91, 0xc5, 0xff}, {0x77, 0x8e, 0xc5, 0xff}, {0x78, 0x8c, 0xc6, 0xff}, {0x79, 0x89, 0xc6, 0xff},
+			{0x7a, 0x87, 0xc7, 0xff}, {0x7b, 0x84, 0xc8, 0xff}, {0x7c, 0x82, 0xc8, 0xff}, {0x7d, 0x7f, 0xc9, 0xff},
+			{0x7e, 0x7d, 0xc9, 0xff}, {0x7f, 0x7a, 0xca, 0xff}, {0x80, 0x77, 0xca, 0xff}, {0x81, 0x75, 0xcb, 0xff},
+			{0x82, 0x72, 0xcc, 0xff}, {0x83, 0x70, 0xcc, 0xff}, {0x84, 0x6d, 0xcd, 0xff}, {0x85, 0x6b, 0xcd, 0xff},
+			{0x86, 0x68, 0xce, 0xff}, {0x87, 0x66, 0xce, 0xff}, {0x88, 0x63, 0xcf, 0xff}, {0x89, 0x60, 0xd0, 0xff},
+			{0x8a, 0x5e, 0xd0, 0xff}, {0x8b, 0x5b, 0xd1, 0xff}, {0x8c, 0x59, 0xd1, 0xff}, {0x8d, 0x56, 0xd2, 0xff},
+			{0x8e, 0x54, 0xd2, 0xff}, {0x8f, 0x51, 0xd3, 0xff}, {0x90, 0x4f, 0xd3, 0xff}, {0x91, 0x4c, 0xd4, 0xff},
+			{0x92, 0x49, 0xd5, 0xff}, {0x93, 0x47, 0xd5, 0xff}, {0x94, 0x44, 0xd6, 0xff}, {0x96, 0x44, 0xd6, 0xff},
+			{0x97, 0x47, 0xd7, 0xff}, {0x98, 0x49, 0xd7, 0xff}, {0x9a, 0x4b, 0xd8, 0xff}, {0x9b, 0x4e, 0xd8, 0xff},
+			{0x9c, 0x50, 0xd9, 0xff}, {0x9d, 0x52, 0xd9, 0xff}, {0x9f, 0x55, 0xda, 0xff}, {0xa0, 0x57, 0xdb, 0xff},
+			{0xa1, 0x5a, 0xdb, 0xff}, {0xa3, 0x5c, 0xdc, 0xff}, {0xa4, 0x5e, 0xdc, 0xff}, {0xa5, 0x61, 0xdd, 0xff},
+			{0xa6, 0x63, 0xdd, 0xff}, {0xa8, 0x65, 0xde, 0xff}, {0xa9, 0x68, 0xde, 0xff}, {0xaa, 0x6a, 0xdf, 0xff},
+			{0xac, 0x6d, 0xdf, 0xff}, {0xad, 0x6f, 0xe0, 0xff}, {0xae, 0x71, 0xe0, 0xff}, {0xb0, 0x74, 0xe1, 0xff},
+			{0xb1, 0x76, 0xe2, 0xff}, {0xb2, 0x79, 0xe2, 0xff}, {0xb3, 0x7b, 0xe3, 0xff}, {0xb5, 0x7d, 0xe3, 0xff},
+			{0xb6, 0x80, 0xe4, 0xff}, {0xb7, 0x82, 0xe4, 0xff}, {0xb9, 0x84, 0xe5, 0xff}, {0xba, 0x87, 0xe5, 0xff},
+			{0xbb, 0x89, 0xe6, 0xff}, {0xbc, 0x8c, 0xe6, 0xff}, {0xbe, 0x8e, 0xe7, 0xff}, {0xbf, 0x90, 0xe7, 0xff},
+			{0xc0, 0x93, 0xe8, 0xff}, {0xc2, 0x95, 0xe8, 0xff}, {0xc3, 0x97, 0xe9, 0xff}, {0xc4, 0x9a, 0xea, 0xff},
+			{0xc6, 0x9c, 0xea, 0xff}, {0xc7, 0x9f, 0xeb, 0xff}, {0xc8, 0xa1, 0xeb, 0xff}, {0xc9, 0xa3, 0xec, 0xff},
+			{0xcb, 0xa6, 0xec, 0xff}, {0xcc, 0xa8, 0xed, 0xff}, {0xcd, 0xaa, 0xed, 0xff}, {0xcf, 0xad, 0xee, 0xff},
+			{0xd0, 0xaf, 0xee, 0xff}, {0xd1, 0xb2, 0xef, 0xff}, {0xd2, 0xb4, 0xef, 0xff}, {0xd4, 0xb6, 0xf0, 0xff},
+			{0xd5, 0xb9, 0xf1, 0xff}, {0xd6, 0xbb, 0xf1, 0xff}, {0xd8, 0xbe, 0xf2, 0xff}, {0xd9, 0xc0, 0xf2, 0xff},
+			{0xda, 0xc2, 0xf3, 0xff}, {0xdc, 0xc5, 0xf3, 0xff}, {0xdd, 0xc7, 0xf4, 0xff}, {0xde, 0xc9, 0xf4, 0xff},
+			{0xdf, 0xcc, 0xf5, 0xff}, {0xe1, 0xce, 0xf5, 0xff}, {0xe2, 0xd1, 0xf6, 0xff}, {0xe3, 0xd3, 0xf6, 0xff},
+			{0xe5, 0xd5, 0xf7, 0xff}, {0xe6, 0xd8, 0xf7, 0xff}, {0xe7, 0xda, 0xf8, 0xff}, {0xe8, 0xdc, 0xf9, 0xff},
+			{0xea, 0xdf, 0xf9, 0xff}, {0xeb, 0xe1, 0xfa, 0xff}, {0xec, 0xe4, 0xfa, 0xff}, {0xee, 0xe6, 0xfb, 0xff},
+			{0xef, 0xe8, 0xfb, 0xff}, {0xf0, 0xeb, 0xfc, 0xff}, {0xf2, 0xed, 0xfc, 0xff}, {0xf3, 0xef, 0xfd, 0xff},
+			{0xf4, 0xf2, 0xfd, 0xff}, {0xf5, 0xf4, 0xfe, 0xff}, {0xf7, 0xf7, 0xfe, 0xff}, {0xf8, 0xf9, 0xff, 0xff},
+		},
+	},
+}
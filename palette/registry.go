@@ -0,0 +1,75 @@
+package palette
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// MaxRegistered is the most palettes Register accepts, so that a server
+// taking palettes from its clients can't be made to hold any number.
+const MaxRegistered = 256
+
+var (
+	// ErrExists is returned by Register for a keyword that is already
+	// taken, by a built-in palette or a registered one.
+	ErrExists = errors.New("palette already exists")
+
+	// ErrFull is returned by Register once MaxRegistered palettes have
+	// been registered.
+	ErrFull = errors.New("palette registry full")
+)
+
+// registry holds the palettes added with Register, after the built-in
+// ColorPalettes. It is safe for concurrent use.
+var registry struct {
+	mu   sync.RWMutex
+	maps []ColorMap
+}
+
+// Register adds cm under its keyword for the rest of the process, making
+// it available to Get and List. cm must pass Validate, and its keyword
+// must not be in use already: neither a built-in palette nor a registered
+// one can be replaced. At most MaxRegistered palettes can be added.
+// Register keeps its own normalized copy of cm.
+func Register(cm ColorMap) error {
+	if err := Validate(cm); err != nil {
+		return err
+	}
+	cm.Colors = slices.Clone(cm.Colors)
+	Normalize(&cm)
+	cm.lut = nil // a Frozen copy of a built-in may have had its stops changed
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if builtin(cm.Keyword) != nil || registered(cm.Keyword) != nil {
+		return fmt.Errorf("%w: %q", ErrExists, cm.Keyword)
+	}
+	if len(registry.maps) >= MaxRegistered {
+		return fmt.Errorf("%w: %d palettes registered", ErrFull, len(registry.maps))
+	}
+	registry.maps = append(registry.maps, cm)
+	return nil
+}
+
+// builtin returns the built-in palette with the keyword, or nil.
+func builtin(keyword string) *ColorMap {
+	for i := range ColorPalettes {
+		if ColorPalettes[i].Keyword == keyword {
+			return &ColorPalettes[i]
+		}
+	}
+	return nil
+}
+
+// registered returns the registered palette with the keyword, or nil.
+// The caller holds registry.mu.
+func registered(keyword string) *ColorMap {
+	for i := range registry.maps {
+		if registry.maps[i].Keyword == keyword {
+			return &registry.maps[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package palette
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// cleanRegistry empties the registry for the test and restores it after.
+func cleanRegistry(t *testing.T) {
+	t.Helper()
+	registry.mu.Lock()
+	saved := registry.maps
+	registry.maps = nil
+	registry.mu.Unlock()
+	t.Cleanup(func() {
+		registry.mu.Lock()
+		registry.maps = saved
+		registry.mu.Unlock()
+	})
+}
+
+// twoStops returns a black-to-white palette named keyword.
+func twoStops(keyword string) ColorMap {
+	return ColorMap{Keyword: keyword, Colors: []Color{
+		{Color: color.RGBA{0, 0, 0, 0xff}},
+		{Color: color.RGBA{0xff, 0xff, 0xff, 0xff}},
+	}}
+}
+
+func TestRegister(t *testing.T) {
+	cleanRegistry(t)
+	cm := twoStops("Mono")
+	if err := Register(cm); err != nil {
+		t.Fatal(err)
+	}
+	cm.Colors[1].Color = color.RGBA{0xff, 0, 0, 0xff} // Register kept its own copy
+	got := Get("Mono")
+	if got == nil || got.Colors[0].Step != 0 || got.Colors[1].Step != 1 || got.Colors[1].Color != (color.RGBA{0xff, 0xff, 0xff, 0xff}) {
+		t.Fatalf("Get after Register = %+v", got)
+	}
+	got.Colors[0].Color = color.RGBA{0, 0xff, 0, 0xff}
+	if again := Get("Mono"); again.Colors[0].Color != (color.RGBA{0, 0, 0, 0xff}) {
+		t.Error("changing Get's result changed the registered palette")
+	}
+	if !slices.Contains(List(), "Mono") {
+		t.Errorf("List() = %v, missing Mono", List())
+	}
+
+	name := ColorPalettes[0].Keyword
+	before := Get(name).Fingerprint()
+	for _, cm := range []ColorMap{twoStops("Mono"), twoStops(name)} {
+		if err := Register(cm); !errors.Is(err, ErrExists) {
+			t.Errorf("Register %q again: got %v, want ErrExists", cm.Keyword, err)
+		}
+	}
+	if Get(name).Fingerprint() != before {
+		t.Errorf("built-in palette %s overwritten", name)
+	}
+
+	noColor := twoStops("NoColor")
+	noColor.Colors[0].Color = nil
+	badStep := twoStops("BadStep")
+	badStep.Colors[1].Step = 2
+	for _, cm := range []ColorMap{
+		twoStops(""),
+		twoStops("with space"),
+		{Keyword: "Empty"},
+		noColor,
+		badStep,
+	} {
+		if err := Register(cm); err == nil || errors.Is(err, ErrExists) {
+			t.Errorf("Register %q: got %v, want a validation error", cm.Keyword, err)
+		}
+		if cm.Keyword != "" && Get(cm.Keyword) != nil {
+			t.Errorf("invalid palette %q registered", cm.Keyword)
+		}
+	}
+}
+
+func TestRegisterFull(t *testing.T) {
+	cleanRegistry(t)
+	for i := range MaxRegistered {
+		if err := Register(twoStops(fmt.Sprintf("P%d", i))); err != nil {
+			t.Fatalf("palette %d: %v", i, err)
+		}
+	}
+	if err := Register(twoStops("OneMore")); !errors.Is(err, ErrFull) {
+		t.Errorf("got %v, want ErrFull", err)
+	}
+	if Get("OneMore") != nil {
+		t.Error("palette registered past the limit")
+	}
+	// a taken name is still reported as such
+	if err := Register(twoStops("P0")); !errors.Is(err, ErrExists) {
+		t.Errorf("got %v, want ErrExists", err)
+	}
+}
+
+func TestRegisterConcurrent(t *testing.T) {
+	// Many goroutines register the same keyword and distinct ones, reading
+	// meanwhile: one wins the shared keyword and every distinct one is
+	// kept. Run with -race.
+	cleanRegistry(t)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		wins int
+	)
+	for i := range 32 {
+		wg.Go(func() {
+			if err := Register(twoStops("Shared")); err == nil {
+				mu.Lock()
+				wins++
+				mu.Unlock()
+			} else if !errors.Is(err, ErrExists) {
+				t.Error(err)
+			}
+			if err := Register(twoStops(fmt.Sprintf("Own%d", i))); err != nil {
+				t.Error(err)
+			}
+			List()
+			Get("Shared")
+		})
+	}
+	wg.Wait()
+	if wins != 1 {
+		t.Errorf("%d goroutines registered Shared, want 1", wins)
+	}
+	if n := len(List()) - len(ColorPalettes); n != 33 {
+		t.Errorf("%d palettes registered, want 33", n)
+	}
+}
@@ -0,0 +1,38 @@
+package palette
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Validate reports the first problem that keeps cm from being a usable
+// palette: a missing keyword or one with spaces in it (keywords appear
+// in URLs and command lines), no stops, a stop without a color, a step
+// outside [-1,1] (negative steps are relative, and NaN ones missing; see
+// Normalize) or a weight that isn't a finite number, 0 or above.
+func Validate(cm ColorMap) error {
+	if cm.Keyword == "" {
+		return errors.New("palette: keyword is empty")
+	}
+	if strings.ContainsFunc(cm.Keyword, unicode.IsSpace) {
+		return fmt.Errorf("palette %q: keyword contains spaces", cm.Keyword)
+	}
+	if len(cm.Colors) == 0 {
+		return fmt.Errorf("palette %q: no color stops", cm.Keyword)
+	}
+	for i, c := range cm.Colors {
+		if c.Color == nil {
+			return fmt.Errorf("palette %q: stop %d: no color", cm.Keyword, i)
+		}
+		if c.Step < -1 || c.Step > 1 {
+			return fmt.Errorf("palette %q: stop %d: step %g outside [-1,1]", cm.Keyword, i, c.Step)
+		}
+		if c.Weight < 0 || math.IsNaN(c.Weight) || math.IsInf(c.Weight, 0) {
+			return fmt.Errorf("palette %q: stop %d: weight %g must be a finite number, 0 or above", cm.Keyword, i, c.Weight)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package palette
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestValidateBuiltins(t *testing.T) {
+	for _, cm := range ColorPalettes {
+		if err := Validate(cm); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	black := color.RGBA{0, 0, 0, 0xff}
+	stops := func(c ...Color) []Color { return c }
+	for _, tc := range []struct {
+		name string
+		cm   ColorMap
+	}{
+		{"no keyword", ColorMap{Colors: stops(Color{Color: black})}},
+		{"space in keyword", ColorMap{Keyword: "Deep Sea", Colors: stops(Color{Color: black})}},
+		{"no stops", ColorMap{Keyword: "Empty"}},
+		{"nil color", ColorMap{Keyword: "Hole", Colors: stops(Color{Step: 0.5})}},
+		{"step past 1", ColorMap{Keyword: "Far", Colors: stops(Color{Step: 1.5, Color: black})}},
+		{"step below -1", ColorMap{Keyword: "Far", Colors: stops(Color{Step: -2, Color: black})}},
+		{"negative weight", ColorMap{Keyword: "Light", Colors: stops(Color{Color: black, Weight: -1})}},
+		{"NaN weight", ColorMap{Keyword: "Light", Colors: stops(Color{Color: black, Weight: math.NaN()})}},
+		{"infinite weight", ColorMap{Keyword: "Heavy", Colors: stops(Color{Color: black, Weight: math.Inf(1)})}},
+	} {
+		if err := Validate(tc.cm); err == nil {
+			t.Errorf("%s: Validate accepted it", tc.name)
+		}
+	}
+
+	// relative steps and missing (NaN) ones are left to Normalize
+	ok := ColorMap{Keyword: "Relative", Colors: stops(
+		Color{Step: 0, Color: black}, Color{Step: -0.5, Color: black}, Color{Step: math.NaN(), Color: black},
+	)}
+	if err := Validate(ok); err != nil {
+		t.Errorf("relative and missing steps: %v", err)
+	}
+}
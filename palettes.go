@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/whalelogic/mandlebrot/output"
+	"github.com/whalelogic/mandlebrot/overlay"
+	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// swatchWidth and swatchHeight size the preview strips in GET /palettes.
+const (
+	swatchWidth  = 128
+	swatchHeight = 12
+)
+
+// paletteInfo is one entry of GET /palettes. Swatch is a PNG strip of the
+// gradient, base64-encoded by encoding/json.
+type paletteInfo struct {
+	Name           string `json:"name"`
+	Swatch         []byte `json:"swatch"`
+	ColorblindSafe bool   `json:"colorblindSafe"`
+}
+
+// handlePalettes serves GET /palettes: every palette name with a swatch
+// and whether it is colorblind-safe.
+func handlePalettes(w http.ResponseWriter, r *http.Request) {
+	var out []paletteInfo
+	for _, name := range palette.List() {
+		cm := palette.Get(name)
+		swatch, err := swatchPNG(cm)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		out = append(out, paletteInfo{Name: name, Swatch: swatch, ColorblindSafe: colorblindSafe(cm)})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// handlePalette serves GET /palettes/{name}: the palette's stops.
+func handlePalette(w http.ResponseWriter, r *http.Request) {
+	cm := palette.Get(r.PathValue("name"))
+	if cm == nil {
+		http.Error(w, "unknown palette "+r.PathValue("name"), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, cm)
+}
+
+// handleAddPalette serves POST /palettes, registering the palette in the
+// body until the server exits. Names can't be reused, so cached tiles
+// never go stale: a taken name is a 409, and once palette.MaxRegistered
+// palettes have been added every further one is a 507.
+func handleAddPalette(w http.ResponseWriter, r *http.Request) {
+	var cm palette.ColorMap
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&cm); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := palette.Register(cm); err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, palette.ErrExists):
+			status = http.StatusConflict
+		case errors.Is(err, palette.ErrFull):
+			status = http.StatusInsufficientStorage
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Location", "/palettes/"+cm.Keyword)
+	writeJSON(w, http.StatusCreated, palette.Get(cm.Keyword))
+}
+
+// colorblindSafe reports whether cm keeps its detail for the common
+// color vision deficiencies: the flag for the built-in palettes, the
+// check itself for registered ones.
+func colorblindSafe(cm *palette.ColorMap) bool {
+	return cm.ColorblindSafe || palette.ColorblindSafe(cm)
+}
+
+// palettesMain runs the "palettes" subcommand. Without arguments it lists
+// the palettes, marking the colorblind-safe ones; "check NAME" simulates
+// protanopia, deuteranopia and tritanopia across the palette and reports
+// where neighbouring regions become indistinguishable, exiting with
+// status 1 if any do; "gpl NAME" prints the palette as a GIMP palette.
+func palettesMain(args []string) {
+	if len(args) == 0 {
+		for _, name := range palette.List() {
+			if colorblindSafe(palette.Get(name)) {
+				fmt.Printf("%-20s colorblind-safe\n", name)
+			} else {
+				fmt.Println(name)
+			}
+		}
+		return
+	}
+	if args[0] == "gpl" {
+		if len(args) != 2 {
+			fail("", fmt.Errorf("%w: palettes gpl: want one palette name, got %d", render.ErrInvalidOptions, len(args)-1))
+		}
+		cm := palette.Get(args[1])
+		if cm == nil {
+			fail("", fmt.Errorf("%w: palette %q: not one of %s", render.ErrInvalidOptions, args[1], strings.Join(palette.List(), ", ")))
+		}
+		fmt.Print(palette.ToGPL(cm))
+		return
+	}
+	if args[0] != "check" {
+		fail("", fmt.Errorf("%w: palettes %q: want no arguments, check NAME or gpl NAME", render.ErrInvalidOptions, args[0]))
+	}
+	fs := flag.NewFlagSet("palettes check", flag.ExitOnError)
+	regions := fs.Int("regions", palette.CheckRegions, "regions the palette is split into; neighbouring ones are compared")
+	minDeltaE := fs.Float64("min-delta-e", palette.CheckMinDeltaE, "CIEDE2000 difference below which neighbouring regions count as indistinguishable")
+	strips := fs.String("png", "", "also write the palette as seen with each deficiency to this PNG, one strip per row")
+	fs.Parse(args[1:])
+	if fs.NArg() != 1 {
+		fail("", fmt.Errorf("%w: palettes check: want one palette name, got %d", render.ErrInvalidOptions, fs.NArg()))
+	}
+	switch {
+	case *regions < 2:
+		fail("", fmt.Errorf("%w: -regions %d: must be at least 2", render.ErrInvalidOptions, *regions))
+	case !(*minDeltaE > 0):
+		fail("", fmt.Errorf("%w: -min-delta-e %g: must be positive", render.ErrInvalidOptions, *minDeltaE))
+	}
+	name := fs.Arg(0)
+	cm := palette.Get(name)
+	if cm == nil {
+		fail("", fmt.Errorf("%w: palette %q: not one of %s", render.ErrInvalidOptions, name, strings.Join(palette.List(), ", ")))
+	}
+
+	results := palette.Check(cm, *regions, *minDeltaE)
+	safe := true
+	for _, r := range results {
+		fmt.Printf("%-13s smallest ΔE %.2f", r.Deficiency, r.MinDeltaE)
+		if len(r.Collapses) == 0 {
+			fmt.Println(", ok")
+			continue
+		}
+		safe = false
+		fmt.Printf(", %d collapsed:\n", len(r.Collapses))
+		for _, c := range r.Collapses {
+			fmt.Printf("  t %.3f-%.3f: ΔE %.2f becomes %.2f\n", c.From, c.To, c.Normal, c.Simulated)
+		}
+	}
+	if *strips != "" {
+		img := simulatedStrips(cm, results)
+		if err := output.WriteFile(*strips, func(w io.Writer) error { return png.Encode(w, img) }); err != nil {
+			fail("", err)
+		}
+		fmt.Printf("Wrote simulated strips to %s\n", *strips)
+	}
+	if !safe {
+		fmt.Printf("%s is not colorblind-safe\n", name)
+		os.Exit(1)
+	}
+	fmt.Printf("%s is colorblind-safe\n", name)
+}
+
+// simulatedStrips draws cm as a gradient strip with normal vision and
+// then one with each deficiency of results simulated, labelled, with the
+// collapsed stretches marked by a bar under them.
+func simulatedStrips(cm *palette.ColorMap, results []palette.CheckResult) *image.RGBA {
+	const w, h, mark = 512, 48, 6
+	img := image.NewRGBA(image.Rect(0, 0, w, (h+mark)*(len(results)+1)))
+	draw.Draw(img, img.Rect, image.NewUniform(color.Black), image.Point{}, draw.Src)
+	row := func(i int, label string, sim func(color.RGBA) color.Color, collapses []palette.Collapse) {
+		y0 := i * (h + mark)
+		for x := range w {
+			c := sim(cm.Interpolate(float64(x) / (w - 1)))
+			for y := y0; y < y0+h; y++ {
+				img.Set(x, y, c)
+			}
+		}
+		for _, c := range collapses {
+			x0, x1 := int(c.From*(w-1)), int(c.To*(w-1))
+			draw.Draw(img, image.Rect(x0, y0+h+1, x1+1, y0+h+mark-1), image.NewUniform(color.White), image.Point{}, draw.Src)
+		}
+		overlay.DrawLabel(img.SubImage(image.Rect(0, y0, w, y0+h)).(*image.RGBA), overlay.Label{
+			Lines:      []string{label},
+			Corner:     overlay.TopLeft,
+			Color:      color.NRGBA{0xff, 0xff, 0xff, 0xff},
+			Background: color.NRGBA{0, 0, 0, 0x99},
+			Scale:      1,
+		})
+	}
+	row(0, "normal", func(c color.RGBA) color.Color { return c }, nil)
+	for i, r := range results {
+		row(i+1, string(r.Deficiency), func(c color.RGBA) color.Color { return palette.Simulate(c, r.Deficiency) }, r.Collapses)
+	}
+	return img
+}
+
+// swatchPNG draws cm left to right as a small PNG strip.
+func swatchPNG(cm *palette.ColorMap) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, cm.Image(swatchWidth, swatchHeight)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSON replies with v as JSON.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
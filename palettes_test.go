@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/palette"
+)
+
+func TestAddPaletteEndpoint(t *testing.T) {
+	srv := httptest.NewServer(testServer().handler())
+	defer srv.Close()
+	post := func(body string) *http.Response {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/palettes", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	// the registry lasts the process: names unique to this run keep the
+	// test repeatable with -count
+	name := fmt.Sprintf("Test%d", time.Now().UnixNano())
+	body := func(name string) string {
+		return `{"name": "` + name + `", "stops": [{"color": "#000000"}, {"color": "#ff8000", "weight": 2}]}`
+	}
+
+	for _, tc := range []struct {
+		body string
+		want int
+	}{
+		{`{"name": "x", "stops": [`, http.StatusBadRequest},
+		{`[1, 2]`, http.StatusBadRequest},
+		{`{"stops": [{"color": "#000000"}]}`, http.StatusBadRequest},
+		{`{"name": "NoStops", "stops": []}`, http.StatusBadRequest},
+		{`{"name": "BadColor", "stops": [{"color": "#00"}]}`, http.StatusBadRequest},
+		{`{"name": "BadStep", "stops": [{"step": 2, "color": "#000000"}]}`, http.StatusBadRequest},
+		{`{"name": "BadWeight", "stops": [{"color": "#000000", "weight": -1}]}`, http.StatusBadRequest},
+		{`{"name": "BadEasing", "stops": [{"color": "#000000", "easing": "bouncy"}]}`, http.StatusBadRequest},
+		{`{"name": "has space", "stops": [{"color": "#000000"}]}`, http.StatusBadRequest},
+		{body(palette.List()[0]), http.StatusConflict},
+		{`{"name": "Big", "stops": [{"color": "#` + strings.Repeat("0", 70<<10) + `"}]}`, http.StatusBadRequest},
+	} {
+		if resp := post(tc.body); resp.StatusCode != tc.want {
+			t.Errorf("%.60s: status %s, want %d", tc.body, resp.Status, tc.want)
+		}
+	}
+
+	registered := func() int { return len(palette.List()) - len(palette.ColorPalettes) }
+	if registered() < palette.MaxRegistered {
+		resp := post(body(name))
+		if resp.StatusCode != http.StatusCreated || resp.Header.Get("Location") != "/palettes/"+name {
+			t.Fatalf("status %s, Location %q", resp.Status, resp.Header.Get("Location"))
+		}
+		var cm palette.ColorMap
+		if err := json.NewDecoder(resp.Body).Decode(&cm); err != nil {
+			t.Fatal(err)
+		}
+		if cm.Keyword != name || len(cm.Colors) != 2 || cm.Colors[1].Step != 1 || cm.Colors[1].Weight != 2 {
+			t.Errorf("registered %+v", cm)
+		}
+		if resp := post(body(name)); resp.StatusCode != http.StatusConflict {
+			t.Errorf("same name again: status %s", resp.Status)
+		}
+	}
+
+	// fill the registry: every palette past the limit is refused
+	for i := 0; registered() < palette.MaxRegistered; i++ {
+		if err := palette.Register(palette.ColorMap{Keyword: fmt.Sprintf("%sFill%d", name, i), Colors: palette.Get(palette.List()[0]).Colors}); err != nil && !errors.Is(err, palette.ErrExists) {
+			t.Fatal(err)
+		}
+	}
+	if resp := post(body(name + "Extra")); resp.StatusCode != http.StatusInsufficientStorage {
+		t.Errorf("past the limit: status %s, want 507", resp.Status)
+	}
+	if palette.Get(name+"Extra") != nil {
+		t.Error("palette registered past the limit")
+	}
+}
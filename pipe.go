@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// pipeCommand is one line of input to the pipe subcommand. Fields left
+// out keep the values given by the subcommand's flags.
+type pipeCommand struct {
+	CX       float64 `json:"cx"`
+	CY       float64 `json:"cy"`
+	Zoom     float64 `json:"zoom"`
+	Rotate   float64 `json:"rotate"`
+	Iters    int     `json:"iters"`
+	Palette  string  `json:"palette"`
+	Coloring string  `json:"coloring"`
+}
+
+// pipeError is written to stderr, as one JSON line, for a command that
+// produced no frame.
+type pipeError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// pipeMain runs the "pipe" subcommand: it reads newline-delimited JSON
+// commands on stdin and writes one frame per command to stdout, in order.
+// Frames are PNGs preceded by their length as a big-endian uint32, or
+// with -format rgba bare width×height×4 RGBA bytes, ready for
+// ffmpeg -f rawvideo -pix_fmt rgba. A command that fails is reported on
+// stderr and skipped; the stream carries on with the next one.
+func pipeMain(args []string) {
+	fs := flag.NewFlagSet("pipe", flag.ExitOnError)
+	width := fs.Int("width", 640, "frame width in pixels")
+	height := fs.Int("height", 480, "frame height in pixels")
+	iters := fs.Int("iters", render.DefaultMaxIter, "default iteration count")
+	pal := fs.String("palette", render.DefaultPalette, "default palette")
+	coloring := fs.String("coloring", string(render.DefaultColoring), "default coloring mode ("+coloringNames()+")")
+	format := fs.String("format", "png", "frame encoding: png (length-prefixed) or rgba (raw)")
+	fs.Parse(args)
+
+	if *format != "png" && *format != "rgba" {
+		fail("", fmt.Errorf("%w: -format %q: want png or rgba", render.ErrUnsupportedFormat, *format))
+	}
+	opts, err := render.New(
+		render.WithSize(*width, *height),
+		render.WithIterations(*iters),
+		render.WithPaletteName(*pal),
+		render.WithColoring(render.Coloring(*coloring)),
+		render.WithDiscardBuffers(true),
+	)
+	if err != nil {
+		fail("invalid options:\n", err)
+	}
+	r, err := render.NewRenderer(opts)
+	if err != nil {
+		fail("", err)
+	}
+	defer r.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	base := render.DefaultBounds.FitToImage(*width, *height)
+	def := pipeCommand{
+		CX: real(base.Center()), CY: imag(base.Center()), Zoom: 1,
+		Iters: *iters, Palette: *pal, Coloring: *coloring,
+	}
+	if err := runPipe(ctx, r, os.Stdin, os.Stdout, os.Stderr, def, base, *width, *height, *format); err != nil {
+		fail("", err)
+	}
+}
+
+// runPipe renders a frame to stdout for each command line read from
+// stdin until it runs out, reporting the commands that fail on stderr.
+// It stops early only for a cancelled render or a failed read or write.
+func runPipe(ctx context.Context, r *render.Renderer, stdin io.Reader, stdout, stderr io.Writer, def pipeCommand, base coords.Bounds, width, height int, format string) error {
+	in := bufio.NewReader(stdin)
+	out := bufio.NewWriterSize(stdout, 1<<20)
+	errs := json.NewEncoder(stderr)
+	for n := 1; ; n++ {
+		// ReadBytes keeps reading across short reads until the newline,
+		// and returns a final unterminated line together with io.EOF
+		line, readErr := in.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			err := pipeFrame(ctx, r, out, line, def, base, width, height, format)
+			if errors.Is(err, render.ErrCancelled) || errors.Is(err, errPipeWrite) {
+				return err
+			}
+			if err != nil {
+				errs.Encode(pipeError{Line: n, Error: err.Error()})
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+var errPipeWrite = errors.New("writing frame")
+
+// pipeFrame renders the command in line and writes its frame to out.
+func pipeFrame(ctx context.Context, r *render.Renderer, out *bufio.Writer, line []byte, def pipeCommand, base coords.Bounds, width, height int, format string) error {
+	cmd := def
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cmd); err != nil {
+		return fmt.Errorf("%w: %v", render.ErrInvalidOptions, err)
+	}
+	if !(cmd.Zoom > 0) {
+		return fmt.Errorf("%w: zoom %g: must be positive", render.ErrInvalidViewport, cmd.Zoom)
+	}
+	vp := coords.Viewport{
+		Bounds:   base.ZoomedTo(complex(cmd.CX, cmd.CY), cmd.Zoom),
+		Rotation: cmd.Rotate,
+		Width:    width,
+		Height:   height,
+	}
+	res, err := r.Render(ctx, vp,
+		render.WithIterations(cmd.Iters),
+		render.WithPaletteName(cmd.Palette),
+		render.WithColoring(render.Coloring(cmd.Coloring)),
+	)
+	if err != nil {
+		return err
+	}
+
+	if format == "rgba" {
+		_, err = out.Write(res.Image.Pix)
+	} else {
+		var buf bytes.Buffer
+		if err := render.Encode(&buf, res.Image, "png"); err != nil {
+			return err
+		}
+		var n [4]byte
+		binary.BigEndian.PutUint32(n[:], uint32(buf.Len()))
+		out.Write(n[:])
+		_, err = out.Write(buf.Bytes())
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPipeWrite, err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// pipeSetup returns a renderer and the defaults pipeMain would start a
+// width×height stream with.
+func pipeSetup(t *testing.T, width, height int) (*render.Renderer, pipeCommand, coords.Bounds) {
+	t.Helper()
+	opts, err := render.New(render.WithSize(width, height), render.WithIterations(100), render.WithDiscardBuffers(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := render.NewRenderer(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	base := render.DefaultBounds.FitToImage(width, height)
+	def := pipeCommand{
+		CX: real(base.Center()), CY: imag(base.Center()), Zoom: 1,
+		Iters: 100, Palette: render.DefaultPalette, Coloring: string(render.DefaultColoring),
+	}
+	return r, def, base
+}
+
+func TestPipeFrames(t *testing.T) {
+	const width, height = 48, 32
+	r, def, base := pipeSetup(t, width, height)
+
+	// ten commands, each zoomed further in, so frames out of order differ;
+	// a bad line in the middle is reported and skipped
+	var in strings.Builder
+	var cmds []pipeCommand
+	for i := range 10 {
+		cmd := def
+		cmd.CX, cmd.CY, cmd.Zoom = -0.745, 0.11, float64(int(1)<<i)
+		if i%2 == 1 {
+			cmd.Palette = "MonochromeSlate"
+		}
+		cmds = append(cmds, cmd)
+		line, _ := json.Marshal(cmd)
+		in.Write(line)
+		in.WriteString("\n")
+		if i == 4 {
+			in.WriteString(`{"zoom": -1}` + "\n\n")
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	// one byte at a time, for the short reads of a slow writer
+	src := iotest.OneByteReader(strings.NewReader(in.String()))
+	if err := runPipe(context.Background(), r, src, &stdout, &stderr, def, base, width, height, "png"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, cmd := range cmds {
+		var n uint32
+		if err := binary.Read(&stdout, binary.BigEndian, &n); err != nil {
+			t.Fatalf("frame %d: reading its length: %v", i, err)
+		}
+		img, err := png.Decode(io.LimitReader(&stdout, int64(n)))
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		want, err := r.Render(context.Background(), coords.Viewport{
+			Bounds: base.ZoomedTo(complex(cmd.CX, cmd.CY), cmd.Zoom), Width: width, Height: height,
+		}, render.WithPaletteName(cmd.Palette))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !samePixels(img, want.Image) {
+			t.Errorf("frame %d isn't the render of command %d", i, i)
+		}
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("%d bytes after the last frame", stdout.Len())
+	}
+
+	var perr pipeError
+	if err := json.Unmarshal(stderr.Bytes(), &perr); err != nil {
+		t.Fatalf("stderr %q: %v", stderr.String(), err)
+	}
+	if perr.Line != 6 || !strings.Contains(perr.Error, "zoom") {
+		t.Errorf("reported %+v, want the zoom on line 6", perr)
+	}
+}
+
+func TestPipeRawFrames(t *testing.T) {
+	const width, height = 16, 12
+	r, def, base := pipeSetup(t, width, height)
+	var in strings.Builder
+	for i := range 3 {
+		// the last line has no newline
+		fmt.Fprintf(&in, `{"zoom": %d}`, i+1)
+		if i < 2 {
+			in.WriteString("\n")
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	if err := runPipe(context.Background(), r, strings.NewReader(in.String()), &stdout, &stderr, def, base, width, height, "rgba"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.Len(), 3*width*height*4; got != want {
+		t.Errorf("wrote %d bytes, want %d", got, want)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("stderr: %s", stderr.String())
+	}
+}
+
+// samePixels reports whether a decoded frame has the pixels of want.
+func samePixels(got image.Image, want *image.RGBA) bool {
+	if got.Bounds() != want.Bounds() {
+		return false
+	}
+	b := want.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r1, g1, b1, a1 := got.At(x, y).RGBA()
+			r2, g2, b2, a2 := want.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/output"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// runPipeline renders a view for each line of in, for -pipe. A line
+// holds "xmin xmax ymin ymax", optionally followed by a palette name;
+// blank lines and lines starting with # are skipped. Everything else
+// comes from base. Each view is rendered through r and written to
+// outTemplate expanded with output.ExpandNumberedTemplate, %n being the
+// line number, and "done N path" is printed to report. A line that
+// doesn't parse or render is warned about on stderr and skipped; a
+// frame that can't be written, or cancellation, ends the run.
+func runPipeline(ctx context.Context, in io.Reader, base render.Options, outTemplate string, r *render.Renderer, report io.Writer) error {
+	sc := bufio.NewScanner(in)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		path, err := pipelineFrame(ctx, line, n, base, outTemplate, r)
+		if errors.Is(err, render.ErrCancelled) || errors.Is(err, output.ErrOutput) {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "line %d: %v\n", n, err)
+			continue
+		}
+		fmt.Fprintf(report, "done %d %s\n", n, path)
+	}
+	return sc.Err()
+}
+
+// pipelineFrame renders line n for runPipeline and returns the file it
+// wrote.
+func pipelineFrame(ctx context.Context, line string, n int, base render.Options, outTemplate string, r *render.Renderer) (string, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 4 && len(fields) != 5 {
+		return "", fmt.Errorf("%w: %q: want xmin xmax ymin ymax [palette]", render.ErrInvalidViewport, line)
+	}
+	var v [4]float64
+	for i := range v {
+		var err error
+		if v[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return "", fmt.Errorf("%w: %q: not a number", render.ErrInvalidViewport, fields[i])
+		}
+	}
+	vp := base.Viewport()
+	vp.Bounds = coords.Bounds{Xmin: v[0], Xmax: v[1], Ymin: v[2], Ymax: v[3]}
+	var overrides []render.Option
+	if len(fields) == 5 {
+		overrides = append(overrides, render.WithPaletteName(fields[4]))
+	}
+	res, err := r.Render(ctx, vp, overrides...)
+	if err != nil {
+		return "", err
+	}
+	path := output.ExpandNumberedTemplate(outTemplate, res.Options, n)
+	format, err := render.FormatFromPath(path)
+	if err != nil {
+		return "", err
+	}
+	return path, writeFrame(ctx, path, res, format)
+}
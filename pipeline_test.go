@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func TestRunPipeline(t *testing.T) {
+	base, err := render.New(render.WithSize(32, 24), render.WithIterations(150), render.WithPaletteName("ThermalHeat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := render.NewRenderer(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "frame_%n_%p.png")
+	in := strings.Join([]string{
+		"# a zoom toward the seahorse valley",
+		"-2 1 -1.2 1.2",
+		"",
+		"-1 -0.5 -0.1875 0.1875 MonochromeSlate",
+		"-1 -0.5 oops 0.1875",
+		"-0.8 -0.7 0.05 0.125",
+		"-0.8 -0.7 0.05 0.125 NoSuchPalette",
+	}, "\n")
+
+	var report bytes.Buffer
+	var runErr error
+	warnings := captureStderr(t, func() {
+		runErr = runPipeline(context.Background(), strings.NewReader(in), base, tmpl, r, &report)
+	})
+	if runErr != nil {
+		t.Fatal(runErr)
+	}
+	want := []string{"frame_2_ThermalHeat.png", "frame_4_MonochromeSlate.png", "frame_6_ThermalHeat.png"}
+	if got := dirNames(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("wrote %v, want %v", got, want)
+	}
+	if got := report.String(); got != "done 2 "+filepath.Join(dir, want[0])+"\n"+
+		"done 4 "+filepath.Join(dir, want[1])+"\n"+
+		"done 6 "+filepath.Join(dir, want[2])+"\n" {
+		t.Errorf("report %q", got)
+	}
+	if !strings.Contains(warnings, "line 5: ") || !strings.Contains(warnings, "line 7: ") || strings.Count(warnings, "\n") != 2 {
+		t.Errorf("warnings %q", warnings)
+	}
+
+	// each file is the view its line asked for
+	opts, err := render.New(render.WithSize(32, 24), render.WithIterations(150), render.WithPaletteName("MonochromeSlate"),
+		render.WithViewport(coords.Bounds{Xmin: -1, Xmax: -0.5, Ymin: -0.1875, Ymax: 0.1875}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filepath.Join(dir, want[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !samePixels(img, res.Image) {
+		t.Error("line 4's file differs from a render of its view")
+	}
+}
+
+// dirNames returns the sorted names of the files in dir.
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,73 @@
+// Package postprocess filters finished images: blurs, sharpening, edge
+// detection and color changes, chained into a Pipeline.
+package postprocess
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// A ProcessingStep filters an image. It returns the result, which may
+// be img itself when the step has nothing to do, and otherwise leaves
+// img as it was.
+type ProcessingStep func(*image.RGBA) *image.RGBA
+
+// Pipeline is a chain of steps, applied in the order they were added.
+// The zero value is an empty pipeline, which changes nothing.
+type Pipeline struct {
+	steps []ProcessingStep
+}
+
+// Add appends step to p.
+func (p *Pipeline) Add(step ProcessingStep) {
+	p.steps = append(p.steps, step)
+}
+
+// Len returns the number of steps in p.
+func (p *Pipeline) Len() int { return len(p.steps) }
+
+// Apply runs img through every step of p in turn and returns the
+// result, img itself if p is empty.
+func (p *Pipeline) Apply(img *image.RGBA) *image.RGBA {
+	for _, step := range p.steps {
+		img = step(img)
+	}
+	return img
+}
+
+// StepNames lists the steps Parse knows.
+var StepNames = []string{"blur", "sharpen", "edges", "grayscale", "invert"}
+
+// Parse builds a pipeline from a comma-separated list of steps, as in
+// "blur:1.5,sharpen". blur takes its standard deviation in pixels after
+// a colon; the other steps take no argument.
+func Parse(s string) (Pipeline, error) {
+	var p Pipeline
+	for _, field := range strings.Split(s, ",") {
+		name, arg, hasArg := strings.Cut(strings.TrimSpace(field), ":")
+		if hasArg && name != "blur" {
+			return Pipeline{}, fmt.Errorf("postprocess: %q: %s takes no argument", field, name)
+		}
+		switch name {
+		case "blur":
+			sigma, err := strconv.ParseFloat(arg, 64)
+			if err != nil || !(sigma >= 0 && sigma <= maxSigma) {
+				return Pipeline{}, fmt.Errorf("postprocess: %q: want blur:sigma with sigma from 0 to %g pixels", field, float64(maxSigma))
+			}
+			p.Add(GaussianBlur(sigma))
+		case "sharpen":
+			p.Add(Sharpen())
+		case "edges":
+			p.Add(EdgeDetect())
+		case "grayscale":
+			p.Add(Grayscale())
+		case "invert":
+			p.Add(Invert())
+		default:
+			return Pipeline{}, fmt.Errorf("postprocess: %q: not one of %s", field, strings.Join(StepNames, ", "))
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,54 @@
+package postprocess
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPipelineApply(t *testing.T) {
+	img := noise(24, 16)
+	var empty Pipeline
+	if out := empty.Apply(img); out != img {
+		t.Error("an empty pipeline made a new image")
+	}
+
+	// steps run in the order added
+	var p Pipeline
+	p.Add(Invert())
+	p.Add(Grayscale())
+	if p.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", p.Len())
+	}
+	if got, want := p.Apply(img), Grayscale()(Invert()(img)); !bytes.Equal(got.Pix, want.Pix) {
+		t.Error("Apply differs from the steps in order")
+	}
+}
+
+func TestParse(t *testing.T) {
+	img := noise(24, 16)
+	p, err := Parse("blur:1.5, sharpen,invert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", p.Len())
+	}
+	if got, want := p.Apply(img), Invert()(Sharpen()(GaussianBlur(1.5)(img))); !bytes.Equal(got.Pix, want.Pix) {
+		t.Error("parsed pipeline differs from its steps")
+	}
+	for _, name := range StepNames {
+		s := name
+		if name == "blur" {
+			s = "blur:0"
+		}
+		if _, err := Parse(s); err != nil {
+			t.Errorf("Parse(%q): %v", s, err)
+		}
+	}
+
+	for _, s := range []string{"", "blur", "blur:", "blur:-1", "blur:65", "blur:NaN", "sharpen:2", "emboss", "invert,,grayscale"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q): no error", s)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package postprocess
+
+import (
+	"image"
+	"math"
+	"runtime"
+
+	"github.com/whalelogic/mandlebrot/internal/blur"
+	"github.com/whalelogic/mandlebrot/internal/parallel"
+)
+
+// maxSigma is the widest blur Parse accepts, in pixels.
+const maxSigma = 64
+
+// The steps work on the stored 8-bit values, premultiplied as in
+// image.RGBA, so a blur averages sRGB values rather than light, and a
+// translucent pixel counts for its alpha.
+
+// GaussianBlur blurs with a Gaussian of standard deviation sigma pixels,
+// cut off at 3 deviations, as a horizontal pass followed by a vertical
+// one. Near the edges the weights that fall outside the image are left
+// out and the rest scaled back up, so the border neither darkens nor
+// brightens. A sigma of 0 or less returns the image as it is.
+func GaussianBlur(sigma float64) ProcessingStep {
+	return func(img *image.RGBA) *image.RGBA {
+		if !(sigma > 0) {
+			return img
+		}
+		w, h := img.Rect.Dx(), img.Rect.Dy()
+		kernel := blur.Gaussian(sigma)
+		r := kernel.Radius()
+		tmp := make([][4]float32, w*h)
+		parallel.Rows(h, runtime.GOMAXPROCS(0), func(y int) {
+			src := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+			for x := range w {
+				var sum [4]float32
+				var wsum float32
+				for k := max(-r, -x); k <= min(r, w-1-x); k++ {
+					wt := kernel.At(k)
+					p := src[4*(x+k) : 4*(x+k)+4 : 4*(x+k)+4]
+					for c := range 4 {
+						sum[c] += wt * float32(p[c])
+					}
+					wsum += wt
+				}
+				for c := range 4 {
+					tmp[y*w+x][c] = sum[c] / wsum
+				}
+			}
+		})
+		out := image.NewRGBA(img.Rect)
+		parallel.Rows(h, runtime.GOMAXPROCS(0), func(y int) {
+			dst := out.Pix[y*out.Stride:]
+			for x := range w {
+				var sum [4]float32
+				var wsum float32
+				for k := max(-r, -y); k <= min(r, h-1-y); k++ {
+					wt := kernel.At(k)
+					for c := range 4 {
+						sum[c] += wt * tmp[(y+k)*w+x][c]
+					}
+					wsum += wt
+				}
+				for c := range 4 {
+					dst[4*x+c] = to8(sum[c] / wsum)
+				}
+			}
+		})
+		return out
+	}
+}
+
+// Sharpen boosts each pixel against its four neighbours with the
+// kernel 5 at the center and -1 above, below and to either side, which
+// leaves flat areas alone and steepens edges. Pixels past the border
+// count as copies of the nearest edge pixel.
+func Sharpen() ProcessingStep {
+	return func(img *image.RGBA) *image.RGBA {
+		w, h := img.Rect.Dx(), img.Rect.Dy()
+		out := image.NewRGBA(img.Rect)
+		parallel.Rows(h, runtime.GOMAXPROCS(0), func(y int) {
+			dst := out.Pix[y*out.Stride:]
+			for x := range w {
+				p := pixel(img, x, y)
+				n := [4][]uint8{pixel(img, x-1, y), pixel(img, x+1, y), pixel(img, x, y-1), pixel(img, x, y+1)}
+				var v [4]uint8
+				for c := range 4 {
+					s := 5 * float32(p[c])
+					for _, q := range n {
+						s -= float32(q[c])
+					}
+					v[c] = to8(s)
+				}
+				// premultiplied colors can't be brighter than their alpha
+				for c := range 3 {
+					v[c] = min(v[c], v[3])
+				}
+				copy(dst[4*x:4*x+4], v[:])
+			}
+		})
+		return out
+	}
+}
+
+// EdgeDetect replaces the image with the strength of its edges: the
+// magnitude of the Sobel gradient of each pixel's luminance, in gray,
+// white where it passes 255. Alpha is kept, and the gray is held within
+// it. Pixels past the border count as copies of the nearest edge pixel.
+func EdgeDetect() ProcessingStep {
+	return func(img *image.RGBA) *image.RGBA {
+		w, h := img.Rect.Dx(), img.Rect.Dy()
+		lum := make([]float32, w*h)
+		parallel.Rows(h, runtime.GOMAXPROCS(0), func(y int) {
+			for x := range w {
+				lum[y*w+x] = luminance(pixel(img, x, y))
+			}
+		})
+		at := func(x, y int) float32 {
+			return lum[min(max(y, 0), h-1)*w+min(max(x, 0), w-1)]
+		}
+		out := image.NewRGBA(img.Rect)
+		parallel.Rows(h, runtime.GOMAXPROCS(0), func(y int) {
+			dst := out.Pix[y*out.Stride:]
+			for x := range w {
+				gx := at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)
+				gy := at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)
+				a := pixel(img, x, y)[3]
+				v := min(to8(float32(math.Hypot(float64(gx), float64(gy)))), a)
+				dst[4*x], dst[4*x+1], dst[4*x+2], dst[4*x+3] = v, v, v, a
+			}
+		})
+		return out
+	}
+}
+
+// Grayscale replaces each color with its luminance, the Rec. 709
+// weighting of its channels, keeping alpha.
+func Grayscale() ProcessingStep {
+	return func(img *image.RGBA) *image.RGBA {
+		w, h := img.Rect.Dx(), img.Rect.Dy()
+		out := image.NewRGBA(img.Rect)
+		parallel.Rows(h, runtime.GOMAXPROCS(0), func(y int) {
+			dst := out.Pix[y*out.Stride:]
+			for x := range w {
+				p := pixel(img, x, y)
+				v := min(to8(luminance(p)), p[3])
+				dst[4*x], dst[4*x+1], dst[4*x+2], dst[4*x+3] = v, v, v, p[3]
+			}
+		})
+		return out
+	}
+}
+
+// Invert turns each color into its negative, keeping alpha: in
+// premultiplied values each channel becomes alpha minus the channel, so
+// a translucent pixel inverts within its own opacity. Inverting twice
+// gives back the image exactly.
+func Invert() ProcessingStep {
+	return func(img *image.RGBA) *image.RGBA {
+		w, h := img.Rect.Dx(), img.Rect.Dy()
+		out := image.NewRGBA(img.Rect)
+		parallel.Rows(h, runtime.GOMAXPROCS(0), func(y int) {
+			dst := out.Pix[y*out.Stride:]
+			for x := range w {
+				p := pixel(img, x, y)
+				dst[4*x], dst[4*x+1], dst[4*x+2], dst[4*x+3] = p[3]-p[0], p[3]-p[1], p[3]-p[2], p[3]
+			}
+		})
+		return out
+	}
+}
+
+// pixel returns the four channels of pixel (x, y) of img, counted from
+// the top left corner of its bounds and clamped to them.
+func pixel(img *image.RGBA, x, y int) []uint8 {
+	x = min(max(x, 0), img.Rect.Dx()-1)
+	y = min(max(y, 0), img.Rect.Dy()-1)
+	i := img.PixOffset(img.Rect.Min.X+x, img.Rect.Min.Y+y)
+	return img.Pix[i : i+4 : i+4]
+}
+
+// luminance returns the Rec. 709 luminance of the channels of p, 0 to
+// 255.
+func luminance(p []uint8) float32 {
+	return 0.2126*float32(p[0]) + 0.7152*float32(p[1]) + 0.0722*float32(p[2])
+}
+
+// to8 rounds v to the nearest 8-bit value, clamped to [0,255].
+func to8(v float32) uint8 {
+	return uint8(min(max(v+0.5, 0), 255))
+}
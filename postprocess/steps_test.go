@@ -0,0 +1,193 @@
+package postprocess
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand/v2"
+	"testing"
+)
+
+// noise returns a w×h image of random translucent pixels, premultiplied:
+// no channel above its alpha.
+func noise(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	r := rand.New(rand.NewPCG(uint64(w), uint64(h)))
+	for i := 0; i < len(img.Pix); i += 4 {
+		a := uint8(r.IntN(256))
+		for c := range 3 {
+			img.Pix[i+c] = uint8(r.IntN(int(a) + 1))
+		}
+		img.Pix[i+3] = a
+	}
+	return img
+}
+
+// flat returns a w×h image of the single color c.
+func flat(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+// roughness returns the sum of squared differences between horizontally
+// and vertically neighbouring channel values, the high-frequency energy
+// of img.
+func roughness(img *image.RGBA) float64 {
+	var e float64
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	for y := range h {
+		for x := range w {
+			p := pixel(img, x, y)
+			for _, q := range [][]uint8{pixel(img, x+1, y), pixel(img, x, y+1)} {
+				for c := range 4 {
+					d := float64(p[c]) - float64(q[c])
+					e += d * d
+				}
+			}
+		}
+	}
+	return e
+}
+
+// premultiplied reports whether no channel of img exceeds its alpha.
+func premultiplied(img *image.RGBA) bool {
+	for i := 0; i < len(img.Pix); i += 4 {
+		if a := img.Pix[i+3]; img.Pix[i] > a || img.Pix[i+1] > a || img.Pix[i+2] > a {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGaussianBlurZero(t *testing.T) {
+	img := noise(40, 30)
+	orig := bytes.Clone(img.Pix)
+	for _, sigma := range []float64{0, -1} {
+		if out := GaussianBlur(sigma)(img); out != img || !bytes.Equal(out.Pix, orig) {
+			t.Errorf("GaussianBlur(%v) changed the image", sigma)
+		}
+	}
+}
+
+func TestGaussianBlurSmooths(t *testing.T) {
+	img := noise(80, 60)
+	orig := bytes.Clone(img.Pix)
+	out := GaussianBlur(1)(img)
+	if !bytes.Equal(img.Pix, orig) {
+		t.Error("GaussianBlur(1) changed its input")
+	}
+	before, after := roughness(img), roughness(out)
+	if after > before/10 {
+		t.Errorf("neighbour difference energy %g after blurring, %g before", after, before)
+	}
+	if !premultiplied(out) {
+		t.Error("blurred image has channels above alpha")
+	}
+	// a wider blur smooths more
+	if wider := roughness(GaussianBlur(3)(img)); wider >= after {
+		t.Errorf("sigma 3 leaves %g, sigma 1 %g", wider, after)
+	}
+}
+
+func TestFlatImagesUnchanged(t *testing.T) {
+	c := color.RGBA{0x40, 0x80, 0x20, 0xc0}
+	img := flat(17, 9, c)
+	for name, step := range map[string]ProcessingStep{"blur": GaussianBlur(2.5), "sharpen": Sharpen()} {
+		if out := step(img); !bytes.Equal(out.Pix, img.Pix) {
+			t.Errorf("%s changed a flat image", name)
+		}
+	}
+	out := EdgeDetect()(img)
+	for i := 0; i < len(out.Pix); i += 4 {
+		if out.Pix[i] != 0 || out.Pix[i+3] != c.A {
+			t.Fatalf("edges of a flat image: %v", out.Pix[i:i+4])
+		}
+	}
+}
+
+func TestEdgesAndSharpen(t *testing.T) {
+	// dark on the left half, light on the right
+	img := flat(20, 6, color.RGBA{0x40, 0x40, 0x40, 0xff})
+	for y := range 6 {
+		for x := 10; x < 20; x++ {
+			img.SetRGBA(x, y, color.RGBA{0xc0, 0xc0, 0xc0, 0xff})
+		}
+	}
+	edges := EdgeDetect()(img)
+	for x := range 20 {
+		v := edges.RGBAAt(x, 3).R
+		if onEdge := x == 9 || x == 10; onEdge != (v > 0) {
+			t.Errorf("edge strength %d at column %d", v, x)
+		}
+	}
+	sharp := Sharpen()(img)
+	// the step overshoots on both sides and stays flat away from it
+	if dark, light := sharp.RGBAAt(9, 3).R, sharp.RGBAAt(10, 3).R; dark >= 0x40 || light <= 0xc0 {
+		t.Errorf("sharpened edge %#x | %#x, want below 0x40 and above 0xc0", dark, light)
+	}
+	if a, b := sharp.RGBAAt(2, 3), sharp.RGBAAt(17, 3); a != img.RGBAAt(2, 3) || b != img.RGBAAt(17, 3) {
+		t.Errorf("sharpen changed flat areas: %v, %v", a, b)
+	}
+}
+
+func TestGrayscale(t *testing.T) {
+	img := noise(16, 16)
+	out := Grayscale()(img)
+	for i := 0; i < len(out.Pix); i += 4 {
+		if p := out.Pix[i : i+4]; p[0] != p[1] || p[1] != p[2] || p[3] != img.Pix[i+3] || p[0] > p[3] {
+			t.Fatalf("pixel %d: %v from %v", i/4, p, img.Pix[i:i+4])
+		}
+	}
+	// Rec. 709 weights green most
+	green := Grayscale()(flat(1, 1, color.RGBA{0, 0xff, 0, 0xff})).Pix[0]
+	red := Grayscale()(flat(1, 1, color.RGBA{0xff, 0, 0, 0xff})).Pix[0]
+	if green != 182 || red != 54 {
+		t.Errorf("green %d, red %d, want 182 and 54", green, red)
+	}
+}
+
+func TestInvertTwiceIsIdentity(t *testing.T) {
+	img := noise(33, 21)
+	inv := Invert()(img)
+	if bytes.Equal(inv.Pix, img.Pix) {
+		t.Error("Invert left the image as it was")
+	}
+	if !premultiplied(inv) {
+		t.Error("inverted image has channels above alpha")
+	}
+	if back := Invert()(inv); !bytes.Equal(back.Pix, img.Pix) {
+		t.Error("Invert(Invert(img)) differs from img")
+	}
+	if got := Invert()(flat(1, 1, color.RGBA{0x10, 0x20, 0x30, 0xff})).RGBAAt(0, 0); got != (color.RGBA{0xef, 0xdf, 0xcf, 0xff}) {
+		t.Errorf("inverted %v", got)
+	}
+}
+
+func TestStepsOnSubImage(t *testing.T) {
+	// a sub-image starts away from the origin and shares its parent's
+	// rows; the steps read and write within its bounds only
+	parent := noise(30, 20)
+	sub := parent.SubImage(image.Rect(5, 4, 25, 14)).(*image.RGBA)
+	own := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for y := range 10 {
+		copy(own.Pix[y*own.Stride:(y+1)*own.Stride], sub.Pix[y*sub.Stride:y*sub.Stride+own.Stride])
+	}
+	for name, step := range map[string]ProcessingStep{
+		"blur": GaussianBlur(1.5), "sharpen": Sharpen(), "edges": EdgeDetect(), "grayscale": Grayscale(), "invert": Invert(),
+	} {
+		got, want := step(sub), step(own)
+		if got.Rect != sub.Rect {
+			t.Errorf("%s: bounds %v, want %v", name, got.Rect, sub.Rect)
+		}
+		for y := range 10 {
+			for x := range 20 {
+				if g, w := got.RGBAAt(5+x, 4+y), want.RGBAAt(x, y); g != w {
+					t.Fatalf("%s: pixel (%d,%d) %v, want %v", name, x, y, g, w)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// Adjust is a color correction applied to every rendered pixel, after
+// coloring and before the pixel is stored. It works in linear light, in
+// the order of the fields. The zero value changes nothing.
+type Adjust struct {
+	Exposure   float64 // in stops: each channel is scaled by 2^Exposure
+	Brightness float64 // added to each channel, within [-1,1]
+	Contrast   float64 // channels move away from middle grey by a factor 1+Contrast; -1 is flat grey
+	Saturation float64 // colors move away from their luminance by a factor 1+Saturation; -1 is greyscale
+}
+
+// middleGrey is the linear value Contrast pivots about, 18% reflectance.
+const middleGrey = 0.18
+
+// validate reports fields outside the ranges documented at Adjust.
+func (a Adjust) validate() []error {
+	var errs []error
+	for _, f := range []struct {
+		name string
+		v    float64
+	}{{"exposure", a.Exposure}, {"brightness", a.Brightness}, {"contrast", a.Contrast}, {"saturation", a.Saturation}} {
+		if math.IsNaN(f.v) || math.IsInf(f.v, 0) {
+			errs = append(errs, fmt.Errorf("%w: %s %g: must be finite", ErrInvalidOptions, f.name, f.v))
+		}
+	}
+	if a.Brightness < -1 || a.Brightness > 1 {
+		errs = append(errs, fmt.Errorf("%w: brightness %g: must be within [-1,1]", ErrInvalidOptions, a.Brightness))
+	}
+	if a.Contrast < -1 {
+		errs = append(errs, fmt.Errorf("%w: contrast %g: must be at least -1", ErrInvalidOptions, a.Contrast))
+	}
+	if a.Saturation < -1 {
+		errs = append(errs, fmt.Errorf("%w: saturation %g: must be at least -1", ErrInvalidOptions, a.Saturation))
+	}
+	return errs
+}
+
+// linear adjusts one color given as linear channel values. Values may
+// leave [0,1] along the way and are only clipped at the end, so a
+// brightness cut after an exposure boost brings back detail the boost
+// pushed past white.
+func (a Adjust) linear(v [3]float64) [3]float64 {
+	k := math.Exp2(a.Exposure)
+	for i := range v {
+		v[i] = v[i]*k + a.Brightness
+		v[i] = middleGrey + (v[i]-middleGrey)*(1+a.Contrast)
+	}
+	// Rec. 709 luminance, the weights of the sRGB primaries
+	y := 0.2126*v[0] + 0.7152*v[1] + 0.0722*v[2]
+	for i := range v {
+		v[i] = clamp01(y + (v[i]-y)*(1+a.Saturation))
+	}
+	return v
+}
+
+// channels adjusts an 8-bit sRGB color with straight alpha.
+func (a Adjust) channels(r, g, b uint8) (uint8, uint8, uint8) {
+	v := a.linear([3]float64{srgbDecode8[r], srgbDecode8[g], srgbDecode8[b]})
+	q := func(x float64) uint8 { return uint8(math.Round(255 * srgbEncode(x))) }
+	return q(v[0]), q(v[1]), q(v[2])
+}
+
+// nrgba adjusts c, keeping its alpha.
+func (a Adjust) nrgba(c color.NRGBA) color.NRGBA {
+	if a == (Adjust{}) {
+		return c
+	}
+	c.R, c.G, c.B = a.channels(c.R, c.G, c.B)
+	return c
+}
+
+// rgba adjusts the premultiplied color c, keeping its alpha. The color
+// is adjusted unpremultiplied, as nrgba would.
+func (a Adjust) rgba(c color.RGBA) color.RGBA {
+	if a == (Adjust{}) || c.A == 0 {
+		return c
+	}
+	if c.A == 0xff {
+		c.R, c.G, c.B = a.channels(c.R, c.G, c.B)
+		return c
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	n.R, n.G, n.B = a.channels(n.R, n.G, n.B)
+	return color.RGBAModel.Convert(n).(color.RGBA)
+}
+
+// Apply adjusts every pixel of img in place, for images colored outside
+// a render, such as Result.Cycle frames. Translucent pixels are adjusted
+// unpremultiplied.
+func (a Adjust) Apply(img *image.RGBA) {
+	if a == (Adjust{}) {
+		return
+	}
+	b := img.Rect
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			img.SetRGBA(x, y, a.rgba(img.RGBAAt(x, y)))
+		}
+	}
+}
+
+// srgbDecode8 is srgbDecode for the 256 8-bit channel values.
+var srgbDecode8 = func() (t [256]float64) {
+	for i := range t {
+		t[i] = srgbDecode(float64(i) / 255)
+	}
+	return t
+}()
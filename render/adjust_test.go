@@ -0,0 +1,102 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"image/color"
+	"testing"
+)
+
+func TestAdjustKnownValues(t *testing.T) {
+	grey := func(v uint8) color.NRGBA { return color.NRGBA{v, v, v, 0xff} }
+	for _, tc := range []struct {
+		name string
+		a    Adjust
+		in   color.NRGBA
+		want color.NRGBA
+	}{
+		// 128 is 0.2159 linear, doubled 0.4319, 176 encoded
+		{"exposure +1", Adjust{Exposure: 1}, grey(128), grey(176)},
+		{"exposure -1", Adjust{Exposure: -1}, grey(176), grey(128)},
+		// clipped at white, never wrapped; 1 is only 0.0003 linear
+		{"exposure +10", Adjust{Exposure: 10}, color.NRGBA{200, 20, 1, 0xff}, color.NRGBA{255, 255, 151, 0xff}},
+		{"exposure +10 on black", Adjust{Exposure: 10}, grey(0), grey(0)},
+		{"brightness +1", Adjust{Brightness: 1}, grey(0), grey(255)},
+		{"brightness -1", Adjust{Brightness: -1}, grey(255), grey(0)},
+		{"brightness +0.5", Adjust{Brightness: 0.5}, grey(0), grey(188)},
+		// flat at middle grey, 0.18 linear
+		{"contrast -1", Adjust{Contrast: -1}, color.NRGBA{255, 0, 40, 0xff}, grey(118)},
+		{"contrast +1", Adjust{Contrast: 1}, grey(255), grey(255)},
+		{"contrast +1 on dark", Adjust{Contrast: 1}, grey(50), grey(0)},
+		// red's luminance is 0.2126 linear, 127 encoded
+		{"saturation -1", Adjust{Saturation: -1}, color.NRGBA{255, 0, 0, 0xff}, grey(127)},
+		{"saturation on grey", Adjust{Saturation: 2}, grey(90), grey(90)},
+		// 0.578 and 0.127 linear about a luminance of 0.223
+		{"saturation +1", Adjust{Saturation: 1}, color.NRGBA{200, 100, 100, 0xff}, color.NRGBA{247, 50, 50, 0xff}},
+		// alpha is kept
+		{"alpha", Adjust{Exposure: 1}, color.NRGBA{128, 128, 128, 0x40}, color.NRGBA{176, 176, 176, 0x40}},
+	} {
+		if got := tc.a.nrgba(tc.in); got != tc.want {
+			t.Errorf("%s: %v becomes %v, want %v", tc.name, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestAdjustIdentity(t *testing.T) {
+	// the zero value, and adjustments too small to move a channel
+	for _, a := range []Adjust{{}, {Exposure: 1e-9}, {Contrast: 1e-9}, {Saturation: 1e-9}} {
+		for v := range 256 {
+			for _, c := range []color.NRGBA{{uint8(v), uint8(v), uint8(v), 0xff}, {uint8(v), uint8(255 - v), uint8(v / 2), 0xff}} {
+				if got := a.nrgba(c); got != c {
+					t.Fatalf("%+v: %v becomes %v", a, c, got)
+				}
+			}
+		}
+	}
+}
+
+func TestAdjustPremultiplied(t *testing.T) {
+	a := Adjust{Exposure: 1}
+	// 64 at alpha 0x80 is 127 unpremultiplied, which exposure takes to
+	// 175, and back to 87
+	c := color.RGBA{64, 64, 64, 0x80}
+	want := color.RGBA{87, 87, 87, 0x80}
+	if got := a.rgba(c); got != want {
+		t.Errorf("rgba(%v) = %v, want %v", c, got, want)
+	}
+	if got := a.rgba(color.RGBA{}); got != (color.RGBA{}) {
+		t.Errorf("transparent became %v", got)
+	}
+}
+
+func TestAdjustRender(t *testing.T) {
+	a := Adjust{Exposure: 0.5, Brightness: -0.02, Contrast: 0.3, Saturation: -0.4}
+	plain, err := Render(context.Background(), smallOptions(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	adjusted, err := Render(context.Background(), smallOptions(t, WithAdjust(a)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// adjusting in the render is the same as adjusting the image after
+	a.Apply(plain.Image)
+	for i := range plain.Image.Pix {
+		if plain.Image.Pix[i] != adjusted.Image.Pix[i] {
+			t.Fatalf("byte %d: %d adjusted after, %d in the render", i, plain.Image.Pix[i], adjusted.Image.Pix[i])
+		}
+	}
+}
+
+func TestAdjustValidate(t *testing.T) {
+	for _, a := range []Adjust{
+		{Brightness: 1.5}, {Brightness: -1.01}, {Contrast: -2}, {Saturation: -1.5},
+	} {
+		if _, err := New(WithAdjust(a)); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("%+v: %v", a, err)
+		}
+	}
+	if _, err := New(WithAdjust(Adjust{Exposure: 1}), WithOutputHSL(true)); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("with -output-hsl: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+)
+
+// APNGWriter writes an animated PNG one frame at a time, so a long
+// animation never has to be held in memory. Every frame must be the size
+// of the first. The animation loops forever.
+type APNGWriter struct {
+	w       io.Writer
+	frames  int
+	fps     int
+	text    []TextChunk
+	written int
+	seq     uint32
+	size    image.Point
+	ihdr    []byte
+	encoder png.Encoder
+	buf     bytes.Buffer
+}
+
+// NewAPNGWriter returns a writer for an animation of frames frames shown
+// at fps frames per second. text is written after the header, as with
+// EncodeWithText.
+func NewAPNGWriter(w io.Writer, frames, fps int, text []TextChunk) (*APNGWriter, error) {
+	if frames < 1 || fps < 1 || fps > 0xffff {
+		return nil, fmt.Errorf("%w: animation of %d frames at %d fps", ErrInvalidOptions, frames, fps)
+	}
+	return &APNGWriter{w: w, frames: frames, fps: fps, text: text}, nil
+}
+
+// WriteFrame appends img to the animation.
+func (a *APNGWriter) WriteFrame(img image.Image) error {
+	if a.written == a.frames {
+		return fmt.Errorf("apng: all %d frames already written", a.frames)
+	}
+	size := img.Bounds().Size()
+	if a.written > 0 && size != a.size {
+		return fmt.Errorf("apng: frame %d is %v, want %v", a.written, size, a.size)
+	}
+	a.buf.Reset()
+	if err := a.encoder.Encode(&a.buf, img); err != nil {
+		return err
+	}
+	ihdr, idats, err := splitPNG(a.buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// image/png picks the color type per image (RGB for an opaque one),
+	// but every frame has to share the first one's header
+	if a.written > 0 && !bytes.Equal(ihdr, a.ihdr) {
+		return fmt.Errorf("apng: frame %d has a different color type than frame 0", a.written)
+	}
+	if a.written == 0 {
+		a.size = size
+		a.ihdr = bytes.Clone(ihdr)
+		if _, err := io.WriteString(a.w, pngSignature); err != nil {
+			return err
+		}
+		if err := writeChunk(a.w, "IHDR", ihdr); err != nil {
+			return err
+		}
+		for _, t := range a.text {
+			if err := writeTextChunk(a.w, t); err != nil {
+				return err
+			}
+		}
+		var actl [8]byte
+		binary.BigEndian.PutUint32(actl[:4], uint32(a.frames)) // num_plays 0: loop forever
+		if err := writeChunk(a.w, "acTL", actl[:]); err != nil {
+			return err
+		}
+	}
+
+	// fcTL: sequence, size, offset, delay 1/fps, dispose none, blend source
+	var fctl [26]byte
+	binary.BigEndian.PutUint32(fctl[0:], a.seq)
+	binary.BigEndian.PutUint32(fctl[4:], uint32(size.X))
+	binary.BigEndian.PutUint32(fctl[8:], uint32(size.Y))
+	binary.BigEndian.PutUint16(fctl[20:], 1)
+	binary.BigEndian.PutUint16(fctl[22:], uint16(a.fps))
+	a.seq++
+	if err := writeChunk(a.w, "fcTL", fctl[:]); err != nil {
+		return err
+	}
+	// the first frame is the default image and keeps its IDAT chunks; the
+	// others carry the same data in sequence-numbered fdAT chunks
+	for _, d := range idats {
+		if a.written == 0 {
+			err = writeChunk(a.w, "IDAT", d)
+		} else {
+			err = writeChunk(a.w, "fdAT", binary.BigEndian.AppendUint32(nil, a.seq), d)
+			a.seq++
+		}
+		if err != nil {
+			return err
+		}
+	}
+	a.written++
+	return nil
+}
+
+// Close ends the file. It fails if fewer frames were written than
+// NewAPNGWriter was told to expect.
+func (a *APNGWriter) Close() error {
+	if a.written != a.frames {
+		return fmt.Errorf("apng: %d of %d frames written", a.written, a.frames)
+	}
+	return writeChunk(a.w, "IEND", nil)
+}
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// splitPNG returns the IHDR payload and the IDAT payloads of the PNG
+// stream b, as written by image/png.
+func splitPNG(b []byte) (ihdr []byte, idats [][]byte, err error) {
+	if !bytes.HasPrefix(b, []byte(pngSignature)) {
+		return nil, nil, errors.New("not a PNG stream")
+	}
+	b = b[len(pngSignature):]
+	for len(b) >= 12 {
+		n := int(binary.BigEndian.Uint32(b))
+		if len(b) < 12+n {
+			break
+		}
+		typ, data := string(b[4:8]), b[8:8+n]
+		switch typ {
+		case "IHDR":
+			ihdr = data
+		case "IDAT":
+			idats = append(idats, data)
+		}
+		b = b[12+n:]
+	}
+	if ihdr == nil || idats == nil {
+		return nil, nil, errors.New("PNG stream without IHDR or IDAT")
+	}
+	return ihdr, idats, nil
+}
+
+// writeChunk writes one PNG chunk whose data is the concatenation of parts.
+func writeChunk(w io.Writer, typ string, parts ...[]byte) error {
+	n := 0
+	for _, p := range parts {
+		n += len(p)
+	}
+	chunk := make([]byte, 8, 12+n)
+	binary.BigEndian.PutUint32(chunk, uint32(n))
+	copy(chunk[4:], typ)
+	for _, p := range parts {
+		chunk = append(chunk, p...)
+	}
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	_, err := w.Write(chunk)
+	return err
+}
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"image/color"
+	"math"
+	"math/cmplx"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+// An orbit has settled into a cycle of period p once p more steps bring
+// it back to within attractorTol of where it was, relative to its size
+// past 1. Cycles longer than maxAttractorPeriod aren't looked for.
+const (
+	attractorTolSq     = 1e-16 // attractorTol 1e-8
+	maxAttractorPeriod = 1024
+)
+
+// attractorUnsettled colors interior points whose orbit hadn't settled
+// into a cycle it could be told apart by: ones converging slowly near a
+// component's edge, or cycling with a longer period.
+var attractorUnsettled = color.RGBA{0x20, 0x20, 0x20, 0xff}
+
+// attractorOf dispatches finiteAttractor the way iterate dispatches the
+// escape-time kernels, so the built-in formulas step without going
+// through the interface.
+func attractorOf(f fractal.Fractal, c complex128, maxIter int, bailoutSq float64) (int, complex128) {
+	switch f := f.(type) {
+	case fractal.Mandelbrot:
+		return finiteAttractor(f, c, maxIter, bailoutSq)
+	case fractal.Julia:
+		return finiteAttractor(f, c, maxIter, bailoutSq)
+	case fractal.BurningShip:
+		return finiteAttractor(f, c, maxIter, bailoutSq)
+	case fractal.Multibrot:
+		return finiteAttractor(f, c, maxIter, bailoutSq)
+	case fractal.Quaternion:
+		return finiteAttractor(f, c, maxIter, bailoutSq)
+	case fractal.Lemniscate:
+		return finiteAttractor(f, c, maxIter, bailoutSq)
+	case fractal.Super:
+		return finiteAttractor(f, c, maxIter, bailoutSq)
+	default:
+		return finiteAttractor(f, c, maxIter, bailoutSq)
+	}
+}
+
+// finiteAttractor runs f from c for maxIter steps and, if the orbit is
+// still bounded, finds the attracting cycle it has settled into. It
+// returns the cycle's period and the point of it the orbit sits on after
+// maxIter steps, or period 0 if the orbit escaped or hadn't settled.
+func finiteAttractor[F fractal.Fractal](f F, c complex128, maxIter int, bailoutSq float64) (int, complex128) {
+	n, s := fractal.Iterate(f, c, maxIter, bailoutSq)
+	if n < maxIter {
+		return 0, 0
+	}
+	w := s.Z
+	tolSq := attractorTolSq * max(1, real(w)*real(w)+imag(w)*imag(w))
+	for p := 1; p <= maxAttractorPeriod; p++ {
+		f.Step(&s)
+		if f.Escaped(&s) {
+			return 0, 0
+		}
+		if d := s.Z - w; real(d)*real(d)+imag(d)*imag(d) < tolSq {
+			return p, w
+		}
+	}
+	return 0, 0
+}
+
+// finiteAttractorColor is the ColoringAttractor color of an interior
+// point c of f, with the period of the cycle its orbit settles into. A
+// polynomial's filled Julia set can hold an attracting cycle besides ∞;
+// each point of a p-cycle is an attracting fixed point of the p-th
+// iterate with its own basin, the petals around it. All orbits are
+// sampled after the same maxIter steps, so those in different petals sit
+// on different points of the cycle, and the color is taken from that
+// point: its angle gives the hue, turned a further golden angle per step
+// of period, and its distance from 0 the lightness. In the parameter
+// plane of a Mandelbrot or Multibrot set the cycle moves with c, so each
+// hyperbolic component shades smoothly and neighbouring components differ.
+// Points that didn't settle get attractorUnsettled and period 0.
+func finiteAttractorColor(f fractal.Fractal, c complex128, maxIter int, bailoutSq float64) (int, color.RGBA) {
+	p, w := attractorOf(f, c, maxIter, bailoutSq)
+	if p == 0 {
+		return 0, attractorUnsettled
+	}
+	hue := cmplx.Phase(w)*180/math.Pi + 137.5*float64(p-1)
+	mag := cmplx.Abs(w)
+	r, g, b := hslToRGB(hue, 70, 30+40*mag/(1+mag))
+	return p, color.RGBA{r, g, b, 0xff}
+}
@@ -0,0 +1,102 @@
+package render
+
+import (
+	"context"
+	"image/color"
+	"math/cmplx"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+func TestAttractorPetals(t *testing.T) {
+	bailoutSq := DefaultBailout * DefaultBailout
+	cubic := fractal.Multibrot{Power: 3}
+	// the main body of z³ + c, period 1, and the bulbs above and below it
+	// centered on ±i, where 0 → ±i → 0 is a 2-cycle
+	var colors []color.RGBA
+	for _, tc := range []struct {
+		c      complex128
+		period int
+	}{
+		{0.1 + 0.05i, 1},
+		{0.05 + 1.02i, 2},
+		{0.05 - 1.02i, 2},
+	} {
+		for _, maxIter := range []int{500, 501} {
+			p, col := finiteAttractorColor(cubic, tc.c, maxIter, bailoutSq)
+			if p != tc.period {
+				t.Errorf("c = %v after %d: period %d, want %d", tc.c, maxIter, p, tc.period)
+			}
+			if maxIter == 500 {
+				colors = append(colors, col)
+			}
+		}
+	}
+	if colors[0] == colors[1] || colors[1] == colors[2] || colors[0] == colors[2] {
+		t.Errorf("colors %v: want three distinct", colors)
+	}
+
+	// the filled Julia set of z² + c at the rabbit has three petals at
+	// its center, each holding one point of the attracting 3-cycle; an
+	// orbit from each sits on a different point after the same steps
+	rabbit := fractal.Julia{K: -0.12256116687665 + 0.74486176661974i}
+	var a [3]complex128
+	z := complex128(0)
+	for range 3000 {
+		z = z*z + rabbit.K
+	}
+	a[0], a[1], a[2] = z, z*z+rabbit.K, (z*z+rabbit.K)*(z*z+rabbit.K)+rabbit.K
+	var petals []color.RGBA
+	for _, start := range a {
+		// a little way into the petal, off the cycle point itself
+		p, col := finiteAttractorColor(rabbit, start+0.01, 999, bailoutSq)
+		if p != 3 {
+			t.Errorf("from %v: period %d, want 3", start, p)
+		}
+		petals = append(petals, col)
+	}
+	if petals[0] == petals[1] || petals[1] == petals[2] || petals[0] == petals[2] {
+		t.Errorf("petal colors %v: want three distinct", petals)
+	}
+
+	// escaping and unsettled orbits have no attractor
+	if p, col := finiteAttractorColor(cubic, 1+1i, 500, bailoutSq); p != 0 || col != attractorUnsettled {
+		t.Errorf("escaping c: period %d, %v", p, col)
+	}
+	// a parabolic point converges too slowly to settle
+	if p, _ := finiteAttractorColor(fractal.Mandelbrot{}, 0.25, 500, bailoutSq); p != 0 {
+		t.Errorf("c = 1/4 settled with period %d", p)
+	}
+	if p, w := attractorOf(fractal.Mandelbrot{}, -1, 500, bailoutSq); p != 2 || cmplx.Abs(w) > 1e-12 && cmplx.Abs(w+1) > 1e-12 {
+		t.Errorf("c = -1: period %d at %v, want 2 on 0 or -1", p, w)
+	}
+}
+
+func TestAttractorColoringRender(t *testing.T) {
+	// a render of the cubic Multibrot colors the body and both bulbs apart
+	o := smallOptions(t,
+		WithSize(64, 64), WithIterations(300),
+		WithViewport(coords.Bounds{Xmin: -1.5, Xmax: 1.5, Ymin: -1.5, Ymax: 1.5}),
+		WithFractal(fractal.Multibrot{Power: 3}), WithColoring(ColoringAttractor),
+	)
+	res, err := Render(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vp := o.Viewport()
+	at := func(c complex128) color.RGBA {
+		x, y := vp.ComplexToPoint(c)
+		return res.Image.RGBAAt(int(x), int(y))
+	}
+	body, top, bottom := at(0.1+0.05i), at(0.05+1.02i), at(0.05-1.02i)
+	if body == top || top == bottom || body == bottom {
+		t.Errorf("body %v, top bulb %v, bottom bulb %v: want three distinct", body, top, bottom)
+	}
+	for _, c := range []color.RGBA{body, top, bottom} {
+		if c == attractorUnsettled {
+			t.Errorf("%v: unsettled", c)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+//go:build avx2 && cgo && amd64
+
+package render
+
+/*
+#cgo CFLAGS: -O3
+
+#include <cpuid.h>
+#include <immintrin.h>
+#include <stdint.h>
+
+enum { mbEscaped, mbInterior, mbPeriodic };
+
+// mb_have_avx2 reports whether the CPU has AVX2 and the OS saves the
+// YMM registers across context switches.
+static int mb_have_avx2(void) {
+	unsigned a, b, c, d, lo, hi;
+	if (!__get_cpuid(1, &a, &b, &c, &d) || !(c & bit_OSXSAVE) || !(c & bit_AVX)) {
+		return 0;
+	}
+	__asm__("xgetbv" : "=a"(lo), "=d"(hi) : "c"(0));
+	if ((lo & 6) != 6) {
+		return 0;
+	}
+	if (!__get_cpuid_count(7, 0, &a, &b, &c, &d)) {
+		return 0;
+	}
+	return (b & bit_AVX2) != 0;
+}
+
+// mb_escape4 runs mandelbrotPeriodic for four points at once, one per
+// lane, with the same operations in the same order so the results match
+// bit for bit. A lane drops out of the mask once it escapes or cycles;
+// the loop ends when every lane has. Only this function targets AVX2,
+// so mb_have_avx2 runs on any CPU; and AVX2 doesn't bring FMA, so the
+// compiler can't fuse a multiply and add the way Go doesn't.
+__attribute__((target("avx2")))
+static void mb_escape4(const double *cr4, const double *ci4, int64_t maxIter, double bailoutSq, double tolSq,
+		int64_t *iter, double *zr4, double *zi4, uint8_t *how) {
+	__m256d cr = _mm256_loadu_pd(cr4), ci = _mm256_loadu_pd(ci4);
+	__m256d zr = _mm256_setzero_pd(), zi = _mm256_setzero_pd();
+	__m256d sr = _mm256_setzero_pd(), si = _mm256_setzero_pd();
+	__m256d bail = _mm256_set1_pd(bailoutSq), tol = _mm256_set1_pd(tolSq);
+	__m256d active = _mm256_castsi256_pd(_mm256_set1_epi64x(-1));
+	double r[4], i[4];
+	int64_t next = 8, n;
+	for (n = 0; n < maxIter; n++) {
+		__m256d nzr = _mm256_add_pd(_mm256_sub_pd(_mm256_mul_pd(zr, zr), _mm256_mul_pd(zi, zi)), cr);
+		__m256d nzi = _mm256_add_pd(_mm256_add_pd(_mm256_mul_pd(zr, zi), _mm256_mul_pd(zi, zr)), ci);
+		zr = nzr;
+		zi = nzi;
+		__m256d mag = _mm256_add_pd(_mm256_mul_pd(zr, zr), _mm256_mul_pd(zi, zi));
+		// not within the radius, so a NaN escapes too, as in safeEscape
+		int esc = _mm256_movemask_pd(_mm256_and_pd(active, _mm256_cmp_pd(mag, bail, _CMP_NLE_UQ)));
+		__m256d dr = _mm256_sub_pd(zr, sr), di = _mm256_sub_pd(zi, si);
+		__m256d d2 = _mm256_add_pd(_mm256_mul_pd(dr, dr), _mm256_mul_pd(di, di));
+		int per = _mm256_movemask_pd(_mm256_and_pd(active, _mm256_cmp_pd(d2, tol, _CMP_LT_OQ))) & ~esc;
+		if (esc | per) {
+			_mm256_storeu_pd(r, zr);
+			_mm256_storeu_pd(i, zi);
+			for (int k = 0; k < 4; k++) {
+				if (esc >> k & 1) {
+					iter[k] = n, zr4[k] = r[k], zi4[k] = i[k], how[k] = mbEscaped;
+				} else if (per >> k & 1) {
+					iter[k] = maxIter, zr4[k] = 0, zi4[k] = 0, how[k] = mbPeriodic;
+				}
+			}
+			int done = esc | per;
+			__m256i clear = _mm256_set_epi64x(done & 8 ? 0 : -1, done & 4 ? 0 : -1, done & 2 ? 0 : -1, done & 1 ? 0 : -1);
+			active = _mm256_and_pd(active, _mm256_castsi256_pd(clear));
+			if (!_mm256_movemask_pd(active)) {
+				return;
+			}
+		}
+		if (n == next) {
+			sr = zr;
+			si = zi;
+			next *= 2;
+		}
+	}
+	int left = _mm256_movemask_pd(active);
+	_mm256_storeu_pd(r, zr);
+	_mm256_storeu_pd(i, zi);
+	for (int k = 0; k < 4; k++) {
+		if (left >> k & 1) {
+			iter[k] = maxIter, zr4[k] = r[k], zi4[k] = i[k], how[k] = mbInterior;
+		}
+	}
+}
+
+// mb_escape runs mb_escape4 over n points, their real and imaginary
+// parts interleaved in c as Go lays out complex128, writing z the same
+// way. The last group is padded with 0, which cycles at once.
+static void mb_escape(const double *c, int64_t n, int64_t maxIter, double bailoutSq, double tolSq,
+		int64_t *iter, double *z, uint8_t *how) {
+	for (int64_t j = 0; j < n; j += 4) {
+		double cr[4] = {0}, ci[4] = {0}, zr[4], zi[4];
+		int64_t it[4];
+		uint8_t hw[4];
+		int64_t m = n - j < 4 ? n - j : 4;
+		for (int64_t k = 0; k < m; k++) {
+			cr[k] = c[2*(j+k)];
+			ci[k] = c[2*(j+k)+1];
+		}
+		mb_escape4(cr, ci, maxIter, bailoutSq, tolSq, it, zr, zi, hw);
+		for (int64_t k = 0; k < m; k++) {
+			iter[j+k] = it[k];
+			z[2*(j+k)] = zr[k];
+			z[2*(j+k)+1] = zi[k];
+			how[j+k] = hw[k];
+		}
+	}
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+// haveAVX2 reports whether computeRowAVX2 can run on this machine.
+var haveAVX2 = C.mb_have_avx2() != 0
+
+// computeRowAVX2 iterates row y of fr four pixels at a time with AVX2,
+// returning its orbits by x-fr.bounds().Min.X, or nil if the CPU lacks
+// AVX2 or opts isn't the plain Mandelbrot set those lanes compute. The
+// cardioid and bulb shortcuts are taken first as iterate takes them, and
+// the orbits come out identical to iterate's, escaped ones carried on
+// for smooth coloring the same way. Pixels that computeRow fills itself,
+// poles of the transform and interior blocks, are left zero.
+func computeRowAVX2(fr *frame, y int, opts *Options) []orbit {
+	f, ok := opts.Fractal.(fractal.Mandelbrot)
+	bailoutSq := opts.Bailout * opts.Bailout
+	if !haveAVX2 || !ok || f.Z0 != 0 || bailoutSq < 4 {
+		return nil
+	}
+	vp := opts.Viewport()
+	b := fr.bounds()
+	row := make([]orbit, b.Dx())
+	cs := make([]complex128, 0, b.Dx())
+	at := make([]int, 0, b.Dx())
+	for x := b.Min.X; x < b.Max.X; x++ {
+		c, finite := opts.Transform.Apply(vp.PixelToComplex(x, y))
+		switch i := x - b.Min.X; {
+		case !finite || fr.interior.contains(x, y):
+		case inCardioid(c):
+			row[i] = orbit{iter: opts.MaxIter, how: resultCardioid, farIter: opts.MaxIter}
+		case inBulb(c):
+			row[i] = orbit{iter: opts.MaxIter, how: resultBulb, farIter: opts.MaxIter}
+		default:
+			cs = append(cs, c)
+			at = append(at, i)
+		}
+	}
+	if len(cs) == 0 {
+		return row
+	}
+	iters := make([]int64, len(cs))
+	zs := make([]complex128, len(cs))
+	hows := make([]uint8, len(cs))
+	C.mb_escape((*C.double)(unsafe.Pointer(&cs[0])), C.int64_t(len(cs)), C.int64_t(opts.MaxIter), C.double(bailoutSq), C.double(periodTolSq),
+		(*C.int64_t)(unsafe.Pointer(&iters[0])), (*C.double)(unsafe.Pointer(&zs[0])), (*C.uint8_t)(unsafe.Pointer(&hows[0])))
+	for k, i := range at {
+		how := resultEscaped
+		switch hows[k] {
+		case C.mbInterior:
+			how = resultInterior
+		case C.mbPeriodic:
+			how = resultPeriodic
+		}
+		row[i] = mandelbrotOrbit(cs[k], int(iters[k]), zs[k], how)
+	}
+	return row
+}
@@ -0,0 +1,9 @@
+//go:build !(avx2 && cgo && amd64)
+
+package render
+
+// haveAVX2 is false without the avx2 build tag; see avx2.go.
+const haveAVX2 = false
+
+// computeRowAVX2 always returns nil here, leaving every row to iterate.
+func computeRowAVX2(fr *frame, y int, opts *Options) []orbit { return nil }
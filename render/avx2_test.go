@@ -0,0 +1,88 @@
+//go:build avx2 && cgo && amd64
+
+package render
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+// avx2Views are the windows the AVX2 rows are checked on: the whole set,
+// the boundary at depth, a view all outside the set and one mostly in it.
+var avx2Views = []coords.Bounds{
+	{Xmin: -2.5, Xmax: 1, Ymin: -1.2, Ymax: 1.2},
+	{Xmin: -0.7437, Xmax: -0.7435, Ymin: 0.1317, Ymax: 0.1319},
+	{Xmin: -2, Xmax: 1, Ymin: 1.13, Ymax: 1.4},
+	{Xmin: -0.6, Xmax: 0.2, Ymin: -0.4, Ymax: 0.4},
+}
+
+func TestComputeRowAVX2Orbits(t *testing.T) {
+	if !haveAVX2 {
+		t.Skip("no AVX2 on this CPU")
+	}
+	for _, b := range avx2Views {
+		opts := smallOptions(t, WithSize(300, 100), WithIterations(2000), WithViewport(b)).withDefaults()
+		fr := newFrame(&opts)
+		vp := opts.Viewport()
+		bailoutSq := opts.Bailout * opts.Bailout
+		for y := range 100 {
+			row := computeRowAVX2(fr, y, &opts)
+			if row == nil {
+				t.Fatal("no AVX2 row for the plain Mandelbrot set")
+			}
+			for x, got := range row {
+				if want := iterate(opts.Fractal, vp.PixelToComplex(x, y), opts.MaxIter, bailoutSq); got != want {
+					t.Fatalf("%v: pixel (%d, %d): AVX2 %+v, Go %+v", b, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestComputeRowAVX2Render(t *testing.T) {
+	if !haveAVX2 {
+		t.Skip("no AVX2 on this CPU")
+	}
+	for _, b := range avx2Views {
+		for _, extra := range [][]Option{
+			nil,
+			{WithSparse(true)},
+			{WithColoring(ColoringDebug)},
+			{WithBailout(100)},
+		} {
+			opts := smallOptions(t, append([]Option{WithSize(300, 100), WithIterations(1000), WithViewport(b)}, extra...)...)
+			avx, err := Render(context.Background(), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			haveAVX2 = false
+			plain, err := Render(context.Background(), opts)
+			haveAVX2 = true
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(avx.Image.Pix, plain.Image.Pix) || !slices.Equal(avx.Iters, plain.Iters) {
+				t.Errorf("%v, %d options: the AVX2 render differs from the Go one", b, len(extra))
+			}
+		}
+	}
+}
+
+func TestComputeRowAVX2Declines(t *testing.T) {
+	if !haveAVX2 {
+		t.Skip("no AVX2 on this CPU")
+	}
+	for name, o := range map[string]Option{
+		"julia":       WithFractalName("julia", complex(-0.8, 0.156)),
+		"multibrot":   WithFractalName("multibrot", 0),
+		"bailout 1.5": WithBailout(1.5),
+	} {
+		opts := smallOptions(t, o).withDefaults()
+		if row := computeRowAVX2(newFrame(&opts), 0, &opts); row != nil {
+			t.Errorf("%s: AVX2 row for a formula it doesn't compute", name)
+		}
+	}
+}
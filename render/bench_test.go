@@ -0,0 +1,194 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+// sinks keep the compiler from discarding benchmarked work
+var (
+	sinkIter int
+	sinkZ    complex128
+	sink     any
+)
+
+// benchOptions returns the options built from opts, with the buffers a
+// benchmark doesn't look at discarded.
+func benchOptions(b *testing.B, opts ...Option) Options {
+	b.Helper()
+	o, err := New(append([]Option{WithDiscardBuffers(true)}, opts...)...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return o
+}
+
+func BenchmarkMandelbrotIterationsInterior(b *testing.B) {
+	for range b.N {
+		sinkIter, sinkZ, _ = mandelbrotIterations(complex(-0.1, 0.1), DefaultMaxIter, DefaultBailout*DefaultBailout, false)
+	}
+}
+
+func BenchmarkMandelbrotIterationsExterior(b *testing.B) {
+	for range b.N {
+		sinkIter, sinkZ, _ = mandelbrotIterations(complex(-0.7436, 0.1318), DefaultMaxIter, DefaultBailout*DefaultBailout, false)
+	}
+}
+
+// BenchmarkMandelbrotIterationsDeriv iterates every pixel of a 1000×800
+// render of the default view with and without the derivative orbit, the
+// cost distance estimation adds to smooth coloring.
+func BenchmarkMandelbrotIterationsDeriv(b *testing.B) {
+	const width, height = 1000, 800
+	vp := coords.NewViewport(DefaultBounds.FitToImage(width, height), width, height)
+	cs := make([]complex128, 0, width*height)
+	for y := range height {
+		for x := range width {
+			cs = append(cs, vp.PixelToComplex(x, y))
+		}
+	}
+	for _, trackDeriv := range []bool{false, true} {
+		b.Run(fmt.Sprintf("trackDeriv=%v", trackDeriv), func(b *testing.B) {
+			for range b.N {
+				for _, c := range cs {
+					sinkIter, _, sinkZ = mandelbrotIterations(c, DefaultMaxIter, DefaultBailout*DefaultBailout, trackDeriv)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkComputeRow times computeRow on one 1920-pixel row, the middle
+// row of the default view and, as Exterior, a row along the real axis just
+// right of the cusp at 0.25, where every pixel escapes but only after
+// hundreds of iterations, about as many as its neighbours': a row outside
+// the set where the iteration, not the coloring, takes the time, as the
+// AVX2 kernel needs to pay off.
+func BenchmarkComputeRow(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"Default", nil},
+		{"Exterior", []Option{
+			WithViewport(coords.Bounds{Xmin: 0.250001, Xmax: 0.2501, Ymin: -1e-7, Ymax: 1e-7}),
+			WithIterations(20000),
+		}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			opts := benchOptions(b, append([]Option{WithSize(1920, 1)}, bc.opts...)...).withDefaults()
+			fr := newFrame(&opts)
+			var st Stats
+			b.SetBytes(1920 * 4)
+			b.ResetTimer()
+			for range b.N {
+				computeRow(fr, 0, &opts, &st)
+			}
+			sink = fr
+		})
+	}
+}
+
+func BenchmarkRenderFull1920x1080(b *testing.B) {
+	const width, height = 1920, 1080
+	opts := benchOptions(b, WithSize(width, height))
+	b.SetBytes(int64(width * height * 4))
+	b.ResetTimer()
+	for range b.N {
+		res, err := Render(context.Background(), opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		sink = res
+	}
+}
+
+// BenchmarkRowsPerChunk times a tall, narrow render on 8 workers claiming
+// chunk rows at a time. Small chunks pay more scheduling per row; large
+// ones leave workers idle when a slow chunk through the set finishes
+// last.
+func BenchmarkRowsPerChunk(b *testing.B) {
+	const width, height = 200, 4000
+	for _, chunk := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("chunk=%d", chunk), func(b *testing.B) {
+			opts := benchOptions(b,
+				WithSize(width, height),
+				WithViewport(DefaultBounds.FitToImage(width, height)),
+				WithIterations(256),
+				WithProcs(8),
+				WithRowsPerChunk(chunk),
+			)
+			b.SetBytes(width * height * 4)
+			b.ResetTimer()
+			for range b.N {
+				res, err := Render(context.Background(), opts)
+				if err != nil {
+					b.Fatal(err)
+				}
+				sink = res
+			}
+		})
+	}
+}
+
+// scalingProcs are the worker counts of BenchmarkScaling.
+var scalingProcs = []int{1, 2, 4, 8, 16}
+
+// minScalingEfficiency is the efficiency below which
+// BenchmarkScalingEfficiency warns.
+const minScalingEfficiency = 0.75
+
+// benchScaling times a 2000×1500 render of the default view on procs
+// workers and reports its throughput in megapixels a second.
+func benchScaling(b *testing.B, procs int) {
+	const width, height = 2000, 1500
+	opts := benchOptions(b,
+		WithSize(width, height),
+		WithViewport(DefaultBounds.FitToImage(width, height)),
+		WithProcs(procs),
+	)
+	b.ResetTimer()
+	for range b.N {
+		res, err := Render(context.Background(), opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		sink = res
+	}
+	b.ReportMetric(width*height*float64(b.N)/b.Elapsed().Seconds()/1e6, "Mpixel/s")
+}
+
+func BenchmarkScaling(b *testing.B) {
+	for _, n := range scalingProcs {
+		b.Run(fmt.Sprintf("procs=%d", n), func(b *testing.B) { benchScaling(b, n) })
+	}
+}
+
+// BenchmarkScalingEfficiency times one worker and then each of the larger
+// worker counts of BenchmarkScaling, reporting for those their parallel
+// efficiency: the throughput over that of one worker divided by the
+// worker count. It warns about any below minScalingEfficiency with no
+// more workers than CPUs, where a lock or shared cache line in the render
+// path is the likely cause.
+func BenchmarkScalingEfficiency(b *testing.B) {
+	nsPerOp := func(b *testing.B) float64 { return float64(b.Elapsed().Nanoseconds()) / float64(b.N) }
+	var base float64 // the last run of a sub-benchmark is the one reported
+	b.Run("procs=1", func(b *testing.B) {
+		benchScaling(b, 1)
+		base = nsPerOp(b)
+	})
+	for _, n := range scalingProcs[1:] {
+		b.Run(fmt.Sprintf("procs=%d", n), func(b *testing.B) {
+			benchScaling(b, n)
+			eff := base / nsPerOp(b) / float64(n)
+			b.ReportMetric(eff, "efficiency")
+			if cpus := runtime.NumCPU(); eff < minScalingEfficiency && n <= cpus {
+				b.Logf("warning: %d workers on %d CPUs are only %.0f%% efficient", n, cpus, 100*eff)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"runtime"
+	"testing"
+)
+
+// BenchmarkBloom times the bloom pass over a 4K render of the default
+// view, at a narrow and a wide radius, on every CPU.
+func BenchmarkBloom(b *testing.B) {
+	const width, height = 3840, 2160
+	opts := benchOptions(b,
+		WithSize(width, height),
+		WithViewport(DefaultBounds.FitToImage(width, height)),
+		WithIterations(64),
+	)
+	res, err := Render(context.Background(), opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+	img := image.NewRGBA(res.Image.Rect)
+	for _, radius := range []float64{12, 96} {
+		b.Run(fmt.Sprintf("radius=%v", radius), func(b *testing.B) {
+			bloom := Bloom{Strength: 0.4, Radius: radius, Threshold: 0.1}
+			b.SetBytes(width * height * 4)
+			for range b.N {
+				copy(img.Pix, res.Image.Pix)
+				bloom.Apply(img, runtime.NumCPU())
+			}
+			sink = img
+		})
+	}
+}
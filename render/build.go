@@ -0,0 +1,531 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/fractal"
+	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/shader"
+)
+
+// Defaults used by New and by the command-line flags.
+const (
+	DefaultWidth       = 1600
+	DefaultHeight      = 1200
+	DefaultMaxIter     = 1200
+	DefaultPalette     = "NebulaSpectre"
+	DefaultColoring    = ColoringSmooth
+	DefaultBands       = 16
+	DefaultBlendSmooth = 0.7
+	DefaultBailout     = 2.0
+	DefaultTrapScale   = 4.0
+
+	DefaultInteriorScale = 10.0
+)
+
+// DefaultBounds is the window showing the whole Mandelbrot set.
+var DefaultBounds = coords.Bounds{Xmin: -2.2, Xmax: 1.0, Ymin: -1.6, Ymax: 1.6}
+
+// An Option configures the Options built by New.
+type Option func(*Options) error
+
+// New builds Options from the defaults and opts, then validates the result.
+// All problems are reported together, joined with errors.Join.
+func New(opts ...Option) (Options, error) {
+	o := Options{
+		Width:       DefaultWidth,
+		Height:      DefaultHeight,
+		Bounds:      DefaultBounds,
+		MaxIter:     DefaultMaxIter,
+		Palette:     palette.Get(DefaultPalette),
+		Fractal:     fractal.Mandelbrot{},
+		Bailout:     DefaultBailout,
+		Coloring:    DefaultColoring,
+		Bands:       DefaultBands,
+		BlendSmooth: DefaultBlendSmooth,
+	}
+	var errs []error
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := o.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return Options{}, errors.Join(errs...)
+	}
+	return o, nil
+}
+
+// Validate checks o for values the renderer cannot use and for
+// combinations of settings that don't make sense together.
+func (o Options) Validate() error {
+	var errs []error
+	if o.Width <= 0 || o.Height <= 0 {
+		errs = append(errs, fmt.Errorf("%w: image size %dx%d: width and height must be positive", ErrInvalidOptions, o.Width, o.Height))
+	}
+	if o.MaxIter <= 0 {
+		errs = append(errs, fmt.Errorf("%w: iterations %d: must be positive", ErrInvalidOptions, o.MaxIter))
+	}
+	b := o.Bounds
+	if !(b.Xmin < b.Xmax) || !(b.Ymin < b.Ymax) || math.IsInf(b.Width(), 0) || math.IsInf(b.Height(), 0) {
+		errs = append(errs, fmt.Errorf("%w: %+v: min must be below max on both axes", ErrInvalidViewport, b))
+	} else if o.Width > 0 && o.Height > 0 && exceedsPrecision(b, o.Width, o.Height, o.doubleDouble()) {
+		errs = append(errs, fmt.Errorf("%w: %+v is too small to resolve %dx%d pixels", ErrPrecisionExceeded, b, o.Width, o.Height))
+	}
+	if o.RowsPerChunk < 0 {
+		errs = append(errs, fmt.Errorf("%w: rows per chunk %d: must not be negative", ErrInvalidOptions, o.RowsPerChunk))
+	}
+	if o.Throttle < 0 || o.Throttle > 100 {
+		errs = append(errs, fmt.Errorf("%w: throttle %d%%: must be from 1 to 100", ErrInvalidOptions, o.Throttle))
+	}
+	if math.IsNaN(o.Rotation) || math.IsInf(o.Rotation, 0) {
+		errs = append(errs, fmt.Errorf("%w: rotation %g: must be finite", ErrInvalidViewport, o.Rotation))
+	}
+	if o.Bailout != 0 && !(o.Bailout > 1) {
+		errs = append(errs, fmt.Errorf("%w: bailout %g: escape radius must be above 1", ErrInvalidOptions, o.Bailout))
+	}
+	if o.Palette == nil || len(o.Palette.Colors) == 0 {
+		errs = append(errs, fmt.Errorf("%w: palette has no colors", ErrInvalidOptions))
+	}
+	if o.Coloring != "" && !slices.Contains(Colorings, o.Coloring) {
+		errs = append(errs, fmt.Errorf("%w: coloring %q: not one of %s", ErrInvalidOptions, o.Coloring, joinColorings()))
+	}
+	if (o.Coloring == ColoringBands || o.Coloring == ColoringBlend) && o.Bands < 2 {
+		errs = append(errs, fmt.Errorf("%w: bands %d: %s coloring needs at least 2", ErrInvalidOptions, o.Bands, o.Coloring))
+	}
+	if o.BlendSmooth < 0 || o.BlendSmooth > 1 {
+		errs = append(errs, fmt.Errorf("%w: blend weight %g: must be within [0,1]", ErrInvalidOptions, o.BlendSmooth))
+	}
+	if o.ZmagSmooth < 0 || o.ZmagSmooth > 1 {
+		errs = append(errs, fmt.Errorf("%w: zmag weight %g: must be within [0,1]", ErrInvalidOptions, o.ZmagSmooth))
+	}
+	if math.IsNaN(o.PalettePhase) || math.IsInf(o.PalettePhase, 0) {
+		errs = append(errs, fmt.Errorf("%w: palette phase %g: must be finite", ErrInvalidOptions, o.PalettePhase))
+	}
+	if o.ZmagSmooth != 0 && o.Coloring != ColoringZmagCos {
+		errs = append(errs, fmt.Errorf("%w: zmag weight only applies to %s coloring, not %s", ErrInvalidOptions, ColoringZmagCos, o.Coloring))
+	}
+	if o.Coloring == ColoringFixedTrap && len(o.TrapPoints) == 0 {
+		errs = append(errs, fmt.Errorf("%w: %s coloring needs at least one trap point", ErrInvalidOptions, ColoringFixedTrap))
+	}
+	if len(o.TrapPoints) > 0 && o.Coloring != ColoringFixedTrap {
+		errs = append(errs, fmt.Errorf("%w: trap points only apply to %s coloring, not %s", ErrInvalidOptions, ColoringFixedTrap, o.Coloring))
+	}
+	for _, p := range o.TrapPoints {
+		if cmplx.IsNaN(p) || cmplx.IsInf(p) {
+			errs = append(errs, fmt.Errorf("%w: trap point %v: must be finite", ErrInvalidOptions, p))
+		}
+	}
+	if o.TrapScale < 0 || math.IsNaN(o.TrapScale) || math.IsInf(o.TrapScale, 0) {
+		errs = append(errs, fmt.Errorf("%w: trap scale %g: must be finite and positive", ErrInvalidOptions, o.TrapScale))
+	}
+	if o.InteriorScale < 0 || math.IsNaN(o.InteriorScale) || math.IsInf(o.InteriorScale, 0) {
+		errs = append(errs, fmt.Errorf("%w: interior scale %g: must be finite and positive", ErrInvalidOptions, o.InteriorScale))
+	}
+	if _, ok := o.Fractal.(fractal.Mandelbrot); (o.Coloring == ColoringInteriorDistance || o.Coloring == ColoringParabolic) && o.Fractal != nil && !ok {
+		errs = append(errs, fmt.Errorf("%w: %s coloring needs the mandelbrot fractal", ErrInvalidOptions, o.Coloring))
+	}
+	if m, ok := o.Fractal.(fractal.Mandelbrot); o.Coloring == ColoringParamDeriv && o.Fractal != nil && (!ok || m.Z0 != 0) {
+		errs = append(errs, fmt.Errorf("%w: %s coloring needs the mandelbrot fractal started from z0 = 0", ErrInvalidOptions, ColoringParamDeriv))
+	}
+	errs = append(errs, o.Transform.validate()...)
+	errs = append(errs, o.Adjust.validate()...)
+	errs = append(errs, o.Bloom.validate()...)
+	if o.OutputHSL && o.Bloom.Strength != 0 {
+		errs = append(errs, fmt.Errorf("%w: HSL output can't have bloom", ErrInvalidOptions))
+	}
+	if o.OutputHSL && o.Adjust != (Adjust{}) {
+		errs = append(errs, fmt.Errorf("%w: HSL output can't be color corrected", ErrInvalidOptions))
+	}
+	if o.OutputHSL && (o.Coloring == ColoringDebug || o.Coloring == ColoringAttractor) {
+		errs = append(errs, fmt.Errorf("%w: HSL output doesn't apply to %s coloring", ErrInvalidOptions, o.Coloring))
+	}
+	if o.AutoContrast && o.Coloring == ColoringDebug {
+		errs = append(errs, fmt.Errorf("%w: auto contrast doesn't apply to %s coloring", ErrInvalidOptions, ColoringDebug))
+	}
+	if o.Shader != nil {
+		switch {
+		case o.OutputHSL:
+			errs = append(errs, fmt.Errorf("%w: HSL output can't have a shader", ErrInvalidOptions))
+		case o.AutoContrast:
+			errs = append(errs, fmt.Errorf("%w: auto contrast can't have a shader", ErrInvalidOptions))
+		case o.Coloring == ColoringDebug || o.Coloring == ColoringAttractor:
+			errs = append(errs, fmt.Errorf("%w: a shader doesn't apply to %s coloring", ErrInvalidOptions, o.Coloring))
+		}
+	}
+	if o.Sparse {
+		switch {
+		case o.Coloring == ColoringDebug || o.Coloring == ColoringAttractor || o.Coloring == ColoringFixedTrap || o.Coloring == ColoringInteriorDistance:
+			errs = append(errs, fmt.Errorf("%w: sparse rendering can't fill the interior of %s coloring", ErrInvalidOptions, o.Coloring))
+		case o.Shader != nil:
+			errs = append(errs, fmt.Errorf("%w: sparse rendering can't fill the interior for a shader", ErrInvalidOptions))
+		case !o.Transform.IsZero():
+			errs = append(errs, fmt.Errorf("%w: sparse rendering can't be combined with a transform", ErrInvalidOptions))
+		}
+	}
+	if m, ok := o.Fractal.(fractal.Multibrot); ok && m.Power < 2 {
+		errs = append(errs, fmt.Errorf("%w: multibrot power %d: must be at least 2", ErrInvalidOptions, m.Power))
+	}
+	if q, ok := o.Fractal.(fractal.Quaternion); ok && (math.IsNaN(q.SliceZ) || math.IsInf(q.SliceZ, 0) || math.IsNaN(q.SliceW) || math.IsInf(q.SliceW, 0)) {
+		errs = append(errs, fmt.Errorf("%w: quaternion slice %g, %g: must be finite", ErrInvalidOptions, q.SliceZ, q.SliceW))
+	}
+	if p, ok := o.Fractal.(fractal.Super); ok && !(p.Power > 1 && !math.IsInf(p.Power, 0)) {
+		errs = append(errs, fmt.Errorf("%w: super power %g: must be a finite number above 1", ErrInvalidOptions, p.Power))
+	}
+	return errors.Join(errs...)
+}
+
+// WithSize sets the image size in pixels.
+func WithSize(width, height int) Option {
+	return func(o *Options) error {
+		o.Width, o.Height = width, height
+		return nil
+	}
+}
+
+// WithViewport sets the window on the complex plane.
+func WithViewport(b coords.Bounds) Option {
+	return func(o *Options) error {
+		o.Bounds = b
+		return nil
+	}
+}
+
+// WithRotation rotates the view deg degrees counter-clockwise about the
+// center of the viewport.
+func WithRotation(deg float64) Option {
+	return func(o *Options) error {
+		o.Rotation = deg
+		return nil
+	}
+}
+
+// WithFlipY turns the image upside down, with Ymin in the top row. Images
+// rendered before the top row became Ymax look like this.
+func WithFlipY(flip bool) Option {
+	return func(o *Options) error {
+		o.FlipY = flip
+		return nil
+	}
+}
+
+// WithHighPrecisionCoords selects the exactly rounded pixel mapping; see
+// Options.HighPrecisionCoords.
+func WithHighPrecisionCoords(use bool) Option {
+	return func(o *Options) error {
+		o.HighPrecisionCoords = use
+		return nil
+	}
+}
+
+// WithIterations sets the iteration limit.
+func WithIterations(n int) Option {
+	return func(o *Options) error {
+		o.MaxIter = n
+		return nil
+	}
+}
+
+// WithBailout sets the escape radius.
+func WithBailout(r float64) Option {
+	return func(o *Options) error {
+		o.Bailout = r
+		return nil
+	}
+}
+
+// WithPalette sets the color map.
+func WithPalette(cm *palette.ColorMap) Option {
+	return func(o *Options) error {
+		o.Palette = cm
+		return nil
+	}
+}
+
+// WithPaletteName looks up a built-in palette by keyword.
+func WithPaletteName(name string) Option {
+	return func(o *Options) error {
+		cm := palette.Get(name)
+		if cm == nil {
+			return unknownPalette(name)
+		}
+		o.Palette = cm
+		return nil
+	}
+}
+
+// WithFractal sets the iteration rule.
+func WithFractal(f fractal.Fractal) Option {
+	return func(o *Options) error {
+		o.Fractal = f
+		return nil
+	}
+}
+
+// WithFormula selects a formula written out as text; see fractal.Expr.
+func WithFormula(expr string) Option {
+	return func(o *Options) error {
+		f, err := fractal.ParseExpr(expr)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+		}
+		o.Fractal = f
+		return nil
+	}
+}
+
+// WithTransform sets the conformal map applied to the sample points.
+func WithTransform(m Mobius) Option {
+	return func(o *Options) error {
+		o.Transform = m
+		return nil
+	}
+}
+
+// WithFractalName selects a built-in formula by name; k is the Julia parameter.
+func WithFractalName(name string, k complex128) Option {
+	return func(o *Options) error {
+		f := fractal.ByName(name, k)
+		if f == nil {
+			return fmt.Errorf("%w: fractal %q: not one of %s", ErrInvalidOptions, name, strings.Join(fractal.Names, ", "))
+		}
+		o.Fractal = f
+		return nil
+	}
+}
+
+// WithColoring sets the coloring mode.
+func WithColoring(c Coloring) Option {
+	return func(o *Options) error {
+		o.Coloring = c
+		return nil
+	}
+}
+
+// WithBands sets the band count for band and blend coloring.
+func WithBands(n int) Option {
+	return func(o *Options) error {
+		o.Bands = n
+		return nil
+	}
+}
+
+// WithBlendSmooth sets the smooth weight for blend coloring.
+func WithBlendSmooth(w float64) Option {
+	return func(o *Options) error {
+		o.BlendSmooth = w
+		return nil
+	}
+}
+
+// WithZmagSmooth sets the smooth weight for zmag-cos coloring.
+func WithZmagSmooth(w float64) Option {
+	return func(o *Options) error {
+		o.ZmagSmooth = w
+		return nil
+	}
+}
+
+// WithTrapPoints sets the orbit traps for fixedtrap coloring.
+func WithTrapPoints(points []complex128) Option {
+	return func(o *Options) error {
+		o.TrapPoints = slices.Clone(points)
+		return nil
+	}
+}
+
+// WithTrapScale sets how quickly fixedtrap coloring runs through the
+// palette as the distance to the nearest trap grows.
+func WithTrapScale(scale float64) Option {
+	return func(o *Options) error {
+		o.TrapScale = scale
+		return nil
+	}
+}
+
+// WithInteriorScale sets how quickly interior-distance coloring fades
+// from the boundary inward.
+func WithInteriorScale(scale float64) Option {
+	return func(o *Options) error {
+		o.InteriorScale = scale
+		return nil
+	}
+}
+
+// WithPalettePhase shifts the colors of escaped pixels along the palette
+// by phase, a fraction of a forward-and-back sweep; see phaseT.
+func WithPalettePhase(phase float64) Option {
+	return func(o *Options) error {
+		o.PalettePhase = phase
+		return nil
+	}
+}
+
+// WithAdjust sets the color correction applied to every pixel.
+func WithAdjust(a Adjust) Option {
+	return func(o *Options) error {
+		o.Adjust = a
+		return nil
+	}
+}
+
+// WithAutoContrast sets whether the escaped pixels' palette positions
+// are stretched to use the whole palette.
+func WithAutoContrast(on bool) Option {
+	return func(o *Options) error {
+		o.AutoContrast = on
+		return nil
+	}
+}
+
+// WithShader sets the pixel shader that colors each pixel in place of
+// the palette.
+func WithShader(s shader.PixelShader) Option {
+	return func(o *Options) error {
+		o.Shader = s
+		return nil
+	}
+}
+
+// WithBloom sets the glow added around bright colors.
+func WithBloom(b Bloom) Option {
+	return func(o *Options) error {
+		o.Bloom = b
+		return nil
+	}
+}
+
+// WithProcs sets the worker count.
+func WithProcs(n int) Option {
+	return func(o *Options) error {
+		o.Procs = n
+		return nil
+	}
+}
+
+// WithRowsPerChunk sets how many rows a worker claims at a time.
+func WithRowsPerChunk(n int) Option {
+	return func(o *Options) error {
+		o.RowsPerChunk = n
+		return nil
+	}
+}
+
+// WithThrottle sets the percentage of the time the workers compute; see
+// Options.Throttle.
+func WithThrottle(pct int) Option {
+	return func(o *Options) error {
+		o.Throttle = pct
+		return nil
+	}
+}
+
+// WithOnPixel sets the per-pixel callback.
+func WithOnPixel(fn func(x, y int, result PixelResult)) Option {
+	return func(o *Options) error {
+		o.OnPixel = fn
+		return nil
+	}
+}
+
+// WithProgress sets the progress callback and its interval.
+func WithProgress(fn func(done, total int), interval time.Duration) Option {
+	return func(o *Options) error {
+		o.OnProgress, o.ProgressInterval = fn, interval
+		return nil
+	}
+}
+
+// WithRegions sets the region callback and whether delivery is in order.
+func WithRegions(fn func(rect image.Rectangle, pixels []color.RGBA), ordered bool) Option {
+	return func(o *Options) error {
+		o.OnRegion, o.OrderedRegions = fn, ordered
+		return nil
+	}
+}
+
+// WithMetrics sets the scheduler instrumentation.
+func WithMetrics(m Metrics) Option {
+	return func(o *Options) error {
+		o.Metrics = m
+		return nil
+	}
+}
+
+// WithOutputHSL selects HSL-encoded output; see Options.OutputHSL.
+func WithOutputHSL(use bool) Option {
+	return func(o *Options) error {
+		o.OutputHSL = use
+		return nil
+	}
+}
+
+// WithNRGBA selects non-premultiplied output in Result.NRGBA.
+func WithNRGBA(use bool) Option {
+	return func(o *Options) error {
+		o.UseNRGBA = use
+		return nil
+	}
+}
+
+// WithDiscardBuffers drops the iteration buffer and interior mask from the Result.
+func WithDiscardBuffers(discard bool) Option {
+	return func(o *Options) error {
+		o.DiscardBuffers = discard
+		return nil
+	}
+}
+
+// WithSparse sets whether blocks found to be interior by sampling their
+// corners are filled without iterating; see Options.Sparse.
+func WithSparse(sparse bool) Option {
+	return func(o *Options) error {
+		o.Sparse = sparse
+		return nil
+	}
+}
+
+// WithTimings sets whether each work unit is timed into Result.Timings.
+func WithTimings(record bool) Option {
+	return func(o *Options) error {
+		o.RecordTimings = record
+		return nil
+	}
+}
+
+// joinColorings returns the coloring modes as a comma-separated list.
+func joinColorings() string {
+	names := make([]string, len(Colorings))
+	for i, c := range Colorings {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ", ")
+}
+
+// exceedsPrecision reports whether adjacent pixels of a width×height image
+// of b would map to the same float64 coordinate, or with dd the same
+// double-double one, whose last bit is 2^-53 of a float64's.
+func exceedsPrecision(b coords.Bounds, width, height int, dd bool) bool {
+	ulp := func(v float64) float64 {
+		u := math.Nextafter(math.Abs(v), math.Inf(1)) - math.Abs(v)
+		if dd {
+			u *= 0x1p-53
+		}
+		return u
+	}
+	dx := b.Width() / float64(width)
+	dy := b.Height() / float64(height)
+	return dx <= 2*max(ulp(b.Xmin), ulp(b.Xmax)) || dy <= 2*max(ulp(b.Ymin), ulp(b.Ymax))
+}
@@ -0,0 +1,225 @@
+package render
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/whalelogic/mandlebrot/cmath"
+	"github.com/whalelogic/mandlebrot/palette"
+)
+
+// Coloring selects how an escape count is mapped to a palette position.
+type Coloring string
+
+const (
+	ColoringSmooth    Coloring = "smooth"    // continuous escape time
+	ColoringDiscrete  Coloring = "discrete"  // integer escape time
+	ColoringBands     Coloring = "bands"     // escape time modulo Bands, one palette sweep per band cycle
+	ColoringBlend     Coloring = "blend"     // BlendSmooth-weighted mix of smooth and bands
+	ColoringZmagCos   Coloring = "zmag-cos"  // cosine of the final |z|, optionally mixed with smooth via ZmagSmooth
+	ColoringDebug     Coloring = "debug"     // which branch of the iteration decided each pixel; see debugColor
+	ColoringPotential Coloring = "potential" // exterior potential, constant along equipotential lines; see potentialT
+	ColoringFixedTrap Coloring = "fixedtrap" // closest approach of the orbit to TrapPoints; see minOrbitDistance
+	ColoringAttractor Coloring = "attractor" // smooth outside; inside, the cycle the orbit settles on; see finiteAttractorColor
+
+	ColoringInteriorDistance Coloring = "interior-distance" // smooth outside; inside, a glow along the boundary; see interiorDistanceEstimate
+	ColoringParabolic        Coloring = "parabolic"         // escape time weighted by the distance from the repelling fixed point; see parabolicImplosionT
+	ColoringParamDeriv       Coloring = "param-deriv"       // argument of dz/dc at escape; see parameterDerivative
+)
+
+// Colorings lists the supported coloring modes by flag name.
+var Colorings = []Coloring{ColoringSmooth, ColoringDiscrete, ColoringBands, ColoringBlend, ColoringZmagCos, ColoringDebug, ColoringPotential, ColoringFixedTrap, ColoringAttractor, ColoringInteriorDistance, ColoringParabolic, ColoringParamDeriv}
+
+// smoothing holds what the continuous escape count needs to know about
+// the formula and the escape radius.
+type smoothing struct {
+	logDegree  float64 // log of the formula's polynomial degree
+	logBailout float64 // log of the escape radius
+}
+
+func newSmoothing(degree, bailout float64) smoothing {
+	return smoothing{logDegree: math.Log(degree), logBailout: math.Log(bailout)}
+}
+
+// escapeT maps an orbit to a palette position in [0,1].
+func escapeT(o orbit, opts *Options, sm smoothing) float64 {
+	switch opts.Coloring {
+	case ColoringDiscrete:
+		return discreteT(o.iter, opts.MaxIter)
+	case ColoringBands:
+		return bandT(o.iter, opts.MaxIter, opts.Bands)
+	case ColoringBlend:
+		return blendT(o, opts.MaxIter, opts.Bands, opts.BlendSmooth, sm)
+	case ColoringZmagCos:
+		if o.iter >= opts.MaxIter {
+			return 0.0
+		}
+		w := clamp01(opts.ZmagSmooth)
+		return w*smoothT(o, opts.MaxIter, sm) + (1-w)*zmagCosT(o.z)
+	case ColoringPotential:
+		return potentialT(o, opts.MaxIter, sm)
+	default:
+		return smoothT(o, opts.MaxIter, sm)
+	}
+}
+
+// phaseT shifts palette position t by phase for Options.PalettePhase.
+// Like CycleT it runs the palette forward and back, so the colors change
+// smoothly with phase: 0 leaves t alone, 0.5 reverses the palette and
+// whole numbers give the original colors again.
+func phaseT(t, phase float64) float64 {
+	u := t/2 + phase
+	f := u - math.Floor(u)
+	return 1 - math.Abs(2*f-1)
+}
+
+// Debug coloring colors for the interior shortcuts.
+var (
+	debugCardioid = color.RGBA{0x00, 0xff, 0x00, 0xff}
+	debugBulb     = color.RGBA{0x00, 0x00, 0xff, 0xff}
+	debugPeriodic = color.RGBA{0xff, 0xff, 0x00, 0xff}
+	debugInterior = color.RGBA{0x00, 0x00, 0x00, 0xff}
+)
+
+// debugColor is the ColoringDebug color of a point: green for the main
+// cardioid shortcut, blue for the period-2 bulb, yellow for an orbit
+// caught cycling and black for one that ran to the limit. Escaped points
+// get the smooth palette color, tinted red where the smooth estimate fell
+// back to the integer count (see smoothIter).
+func debugColor(o orbit, t float64, cm *palette.ColorMap, sm smoothing) color.RGBA {
+	switch o.how {
+	case resultCardioid:
+		return debugCardioid
+	case resultBulb:
+		return debugBulb
+	case resultPeriodic:
+		return debugPeriodic
+	case resultInterior:
+		return debugInterior
+	}
+	c := cm.Interpolate(t)
+	if _, ok := smoothIterOK(o, sm); !ok {
+		c.R = uint8((uint16(c.R) + uint16(c.A)) / 2)
+		c.G /= 2
+		c.B /= 2
+	}
+	return c
+}
+
+// smoothIter is the continuous (smooth) iteration count
+// nu = n + 1 - log(log|z_n|/log R)/log(d) for an orbit that escaped radius
+// R, z_n being its iterate number n. Each further step multiplies log|z|
+// by about d and so adds one to n and takes one off the log term, which
+// makes any iterate past R give the same nu; the estimate is measured on
+// o.far, far enough out for that to hold closely, so neighbouring points
+// that escape on different steps blend without a seam.
+func smoothIter(o orbit, sm smoothing) float64 {
+	nu, _ := smoothIterOK(o, sm)
+	return nu
+}
+
+// smoothIterOK is smoothIter, also reporting false when the estimate was
+// unusable and the integer count was returned instead.
+func smoothIterOK(o orbit, sm smoothing) (float64, bool) {
+	nu := float64(o.farIter) + 1 - math.Log(math.Log(cmath.Abs(o.far))/sm.logBailout)/sm.logDegree
+	// an orbit that overflowed to an infinite |z|, or a formula whose
+	// escape test let it out with |z| <= 1, has no usable estimate; a
+	// negative nu is fine, it just lies outside the palette range
+	if math.IsNaN(nu) || math.IsInf(nu, 0) {
+		return float64(o.iter), false
+	}
+	return nu, true
+}
+
+// smoothT is smoothIter normalized to [0,1] by maxIter.
+func smoothT(o orbit, maxIter int, sm smoothing) float64 {
+	if o.iter >= maxIter {
+		// inside set -> black (or the palette start)
+		return 0.0
+	}
+	nu := smoothIter(o, sm)
+	return math.Pow(clamp01(nu/float64(maxIter)), 0.8)
+}
+
+// externalPotential is the potential of the set's exterior at a point,
+// estimated from its orbit as log|z_n|/d^n with z_n = o.far the n-th
+// iterate of a degree-d formula. It is the log of the Böttcher
+// coordinate's magnitude, the map taking the exterior of the set
+// conformally onto the exterior of the unit disk, for a Julia set; for
+// the Mandelbrot set, whose orbits start at 0 rather than at c, it is
+// that log divided by d, about log|c|/d far out. Either way it is 0 on
+// the boundary and grows outward, with level curves that are the
+// equipotential lines of the set's electric field. The estimate gets
+// more accurate the larger |z_n| is, which is why it reads o.far.
+func externalPotential(o orbit, sm smoothing) float64 {
+	mag := cmath.Abs(o.far)
+	if !(mag > 1) {
+		return 0
+	}
+	// farIter is 0-based, so far is iterate farIter+1; working with logs
+	// keeps d^n from overflowing on deep orbits
+	return math.Exp(math.Log(math.Log(mag)) - float64(o.farIter+1)*sm.logDegree)
+}
+
+// potentialT maps externalPotential to [0,1] as 1-exp(-10·potential): the
+// boundary and interior get the palette start and far-away points its
+// end. Each color traces one equipotential line, so the image shows the
+// shape of the field around the set rather than the escape speed.
+func potentialT(o orbit, maxIter int, sm smoothing) float64 {
+	if o.iter >= maxIter {
+		return 0.0
+	}
+	return clamp01(-math.Expm1(-10 * externalPotential(o, sm)))
+}
+
+// discreteT is the integer iteration count normalized to [0,1].
+func discreteT(iter, maxIter int) float64 {
+	if iter >= maxIter {
+		return 0.0
+	}
+	return math.Pow(float64(iter)/float64(maxIter), 0.8)
+}
+
+// bandT cycles through the palette every bandCount iterations, giving
+// hard-edged bands of constant color.
+func bandT(iter, maxIter, bandCount int) float64 {
+	if iter >= maxIter || bandCount <= 1 {
+		return 0.0
+	}
+	return float64(iter%bandCount) / float64(bandCount-1)
+}
+
+// blendT linearly mixes smoothT and bandT:
+// t = blendWeight*smooth + (1-blendWeight)*band.
+// A weight of 1 is pure smooth coloring, 0 is pure bands.
+func blendT(o orbit, maxIter int, bandCount int, blendWeight float64, sm smoothing) float64 {
+	if o.iter >= maxIter {
+		return 0.0
+	}
+	w := clamp01(blendWeight)
+	if w == 1 {
+		return smoothT(o, maxIter, sm)
+	}
+	if w == 0 {
+		return bandT(o.iter, maxIter, bandCount)
+	}
+	return clamp01(w*smoothT(o, maxIter, sm) + (1-w)*bandT(o.iter, maxIter, bandCount))
+}
+
+// zmagCosT colors by the magnitude of the escaped z alone:
+// t = 0.5 + 0.5*cos(sqrt(pi*|z|)). It ignores the iteration count, so the
+// bands it produces follow the orbit's landing point rather than its speed.
+func zmagCosT(z complex128) float64 {
+	return 0.5 + 0.5*math.Cos(math.Sqrt(math.Pi*cmath.Abs(z)))
+}
+
+// clamp01 limits v to [0,1], mapping NaN to 0.
+func clamp01(v float64) float64 {
+	if v < 0 || math.IsNaN(v) {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,248 @@
+package render
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+// coloringPoints are sample points for the coloring functions: interior,
+// slow and fast escapes, and one on the antenna.
+var coloringPoints = []complex128{
+	0, -1, -2, 0.26, 0.3 + 0.5i, -0.75 + 0.1i, -0.1 + 0.9i, 1, 2 + 2i, -1.5 + 0.01i,
+}
+
+func TestBlendTEndpoints(t *testing.T) {
+	const maxIter = 500
+	sm := newSmoothing(2, DefaultBailout)
+	for _, c := range coloringPoints {
+		o := iterate(fractal.Mandelbrot{}, c, maxIter, DefaultBailout*DefaultBailout)
+		for _, bands := range []int{0, 1, 2, DefaultBands, 100} {
+			if got, want := blendT(o, maxIter, bands, 1, sm), smoothT(o, maxIter, sm); got != want {
+				t.Errorf("c = %v, %d bands, weight 1: %v, smoothT %v", c, bands, got, want)
+			}
+			if got, want := blendT(o, maxIter, bands, 0, sm), bandT(o.iter, maxIter, bands); got != want {
+				t.Errorf("c = %v, %d bands, weight 0: %v, bandT %v", c, bands, got, want)
+			}
+			for _, w := range []float64{-1, 0.01, 0.3, DefaultBlendSmooth, 0.99, 2} {
+				if v := blendT(o, maxIter, bands, w, sm); !(v >= 0 && v <= 1) {
+					t.Errorf("c = %v, %d bands, weight %v: %v outside [0,1]", c, bands, w, v)
+				}
+			}
+		}
+	}
+}
+
+func TestZmagCosT(t *testing.T) {
+	if got, want := zmagCosT(complex(2, 0)), 0.5+0.5*math.Cos(math.Sqrt(math.Pi*2)); got != want {
+		t.Errorf("zmagCosT(2) = %v, want %v", got, want)
+	}
+	// magnitudes from 2 to 1e6 on a log scale, at a few angles
+	for i := range 1001 {
+		mag := 2 * math.Pow(5e5, float64(i)/1000)
+		for _, theta := range []float64{0, 1, math.Pi / 2, -2.5} {
+			z := cmplx.Rect(mag, theta)
+			if v := zmagCosT(z); !(v >= 0 && v <= 1) {
+				t.Fatalf("zmagCosT(%v) = %v outside [0,1]", z, v)
+			}
+		}
+	}
+}
+
+func TestSmoothTBailout(t *testing.T) {
+	const maxIter = 1000
+	// the log of the radius in nu's formula shifts every exterior point's
+	// count by the same log2(log 1e4 / log 2) when the radius changes
+	shift := math.Log2(math.Log(1e4) / math.Log(2))
+	for _, c := range []complex128{0.26, 1, -0.75 + 0.1i, -0.1 + 0.9i, 0.5 - 0.5i} {
+		small := iterate(fractal.Mandelbrot{}, c, maxIter, 2*2)
+		large := iterate(fractal.Mandelbrot{}, c, maxIter, 1e4*1e4)
+		smSmall, smLarge := newSmoothing(2, 2), newSmoothing(2, 1e4)
+		ts, tl := smoothT(small, maxIter, smSmall), smoothT(large, maxIter, smLarge)
+		if ts == tl {
+			t.Errorf("c = %v: t = %v for both radii", c, ts)
+		}
+		for _, v := range []float64{ts, tl} {
+			if !(v >= 0 && v <= 1) {
+				t.Errorf("c = %v: t = %v outside [0,1]", c, v)
+			}
+		}
+		if d := smoothIter(large, smLarge) - smoothIter(small, smSmall); math.Abs(d-shift) > 0.01 {
+			t.Errorf("c = %v: counts differ by %v, want %v", c, d, shift)
+		}
+	}
+}
+
+func TestDebugColoring(t *testing.T) {
+	// the default window, widened on the right to take in 1.5
+	b := DefaultBounds
+	b.Xmax = 1.8
+	opts := func(c Coloring) Options {
+		o, err := New(WithSize(500, 300), WithViewport(b), WithIterations(500), WithColoring(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return o
+	}
+	debug, err := Render(context.Background(), opts(ColoringDebug))
+	if err != nil {
+		t.Fatal(err)
+	}
+	smooth, err := Render(context.Background(), opts(ColoringSmooth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vp := opts(ColoringDebug).Viewport()
+	at := func(img *image.RGBA, c complex128) color.RGBA {
+		x, y, ok := vp.ComplexToPixel(c)
+		if !ok {
+			t.Fatalf("%v is outside the window", c)
+		}
+		return img.RGBAAt(x, y)
+	}
+	for _, tc := range []struct {
+		c    complex128
+		want color.RGBA
+	}{
+		{0, debugCardioid},
+		{-0.2 + 0.3i, debugCardioid},
+		{0.1 - 0.4i, debugCardioid},
+		{-1, debugBulb},
+		{-1.1 + 0.1i, debugBulb},
+	} {
+		if got := at(debug.Image, tc.c); got != tc.want {
+			t.Errorf("%v: %v, want %v", tc.c, got, tc.want)
+		}
+	}
+	if debugCardioid != (color.RGBA{0, 255, 0, 255}) {
+		t.Errorf("cardioid color %v, want pure green", debugCardioid)
+	}
+	// the shortcuts agree with iterating
+	for y := range 300 {
+		for x := range 500 {
+			if c := debug.Image.RGBAAt(x, y); c == debugCardioid || c == debugBulb {
+				if n, _ := fractal.Iterate(fractal.Mandelbrot{}, vp.PixelToComplex(x, y), 500, fractal.DefaultBailoutSq); n < 500 {
+					t.Fatalf("(%d, %d) is %v, but escapes at %d", x, y, c, n)
+				}
+			}
+		}
+	}
+	// outside, the palette's smooth color
+	if got, want := at(debug.Image, 1.5), at(smooth.Image, 1.5); got != want {
+		t.Errorf("1.5: %v, want the smooth color %v", got, want)
+	}
+}
+
+// potentialRef is the exterior potential of c computed directly, from an
+// iterate of 0 past 1e100.
+func potentialRef(c complex128) float64 {
+	z := c
+	n := 1
+	for cmplx.Abs(z) < 1e100 {
+		z = z*z + c
+		n++
+	}
+	return math.Log(cmplx.Abs(z)) / math.Pow(2, float64(n))
+}
+
+func TestExternalPotential(t *testing.T) {
+	const maxIter = 1000
+	sm := newSmoothing(2, DefaultBailout)
+	at := func(c complex128) float64 {
+		return externalPotential(iterate(fractal.Mandelbrot{}, c, maxIter, DefaultBailout*DefaultBailout), sm)
+	}
+	// the tip at -2 stays on the radius, on the boundary
+	if v := at(-2); v > 1e-6 {
+		t.Errorf("externalPotential(-2) = %v, want about 0", v)
+	}
+	// approaching the tip from outside, the potential falls toward 0
+	last := math.Inf(1)
+	for _, d := range []float64{1, 1e-1, 1e-2, 1e-4, 1e-6} {
+		c := complex(-2-d, 0)
+		v := at(c)
+		if !(v > 0 && v < last) {
+			t.Errorf("%v: potential %v, want positive and under %v", c, v, last)
+		}
+		last = v
+		if ref := potentialRef(c); math.Abs(v-ref) > 0.02*ref {
+			t.Errorf("%v: potential %v, reference %v", c, v, ref)
+		}
+	}
+	// farther out is larger; far out it is about log|c|/2, the orbit
+	// starting from 0 rather than c
+	for _, pair := range [][2]complex128{{0.3, 1}, {1, 2 + 2i}, {-0.1 + 1.2i, 3i}} {
+		if a, b := at(pair[0]), at(pair[1]); !(a < b) {
+			t.Errorf("potential %v at %v, not under %v at %v", a, pair[0], b, pair[1])
+		}
+	}
+	if v := at(1e6); math.Abs(v-math.Log(1e6)/2) > 1e-6 {
+		t.Errorf("externalPotential(1e6) = %v, want about %v", v, math.Log(1e6)/2)
+	}
+	// interior points get the palette start
+	for _, c := range []complex128{0, -1, -0.12 + 0.75i} {
+		o := iterate(fractal.Mandelbrot{}, c, maxIter, DefaultBailout*DefaultBailout)
+		if v := potentialT(o, maxIter, sm); v != 0 {
+			t.Errorf("potentialT(%v) = %v, want 0", c, v)
+		}
+	}
+}
+
+func TestSmoothNoSeams(t *testing.T) {
+	const (
+		maxIter = 1000
+		n       = 20000
+	)
+	julia := fractal.ByName("julia", complex(-0.8, 0.156))
+	for _, tc := range []struct {
+		name    string
+		f       fractal.Fractal
+		y       float64
+		bailout float64
+	}{
+		{"mandelbrot", fractal.Mandelbrot{}, 1.2, DefaultBailout},
+		{"mandelbrot, bailout 1.5", fractal.Mandelbrot{}, 1.2, 1.5},
+		{"julia", julia, 1.0, DefaultBailout},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// a line across the gradient outside the set, where every
+			// point escapes and nu changes gently; with the estimate
+			// measured at the escape radius it jumped by up to 0.6 where
+			// neighbours escape on different steps
+			sm := newSmoothing(fractal.Degree(tc.f), tc.bailout)
+			var last, lastT float64
+			for i := range n {
+				c := complex(-2+3*float64(i)/n, tc.y)
+				o := iterate(tc.f, c, maxIter, tc.bailout*tc.bailout)
+				if o.iter >= maxIter {
+					t.Fatalf("%v is interior", c)
+				}
+				nu, ok := smoothIterOK(o, sm)
+				if !ok {
+					t.Fatalf("%v: no smooth estimate", c)
+				}
+				v := smoothT(o, maxIter, sm)
+				if i > 0 && (math.Abs(nu-last) > 5e-3 || math.Abs(v-lastT) > 1e-3) {
+					t.Fatalf("at %v: nu jumps from %v to %v, t from %v to %v", c, last, nu, lastT, v)
+				}
+				last, lastT = nu, v
+			}
+		})
+	}
+}
+
+func TestSmoothIterNonFinite(t *testing.T) {
+	sm := newSmoothing(2, DefaultBailout)
+	// an orbit that overflowed, and one let out barely past |z| = 1,
+	// fall back to the integer count rather than to 0
+	for _, far := range []complex128{cmplx.Inf(), complex(math.NaN(), 0), 1} {
+		o := orbit{iter: 7, z: far, how: resultEscaped, far: far, farIter: 7}
+		if nu, ok := smoothIterOK(o, sm); ok || nu != 7 {
+			t.Errorf("far = %v: nu = %v, ok %v; want the count 7", far, nu, ok)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"slices"
+	"testing"
+)
+
+// pngChunk is a chunk of a PNG stream, without its length and CRC.
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+// pngChunks splits the PNG stream b into its chunks.
+func pngChunks(t *testing.T, b []byte) []pngChunk {
+	t.Helper()
+	if len(b) < 8 || string(b[1:4]) != "PNG" {
+		t.Fatal("not a PNG")
+	}
+	var chunks []pngChunk
+	for b = b[8:]; len(b) >= 12; {
+		n := int(binary.BigEndian.Uint32(b))
+		chunks = append(chunks, pngChunk{string(b[4:8]), b[8 : 8+n]})
+		b = b[12+n:]
+	}
+	return chunks
+}
+
+// chunkTypes returns the chunk types in order, each run of IDAT as one.
+func chunkTypes(chunks []pngChunk) []string {
+	var types []string
+	for _, c := range chunks {
+		if c.typ != "IDAT" || types[len(types)-1] != "IDAT" {
+			types = append(types, c.typ)
+		}
+	}
+	return types
+}
+
+func TestSRGBChunks(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	var buf bytes.Buffer
+	if err := EncodeWithText(&buf, img, "png", []TextChunk{{"Title", "test"}}); err != nil {
+		t.Fatal(err)
+	}
+	chunks := pngChunks(t, buf.Bytes())
+	if got, want := chunkTypes(chunks), []string{"IHDR", "sRGB", "gAMA", "cHRM", "tEXt", "IDAT", "IEND"}; !slices.Equal(got, want) {
+		t.Fatalf("chunks %v, want %v", got, want)
+	}
+	// perceptual intent, gamma 1/2.2 and the sRGB primaries, in 100000ths
+	if !bytes.Equal(chunks[1].data, []byte{0}) {
+		t.Errorf("sRGB %v", chunks[1].data)
+	}
+	if g := binary.BigEndian.Uint32(chunks[2].data); g != 45455 {
+		t.Errorf("gAMA %d", g)
+	}
+	var chrm []uint32
+	for i := 0; i < len(chunks[3].data); i += 4 {
+		chrm = append(chrm, binary.BigEndian.Uint32(chunks[3].data[i:]))
+	}
+	if want := []uint32{31270, 32900, 64000, 33000, 30000, 60000, 15000, 6000}; !slices.Equal(chrm, want) {
+		t.Errorf("cHRM %v, want %v", chrm, want)
+	}
+}
+
+func TestDisplayP3Chunks(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	var buf bytes.Buffer
+	if err := EncodeWithProfile(&buf, img, "png", nil, ProfileP3); err != nil {
+		t.Fatal(err)
+	}
+	chunks := pngChunks(t, buf.Bytes())
+	if got, want := chunkTypes(chunks), []string{"IHDR", "iCCP", "IDAT", "IEND"}; !slices.Equal(got, want) {
+		t.Fatalf("chunks %v, want %v", got, want)
+	}
+	name, rest, _ := bytes.Cut(chunks[1].data, []byte{0})
+	if string(name) != "Display P3" || rest[0] != 0 {
+		t.Fatalf("iCCP named %q, compression %d", name, rest[0])
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(rest[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	icc, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int(binary.BigEndian.Uint32(icc)) != len(icc) || string(icc[12:16]) != "mntr" ||
+		string(icc[16:20]) != "RGB " || string(icc[20:24]) != "XYZ " || string(icc[36:40]) != "acsp" {
+		t.Fatalf("not an RGB display profile: header % x", icc[:40])
+	}
+	tags := make(map[string][]byte)
+	for i := range int(binary.BigEndian.Uint32(icc[128:])) {
+		e := icc[132+12*i:]
+		off, n := binary.BigEndian.Uint32(e[4:]), binary.BigEndian.Uint32(e[8:])
+		tags[string(e[:4])] = icc[off : off+n]
+	}
+	xyz := func(sig string) [3]float64 {
+		b := tags[sig]
+		if string(b[:4]) != "XYZ " {
+			t.Fatalf("%s is not an XYZ tag", sig)
+		}
+		var v [3]float64
+		for i := range v {
+			v[i] = float64(int32(binary.BigEndian.Uint32(b[8+4*i:]))) / 65536
+		}
+		return v
+	}
+	// the D50-adapted colorants of Apple's Display P3 profile
+	for sig, want := range map[string][3]float64{
+		"rXYZ": {0.5151, 0.2412, -0.0011},
+		"gXYZ": {0.2920, 0.6922, 0.0419},
+		"bXYZ": {0.1571, 0.0666, 0.7841},
+		"wtpt": {0.9642, 1.0, 0.8249},
+	} {
+		got := xyz(sig)
+		for i := range got {
+			if math.Abs(got[i]-want[i]) > 2e-4 {
+				t.Errorf("%s = %v, want %v", sig, got, want)
+				break
+			}
+		}
+	}
+	for _, sig := range []string{"desc", "cprt", "chad", "rTRC", "gTRC", "bTRC"} {
+		if tags[sig] == nil {
+			t.Errorf("no %s tag", sig)
+		}
+	}
+}
+
+func TestToDisplayP3(t *testing.T) {
+	for _, tc := range []struct{ in, want color.NRGBA }{
+		// the published P3 coordinates of the sRGB primaries, 0.9175,
+		// 0.2003, 0.1386 and so on, in 8 bits
+		{color.NRGBA{255, 0, 0, 255}, color.NRGBA{234, 51, 35, 255}},
+		{color.NRGBA{0, 255, 0, 255}, color.NRGBA{117, 251, 76, 255}},
+		{color.NRGBA{0, 0, 255, 255}, color.NRGBA{0, 0, 245, 255}},
+		// the shared white point and tone curve leave greys alone
+		{color.NRGBA{255, 255, 255, 255}, color.NRGBA{255, 255, 255, 255}},
+		{color.NRGBA{0, 0, 0, 255}, color.NRGBA{0, 0, 0, 255}},
+		{color.NRGBA{128, 128, 128, 255}, color.NRGBA{128, 128, 128, 255}},
+		{color.NRGBA{255, 0, 0, 100}, color.NRGBA{234, 51, 35, 100}},
+	} {
+		img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+		img.SetNRGBA(0, 0, tc.in)
+		if got := ToDisplayP3(img).NRGBAAt(0, 0); got != tc.want {
+			t.Errorf("ToDisplayP3(%v) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestEncodeWithProfileErrors(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	if err := EncodeWithProfile(io.Discard, img, "jpeg", nil, ProfileP3); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("p3 jpeg: %v", err)
+	}
+	if err := EncodeWithProfile(io.Discard, img, "png", nil, "adobe"); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("unknown profile: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"math"
+
+	"github.com/whalelogic/mandlebrot/internal/parallel"
+)
+
+// autoContrastRemap stretches palette position t so that [tmin, tmax]
+// covers the whole palette: t = (t - tmin) / (tmax - tmin).
+func autoContrastRemap(tmin, tmax, t float64) float64 {
+	return clamp01((t - tmin) / (tmax - tmin))
+}
+
+// autoContrast is the second pass of Options.AutoContrast: it finds the
+// range of the palette positions computeRow kept in fr.ts for the escaped
+// pixels and colors those pixels again with the range stretched over the
+// whole palette. Interior pixels keep their color. A frame whose escaped
+// pixels all share one position, or has none, is left alone.
+func (fr *frame) autoContrast(opts *Options) {
+	tmin, tmax := math.Inf(1), math.Inf(-1)
+	for _, t := range fr.ts {
+		if !math.IsNaN(t) {
+			tmin, tmax = min(tmin, t), max(tmax, t)
+		}
+	}
+	if !(tmax > tmin) {
+		return
+	}
+	w := opts.Width
+	parallel.Rows(opts.Height, opts.Procs, func(y int) {
+		for x, t := range fr.ts[y*w : (y+1)*w] {
+			if math.IsNaN(t) {
+				continue
+			}
+			t = autoContrastRemap(tmin, tmax, t)
+			if opts.PalettePhase != 0 {
+				t = phaseT(t, opts.PalettePhase)
+			}
+			switch {
+			case opts.OutputHSL:
+				c := hslPixel(t, opts.Palette)
+				if fr.nimg != nil {
+					fr.nimg.Set(x, y, c)
+				} else {
+					fr.img.SetRGBA(x, y, c)
+				}
+			case fr.nimg != nil:
+				fr.nimg.SetNRGBA(x, y, opts.Adjust.nrgba(opts.Palette.InterpolateNRGBA(t)))
+			default:
+				fr.img.SetRGBA(x, y, opts.Adjust.rgba(opts.Palette.Interpolate(t)))
+			}
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package render
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/palette"
+)
+
+// greyRamp is a palette from black at 0 to white at 1, so a pixel's grey
+// level is its palette position.
+func greyRamp() *palette.ColorMap {
+	cm := &palette.ColorMap{Keyword: "GreyRamp", Colors: []palette.Color{
+		palette.NewStop(0, 0, 0, 0, 0xff), palette.NewStop(1, 0xff, 0xff, 0xff, 0xff),
+	}}
+	palette.Normalize(cm)
+	return cm
+}
+
+func TestAutoContrastRemap(t *testing.T) {
+	for _, tc := range []struct{ t, want float64 }{
+		{0.8, 0}, {1, 1}, {0.9, 0.5}, {0.85, 0.25},
+		// outside the range is clipped
+		{0.5, 0}, {1.2, 1},
+	} {
+		if got := autoContrastRemap(0.8, 1, tc.t); math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("autoContrastRemap(0.8, 1, %v) = %v, want %v", tc.t, got, tc.want)
+		}
+	}
+}
+
+func TestAutoContrastSpread(t *testing.T) {
+	// a frame whose escaped positions all lie in [0.8, 1], around an
+	// interior pixel
+	opts := smallOptions(t, WithSize(101, 1), WithPalette(greyRamp()), WithAutoContrast(true))
+	fr := newFrame(&opts)
+	for x := range 101 {
+		fr.ts[x] = 0.8 + 0.2*float64(x)/100
+		fr.img.SetRGBA(x, 0, opts.Palette.Interpolate(fr.ts[x]))
+	}
+	fr.ts[50] = math.NaN()
+	fr.img.SetRGBA(50, 0, opts.Palette.Interpolate(0.123))
+	before := fr.img.RGBAAt(0, 0).R
+	fr.autoContrast(&opts)
+
+	// the positions now run over the whole palette, in the same order
+	if before < 0xcc {
+		t.Fatalf("the lowest position starts at grey %d", before)
+	}
+	for x := range 101 {
+		got := fr.img.RGBAAt(x, 0).R
+		want := uint8(math.Round(255 * float64(x) / 100))
+		if x == 50 {
+			want = opts.Palette.Interpolate(0.123).R
+		}
+		if d := int(got) - int(want); d < -1 || d > 1 {
+			t.Errorf("x = %d: grey %d, want %d", x, got, want)
+		}
+	}
+}
+
+func TestAutoContrastRender(t *testing.T) {
+	// next to the parabolic point at -3/4 the escaped pixels all take
+	// many iterations, so they use only the top of the palette
+	view := WithViewport(coords.Bounds{Xmin: -0.752, Xmax: -0.748, Ymin: 0.002, Ymax: 0.005})
+	spread := func(on bool) (lo, hi uint8) {
+		res, err := Render(context.Background(), smallOptions(t, view, WithIterations(700), WithPalette(greyRamp()), WithAutoContrast(on)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lo, hi = 0xff, 0
+		for i, in := range res.Inside {
+			if !in {
+				g := res.Image.Pix[4*i]
+				lo, hi = min(lo, g), max(hi, g)
+			}
+		}
+		return lo, hi
+	}
+	if lo, hi := spread(false); lo < 0xcc {
+		t.Fatalf("without auto-contrast the escaped greys span %d-%d", lo, hi)
+	}
+	if lo, hi := spread(true); lo != 0 || hi != 0xff {
+		t.Errorf("with auto-contrast the escaped greys span %d-%d, want 0-255", lo, hi)
+	}
+}
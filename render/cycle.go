@@ -0,0 +1,51 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// CycleT returns the palette position of escape count nu in one frame of
+// a palette cycling animation. The palette is swept forward and back once
+// every period iterations, shifted by phase, a fraction of the sweep.
+// Going back and forth rather than wrapping around keeps neighboring
+// counts close in color, and phases a whole number apart give the same
+// frame, so a loop whose phase advances by whole numbers has no seam.
+func CycleT(nu, period, phase float64) float64 {
+	u := nu/period + phase
+	f := u - math.Floor(u)
+	return 1 - math.Abs(2*f-1)
+}
+
+// Cycle colors the escape counts of r into dst, which must be the size of
+// the render, as one frame of a palette cycling animation: each escaped
+// pixel gets the palette color at CycleT and interior pixels the first
+// color, with Options.Adjust applied. No iteration is redone, so it needs the buffers that
+// Options.DiscardBuffers drops.
+func (r *Result) Cycle(dst *image.RGBA, period, phase float64) error {
+	if r.Iters == nil {
+		return fmt.Errorf("%w: palette cycling needs the iteration buffer", ErrInvalidOptions)
+	}
+	if !(period > 0) {
+		return fmt.Errorf("%w: cycle period %g: must be positive", ErrInvalidOptions, period)
+	}
+	w, h := r.Options.Width, r.Options.Height
+	if dst.Rect.Dx() != w || dst.Rect.Dy() != h {
+		return fmt.Errorf("%w: cycle target %v: render is %dx%d", ErrInvalidOptions, dst.Rect.Size(), w, h)
+	}
+	cm := r.Options.Palette
+	adj := r.Options.Adjust
+	interior := adj.rgba(cm.Interpolate(0))
+	for y := range h {
+		for x := range w {
+			i := y*w + x
+			c := interior
+			if !r.Inside[i] {
+				c = adj.rgba(cm.Interpolate(CycleT(r.Iters[i], period, phase)))
+			}
+			dst.SetRGBA(dst.Rect.Min.X+x, dst.Rect.Min.Y+y, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package render
+
+import (
+	"math"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+// DD is a double-double number: the unevaluated sum Hi + Lo of two
+// float64s with |Lo| at most half an ulp of Hi, which carries 106 bits
+// of mantissa, about 32 decimal digits, where a float64 has 53. The
+// exponent range is still that of a float64.
+//
+// The operations below are the classic ones of Dekker and Knuth: sums
+// and products are worked out with their rounding errors exactly by
+// TwoSum and by Dekker's product on Veltkamp's split, and the errors are
+// folded back into Lo. Their results are good to a few units in the
+// last of those 106 bits.
+type DD struct {
+	Hi, Lo float64
+}
+
+// The error-free transformations depend on each product and sum being
+// rounded on its own. Go may fuse x*y + z into one FMA on some
+// platforms, which would break them, so the explicit float64
+// conversions below, which the spec says round, keep it from doing so.
+
+// ddTwoSum returns a+b rounded and its rounding error.
+func ddTwoSum(a, b float64) DD {
+	s := a + b
+	bb := s - a
+	return DD{s, (a - (s - bb)) + (b - bb)}
+}
+
+// ddQuickTwoSum is ddTwoSum for |a| >= |b|.
+func ddQuickTwoSum(a, b float64) DD {
+	s := a + b
+	return DD{s, b - (s - a)}
+}
+
+// ddSplitter is 2^27+1, which splits a float64 into two halves of 26
+// bits each (the sign makes up the last).
+const ddSplitter = 1<<27 + 1
+
+// ddSplit is Veltkamp's split of a into hi + lo, each with a mantissa
+// short enough that the product of two halves is exact.
+func ddSplit(a float64) (hi, lo float64) {
+	t := float64(ddSplitter * a)
+	hi = t - (t - a)
+	return hi, a - hi
+}
+
+// ddTwoProd returns a·b rounded and its rounding error, by Dekker's
+// product.
+func ddTwoProd(a, b float64) DD {
+	p := float64(a * b)
+	ah, al := ddSplit(a)
+	bh, bl := ddSplit(b)
+	e := float64(float64(float64(float64(ah*bh)-p)+float64(ah*bl))+float64(al*bh)) + float64(al*bl)
+	return DD{p, e}
+}
+
+// DDAdd returns a + b.
+func DDAdd(a, b DD) DD {
+	s := ddTwoSum(a.Hi, b.Hi)
+	t := ddTwoSum(a.Lo, b.Lo)
+	s = ddQuickTwoSum(s.Hi, s.Lo+t.Hi)
+	return ddQuickTwoSum(s.Hi, s.Lo+t.Lo)
+}
+
+// DDSub returns a - b.
+func DDSub(a, b DD) DD {
+	return DDAdd(a, DD{-b.Hi, -b.Lo})
+}
+
+// DDMul returns a · b.
+func DDMul(a, b DD) DD {
+	p := ddTwoProd(a.Hi, b.Hi)
+	return ddQuickTwoSum(p.Hi, p.Lo+float64(a.Hi*b.Lo)+float64(a.Lo*b.Hi))
+}
+
+// DDSqAbs returns re² + im², the squared magnitude of re + im·i.
+func DDSqAbs(re, im DD) DD {
+	return DDAdd(DDMul(re, re), DDMul(im, im))
+}
+
+// ddMulFloat returns a · b for a float64 b.
+func ddMulFloat(a DD, b float64) DD {
+	p := ddTwoProd(a.Hi, b)
+	return ddQuickTwoSum(p.Hi, p.Lo+float64(a.Lo*b))
+}
+
+// ddDivFloat returns a / b for a float64 b.
+func ddDivFloat(a DD, b float64) DD {
+	q := a.Hi / b
+	p := ddTwoProd(q, b)
+	s := ddTwoSum(a.Hi, -p.Hi)
+	r := (s.Hi + (s.Lo - p.Lo + a.Lo)) / b
+	return ddQuickTwoSum(q, r)
+}
+
+// MandelbrotIterationsDD is the Mandelbrot escape-time loop, z = z² + c
+// from z = 0, in double-double arithmetic, for views too deep for the
+// points of neighboring pixels to be told apart in float64. It returns
+// the iteration at which |z|² passed bailoutSq, maxIter if it never did,
+// and z at that point rounded to complex128.
+//
+// It has neither of the shortcuts of the float64 kernels: the cardioid
+// and bulb tests are as good in float64 and are left to the caller, and
+// cycle detection compares orbits to an absolute tolerance that at these
+// depths an escaping orbit can pass within.
+func MandelbrotIterationsDD(cRe, cIm DD, maxIter int, bailoutSq float64) (int, complex128) {
+	var x, y, x2, y2 DD
+	for n := range maxIter {
+		xy := DDMul(x, y)
+		x = DDAdd(DDSub(x2, y2), cRe)
+		y = DDAdd(DD{2 * xy.Hi, 2 * xy.Lo}, cIm)
+		x2, y2 = DDMul(x, x), DDMul(y, y)
+		// not within the radius, so a NaN escapes too, as in safeEscape
+		if !(x2.Hi+y2.Hi <= bailoutSq) {
+			return n, complex(x.Hi, y.Hi)
+		}
+	}
+	return maxIter, complex(x.Hi, y.Hi)
+}
+
+// ddThreshold is how close together, relative to the largest coordinate
+// in the view, pixels may sample before the render switches to
+// double-double: 2^-44, or 256 float64 ulps, below which pixels start
+// to share sample points and rounding in the orbit shows as noise.
+const ddThreshold = 0x1p-44
+
+// ddView maps pixels to their sample points in double-double, for
+// computeRow. Like coords.Viewport it measures from the center of the
+// view in half pixels; the center and spans of the float64 bounds are
+// exact in double-double, so only the final scaling rounds.
+type ddView struct {
+	cx, cy DD // center of the view
+	sx, sy DD // width and height of the view, sy negated unless FlipY
+	w, h   float64
+}
+
+// doubleDouble reports whether o can be rendered in double-double once
+// its pixels are close enough together to need it: only the plain
+// Mandelbrot set, unrotated and untransformed, can; any other view keeps
+// to float64.
+func (o *Options) doubleDouble() bool {
+	f, ok := o.Fractal.(fractal.Mandelbrot)
+	return ok && f.Z0 == 0 && o.Rotation == 0 && o.Transform.IsZero()
+}
+
+// newDDView returns the ddView for opts, and whether opts is rendered in
+// double-double: whether it can be, and its pixels are close enough
+// together to need it.
+func newDDView(opts *Options) (ddView, bool) {
+	if !opts.doubleDouble() {
+		return ddView{}, false
+	}
+	b := opts.Bounds
+	step := min(b.Width()/float64(opts.Width), b.Height()/float64(opts.Height))
+	mag := max(math.Abs(b.Xmin), math.Abs(b.Xmax), math.Abs(b.Ymin), math.Abs(b.Ymax))
+	if !(step < mag*ddThreshold) {
+		return ddView{}, false
+	}
+	cx, cy := ddTwoSum(b.Xmin, b.Xmax), ddTwoSum(b.Ymin, b.Ymax)
+	v := ddView{
+		cx: DD{cx.Hi / 2, cx.Lo / 2},
+		cy: DD{cy.Hi / 2, cy.Lo / 2},
+		sx: ddTwoSum(b.Xmax, -b.Xmin),
+		sy: ddTwoSum(b.Ymin, -b.Ymax), // row 0 at Ymax
+		w:  float64(opts.Width),
+		h:  float64(opts.Height),
+	}
+	if opts.FlipY {
+		v.sy = DD{-v.sy.Hi, -v.sy.Lo}
+	}
+	return v, true
+}
+
+// pixel returns the center of pixel (x, y).
+func (v ddView) pixel(x, y int) (re, im DD) {
+	re = DDAdd(v.cx, ddDivFloat(ddMulFloat(v.sx, float64(2*x+1)-v.w), 2*v.w))
+	im = DDAdd(v.cy, ddDivFloat(ddMulFloat(v.sy, float64(2*y+1)-v.h), 2*v.h))
+	return re, im
+}
+
+// iterateDD is iterate for the Mandelbrot set at the point (re, im),
+// which rounds to c, in double-double.
+func iterateDD(c complex128, re, im DD, maxIter int, bailoutSq float64) orbit {
+	if bailoutSq >= 4 {
+		if inCardioid(c) {
+			return orbit{iter: maxIter, how: resultCardioid, farIter: maxIter}
+		}
+		if inBulb(c) {
+			return orbit{iter: maxIter, how: resultBulb, farIter: maxIter}
+		}
+	}
+	n, z := MandelbrotIterationsDD(re, im, maxIter, bailoutSq)
+	how := resultEscaped
+	if n >= maxIter {
+		how = resultInterior
+	}
+	return mandelbrotOrbit(c, n, z, how)
+}
@@ -0,0 +1,238 @@
+package render
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+// bigPrec is well past the 106 bits of a DD, so that a big.Float
+// computation at it serves as the exact answer.
+const bigPrec = 300
+
+func newBig() *big.Float { return new(big.Float).SetPrec(bigPrec) }
+
+// ddBig returns d exactly as a big.Float.
+func ddBig(d DD) *big.Float {
+	return newBig().Add(big.NewFloat(d.Hi), big.NewFloat(d.Lo))
+}
+
+// bigDD returns v rounded to a DD.
+func bigDD(v *big.Float) DD {
+	hi, _ := v.Float64()
+	lo, _ := newBig().Sub(v, big.NewFloat(hi)).Float64()
+	return DD{hi, lo}
+}
+
+// bigIterations is MandelbrotIterationsDD's escape iteration in big.Float
+// at prec bits.
+func bigIterations(cr, ci *big.Float, maxIter int, bailoutSq float64, prec uint) int {
+	nf := func() *big.Float { return new(big.Float).SetPrec(prec) }
+	x, y, x2, y2, t := nf(), nf(), nf(), nf(), nf()
+	bail := big.NewFloat(bailoutSq)
+	for n := range maxIter {
+		t.Mul(x, y)
+		y.Add(t.Add(t, t), ci)
+		x.Add(x.Sub(x2, y2), cr)
+		x2.Mul(x, x)
+		y2.Mul(y, y)
+		if t.Add(x2, y2).Cmp(bail) > 0 {
+			return n
+		}
+	}
+	return maxIter
+}
+
+// relErr returns |got - want| / scale.
+func relErr(got DD, want, scale *big.Float) float64 {
+	d := newBig().Sub(ddBig(got), want)
+	e, _ := d.Quo(d.Abs(d), scale).Float64()
+	return e
+}
+
+func TestDDArithmetic(t *testing.T) {
+	// Each result is within a few units of the 106th bit of the exact
+	// one, measured against the size of the operands for the sums, where
+	// cancellation may leave a much smaller result.
+	const tol = 0x1p-100
+	r := rand.New(rand.NewPCG(1, 2))
+	random := func() DD {
+		hi := r.NormFloat64() * math.Pow(2, float64(r.IntN(40)-20))
+		return ddQuickTwoSum(hi, hi*0x1p-53*(r.Float64()-0.5))
+	}
+	abs := func(d DD) *big.Float { v := ddBig(d); return v.Abs(v) }
+	for range 10000 {
+		a, b := random(), random()
+		if r.IntN(4) == 0 {
+			// nearly cancelling
+			b = DDAdd(DD{-a.Hi, -a.Lo}, DD{a.Hi * 0x1p-60, 0})
+		}
+		A, B := ddBig(a), ddBig(b)
+		sumScale := newBig().Add(abs(a), abs(b))
+		for _, tc := range []struct {
+			op          string
+			got         DD
+			want, scale *big.Float
+		}{
+			{"DDAdd", DDAdd(a, b), newBig().Add(A, B), sumScale},
+			{"DDSub", DDSub(a, b), newBig().Sub(A, B), sumScale},
+			{"DDMul", DDMul(a, b), newBig().Mul(A, B), newBig().Mul(abs(a), abs(b))},
+			{"DDSqAbs", DDSqAbs(a, b), newBig().Add(newBig().Mul(A, A), newBig().Mul(B, B)), newBig().Add(newBig().Mul(A, A), newBig().Mul(B, B))},
+			{"ddMulFloat", ddMulFloat(a, b.Hi), newBig().Mul(A, big.NewFloat(b.Hi)), newBig().Mul(abs(a), big.NewFloat(math.Abs(b.Hi)))},
+			{"ddDivFloat", ddDivFloat(a, b.Hi), newBig().Quo(A, big.NewFloat(b.Hi)), newBig().Quo(abs(a), big.NewFloat(math.Abs(b.Hi)))},
+		} {
+			if tc.scale.Sign() == 0 {
+				continue
+			}
+			if e := relErr(tc.got, tc.want, tc.scale); e > tol {
+				t.Fatalf("%s(%v, %v) = %v, off by %g relative", tc.op, a, b, tc.got, e)
+			}
+		}
+	}
+}
+
+// deepPoint is a point in the seahorse valley, with more digits than a
+// DD holds.
+const deepRe, deepIm = "-0.743643887037158704752191506114774", "0.131825904205311970493132056385139"
+
+func TestMandelbrotIterationsDDDeep(t *testing.T) {
+	// 50 points at distances from 1e-13 down to 1e-26 of deepPoint, each
+	// rounded to a DD, against big.Float at bigPrec bits for exactly that
+	// point. Some orbits amplify rounding by more than 106 bits can
+	// absorb, and no DD computation can get those right; where DD
+	// disagrees, big.Float at 106 bits must disagree as well, which shows
+	// the point rather than the arithmetic is at fault.
+	const maxIter = 50000
+	bailoutSq := DefaultBailout * DefaultBailout
+	c0r, _ := newBig().SetString(deepRe)
+	c0i, _ := newBig().SetString(deepIm)
+	matched, float64Matched := 0, 0
+	for k := range 50 {
+		dist := math.Pow(10, -13-13*float64(k)/49)
+		angle := float64(k) * 2.4 // spread around the point
+		re := bigDD(newBig().Add(c0r, big.NewFloat(dist*math.Cos(angle))))
+		im := bigDD(newBig().Add(c0i, big.NewFloat(dist*math.Sin(angle))))
+
+		want := bigIterations(ddBig(re), ddBig(im), maxIter, bailoutSq, bigPrec)
+		got, _ := MandelbrotIterationsDD(re, im, maxIter, bailoutSq)
+		if got == want {
+			matched++
+		} else if at106 := bigIterations(ddBig(re), ddBig(im), maxIter, bailoutSq, 106); at106 == want {
+			t.Errorf("point %d (%.1e away): DD escapes at %d, big.Float at %d, at 106 bits too", k, dist, got, want)
+		}
+		if n, _ := MandelbrotIterationsDD(DD{re.Hi, 0}, DD{im.Hi, 0}, maxIter, bailoutSq); n == want {
+			float64Matched++
+		}
+	}
+	if matched < 45 {
+		t.Errorf("DD matched big.Float on %d of 50 points", matched)
+	}
+	// the low parts matter: without them, few points come out right
+	if float64Matched > 10 {
+		t.Errorf("the float64 parts alone matched on %d of 50 points", float64Matched)
+	}
+}
+
+func TestDDViewPixel(t *testing.T) {
+	b := coords.Bounds{Xmin: -0.74364388703716, Xmax: -0.743643887037156, Ymin: 0.131825904205311, Ymax: 0.131825904205314}
+	o := smallOptions(t, WithViewport(b), WithIterations(1000))
+	v, deep := newDDView(&o)
+	if !deep {
+		t.Fatalf("a %g wide view isn't rendered in double-double", o.Bounds.Width())
+	}
+	// pixel centers measured from the exact center of the view
+	two := big.NewFloat(2)
+	cx := newBig().Quo(newBig().Add(big.NewFloat(o.Bounds.Xmin), big.NewFloat(o.Bounds.Xmax)), two)
+	cy := newBig().Quo(newBig().Add(big.NewFloat(o.Bounds.Ymin), big.NewFloat(o.Bounds.Ymax)), two)
+	sx := newBig().Sub(big.NewFloat(o.Bounds.Xmax), big.NewFloat(o.Bounds.Xmin))
+	sy := newBig().Sub(big.NewFloat(o.Bounds.Ymax), big.NewFloat(o.Bounds.Ymin))
+	w, h := float64(o.Width), float64(o.Height)
+	for y := range o.Height {
+		for x := range o.Width {
+			re, im := v.pixel(x, y)
+			wantRe := newBig().Add(cx, newBig().Quo(newBig().Mul(sx, big.NewFloat(float64(2*x+1)-w)), big.NewFloat(2*w)))
+			wantIm := newBig().Sub(cy, newBig().Quo(newBig().Mul(sy, big.NewFloat(float64(2*y+1)-h)), big.NewFloat(2*h)))
+			if e := relErr(re, wantRe, big.NewFloat(1)); e > 0x1p-100 {
+				t.Fatalf("pixel (%d,%d): re off by %g", x, y, e)
+			}
+			if e := relErr(im, wantIm, big.NewFloat(1)); e > 0x1p-100 {
+				t.Fatalf("pixel (%d,%d): im off by %g", x, y, e)
+			}
+		}
+	}
+
+	// shallow views, other fractals and rotated views stay in float64
+	shallow := smallOptions(t)
+	julia, rotated := o, o
+	julia.Fractal = fractal.ByName("julia", complex(-0.8, 0.156))
+	rotated.Rotation = 0.1
+	for name, o := range map[string]Options{"default view": shallow, "Julia set": julia, "rotated view": rotated} {
+		if _, deep := newDDView(&o); deep {
+			t.Errorf("%s rendered in double-double", name)
+		}
+	}
+}
+
+func TestRenderDeepUsesDD(t *testing.T) {
+	// A view 2e-15 wide is a handful of float64 ulps across, so in float64
+	// whole runs of pixels would share a sample point. Each pixel must
+	// instead be the DD orbit of its own center.
+	b := coords.Bounds{Xmin: -0.743643887037159, Xmax: -0.743643887037157, Ymin: 0.131825904205311, Ymax: 0.1318259042053125}
+	o := smallOptions(t, WithViewport(b), WithIterations(20000))
+	v, deep := newDDView(&o)
+	if !deep {
+		t.Fatal("view isn't rendered in double-double")
+	}
+	got := make([]int, o.Width*o.Height)
+	o.OnPixel = func(x, y int, r PixelResult) { got[y*o.Width+x] = r.Iter }
+	if _, err := Render(context.Background(), o); err != nil {
+		t.Fatal(err)
+	}
+
+	bailoutSq := o.Bailout * o.Bailout
+	float64Differs := 0
+	for y := range o.Height {
+		for x := range o.Width {
+			re, im := v.pixel(x, y)
+			want, _ := MandelbrotIterationsDD(re, im, o.MaxIter, bailoutSq)
+			if n := got[y*o.Width+x]; n != want {
+				t.Fatalf("pixel (%d,%d) escaped at %d, its DD orbit at %d", x, y, n, want)
+			}
+			c := o.Viewport().PixelToComplex(x, y)
+			if n, _ := fractal.Iterate(fractal.Mandelbrot{}, c, o.MaxIter, bailoutSq); n != want {
+				float64Differs++
+			}
+		}
+	}
+	if float64Differs == 0 {
+		t.Error("float64 gives the same image; the view is too shallow to tell")
+	}
+}
+
+// benchC is a point deep in the seahorse valley as a double-double, with
+// low parts a float64 would drop, which escapes after 8054 iterations.
+var benchC = [2]DD{
+	{Hi: -0.7436438870371587, Lo: 9.85e-18},
+	{Hi: 0.13182590420531198, Lo: -1.32e-18},
+}
+
+func BenchmarkMandelbrotIterationsDDDeep(b *testing.B) {
+	for range b.N {
+		sinkIter, sinkZ = MandelbrotIterationsDD(benchC[0], benchC[1], 20000, DefaultBailout*DefaultBailout)
+	}
+}
+
+// BenchmarkMandelbrotIterationsBigFloatDeep iterates benchC in math/big at
+// the 106 bits of a double-double, for comparison with
+// BenchmarkMandelbrotIterationsDDDeep.
+func BenchmarkMandelbrotIterationsBigFloatDeep(b *testing.B) {
+	cr, ci := ddBig(benchC[0]), ddBig(benchC[1])
+	for range b.N {
+		sinkIter = bigIterations(cr, ci, 20000, DefaultBailout*DefaultBailout, 106)
+	}
+}
@@ -0,0 +1,52 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Formats lists the output formats Encode understands.
+var Formats = []string{"png", "jpeg"}
+
+// FormatFromPath derives the output format from a file extension.
+func FormatFromPath(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".png":
+		return "png", nil
+	case ".jpg", ".jpeg":
+		return "jpeg", nil
+	default:
+		return "", fmt.Errorf("%w: %q (want one of %s)", ErrUnsupportedFormat, ext, strings.Join(Formats, ", "))
+	}
+}
+
+// parallelPNGPixels is the size from which encodePNG compresses on every
+// CPU. Below it the bands cost more than they save, and make the file a
+// percent or two larger.
+const parallelPNGPixels = 1 << 20
+
+// encodePNG writes img to w as a PNG, with ParallelPNGEncode if it is
+// large enough to gain from it.
+func encodePNG(w io.Writer, img image.Image) error {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Dx()*rgba.Rect.Dy() >= parallelPNGPixels {
+		return ParallelPNGEncode(w, rgba, 0)
+	}
+	return png.Encode(w, img)
+}
+
+// Encode writes img to w in the given format.
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case "png":
+		return encodePNG(w, img)
+	case "jpeg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 95})
+	default:
+		return fmt.Errorf("%w: %q (want one of %s)", ErrUnsupportedFormat, format, strings.Join(Formats, ", "))
+	}
+}
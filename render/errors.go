@@ -0,0 +1,90 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/whalelogic/mandlebrot/palette"
+)
+
+// Sentinel errors, for use with errors.Is. Errors returned by this package
+// wrap one of these together with the details.
+var (
+	ErrInvalidOptions    = errors.New("invalid options")
+	ErrInvalidViewport   = errors.New("invalid viewport")
+	ErrUnknownPalette    = errors.New("unknown palette")
+	ErrUnsupportedFormat = errors.New("unsupported format")
+	ErrCancelled         = errors.New("render cancelled")
+	ErrPrecisionExceeded = errors.New("viewport exceeds float64 precision")
+	ErrClosed            = errors.New("renderer closed")
+)
+
+// PaletteError reports a palette name that isn't registered, along with
+// the closest known names. It matches ErrUnknownPalette.
+type PaletteError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *PaletteError) Error() string {
+	msg := fmt.Sprintf("unknown palette %q", e.Name)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(e.Suggestions, ", "))
+	}
+	return msg
+}
+
+func (e *PaletteError) Is(target error) bool { return target == ErrUnknownPalette }
+
+// CancelledError reports how far a render got before its context ended.
+// It matches ErrCancelled and unwraps to the context's error.
+type CancelledError struct {
+	Done, Total int // completed and total rows
+	Err         error
+}
+
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("render cancelled after %d of %d rows: %v", e.Done, e.Total, e.Err)
+}
+
+func (e *CancelledError) Is(target error) bool { return target == ErrCancelled }
+func (e *CancelledError) Unwrap() error        { return e.Err }
+
+// unknownPalette builds a PaletteError suggesting the built-in palettes
+// whose names are close to name.
+func unknownPalette(name string) *PaletteError {
+	e := &PaletteError{Name: name}
+	lower := strings.ToLower(name)
+	for _, keyword := range palette.List() {
+		k := strings.ToLower(keyword)
+		if strings.Contains(k, lower) || strings.Contains(lower, k) || levenshtein(k, lower) <= 3 {
+			e.Suggestions = append(e.Suggestions, keyword)
+		}
+	}
+	if len(e.Suggestions) == 0 {
+		e.Suggestions = palette.List()
+	}
+	return e
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,91 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+var sentinels = []error{
+	ErrInvalidOptions, ErrInvalidViewport, ErrUnknownPalette, ErrUnsupportedFormat,
+	ErrCancelled, ErrPrecisionExceeded, ErrClosed,
+}
+
+func TestErrorClassification(t *testing.T) {
+	render := func(opts ...Option) error {
+		o, err := New(append([]Option{WithSize(64, 48), WithIterations(100)}, opts...)...)
+		if err != nil {
+			return err
+		}
+		_, err = Render(context.Background(), o)
+		return err
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tc := range []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"zero size", render(WithSize(0, 48)), ErrInvalidOptions},
+		{"zero iterations", render(WithIterations(0)), ErrInvalidOptions},
+		{"bailout of 1", render(WithBailout(1)), ErrInvalidOptions},
+		{"empty viewport", render(WithViewport(coords.Bounds{Xmin: 1, Xmax: 1, Ymin: -1, Ymax: 1})), ErrInvalidViewport},
+		{"inverted viewport", render(WithViewport(coords.Bounds{Xmin: 1, Xmax: -1, Ymin: -1, Ymax: 1})), ErrInvalidViewport},
+		// a few ulps wide; a Julia set, as the Mandelbrot set would go to
+		// double-double
+		{"window below precision", render(WithFractalName("julia", -0.8+0.156i), WithViewport(coords.Bounds{Xmin: -0.5, Xmax: -0.5 + 1e-15, Ymin: 0.5, Ymax: 0.5 + 1e-15})), ErrPrecisionExceeded},
+		{"unknown palette", render(WithPaletteName("NoSuchPalette")), ErrUnknownPalette},
+		{"unknown format", Encode(io.Discard, nil, "bmp"), ErrUnsupportedFormat},
+		{"unknown extension", func() error { _, err := FormatFromPath("out.tiff"); return err }(), ErrUnsupportedFormat},
+		{"cancelled", func() error { _, err := Render(cancelled, smallOptions(t)); return err }(), ErrCancelled},
+		{"closed renderer", func() error {
+			r, err := NewRenderer(smallOptions(t))
+			if err != nil {
+				return err
+			}
+			r.Close()
+			_, err = r.Render(context.Background(), smallOptions(t).Viewport())
+			return err
+		}(), ErrClosed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.err == nil {
+				t.Fatalf("no error, want %v", tc.want)
+			}
+			for _, s := range sentinels {
+				if got := errors.Is(tc.err, s); got != (s == tc.want) {
+					t.Errorf("errors.Is(%q, %v) = %v", tc.err, s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPaletteErrorSuggests(t *testing.T) {
+	_, err := New(WithPaletteName("ThermalHet"))
+	var pe *PaletteError
+	if !errors.As(err, &pe) {
+		t.Fatalf("got %v, want a PaletteError", err)
+	}
+	if pe.Name != "ThermalHet" || len(pe.Suggestions) == 0 || pe.Suggestions[0] != "ThermalHeat" {
+		t.Errorf("PaletteError{%q, %v}, want ThermalHeat suggested", pe.Name, pe.Suggestions)
+	}
+}
+
+func TestCancelledErrorUnwraps(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Render(ctx, smallOptions(t))
+	var ce *CancelledError
+	if !errors.As(err, &ce) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want a CancelledError wrapping context.Canceled", err)
+	}
+	if ce.Total != 48 || ce.Done > ce.Total {
+		t.Errorf("%d of %d rows", ce.Done, ce.Total)
+	}
+}
@@ -0,0 +1,43 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultEstimateFraction is the share of the pixels EstimateRenderTime
+// samples for the command line's -estimate-time.
+const DefaultEstimateFraction = 0.01
+
+// EstimateRenderTime predicts how long Render(ctx, opts) will take by
+// rendering an evenly spread sample of about sampleFraction of its
+// pixels and scaling the time taken up to the full count. The sample is
+// the same view at a smaller size, so it meets the interior, the
+// boundary and the exterior in the same proportions, with the same
+// iterations, coloring and worker count; the callbacks are dropped.
+// Fixed costs such as starting the workers are scaled up with the rest,
+// so small renders are overestimated, and encoding isn't included.
+// sampleFraction must be in (0, 1].
+func EstimateRenderTime(ctx context.Context, opts Options, sampleFraction float64) (time.Duration, error) {
+	if !(sampleFraction > 0 && sampleFraction <= 1) {
+		return 0, fmt.Errorf("%w: sample fraction %g: must be in (0, 1]", ErrInvalidOptions, sampleFraction)
+	}
+	if err := opts.Validate(); err != nil {
+		return 0, err
+	}
+	scale := math.Sqrt(sampleFraction)
+	o := opts
+	o.Width = max(int(math.Round(float64(opts.Width)*scale)), 1)
+	o.Height = max(int(math.Round(float64(opts.Height)*scale)), 1)
+	o.RowsPerChunk = 0
+	o.OnPixel, o.OnProgress, o.OnRegion, o.Metrics = nil, nil, nil, nil
+	o.RecordTimings = false
+	start := time.Now()
+	if _, err := Render(ctx, o); err != nil {
+		return 0, err
+	}
+	full := float64(opts.Width) * float64(opts.Height)
+	return time.Duration(float64(time.Since(start)) * full / float64(o.Width*o.Height)), nil
+}
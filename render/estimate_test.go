@@ -0,0 +1,74 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+func TestEstimateRenderTime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("times a full render")
+	}
+	opts := smallOptions(t, WithSize(800, 600), WithIterations(3000),
+		WithViewport(coords.Bounds{Xmin: -0.76, Xmax: -0.72, Ymin: 0.09, Ymax: 0.12}))
+	ctx := context.Background()
+	// the best of a few runs of each, to keep a busy machine's pauses out
+	best := func(f func() time.Duration) time.Duration {
+		d := time.Duration(math.MaxInt64)
+		for range 3 {
+			d = min(d, f())
+		}
+		return d
+	}
+	actual := best(func() time.Duration {
+		start := time.Now()
+		if _, err := Render(ctx, opts); err != nil {
+			t.Fatal(err)
+		}
+		return time.Since(start)
+	})
+	est := best(func() time.Duration {
+		d, err := EstimateRenderTime(ctx, opts, 0.01)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	})
+	if ratio := float64(est) / float64(actual); ratio < 1.0/3 || ratio > 3 {
+		t.Errorf("estimated %v for a render taking %v, off by more than 3x", est, actual)
+	}
+}
+
+func TestEstimateRenderTimeErrors(t *testing.T) {
+	opts := smallOptions(t)
+	for _, f := range []float64{0, -0.1, 1.5, math.NaN()} {
+		if _, err := EstimateRenderTime(context.Background(), opts, f); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("fraction %v: %v", f, err)
+		}
+	}
+	bad := opts
+	bad.Width = 0
+	if _, err := EstimateRenderTime(context.Background(), bad, 0.01); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("invalid options: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := EstimateRenderTime(ctx, opts, 0.5); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled: %v", err)
+	}
+
+	// the sample never calls back, and a tiny fraction still renders a pixel
+	called := false
+	opts.OnProgress = func(done, total int) { called = true }
+	if d, err := EstimateRenderTime(context.Background(), opts, 1e-9); err != nil || d <= 0 {
+		t.Errorf("tiny fraction: %v, %v", d, err)
+	}
+	if called {
+		t.Error("the sample called OnProgress")
+	}
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/whalelogic/mandlebrot/palette"
+)
+
+// hslPixel is the Options.OutputHSL pixel for palette position t: the
+// palette color converted to HSL and packed into the color channels, hue
+// in R (0–360 degrees as 0–255), saturation in G and lightness in B (each
+// 0–100% as 0–255). The pixel is opaque whatever the palette's alpha.
+func hslPixel(t float64, cm *palette.ColorMap) color.RGBA {
+	c := cm.InterpolateNRGBA(t)
+	h, s, l := rgbToHSL(c.R, c.G, c.B)
+	return color.RGBA{
+		R: uint8(math.Round(h / 360 * 255)),
+		G: uint8(math.Round(s / 100 * 255)),
+		B: uint8(math.Round(l / 100 * 255)),
+		A: 0xff,
+	}
+}
+
+// rgbToHSL converts an 8-bit color to hue in degrees [0, 360) and
+// saturation and lightness in percent [0, 100]. Grays have hue 0.
+func rgbToHSL(r, g, b uint8) (h, s, l float64) {
+	rf, gf, bf := float64(r)/255, float64(g)/255, float64(b)/255
+	hi, lo := max(rf, gf, bf), min(rf, gf, bf)
+	l = (hi + lo) / 2
+	d := hi - lo
+	if d == 0 {
+		return 0, 0, l * 100
+	}
+	s = d / (1 - math.Abs(2*l-1))
+	switch hi {
+	case rf:
+		h = math.Mod((gf-bf)/d+6, 6)
+	case gf:
+		h = (bf-rf)/d + 2
+	default:
+		h = (rf-gf)/d + 4
+	}
+	return h * 60, s * 100, l * 100
+}
+
+// hslToRGB is the inverse of rgbToHSL, for reading OutputHSL images back.
+func hslToRGB(h, s, l float64) (r, g, b uint8) {
+	s, l = clamp01(s/100), clamp01(l/100)
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := math.Mod(math.Mod(h, 360)+360, 360) / 60
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+	var rf, gf, bf float64
+	switch {
+	case hp < 1:
+		rf, gf = c, x
+	case hp < 2:
+		rf, gf = x, c
+	case hp < 3:
+		gf, bf = c, x
+	case hp < 4:
+		gf, bf = x, c
+	case hp < 5:
+		rf, bf = x, c
+	default:
+		rf, bf = c, x
+	}
+	m := l - c/2
+	to8 := func(v float64) uint8 { return uint8(math.Round(clamp01(v+m) * 255)) }
+	return to8(rf), to8(gf), to8(bf)
+}
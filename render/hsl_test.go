@@ -0,0 +1,66 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/palette"
+)
+
+func TestRGBToHSLKnownValues(t *testing.T) {
+	for _, tc := range []struct {
+		r, g, b uint8
+		h, s, l float64
+	}{
+		{0, 0, 0, 0, 0, 0},
+		{255, 255, 255, 0, 0, 100},
+		{128, 128, 128, 0, 0, 50.19607843137255},
+		{255, 0, 0, 0, 100, 50},
+		{0, 255, 0, 120, 100, 50},
+		{0, 0, 255, 240, 100, 50},
+		{255, 255, 0, 60, 100, 50},
+		{255, 0, 255, 300, 100, 50},
+		{255, 128, 128, 0, 100, 75.09803921568627},
+	} {
+		h, s, l := rgbToHSL(tc.r, tc.g, tc.b)
+		if math.Abs(h-tc.h) > 1e-9 || math.Abs(s-tc.s) > 1e-9 || math.Abs(l-tc.l) > 1e-9 {
+			t.Errorf("rgbToHSL(%d, %d, %d) = %v, %v, %v; want %v, %v, %v", tc.r, tc.g, tc.b, h, s, l, tc.h, tc.s, tc.l)
+		}
+	}
+}
+
+func TestHSLRoundTrip(t *testing.T) {
+	diff := func(a, b uint8) int { return max(int(a)-int(b), int(b)-int(a)) }
+	for r := 0; r < 256; r += 3 {
+		for g := 0; g < 256; g += 3 {
+			for b := 0; b < 256; b += 3 {
+				h, s, l := rgbToHSL(uint8(r), uint8(g), uint8(b))
+				r2, g2, b2 := hslToRGB(h, s, l)
+				if d := max(diff(uint8(r), r2), diff(uint8(g), g2), diff(uint8(b), b2)); d > 2 {
+					t.Fatalf("(%d, %d, %d) comes back as (%d, %d, %d)", r, g, b, r2, g2, b2)
+				}
+			}
+		}
+	}
+}
+
+func TestHSLPixel(t *testing.T) {
+	// packing each of H, S and L into a byte costs some accuracy, most
+	// of it in the hue: 256 steps for 360 degrees
+	diff := func(a, b uint8) int { return max(int(a)-int(b), int(b)-int(a)) }
+	for _, p := range palette.ColorPalettes {
+		cm := palette.Get(p.Keyword)
+		for i := range 101 {
+			x := float64(i) / 100
+			px := hslPixel(x, cm)
+			if px.A != 0xff {
+				t.Fatalf("%s at %v: alpha %d", p.Keyword, x, px.A)
+			}
+			r, g, b := hslToRGB(float64(px.R)/255*360, float64(px.G)/255*100, float64(px.B)/255*100)
+			want := cm.InterpolateNRGBA(x)
+			if d := max(diff(r, want.R), diff(g, want.G), diff(b, want.B)); d > 4 {
+				t.Errorf("%s at %v: %v decodes to (%d, %d, %d), want %v", p.Keyword, x, px, r, g, b, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"unicode/utf16"
+)
+
+// iccD50 is the ICC profile connection space illuminant.
+var iccD50 = [3]float64{0.9642, 1.0, 0.8249}
+
+// bradford is the Bradford cone response matrix used for chromatic
+// adaptation.
+var bradford = mat3{
+	{0.8951, 0.2664, -0.1614},
+	{-0.7502, 1.7135, 0.0367},
+	{0.0389, -0.0685, 1.0296},
+}
+
+// adaptToD50 returns the Bradford matrix that adapts XYZ colors seen
+// under white to the D50 of the profile connection space.
+func adaptToD50(white [3]float64) mat3 {
+	src, dst := bradford.apply(white), bradford.apply(iccD50)
+	var scale mat3
+	for i := range 3 {
+		scale[i][i] = dst[i] / src[i]
+	}
+	return bradford.inverse().mul(scale).mul(bradford)
+}
+
+// displayP3ICC builds a version 4 ICC display profile for Display P3:
+// the P3 primaries adapted to D50, the sRGB tone curve for each channel
+// and the adaptation matrix in chad. The result is the same on every
+// call, down to the creation date.
+func displayP3ICC() []byte {
+	chad := adaptToD50(d65.xyz())
+	cols := chad.mul(p3Space.toXYZ())
+	colorant := func(i int) []byte { return iccXYZ([3]float64{cols[0][i], cols[1][i], cols[2][i]}) }
+	// the sRGB curve: (a·x + b)^g above d, c·x below it
+	trc := iccPara(2.4, 1/1.055, 0.055/1.055, 1/12.92, 0.04045)
+	tags := []struct {
+		sig  string
+		data []byte
+	}{
+		{"desc", iccMLUC("Display P3")},
+		{"cprt", iccMLUC("No copyright, use freely")},
+		{"wtpt", iccXYZ(iccD50)},
+		{"chad", iccSF32(chad)},
+		{"rXYZ", colorant(0)},
+		{"gXYZ", colorant(1)},
+		{"bXYZ", colorant(2)},
+		{"rTRC", trc},
+		{"gTRC", trc},
+		{"bTRC", trc},
+	}
+
+	const headerLen = 128
+	tableLen := 4 + 12*len(tags)
+	var table, data bytes.Buffer
+	binary.Write(&table, binary.BigEndian, uint32(len(tags)))
+	offsets := make(map[string]int) // identical tag data is stored once
+	for _, t := range tags {
+		off, ok := offsets[string(t.data)]
+		if !ok {
+			off = headerLen + tableLen + data.Len()
+			offsets[string(t.data)] = off
+			data.Write(t.data)
+			for data.Len()%4 != 0 {
+				data.WriteByte(0)
+			}
+		}
+		table.WriteString(t.sig)
+		binary.Write(&table, binary.BigEndian, uint32(off))
+		binary.Write(&table, binary.BigEndian, uint32(len(t.data)))
+	}
+
+	size := headerLen + tableLen + data.Len()
+	h := make([]byte, headerLen)
+	binary.BigEndian.PutUint32(h[0:], uint32(size))
+	binary.BigEndian.PutUint32(h[8:], 0x04300000) // version 4.3
+	copy(h[12:], "mntr")
+	copy(h[16:], "RGB ")
+	copy(h[20:], "XYZ ")
+	for i, v := range []uint16{2026, 1, 1, 0, 0, 0} {
+		binary.BigEndian.PutUint16(h[24+2*i:], v)
+	}
+	copy(h[36:], "acsp")
+	// rendering intent at 64 stays 0, perceptual
+	copy(h[68:], iccXYZ(iccD50)[8:])
+	out := append(h, table.Bytes()...)
+	return append(out, data.Bytes()...)
+}
+
+// s15Fixed16 encodes v in the ICC signed 15.16 fixed-point format.
+func s15Fixed16(v float64) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(int32(math.Round(v*65536))))
+}
+
+// iccXYZ is an XYZType tag holding one XYZ number.
+func iccXYZ(v [3]float64) []byte {
+	b := append([]byte("XYZ "), 0, 0, 0, 0)
+	for _, x := range v {
+		b = append(b, s15Fixed16(x)...)
+	}
+	return b
+}
+
+// iccSF32 is an s15Fixed16ArrayType tag holding m row by row.
+func iccSF32(m mat3) []byte {
+	b := append([]byte("sf32"), 0, 0, 0, 0)
+	for _, row := range m {
+		for _, x := range row {
+			b = append(b, s15Fixed16(x)...)
+		}
+	}
+	return b
+}
+
+// iccPara is a parametricCurveType tag of function type 3.
+func iccPara(g, a, b, c, d float64) []byte {
+	t := append([]byte("para"), 0, 0, 0, 0, 0, 3, 0, 0)
+	for _, x := range []float64{g, a, b, c, d} {
+		t = append(t, s15Fixed16(x)...)
+	}
+	return t
+}
+
+// iccMLUC is a multiLocalizedUnicodeType tag holding s in US English.
+func iccMLUC(s string) []byte {
+	text := utf16.Encode([]rune(s))
+	b := append([]byte("mluc"), 0, 0, 0, 0)
+	b = binary.BigEndian.AppendUint32(b, 1)  // one record
+	b = binary.BigEndian.AppendUint32(b, 12) // of 12 bytes
+	b = append(b, "enUS"...)
+	b = binary.BigEndian.AppendUint32(b, uint32(2*len(text)))
+	b = binary.BigEndian.AppendUint32(b, 28) // the string follows the record
+	for _, u := range text {
+		b = binary.BigEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+// compressedICC zlib-compresses an ICC profile for an iCCP chunk.
+func compressedICC(profile []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(profile); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,53 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"sync"
+)
+
+// FractalImage is an image.Image whose pixels are computed on first access.
+// Rows are computed whole and memoized, so reading a sub-rectangle only
+// pays for the rows it touches. It is safe for concurrent use.
+type FractalImage struct {
+	opts Options
+	rows []fractalRow
+}
+
+type fractalRow struct {
+	once sync.Once
+	img  *image.RGBA
+}
+
+// NewFractalImage returns a lazy image of the render described by opts.
+// Procs, OnProgress and Sparse are ignored; OnPixel, if set, is called from
+// whichever goroutine first touches a row.
+func NewFractalImage(opts Options) *FractalImage {
+	return &FractalImage{
+		opts: opts.withDefaults(),
+		rows: make([]fractalRow, opts.Height),
+	}
+}
+
+func (f *FractalImage) ColorModel() color.Model { return color.RGBAModel }
+
+func (f *FractalImage) Bounds() image.Rectangle {
+	return image.Rect(0, 0, f.opts.Width, f.opts.Height)
+}
+
+func (f *FractalImage) At(x, y int) color.Color { return f.RGBAAt(x, y) }
+
+// RGBAAt returns the pixel at (x, y), computing its row if needed.
+func (f *FractalImage) RGBAAt(x, y int) color.RGBA {
+	if !(image.Point{x, y}.In(f.Bounds())) {
+		return color.RGBA{}
+	}
+	r := &f.rows[y]
+	r.once.Do(func() {
+		// a one-row image at y's position, so computeRow can index it as usual
+		r.img = image.NewRGBA(image.Rect(0, y, f.opts.Width, y+1))
+		var st Stats
+		computeRow(&frame{img: r.img}, y, &f.opts, &st)
+	})
+	return r.img.RGBAAt(x, y)
+}
@@ -0,0 +1,37 @@
+package render
+
+import (
+	"context"
+	"image"
+	"image/draw"
+	"testing"
+)
+
+func TestFractalImageDrawSubRect(t *testing.T) {
+	o := smallOptions(t)
+	res, err := Render(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := 0
+	o.OnPixel = func(x, y int, _ PixelResult) {
+		if x == 0 {
+			rows++
+		}
+	}
+	lazy := NewFractalImage(o)
+
+	r := image.Rect(10, 5, 40, 25)
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), lazy, r.Min, draw.Src)
+	for y := range r.Dy() {
+		for x := range r.Dx() {
+			if got, want := dst.RGBAAt(x, y), res.Image.RGBAAt(r.Min.X+x, r.Min.Y+y); got != want {
+				t.Fatalf("(%d, %d): %v drawn, %v rendered", r.Min.X+x, r.Min.Y+y, got, want)
+			}
+		}
+	}
+	if rows != r.Dy() {
+		t.Errorf("%d rows computed for a %d-row rectangle", rows, r.Dy())
+	}
+}
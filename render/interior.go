@@ -0,0 +1,70 @@
+package render
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+// interiorNewtonSteps bounds the Newton steps interiorDistanceEstimate
+// takes to pin down the attracting cycle.
+const interiorNewtonSteps = 16
+
+// interiorDistanceEstimate estimates how far an interior point c of the
+// Mandelbrot family z² + c lies from the boundary, the interior
+// counterpart of the exterior distance estimate. The orbit of c from z0
+// is run for maxIter steps, the period of the cycle it settles into found
+// as ColoringAttractor finds it, and the cycle point w polished by
+// Newton's method on f^p(w) = w. With the derivatives of f^p at w,
+//
+//	d = (1 - |∂z|²) / |∂c∂z + ∂z∂z·∂c/(1 - ∂z)|
+//
+// is within a factor of 4 of the true distance. It is 0 for points whose
+// orbit escaped or didn't settle, which lie near the boundary, and for
+// cycles that aren't attracting.
+func interiorDistanceEstimate(f fractal.Mandelbrot, c complex128, maxIter int, bailoutSq float64) float64 {
+	p, w := finiteAttractor(f, c, maxIter, bailoutSq)
+	if p == 0 {
+		return 0
+	}
+	for range interiorNewtonSteps {
+		z, dz := w, complex128(1)
+		for range p {
+			z, dz = z*z+c, 2*z*dz
+		}
+		if dz == 1 {
+			break
+		}
+		step := (z - w) / (dz - 1)
+		w -= step
+		if cmplx.Abs(step) <= 1e-15*(1+cmplx.Abs(w)) {
+			break
+		}
+	}
+	z, dz := w, complex128(1)
+	var dc, dzdz, dcdz complex128
+	for range p {
+		dcdz = 2 * (z*dcdz + dc*dz)
+		dc = 2*z*dc + 1
+		dzdz = 2 * (dz*dz + z*dzdz)
+		dz = 2 * z * dz
+		z = z*z + c
+	}
+	mag := cmplx.Abs(dz)
+	if mag >= 1 {
+		return 0
+	}
+	d := (1 - mag*mag) / cmplx.Abs(dcdz+dzdz*dc/(1-dz))
+	if math.IsNaN(d) || math.IsInf(d, 0) {
+		return 0
+	}
+	return d
+}
+
+// interiorT maps an interior distance to [0,1] as exp(-scale·dist): the
+// boundary gets the palette end, glowing inward, and points deep inside
+// its start.
+func interiorT(dist, scale float64) float64 {
+	return math.Exp(-scale * dist)
+}
@@ -0,0 +1,85 @@
+package render
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+// interiorDist is interiorDistanceEstimate for the plain Mandelbrot set.
+func interiorDist(c complex128) float64 {
+	return interiorDistanceEstimate(fractal.Mandelbrot{}, c, 100000, 4)
+}
+
+func TestInteriorDistanceBoundary(t *testing.T) {
+	// the cusp, the root of the period-2 bulb, points on the cardioid and
+	// on the bulb's circle |c+1| = 1/4, and the tip of the set
+	boundary := []complex128{0.25, -0.75, -1.25, -2, complex(-1, 0.25)}
+	for _, th := range []float64{0.3, 1, 2, 2.8} {
+		e := cmplx.Exp(complex(0, th))
+		boundary = append(boundary, e/2-e*e/4)
+	}
+	for _, c := range boundary {
+		if d := interiorDist(c); d > 1e-3 {
+			t.Errorf("%v on the boundary: %v", c, d)
+		}
+	}
+	// and outside the set
+	for _, c := range []complex128{1, complex(0, 1.5), -2.1, 0.26} {
+		if d := interiorDist(c); d != 0 {
+			t.Errorf("%v outside: %v", c, d)
+		}
+	}
+}
+
+func TestInteriorDistanceInterior(t *testing.T) {
+	// the estimate at the centers, where the derivative vanishes
+	for _, tc := range []struct {
+		c    complex128
+		want float64
+	}{
+		{0, 0.5},
+		{-1, 0.25},
+	} {
+		if d := interiorDist(tc.c); math.Abs(d-tc.want) > 1e-9 {
+			t.Errorf("%v: %v, want %v", tc.c, d, tc.want)
+		}
+	}
+	// positive inside, in bulbs of other periods too
+	for _, c := range []complex128{-0.1 + 0.1i, 0.2, -1.1, complex(-0.1226, 0.7449), -1.7549, complex(0.2822, 0.5301)} {
+		if d := interiorDist(c); !(d > 0) {
+			t.Errorf("%v inside: %v", c, d)
+		}
+	}
+	// the big cardioid lies farther from the boundary than the small
+	// period-2 bulb, center for center and point for point
+	for _, pair := range [][2]complex128{{0, -1}, {-0.2, -1.05}, {-0.2 + 0.2i, -1 + 0.1i}} {
+		if a, b := interiorDist(pair[0]), interiorDist(pair[1]); !(a > b) {
+			t.Errorf("cardioid %v: %v, no farther than bulb %v: %v", pair[0], a, pair[1], b)
+		}
+	}
+	// and the estimate shrinks toward the cusp
+	prev := math.Inf(1)
+	for x := 0.0; x < 0.25; x += 0.02 {
+		d := interiorDist(complex(x, 0))
+		if !(d < prev) {
+			t.Errorf("%v: %v, no nearer the boundary than %v before", x, d, prev)
+		}
+		prev = d
+	}
+}
+
+func TestInteriorT(t *testing.T) {
+	for _, tc := range []struct{ dist, scale, want float64 }{
+		{0, 10, 1},
+		{0.1, 10, math.Exp(-1)},
+		{0.5, 2, math.Exp(-1)},
+		{math.Inf(1), 10, 0},
+	} {
+		if got := interiorT(tc.dist, tc.scale); math.Abs(got-tc.want) > 1e-15 {
+			t.Errorf("interiorT(%v, %v) = %v, want %v", tc.dist, tc.scale, got, tc.want)
+		}
+	}
+}
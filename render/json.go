@@ -0,0 +1,272 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/fractal"
+	"github.com/whalelogic/mandlebrot/palette"
+)
+
+// jsonOptions is the JSON form of Options:
+//
+//	{"width": 800, "height": 600,
+//	 "bounds": {"xmin": -2.2, "xmax": 1, "ymin": -1.2, "ymax": 1.2},
+//	 "maxIter": 1200, "palette": "NebulaSpectre", "fractal": "mandelbrot",
+//	 "bailout": 2, "coloring": "smooth", "bands": 16, "blendSmooth": 0.7}
+//
+// The palette and fractal are given by name, complex numbers as [re, im]
+// pairs. A fractal.Expr is "fractal": "formula" with its text in
+// "formula". Only what decides the picture is included: the callbacks,
+// metrics and buffer settings are not, and neither is the worker count.
+type jsonOptions struct {
+	Width         int          `json:"width"`
+	Height        int          `json:"height"`
+	Bounds        jsonBounds   `json:"bounds"`
+	Rotation      float64      `json:"rotation,omitempty"`
+	FlipY         bool         `json:"flipY,omitempty"`
+	HighPrecision bool         `json:"highPrecision,omitempty"`
+	MaxIter       int          `json:"maxIter"`
+	Palette       string       `json:"palette"`
+	Fractal       string       `json:"fractal"`
+	Julia         *[2]float64  `json:"julia,omitempty"`      // for "julia"
+	Z0            *[2]float64  `json:"z0,omitempty"`         // for "mandelbrot"
+	Power         int          `json:"power,omitempty"`      // for "multibrot"
+	QSlice        *[2]float64  `json:"qslice,omitempty"`     // for "quaternion"
+	SuperPower    float64      `json:"superPower,omitempty"` // for "super"
+	Formula       string       `json:"formula,omitempty"`
+	Mobius        [][2]float64 `json:"mobius,omitempty"`
+	Bailout       float64      `json:"bailout"`
+	Coloring      Coloring     `json:"coloring"`
+	Bands         int          `json:"bands"`
+	BlendSmooth   float64      `json:"blendSmooth"`
+	ZmagSmooth    float64      `json:"zmagSmooth,omitempty"`
+	TrapPoints    [][2]float64 `json:"trapPoints,omitempty"`
+	TrapScale     float64      `json:"trapScale,omitempty"`
+	InteriorScale float64      `json:"interiorScale,omitempty"`
+	PalettePhase  float64      `json:"palettePhase,omitempty"`
+	AutoContrast  bool         `json:"autoContrast,omitempty"`
+	Exposure      float64      `json:"exposure,omitempty"`
+	Brightness    float64      `json:"brightness,omitempty"`
+	Contrast      float64      `json:"contrast,omitempty"`
+	Saturation    float64      `json:"saturation,omitempty"`
+	Bloom         *jsonBloom   `json:"bloom,omitempty"`
+	OutputHSL     bool         `json:"outputHSL,omitempty"`
+	UseNRGBA      bool         `json:"useNRGBA,omitempty"`
+	Sparse        bool         `json:"sparse,omitempty"`
+}
+
+type jsonBloom struct {
+	Strength  float64 `json:"strength"`
+	Radius    float64 `json:"radius"`
+	Threshold float64 `json:"threshold"`
+}
+
+type jsonBounds struct {
+	Xmin float64 `json:"xmin"`
+	Xmax float64 `json:"xmax"`
+	Ymin float64 `json:"ymin"`
+	Ymax float64 `json:"ymax"`
+}
+
+func pair(c complex128) [2]float64 { return [2]float64{real(c), imag(c)} }
+
+// MarshalJSON encodes the settings of o that decide the picture in the
+// form read by UnmarshalJSON. Floats are written in their shortest exact
+// form, so they decode to the same bits. A palette without a keyword or
+// a formula other than the built-ins and fractal.Expr can't be named and
+// is an error.
+func (o Options) MarshalJSON() ([]byte, error) {
+	o = o.withDefaults()
+	name := fractal.NameOf(o.Fractal)
+	if _, ok := o.Fractal.(fractal.Expr); ok {
+		name = "formula"
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: a custom formula can't be written as JSON", ErrInvalidOptions)
+	}
+	if o.Palette == nil || o.Palette.Keyword == "" {
+		return nil, fmt.Errorf("%w: a palette without a name can't be written as JSON", ErrInvalidOptions)
+	}
+	b := o.Bounds
+	out := jsonOptions{
+		Width:         o.Width,
+		Height:        o.Height,
+		Bounds:        jsonBounds{b.Xmin, b.Xmax, b.Ymin, b.Ymax},
+		Rotation:      o.Rotation,
+		FlipY:         o.FlipY,
+		HighPrecision: o.HighPrecisionCoords,
+		MaxIter:       o.MaxIter,
+		Palette:       o.Palette.Keyword,
+		Fractal:       name,
+		Bailout:       o.Bailout,
+		Coloring:      o.Coloring,
+		Bands:         o.Bands,
+		BlendSmooth:   o.BlendSmooth,
+		ZmagSmooth:    o.ZmagSmooth,
+		TrapScale:     o.TrapScale,
+		InteriorScale: o.InteriorScale,
+		PalettePhase:  o.PalettePhase,
+		AutoContrast:  o.AutoContrast,
+		Exposure:      o.Adjust.Exposure,
+		Brightness:    o.Adjust.Brightness,
+		Contrast:      o.Adjust.Contrast,
+		Saturation:    o.Adjust.Saturation,
+		OutputHSL:     o.OutputHSL,
+		UseNRGBA:      o.UseNRGBA,
+		Sparse:        o.Sparse,
+	}
+	if out.Coloring == "" {
+		out.Coloring = DefaultColoring
+	}
+	switch f := o.Fractal.(type) {
+	case fractal.Julia:
+		k := pair(f.K)
+		out.Julia = &k
+	case fractal.Mandelbrot:
+		if f.Z0 != 0 {
+			z0 := pair(f.Z0)
+			out.Z0 = &z0
+		}
+	case fractal.Multibrot:
+		out.Power = f.Power
+	case fractal.Super:
+		out.SuperPower = f.Power
+	case fractal.Quaternion:
+		if f != (fractal.Quaternion{}) {
+			out.QSlice = &[2]float64{f.SliceZ, f.SliceW}
+		}
+	case fractal.Expr:
+		out.Formula = f.Source()
+	}
+	if o.Coloring != ColoringFixedTrap {
+		out.TrapScale = 0
+	}
+	if o.Coloring != ColoringInteriorDistance {
+		out.InteriorScale = 0
+	}
+	if b := o.Bloom; b.Strength != 0 {
+		out.Bloom = &jsonBloom{b.Strength, b.Radius, b.Threshold}
+		if b.Radius == 0 {
+			out.Bloom.Radius = DefaultBloomRadius
+		}
+	}
+	for _, p := range o.TrapPoints {
+		out.TrapPoints = append(out.TrapPoints, pair(p))
+	}
+	if t := o.Transform; !t.IsZero() {
+		out.Mobius = [][2]float64{pair(t.A), pair(t.B), pair(t.C), pair(t.D)}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes options written by MarshalJSON and validates
+// them. Fields left out take the defaults New starts from. The palette
+// is looked up by keyword, so it must be built in or registered.
+func (o *Options) UnmarshalJSON(data []byte) error {
+	d := DefaultBounds
+	in := jsonOptions{
+		Width:       DefaultWidth,
+		Height:      DefaultHeight,
+		Bounds:      jsonBounds{d.Xmin, d.Xmax, d.Ymin, d.Ymax},
+		MaxIter:     DefaultMaxIter,
+		Palette:     DefaultPalette,
+		Fractal:     "mandelbrot",
+		Bailout:     DefaultBailout,
+		Coloring:    DefaultColoring,
+		Bands:       DefaultBands,
+		BlendSmooth: DefaultBlendSmooth,
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	out := Options{
+		Width:               in.Width,
+		Height:              in.Height,
+		Bounds:              coords.Bounds{Xmin: in.Bounds.Xmin, Xmax: in.Bounds.Xmax, Ymin: in.Bounds.Ymin, Ymax: in.Bounds.Ymax},
+		Rotation:            in.Rotation,
+		FlipY:               in.FlipY,
+		HighPrecisionCoords: in.HighPrecision,
+		MaxIter:             in.MaxIter,
+		Palette:             palette.Get(in.Palette),
+		Bailout:             in.Bailout,
+		Coloring:            in.Coloring,
+		Bands:               in.Bands,
+		BlendSmooth:         in.BlendSmooth,
+		ZmagSmooth:          in.ZmagSmooth,
+		TrapScale:           in.TrapScale,
+		InteriorScale:       in.InteriorScale,
+		PalettePhase:        in.PalettePhase,
+		AutoContrast:        in.AutoContrast,
+		Adjust:              Adjust{in.Exposure, in.Brightness, in.Contrast, in.Saturation},
+		OutputHSL:           in.OutputHSL,
+		UseNRGBA:            in.UseNRGBA,
+		Sparse:              in.Sparse,
+	}
+	if out.Palette == nil {
+		return fmt.Errorf("%w: palette %q: not found", ErrInvalidOptions, in.Palette)
+	}
+	var k complex128
+	if in.Julia != nil {
+		if in.Fractal != "julia" {
+			return fmt.Errorf("%w: julia parameter given for fractal %q", ErrInvalidOptions, in.Fractal)
+		}
+		k = complex(in.Julia[0], in.Julia[1])
+	}
+	if in.Formula != "" && in.Fractal != "formula" {
+		return fmt.Errorf("%w: formula given for fractal %q", ErrInvalidOptions, in.Fractal)
+	}
+	if in.Fractal == "formula" {
+		f, err := fractal.ParseExpr(in.Formula)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+		}
+		out.Fractal = f
+	} else if out.Fractal = fractal.ByName(in.Fractal, k); out.Fractal == nil {
+		return fmt.Errorf("%w: fractal %q: not one of %s", ErrInvalidOptions, in.Fractal, strings.Join(fractal.Names, ", "))
+	}
+	if in.Z0 != nil {
+		if in.Fractal != "mandelbrot" {
+			return fmt.Errorf("%w: z0 given for fractal %q", ErrInvalidOptions, in.Fractal)
+		}
+		out.Fractal = fractal.Mandelbrot{Z0: complex(in.Z0[0], in.Z0[1])}
+	}
+	if in.Power != 0 {
+		if in.Fractal != "multibrot" {
+			return fmt.Errorf("%w: power given for fractal %q", ErrInvalidOptions, in.Fractal)
+		}
+		out.Fractal = fractal.Multibrot{Power: in.Power}
+	}
+	if in.QSlice != nil {
+		if in.Fractal != "quaternion" {
+			return fmt.Errorf("%w: qslice given for fractal %q", ErrInvalidOptions, in.Fractal)
+		}
+		out.Fractal = fractal.Quaternion{SliceZ: in.QSlice[0], SliceW: in.QSlice[1]}
+	}
+	if in.SuperPower != 0 {
+		if in.Fractal != "super" {
+			return fmt.Errorf("%w: superPower given for fractal %q", ErrInvalidOptions, in.Fractal)
+		}
+		out.Fractal = fractal.Super{Power: in.SuperPower}
+	}
+	if in.Bloom != nil {
+		out.Bloom = Bloom{in.Bloom.Strength, in.Bloom.Radius, in.Bloom.Threshold}
+	}
+	for _, p := range in.TrapPoints {
+		out.TrapPoints = append(out.TrapPoints, complex(p[0], p[1]))
+	}
+	if in.Mobius != nil {
+		if len(in.Mobius) != 4 {
+			return fmt.Errorf("%w: mobius: want the 4 coefficients a, b, c, d, got %d", ErrInvalidOptions, len(in.Mobius))
+		}
+		m := in.Mobius
+		out.Transform = Mobius{complex(m[0][0], m[0][1]), complex(m[1][0], m[1][1]), complex(m[2][0], m[2][1]), complex(m[3][0], m[3][1])}
+	}
+	if err := out.Validate(); err != nil {
+		return err
+	}
+	*o = out
+	return nil
+}
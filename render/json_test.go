@@ -0,0 +1,153 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/fractal"
+	"github.com/whalelogic/mandlebrot/palette"
+)
+
+// sameOptions reports the first setting that differs between a and b, of
+// those the JSON form carries, or "".
+func sameOptions(a, b Options) string {
+	switch {
+	case a.Width != b.Width || a.Height != b.Height:
+		return "size"
+	case a.Bounds != b.Bounds:
+		return "bounds"
+	case a.Rotation != b.Rotation || a.FlipY != b.FlipY:
+		return "orientation"
+	case a.MaxIter != b.MaxIter:
+		return "iterations"
+	case a.Palette.Keyword != b.Palette.Keyword:
+		return "palette"
+	case a.Fractal != b.Fractal:
+		return "fractal"
+	case a.Bailout != b.Bailout:
+		return "bailout"
+	case a.Coloring != b.Coloring || a.Bands != b.Bands || a.BlendSmooth != b.BlendSmooth || a.PalettePhase != b.PalettePhase:
+		return "coloring"
+	case a.Adjust != b.Adjust:
+		return "adjustments"
+	case a.Bloom != b.Bloom:
+		return "bloom"
+	}
+	return ""
+}
+
+func TestOptionsJSONRoundTrip(t *testing.T) {
+	// values that are not short decimals, to check floats keep their bits
+	b := coords.Bounds{Xmin: -0.1 - 0.2, Xmax: 1.0 / 3, Ymin: -2.0 / 7, Ymax: 0.7 + 0.1}
+	for _, p := range palette.ColorPalettes {
+		for _, f := range []fractal.Fractal{
+			fractal.Mandelbrot{},
+			fractal.Mandelbrot{Z0: complex(0.1+0.2, -1.0/3)},
+			fractal.Julia{K: complex(-0.8, 0.156)},
+			fractal.ByName("burningship", 0),
+		} {
+			o := smallOptions(t,
+				WithSize(97, 61), WithViewport(b), WithRotation(12.345678901234567), WithIterations(1234),
+				WithPaletteName(p.Keyword), WithFractal(f), WithBailout(1e3/7),
+				WithColoring(ColoringBlend), WithBands(13), WithBlendSmooth(0.1+0.2), WithPalettePhase(1.0/9),
+				WithAdjust(Adjust{Exposure: 0.3, Brightness: -0.05, Contrast: 1.1, Saturation: 0.9}),
+				WithBloom(Bloom{Strength: 0.6, Radius: 7.5, Threshold: 0.8}),
+			)
+			data, err := json.Marshal(o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var back Options
+			if err := json.Unmarshal(data, &back); err != nil {
+				t.Fatalf("%s: %v", data, err)
+			}
+			if d := sameOptions(o, back); d != "" {
+				t.Errorf("%s: the %s changed", data, d)
+			}
+			again, err := json.Marshal(back)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(data) {
+				t.Errorf("wrote %s, then %s", data, again)
+			}
+		}
+	}
+}
+
+func TestOptionsJSONForm(t *testing.T) {
+	data, err := json.Marshal(smallOptions(t, WithPaletteName("ThermalHeat"), WithFractalName("julia", -0.8+0.156i)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["palette"] != "ThermalHeat" || m["fractal"] != "julia" {
+		t.Errorf("palette %v, fractal %v", m["palette"], m["fractal"])
+	}
+	bounds, _ := m["bounds"].(map[string]any)
+	d := DefaultBounds
+	if bounds["xmin"] != d.Xmin || bounds["xmax"] != d.Xmax || bounds["ymin"] != d.Ymin || bounds["ymax"] != d.Ymax {
+		t.Errorf("bounds %v", m["bounds"])
+	}
+	if j, _ := m["julia"].([]any); len(j) != 2 || j[0] != -0.8 || j[1] != 0.156 {
+		t.Errorf("julia %v", m["julia"])
+	}
+
+	// a formula goes by its text
+	o := smallOptions(t, WithFormula("z^3 + c"))
+	if data, err = json.Marshal(o); err != nil {
+		t.Fatal(err)
+	}
+	var back Options
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if f, ok := back.Fractal.(fractal.Expr); !ok || f.Source() != o.Fractal.(fractal.Expr).Source() {
+		t.Errorf("%s read back as %v", data, back.Fractal)
+	}
+
+	// left out fields take the defaults
+	if err := json.Unmarshal([]byte(`{"width": 10, "height": 10}`), &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Bounds != DefaultBounds || back.MaxIter != DefaultMaxIter || back.Palette.Keyword != DefaultPalette {
+		t.Errorf("defaults: %+v", back)
+	}
+}
+
+func TestOptionsJSONErrors(t *testing.T) {
+	for _, src := range []string{
+		`{"width": 0}`,
+		`{"maxIter": -1}`,
+		`{"palette": "NoSuchPalette"}`,
+		`{"fractal": "nosuchfractal"}`,
+		`{"fractal": "mandelbrot", "julia": [0, 1]}`,
+		`{"fractal": "julia", "z0": [0, 1]}`,
+		`{"formula": "z^2 + c"}`,
+		`{"fractal": "formula", "formula": "z^"}`,
+		`{"mobius": [[1, 0]]}`,
+	} {
+		var o Options
+		if err := json.Unmarshal([]byte(src), &o); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("%s: %v", src, err)
+		}
+	}
+	var o Options
+	if err := json.Unmarshal([]byte(`{"bounds": {"xmin": 1, "xmax": 1, "ymin": 0, "ymax": 1}}`), &o); !errors.Is(err, ErrInvalidViewport) {
+		t.Errorf("degenerate bounds: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"width": "wide"}`), &o); err == nil {
+		t.Error("a string width was accepted")
+	}
+
+	unnamed := smallOptions(t, WithPalette(&palette.ColorMap{Colors: palette.Get(DefaultPalette).Colors}))
+	if _, err := json.Marshal(unnamed); err == nil || !strings.Contains(err.Error(), "palette") {
+		t.Errorf("a palette without a name: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package render
+
+import "github.com/whalelogic/mandlebrot/fractal"
+
+// iterResult records which branch of iterate decided a point, for
+// ColoringDebug.
+type iterResult uint8
+
+const (
+	resultEscaped  iterResult = iota // the orbit left the escape radius
+	resultInterior                   // the orbit stayed bounded for maxIter steps
+	resultCardioid                   // inside the main cardioid, not iterated
+	resultBulb                       // inside the period-2 bulb, not iterated
+	resultPeriodic                   // the orbit was caught repeating itself
+)
+
+// Smooth coloring measures how far past the escape radius an orbit got,
+// which is only accurate once |z| is well past it: an orbit that escaped
+// is carried on until |z| passes smoothRadius, or for at most
+// maxSmoothSteps more steps, before it is measured.
+const (
+	smoothRadiusSq = 1e6 // smoothRadius 1000
+	maxSmoothSteps = 16
+)
+
+// orbit is what iterate found out about one sample point.
+type orbit struct {
+	iter int        // escape iteration, maxIter for interior points
+	z    complex128 // first iterate outside the escape radius, or the last one
+	how  iterResult
+
+	// far is the orbit carried on past z as described at smoothRadiusSq,
+	// and farIter its escape iteration counted the same way as iter. For
+	// interior points they are z and iter.
+	far     complex128
+	farIter int
+}
+
+// iterate dispatches to the iteration kernel for f. The built-in formulas
+// get their own instantiation of iterateFormula (and Mandelbrot its
+// hand-written loop) so the per-step calls stay inlined; anything else
+// goes through the interface.
+//
+// Mandelbrot points in the main cardioid or the period-2 bulb are known
+// to be interior and skip iteration altogether, as do orbits that settle
+// into a cycle. Those interior points come back with z = 0 rather than
+// their last orbit value. The shortcuts need an escape radius of at least
+// 2, which every orbit in those components stays within. A Mandelbrot
+// formula with a nonzero Z0 has components of its own and is iterated as
+// any other formula.
+func iterate(f fractal.Fractal, c complex128, maxIter int, bailoutSq float64) orbit {
+	switch f := f.(type) {
+	case fractal.Mandelbrot:
+		if f.Z0 != 0 {
+			return iterateFormula(f, c, maxIter, bailoutSq)
+		}
+		if bailoutSq >= 4 {
+			if inCardioid(c) {
+				return orbit{iter: maxIter, how: resultCardioid, farIter: maxIter}
+			}
+			if inBulb(c) {
+				return orbit{iter: maxIter, how: resultBulb, farIter: maxIter}
+			}
+		}
+		n, z, how := mandelbrotPeriodic(c, maxIter, bailoutSq)
+		return mandelbrotOrbit(c, n, z, how)
+	case fractal.Julia:
+		return iterateFormula(f, c, maxIter, bailoutSq)
+	case fractal.BurningShip:
+		return iterateFormula(f, c, maxIter, bailoutSq)
+	case fractal.Multibrot:
+		return iterateFormula(f, c, maxIter, bailoutSq)
+	case fractal.Quaternion:
+		return iterateFormula(f, c, maxIter, bailoutSq)
+	case fractal.Lemniscate:
+		return iterateFormula(f, c, maxIter, bailoutSq)
+	case fractal.Super:
+		return iterateFormula(f, c, maxIter, bailoutSq)
+	case fractal.Func:
+		return iterateFormula(f, c, maxIter, bailoutSq)
+	case fractal.Expr:
+		return iterateFormula(f, c, maxIter, bailoutSq)
+	default:
+		return iterateFormula(f, c, maxIter, bailoutSq)
+	}
+}
+
+// mandelbrotOrbit makes the orbit for a result of mandelbrotPeriodic at
+// c, carrying an escaped orbit on for smooth coloring.
+func mandelbrotOrbit(c complex128, n int, z complex128, how iterResult) orbit {
+	o := orbit{iter: n, z: z, how: how, far: z, farIter: n}
+	if how == resultEscaped {
+		for k := 0; k < maxSmoothSteps && real(o.far)*real(o.far)+imag(o.far)*imag(o.far) <= smoothRadiusSq; k++ {
+			o.far = o.far*o.far + c
+			o.farIter++
+		}
+	}
+	return o
+}
+
+// iterateFormula is iterate for any formula: fractal.Iterate, then the
+// steps past the escape radius for smooth coloring.
+func iterateFormula[F fractal.Fractal](f F, c complex128, maxIter int, bailoutSq float64) orbit {
+	n, s := fractal.Iterate(f, c, maxIter, bailoutSq)
+	o := orbit{iter: n, z: s.Z, how: resultEscaped, farIter: n}
+	if n >= maxIter {
+		o.how = resultInterior
+	} else {
+		for k := 0; k < maxSmoothSteps && real(s.Z)*real(s.Z)+imag(s.Z)*imag(s.Z) <= smoothRadiusSq; k++ {
+			f.Step(&s)
+			o.farIter++
+		}
+	}
+	o.far = s.Z
+	return o
+}
+
+// inCardioid reports whether c lies in the main cardioid of the
+// Mandelbrot set.
+func inCardioid(c complex128) bool {
+	x, y := real(c)-0.25, imag(c)
+	q := x*x + y*y
+	return q*(q+x) <= 0.25*y*y
+}
+
+// inBulb reports whether c lies in the period-2 disk centered on -1.
+func inBulb(c complex128) bool {
+	x, y := real(c)+1, imag(c)
+	return x*x+y*y <= 1.0/16
+}
+
+// periodTolSq is how close, squared, an orbit must come back to a
+// checkpoint to count as cycling. Orbits drawn into an attracting cycle
+// close in far below it; one that is still going to escape can't
+// return that close.
+const periodTolSq = 1e-30
+
+// mandelbrotPeriodic is the mandelbrotIterations loop with Brent-style
+// cycle detection: the orbit is compared against a checkpoint that is
+// moved to the current point at every power of two, and a match ends the
+// iteration as interior.
+func mandelbrotPeriodic(c complex128, maxIter int, bailoutSq float64) (int, complex128, iterResult) {
+	var z, saved complex128
+	next := 8
+	for n := range maxIter {
+		z = z*z + c
+		if safeEscape(z, bailoutSq) {
+			return n, z, resultEscaped
+		}
+		if d := z - saved; real(d)*real(d)+imag(d)*imag(d) < periodTolSq {
+			return maxIter, 0, resultPeriodic
+		}
+		if n == next {
+			saved, next = z, 2*next
+		}
+	}
+	return maxIter, z, resultInterior
+}
+
+// safeEscape reports whether z has escaped: |z|² > bailoutSq, or z is no
+// longer a number. A |z|² too large for a float64 is +Inf, which passes
+// the comparison anyway, but one with a NaN part fails every comparison
+// and would leave the orbit iterating to maxIter as if it were inside.
+// Testing for "not within" instead of "beyond" catches both in the one
+// comparison.
+func safeEscape(z complex128, bailoutSq float64) bool {
+	return !(real(z)*real(z)+imag(z)*imag(z) <= bailoutSq)
+}
+
+// mandelbrotIterations is the hand-written z = z^2 + c kernel, escaping
+// once |z|² > bailoutSq. It returns the escape iteration, the final z and,
+// when trackDeriv is set, the
+// derivative dz/dc (dz = 2*z*dz + 1) needed for distance estimation.
+// Without trackDeriv the derivative is skipped entirely and returned as 0.
+//
+// The escape iteration is 0-based: n means z_(n+1) was the first iterate
+// outside the radius, so c itself outside it gives 0. The test is strict,
+// which keeps c = -2 (orbit -2, 2, 2, ...) inside at the default radius.
+// An escaped z has |z|² > bailoutSq or a NaN part (see safeEscape); a
+// result of maxIter means it never did.
+func mandelbrotIterations(c complex128, maxIter int, bailoutSq float64, trackDeriv bool) (int, complex128, complex128) {
+	var z, dz complex128
+	if trackDeriv {
+		for n := range maxIter {
+			dz = 2*z*dz + 1
+			z = z*z + c
+			if safeEscape(z, bailoutSq) {
+				return n, z, dz
+			}
+		}
+		return maxIter, z, dz
+	}
+	for n := range maxIter {
+		z = z*z + c
+		if safeEscape(z, bailoutSq) {
+			return n, z, 0
+		}
+	}
+	return maxIter, z, 0
+}
@@ -0,0 +1,53 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/cmath"
+)
+
+func FuzzRender(f *testing.F) {
+	for _, seed := range []struct {
+		re, im  float64
+		maxIter uint16
+	}{
+		{0, 0, 100},          // interior: the center of the cardioid
+		{-1, 0, 100},         // interior: the center of the period-2 bulb
+		{-2, 0, 100},         // boundary: the tip of the antenna, bounded at 2
+		{0.25, 0, 1000},      // boundary: the cusp of the cardioid
+		{0.26, 0, 1000},      // exterior, just past the cusp
+		{2, 2, 10},           // exterior from the start
+		{-0.75, 0.1, 10000},  // exterior, slowly, through the seahorse valley
+		{1e308, -1e308, 5},   // overflows to infinity
+		{5e-324, -5e-324, 5}, // denormal
+		{math.NaN(), 0, 5},
+		{math.Inf(-1), math.Inf(1), 5},
+	} {
+		f.Add(seed.re, seed.im, seed.maxIter)
+	}
+
+	f.Fuzz(func(t *testing.T, re, im float64, maxIter uint16) {
+		n := 1 + int(maxIter)%10000
+		c := complex(re, im)
+		bailoutSq := DefaultBailout * DefaultBailout
+		iter, z, _ := mandelbrotIterations(c, n, bailoutSq, false)
+		if iter < 0 || iter > n {
+			t.Fatalf("c = %v: iter %d outside [0, %d]", c, iter, n)
+		}
+		// an escape is past the radius, or NaN, which safeEscape lets out
+		if abs := cmath.Abs(z); iter < n && abs <= DefaultBailout-1e-9 {
+			t.Fatalf("c = %v: escaped at %d with |z| = %v", c, iter, abs)
+		}
+
+		// the smooth coloring of the orbit is a palette position, never NaN
+		how := resultEscaped
+		if iter >= n {
+			how = resultInterior
+		}
+		o := mandelbrotOrbit(c, iter, z, how)
+		if v := smoothT(o, n, newSmoothing(2, DefaultBailout)); !(v >= 0 && v <= 1) {
+			t.Fatalf("c = %v: smoothT = %v", c, v)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package render
+
+import (
+	"cmp"
+	"math"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/cmath"
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+func TestMandelbrotIterationsKnownValues(t *testing.T) {
+	const maxIter = 1000
+	bailoutSq := DefaultBailout * DefaultBailout
+	for _, tc := range []struct {
+		name string
+		c    complex128
+		want int // escape iteration, maxIter for a bounded orbit; -1 for any escape
+	}{
+		// z_1 = c is past the radius already
+		{"outside the radius", 3, 0},
+		// 2, 6: the orbit lands on the radius, which the strict test keeps in
+		{"on the radius first", 2, 1},
+		// 1, 2, 5
+		{"real 1", 1, 2},
+		// -2, 2, 2, ...: the antenna tip stays on the radius forever
+		{"antenna tip", -2, maxIter},
+		{"cardioid center", 0, maxIter},
+		{"period-2 bulb center", -1, maxIter},
+		{"inside the period-2 bulb", -1.25, maxIter},
+		// the cardioid only reaches 0.25 along the real axis
+		{"0.5, past the cusp", 0.5, 4},
+		{"just past the cusp", 0.26, 29},
+		{"upper bulb", -0.12 + 0.75i, maxIter},
+		{"off the top", 0.5i + 1, -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, deriv := range []bool{false, true} {
+				n, z, _ := mandelbrotIterations(tc.c, maxIter, bailoutSq, deriv)
+				switch {
+				case tc.want >= 0 && n != tc.want:
+					t.Errorf("trackDeriv %v: escaped at %d, want %d", deriv, n, tc.want)
+				case tc.want < 0 && n >= maxIter:
+					t.Errorf("trackDeriv %v: never escaped", deriv)
+				}
+				// |z| > 2 exactly for the orbits that escaped
+				if escaped := cmath.AbsSq(z) > bailoutSq; escaped != (n < maxIter) {
+					t.Errorf("trackDeriv %v: n = %d but |z|² = %v", deriv, n, cmath.AbsSq(z))
+				}
+			}
+		})
+	}
+}
+
+func TestSafeEscape(t *testing.T) {
+	const bailoutSq = 4
+	nan, inf := math.NaN(), math.Inf(1)
+	for _, tc := range []struct {
+		z    complex128
+		want bool
+	}{
+		{0, false},
+		{2, false}, // on the radius is still inside
+		{complex(1.5, 1.5), true},
+		{complex(1e200, 0), true}, // |z|² overflows to +Inf
+		{complex(-2e154, 2e154), true},
+		{complex(inf, 0), true},
+		{complex(0, -inf), true},
+		{complex(nan, 0), true},
+		{complex(0, nan), true},
+		{complex(inf, inf), true}, // Inf-Inf would be NaN; the sum of squares isn't
+	} {
+		if got := safeEscape(tc.z, bailoutSq); got != tc.want {
+			t.Errorf("safeEscape(%v) = %v, want %v", tc.z, got, tc.want)
+		}
+	}
+}
+
+func TestMandelbrotIterationsHuge(t *testing.T) {
+	// Each of these is outside the radius as c itself, so every kernel
+	// must escape at once and hand back z_1 = c, not iterate to maxIter
+	// on an Inf or NaN orbit.
+	const maxIter = 100
+	bailoutSq := DefaultBailout * DefaultBailout
+	nan, inf := math.NaN(), math.Inf(1)
+	// same reports a == b, counting NaN parts as equal to each other.
+	same := func(a, b complex128) bool {
+		return cmp.Compare(real(a), real(b)) == 0 && cmp.Compare(imag(a), imag(b)) == 0
+	}
+	for _, c := range []complex128{
+		complex(1e200, 0), complex(1e200, 1e200), complex(-1e200, 0),
+		complex(2e154, 2e154), complex(inf, 0), complex(nan, 0), complex(0, nan),
+	} {
+		for _, deriv := range []bool{false, true} {
+			n, z, _ := mandelbrotIterations(c, maxIter, bailoutSq, deriv)
+			if n != 0 || !same(z, c) {
+				t.Errorf("mandelbrotIterations(%v, trackDeriv %v) = %d, %v, want 0, %v", c, deriv, n, z, c)
+			}
+		}
+		n, z, how := mandelbrotPeriodic(c, maxIter, bailoutSq)
+		if n != 0 || !same(z, c) || how != resultEscaped {
+			t.Errorf("mandelbrotPeriodic(%v) = %d, %v, %v, want 0, %v, escaped", c, n, z, how, c)
+		}
+	}
+}
+
+func TestIterateFormulaHuge(t *testing.T) {
+	// The generic path through fractal.Iterate: a NaN orbit there must
+	// escape as well, not come back as interior.
+	bailoutSq := DefaultBailout * DefaultBailout
+	for _, f := range []fractal.Fractal{
+		fractal.Julia{}, fractal.Julia{K: -0.8 + 0.156i}, fractal.Mandelbrot{Z0: 0.1},
+		fractal.Multibrot{Power: 3}, fractal.BurningShip{}, fractal.Lemniscate{},
+	} {
+		for _, c := range []complex128{complex(1e200, 1e200), complex(math.NaN(), 0)} {
+			if o := iterate(f, c, 100, bailoutSq); o.how != resultEscaped || o.iter >= 100 {
+				t.Errorf("%T at %v: %+v, want escaped", f, c, o)
+			}
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+// Version identifies the build in the Software metadata. Release builds
+// set it with -ldflags "-X github.com/whalelogic/mandlebrot/render.Version=...".
+var Version = "dev"
+
+// Metadata keys written by Metadata.
+const (
+	MetaSoftware         = "Software"
+	MetaReproduceCommand = "Reproduce-Command"
+)
+
+// TextChunk is a PNG tEXt entry.
+type TextChunk struct {
+	Key, Value string
+}
+
+// Metadata returns the text entries recorded with a render of opts.
+func Metadata(opts Options) []TextChunk {
+	return []TextChunk{
+		{MetaSoftware, "mandlebrot " + Version},
+		{MetaReproduceCommand, BuildReproduceCommand(opts)},
+	}
+}
+
+// BuildReproduceCommand returns the command line that renders opts again.
+// Floats are written in their shortest exact form so the command
+// reproduces the view bit for bit. A palette or formula that can't be
+// named on the command line is left at the CLI default.
+func BuildReproduceCommand(opts Options) string {
+	opts = opts.withDefaults()
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	cf := func(c complex128) string { return strings.Trim(strconv.FormatComplex(c, 'g', -1, 128), "()") }
+	args := []string{"./mandelbrot",
+		"-width", strconv.Itoa(opts.Width),
+		"-height", strconv.Itoa(opts.Height),
+		"-xmin", f(opts.Bounds.Xmin),
+		"-xmax", f(opts.Bounds.Xmax),
+		"-ymin", f(opts.Bounds.Ymin),
+		"-ymax", f(opts.Bounds.Ymax),
+	}
+	if opts.Rotation != 0 {
+		args = append(args, "-rotate", f(opts.Rotation))
+	}
+	if opts.FlipY {
+		args = append(args, "-flipy")
+	}
+	if opts.HighPrecisionCoords {
+		args = append(args, "-high-precision")
+	}
+	args = append(args, "-iters", strconv.Itoa(opts.MaxIter), "-bailout", f(opts.Bailout))
+	if opts.Palette != nil && opts.Palette.Keyword != "" {
+		args = append(args, "-palette", opts.Palette.Keyword)
+	}
+	if m, ok := opts.Fractal.(fractal.Mandelbrot); ok && m.Z0 != 0 {
+		args = append(args, "-z0-re", f(real(m.Z0)), "-z0-im", f(imag(m.Z0)))
+	}
+	if x, ok := opts.Fractal.(fractal.Expr); ok {
+		args = append(args, "-formula", strconv.Quote(x.Source()))
+	}
+	if name := fractal.NameOf(opts.Fractal); name != "" && name != "mandelbrot" {
+		args = append(args, "-fractal", name)
+		if j, ok := opts.Fractal.(fractal.Julia); ok {
+			args = append(args, "-julia-re", f(real(j.K)), "-julia-im", f(imag(j.K)))
+		}
+		if m, ok := opts.Fractal.(fractal.Multibrot); ok {
+			args = append(args, "-power", strconv.Itoa(m.Power))
+		}
+		if p, ok := opts.Fractal.(fractal.Super); ok {
+			args = append(args, "-super-power", f(p.Power))
+		}
+		if q, ok := opts.Fractal.(fractal.Quaternion); ok && q != (fractal.Quaternion{}) {
+			args = append(args, "-qslice-z", f(q.SliceZ), "-qslice-w", f(q.SliceW))
+		}
+	}
+	switch t := opts.Transform; {
+	case t == Inversion:
+		args = append(args, "-transform", "inverse")
+	case !t.IsZero():
+		args = append(args, "-transform", "mobius", "-mobius", strings.Join([]string{cf(t.A), cf(t.B), cf(t.C), cf(t.D)}, ","))
+	}
+	coloring := opts.Coloring
+	if coloring == "" {
+		coloring = DefaultColoring
+	}
+	args = append(args, "-coloring", string(coloring))
+	switch coloring {
+	case ColoringBands:
+		args = append(args, "-bands", strconv.Itoa(opts.Bands))
+	case ColoringBlend:
+		args = append(args, "-bands", strconv.Itoa(opts.Bands), "-blend-smooth", f(opts.BlendSmooth))
+	case ColoringZmagCos:
+		args = append(args, "-zmag-smooth", f(opts.ZmagSmooth))
+	case ColoringFixedTrap:
+		traps := make([]string, len(opts.TrapPoints))
+		for i, p := range opts.TrapPoints {
+			traps[i] = cf(p)
+		}
+		args = append(args, "-trap-points", strings.Join(traps, ","), "-trap-scale", f(opts.TrapScale))
+	case ColoringInteriorDistance:
+		args = append(args, "-interior-scale", f(opts.InteriorScale))
+	}
+	if opts.PalettePhase != 0 {
+		args = append(args, "-palette-phase", f(opts.PalettePhase))
+	}
+	if opts.AutoContrast {
+		args = append(args, "-auto-contrast")
+	}
+	if a := opts.Adjust; a != (Adjust{}) {
+		for _, p := range []struct {
+			flag string
+			v    float64
+		}{{"-exposure", a.Exposure}, {"-brightness", a.Brightness}, {"-contrast", a.Contrast}, {"-saturation", a.Saturation}} {
+			if p.v != 0 {
+				args = append(args, p.flag, f(p.v))
+			}
+		}
+	}
+	if b := opts.Bloom; b.Strength != 0 {
+		radius := b.Radius
+		if radius == 0 {
+			radius = DefaultBloomRadius
+		}
+		args = append(args, "-bloom", f(b.Strength), "-bloomradius", f(radius), "-bloomthreshold", f(b.Threshold))
+	}
+	if opts.OutputHSL {
+		args = append(args, "-output-hsl")
+	}
+	if opts.Sparse {
+		args = append(args, "-sparse")
+	}
+	return strings.Join(args, " ")
+}
+
+// EncodeWithText is EncodeWithProfile for sRGB colors.
+func EncodeWithText(w io.Writer, img image.Image, format string, text []TextChunk) error {
+	return EncodeWithProfile(w, img, format, text, ProfileSRGB)
+}
+
+// EncodeWithProfile is Encode with text metadata and color management.
+// PNG output is tagged as profile and then carries the text as tEXt
+// chunks, all right after the header; for ProfileP3 the colors are
+// converted with ToDisplayP3 first. Other formats carry neither and
+// only take ProfileSRGB.
+func EncodeWithProfile(w io.Writer, img image.Image, format string, text []TextChunk, profile ColorProfile) error {
+	if profile != "" && !slices.Contains(ColorProfiles, profile) {
+		return fmt.Errorf("%w: color profile %q: not one of %s", ErrInvalidOptions, profile, joinProfiles())
+	}
+	if format != "png" {
+		if profile != "" && profile != ProfileSRGB {
+			return fmt.Errorf("%w: the %s color profile needs png output, not %s", ErrUnsupportedFormat, profile, format)
+		}
+		return Encode(w, img, format)
+	}
+	if profile == ProfileP3 {
+		img = ToDisplayP3(img)
+	}
+	return encodePNG(&chunkSplicer{w: w, insert: func(w io.Writer) error {
+		if err := writeProfileChunks(w, profile); err != nil {
+			return err
+		}
+		for _, t := range text {
+			if err := writeTextChunk(w, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}, img)
+}
+
+// pngHeaderLen is the length of the 8-byte PNG signature and the 25-byte
+// IHDR chunk that come first in every PNG.
+const pngHeaderLen = 8 + 4 + 4 + 13 + 4
+
+// chunkSplicer passes a PNG being encoded through to w, calling insert to
+// add chunks of its own once the header has gone by. The PNG streams
+// through as it is encoded, however large.
+type chunkSplicer struct {
+	w      io.Writer
+	n      int // bytes of the header written so far
+	insert func(w io.Writer) error
+}
+
+func (s *chunkSplicer) Write(p []byte) (int, error) {
+	written := 0
+	if s.n < pngHeaderLen {
+		k := min(len(p), pngHeaderLen-s.n)
+		m, err := s.w.Write(p[:k])
+		s.n += m
+		written += m
+		if err != nil {
+			return written, err
+		}
+		if s.n == pngHeaderLen {
+			if err := s.insert(s.w); err != nil {
+				return written, err
+			}
+		}
+		p = p[k:]
+	}
+	m, err := s.w.Write(p)
+	return written + m, err
+}
+
+func writeTextChunk(w io.Writer, t TextChunk) error {
+	if len(t.Key) < 1 || len(t.Key) > 79 || strings.ContainsRune(t.Key, 0) {
+		return fmt.Errorf("png tEXt keyword %q: must be 1-79 bytes without NUL", t.Key)
+	}
+	return writeChunk(w, "tEXt", []byte(t.Key), []byte{0}, []byte(t.Value))
+}
+
+// ReadPNGText returns the tEXt entries of the PNG stream r in file order.
+func ReadPNGText(r io.Reader) ([]TextChunk, error) {
+	var sig [8]byte
+	if _, err := io.ReadFull(r, sig[:]); err != nil {
+		return nil, err
+	}
+	if string(sig[:]) != pngSignature {
+		return nil, errors.New("not a PNG file")
+	}
+	var text []TextChunk
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil, err
+		}
+		n, typ := binary.BigEndian.Uint32(hdr[:4]), string(hdr[4:])
+		if typ == "IEND" {
+			return text, nil
+		}
+		if typ != "tEXt" {
+			if _, err := io.CopyN(io.Discard, r, int64(n)+4); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		data := make([]byte, n+4)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		if key, value, ok := bytes.Cut(data[:n], []byte{0}); ok {
+			text = append(text, TextChunk{string(key), string(value)})
+		}
+	}
+}
+
+// ReproduceCommand reads back the command recorded in the PNG at path.
+func ReproduceCommand(pngPath string) (string, error) {
+	f, err := os.Open(pngPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	text, err := ReadPNGText(f)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", pngPath, err)
+	}
+	for _, t := range text {
+		if t.Key == MetaReproduceCommand {
+			return t.Value, nil
+		}
+	}
+	return "", fmt.Errorf("%s: no %s metadata", pngPath, MetaReproduceCommand)
+}
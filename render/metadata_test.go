@@ -0,0 +1,81 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+func TestReproduceCommandRoundTrip(t *testing.T) {
+	for _, o := range []Options{
+		smallOptions(t),
+		smallOptions(t, WithRotation(12.5), WithColoring(ColoringBlend), WithPaletteName("ThermalHeat")),
+		smallOptions(t, WithFractal(fractal.ByName("julia", -0.8+0.156i)), WithBailout(1e4)),
+	} {
+		res, err := Render(context.Background(), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "out.png")
+		var buf bytes.Buffer
+		if err := EncodeWithText(&buf, res.Image, "png", Metadata(o)); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		cmd, err := ReproduceCommand(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := BuildReproduceCommand(o); cmd != want {
+			t.Errorf("read back %q, wrote %q", cmd, want)
+		}
+		// the chunks leave the image readable and unchanged
+		img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for y := range o.Height {
+			for x := range o.Width {
+				if color.RGBAModel.Convert(img.At(x, y)) != res.Image.RGBAAt(x, y) {
+					t.Fatalf("(%d, %d) changed", x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestReadPNGTextOrder(t *testing.T) {
+	res, err := Render(context.Background(), smallOptions(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := []TextChunk{{"Title", "first"}, {"Comment", "a value\nwith a newline"}, {"Title", "again"}}
+	var buf bytes.Buffer
+	if err := EncodeWithProfile(&buf, res.Image, "png", text, ProfileP3); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadPNGText(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, text) {
+		t.Errorf("read %v, wrote %v", got, text)
+	}
+
+	if err := EncodeWithText(&buf, res.Image, "png", []TextChunk{{"", "no key"}}); err == nil {
+		t.Error("empty keyword accepted")
+	}
+	if _, err := ReproduceCommand(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
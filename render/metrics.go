@@ -0,0 +1,18 @@
+package render
+
+import "time"
+
+// Metrics receives scheduling events from Render and Renderer so a
+// server can export them. A Metrics shared between concurrent renders
+// must be safe for concurrent use. The render package itself depends on
+// no monitoring library.
+type Metrics interface {
+	// RenderStarted is called as a frame starts.
+	RenderStarted()
+
+	// RenderDone is called as the frame ends, with the number of pixels
+	// computed, its wall time, the total time its procs workers spent
+	// computing rows, and the error Render returns.
+	// busy/(elapsed·procs) is the worker pool's utilization.
+	RenderDone(pixels int, elapsed, busy time.Duration, procs int, err error)
+}
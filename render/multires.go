@@ -0,0 +1,51 @@
+package render
+
+import (
+	"context"
+	"image"
+)
+
+// MinLevelSize is the smallest width or height MultiResolutionRender
+// renders a level at.
+const MinLevelSize = 32
+
+// RenderedLevel is one level of a MultiResolutionRender.
+type RenderedLevel struct {
+	Image         *image.RGBA
+	Width, Height int
+}
+
+// MultiResolutionRender renders opts at up to levels sizes, the full
+// size and then each half the one before, rounded down, for serving the
+// resolution that suits a screen's pixel ratio. It stops early at a
+// level narrower or shorter than MinLevelSize; the first level is always
+// rendered. levels of 0 or less renders every level down to that limit.
+//
+// Every level covers the same Bounds with the same iterations, palette
+// and coloring. A bloom radius, given in pixels, is halved with the size
+// so the glow looks the same. UseNRGBA is ignored, and callbacks such as
+// OnProgress are called for each level in turn with that level's size.
+// A cancelled render returns the finished levels with the error.
+func MultiResolutionRender(ctx context.Context, opts Options, levels int) ([]RenderedLevel, error) {
+	var out []RenderedLevel
+	for i := 0; levels <= 0 || i < levels; i++ {
+		o := opts
+		o.Width, o.Height = opts.Width>>i, opts.Height>>i
+		if i > 0 && (o.Width < MinLevelSize || o.Height < MinLevelSize) {
+			break
+		}
+		o.UseNRGBA = false
+		if o.Bloom.Strength > 0 {
+			if o.Bloom.Radius == 0 {
+				o.Bloom.Radius = DefaultBloomRadius
+			}
+			o.Bloom.Radius /= float64(int(1) << i)
+		}
+		res, err := Render(ctx, o)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, RenderedLevel{Image: res.Image, Width: o.Width, Height: o.Height})
+	}
+	return out, nil
+}
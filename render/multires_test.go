@@ -0,0 +1,103 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+func TestMultiResolutionRender(t *testing.T) {
+	opts, err := New(WithSize(640, 480), WithIterations(200),
+		WithViewport(coords.Bounds{Xmin: -0.9, Xmax: -0.6, Ymin: 0.05, Ymax: 0.275}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	levels, err := MultiResolutionRender(context.Background(), opts, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(levels) != 3 {
+		t.Fatalf("%d levels, want 3", len(levels))
+	}
+	center := opts.Bounds.Center()
+	for i, l := range levels {
+		w, h := 640>>i, 480>>i
+		if l.Width != w || l.Height != h || l.Image.Rect.Dx() != w || l.Image.Rect.Dy() != h {
+			t.Fatalf("level %d: %dx%d, image %v, want %dx%d", i, l.Width, l.Height, l.Image.Rect, w, h)
+		}
+		// the middle of every level is the middle of the view
+		o := opts
+		o.Width, o.Height = l.Width, l.Height
+		if got := o.Viewport().PointToComplex(float64(w)/2, float64(h)/2); got != center {
+			t.Errorf("level %d: centered on %v, want %v", i, got, center)
+		}
+		// and each is what rendering the view at its size gives
+		res, err := Render(context.Background(), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(l.Image.Pix, res.Image.Pix) {
+			t.Errorf("level %d differs from a render of the same view at %dx%d", i, w, h)
+		}
+	}
+}
+
+func TestMultiResolutionRenderLevels(t *testing.T) {
+	for _, tc := range []struct {
+		w, h, levels int
+		want         []int
+	}{
+		// every level down to 32 pixels
+		{1024, 512, 0, []int{1024, 512, 256, 128, 64}},
+		{256, 256, -1, []int{256, 128, 64, 32}},
+		// the height runs out first: 480/16 is 30
+		{640, 480, 0, []int{640, 320, 160, 80}},
+		{640, 100, 0, []int{640, 320}},
+		// the first level is rendered however small
+		{20, 10, 4, []int{20}},
+		{640, 480, 1, []int{640}},
+	} {
+		levels, err := MultiResolutionRender(context.Background(), smallOptions(t, WithSize(tc.w, tc.h), WithIterations(20)), tc.levels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var widths []int
+		for _, l := range levels {
+			widths = append(widths, l.Width)
+		}
+		if !slices.Equal(widths, tc.want) {
+			t.Errorf("%dx%d, %d levels: widths %v, want %v", tc.w, tc.h, tc.levels, widths, tc.want)
+		}
+	}
+}
+
+func TestMultiResolutionRenderBloom(t *testing.T) {
+	// the glow's radius is halved with the size
+	opts := smallOptions(t, WithSize(128, 96), WithBloom(Bloom{Strength: 0.8, Threshold: 0.2}))
+	levels, err := MultiResolutionRender(context.Background(), opts, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	half := opts
+	half.Width, half.Height = 64, 48
+	half.Bloom.Radius = DefaultBloomRadius / 2
+	res, err := Render(context.Background(), half)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(levels[1].Image.Pix, res.Image.Pix) {
+		t.Error("half-size level isn't bloomed with half the radius")
+	}
+}
+
+func TestMultiResolutionRenderCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	levels, err := MultiResolutionRender(ctx, smallOptions(t), 3)
+	if !errors.Is(err, context.Canceled) || len(levels) != 0 {
+		t.Errorf("cancelled: %d levels, %v", len(levels), err)
+	}
+}
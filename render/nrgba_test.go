@@ -0,0 +1,70 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/palette"
+)
+
+func TestNRGBAOpaqueMatchesRGBA(t *testing.T) {
+	for _, name := range palette.List() {
+		o := smallOptions(t, WithPaletteName(name))
+		rgba, err := Render(context.Background(), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.UseNRGBA = true
+		nrgba, err := Render(context.Background(), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nrgba.Image != nil || nrgba.NRGBA == nil {
+			t.Fatalf("%s: UseNRGBA filled Image %v, NRGBA %v", name, nrgba.Image != nil, nrgba.NRGBA != nil)
+		}
+		// with every alpha 255, premultiplying changes nothing
+		if !bytes.Equal(rgba.Image.Pix, nrgba.NRGBA.Pix) {
+			t.Errorf("%s: NRGBA pixels differ from RGBA", name)
+		}
+	}
+}
+
+func TestNRGBACompositing(t *testing.T) {
+	translucent := &palette.ColorMap{Colors: []palette.Color{
+		palette.NewStop(0, 0x10, 0x20, 0xc0, 0x80),
+		palette.NewStop(0.5, 0xff, 0xff, 0x00, 0x40),
+		palette.NewStop(1, 0xff, 0x00, 0x40, 0xc0),
+	}}
+	bg, err := Render(context.Background(), smallOptions(t, WithPaletteName("ThermalHeat"), WithNRGBA(true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fg, err := Render(context.Background(), smallOptions(t, WithPalette(translucent), WithNRGBA(true), WithPalettePhase(0.3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := image.NewNRGBA(bg.NRGBA.Rect)
+	draw.Draw(out, out.Rect, bg.NRGBA, image.Point{}, draw.Src)
+	draw.Draw(out, out.Rect, fg.NRGBA, image.Point{}, draw.Over)
+	blend := func(s, d, a uint8) int { return (int(s)*int(a) + int(d)*(255-int(a)) + 127) / 255 }
+	near := func(a uint8, b int) bool { return int(a)-b <= 1 && b-int(a) <= 1 }
+	translucentSeen := false
+	for y := range out.Rect.Dy() {
+		for x := range out.Rect.Dx() {
+			s, d, got := fg.NRGBA.NRGBAAt(x, y), bg.NRGBA.NRGBAAt(x, y), out.NRGBAAt(x, y)
+			translucentSeen = translucentSeen || (s.A > 0 && s.A < 0xff)
+			want := color.NRGBA{uint8(blend(s.R, d.R, s.A)), uint8(blend(s.G, d.G, s.A)), uint8(blend(s.B, d.B, s.A)), 0xff}
+			if !near(got.R, int(want.R)) || !near(got.G, int(want.G)) || !near(got.B, int(want.B)) || got.A != 0xff {
+				t.Fatalf("(%d, %d): %v over %v gives %v, want %v", x, y, s, d, got, want)
+			}
+		}
+	}
+	if !translucentSeen {
+		t.Fatal("no translucent pixels in the foreground")
+	}
+}
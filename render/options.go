@@ -0,0 +1,158 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"runtime"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/fractal"
+	"github.com/whalelogic/mandlebrot/palette"
+	"github.com/whalelogic/mandlebrot/shader"
+)
+
+// Options describes a single render.
+type Options struct {
+	Width, Height int
+	Bounds        coords.Bounds
+	Rotation      float64 // degrees counter-clockwise about the center of Bounds
+	FlipY         bool    // put Ymin at the top of the image; see coords.Viewport
+	MaxIter       int
+	Palette       *palette.ColorMap
+	Fractal       fractal.Fractal // nil means fractal.Mandelbrot{}
+	Transform     Mobius          // conformal map from the view to the sample points, the zero value for none
+	Bailout       float64         // escape radius, 0 means DefaultBailout
+	Coloring      Coloring        // "" means ColoringSmooth
+	Bands         int             // band count for ColoringBands and ColoringBlend
+	BlendSmooth   float64         // smooth weight for ColoringBlend, 0..1
+	ZmagSmooth    float64         // smooth weight for ColoringZmagCos, 0..1
+	TrapPoints    []complex128    // orbit traps for ColoringFixedTrap
+	TrapScale     float64         // distance scale for ColoringFixedTrap, 0 means DefaultTrapScale
+	InteriorScale float64         // distance scale for ColoringInteriorDistance, 0 means DefaultInteriorScale
+	PalettePhase  float64         // shifts escaped pixels along the palette; see phaseT
+	AutoContrast  bool            // stretch the escaped pixels' palette positions over the whole palette once the frame is done; see autoContrast
+	Adjust        Adjust          // color correction of every pixel
+	Bloom         Bloom           // glow around bright colors, added once the frame is done
+	Procs         int             // worker count, 0 means runtime.NumCPU()
+
+	// Shader, if set, colors each pixel in place of the palette, given
+	// the palette position the coloring chose; see shader.PixelShader.
+	// The render's color correction still applies. It isn't part of the
+	// JSON form or the reproduce command.
+	Shader shader.PixelShader
+
+	// RowsPerChunk is how many consecutive rows a worker claims at a
+	// time; 0 means max(1, Height/Procs/4). Larger chunks cut the
+	// scheduling overhead per row, which matters for narrow images, but
+	// coarsen load balancing: rows inside the set cost far more than rows
+	// outside it, and with few chunks per worker one slow chunk can leave
+	// the others idle at the end of the frame.
+	RowsPerChunk int
+
+	// Throttle is the percentage of the time, 1 to 100, each worker
+	// spends computing: after every row it sleeps for that row's time
+	// times (100-Throttle)/Throttle, so 50 takes twice as long. It keeps
+	// a long render from running a machine hot. 0 means 100, no sleep.
+	Throttle int
+
+	// OnPixel, if set, is called once per pixel with the kernel's output.
+	// It is invoked concurrently from the worker goroutines, in no
+	// particular order, and must be safe for concurrent use.
+	OnPixel func(x, y int, result PixelResult)
+
+	// OnProgress, if set, is called with the number of completed rows
+	// and the total every ProgressInterval while the render runs, and
+	// once more when it finishes. Calls come from a single goroutine and
+	// done never decreases. Workers never wait on it: if a call is slow,
+	// the intermediate updates are dropped.
+	OnProgress       func(done, total int)
+	ProgressInterval time.Duration // 0 means 100ms
+
+	// OnRegion, if set, is called with each completed region (currently
+	// a single row) and a copy of its pixels in row-major order, which
+	// the callee may keep. Calls come from a single goroutine; with
+	// OrderedRegions they arrive top to bottom, otherwise in completion
+	// order. A slow OnRegion delays delivery but never stalls the
+	// workers, and Render returns only after the last call.
+	OnRegion       func(rect image.Rectangle, pixels []color.RGBA)
+	OrderedRegions bool
+
+	// Metrics, if set, is told when each frame starts and ends.
+	Metrics Metrics
+
+	// UseNRGBA renders into Result.NRGBA, with non-premultiplied alpha,
+	// instead of Result.Image. Opaque palettes give the same pixels
+	// either way; translucent ones keep their exact colors, which is
+	// what compositing with draw.Draw needs.
+	UseNRGBA bool
+
+	// OutputHSL writes each escape-time color as HSL packed into the
+	// color channels (see hslPixel) for pipelines that work in HSL. It
+	// can't be combined with ColoringDebug or ColoringAttractor.
+	OutputHSL bool
+
+	// HighPrecisionCoords rounds each pixel's sample point once from its
+	// exact value instead of accumulating the roundings of the plain
+	// mapping, for deep zooms; see coords.Viewport.HighPrecision.
+	HighPrecisionCoords bool
+
+	// DiscardBuffers drops the per-pixel iteration buffer and interior
+	// mask from the Result, saving 9 bytes per pixel.
+	DiscardBuffers bool
+
+	// Sparse samples every SparseBlock-th row and column before the
+	// render and fills the SparseBlock×SparseBlock blocks deep inside
+	// rings of interior samples with the interior color, without
+	// iterating their pixels; see identifyInteriorBlocks. It pays off for
+	// formulas whose interior costs the full iteration count, such as
+	// Julia sets, less for the Mandelbrot set, whose largest components
+	// are recognized without iterating anyway. An escaping filament too
+	// thin to touch any sample would be lost, and OnPixel sees a zero Z
+	// for the skipped pixels. It can't be combined with the colorings
+	// that vary inside the set, a shader or a transform.
+	Sparse bool
+
+	// RecordTimings times every work unit the workers claim and returns
+	// the times in Result.Timings, for judging how evenly the load is
+	// spread. It costs two clock reads per unit.
+	RecordTimings bool
+}
+
+// PixelResult is the per-pixel output handed to Options.OnPixel.
+type PixelResult struct {
+	Iter   int        // escape iteration, MaxIter for interior points
+	Smooth float64    // continuous escape count, MaxIter for interior points
+	T      float64    // palette position in [0,1]
+	Z      complex128 // final orbit value
+	Color  color.RGBA
+}
+
+// Viewport returns the pixel↔plane mapping for the render.
+func (o Options) Viewport() coords.Viewport {
+	return coords.Viewport{Bounds: o.Bounds, Rotation: o.Rotation, Width: o.Width, Height: o.Height, FlipY: o.FlipY, HighPrecision: o.HighPrecisionCoords}
+}
+
+// withDefaults fills in the zero-valued fields that have defaults, and
+// freezes the palette, so a built-in one is read from its table.
+func (o Options) withDefaults() Options {
+	if o.Palette != nil {
+		o.Palette = o.Palette.Frozen()
+	}
+	if o.Fractal == nil {
+		o.Fractal = fractal.Mandelbrot{}
+	}
+	if o.Procs <= 0 {
+		o.Procs = runtime.NumCPU()
+	}
+	if o.Bailout == 0 {
+		o.Bailout = DefaultBailout
+	}
+	if o.TrapScale == 0 {
+		o.TrapScale = DefaultTrapScale
+	}
+	if o.InteriorScale == 0 {
+		o.InteriorScale = DefaultInteriorScale
+	}
+	return o
+}
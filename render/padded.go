@@ -0,0 +1,75 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"unsafe"
+)
+
+// cacheLine is the stride alignment of PaddedRGBA.
+const cacheLine = 64
+
+// PaddedRGBA is an RGBA image whose rows start on cache-line boundaries,
+// so workers writing neighbouring rows never touch the same cache line.
+// Pixels are laid out as in image.RGBA, with Stride rounded up to a
+// multiple of 64 bytes.
+type PaddedRGBA struct {
+	Pix    []uint8
+	Stride int
+	Rect   image.Rectangle
+}
+
+// NewPaddedRGBA returns a blank width×height image.
+func NewPaddedRGBA(width, height int) *PaddedRGBA {
+	stride := (4*width + cacheLine - 1) / cacheLine * cacheLine
+	// over-allocate so the first row can be moved onto a line boundary
+	buf := make([]uint8, stride*height+cacheLine)
+	off := 0
+	if len(buf) > 0 {
+		off = int(-uintptr(unsafe.Pointer(&buf[0])) & (cacheLine - 1))
+	}
+	return &PaddedRGBA{
+		Pix:    buf[off : off+stride*height],
+		Stride: stride,
+		Rect:   image.Rect(0, 0, width, height),
+	}
+}
+
+func (p *PaddedRGBA) ColorModel() color.Model { return color.RGBAModel }
+
+func (p *PaddedRGBA) Bounds() image.Rectangle { return p.Rect }
+
+func (p *PaddedRGBA) At(x, y int) color.Color { return p.RGBAAt(x, y) }
+
+// PixOffset returns the index of the first byte of pixel (x, y) in Pix.
+func (p *PaddedRGBA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x-p.Rect.Min.X)*4
+}
+
+// RGBAAt returns the pixel at (x, y), or transparent black outside the image.
+func (p *PaddedRGBA) RGBAAt(x, y int) color.RGBA {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return color.RGBA{}
+	}
+	s := p.Pix[p.PixOffset(x, y):]
+	return color.RGBA{s[0], s[1], s[2], s[3]}
+}
+
+// SetRGBA sets the pixel at (x, y); points outside the image are ignored.
+func (p *PaddedRGBA) SetRGBA(x, y int, c color.RGBA) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	s := p.Pix[p.PixOffset(x, y):]
+	s[0], s[1], s[2], s[3] = c.R, c.G, c.B, c.A
+}
+
+// ToRGBA copies p into a tightly packed image.RGBA.
+func (p *PaddedRGBA) ToRGBA() *image.RGBA {
+	out := image.NewRGBA(p.Rect)
+	n := 4 * p.Rect.Dx()
+	for y := range p.Rect.Dy() {
+		copy(out.Pix[y*out.Stride:y*out.Stride+n], p.Pix[y*p.Stride:y*p.Stride+n])
+	}
+	return out
+}
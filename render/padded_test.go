@@ -0,0 +1,88 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"runtime"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func TestPaddedRGBA(t *testing.T) {
+	res, err := Render(context.Background(), smallOptions(t, WithSize(37, 23)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := res.Image
+	p := NewPaddedRGBA(37, 23)
+	if p.Stride%cacheLine != 0 || p.Stride < 4*37 {
+		t.Errorf("stride %d, want a multiple of %d of at least %d", p.Stride, cacheLine, 4*37)
+	}
+	if off := uintptr(unsafe.Pointer(&p.Pix[0])) % cacheLine; off != 0 {
+		t.Errorf("first row starts %d bytes past a cache line", off)
+	}
+	for y := range 23 {
+		for x := range 37 {
+			p.SetRGBA(x, y, want.RGBAAt(x, y))
+		}
+	}
+	p.SetRGBA(37, 0, color.RGBA{1, 2, 3, 4}) // outside, ignored
+	if got := p.RGBAAt(-1, 0); got != (color.RGBA{}) {
+		t.Errorf("RGBAAt outside = %v", got)
+	}
+
+	got := p.ToRGBA()
+	if got.Rect != want.Rect || !bytes.Equal(got.Pix, want.Pix) {
+		t.Fatal("ToRGBA differs from the render")
+	}
+	var a, b bytes.Buffer
+	if err := png.Encode(&a, got); err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(&b, want); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("PNG of ToRGBA differs from the render's")
+	}
+}
+
+// BenchmarkParallelRows fills a 2000×1500 image with one worker per CPU
+// taking rows round robin, the access pattern of the renderer, into an
+// image.RGBA and a PaddedRGBA, to show the cost of false sharing between
+// adjacent rows.
+func BenchmarkParallelRows(b *testing.B) {
+	const w, h = 2000, 1500
+	for _, bc := range []struct {
+		name string
+		img  interface{ SetRGBA(x, y int, c color.RGBA) }
+	}{
+		{"RGBA", image.NewRGBA(image.Rect(0, 0, w, h))},
+		{"Padded", NewPaddedRGBA(w, h)},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			procs := runtime.GOMAXPROCS(0)
+			b.SetBytes(w * h * 4)
+			for range b.N {
+				var wg sync.WaitGroup
+				for p := range procs {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for y := p; y < h; y += procs {
+							for x := range w {
+								bc.img.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y), 0, 0xff})
+							}
+						}
+					}()
+				}
+				wg.Wait()
+			}
+			sink = bc.img
+		})
+	}
+}
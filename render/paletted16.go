@@ -0,0 +1,209 @@
+package render
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+)
+
+// Paletted16Colors is the size of the color table Render16bit looks
+// palette positions up in.
+const Paletted16Colors = 1 << 16
+
+// PaletteImage is a render kept as a 16-bit index per pixel into a
+// table of Paletted16Colors colors, the palette sampled evenly from one
+// end to the other with the render's color correction applied. Index i
+// holds the color at palette position i/65535, which is close enough
+// to the exact color that no channel is more than a unit off.
+type PaletteImage struct {
+	Rect  image.Rectangle
+	Index []uint16     // one per pixel, row by row
+	Table []color.RGBA // Paletted16Colors entries
+}
+
+// ColorModel returns color.RGBAModel.
+func (p *PaletteImage) ColorModel() color.Model { return color.RGBAModel }
+
+// Bounds returns p.Rect.
+func (p *PaletteImage) Bounds() image.Rectangle { return p.Rect }
+
+// At returns the color of the pixel at (x, y).
+func (p *PaletteImage) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return color.RGBA{}
+	}
+	return p.Table[p.Index[(y-p.Rect.Min.Y)*p.Rect.Dx()+x-p.Rect.Min.X]]
+}
+
+// Render16bit renders opts as a PaletteImage: each pixel's palette
+// position t is stored as the index uint16(t*65535) instead of as a
+// color. Only renders colored by palette position can be kept this way,
+// so a shader, HSL or NRGBA output, bloom, auto contrast and the debug
+// and attractor colorings are invalid.
+func Render16bit(ctx context.Context, opts Options) (*PaletteImage, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	for _, c := range []struct {
+		set  bool
+		what string
+	}{
+		{opts.Shader != nil, "a shader"},
+		{opts.OutputHSL, "HSL output"},
+		{opts.UseNRGBA, "NRGBA output"},
+		{opts.Bloom.Strength != 0, "bloom"},
+		{opts.AutoContrast, "auto contrast"},
+		{opts.Coloring == ColoringDebug || opts.Coloring == ColoringAttractor, string(opts.Coloring) + " coloring"},
+	} {
+		if c.set {
+			return nil, fmt.Errorf("%w: 16-bit paletted output colors by palette position and can't have %s", ErrInvalidOptions, c.what)
+		}
+	}
+
+	w, h := opts.Width, opts.Height
+	p := &PaletteImage{
+		Rect:  image.Rect(0, 0, w, h),
+		Index: make([]uint16, w*h),
+		Table: make([]color.RGBA, Paletted16Colors),
+	}
+	for i := range p.Table {
+		p.Table[i] = opts.Adjust.rgba(opts.Palette.Interpolate(float64(i) / (Paletted16Colors - 1)))
+	}
+	onPixel := opts.OnPixel
+	opts.OnPixel = func(x, y int, r PixelResult) {
+		t := min(r.T, 1)
+		if !(t > 0) {
+			t = 0
+		}
+		p.Index[y*w+x] = uint16(t * (Paletted16Colors - 1))
+		if onPixel != nil {
+			onPixel(x, y, r)
+		}
+	}
+	opts.DiscardBuffers = true
+	if _, err := Render(ctx, opts); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Paletted returns p reduced to an image.Paletted of at most n colors,
+// n in [1, 256], chosen by median cut from the table entries p uses,
+// weighted by how many pixels use each. Every entry is drawn in the
+// nearest of the chosen colors.
+func (p *PaletteImage) Paletted(n int) *image.Paletted {
+	n = min(max(n, 1), 256)
+	counts := make([]int, len(p.Table))
+	for _, i := range p.Index {
+		counts[i]++
+	}
+	var used []tableEntry
+	for i, k := range counts {
+		if k > 0 {
+			used = append(used, tableEntry{p.Table[i], k})
+		}
+	}
+	pal := medianCut(used, n)
+
+	// nearest chosen color of every table entry in use
+	lut := make([]uint8, len(p.Table))
+	for i, k := range counts {
+		if k > 0 {
+			lut[i] = uint8(pal.Index(p.Table[i]))
+		}
+	}
+	out := image.NewPaletted(p.Rect, pal)
+	for y := range p.Rect.Dy() {
+		row := p.Index[y*p.Rect.Dx() : (y+1)*p.Rect.Dx()]
+		dst := out.Pix[y*out.Stride:]
+		for x, i := range row {
+			dst[x] = lut[i]
+		}
+	}
+	return out
+}
+
+// tableEntry is a color and the number of pixels that use it.
+type tableEntry struct {
+	c     color.RGBA
+	count int
+}
+
+// channel returns channel ch of e's color: 0 red, 1 green, 2 blue or 3
+// alpha.
+func (e tableEntry) channel(ch int) uint8 {
+	return [4]uint8{e.c.R, e.c.G, e.c.B, e.c.A}[ch]
+}
+
+// medianCut picks at most n colors for entries by Heckbert's median cut:
+// the box of colors with the widest range in any channel is split at
+// the pixel-weighted median of that channel, until there are n boxes or
+// none can be split, and each box gives the weighted mean of its colors.
+func medianCut(entries []tableEntry, n int) color.Palette {
+	if len(entries) == 0 {
+		return color.Palette{color.RGBA{}}
+	}
+	boxes := [][]tableEntry{entries}
+	for len(boxes) < n {
+		widest, ch, span := -1, 0, 0
+		for i, b := range boxes {
+			if c, s := boxSpan(b); s > span {
+				widest, ch, span = i, c, s
+			}
+		}
+		if widest < 0 {
+			break // every box holds a single color
+		}
+		b := boxes[widest]
+		slices.SortFunc(b, func(x, y tableEntry) int { return cmp.Compare(x.channel(ch), y.channel(ch)) })
+		total := 0
+		for _, e := range b {
+			total += e.count
+		}
+		// the first entry at which half the pixels have gone by, moved off
+		// the ends so both halves keep at least one entry
+		cut, seen := 1, 0
+		for i, e := range b[:len(b)-1] {
+			if seen += e.count; 2*seen >= total {
+				cut = i + 1
+				break
+			}
+		}
+		boxes[widest] = b[:cut]
+		boxes = append(boxes, b[cut:])
+	}
+
+	pal := make(color.Palette, len(boxes))
+	for i, b := range boxes {
+		var sum [4]int
+		total := 0
+		for _, e := range b {
+			for ch := range sum {
+				sum[ch] += int(e.channel(ch)) * e.count
+			}
+			total += e.count
+		}
+		mean := func(ch int) uint8 { return uint8((sum[ch] + total/2) / total) }
+		pal[i] = color.RGBA{mean(0), mean(1), mean(2), mean(3)}
+	}
+	return pal
+}
+
+// boxSpan returns the channel in which the colors of b are spread the
+// widest, and the spread.
+func boxSpan(b []tableEntry) (ch, span int) {
+	for c := range 4 {
+		lo, hi := uint8(255), uint8(0)
+		for _, e := range b {
+			v := e.channel(c)
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		if s := int(hi) - int(lo); s > span {
+			ch, span = c, s
+		}
+	}
+	return ch, span
+}
@@ -0,0 +1,83 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/palette"
+)
+
+// maxChannelDiff returns the largest difference in any channel between
+// two images of the same bounds.
+func maxChannelDiff(a, b image.Image) int {
+	worst := 0
+	r := a.Bounds()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			ca := color.RGBAModel.Convert(a.At(x, y)).(color.RGBA)
+			cb := color.RGBAModel.Convert(b.At(x, y)).(color.RGBA)
+			for _, d := range []int{
+				int(ca.R) - int(cb.R), int(ca.G) - int(cb.G),
+				int(ca.B) - int(cb.B), int(ca.A) - int(cb.A),
+			} {
+				worst = max(worst, d, -d)
+			}
+		}
+	}
+	return worst
+}
+
+func TestRender16bitPaletted(t *testing.T) {
+	seahorse := WithViewport(coords.Bounds{Xmin: -0.76, Xmax: -0.74, Ymin: 0.09, Ymax: 0.11})
+	for _, name := range palette.List() {
+		for _, coloring := range []Coloring{ColoringSmooth, ColoringBands} {
+			o, err := New(WithSize(160, 120), WithIterations(300), seahorse,
+				WithPaletteName(name), WithColoring(coloring))
+			if err != nil {
+				t.Fatal(err)
+			}
+			full, err := Render(context.Background(), o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := Render16bit(context.Background(), o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := maxChannelDiff(p, full.Image); d > 1 {
+				t.Errorf("%s %s: 16-bit image differs from Render's by %d, want at most 1", name, coloring, d)
+			}
+
+			pal := p.Paletted(256)
+			used := make(map[uint8]bool)
+			for _, i := range pal.Pix {
+				used[i] = true
+			}
+			if len(pal.Palette) > 256 || len(used) > 256 {
+				t.Errorf("%s %s: %d palette entries, %d used, want at most 256", name, coloring, len(pal.Palette), len(used))
+			}
+			if d := maxChannelDiff(pal, full.Image); d > 3 {
+				t.Errorf("%s %s: paletted image differs from Render's by %d, want at most 3", name, coloring, d)
+			}
+		}
+	}
+}
+
+func TestRender16bitInvalid(t *testing.T) {
+	for _, opt := range []Option{
+		WithOutputHSL(true),
+		WithNRGBA(true),
+		WithBloom(Bloom{Strength: 0.5, Radius: 2}),
+		WithAutoContrast(true),
+		WithColoring(ColoringDebug),
+		WithColoring(ColoringAttractor),
+	} {
+		if _, err := Render16bit(context.Background(), smallOptions(t, opt)); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("got %v, want ErrInvalidOptions", err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package render
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// repellingFixedPoint returns the fixed point of z² + c that pushes
+// orbits away the harder, the root of z² - z + c = 0 with the larger
+// |2z|. At the cusp c = 1/4 the two meet at 1/2 and neither attracts or
+// repels; that parabolic point is what gives the region its name.
+func repellingFixedPoint(c complex128) complex128 {
+	s := cmplx.Sqrt(1 - 4*c)
+	a, b := (1+s)/2, (1-s)/2
+	if cmplx.Abs(b) > cmplx.Abs(a) {
+		return b
+	}
+	return a
+}
+
+// parabolicImplosionT is the ColoringParabolic palette position of an
+// escaped orbit of z² + c: the continuous escape count as a fraction of
+// maxIter, weighted by 1 + ln(1 + |z - z*|) with z the first iterate
+// past the escape radius and z* the repelling fixed point. Near the cusp
+// of the main cardioid the orbits crawl through the gate between the
+// two fixed points for many steps and leave it close to z*, so the
+// weight changes sharply from one orbit to the next and the exterior
+// there breaks into flame-like tongues. Interior points get 0.
+func parabolicImplosionT(o orbit, c complex128, maxIter int, sm smoothing) float64 {
+	if o.iter >= maxIter {
+		return 0
+	}
+	w := 1 + math.Log1p(cmplx.Abs(o.z-repellingFixedPoint(c)))
+	return clamp01(smoothIter(o, sm) / float64(maxIter) * w)
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"context"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+func TestRepellingFixedPoint(t *testing.T) {
+	for _, tc := range []struct{ c, want complex128 }{
+		{0.24, 0.6},
+		{-0.5, (1 + complex(math.Sqrt(3), 0)) / 2},
+		{0.25, 0.5},
+		{-2, 2},
+		{0, 1},
+	} {
+		z := repellingFixedPoint(tc.c)
+		if cmplx.Abs(z-tc.want) > 1e-12 {
+			t.Errorf("c=%v: %v, want %v", tc.c, z, tc.want)
+		}
+	}
+	// a fixed point, and the one with the larger multiplier |2z|, for
+	// points in and out of the set
+	for _, c := range []complex128{0.3 + 0.5i, -0.75 + 0.1i, 2i, -1.2, 0.1 - 0.4i} {
+		z := repellingFixedPoint(c)
+		if cmplx.Abs(z*z+c-z) > 1e-12 {
+			t.Errorf("c=%v: %v isn't fixed", c, z)
+		}
+		if other := 1 - z; cmplx.Abs(2*other) > cmplx.Abs(2*z)+1e-12 {
+			t.Errorf("c=%v: %v repels less than %v", c, z, other)
+		}
+	}
+}
+
+func TestParabolicImplosionT(t *testing.T) {
+	sm := newSmoothing(2, DefaultBailout)
+	// the same escape, iteration count and final z: nearer the cusp, z* is
+	// nearer 1/2 and so farther from z, which weighs more
+	o := orbit{iter: 40, z: 2.5, how: resultEscaped, far: 2.5, farIter: 40}
+	near, far := parabolicImplosionT(o, 0.24, 1000, sm), parabolicImplosionT(o, -0.5, 1000, sm)
+	if !(near > far) {
+		t.Errorf("t at c=0.24 is %v, no larger than %v at c=-0.5", near, far)
+	}
+	// the weights are 1 + ln(1 + 1.9) and 1 + ln(1 + 1.134)
+	if got, want := near/far, (1+math.Log(2.9))/(1+math.Log1p(2.5-real(repellingFixedPoint(-0.5)))); math.Abs(got-want) > 1e-12 {
+		t.Errorf("ratio %v, want %v", got, want)
+	}
+	base := smoothIter(o, sm) / 1000
+	if got, want := near, base*(1+math.Log(2.9)); math.Abs(got-want) > 1e-12 {
+		t.Errorf("t at c=0.24 is %v, want %v", got, want)
+	}
+
+	// interior points get 0, and t stays within the palette
+	if got := parabolicImplosionT(orbit{iter: 1000, how: resultInterior, farIter: 1000}, 0.24, 1000, sm); got != 0 {
+		t.Errorf("interior: %v", got)
+	}
+	slow := orbit{iter: 990, z: 1e3, how: resultEscaped, far: 1e3, farIter: 990}
+	if got := parabolicImplosionT(slow, 0.24, 1000, sm); got != 1 {
+		t.Errorf("slow escape far from z*: %v, want the clamp at 1", got)
+	}
+}
+
+func TestParabolicRender(t *testing.T) {
+	// just past the cusp, orbits crawl through the gate and take long to
+	// escape, so t is far larger there than farther along the real axis,
+	// though the weight makes it ripple rather than fall steadily
+	opts := smallOptions(t, WithSize(101, 3), WithIterations(2000), WithColoring(ColoringParabolic),
+		WithViewport(coords.Bounds{Xmin: 0.255, Xmax: 0.5, Ymin: -0.001, Ymax: 0.001}))
+	var ts [101]float64
+	opts.OnPixel = func(x, y int, p PixelResult) {
+		if y == 1 {
+			ts[x] = p.T
+		}
+	}
+	if _, err := Render(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	var near, far float64
+	for x := range 10 {
+		near += ts[x]
+		far += ts[len(ts)-1-x]
+	}
+	if !(far > 0) || near < 5*far {
+		t.Errorf("t sums to %v next to the cusp, %v far from it", near, far)
+	}
+}
@@ -0,0 +1,313 @@
+package render
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"hash/adler32"
+	"image"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// bandsPerWorker is how many bands ParallelPNGEncode cuts the image into
+// for each worker, so one slow band doesn't leave the others idle, and
+// minBandRows the fewest rows in a band, so the flush at the end of each
+// costs next to nothing.
+const (
+	bandsPerWorker = 4
+	minBandRows    = 64
+)
+
+// flateWriters holds deflate compressors for reuse; each carries a
+// window and hash tables of several hundred kilobytes.
+var flateWriters = sync.Pool{
+	New: func() any {
+		fw, _ := flate.NewWriter(nil, flate.DefaultCompression)
+		return fw
+	},
+}
+
+// ParallelPNGEncode writes img to w as a PNG, as png.Encode would, but
+// filters and compresses bands of rows on up to concurrency goroutines
+// at once; 0 or less means one per CPU. The result decodes to the same
+// pixels as png.Encode's, and for a large image is within a few tenths
+// of a percent of its size.
+//
+// PNG needs the IDAT chunks to hold a single zlib stream, so the bands
+// can't be compressed as streams of their own. Each is compressed as a
+// run of deflate blocks instead, ending in a sync flush that leaves it
+// on a byte boundary, and the runs are joined behind one zlib header
+// with the Adler-32 of the whole, as pigz does. Each band goes out in
+// its own IDAT chunk as soon as those before it have.
+func ParallelPNGEncode(w io.Writer, img *image.RGBA, concurrency int) error {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width <= 0 || height <= 0 || int64(width) >= 1<<32 || int64(height) >= 1<<32 {
+		return fmt.Errorf("png: invalid image size: %dx%d", width, height)
+	}
+	// like png.Encode, drop the alpha channel when it's all opaque
+	bpp, colorType := 3, byte(2)
+	if !img.Opaque() {
+		bpp, colorType = 4, 6
+	}
+
+	if _, err := io.WriteString(w, pngSignature); err != nil {
+		return err
+	}
+	var ihdr [13]byte
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(width))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(height))
+	ihdr[8], ihdr[9] = 8, colorType // 8 bits a sample; compression, filter and interlace 0
+	if err := writeChunk(w, "IHDR", ihdr[:]); err != nil {
+		return err
+	}
+
+	rows := max((height+concurrency*bandsPerWorker-1)/(concurrency*bandsPerWorker), minBandRows)
+	bands := make([]pngBand, (height+rows-1)/rows)
+	for i := range bands {
+		bands[i].done = make(chan struct{})
+	}
+	var next atomic.Int64
+	for range min(concurrency, len(bands)) {
+		go func() {
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(bands) {
+					return
+				}
+				y0 := b.Min.Y + i*rows
+				bands[i].encode(img, y0, min(y0+rows, b.Max.Y), bpp, i == len(bands)-1)
+				close(bands[i].done)
+			}
+		}()
+	}
+
+	// zlib header: deflate with a 32K window at the default level
+	zlibHeader := []byte{0x78, 0x9c}
+	sum := uint32(1)
+	var err error
+	for i := range bands {
+		<-bands[i].done
+		band := &bands[i]
+		if err == nil {
+			if i == 0 {
+				err = writeChunk(w, "IDAT", zlibHeader, band.data.Bytes())
+			} else {
+				err = writeChunk(w, "IDAT", band.data.Bytes())
+			}
+		}
+		sum = adler32Combine(sum, band.sum, band.n)
+		band.data = bytes.Buffer{}
+	}
+	if err != nil {
+		return err
+	}
+	if err := writeChunk(w, "IDAT", binary.BigEndian.AppendUint32(nil, sum)); err != nil {
+		return err
+	}
+	return writeChunk(w, "IEND", nil)
+}
+
+// pngBand is one band of rows of ParallelPNGEncode's image, compressed.
+type pngBand struct {
+	data bytes.Buffer  // the deflate blocks
+	sum  uint32        // the Adler-32 of the filtered rows
+	n    int64         // the length of the filtered rows
+	done chan struct{} // closed once the fields above are set
+}
+
+// encode filters rows [y0, y1) of img and compresses them into band,
+// finishing the deflate stream if last is set.
+func (band *pngBand) encode(img *image.RGBA, y0, y1, bpp int, last bool) {
+	width := img.Bounds().Dx()
+	stride := 1 + width*bpp
+	prev := make([]byte, stride) // rows above the image are zero
+	cur := make([]byte, stride)
+	if y0 > img.Bounds().Min.Y {
+		packRow(prev[1:], img, y0-1, bpp)
+	}
+	var filters [5][]byte
+	for f := range filters {
+		filters[f] = make([]byte, stride)
+	}
+
+	fw := flateWriters.Get().(*flate.Writer)
+	defer flateWriters.Put(fw)
+	fw.Reset(&band.data)
+	h := adler32.New()
+	for y := y0; y < y1; y++ {
+		packRow(cur[1:], img, y, bpp)
+		row := filterRow(cur, prev, bpp, &filters)
+		h.Write(row)
+		fw.Write(row) // writes to a bytes.Buffer can't fail
+		cur, prev = prev, cur
+	}
+	band.sum = h.Sum32()
+	band.n = int64(y1-y0) * int64(stride)
+	if last {
+		fw.Close()
+	} else {
+		fw.Flush()
+	}
+}
+
+// packRow copies row y of img into dst as PNG samples: RGB when bpp is
+// 3, and otherwise RGBA with the alpha unpremultiplied the way
+// color.NRGBAModel does it, which is what png.Encode writes.
+func packRow(dst []byte, img *image.RGBA, y, bpp int) {
+	src := img.Pix[img.PixOffset(img.Bounds().Min.X, y):]
+	if bpp == 4 {
+		for i := 0; i < len(dst); i += 4 {
+			r, g, b, a := src[i], src[i+1], src[i+2], src[i+3]
+			if a != 0 && a != 0xff {
+				a16 := uint32(a) * 0x101
+				r = uint8(uint32(r) * 0x101 * 0xffff / a16 >> 8)
+				g = uint8(uint32(g) * 0x101 * 0xffff / a16 >> 8)
+				b = uint8(uint32(b) * 0x101 * 0xffff / a16 >> 8)
+			} else if a == 0 {
+				r, g, b = 0, 0, 0
+			}
+			dst[i], dst[i+1], dst[i+2], dst[i+3] = r, g, b, a
+		}
+		return
+	}
+	for i, j := 0, 0; i < len(dst); i, j = i+3, j+4 {
+		dst[i], dst[i+1], dst[i+2] = src[j], src[j+1], src[j+2]
+	}
+}
+
+// filterRow picks the filter for cur, a row of samples behind a byte for
+// the filter type, the way png.Encode does: the one whose output has the
+// smallest sum of absolute values, as signed bytes. Like png.Encode it
+// tries the likeliest winners first and gives up on a filter as soon as
+// it can't win. It returns the filtered row, which is one of the scratch
+// rows.
+func filterRow(cur, prev []byte, bpp int, scratch *[5][]byte) []byte {
+	cdat, pdat := cur[1:], prev[1:]
+	n := len(cdat)
+	for f, out := range scratch {
+		out[0] = byte(f)
+	}
+
+	// up
+	best, bestSum := 2, 0
+	up := scratch[2][1:]
+	for i := range n {
+		up[i] = cdat[i] - pdat[i]
+		bestSum += absInt8(up[i])
+	}
+	// paeth: with no pixel to the left it predicts the one above
+	sum := 0
+	pth := scratch[4][1:]
+	for i := range bpp {
+		pth[i] = cdat[i] - pdat[i]
+		sum += absInt8(pth[i])
+	}
+	for i := bpp; i < n && sum < bestSum; i++ {
+		pth[i] = cdat[i] - paeth(cdat[i-bpp], pdat[i], pdat[i-bpp])
+		sum += absInt8(pth[i])
+	}
+	if sum < bestSum {
+		best, bestSum = 4, sum
+	}
+	// none
+	sum = 0
+	for i := 0; i < n && sum < bestSum; i++ {
+		sum += absInt8(cdat[i])
+	}
+	if sum < bestSum {
+		copy(scratch[0][1:], cdat)
+		best, bestSum = 0, sum
+	}
+	// sub
+	sum = 0
+	sub := scratch[1][1:]
+	for i := range bpp {
+		sub[i] = cdat[i]
+		sum += absInt8(sub[i])
+	}
+	for i := bpp; i < n && sum < bestSum; i++ {
+		sub[i] = cdat[i] - cdat[i-bpp]
+		sum += absInt8(sub[i])
+	}
+	if sum < bestSum {
+		best, bestSum = 1, sum
+	}
+	// average
+	sum = 0
+	avg := scratch[3][1:]
+	for i := range bpp {
+		avg[i] = cdat[i] - pdat[i]/2
+		sum += absInt8(avg[i])
+	}
+	for i := bpp; i < n && sum < bestSum; i++ {
+		avg[i] = cdat[i] - uint8((int(cdat[i-bpp])+int(pdat[i]))/2)
+		sum += absInt8(avg[i])
+	}
+	if sum < bestSum {
+		best = 3
+	}
+	return scratch[best]
+}
+
+func abs(k int) int {
+	if k < 0 {
+		return -k
+	}
+	return k
+}
+
+func absInt8(v byte) int {
+	if v < 0x80 {
+		return int(v)
+	}
+	return 0x100 - int(v)
+}
+
+// paeth is PNG's Paeth predictor: whichever of a (left), b (up) and c
+// (up-left) is closest to a + b - c.
+func paeth(a, b, c byte) byte {
+	pc := int(c)
+	pa := abs(int(b) - pc)
+	pb := abs(int(a) - pc)
+	pcd := abs(int(a) + int(b) - 2*pc)
+	if pa <= pb && pa <= pcd {
+		return a
+	}
+	if pb <= pcd {
+		return b
+	}
+	return c
+}
+
+// adler32Combine returns the Adler-32 of two runs of bytes joined, given
+// the checksum of each and the length of the second, as zlib's
+// adler32_combine does.
+func adler32Combine(a1, a2 uint32, len2 int64) uint32 {
+	const base = 65521
+	rem := uint32(len2 % base)
+	s1 := a1 & 0xffff
+	s2 := rem * s1 % base
+	s1 += a2&0xffff + base - 1
+	s2 += a1>>16 + a2>>16 + base - rem
+	if s1 >= base {
+		s1 -= base
+	}
+	if s1 >= base {
+		s1 -= base
+	}
+	if s2 >= base<<1 {
+		s2 -= base << 1
+	}
+	if s2 >= base {
+		s2 -= base
+	}
+	return s2<<16 | s1
+}
@@ -0,0 +1,215 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"hash/adler32"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand/v2"
+	"testing"
+)
+
+// randomRGBA returns a w×h image of random premultiplied pixels, opaque
+// if asked, with runs and smooth stretches between the noise so every
+// row filter gets picked somewhere.
+func randomRGBA(rng *rand.Rand, w, h int, opaque bool) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			var c color.NRGBA
+			switch (y / 7) % 3 {
+			case 0: // noise
+				c = color.NRGBA{uint8(rng.IntN(256)), uint8(rng.IntN(256)), uint8(rng.IntN(256)), uint8(rng.IntN(256))}
+			case 1: // gradient
+				c = color.NRGBA{uint8(x), uint8(y), uint8(x + y), uint8(255 - x)}
+			default: // flat
+				c = color.NRGBA{40, 80, 120, 200}
+			}
+			if opaque {
+				c.A = 0xff
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// decodedSame decodes both PNGs and reports whether they hold the same
+// pixels, as NRGBA.
+func decodedSame(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	ia, err := png.Decode(bytes.NewReader(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ib, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ia.Bounds() != ib.Bounds() {
+		return false
+	}
+	r := ia.Bounds()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if color.NRGBAModel.Convert(ia.At(x, y)) != color.NRGBAModel.Convert(ib.At(x, y)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestParallelPNGEncode(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, tc := range []struct {
+		w, h   int
+		opaque bool
+	}{
+		{1, 1, true}, {1, 1, false}, {3, 500, false}, {300, 200, true}, {257, 311, false}, {640, 480, false},
+	} {
+		img := randomRGBA(rng, tc.w, tc.h, tc.opaque)
+		var want bytes.Buffer
+		if err := png.Encode(&want, img); err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range []int{1, 2, 3, 8, 0} {
+			var got bytes.Buffer
+			if err := ParallelPNGEncode(&got, img, n); err != nil {
+				t.Fatalf("%dx%d, %d workers: %v", tc.w, tc.h, n, err)
+			}
+			if !decodedSame(t, got.Bytes(), want.Bytes()) {
+				t.Errorf("%dx%d opaque %v, %d workers: decodes differently from png.Encode", tc.w, tc.h, tc.opaque, n)
+			}
+		}
+	}
+}
+
+func TestParallelPNGEncodeRender(t *testing.T) {
+	// a render large enough for many bands, and a sub-image of it
+	opts := smallOptions(t, WithSize(1200, 900))
+	res, err := Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, img := range []*image.RGBA{res.Image, res.Image.SubImage(image.Rect(101, 77, 1003, 800)).(*image.RGBA)} {
+		var want, got bytes.Buffer
+		png.Encode(&want, img)
+		if err := ParallelPNGEncode(&got, img, 4); err != nil {
+			t.Fatal(err)
+		}
+		if !decodedSame(t, got.Bytes(), want.Bytes()) {
+			t.Errorf("%v: decodes differently from png.Encode", img.Rect)
+		}
+		if g, w := got.Len(), want.Len(); g > w+w/50 {
+			t.Errorf("%v: %d bytes, png.Encode %d", img.Rect, g, w)
+		}
+		// one zlib stream over the IDAT chunks, one per band
+		var idats int
+		for _, c := range pngChunks(t, got.Bytes()) {
+			if c.typ == "IDAT" {
+				idats++
+			}
+		}
+		if idats < 2 {
+			t.Errorf("%v: %d IDAT chunks", img.Rect, idats)
+		}
+	}
+}
+
+func TestParallelPNGEncodeErrors(t *testing.T) {
+	if err := ParallelPNGEncode(new(bytes.Buffer), image.NewRGBA(image.Rect(0, 0, 0, 5)), 2); err == nil {
+		t.Error("empty image: no error")
+	}
+	img := randomRGBA(rand.New(rand.NewPCG(3, 4)), 200, 300, false)
+	broken := errors.New("disk full")
+	for _, after := range []int{0, 10, 100, 5000} {
+		w := &limitWriter{n: after, err: broken}
+		if err := ParallelPNGEncode(w, img, 3); !errors.Is(err, broken) {
+			t.Errorf("writer failing after %d bytes: %v", after, err)
+		}
+	}
+}
+
+// limitWriter accepts n bytes, then fails with err.
+type limitWriter struct {
+	n   int
+	err error
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		k := w.n
+		w.n = 0
+		return k, w.err
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestAdler32Combine(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 6))
+	for _, n := range [][2]int{{0, 0}, {1, 0}, {0, 1}, {5, 7}, {65520, 3}, {70000, 140000}, {3, 65521}} {
+		a, b := make([]byte, n[0]), make([]byte, n[1])
+		for i := range a {
+			a[i] = byte(rng.IntN(256))
+		}
+		for i := range b {
+			b[i] = 0xff // the largest sums
+		}
+		want := adler32.Checksum(append(append([]byte{}, a...), b...))
+		if got := adler32Combine(adler32.Checksum(a), adler32.Checksum(b), int64(len(b))); got != want {
+			t.Errorf("%v: %08x, want %08x", n, got, want)
+		}
+	}
+}
+
+func TestEncodeLargePNG(t *testing.T) {
+	// Encode hands images of a megapixel and up to ParallelPNGEncode
+	img := randomRGBA(rand.New(rand.NewPCG(7, 8)), 1024, 1024, true)
+	var got, want bytes.Buffer
+	if err := Encode(&got, img, "png"); err != nil {
+		t.Fatal(err)
+	}
+	ParallelPNGEncode(&want, img, 0)
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Error("Encode of a megapixel image isn't ParallelPNGEncode's")
+	}
+}
+
+// BenchmarkPNGEncode writes a 4096×3072 render of the default view as a
+// PNG with image/png and with ParallelPNGEncode on every CPU.
+func BenchmarkPNGEncode(b *testing.B) {
+	const width, height = 4096, 3072
+	opts := benchOptions(b, WithSize(width, height), WithViewport(DefaultBounds.FitToImage(width, height)))
+	res, err := Render(context.Background(), opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, parallel := range []bool{false, true} {
+		name := "Serial"
+		if parallel {
+			name = "Parallel"
+		}
+		b.Run(name, func(b *testing.B) {
+			var buf bytes.Buffer
+			b.SetBytes(width * height * 4)
+			for range b.N {
+				buf.Reset()
+				var err error
+				if parallel {
+					err = ParallelPNGEncode(&buf, res.Image, 0)
+				} else {
+					err = png.Encode(&buf, res.Image)
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			sink = buf.Len()
+		})
+	}
+}
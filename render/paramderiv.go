@@ -0,0 +1,27 @@
+package render
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// parameterDerivative returns dz/dc, how fast the orbit of z² + c from
+// 0 moves as c does, at the first iterate past the escape radius, or
+// after maxIter steps if there is none. It follows dz/dc = 2z·dz/dc + 1
+// alongside the orbit with mandelbrotIterations. Its size grows without
+// bound toward the boundary of the set and stays near 1 far outside it;
+// its argument changes smoothly within each band of equal escape count.
+func parameterDerivative(c complex128, maxIter int, bailoutSq float64) complex128 {
+	_, _, dz := mandelbrotIterations(c, maxIter, bailoutSq, true)
+	return dz
+}
+
+// paramDerivT is the ColoringParamDeriv palette position of an orbit
+// from c: the argument of parameterDerivative mapped from (-π, π] to
+// [0,1]. Interior points get 0.
+func paramDerivT(o orbit, c complex128, maxIter int, bailoutSq float64) float64 {
+	if o.iter >= maxIter {
+		return 0
+	}
+	return (cmplx.Phase(parameterDerivative(c, maxIter, bailoutSq)) + math.Pi) / (2 * math.Pi)
+}
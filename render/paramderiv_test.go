@@ -0,0 +1,101 @@
+package render
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+func TestParameterDerivative(t *testing.T) {
+	const maxIter, bailoutSq = 10000, 4
+	// worked by hand: c = 3 escapes at once with dz/dc = 1; 1+i escapes
+	// on the second step, with dz/dc = 2(1+i)·1 + 1
+	for _, tc := range []struct{ c, want complex128 }{
+		{3, 1},
+		{1 + 1i, 3 + 2i},
+	} {
+		if got := parameterDerivative(tc.c, maxIter, bailoutSq); cmplx.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("c=%v: %v, want %v", tc.c, got, tc.want)
+		}
+	}
+
+	// small far out, large near the boundary
+	far := []complex128{3, -3, 2i, 1 + 1i}
+	near := []complex128{0.2501, -0.75 + 0.001i, -1.25 + 0.01i, complex(-0.1, 1.13)}
+	for _, c := range far {
+		if d := cmplx.Abs(parameterDerivative(c, maxIter, bailoutSq)); d > 5 {
+			t.Errorf("c=%v far out: |dz/dc| = %v", c, d)
+		}
+	}
+	for _, c := range near {
+		if d := cmplx.Abs(parameterDerivative(c, maxIter, bailoutSq)); d < 50 {
+			t.Errorf("c=%v near the boundary: |dz/dc| = %v", c, d)
+		}
+	}
+	// and growing steadily along the real axis toward the cusp
+	prev := 0.0
+	for _, x := range []float64{2, 1, 0.5, 0.3, 0.26, 0.251, 0.2501} {
+		d := cmplx.Abs(parameterDerivative(complex(x, 0), maxIter, bailoutSq))
+		if !(d > prev) {
+			t.Errorf("c=%v: |dz/dc| = %v, no larger than %v farther out", x, d, prev)
+		}
+		prev = d
+	}
+}
+
+func TestParameterDerivativeFiniteDifference(t *testing.T) {
+	// the derivative of the escaping iterate, against a difference
+	// quotient of the same iterate
+	for _, c := range []complex128{0.5 + 0.5i, -0.8 + 0.3i, 0.4 - 0.1i, -1.9 + 0.2i} {
+		n, _, _ := mandelbrotIterations(c, 1000, 4, false)
+		if n == 1000 {
+			t.Fatalf("%v doesn't escape", c)
+		}
+		zn := func(c complex128) complex128 {
+			var z complex128
+			for range n + 1 {
+				z = z*z + c
+			}
+			return z
+		}
+		const h = 1e-7
+		want := (zn(c+h) - zn(c-h)) / (2 * h)
+		got := parameterDerivative(c, 1000, 4)
+		if cmplx.Abs(got-want) > 1e-5*cmplx.Abs(want) {
+			t.Errorf("c=%v: %v, difference quotient %v", c, got, want)
+		}
+	}
+}
+
+func TestParamDerivT(t *testing.T) {
+	escaped := orbit{iter: 2, how: resultEscaped}
+	want := (math.Atan2(2, 3) + math.Pi) / (2 * math.Pi)
+	if got := paramDerivT(escaped, 1+1i, 100, 4); math.Abs(got-want) > 1e-12 {
+		t.Errorf("1+i: %v, want %v", got, want)
+	}
+	if got := paramDerivT(orbit{iter: 100, how: resultInterior}, -0.1, 100, 4); got != 0 {
+		t.Errorf("interior: %v", got)
+	}
+	for _, c := range []complex128{3, -3, 0.3 + 0.5i, -0.75 + 0.01i, -2.1} {
+		if got := paramDerivT(escaped, c, 1000, 4); !(got >= 0 && got <= 1) {
+			t.Errorf("c=%v: t = %v", c, got)
+		}
+	}
+}
+
+func TestParamDerivValidate(t *testing.T) {
+	for _, o := range []Option{
+		WithFractalName("julia", complex(-0.8, 0.156)),
+		WithFractal(fractal.Mandelbrot{Z0: 0.1}),
+	} {
+		if _, err := New(WithColoring(ColoringParamDeriv), o); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("%v", err)
+		}
+	}
+	if _, err := New(WithColoring(ColoringParamDeriv)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,284 @@
+// Package render turns a fractal, a window on the complex plane and a palette into an image.
+package render
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+// Render computes the image described by opts.
+//
+// Workers check ctx between rows, so cancellation takes effect within one
+// row's worth of work. A cancelled render returns the partial Result
+// together with a *CancelledError, which matches ErrCancelled and wraps
+// ctx.Err(). Options that fail Validate return its error, matching
+// ErrInvalidOptions, and nothing is rendered.
+func Render(ctx context.Context, opts Options) (*Result, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	r := newRenderer(opts)
+	defer r.Close()
+	return r.render(ctx, r.base)
+}
+
+// reportProgress starts the OnProgress reporter and returns a function that
+// stops it after delivering the final count.
+func reportProgress(opts *Options, done *atomic.Int64) (stop func()) {
+	if opts.OnProgress == nil {
+		return func() {}
+	}
+	interval := opts.ProgressInterval
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+	total := opts.Height
+	quit := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		last := -1
+		for {
+			select {
+			case <-ticker.C:
+				if n := int(done.Load()); n != last {
+					opts.OnProgress(n, total)
+					last = n
+				}
+			case <-quit:
+				opts.OnProgress(int(done.Load()), total)
+				return
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		<-exited
+	}
+}
+
+// deliverRegions starts the OnRegion dispatcher. Workers send finished
+// row indices on the returned channel, which is buffered for every row so
+// sends never block; stop waits until everything sent has been delivered.
+func deliverRegions(fr *frame, opts *Options) (completed chan<- int, stop func()) {
+	if opts.OnRegion == nil {
+		return nil, func() {}
+	}
+	ch := make(chan int, opts.Height)
+	exited := make(chan struct{})
+	emit := func(y int) {
+		r := image.Rect(0, y, opts.Width, y+1)
+		px := make([]color.RGBA, opts.Width)
+		for x := range px {
+			px[x] = fr.rgbaAt(x, y)
+		}
+		opts.OnRegion(r, px)
+	}
+	go func() {
+		defer close(exited)
+		if !opts.OrderedRegions {
+			for y := range ch {
+				emit(y)
+			}
+			return
+		}
+		// reorder buffer: hold rows that finished ahead of the next one due
+		pending := make(map[int]bool)
+		next := 0
+		for y := range ch {
+			pending[y] = true
+			for pending[next] {
+				delete(pending, next)
+				emit(next)
+				next++
+			}
+		}
+	}()
+	return ch, func() {
+		close(ch)
+		<-exited
+	}
+}
+
+// frame holds the output buffers a render writes into.
+type frame struct {
+	img    *image.RGBA  // nil when rendering to nimg
+	nimg   *image.NRGBA // nil unless Options.UseNRGBA
+	iters  []float64    // nil unless retained
+	inside []bool       // nil unless retained
+	ts     []float64    // palette positions before PalettePhase, NaN inside; nil unless AutoContrast
+
+	interior *interiorBlocks // blocks filled without iterating; nil unless Options.Sparse
+}
+
+func newFrame(opts *Options) *frame {
+	fr := &frame{}
+	if r := image.Rect(0, 0, opts.Width, opts.Height); opts.UseNRGBA {
+		fr.nimg = image.NewNRGBA(r)
+	} else {
+		fr.img = image.NewRGBA(r)
+	}
+	if !opts.DiscardBuffers {
+		fr.iters = make([]float64, opts.Width*opts.Height)
+		fr.inside = make([]bool, opts.Width*opts.Height)
+	}
+	if opts.AutoContrast {
+		fr.ts = make([]float64, opts.Width*opts.Height)
+	}
+	return fr
+}
+
+// bounds returns the frame's image rectangle.
+func (fr *frame) bounds() image.Rectangle {
+	if fr.nimg != nil {
+		return fr.nimg.Rect
+	}
+	return fr.img.Rect
+}
+
+// rgbaAt returns pixel (x, y) premultiplied, whichever image holds it.
+func (fr *frame) rgbaAt(x, y int) color.RGBA {
+	if fr.nimg != nil {
+		r, g, b, a := fr.nimg.NRGBAAt(x, y).RGBA()
+		return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	}
+	return fr.img.RGBAAt(x, y)
+}
+
+// computeRow computes the pixels of row y within fr's bounds, writes them
+// into fr and adds them to st.
+//
+// Everything it depends on comes in through opts: the formula is
+// opts.Fractal, which any fractal.Fractal, a fractal.Func among them,
+// can fill, and opts.OnPixel sees each pixel's orbit and color as it is
+// written. fr stays concrete, since the hot loop writes to it for every
+// pixel, and the AVX2 and double-double paths are picked by the
+// concrete type of opts.Fractal.
+func computeRow(fr *frame, y int, opts *Options, st *Stats) {
+	width := opts.Width
+	vp := opts.Viewport()
+	sm := newSmoothing(fractal.Degree(opts.Fractal), opts.Bailout)
+	bailoutSq := opts.Bailout * opts.Bailout
+	b := fr.bounds()
+	dd, deep := newDDView(opts)
+	var batch []orbit
+	if !deep {
+		batch = computeRowAVX2(fr, y, opts)
+	}
+	for x := b.Min.X; x < b.Max.X; x++ {
+		var c complex128
+		var re, im DD
+		finite := true
+		if deep {
+			re, im = dd.pixel(x, y)
+			c = complex(re.Hi, im.Hi)
+		} else {
+			c, finite = opts.Transform.Apply(vp.PixelToComplex(x, y))
+		}
+
+		o := poleOrbit
+		switch {
+		case fr.interior.contains(x, y):
+			o = orbit{iter: opts.MaxIter, how: resultInterior, farIter: opts.MaxIter}
+			st.SkippedPixels++
+		case deep:
+			o = iterateDD(c, re, im, opts.MaxIter, bailoutSq)
+		case finite && batch != nil:
+			o = batch[x-b.Min.X]
+		case finite:
+			o = iterate(opts.Fractal, c, opts.MaxIter, bailoutSq)
+		}
+		iter := o.iter
+		var t float64
+		if opts.Coloring == ColoringFixedTrap {
+			dist := math.Inf(1)
+			if finite {
+				dist = trapDistance(opts.Fractal, c, opts.MaxIter, bailoutSq, opts.TrapPoints)
+			}
+			t = trapT(dist, opts.TrapScale)
+		} else if opts.Coloring == ColoringParamDeriv {
+			t = paramDerivT(o, c, opts.MaxIter, bailoutSq)
+		} else if opts.Coloring == ColoringParabolic {
+			t = parabolicImplosionT(o, c, opts.MaxIter, sm)
+		} else if m, ok := opts.Fractal.(fractal.Mandelbrot); ok && opts.Coloring == ColoringInteriorDistance && iter >= opts.MaxIter {
+			// Validate allows only the Mandelbrot set; any other formula
+			// that gets here unvalidated keeps the plain interior color
+			t = interiorT(interiorDistanceEstimate(m, c, opts.MaxIter, bailoutSq), opts.InteriorScale)
+		} else {
+			t = escapeT(o, opts, sm)
+		}
+		if fr.ts != nil {
+			fr.ts[y*width+x] = t
+			if iter >= opts.MaxIter {
+				fr.ts[y*width+x] = math.NaN()
+			}
+		}
+		if opts.PalettePhase != 0 && iter < opts.MaxIter {
+			t = phaseT(t, opts.PalettePhase)
+		}
+
+		var clr color.RGBA
+		if opts.Coloring == ColoringDebug || opts.Coloring == ColoringAttractor && iter >= opts.MaxIter {
+			if opts.Coloring == ColoringDebug {
+				clr = debugColor(o, t, opts.Palette, sm)
+			} else {
+				_, clr = finiteAttractorColor(opts.Fractal, c, opts.MaxIter, bailoutSq)
+			}
+			clr = opts.Adjust.rgba(clr)
+			if fr.nimg != nil {
+				fr.nimg.Set(x, y, clr)
+			} else {
+				fr.img.SetRGBA(x, y, clr)
+			}
+		} else if opts.Shader != nil {
+			clr = opts.Adjust.rgba(opts.Shader.Shade(x, y, width, opts.Height, c, iter, o.z, t))
+			if fr.nimg != nil {
+				fr.nimg.Set(x, y, clr)
+			} else {
+				fr.img.SetRGBA(x, y, clr)
+			}
+		} else if opts.OutputHSL {
+			clr = hslPixel(t, opts.Palette)
+			if fr.nimg != nil {
+				fr.nimg.Set(x, y, clr)
+			} else {
+				fr.img.SetRGBA(x, y, clr)
+			}
+		} else if fr.nimg != nil {
+			nc := opts.Adjust.nrgba(opts.Palette.InterpolateNRGBA(t))
+			fr.nimg.SetNRGBA(x, y, nc)
+			if opts.OnPixel != nil {
+				r, g, b, a := nc.RGBA()
+				clr = color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+			}
+		} else {
+			clr = opts.Adjust.rgba(opts.Palette.Interpolate(t))
+			fr.img.SetRGBA(x, y, clr)
+		}
+		st.add(iter, opts.MaxIter)
+
+		if fr.iters != nil || opts.OnPixel != nil {
+			inside := iter >= opts.MaxIter
+			nu := float64(opts.MaxIter)
+			if !inside {
+				nu = smoothIter(o, sm)
+			}
+			if fr.iters != nil {
+				i := y*width + x
+				fr.iters[i] = nu
+				fr.inside[i] = inside
+			}
+			if opts.OnPixel != nil {
+				opts.OnPixel(x, y, PixelResult{Iter: iter, Smooth: nu, T: t, Z: o.z, Color: clr})
+			}
+		}
+	}
+}
@@ -0,0 +1,497 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/fractal"
+	"github.com/whalelogic/mandlebrot/palette"
+)
+
+// smallOptions returns valid options for a quick 64×48 render.
+func smallOptions(t *testing.T, opts ...Option) Options {
+	t.Helper()
+	o, err := New(append([]Option{WithSize(64, 48), WithIterations(100)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func TestInteriorDistanceOtherFractalUnvalidated(t *testing.T) {
+	// Validate refuses interior-distance coloring for anything but the
+	// Mandelbrot set; a renderer built without validating must still
+	// not crash on it.
+	o := smallOptions(t)
+	o.Fractal = fractal.ByName("julia", complex(-0.8, 0.156))
+	o.Coloring = ColoringInteriorDistance
+	r := newRenderer(o)
+	defer r.Close()
+	res, err := r.render(context.Background(), r.base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Image == nil {
+		t.Fatal("no image")
+	}
+}
+
+func TestRenderValidates(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		edit func(*Options)
+	}{
+		{"negative width", func(o *Options) { o.Width = -1 }},
+		{"zero height", func(o *Options) { o.Height = 0 }},
+		{"interior distance of a Julia set", func(o *Options) {
+			o.Fractal = fractal.ByName("julia", complex(-0.8, 0.156))
+			o.Coloring = ColoringInteriorDistance
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := smallOptions(t)
+			tc.edit(&o)
+			if _, err := Render(context.Background(), o); !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Render: got %v, want ErrInvalidOptions", err)
+			}
+		})
+	}
+}
+
+func TestOnPixelReconstructsImage(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	o := smallOptions(t)
+	img := image.NewRGBA(image.Rect(0, 0, o.Width, o.Height))
+	iters := make([]float64, o.Width*o.Height)
+	o.OnPixel = func(x, y int, p PixelResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		img.SetRGBA(x, y, p.Color)
+		iters[y*o.Width+x] = p.Smooth
+	}
+	res, err := Render(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != o.Width*o.Height {
+		t.Errorf("%d calls for %d pixels", calls, o.Width*o.Height)
+	}
+	for i := range img.Pix {
+		if img.Pix[i] != res.Image.Pix[i] {
+			p := i / 4
+			t.Fatalf("pixel (%d, %d): %v from OnPixel, %v rendered", p%o.Width, p/o.Width, img.RGBAAt(p%o.Width, p/o.Width), res.Image.RGBAAt(p%o.Width, p/o.Width))
+		}
+	}
+	for i, v := range iters {
+		if v != res.Iters[i] {
+			t.Fatalf("pixel %d: Smooth %v, Iters %v", i, v, res.Iters[i])
+		}
+	}
+}
+
+func TestRenderCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+	// resting 99 times as long as it computes, the render takes seconds
+	o := smallOptions(t, WithSize(800, 600), WithProcs(2), WithThrottle(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := Render(ctx, o)
+	if took := time.Since(start); took > 500*time.Millisecond {
+		t.Errorf("returned %v after the cancel", took-50*time.Millisecond)
+	}
+	var ce *CancelledError
+	if !errors.As(err, &ce) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want a CancelledError wrapping context.Canceled", err)
+	}
+	if ce.Done >= ce.Total {
+		t.Errorf("%d of %d rows done", ce.Done, ce.Total)
+	}
+
+	// the workers are gone once Render has returned, give or take the
+	// scheduler getting round to them
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines before the render, %d after", before, n)
+	}
+}
+
+func TestOnProgressMonotonic(t *testing.T) {
+	var done []int
+	total := 0
+	o := smallOptions(t, WithSize(400, 300), WithProcs(4), WithProgress(func(d, tot int) {
+		// calls come from one goroutine, so no lock
+		done = append(done, d)
+		total = tot
+		time.Sleep(time.Millisecond) // slower than the workers
+	}, time.Microsecond))
+	if _, err := Render(context.Background(), o); err != nil {
+		t.Fatal(err)
+	}
+	if len(done) == 0 {
+		t.Fatal("OnProgress never called")
+	}
+	if total != o.Height {
+		t.Errorf("total %d, want %d rows", total, o.Height)
+	}
+	for i := 1; i < len(done); i++ {
+		if done[i] < done[i-1] {
+			t.Fatalf("done went from %d to %d", done[i-1], done[i])
+		}
+	}
+	if last := done[len(done)-1]; last != o.Height {
+		t.Errorf("last call has done = %d, want %d", last, o.Height)
+	}
+}
+
+func TestOnRegionReconstructsImage(t *testing.T) {
+	for _, ordered := range []bool{false, true} {
+		for _, slow := range []bool{false, true} {
+			o := smallOptions(t, WithProcs(4))
+			img := image.NewRGBA(image.Rect(0, 0, o.Width, o.Height))
+			nextY := 0
+			o.OnRegion = func(rect image.Rectangle, px []color.RGBA) {
+				if ordered && rect.Min.Y != nextY {
+					t.Errorf("ordered: region at row %d, want %d", rect.Min.Y, nextY)
+				}
+				nextY = rect.Max.Y
+				for i, c := range px {
+					img.SetRGBA(rect.Min.X+i%rect.Dx(), rect.Min.Y+i/rect.Dx(), c)
+				}
+				if slow {
+					time.Sleep(time.Millisecond)
+				}
+			}
+			o.OrderedRegions = ordered
+			done := make(chan struct{})
+			var res *Result
+			var err error
+			go func() {
+				defer close(done)
+				res, err = Render(context.Background(), o)
+			}()
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatalf("ordered %v, slow %v: render stuck", ordered, slow)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(img.Pix, res.Image.Pix) {
+				t.Errorf("ordered %v, slow %v: regions differ from the image", ordered, slow)
+			}
+		}
+	}
+}
+
+func TestStatsTinyRender(t *testing.T) {
+	// four pixels sampling 0, 1, 2 and 3 on the real axis: inside, then
+	// escaping at 2, 1 and 0 (see TestMandelbrotIterationsKnownValues)
+	o := smallOptions(t, WithSize(4, 1), WithViewport(coords.Bounds{Xmin: -0.5, Xmax: 3.5, Ymin: -0.5, Ymax: 0.5}))
+	res, err := Render(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := res.Stats
+	if st.Pixels != 4 || st.InsidePixels != 1 || st.InsideFraction != 0.25 {
+		t.Errorf("Pixels %d, InsidePixels %d, InsideFraction %v; want 4, 1, 0.25", st.Pixels, st.InsidePixels, st.InsideFraction)
+	}
+	if st.MinIter != 0 || st.MaxIter != 2 || st.MeanIter != 1 {
+		t.Errorf("MinIter %d, MaxIter %d, MeanIter %v; want 0, 2, 1", st.MinIter, st.MaxIter, st.MeanIter)
+	}
+	if want := []bool{true, false, false, false}; !slices.Equal(res.Inside, want) {
+		t.Errorf("Inside = %v, want %v", res.Inside, want)
+	}
+	if res.Iters[0] != float64(o.MaxIter) {
+		t.Errorf("Iters[0] = %v, want %d for the interior", res.Iters[0], o.MaxIter)
+	}
+}
+
+func TestAllPalettesRender(t *testing.T) {
+	for _, name := range palette.List() {
+		t.Run(name, func(t *testing.T) {
+			o, err := New(WithSize(32, 24), WithPaletteName(name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := Render(context.Background(), o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			img := res.Image
+			if size := img.Bounds().Size(); size != image.Pt(32, 24) {
+				t.Fatalf("image is %v", size)
+			}
+			colors := map[color.RGBA]bool{}
+			for y := range 24 {
+				for x := range 32 {
+					c := img.RGBAAt(x, y)
+					if c.A != 0xff {
+						t.Fatalf("(%d, %d) = %v is not opaque", x, y, c)
+					}
+					colors[c] = true
+				}
+			}
+			if len(colors) < 2 {
+				t.Errorf("every pixel is %v", img.RGBAAt(0, 0))
+			}
+
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, img); err != nil {
+				t.Fatal(err)
+			}
+			back, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for y := range 24 {
+				for x := range 32 {
+					if got, want := color.RGBAModel.Convert(back.At(x, y)), img.RGBAAt(x, y); got != want {
+						t.Fatalf("(%d, %d) decodes as %v, was %v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAsymmetricViewOrientation(t *testing.T) {
+	// the upper half plane and a little below the axis: the period-2 bulb
+	// at -1 sits near the bottom and the upper bulb near the top
+	b := coords.Bounds{Xmin: -2.2, Xmax: 0.6, Ymin: -0.2, Ymax: 1.2}
+	const w, h = 280, 140
+	for _, flip := range []bool{false, true} {
+		inside := make([]bool, w*h)
+		var mu sync.Mutex
+		o := smallOptions(t, WithSize(w, h), WithViewport(b), WithIterations(500), WithFlipY(flip))
+		o.OnPixel = func(x, y int, p PixelResult) {
+			mu.Lock()
+			inside[y*w+x] = p.Iter >= o.MaxIter
+			mu.Unlock()
+		}
+		if _, err := Render(context.Background(), o); err != nil {
+			t.Fatal(err)
+		}
+		// rows of the period-2 bulb's center and the upper bulb's,
+		// counted from the top unless flipped
+		bulb, upper := int((1.2-0)/1.4*h), int((1.2-0.75)/1.4*h)
+		if flip {
+			bulb, upper = h-1-bulb, h-1-upper
+		}
+		bulbX, upperX := int((-1+2.2)/2.8*w), int((-0.12+2.2)/2.8*w)
+		if !inside[bulb*w+bulbX] || !inside[upper*w+upperX] {
+			t.Errorf("flip %v: the bulbs aren't at rows %d and %d", flip, bulb, upper)
+		}
+		// the bulb's mirror image across the middle row, near 1i, is
+		// outside the set
+		if inside[(h-1-bulb)*w+bulbX] {
+			t.Errorf("flip %v: the image is upside down", flip)
+		}
+		// most of the set in view is above the real axis, about 2.6 to 1
+		var top, bottom int
+		for i, in := range inside {
+			if !in {
+				continue
+			}
+			if row := i / w; flip == (row >= bulb) {
+				top++
+			} else {
+				bottom++
+			}
+		}
+		if top < 2*bottom {
+			t.Errorf("flip %v: %d interior pixels above the axis and %d below", flip, top, bottom)
+		}
+	}
+	if got := (Options{Bounds: b, Width: w, Height: h}).Viewport().PixelToComplex(0, 0); imag(got) < 1.19 {
+		t.Errorf("row 0 samples %v, want the top of the window", got)
+	}
+}
+
+func TestSymmetricViewSymmetricImage(t *testing.T) {
+	b := coords.Bounds{Xmin: -2.2, Xmax: 1, Ymin: -1.3, Ymax: 1.3}
+	for _, h := range []int{120, 121} {
+		res, err := Render(context.Background(), smallOptions(t, WithSize(160, h), WithViewport(b), WithIterations(300)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img := res.Image
+		for y := range h / 2 {
+			top := img.Pix[y*img.Stride : y*img.Stride+160*4]
+			bottom := img.Pix[(h-1-y)*img.Stride : (h-1-y)*img.Stride+160*4]
+			if !bytes.Equal(top, bottom) {
+				t.Fatalf("height %d: rows %d and %d differ", h, y, h-1-y)
+			}
+		}
+	}
+}
+
+func TestRenderDeterminism(t *testing.T) {
+	for _, opts := range [][]Option{
+		nil,
+		{WithViewport(coords.Bounds{Xmin: -0.7445, Xmax: -0.7425, Ymin: 0.1305, Ymax: 0.132}), WithIterations(2000)},
+		{WithColoring(ColoringBlend), WithRotation(30)},
+	} {
+		o := smallOptions(t, append([]Option{WithSize(128, 96), WithProcs(4)}, opts...)...)
+		a, err := Render(context.Background(), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := Render(context.Background(), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range a.Image.Pix {
+			if a.Image.Pix[i] != b.Image.Pix[i] {
+				p := i / 4
+				t.Fatalf("pixel (%d, %d): %v, then %v", p%128, p/128, a.Image.RGBAAt(p%128, p/128), b.Image.RGBAAt(p%128, p/128))
+			}
+		}
+	}
+}
+
+func TestZ0(t *testing.T) {
+	plain, err := Render(context.Background(), smallOptions(t, WithIterations(500)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// starting from 0 is the standard render
+	zero, err := Render(context.Background(), smallOptions(t, WithIterations(500), WithFractal(fractal.Mandelbrot{Z0: 0})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(plain.Image.Pix, zero.Image.Pix) {
+		t.Error("z0 = 0 differs from the standard render")
+	}
+
+	// elsewhere, the interior is that of orbits from z0
+	f := fractal.Mandelbrot{Z0: 0.3 + 0.1i}
+	o := smallOptions(t, WithIterations(500), WithFractal(f))
+	res, err := Render(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(res.Image.Pix, plain.Image.Pix) {
+		t.Error("z0 = 0.3+0.1i looks like the standard render")
+	}
+	vp := o.Viewport()
+	for y := range o.Height {
+		for x := range o.Width {
+			c := vp.PixelToComplex(x, y)
+			z := f.Z0
+			n := 0
+			for ; n < 500 && real(z)*real(z)+imag(z)*imag(z) <= 4; n++ {
+				z = z*z + c
+			}
+			if in := res.Inside[y*o.Width+x]; in != (n == 500) {
+				t.Fatalf("pixel (%d, %d) at %v: inside %v, but the orbit from z0 ran %d steps", x, y, c, in, n)
+			}
+		}
+	}
+}
+
+func TestComputeRowInjectedFractal(t *testing.T) {
+	// A fractal.Func stands in for the formula and sends every point past
+	// the radius at once. It sees each pixel's point in order along the
+	// row; several times in a row, as the orbit is carried on past the
+	// radius for smoothing (see smoothRadiusSq).
+	var seen []complex128
+	jump := fractal.Func(func(z, c complex128) complex128 {
+		seen = append(seen, c)
+		return 100
+	})
+	opts := smallOptions(t, WithFractal(jump), WithViewport(coords.Bounds{Xmin: -1, Xmax: 3, Ymin: -2, Ymax: 1})).withDefaults()
+	var results []PixelResult
+	opts.OnPixel = func(x, y int, r PixelResult) { results = append(results, r) }
+	fr := newFrame(&opts)
+	vp := opts.Viewport()
+	var st Stats
+	for _, y := range []int{0, 17, opts.Height - 1} {
+		seen, results = seen[:0], results[:0]
+		computeRow(fr, y, &opts, &st)
+		seen = slices.Compact(seen)
+		if len(seen) != opts.Width || len(results) != opts.Width {
+			t.Fatalf("row %d: %d points and %d pixels for %d columns", y, len(seen), len(results), opts.Width)
+		}
+		for x, c := range seen {
+			if want := vp.PixelToComplex(x, y); c != want {
+				t.Errorf("pixel (%d,%d) iterated %v, want %v", x, y, c, want)
+			}
+			r := results[x]
+			if r.Iter != 0 || r.Z != 100 {
+				t.Errorf("pixel (%d,%d): iteration %d, z %v, want 0 and 100", x, y, r.Iter, r.Z)
+			}
+			if r.T != results[0].T {
+				t.Errorf("pixel (%d,%d): palette position %v, pixel 0 had %v", x, y, r.T, results[0].T)
+			}
+			if want := opts.Palette.Interpolate(r.T); r.Color != want || fr.rgbaAt(x, y) != want {
+				t.Errorf("pixel (%d,%d): color %v, written %v, palette gives %v", x, y, r.Color, fr.rgbaAt(x, y), want)
+			}
+		}
+	}
+}
+
+func TestComputeRowInjectedPalette(t *testing.T) {
+	// z steps by 3+Re(c) each time, so the escape count falls from left
+	// to right; with discrete coloring the palette position follows it,
+	// and each pixel is the palette's color there.
+	walk := fractal.Func(func(z, c complex128) complex128 { return z + complex(3+real(c), 0) })
+	opts := smallOptions(t, WithFractal(walk), WithPalette(greyRamp()), WithColoring(ColoringDiscrete),
+		WithViewport(coords.Bounds{Xmin: -2.9, Xmax: -1, Ymin: -1, Ymax: 1})).withDefaults()
+	var results []PixelResult
+	opts.OnPixel = func(x, y int, r PixelResult) { results = append(results, r) }
+	fr := newFrame(&opts)
+	var st Stats
+	computeRow(fr, 5, &opts, &st)
+	for x, r := range results {
+		if x > 0 && (r.Iter > results[x-1].Iter || r.T > results[x-1].T) {
+			t.Errorf("pixel %d: iteration %d at %v after %d at %v", x, r.Iter, r.T, results[x-1].Iter, results[x-1].T)
+		}
+		if want := opts.Palette.Interpolate(r.T); r.Color != want || fr.rgbaAt(x, 5) != want {
+			t.Errorf("pixel %d: color %v, written %v, palette gives %v", x, r.Color, fr.rgbaAt(x, 5), want)
+		}
+	}
+	if first, last := results[0], results[len(results)-1]; first.Iter == last.Iter {
+		t.Errorf("escape count %d across the whole row", first.Iter)
+	}
+	if st.Pixels != opts.Width || st.InsidePixels != 0 {
+		t.Errorf("stats counted %d pixels, %d inside, want %d and 0", st.Pixels, st.InsidePixels, opts.Width)
+	}
+}
+
+func TestQuaternionZeroSliceRender(t *testing.T) {
+	// the quaternion formula at slice 0, 0 goes through the generic
+	// kernel, without the cardioid and cycle shortcuts, and still draws
+	// the standard render
+	for _, b := range []coords.Bounds{DefaultBounds, {Xmin: -0.76, Xmax: -0.74, Ymin: 0.09, Ymax: 0.11}} {
+		plain, err := Render(context.Background(), smallOptions(t, WithViewport(b), WithIterations(500)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := Render(context.Background(), smallOptions(t, WithViewport(b), WithIterations(500), WithFractal(fractal.Quaternion{})))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(plain.Image.Pix, q.Image.Pix) || !slices.Equal(plain.Iters, q.Iters) {
+			t.Errorf("%v: quaternion at slice 0, 0 differs from the standard render", b)
+		}
+	}
+}
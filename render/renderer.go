@@ -0,0 +1,268 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"image"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/coords"
+)
+
+// Renderer renders a sequence of frames that share one configuration. It
+// keeps its worker goroutines and output buffers between frames, so a
+// steady stream of same-sized frames allocates nothing.
+//
+// Frames are independent: every frame recomputes every pixel from its own
+// Options, so a cancelled or failed frame leaves nothing behind that the
+// next one could pick up, and a palette change takes effect immediately.
+type Renderer struct {
+	mu     sync.Mutex // serializes frames and Close
+	base   Options
+	closed bool
+	work   chan int // a worker index per worker per frame
+	wg     sync.WaitGroup
+
+	// per-frame state, set before the workers are started and read back
+	// after they have all finished
+	ctx       context.Context
+	opts      Options
+	fr        *frame
+	chunk     int          // rows per work unit
+	next      atomic.Int64 // next row to hand out
+	done      atomic.Int64 // rows completed
+	completed chan<- int
+	stats     []Stats // per worker
+	start     time.Time
+	timings   [][]TaskTiming // per worker, with RecordTimings
+	res       Result
+}
+
+// NewRenderer validates opts and starts opts.Procs workers. The size and
+// viewport in opts are only defaults; each frame supplies its own.
+// Close stops the workers.
+func NewRenderer(opts Options) (*Renderer, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return newRenderer(opts), nil
+}
+
+func newRenderer(opts Options) *Renderer {
+	opts = opts.withDefaults()
+	r := &Renderer{
+		base:    opts,
+		work:    make(chan int),
+		stats:   make([]Stats, opts.Procs),
+		timings: make([][]TaskTiming, opts.Procs),
+	}
+	for range opts.Procs {
+		go r.worker()
+	}
+	return r
+}
+
+// Render renders vp with the Renderer's configuration, with overrides
+// applied for this frame only. The worker count is fixed when the
+// Renderer is created and cannot be overridden.
+//
+// The returned Result and its buffers belong to the Renderer and are
+// overwritten by the next frame; copy anything that must outlive it.
+// Cancellation behaves as for the package-level Render.
+func (r *Renderer) Render(ctx context.Context, vp coords.Viewport, overrides ...Option) (*Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, ErrClosed
+	}
+	opts := r.base
+	opts.Bounds, opts.Rotation, opts.FlipY = vp.Bounds, vp.Rotation, vp.FlipY
+	opts.HighPrecisionCoords = vp.HighPrecision
+	opts.Width, opts.Height = vp.Width, vp.Height
+	if len(overrides) > 0 {
+		// kept out of line: applying an Option moves its target to the
+		// heap, which plain frames shouldn't pay for
+		var err error
+		if opts, err = applyOptions(opts, overrides); err != nil {
+			return nil, err
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if pal := opts.Palette; pal == r.base.Palette {
+		// frozen by newRenderer and not overridden; freezing it again
+		// would copy it every frame
+		opts.Palette = nil
+		opts = opts.withDefaults()
+		opts.Palette = pal
+	} else {
+		opts = opts.withDefaults()
+	}
+	return r.render(ctx, opts)
+}
+
+// applyOptions returns o with opts applied, joining their errors.
+func applyOptions(o Options, opts []Option) (Options, error) {
+	var errs []error
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return o, errors.Join(errs...)
+}
+
+// Close stops the workers. Render fails with ErrClosed afterwards.
+func (r *Renderer) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		r.closed = true
+		close(r.work)
+	}
+}
+
+// render runs one frame on the workers. The caller holds r.mu, or owns r
+// outright.
+func (r *Renderer) render(ctx context.Context, opts Options) (*Result, error) {
+	start := time.Now()
+	opts.Procs = len(r.stats)
+	r.opts = opts // its address is taken below, which would move opts to the heap
+	if opts.Metrics != nil {
+		opts.Metrics.RenderStarted()
+	}
+
+	fr := r.fr
+	if fr == nil || fr.bounds().Dx() != opts.Width || fr.bounds().Dy() != opts.Height ||
+		(fr.iters == nil) != opts.DiscardBuffers || (fr.nimg != nil) != opts.UseNRGBA || (fr.ts != nil) != opts.AutoContrast {
+		fr = newFrame(&r.opts)
+	}
+	fr.interior = nil
+	if opts.Sparse {
+		fr.interior = newInteriorBlocks(ctx, &r.opts, fr.bounds())
+	}
+	r.ctx, r.fr, r.start = ctx, fr, start
+	r.chunk = opts.RowsPerChunk
+	if r.chunk <= 0 {
+		r.chunk = max(1, opts.Height/opts.Procs/4)
+	}
+	r.next.Store(0)
+	r.done.Store(0)
+
+	completed, stopRegions := deliverRegions(fr, &r.opts)
+	r.completed = completed
+	stopProgress := reportProgress(&r.opts, &r.done)
+
+	r.wg.Add(len(r.stats))
+	for i := range r.stats {
+		r.work <- i
+	}
+	r.wg.Wait()
+	stopRegions()
+	stopProgress()
+	if int(r.done.Load()) == opts.Height {
+		if opts.AutoContrast {
+			fr.autoContrast(&r.opts)
+		}
+		if fr.nimg != nil {
+			opts.Bloom.apply(fr.nimg.Pix, fr.nimg.Stride, opts.Width, opts.Height, false, opts.Procs)
+		} else {
+			opts.Bloom.apply(fr.img.Pix, fr.img.Stride, opts.Width, opts.Height, true, opts.Procs)
+		}
+	}
+
+	timings := r.res.Timings[:0]
+	r.res = Result{Image: fr.img, NRGBA: fr.nimg, Iters: fr.iters, Inside: fr.inside, Options: opts}
+	if opts.RecordTimings {
+		for _, ts := range r.timings {
+			timings = append(timings, ts...)
+		}
+		slices.SortFunc(timings, func(a, b TaskTiming) int { return a.Rect.Min.Y - b.Rect.Min.Y })
+		r.res.Timings = timings
+	}
+	for _, st := range r.stats {
+		r.res.Stats.merge(st)
+	}
+	r.res.Stats.finish(time.Since(start))
+	r.ctx, r.completed = nil, nil
+	var err error
+	if n := int(r.done.Load()); n < opts.Height {
+		err = &CancelledError{Done: n, Total: opts.Height, Err: ctx.Err()}
+	}
+	if opts.Metrics != nil {
+		st := &r.res.Stats
+		opts.Metrics.RenderDone(st.Pixels, st.Elapsed, st.busy, opts.Procs, err)
+	}
+	return &r.res, err
+}
+
+// worker runs its share of each frame until Close.
+func (r *Renderer) worker() {
+	for i := range r.work {
+		st := &r.stats[i]
+		*st = Stats{}
+		r.timings[i] = r.timings[i][:0]
+		start := time.Now()
+		th := newThrottler(r.opts.Throttle)
+		for {
+			rr, ok := r.claim()
+			if !ok {
+				break
+			}
+			var t0 time.Time
+			if r.opts.RecordTimings {
+				t0 = time.Now()
+			}
+			if !r.computeRowRange(rr, st, th) {
+				break
+			}
+			if r.opts.RecordTimings {
+				r.timings[i] = append(r.timings[i], TaskTiming{
+					Rect:    image.Rect(0, rr.start, r.opts.Width, rr.end),
+					Worker:  i,
+					Start:   t0.Sub(r.start),
+					Elapsed: time.Since(t0),
+				})
+			}
+		}
+		st.busy = time.Since(start)
+		r.wg.Done()
+	}
+}
+
+// rowRange is a work unit: rows [start, end) of the frame.
+type rowRange struct{ start, end int }
+
+// claim hands out the next chunk of rows, reporting false once the frame
+// has none left.
+func (r *Renderer) claim() (rowRange, bool) {
+	end := int(r.next.Add(int64(r.chunk)))
+	start := end - r.chunk
+	if start >= r.opts.Height {
+		return rowRange{}, false
+	}
+	return rowRange{start, min(end, r.opts.Height)}, true
+}
+
+// computeRowRange renders the rows of rr, checking for cancellation before
+// each one and resting after each one as th says. It reports false if
+// the frame was cancelled.
+func (r *Renderer) computeRowRange(rr rowRange, st *Stats, th *throttler) bool {
+	for y := rr.start; y < rr.end; y++ {
+		if r.ctx.Err() != nil {
+			return false
+		}
+		t0 := time.Now()
+		computeRow(r.fr, y, &r.opts, st)
+		th.rest(r.ctx, time.Since(t0))
+		r.done.Add(1)
+		if r.completed != nil {
+			r.completed <- y
+		}
+	}
+	return true
+}
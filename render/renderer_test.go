@@ -0,0 +1,52 @@
+package render
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRendererFrameAllocs(t *testing.T) {
+	opts := smallOptions(t, WithDiscardBuffers(true), WithProcs(4))
+	r, err := NewRenderer(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	vp := opts.Viewport()
+	if _, err := r.Render(context.Background(), vp); err != nil {
+		t.Fatal(err)
+	}
+	allocs := testing.AllocsPerRun(20, func() {
+		if _, err := r.Render(context.Background(), vp); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("%v allocations per frame after the first, want 0", allocs)
+	}
+}
+
+// BenchmarkRendererFrame times steady-state frames on a reused Renderer;
+// after the first frame it should not allocate.
+func BenchmarkRendererFrame(b *testing.B) {
+	opts := benchOptions(b, WithSize(640, 480))
+	r, err := NewRenderer(opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer r.Close()
+	vp := opts.Viewport()
+	if _, err := r.Render(context.Background(), vp); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(vp.Width * vp.Height * 4))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		res, err := r.Render(context.Background(), vp)
+		if err != nil {
+			b.Fatal(err)
+		}
+		sink = res
+	}
+}
@@ -0,0 +1,98 @@
+package render
+
+import (
+	"image"
+	"time"
+)
+
+// Result is everything a render produced.
+type Result struct {
+	// Image holds the pixels, or is nil when Options.UseNRGBA put them in
+	// NRGBA instead.
+	Image *image.RGBA
+	NRGBA *image.NRGBA
+
+	// Iters holds the continuous escape count of every pixel in row-major
+	// order (MaxIter for interior points) and Inside the interior mask.
+	// Both are nil when Options.DiscardBuffers is set.
+	Iters  []float64
+	Inside []bool
+
+	Stats Stats
+
+	// Timings holds a TaskTiming per work unit, top to bottom, when
+	// Options.RecordTimings is set.
+	Timings []TaskTiming
+
+	// Options are the parameters actually used, with defaults filled in.
+	Options Options
+}
+
+// Output returns whichever of Image and NRGBA holds the pixels.
+func (r *Result) Output() image.Image {
+	if r.NRGBA != nil {
+		return r.NRGBA
+	}
+	return r.Image
+}
+
+// Stats summarizes a render. The iteration figures cover escaped pixels only.
+type Stats struct {
+	Pixels         int
+	InsidePixels   int
+	SkippedPixels  int // interior pixels Options.Sparse filled without iterating
+	InsideFraction float64
+	MinIter        int
+	MaxIter        int
+	MeanIter       float64
+	Elapsed        time.Duration
+
+	sumIter float64
+	busy    time.Duration // time spent computing rows, summed over workers
+}
+
+// add records one pixel.
+func (s *Stats) add(iter, maxIter int) {
+	s.Pixels++
+	if iter >= maxIter {
+		s.InsidePixels++
+		return
+	}
+	escaped := s.Pixels - s.InsidePixels
+	if escaped == 1 || iter < s.MinIter {
+		s.MinIter = iter
+	}
+	if escaped == 1 || iter > s.MaxIter {
+		s.MaxIter = iter
+	}
+	s.sumIter += float64(iter)
+}
+
+// merge folds the per-worker totals o into s.
+func (s *Stats) merge(o Stats) {
+	se, oe := s.Pixels-s.InsidePixels, o.Pixels-o.InsidePixels
+	if oe > 0 {
+		if se == 0 || o.MinIter < s.MinIter {
+			s.MinIter = o.MinIter
+		}
+		if se == 0 || o.MaxIter > s.MaxIter {
+			s.MaxIter = o.MaxIter
+		}
+	}
+	s.Pixels += o.Pixels
+	s.InsidePixels += o.InsidePixels
+	s.SkippedPixels += o.SkippedPixels
+	s.sumIter += o.sumIter
+	s.busy += o.busy
+}
+
+// finish derives the fractions and means once all pixels are in.
+func (s *Stats) finish(elapsed time.Duration) {
+	s.Elapsed = elapsed
+	if s.Pixels > 0 {
+		s.InsideFraction = float64(s.InsidePixels) / float64(s.Pixels)
+	}
+	if escaped := s.Pixels - s.InsidePixels; escaped > 0 {
+		s.MeanIter = s.sumIter / float64(escaped)
+	}
+}
@@ -0,0 +1,67 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RenderRows renders rows [start, end) of the image described by opts,
+// returning an image whose bounds are those rows of the full frame. The
+// pixels are exactly those Render would produce for the same rows, so
+// strips rendered separately, even on different machines, assemble into
+// the same image. Callbacks and Metrics in opts are ignored.
+func RenderRows(ctx context.Context, opts Options, start, end int) (*image.RGBA, error) {
+	if start < 0 || end > opts.Height || start >= end {
+		return nil, fmt.Errorf("%w: rows [%d, %d): outside the %d-row image", ErrInvalidOptions, start, end, opts.Height)
+	}
+	return RenderRect(ctx, opts, image.Rect(0, start, opts.Width, end))
+}
+
+// RenderRect is RenderRows for any rectangle r of the image, such as a
+// tile. Bloom and auto contrast, which need the whole frame, are left
+// out, so the pixels match Render's only without them.
+func RenderRect(ctx context.Context, opts Options, r image.Rectangle) (*image.RGBA, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if r.Empty() || !r.In(image.Rect(0, 0, opts.Width, opts.Height)) {
+		return nil, fmt.Errorf("%w: rectangle %v: outside the %dx%d image", ErrInvalidOptions, r, opts.Width, opts.Height)
+	}
+	opts = opts.withDefaults()
+	opts.OnPixel, opts.OnProgress, opts.OnRegion, opts.Metrics = nil, nil, nil, nil
+	fr := &frame{img: image.NewRGBA(r)}
+	if opts.Sparse {
+		fr.interior = newInteriorBlocks(ctx, &opts, r)
+	}
+
+	var next, done atomic.Int64
+	next.Store(int64(r.Min.Y))
+	var wg sync.WaitGroup
+	for range min(opts.Procs, r.Dy()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var st Stats
+			th := newThrottler(opts.Throttle)
+			for {
+				y := int(next.Add(1)) - 1
+				if y >= r.Max.Y || ctx.Err() != nil {
+					return
+				}
+				t0 := time.Now()
+				computeRow(fr, y, &opts, &st)
+				th.rest(ctx, time.Since(t0))
+				done.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := int(done.Load()); n < r.Dy() {
+		return fr.img, &CancelledError{Done: n, Total: r.Dy(), Err: ctx.Err()}
+	}
+	return fr.img, nil
+}
@@ -0,0 +1,136 @@
+package render
+
+import (
+	"context"
+	"image"
+	"sync"
+	"sync/atomic"
+)
+
+// SparseBlock is the side of the square blocks Options.Sparse fills
+// without iterating.
+const SparseBlock = 8
+
+// identifyInteriorBlocks cuts r into blockSize×blockSize blocks, the
+// last row and column cut short by its edges, and returns those the set
+// encloses. It samples a coarse grid first: every blockSize-th row and
+// column of pixels, and the last ones. A block's ring is closed when
+// the samples all around it, its own top row and left column and the
+// next block's, are interior. The Mandelbrot set and filled Julia sets
+// have no holes, so nothing inside a closed ring escapes unless it
+// slips between two samples; a filament that does usually touches samples
+// nearby, so a block counts only if its neighbours' rings are closed
+// too. The rings share their sides, so the grid costs about two pixels
+// in blockSize. The lines are sampled by opts.Procs goroutines; if ctx
+// is cancelled it returns what it has found so far.
+func identifyInteriorBlocks(ctx context.Context, opts *Options, r image.Rectangle, blockSize int) []image.Rectangle {
+	cols := (r.Dx() + blockSize - 1) / blockSize
+	rows := (r.Dy() + blockSize - 1) / blockSize
+	// grid line i lies on pixel column gx(i), line j on row gy(j)
+	gx := func(i int) int { return min(r.Min.X+i*blockSize, r.Max.X-1) }
+	gy := func(j int) int { return min(r.Min.Y+j*blockSize, r.Max.Y-1) }
+	hlines := make([][]bool, rows+1) // by j, then x-r.Min.X
+	vlines := make([][]bool, cols+1) // by i, then y-r.Min.Y
+
+	vp := opts.Viewport()
+	bailoutSq := opts.Bailout * opts.Bailout
+	inside := func(x, y int) bool {
+		return iterate(opts.Fractal, vp.PixelToComplex(x, y), opts.MaxIter, bailoutSq).iter >= opts.MaxIter
+	}
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range max(1, opts.Procs) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				k := int(next.Add(1)) - 1
+				if k >= len(hlines)+len(vlines) || ctx.Err() != nil {
+					return
+				}
+				if k < len(hlines) {
+					line := make([]bool, r.Dx())
+					for x := range line {
+						line[x] = inside(r.Min.X+x, gy(k))
+					}
+					hlines[k] = line
+				} else {
+					i := k - len(hlines)
+					line := make([]bool, r.Dy())
+					for y := range line {
+						line[y] = inside(gx(i), r.Min.Y+y)
+					}
+					vlines[i] = line
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// all reports whether line holds only interior samples in [from, to]
+	all := func(line []bool, from, to int) bool {
+		if line == nil {
+			return false
+		}
+		for _, in := range line[from : to+1] {
+			if !in {
+				return false
+			}
+		}
+		return true
+	}
+	closed := make([]bool, rows*cols) // by block, whether its ring is all interior
+	for j := range rows {
+		y0, y1 := gy(j)-r.Min.Y, gy(j+1)-r.Min.Y
+		for i := range cols {
+			x0, x1 := gx(i)-r.Min.X, gx(i+1)-r.Min.X
+			closed[j*cols+i] = all(hlines[j], x0, x1) && all(hlines[j+1], x0, x1) && all(vlines[i], y0, y1) && all(vlines[i+1], y0, y1)
+		}
+	}
+	var blocks []image.Rectangle
+	for j := range rows {
+	block:
+		for i := range cols {
+			for nj := max(j-1, 0); nj <= min(j+1, rows-1); nj++ {
+				for ni := max(i-1, 0); ni <= min(i+1, cols-1); ni++ {
+					if !closed[nj*cols+ni] {
+						continue block
+					}
+				}
+			}
+			x, y := r.Min.X+i*blockSize, r.Min.Y+j*blockSize
+			blocks = append(blocks, image.Rect(x, y, min(x+blockSize, r.Max.X), min(y+blockSize, r.Max.Y)))
+		}
+	}
+	return blocks
+}
+
+// interiorBlocks looks up whether a pixel lies in one of the blocks
+// identifyInteriorBlocks found. A nil *interiorBlocks holds none.
+type interiorBlocks struct {
+	origin image.Point
+	cols   int
+	in     []bool // by block, row-major
+}
+
+// newInteriorBlocks finds the interior blocks of r for Options.Sparse.
+func newInteriorBlocks(ctx context.Context, opts *Options, r image.Rectangle) *interiorBlocks {
+	ib := &interiorBlocks{
+		origin: r.Min,
+		cols:   (r.Dx() + SparseBlock - 1) / SparseBlock,
+	}
+	ib.in = make([]bool, ib.cols*((r.Dy()+SparseBlock-1)/SparseBlock))
+	for _, b := range identifyInteriorBlocks(ctx, opts, r, SparseBlock) {
+		p := b.Min.Sub(r.Min).Div(SparseBlock)
+		ib.in[p.Y*ib.cols+p.X] = true
+	}
+	return ib
+}
+
+// contains reports whether pixel (x, y) lies in an interior block.
+func (ib *interiorBlocks) contains(x, y int) bool {
+	if ib == nil {
+		return false
+	}
+	return ib.in[(y-ib.origin.Y)/SparseBlock*ib.cols+(x-ib.origin.X)/SparseBlock]
+}
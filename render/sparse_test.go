@@ -0,0 +1,142 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"image"
+	"slices"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+// sparseAndFull renders opts with and without Sparse.
+func sparseAndFull(t *testing.T, opts Options) (sparse, full *Result) {
+	t.Helper()
+	var err error
+	opts.Sparse = false
+	if full, err = Render(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	opts.Sparse = true
+	if sparse, err = Render(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	return sparse, full
+}
+
+func TestSparseCardioid(t *testing.T) {
+	// centered on the main cardioid, which fills most of the window
+	opts := smallOptions(t, WithSize(400, 400), WithIterations(300),
+		WithViewport(coords.Bounds{Xmin: -0.85, Xmax: 0.35, Ymin: -0.6, Ymax: 0.6}))
+	sparse, full := sparseAndFull(t, opts)
+	if !slices.Equal(sparse.Image.Pix, full.Image.Pix) {
+		t.Error("sparse image differs from the full render")
+	}
+	if !slices.Equal(sparse.Iters, full.Iters) || !slices.Equal(sparse.Inside, full.Inside) {
+		t.Error("sparse escape counts differ from the full render")
+	}
+	if n := sparse.Stats.SkippedPixels; n < 400*400/2 {
+		t.Errorf("skipped %d of %d pixels, want at least half", n, 400*400)
+	}
+	if full.Stats.SkippedPixels != 0 {
+		t.Errorf("the full render skipped %d pixels", full.Stats.SkippedPixels)
+	}
+}
+
+func TestSparseMatchesFull(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Option
+	}{
+		// bulbs joined by thin filaments, at sizes the blocks don't divide
+		{"seahorse valley", []Option{WithSize(203, 151), WithViewport(coords.Bounds{Xmin: -0.8, Xmax: -0.7, Ymin: 0.05, Ymax: 0.15})}},
+		{"period-2 bulb", []Option{WithSize(180, 130), WithViewport(coords.Bounds{Xmin: -1.4, Xmax: -0.6, Ymin: -0.3, Ymax: 0.3})}},
+		{"julia", []Option{WithSize(160, 120), WithFractalName("julia", complex(-0.4, 0.1))}},
+		{"discrete coloring", []Option{WithSize(160, 120), WithColoring(ColoringDiscrete)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sparse, full := sparseAndFull(t, smallOptions(t, append(tc.opts, WithIterations(400))...))
+			if !slices.Equal(sparse.Image.Pix, full.Image.Pix) {
+				t.Error("sparse image differs from the full render")
+			}
+			if sparse.Stats.SkippedPixels == 0 {
+				t.Error("no pixels skipped")
+			}
+		})
+	}
+}
+
+func TestSparseRenderRect(t *testing.T) {
+	opts := smallOptions(t, WithSize(200, 150), WithIterations(200), WithSparse(true))
+	full, err := Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a tile off the block grid of the full frame
+	r := image.Rect(37, 21, 151, 130)
+	tile, err := RenderRect(context.Background(), opts, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if got, want := tile.RGBAAt(x, y), full.Image.RGBAAt(x, y); got != want {
+				t.Fatalf("(%d, %d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestIdentifyInteriorBlocks(t *testing.T) {
+	opts := smallOptions(t, WithSize(100, 75), WithIterations(200),
+		WithViewport(coords.Bounds{Xmin: -0.9, Xmax: 0.3, Ymin: -0.45, Ymax: 0.45})).withDefaults()
+	r := image.Rect(10, 5, 100, 75)
+	blocks := identifyInteriorBlocks(context.Background(), &opts, r, 8)
+	if len(blocks) == 0 {
+		t.Fatal("no interior blocks")
+	}
+	vp := opts.Viewport()
+	bailoutSq := opts.Bailout * opts.Bailout
+	for _, b := range blocks {
+		// on the grid of r, at most 8 square, inside r
+		if off := b.Min.Sub(r.Min); off.X%8 != 0 || off.Y%8 != 0 || !b.In(r) || b.Dx() > 8 || b.Dy() > 8 {
+			t.Fatalf("block %v off the grid of %v", b, r)
+		}
+		if b.Dx() < 8 && b.Max.X != r.Max.X || b.Dy() < 8 && b.Max.Y != r.Max.Y {
+			t.Fatalf("block %v cut short away from the edge", b)
+		}
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if iterate(opts.Fractal, vp.PixelToComplex(x, y), opts.MaxIter, bailoutSq).iter < opts.MaxIter {
+					t.Fatalf("pixel (%d, %d) of block %v escapes", x, y, b)
+				}
+			}
+		}
+	}
+
+	// nothing is inside a window outside the set
+	opts.Bounds = coords.Bounds{Xmin: 1, Xmax: 2, Ymin: 1, Ymax: 2}
+	if blocks := identifyInteriorBlocks(context.Background(), &opts, r, 8); len(blocks) != 0 {
+		t.Errorf("%d blocks outside the set", len(blocks))
+	}
+	// and a cancelled search finds nothing
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	opts.Bounds = coords.Bounds{Xmin: -0.5, Xmax: 0, Ymin: -0.25, Ymax: 0.25}
+	if blocks := identifyInteriorBlocks(ctx, &opts, r, 8); len(blocks) != 0 {
+		t.Errorf("%d blocks after cancelling", len(blocks))
+	}
+}
+
+func TestSparseValidate(t *testing.T) {
+	for _, c := range []Coloring{ColoringDebug, ColoringAttractor, ColoringFixedTrap, ColoringInteriorDistance} {
+		if _, err := New(WithSize(64, 48), WithColoring(c), WithSparse(true)); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("%s coloring: %v", c, err)
+		}
+	}
+	if _, err := New(WithSize(64, 48), WithFractal(fractal.Mandelbrot{}), WithSparse(true)); err != nil {
+		t.Error(err)
+	}
+}
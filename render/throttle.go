@@ -0,0 +1,47 @@
+package render
+
+import (
+	"context"
+	"time"
+)
+
+// minThrottleRest is the shortest rest a throttler takes; shorter rests
+// are saved up, as a sleep costs about as much as it saves below it.
+const minThrottleRest = time.Millisecond
+
+// throttler keeps one worker to Options.Throttle percent of the time:
+// after each row it rests for as long, in proportion, as the row took.
+// Each worker has its own, as the rest owed is per worker.
+type throttler struct {
+	pct  int
+	owed time.Duration
+}
+
+// newThrottler returns a throttler for pct percent; 0 and 100 mean none.
+func newThrottler(pct int) *throttler {
+	if pct <= 0 || pct >= 100 {
+		return nil
+	}
+	return &throttler{pct: pct}
+}
+
+// rest records a row that took busy and sleeps off the rest owed once it
+// passes minThrottleRest, or until ctx is done. A nil throttler never
+// sleeps.
+func (t *throttler) rest(ctx context.Context, busy time.Duration) {
+	if t == nil {
+		return
+	}
+	t.owed += busy * time.Duration(100-t.pct) / time.Duration(t.pct)
+	if t.owed < minThrottleRest {
+		return
+	}
+	timer := time.NewTimer(t.owed)
+	defer timer.Stop()
+	start := time.Now()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	t.owed -= time.Since(start)
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestThrottlerRest(t *testing.T) {
+	for _, pct := range []int{0, 100} {
+		if th := newThrottler(pct); th != nil {
+			t.Errorf("newThrottler(%d) = %+v, want none", pct, th)
+		}
+	}
+	var none *throttler
+	none.rest(context.Background(), time.Second) // returns at once
+
+	// at 50% a row owes its own time; short rests are saved up
+	th := newThrottler(50)
+	th.rest(context.Background(), 400*time.Microsecond)
+	if th.owed != 400*time.Microsecond {
+		t.Errorf("owed %v after a 400µs row, want 400µs", th.owed)
+	}
+	start := time.Now()
+	th.rest(context.Background(), 400*time.Microsecond)
+	th.rest(context.Background(), 400*time.Microsecond)
+	if slept := time.Since(start); slept < 1200*time.Microsecond {
+		t.Errorf("slept %v after 1.2ms owed", slept)
+	}
+	if th.owed > 0 {
+		t.Errorf("still owed %v after sleeping", th.owed)
+	}
+
+	// at 25% it owes three times the row
+	th = newThrottler(25)
+	start = time.Now()
+	th.rest(context.Background(), 10*time.Millisecond)
+	if slept := time.Since(start); slept < 30*time.Millisecond {
+		t.Errorf("25%%: slept %v after a 10ms row, want 30ms", slept)
+	}
+
+	// a cancelled render doesn't wait out its rest
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start = time.Now()
+	newThrottler(1).rest(ctx, time.Second)
+	if slept := time.Since(start); slept > 50*time.Millisecond {
+		t.Errorf("cancelled: slept %v", slept)
+	}
+}
+
+func TestThrottleRender(t *testing.T) {
+	if testing.Short() {
+		t.Skip("renders for about a second")
+	}
+	timed := func(pct int) (*Result, time.Duration) {
+		t.Helper()
+		o := smallOptions(t, WithSize(800, 600), WithIterations(2000), WithThrottle(pct))
+		start := time.Now()
+		res, err := Render(context.Background(), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, time.Since(start)
+	}
+	timed(100) // warm up
+	full, fast := timed(100)
+	half, slow := timed(50)
+	// at 50% each worker rests as long as it computes, so the render
+	// takes twice as long, less what is spent outside the rows
+	const margin = 50 * time.Millisecond
+	if slow < 2*fast-margin {
+		t.Errorf("50%% took %v, 100%% %v: want at least twice as long", slow, fast)
+	}
+	if !bytes.Equal(full.Image.Pix, half.Image.Pix) {
+		t.Error("throttling changed the image")
+	}
+}
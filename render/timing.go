@@ -0,0 +1,17 @@
+package render
+
+import (
+	"image"
+	"time"
+)
+
+// TaskTiming is how long a worker took over one work unit of a frame,
+// recorded with Options.RecordTimings. The units are the chunks of
+// Options.RowsPerChunk rows the workers claim in turn, so Rect spans the
+// width of the image.
+type TaskTiming struct {
+	Rect    image.Rectangle // the pixels of the unit
+	Worker  int             // index of the worker that computed it
+	Start   time.Duration   // when the worker started it, from the start of the frame
+	Elapsed time.Duration   // wall time spent computing it
+}
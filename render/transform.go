@@ -0,0 +1,62 @@
+package render
+
+import (
+	"fmt"
+	"math/cmplx"
+)
+
+// Mobius is the conformal map z -> (A·z + B) / (C·z + D) that
+// Options.Transform applies to each sample point between PixelToComplex
+// and the fractal: the pixel at z shows the point Apply(z). The map keeps
+// angles, so the iteration and the coloring work unchanged on the
+// transformed image. The zero value means no transform; any other needs
+// AD - BC != 0.
+type Mobius struct {
+	A, B, C, D complex128
+}
+
+// Inversion is the Mobius map 1/z. It turns the set inside out: the
+// exterior becomes a bounded figure around the origin and the set wraps
+// around it, cusp first.
+var Inversion = Mobius{B: 1, C: 1}
+
+// IsZero reports whether m is the zero value, no transform.
+func (m Mobius) IsZero() bool { return m == Mobius{} }
+
+// Apply returns the point the pixel at z shows. It reports false at the
+// pole, where Cz + D is 0 and the point is ∞; such pixels are colored as
+// the exterior. The zero value returns z.
+func (m Mobius) Apply(z complex128) (complex128, bool) {
+	if m.IsZero() {
+		return z, true
+	}
+	den := m.C*z + m.D
+	if den == 0 {
+		return 0, false
+	}
+	w := (m.A*z + m.B) / den
+	return w, !cmplx.IsInf(w) && !cmplx.IsNaN(w)
+}
+
+// validate checks that a transform is set up as a Mobius map.
+func (m Mobius) validate() []error {
+	if m.IsZero() {
+		return nil
+	}
+	var errs []error
+	for _, v := range []complex128{m.A, m.B, m.C, m.D} {
+		if cmplx.IsNaN(v) || cmplx.IsInf(v) {
+			errs = append(errs, fmt.Errorf("%w: mobius coefficient %v: must be finite", ErrInvalidOptions, v))
+		}
+	}
+	if len(errs) == 0 && m.A*m.D-m.B*m.C == 0 {
+		errs = append(errs, fmt.Errorf("%w: mobius coefficients %v, %v, %v, %v: ad - bc must not be 0", ErrInvalidOptions, m.A, m.B, m.C, m.D))
+	}
+	return errs
+}
+
+// poleOrbit is what iterate would find for the point at ∞: escaped at
+// once, at iteration 0, with an infinite z. The colorings treat it like an
+// orbit that overflowed, which gives the color of the exterior far from
+// the set.
+var poleOrbit = orbit{z: cmplx.Inf(), far: cmplx.Inf(), how: resultEscaped}
@@ -0,0 +1,65 @@
+package render
+
+import (
+	"math"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+// trapT maps the distance from ColoringFixedTrap to [0,1] as
+// 1-exp(-scale·dist): orbits that pass through a trap get the palette
+// start and those that stay far from every trap its end.
+func trapT(dist, scale float64) float64 {
+	return clamp01(-math.Expm1(-scale * dist))
+}
+
+// trapDistance dispatches minOrbitDistance the way iterate dispatches the
+// escape-time kernels, so the built-in formulas step without going
+// through the interface.
+func trapDistance(f fractal.Fractal, c complex128, maxIter int, bailoutSq float64, traps []complex128) float64 {
+	switch f := f.(type) {
+	case fractal.Mandelbrot:
+		return minOrbitDistance(f, c, maxIter, bailoutSq, traps)
+	case fractal.Julia:
+		return minOrbitDistance(f, c, maxIter, bailoutSq, traps)
+	case fractal.BurningShip:
+		return minOrbitDistance(f, c, maxIter, bailoutSq, traps)
+	case fractal.Multibrot:
+		return minOrbitDistance(f, c, maxIter, bailoutSq, traps)
+	case fractal.Quaternion:
+		return minOrbitDistance(f, c, maxIter, bailoutSq, traps)
+	case fractal.Lemniscate:
+		return minOrbitDistance(f, c, maxIter, bailoutSq, traps)
+	case fractal.Super:
+		return minOrbitDistance(f, c, maxIter, bailoutSq, traps)
+	case fractal.Func:
+		return minOrbitDistance(f, c, maxIter, bailoutSq, traps)
+	case fractal.Expr:
+		return minOrbitDistance(f, c, maxIter, bailoutSq, traps)
+	default:
+		return minOrbitDistance(f, c, maxIter, bailoutSq, traps)
+	}
+}
+
+// minOrbitDistance returns how close the orbit of c comes to the nearest
+// of traps, over the iterates z_1 to z_maxIter or up to and including
+// the first one outside the escape radius. The starting z is left out:
+// for the Mandelbrot set it is the same for every c, and a trap on it
+// would be hit by every point. None of the interior shortcuts apply, as
+// the distance depends on the whole orbit.
+func minOrbitDistance[F fractal.Fractal](f F, c complex128, maxIter int, bailoutSq float64, traps []complex128) float64 {
+	s := f.Init(c)
+	s.BailoutSq = bailoutSq
+	best := math.Inf(1)
+	for range maxIter {
+		f.Step(&s)
+		for _, p := range traps {
+			d := s.Z - p
+			best = min(best, real(d)*real(d)+imag(d)*imag(d))
+		}
+		if f.Escaped(&s) {
+			break
+		}
+	}
+	return math.Sqrt(best)
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+)
+
+func TestMinOrbitDistance(t *testing.T) {
+	const maxIter = 200
+	bailoutSq := DefaultBailout * DefaultBailout
+	traps := []complex128{0, 1, -1}
+	dist := func(c complex128, traps []complex128) float64 {
+		return minOrbitDistance(fractal.Mandelbrot{}, c, maxIter, bailoutSq, traps)
+	}
+	// c = 0 stays at 0, on a trap; an escaping point far from 0 never
+	// comes near one
+	inside, outside := dist(0, traps), dist(3+3i, traps)
+	if inside != 0 {
+		t.Errorf("c = 0: distance %v, want 0", inside)
+	}
+	if !(inside < outside) {
+		t.Errorf("c = 0: distance %v, not under %v for 3+3i", inside, outside)
+	}
+	for _, tc := range []struct {
+		c     complex128
+		traps []complex128
+		want  float64
+	}{
+		// the orbit of 0 sits at 0
+		{0, []complex128{1, -1}, 1},
+		// -1, 0, -1, 0, ...
+		{-1, []complex128{0.5}, 0.5},
+		{-1, []complex128{-1.25i}, 1.25},
+		// 3+3i escapes at once, so only z_1 = c counts
+		{3 + 3i, []complex128{1}, math.Hypot(2, 3)},
+	} {
+		if got := dist(tc.c, tc.traps); math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("c = %v, traps %v: distance %v, want %v", tc.c, tc.traps, got, tc.want)
+		}
+	}
+}
+
+func TestTrapDistanceDispatch(t *testing.T) {
+	traps := []complex128{0.1 + 0.2i, -0.5}
+	for _, f := range []fractal.Fractal{
+		fractal.Mandelbrot{},
+		fractal.ByName("julia", complex(-0.8, 0.156)),
+		fractal.ByName("burningship", 0),
+	} {
+		for _, c := range coloringPoints {
+			got := trapDistance(f, c, 300, 4, traps)
+			// through the interface, with no type switch
+			want := minOrbitDistance[fractal.Fractal](f, c, 300, 4, traps)
+			if got != want {
+				t.Errorf("%T at %v: %v, through the interface %v", f, c, got, want)
+			}
+		}
+	}
+}
+
+func TestTrapT(t *testing.T) {
+	if got := trapT(0, DefaultTrapScale); got != 0 {
+		t.Errorf("trapT(0) = %v, want 0", got)
+	}
+	last := 0.0
+	for _, d := range []float64{0.01, 0.1, 0.5, 1, 10} {
+		v := trapT(d, DefaultTrapScale)
+		if !(v > last && v <= 1) {
+			t.Errorf("trapT(%v) = %v, want above %v and at most 1", d, v, last)
+		}
+		last = v
+	}
+	if got, want := trapT(0.25, 4), 1-math.Exp(-1); math.Abs(got-want) > 1e-15 {
+		t.Errorf("trapT(0.25, 4) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,486 @@
+// Render service for the mandlebrot server, started with `serve -grpc`.
+//
+// Regenerate the Go code after editing with:
+//
+//   protoc --go_out=. --go_opt=paths=source_relative \
+//     --go-grpc_out=. --go-grpc_opt=paths=source_relative renderpb/render.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: renderpb/render.proto
+
+package renderpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// RenderRequest mirrors render.Options. Zero fields take the same
+// defaults as the HTTP /render endpoint.
+type RenderRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Width  int32                  `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`   // 0 means 800
+	Height int32                  `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"` // 0 means 600
+	// Window on the complex plane; all zero means the default view fitted
+	// to width×height.
+	Xmin        float64 `protobuf:"fixed64,3,opt,name=xmin,proto3" json:"xmin,omitempty"`
+	Xmax        float64 `protobuf:"fixed64,4,opt,name=xmax,proto3" json:"xmax,omitempty"`
+	Ymin        float64 `protobuf:"fixed64,5,opt,name=ymin,proto3" json:"ymin,omitempty"`
+	Ymax        float64 `protobuf:"fixed64,6,opt,name=ymax,proto3" json:"ymax,omitempty"`
+	Rotation    float64 `protobuf:"fixed64,7,opt,name=rotation,proto3" json:"rotation,omitempty"`               // degrees counter-clockwise about the center
+	MaxIter     int32   `protobuf:"varint,8,opt,name=max_iter,json=maxIter,proto3" json:"max_iter,omitempty"`   // 0 means 1200
+	Bailout     float64 `protobuf:"fixed64,9,opt,name=bailout,proto3" json:"bailout,omitempty"`                 // escape radius, 0 means 2
+	Palette     string  `protobuf:"bytes,10,opt,name=palette,proto3" json:"palette,omitempty"`                  // "" means NebulaSpectre
+	Fractal     string  `protobuf:"bytes,11,opt,name=fractal,proto3" json:"fractal,omitempty"`                  // "" means mandelbrot
+	JuliaRe     float64 `protobuf:"fixed64,12,opt,name=julia_re,json=juliaRe,proto3" json:"julia_re,omitempty"` // Julia parameter, used by fractal "julia"
+	JuliaIm     float64 `protobuf:"fixed64,13,opt,name=julia_im,json=juliaIm,proto3" json:"julia_im,omitempty"`
+	Coloring    string  `protobuf:"bytes,14,opt,name=coloring,proto3" json:"coloring,omitempty"` // "" means smooth
+	Bands       int32   `protobuf:"varint,15,opt,name=bands,proto3" json:"bands,omitempty"`      // band count for band and blend coloring
+	BlendSmooth float64 `protobuf:"fixed64,16,opt,name=blend_smooth,json=blendSmooth,proto3" json:"blend_smooth,omitempty"`
+	ZmagSmooth  float64 `protobuf:"fixed64,17,opt,name=zmag_smooth,json=zmagSmooth,proto3" json:"zmag_smooth,omitempty"`
+	// tile_size, if set, asks for the image as tile_size×tile_size tiles
+	// whatever its size. 0 lets the server choose.
+	TileSize      int32 `protobuf:"varint,18,opt,name=tile_size,json=tileSize,proto3" json:"tile_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderRequest) Reset() {
+	*x = RenderRequest{}
+	mi := &file_renderpb_render_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderRequest) ProtoMessage() {}
+
+func (x *RenderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renderpb_render_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderRequest.ProtoReflect.Descriptor instead.
+func (*RenderRequest) Descriptor() ([]byte, []int) {
+	return file_renderpb_render_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RenderRequest) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetXmin() float64 {
+	if x != nil {
+		return x.Xmin
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetXmax() float64 {
+	if x != nil {
+		return x.Xmax
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetYmin() float64 {
+	if x != nil {
+		return x.Ymin
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetYmax() float64 {
+	if x != nil {
+		return x.Ymax
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetRotation() float64 {
+	if x != nil {
+		return x.Rotation
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetMaxIter() int32 {
+	if x != nil {
+		return x.MaxIter
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetBailout() float64 {
+	if x != nil {
+		return x.Bailout
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetPalette() string {
+	if x != nil {
+		return x.Palette
+	}
+	return ""
+}
+
+func (x *RenderRequest) GetFractal() string {
+	if x != nil {
+		return x.Fractal
+	}
+	return ""
+}
+
+func (x *RenderRequest) GetJuliaRe() float64 {
+	if x != nil {
+		return x.JuliaRe
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetJuliaIm() float64 {
+	if x != nil {
+		return x.JuliaIm
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetColoring() string {
+	if x != nil {
+		return x.Coloring
+	}
+	return ""
+}
+
+func (x *RenderRequest) GetBands() int32 {
+	if x != nil {
+		return x.Bands
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetBlendSmooth() float64 {
+	if x != nil {
+		return x.BlendSmooth
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetZmagSmooth() float64 {
+	if x != nil {
+		return x.ZmagSmooth
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetTileSize() int32 {
+	if x != nil {
+		return x.TileSize
+	}
+	return 0
+}
+
+// RenderChunk is a PNG of the rectangle [x, x+width)×[y, y+height) of an
+// image_width×image_height render.
+type RenderChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             int32                  `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int32                  `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+	Width         int32                  `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	ImageWidth    int32                  `protobuf:"varint,5,opt,name=image_width,json=imageWidth,proto3" json:"image_width,omitempty"`
+	ImageHeight   int32                  `protobuf:"varint,6,opt,name=image_height,json=imageHeight,proto3" json:"image_height,omitempty"`
+	Png           []byte                 `protobuf:"bytes,7,opt,name=png,proto3" json:"png,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderChunk) Reset() {
+	*x = RenderChunk{}
+	mi := &file_renderpb_render_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderChunk) ProtoMessage() {}
+
+func (x *RenderChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_renderpb_render_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderChunk.ProtoReflect.Descriptor instead.
+func (*RenderChunk) Descriptor() ([]byte, []int) {
+	return file_renderpb_render_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RenderChunk) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *RenderChunk) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *RenderChunk) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *RenderChunk) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *RenderChunk) GetImageWidth() int32 {
+	if x != nil {
+		return x.ImageWidth
+	}
+	return 0
+}
+
+func (x *RenderChunk) GetImageHeight() int32 {
+	if x != nil {
+		return x.ImageHeight
+	}
+	return 0
+}
+
+func (x *RenderChunk) GetPng() []byte {
+	if x != nil {
+		return x.Png
+	}
+	return nil
+}
+
+type ListPalettesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPalettesRequest) Reset() {
+	*x = ListPalettesRequest{}
+	mi := &file_renderpb_render_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPalettesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPalettesRequest) ProtoMessage() {}
+
+func (x *ListPalettesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renderpb_render_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPalettesRequest.ProtoReflect.Descriptor instead.
+func (*ListPalettesRequest) Descriptor() ([]byte, []int) {
+	return file_renderpb_render_proto_rawDescGZIP(), []int{2}
+}
+
+type ListPalettesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Names         []string               `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPalettesResponse) Reset() {
+	*x = ListPalettesResponse{}
+	mi := &file_renderpb_render_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPalettesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPalettesResponse) ProtoMessage() {}
+
+func (x *ListPalettesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_renderpb_render_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPalettesResponse.ProtoReflect.Descriptor instead.
+func (*ListPalettesResponse) Descriptor() ([]byte, []int) {
+	return file_renderpb_render_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListPalettesResponse) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+var File_renderpb_render_proto protoreflect.FileDescriptor
+
+const file_renderpb_render_proto_rawDesc = "" +
+	"\n" +
+	"\x15renderpb/render.proto\x12\rmandlebrot.v1\"\xdb\x03\n" +
+	"\rRenderRequest\x12\x14\n" +
+	"\x05width\x18\x01 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x02 \x01(\x05R\x06height\x12\x12\n" +
+	"\x04xmin\x18\x03 \x01(\x01R\x04xmin\x12\x12\n" +
+	"\x04xmax\x18\x04 \x01(\x01R\x04xmax\x12\x12\n" +
+	"\x04ymin\x18\x05 \x01(\x01R\x04ymin\x12\x12\n" +
+	"\x04ymax\x18\x06 \x01(\x01R\x04ymax\x12\x1a\n" +
+	"\brotation\x18\a \x01(\x01R\brotation\x12\x19\n" +
+	"\bmax_iter\x18\b \x01(\x05R\amaxIter\x12\x18\n" +
+	"\abailout\x18\t \x01(\x01R\abailout\x12\x18\n" +
+	"\apalette\x18\n" +
+	" \x01(\tR\apalette\x12\x18\n" +
+	"\afractal\x18\v \x01(\tR\afractal\x12\x19\n" +
+	"\bjulia_re\x18\f \x01(\x01R\ajuliaRe\x12\x19\n" +
+	"\bjulia_im\x18\r \x01(\x01R\ajuliaIm\x12\x1a\n" +
+	"\bcoloring\x18\x0e \x01(\tR\bcoloring\x12\x14\n" +
+	"\x05bands\x18\x0f \x01(\x05R\x05bands\x12!\n" +
+	"\fblend_smooth\x18\x10 \x01(\x01R\vblendSmooth\x12\x1f\n" +
+	"\vzmag_smooth\x18\x11 \x01(\x01R\n" +
+	"zmagSmooth\x12\x1b\n" +
+	"\ttile_size\x18\x12 \x01(\x05R\btileSize\"\xad\x01\n" +
+	"\vRenderChunk\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x05R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x05R\x01y\x12\x14\n" +
+	"\x05width\x18\x03 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x04 \x01(\x05R\x06height\x12\x1f\n" +
+	"\vimage_width\x18\x05 \x01(\x05R\n" +
+	"imageWidth\x12!\n" +
+	"\fimage_height\x18\x06 \x01(\x05R\vimageHeight\x12\x10\n" +
+	"\x03png\x18\a \x01(\fR\x03png\"\x15\n" +
+	"\x13ListPalettesRequest\",\n" +
+	"\x14ListPalettesResponse\x12\x14\n" +
+	"\x05names\x18\x01 \x03(\tR\x05names2\xa9\x01\n" +
+	"\bRenderer\x12D\n" +
+	"\x06Render\x12\x1c.mandlebrot.v1.RenderRequest\x1a\x1a.mandlebrot.v1.RenderChunk0\x01\x12W\n" +
+	"\fListPalettes\x12\".mandlebrot.v1.ListPalettesRequest\x1a#.mandlebrot.v1.ListPalettesResponseB+Z)github.com/whalelogic/mandlebrot/renderpbb\x06proto3"
+
+var (
+	file_renderpb_render_proto_rawDescOnce sync.Once
+	file_renderpb_render_proto_rawDescData []byte
+)
+
+func file_renderpb_render_proto_rawDescGZIP() []byte {
+	file_renderpb_render_proto_rawDescOnce.Do(func() {
+		file_renderpb_render_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_renderpb_render_proto_rawDesc), len(file_renderpb_render_proto_rawDesc)))
+	})
+	return file_renderpb_render_proto_rawDescData
+}
+
+var file_renderpb_render_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_renderpb_render_proto_goTypes = []any{
+	(*RenderRequest)(nil),        // 0: mandlebrot.v1.RenderRequest
+	(*RenderChunk)(nil),          // 1: mandlebrot.v1.RenderChunk
+	(*ListPalettesRequest)(nil),  // 2: mandlebrot.v1.ListPalettesRequest
+	(*ListPalettesResponse)(nil), // 3: mandlebrot.v1.ListPalettesResponse
+}
+var file_renderpb_render_proto_depIdxs = []int32{
+	0, // 0: mandlebrot.v1.Renderer.Render:input_type -> mandlebrot.v1.RenderRequest
+	2, // 1: mandlebrot.v1.Renderer.ListPalettes:input_type -> mandlebrot.v1.ListPalettesRequest
+	1, // 2: mandlebrot.v1.Renderer.Render:output_type -> mandlebrot.v1.RenderChunk
+	3, // 3: mandlebrot.v1.Renderer.ListPalettes:output_type -> mandlebrot.v1.ListPalettesResponse
+	2, // [2:4] is the sub-list for method output_type
+	0, // [0:2] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_renderpb_render_proto_init() }
+func file_renderpb_render_proto_init() {
+	if File_renderpb_render_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_renderpb_render_proto_rawDesc), len(file_renderpb_render_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_renderpb_render_proto_goTypes,
+		DependencyIndexes: file_renderpb_render_proto_depIdxs,
+		MessageInfos:      file_renderpb_render_proto_msgTypes,
+	}.Build()
+	File_renderpb_render_proto = out.File
+	file_renderpb_render_proto_goTypes = nil
+	file_renderpb_render_proto_depIdxs = nil
+}
@@ -0,0 +1,188 @@
+// Render service for the mandlebrot server, started with `serve -grpc`.
+//
+// Regenerate the Go code after editing with:
+//
+//   protoc --go_out=. --go_opt=paths=source_relative \
+//     --go-grpc_out=. --go-grpc_opt=paths=source_relative renderpb/render.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: renderpb/render.proto
+
+package renderpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Renderer_Render_FullMethodName       = "/mandlebrot.v1.Renderer/Render"
+	Renderer_ListPalettes_FullMethodName = "/mandlebrot.v1.Renderer/ListPalettes"
+)
+
+// RendererClient is the client API for Renderer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RendererClient interface {
+	// Render renders one image. Images up to the server's single-image
+	// limit arrive as one chunk covering the whole image; larger ones, or
+	// any request with tile_size set, arrive as a stream of tiles in
+	// row-major order, each sent as soon as its rows are finished.
+	//
+	// Invalid requests fail with INVALID_ARGUMENT. The call's deadline or
+	// cancellation stops the render, failing with DEADLINE_EXCEEDED or
+	// CANCELLED.
+	Render(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RenderChunk], error)
+	// ListPalettes returns the names accepted by RenderRequest.palette.
+	ListPalettes(ctx context.Context, in *ListPalettesRequest, opts ...grpc.CallOption) (*ListPalettesResponse, error)
+}
+
+type rendererClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRendererClient(cc grpc.ClientConnInterface) RendererClient {
+	return &rendererClient{cc}
+}
+
+func (c *rendererClient) Render(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RenderChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Renderer_ServiceDesc.Streams[0], Renderer_Render_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RenderRequest, RenderChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Renderer_RenderClient = grpc.ServerStreamingClient[RenderChunk]
+
+func (c *rendererClient) ListPalettes(ctx context.Context, in *ListPalettesRequest, opts ...grpc.CallOption) (*ListPalettesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPalettesResponse)
+	err := c.cc.Invoke(ctx, Renderer_ListPalettes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RendererServer is the server API for Renderer service.
+// All implementations must embed UnimplementedRendererServer
+// for forward compatibility.
+type RendererServer interface {
+	// Render renders one image. Images up to the server's single-image
+	// limit arrive as one chunk covering the whole image; larger ones, or
+	// any request with tile_size set, arrive as a stream of tiles in
+	// row-major order, each sent as soon as its rows are finished.
+	//
+	// Invalid requests fail with INVALID_ARGUMENT. The call's deadline or
+	// cancellation stops the render, failing with DEADLINE_EXCEEDED or
+	// CANCELLED.
+	Render(*RenderRequest, grpc.ServerStreamingServer[RenderChunk]) error
+	// ListPalettes returns the names accepted by RenderRequest.palette.
+	ListPalettes(context.Context, *ListPalettesRequest) (*ListPalettesResponse, error)
+	mustEmbedUnimplementedRendererServer()
+}
+
+// UnimplementedRendererServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRendererServer struct{}
+
+func (UnimplementedRendererServer) Render(*RenderRequest, grpc.ServerStreamingServer[RenderChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Render not implemented")
+}
+func (UnimplementedRendererServer) ListPalettes(context.Context, *ListPalettesRequest) (*ListPalettesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPalettes not implemented")
+}
+func (UnimplementedRendererServer) mustEmbedUnimplementedRendererServer() {}
+func (UnimplementedRendererServer) testEmbeddedByValue()                  {}
+
+// UnsafeRendererServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RendererServer will
+// result in compilation errors.
+type UnsafeRendererServer interface {
+	mustEmbedUnimplementedRendererServer()
+}
+
+func RegisterRendererServer(s grpc.ServiceRegistrar, srv RendererServer) {
+	// If the following call pancis, it indicates UnimplementedRendererServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Renderer_ServiceDesc, srv)
+}
+
+func _Renderer_Render_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RenderRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RendererServer).Render(m, &grpc.GenericServerStream[RenderRequest, RenderChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Renderer_RenderServer = grpc.ServerStreamingServer[RenderChunk]
+
+func _Renderer_ListPalettes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPalettesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RendererServer).ListPalettes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Renderer_ListPalettes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RendererServer).ListPalettes(ctx, req.(*ListPalettesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Renderer_ServiceDesc is the grpc.ServiceDesc for Renderer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Renderer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mandlebrot.v1.Renderer",
+	HandlerType: (*RendererServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListPalettes",
+			Handler:    _Renderer_ListPalettes_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Render",
+			Handler:       _Renderer_Render_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "renderpb/render.proto",
+}
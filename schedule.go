@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// frameScheduler renders the frames of an animation with several
+// workers at once. Each frame is claimed with a lock file next to its
+// output before it is rendered, so several processes, on one machine or
+// on several sharing the output directory, can work through the same
+// frame range without rendering a frame twice. A lock whose owner stops
+// refreshing it for lockStale counts as abandoned and may be taken over,
+// which is how the frames of a killed run get finished.
+type frameScheduler struct {
+	pattern       string // printf pattern of the frame paths
+	frames        int
+	width, height int
+	resume        bool          // count valid frames from earlier runs as done
+	lockStale     time.Duration // age at which a lock is abandoned
+	manifest      string        // progress manifest path, "" for none
+	command       string        // recorded in the manifest
+
+	mu       sync.Mutex
+	done     []bool
+	rendered int // frames this process rendered
+	began    time.Time
+}
+
+// frameManifest is the progress file the scheduler keeps up to date.
+type frameManifest struct {
+	Command  string    `json:"command"`
+	Frames   int       `json:"frames"`
+	Complete int       `json:"complete"`
+	Missing  []int     `json:"missing"`
+	Updated  time.Time `json:"updated"`
+}
+
+// run renders every frame that isn't done yet with jobs workers, calling
+// render(ctx, w, i) for frame i on worker w; render returns the detail
+// shown in the progress line. Frames claimed by another process are
+// waited for, and taken over if their lock goes stale, so run returns
+// only when every frame is done or on the first error.
+func (s *frameScheduler) run(ctx context.Context, jobs int, render func(ctx context.Context, w, i int) (string, error)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.began = time.Now()
+	s.done = make([]bool, s.frames)
+	if s.resume {
+		n := 0
+		for i := range s.frames {
+			if s.frameDone(i) {
+				s.done[i] = true
+				n++
+			}
+		}
+		if n > 0 {
+			fmt.Printf("%d of %d frames already done\n", n, s.frames)
+		}
+	}
+	for {
+		var pending []int
+		for i, d := range s.done {
+			if !d {
+				pending = append(pending, i)
+			}
+		}
+		if err := s.writeManifest(); err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		queue := make(chan int)
+		var (
+			wg       sync.WaitGroup
+			errOnce  sync.Once
+			firstErr error
+			claimed  int
+		)
+		for w := range jobs {
+			wg.Go(func() {
+				for i := range queue {
+					if ctx.Err() != nil {
+						continue
+					}
+					if s.frameDone(i) {
+						s.markDone(i, "")
+						continue
+					}
+					release, ok := s.claim(i)
+					if !ok {
+						continue // another worker has it
+					}
+					if s.frameDone(i) {
+						// finished by whoever held the lock between the
+						// check above and the claim
+						release()
+						s.markDone(i, "")
+						continue
+					}
+					s.mu.Lock()
+					claimed++
+					s.mu.Unlock()
+					frameStart := time.Now()
+					detail, err := render(ctx, w, i)
+					release()
+					if err != nil {
+						errOnce.Do(func() { firstErr = fmt.Errorf("frame %d: %w", i, err); cancel() })
+						continue
+					}
+					s.markDone(i, fmt.Sprintf("%s  %v", detail, time.Since(frameStart).Round(time.Millisecond)))
+				}
+			})
+		}
+		for _, i := range pending {
+			queue <- i
+		}
+		close(queue)
+		wg.Wait()
+		if firstErr != nil {
+			return firstErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if claimed == 0 {
+			// everything left belongs to other workers: check back once
+			// they've had time to finish or their locks to go stale
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.lockStale / 4):
+			}
+		}
+	}
+}
+
+// path returns the output path of frame i.
+func (s *frameScheduler) path(i int) string { return fmt.Sprintf(s.pattern, i) }
+
+// frameDone reports whether frame i has a complete output file: one that
+// decodes in full, which checks every PNG chunk's CRC, at the frame size.
+// Without resume only files written since this run began count, which
+// are the ones other workers finished.
+func (s *frameScheduler) frameDone(i int) bool {
+	f, err := os.Open(s.path(i))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	if !s.resume {
+		st, err := f.Stat()
+		if err != nil || st.ModTime().Before(s.began) {
+			return false
+		}
+	}
+	img, _, err := image.Decode(f)
+	return err == nil && img.Bounds().Dx() == s.width && img.Bounds().Dy() == s.height
+}
+
+// markDone records frame i as done and prints a progress line for it,
+// unless detail is empty because another worker rendered it.
+func (s *frameScheduler) markDone(i int, detail string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done[i] {
+		return
+	}
+	s.done[i] = true
+	complete := 0
+	for _, d := range s.done {
+		if d {
+			complete++
+		}
+	}
+	if detail != "" {
+		// the remaining frames are estimated at this process's average so
+		// far; deeper frames take longer, so the estimate errs on the
+		// short side
+		s.rendered++
+		elapsed := time.Since(s.began)
+		eta := elapsed / time.Duration(s.rendered) * time.Duration(s.frames-complete)
+		fmt.Printf("frame %*d/%d  %s  %s  (total %3d%%, elapsed %v, eta %v)\n",
+			digits(s.frames), i+1, s.frames, s.path(i), detail,
+			complete*100/s.frames, elapsed.Round(time.Second), eta.Round(time.Second))
+	}
+	if err := s.writeManifestLocked(); err != nil {
+		fmt.Fprintf(os.Stderr, "manifest: %v\n", err)
+	}
+}
+
+// claim takes the lock on frame i. While the lock is held it is touched
+// regularly to show its owner is alive; release stops that and removes
+// it. A lock left untouched for lockStale is taken over: it is first
+// renamed out of the way, which only one of several claimers racing for
+// it can do, and then claimed anew.
+func (s *frameScheduler) claim(i int) (release func(), ok bool) {
+	lock := s.path(i) + ".lock"
+	f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		st, err := os.Stat(lock)
+		if err != nil || time.Since(st.ModTime()) < s.lockStale || !s.takeOver(lock, st) {
+			return nil, false
+		}
+		if f, err = os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644); err != nil {
+			return nil, false // claimed normally since the lock went
+		}
+	}
+	host, _ := os.Hostname()
+	fmt.Fprintf(f, "%s %d %s\n", host, os.Getpid(), time.Now().Format(time.RFC3339))
+	own, err := f.Stat()
+	f.Close()
+	if err != nil {
+		os.Remove(lock)
+		return nil, false
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		t := time.NewTicker(s.lockStale / 4)
+		defer t.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-t.C:
+				os.Chtimes(lock, now, now)
+			}
+		}
+	})
+	return func() {
+		close(stop)
+		wg.Wait()
+		// only our own lock: one taken over after all would be another's
+		if st, err := os.Stat(lock); err == nil && os.SameFile(st, own) {
+			os.Remove(lock)
+		}
+	}, true
+}
+
+// takeOver removes lock, which was stale when stat found it as st,
+// reporting whether it did. Of several processes that found the same
+// stale lock only the first to rename it away succeeds; one that renames
+// a lock claimed since it looked puts that one back.
+func (s *frameScheduler) takeOver(lock string, st os.FileInfo) bool {
+	aside := fmt.Sprintf("%s.%d-%d", lock, os.Getpid(), rand.Uint64())
+	if os.Rename(lock, aside) != nil {
+		return false // someone else took it over first
+	}
+	defer os.Remove(aside)
+	got, err := os.Stat(aside)
+	if err != nil || !os.SameFile(got, st) || time.Since(got.ModTime()) < s.lockStale {
+		os.Link(aside, lock)
+		return false
+	}
+	return true
+}
+
+// writeManifest writes the progress manifest, if there is one.
+func (s *frameScheduler) writeManifest() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writeManifestLocked()
+}
+
+// writeManifestLocked is writeManifest for a caller holding s.mu. The
+// manifest is replaced in one rename, so readers never see half of it.
+func (s *frameScheduler) writeManifestLocked() error {
+	if s.manifest == "" {
+		return nil
+	}
+	m := frameManifest{Command: s.command, Frames: s.frames, Missing: []int{}, Updated: time.Now().UTC()}
+	for i, d := range s.done {
+		if d {
+			m.Complete++
+		} else {
+			m.Missing = append(m.Missing, i)
+		}
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(s.manifest), ".manifest-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.manifest)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
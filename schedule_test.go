@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testScheduler returns a resuming scheduler for frames 8×6 frames in
+// dir, with lockStale long enough that no lock goes stale by accident.
+func testScheduler(dir string, frames int) *frameScheduler {
+	return &frameScheduler{
+		pattern:   filepath.Join(dir, "frame%03d.png"),
+		frames:    frames,
+		width:     8,
+		height:    6,
+		resume:    true,
+		lockStale: time.Minute,
+		manifest:  filepath.Join(dir, "manifest.json"),
+	}
+}
+
+// writeTestFrame writes a blank frame of s's size for frame i.
+func writeTestFrame(s *frameScheduler, i int) error {
+	f, err := os.Create(s.path(i))
+	if err != nil {
+		return err
+	}
+	err = png.Encode(f, image.NewRGBA(image.Rect(0, 0, s.width, s.height)))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func TestSchedulerResume(t *testing.T) {
+	dir := t.TempDir()
+	const frames = 10
+	var rendered []int
+	renderFrame := func(s *frameScheduler, failAt int) func(context.Context, int, int) (string, error) {
+		return func(ctx context.Context, w, i int) (string, error) {
+			if i == failAt {
+				return "", errors.New("killed")
+			}
+			rendered = append(rendered, i)
+			return "", writeTestFrame(s, i)
+		}
+	}
+
+	// the first run dies at frame 4
+	s := testScheduler(dir, frames)
+	if err := s.run(context.Background(), 1, renderFrame(s, 4)); err == nil {
+		t.Fatal("first run didn't fail")
+	}
+	if want := []int{0, 1, 2, 3}; !slices.Equal(rendered, want) {
+		t.Fatalf("first run rendered %v, want %v", rendered, want)
+	}
+	var m frameManifest
+	readManifest := func() {
+		t.Helper()
+		data, err := os.ReadFile(s.manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readManifest()
+	if m.Complete != 4 || !slices.Equal(m.Missing, []int{4, 5, 6, 7, 8, 9}) {
+		t.Fatalf("manifest after the first run: %d complete, missing %v", m.Complete, m.Missing)
+	}
+
+	// frame 2 was cut short and frame 3 came out the wrong size: both
+	// must be rendered again, the other finished frames not
+	if err := os.Truncate(s.path(2), 40); err != nil {
+		t.Fatal(err)
+	}
+	wrong := testScheduler(dir, frames)
+	wrong.width = 5
+	if err := writeTestFrame(wrong, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	rendered = nil
+	s = testScheduler(dir, frames)
+	if err := s.run(context.Background(), 1, renderFrame(s, -1)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{2, 3, 4, 5, 6, 7, 8, 9}; !slices.Equal(rendered, want) {
+		t.Errorf("resumed run rendered %v, want %v", rendered, want)
+	}
+	readManifest()
+	if m.Complete != frames || len(m.Missing) != 0 {
+		t.Errorf("final manifest: %d complete, missing %v", m.Complete, m.Missing)
+	}
+	for i := range frames {
+		if !s.frameDone(i) {
+			t.Errorf("frame %d missing", i)
+		}
+		if _, err := os.Stat(s.path(i) + ".lock"); !os.IsNotExist(err) {
+			t.Errorf("frame %d lock left behind", i)
+		}
+	}
+}
+
+// staleLock leaves a lock on frame i of s last touched twice lockStale ago.
+func staleLock(t *testing.T, s *frameScheduler, i int) {
+	t.Helper()
+	lock := s.path(i) + ".lock"
+	if err := os.WriteFile(lock, []byte("gone 1 earlier\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * s.lockStale)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSchedulerStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	s := testScheduler(dir, 2)
+	staleLock(t, s, 0)
+	release, ok := s.claim(0)
+	if !ok {
+		t.Fatal("stale lock not taken over")
+	}
+	// the lock is ours now: fresh, so no one else can have it
+	other := testScheduler(dir, 2)
+	if _, ok := other.claim(0); ok {
+		t.Fatal("claimed a lock held by another")
+	}
+	release()
+	if _, err := os.Stat(s.path(0) + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock left after release: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("files left behind: %v", entries)
+	}
+
+	// a lock that is being kept fresh is never taken over
+	release, _ = s.claim(1)
+	defer release()
+	if _, ok := other.claim(1); ok {
+		t.Error("claimed a fresh lock")
+	}
+}
+
+func TestSchedulerConcurrentClaim(t *testing.T) {
+	// Claimers in separate schedulers, as in separate processes, racing
+	// for one stale lock: exactly one may win each time.
+	dir := t.TempDir()
+	for round := range 50 {
+		staleLock(t, testScheduler(dir, 1), 0)
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			releases []func()
+		)
+		for range 8 {
+			wg.Go(func() {
+				if release, ok := testScheduler(dir, 1).claim(0); ok {
+					mu.Lock()
+					releases = append(releases, release)
+					mu.Unlock()
+				}
+			})
+		}
+		wg.Wait()
+		if len(releases) != 1 {
+			t.Fatalf("round %d: %d claimers won the stale lock", round, len(releases))
+		}
+		releases[0]()
+	}
+}
+
+func TestSchedulerTakeOverLate(t *testing.T) {
+	// A claimer that found the lock stale but got to it only after
+	// another had taken it over must leave the new owner's lock alone.
+	dir := t.TempDir()
+	s, late := testScheduler(dir, 1), testScheduler(dir, 1)
+	staleLock(t, s, 0)
+	lock := s.path(0) + ".lock"
+	seen, err := os.Stat(lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, ok := s.claim(0)
+	if !ok {
+		t.Fatal("stale lock not taken over")
+	}
+	defer release()
+	owned, err := os.Stat(lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if late.takeOver(lock, seen) {
+		t.Fatal("took over a lock claimed since it was found stale")
+	}
+	if st, err := os.Stat(lock); err != nil || !os.SameFile(st, owned) {
+		t.Errorf("new owner's lock not put back: %v", err)
+	}
+	if _, ok := late.claim(0); ok {
+		t.Error("claimed a fresh lock")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("files left behind: %v", entries)
+	}
+}
+
+func TestSchedulerConcurrentRuns(t *testing.T) {
+	// Two runs sharing a directory, with a stale lock from a killed run
+	// among the frames, render every frame exactly once between them.
+	dir := t.TempDir()
+	const frames = 24
+	staleLock(t, testScheduler(dir, frames), 5)
+	var (
+		mu    sync.Mutex
+		count [frames]int
+		wg    sync.WaitGroup
+	)
+	for range 2 {
+		wg.Go(func() {
+			s := testScheduler(dir, frames)
+			s.manifest = ""
+			s.lockStale = 200 * time.Millisecond // not to wait long on the other run
+			err := s.run(context.Background(), 3, func(ctx context.Context, w, i int) (string, error) {
+				mu.Lock()
+				count[i]++
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				return "", writeTestFrame(s, i)
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	for i, n := range count {
+		if n != 1 {
+			t.Errorf("frame %d rendered %d times", i, n)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/anim"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// scoutMetrics are the accepted -metric values.
+var scoutMetrics = []string{"entropy", "boundary"}
+
+// scoutMain runs the "scout" subcommand: starting from a view, it renders
+// a handful of random sub-windows coarsely, zooms into the one that scores
+// best and repeats, then prints where it ended up and optionally renders
+// it. The same seed always takes the same path.
+func scoutMain(args []string) {
+	fs := flag.NewFlagSet("scout", flag.ExitOnError)
+	from := fs.String("from", "default", "starting view: a name ("+viewNames()+"), bookmark:N or cx,cy,width")
+	levels := fs.Int("levels", 8, "zoom levels to descend")
+	candidates := fs.Int("candidates", 16, "sub-windows scored at each level")
+	zoom := fs.Float64("zoom", 4, "zoom factor per level")
+	seed := fs.Uint64("seed", 0, "random seed for the candidate windows (0 = pick one and print it)")
+	minBoundary := fs.Float64("min-boundary", 0.02, "fraction of probe pixels that must lie on the edge of the set for a window to be considered")
+	metric := fs.String("metric", "entropy", "interest metric ("+strings.Join(scoutMetrics, ", ")+"): entropy of the escape counts or boundary density")
+	probe := fs.Int("probe", 64, "width in pixels of the coarse probe renders")
+	width := fs.Int("width", 640, "image width in pixels; sets the aspect ratio of the windows")
+	height := fs.Int("height", 480, "image height in pixels")
+	iters := fs.Int("iters", 300, "iteration count at the starting view")
+	itersPerOctave := fs.Int("iters-per-octave", 50, "iterations added each time the view width halves")
+	pal := fs.String("palette", render.DefaultPalette, "palette name (case-sensitive) for -out")
+	out := fs.String("out", "", "render the view found to this file")
+	bookmarks := fs.String("bookmarks", "bookmarks.txt", "bookmarks file for bookmark:N views")
+	concurrency := fs.Int("procs", runtime.NumCPU(), "concurrent worker count")
+	fs.Parse(args)
+
+	switch {
+	case *levels < 1 || *candidates < 1:
+		fail("", fmt.Errorf("%w: -levels %d, -candidates %d: must be positive", render.ErrInvalidOptions, *levels, *candidates))
+	case !(*zoom > 1) || math.IsInf(*zoom, 0):
+		fail("", fmt.Errorf("%w: -zoom %g: must be finite and above 1", render.ErrInvalidOptions, *zoom))
+	case !(*minBoundary >= 0 && *minBoundary <= 1):
+		fail("", fmt.Errorf("%w: -min-boundary %g: must be within [0,1]", render.ErrInvalidOptions, *minBoundary))
+	case !slices.Contains(scoutMetrics, *metric):
+		fail("", fmt.Errorf("%w: -metric %q: not one of %s", render.ErrInvalidOptions, *metric, strings.Join(scoutMetrics, ", ")))
+	case *probe < 2:
+		fail("", fmt.Errorf("%w: -probe %d: must be at least 2", render.ErrInvalidOptions, *probe))
+	case *itersPerOctave < 0:
+		fail("", fmt.Errorf("%w: -iters-per-octave %d: must not be negative", render.ErrInvalidOptions, *itersPerOctave))
+	}
+	start, err := parseView(*from, *bookmarks, *width, *height)
+	if err != nil {
+		fail("-from: ", err)
+	}
+	var format string
+	if *out != "" {
+		if format, err = render.FormatFromPath(*out); err != nil {
+			fail("", err)
+		}
+	}
+	itersAt := func(v anim.View) int {
+		return *iters + int(math.Round(float64(*itersPerOctave)*max(math.Log2(start.Width/v.Width), 0)))
+	}
+
+	pw := *probe
+	ph := max(1, int(math.Round(float64(pw)*float64(*height)/float64(*width))))
+	opts, err := render.New(
+		render.WithSize(pw, ph),
+		render.WithViewport(start.Bounds(pw, ph)),
+		render.WithIterations(*iters),
+		render.WithPaletteName(*pal),
+		render.WithProcs(*concurrency),
+	)
+	if err != nil {
+		fail("invalid options:\n", err)
+	}
+	r, err := render.NewRenderer(opts)
+	if err != nil {
+		fail("", err)
+	}
+	defer r.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	began := time.Now()
+	s := scout{
+		r:           r,
+		rng:         globalRand(*seed),
+		width:       pw,
+		height:      ph,
+		candidates:  *candidates,
+		zoom:        *zoom,
+		minBoundary: *minBoundary,
+		metric:      *metric,
+		itersAt:     itersAt,
+	}
+	cur := start
+	for level := 1; level <= *levels; level++ {
+		next, p, ok, err := s.step(ctx, cur)
+		if err != nil {
+			fail("", err)
+		}
+		if !ok {
+			fmt.Printf("level %d: no window has a boundary density of at least %g; stopping\n", level, *minBoundary)
+			break
+		}
+		cur = next
+		fmt.Printf("level %d: %s  entropy %.3f bits  boundary %.3f\n", level, formatView(cur), p.entropy, p.boundary)
+	}
+	fmt.Printf("Scouted in %v\n", time.Since(began).Round(time.Millisecond))
+	fmt.Printf("View:  %s\n", formatView(cur))
+	fmt.Printf("Zoom:  %.6gx\n", start.Width/cur.Width)
+
+	final, err := render.New(
+		render.WithSize(*width, *height),
+		render.WithViewport(cur.Bounds(*width, *height)),
+		render.WithIterations(itersAt(cur)),
+		render.WithPaletteName(*pal),
+		render.WithProcs(*concurrency),
+	)
+	if err != nil {
+		fail("invalid options:\n", err)
+	}
+	fmt.Println(render.BuildReproduceCommand(final))
+	if *out == "" {
+		return
+	}
+	res, err := render.Render(ctx, final)
+	if err != nil {
+		fail("", err)
+	}
+	if err := writeFrame(ctx, *out, res, format); err != nil {
+		fail("", err)
+	}
+	fmt.Printf("Wrote %s in %v\n", *out, res.Stats.Elapsed.Round(time.Millisecond))
+}
+
+// formatView writes v in the cx,cy,width form -from and -view accept,
+// with every float exact.
+func formatView(v anim.View) string {
+	f := func(x float64) string { return strconv.FormatFloat(x, 'g', -1, 64) }
+	return f(real(v.Center)) + "," + f(imag(v.Center)) + "," + f(v.Width)
+}
+
+// scout holds the state of a scout descent.
+type scout struct {
+	r             *render.Renderer
+	rng           *rand.Rand
+	width, height int // probe size
+	candidates    int
+	zoom          float64
+	minBoundary   float64
+	metric        string
+	itersAt       func(anim.View) int
+}
+
+// probeScore is what a coarse render of a window measured.
+type probeScore struct {
+	entropy  float64 // of the escape-count histogram, in bits
+	boundary float64 // fraction of pixels on the edge of the interior
+}
+
+// step scores s.candidates random windows 1/s.zoom the width of cur that
+// lie wholly inside it and returns the best one that passes the boundary
+// threshold. Ties go to the earliest candidate, so a fixed seed fixes
+// the result. ok is false when no candidate passes.
+func (s *scout) step(ctx context.Context, cur anim.View) (best anim.View, score probeScore, ok bool, err error) {
+	w := cur.Width / s.zoom
+	spanX := cur.Width - w
+	spanY := spanX * float64(s.height) / float64(s.width)
+	bestKey := math.Inf(-1)
+	for range s.candidates {
+		// draw both coordinates whether or not the window is kept, so each
+		// candidate uses the same random numbers regardless of the scores
+		dx, dy := s.rng.Float64()-0.5, s.rng.Float64()-0.5
+		v := anim.View{Center: cur.Center + complex(dx*spanX, dy*spanY), Width: w}
+		res, err := s.r.Render(ctx, v.Viewport(s.width, s.height), render.WithIterations(s.itersAt(v)))
+		if err != nil {
+			return anim.View{}, probeScore{}, false, err
+		}
+		p := measureProbe(res)
+		if p.boundary < s.minBoundary {
+			continue
+		}
+		key := p.entropy
+		if s.metric == "boundary" {
+			key = p.boundary
+		}
+		if key > bestKey {
+			best, score, ok, bestKey = v, p, true, key
+		}
+	}
+	return best, score, ok, nil
+}
+
+// measureProbe scores a coarse render. The entropy is that of the
+// distribution of integer escape counts, with the interior as one more
+// value: flat exterior and solid interior both score near zero, and
+// views with many bands in even measure score highest. A pixel is on
+// the boundary when one of its four neighbours is on the other side of
+// the interior mask.
+func measureProbe(res *render.Result) probeScore {
+	w, h := res.Options.Width, res.Options.Height
+	// counts[0] is the interior, counts[n+1] escape count n; a slice
+	// rather than a map so the entropy is summed in the same order
+	// every time
+	counts := make([]int, res.Options.MaxIter+2)
+	edge := 0
+	for y := range h {
+		for x := range w {
+			i := y*w + x
+			if res.Inside[i] {
+				counts[0]++
+			} else {
+				counts[min(int(res.Iters[i]), res.Options.MaxIter)+1]++
+			}
+			in := res.Inside[i]
+			if x > 0 && res.Inside[i-1] != in || x < w-1 && res.Inside[i+1] != in ||
+				y > 0 && res.Inside[i-w] != in || y < h-1 && res.Inside[i+w] != in {
+				edge++
+			}
+		}
+	}
+	n := float64(w * h)
+	var p probeScore
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		q := float64(c) / n
+		p.entropy -= q * math.Log2(q)
+	}
+	p.boundary = float64(edge) / n
+	return p
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/anim"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// testScout returns a scout descending from the default view with probes
+// of 48×36 pixels and 16 candidates a level.
+func testScout(t *testing.T, seed uint64, metric string, procs int) *scout {
+	t.Helper()
+	opts, err := render.New(render.WithSize(48, 36), render.WithIterations(300), render.WithProcs(procs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := render.NewRenderer(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	start := anim.ViewOf(render.DefaultBounds)
+	return &scout{
+		r: r, rng: globalRand(seed), width: 48, height: 36,
+		candidates: 16, zoom: 4, minBoundary: 0.02, metric: metric,
+		itersAt: func(v anim.View) int {
+			return 300 + int(math.Round(50*max(math.Log2(start.Width/v.Width), 0)))
+		},
+	}
+}
+
+// descend runs s for levels levels from the default view and returns the
+// views it visits.
+func descend(t *testing.T, s *scout, levels int) []anim.View {
+	t.Helper()
+	cur := anim.ViewOf(render.DefaultBounds)
+	var path []anim.View
+	for range levels {
+		next, p, ok, err := s.step(context.Background(), cur)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		if p.boundary < s.minBoundary {
+			t.Errorf("took a window with boundary density %v, under %v", p.boundary, s.minBoundary)
+		}
+		// the new window lies inside the old one, zoomed by 4
+		if math.Abs(next.Width*4/cur.Width-1) > 1e-12 ||
+			math.Abs(real(next.Center-cur.Center)) > (cur.Width-next.Width)/2 ||
+			math.Abs(imag(next.Center-cur.Center)) > (cur.Width-next.Width)*36/48/2 {
+			t.Errorf("%+v isn't a quarter-width window inside %+v", next, cur)
+		}
+		cur = next
+		path = append(path, cur)
+	}
+	return path
+}
+
+func TestScoutDeterministic(t *testing.T) {
+	for _, metric := range scoutMetrics {
+		a := descend(t, testScout(t, 42, metric, 4), 4)
+		if len(a) < 2 {
+			t.Fatalf("%s: stopped after %d levels", metric, len(a))
+		}
+		for _, procs := range []int{4, 1} {
+			b := descend(t, testScout(t, 42, metric, procs), 4)
+			if len(b) != len(a) {
+				t.Fatalf("%s, %d procs: %d levels, then %d", metric, procs, len(a), len(b))
+			}
+			for i := range a {
+				if a[i] != b[i] {
+					t.Errorf("%s, %d procs: level %d went to %+v, then %+v", metric, procs, i+1, a[i], b[i])
+				}
+			}
+		}
+	}
+	// another seed takes another path
+	a, b := descend(t, testScout(t, 42, "entropy", 4), 2), descend(t, testScout(t, 7, "entropy", 4), 2)
+	if len(a) == 2 && len(b) == 2 && a[1] == b[1] {
+		t.Errorf("seeds 42 and 7 both went to %+v", a[1])
+	}
+}
+
+func TestScoutStopsInExterior(t *testing.T) {
+	s := testScout(t, 1, "entropy", 2)
+	// far outside the set no window has any boundary
+	if _, _, ok, err := s.step(context.Background(), anim.View{Center: 3 + 3i, Width: 0.5}); err != nil || ok {
+		t.Errorf("step outside the set: ok %v, err %v", ok, err)
+	}
+}
+
+func TestMeasureProbe(t *testing.T) {
+	// a 4×2 probe: the left half interior, the right escaping at 3 and 5
+	res := &render.Result{
+		Options: render.Options{Width: 4, Height: 2, MaxIter: 10},
+		Inside:  []bool{true, true, false, false, true, true, false, false},
+		Iters:   []float64{10, 10, 3, 5, 10, 10, 3.5, 5.9},
+	}
+	p := measureProbe(res)
+	// half interior, a quarter each at 3 and 5
+	if want := 1.5; math.Abs(p.entropy-want) > 1e-12 {
+		t.Errorf("entropy %v, want %v", p.entropy, want)
+	}
+	if p.boundary != 0.5 {
+		t.Errorf("boundary %v, want 0.5", p.boundary)
+	}
+}
+
+func TestScoutPrintedSeed(t *testing.T) {
+	// a run without -seed goes the same way again from the seed it printed
+	var a []anim.View
+	out := captureStderr(t, func() { a = descend(t, testScout(t, 0, "entropy", 4), 3) })
+	var seed uint64
+	if _, err := fmt.Sscanf(out, "seed %d\n", &seed); err != nil {
+		t.Fatalf("printed %q", out)
+	}
+	if b := descend(t, testScout(t, seed, "entropy", 4), 3); !slices.Equal(a, b) {
+		t.Errorf("seed %d went %+v, the run that printed it %+v", seed, b, a)
+	}
+}
@@ -0,0 +1,299 @@
+// Package scratch holds an image too large for memory in a file on disk,
+// for renders of print-sized stills.
+//
+// The image is cut into square tiles, the last row and column of them
+// cut short by the image edges. A Buffer takes the tiles in any order as
+// they are rendered and reads the image back a row at a time, as the
+// encoders want it. Recently written tiles are kept in memory up to a
+// byte limit, and only the ones pushed out are written to the file.
+//
+// The file starts with a 32-byte header: the magic "MBSCRATCH1", six zero
+// bytes, then the width, height and tile size as big-endian uint32s and
+// four more zero bytes. The tiles follow in row-major order, each as
+// straight RGBA rows of its own width, premultiplied as in image.RGBA. A
+// tile never written reads as transparent black.
+package scratch
+
+import (
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"sync"
+)
+
+// Defaults for New.
+const (
+	DefaultTileSize   = 256
+	DefaultCacheBytes = 64 << 20
+)
+
+// magic opens every scratch file.
+const magic = "MBSCRATCH1"
+
+// headerLen is the size of the file header.
+const headerLen = 32
+
+// Buffer is a disk-backed RGBA image. WriteTile and the reading methods
+// are safe for concurrent use.
+type Buffer struct {
+	f             *os.File
+	width, height int
+	tile          int
+	cols, rows    int // tile grid
+
+	mu         sync.Mutex
+	cacheBytes int
+	cached     int                   // bytes of tiles in the cache
+	lru        *list.List            // of *cachedTile, most recent first
+	byIndex    map[int]*list.Element // tile index to its lru element
+	band       []byte                // the rows At last read
+	bandY      int                   // the first of them, -1 for none
+	err        error                 // first read error of At
+}
+
+// cachedTile is a tile held in memory. dirty tiles haven't been written
+// to the file yet.
+type cachedTile struct {
+	i     int
+	pix   []byte
+	dirty bool
+}
+
+// New creates a scratch file in dir ("" for os.TempDir) for a
+// width×height image cut into tile×tile tiles, keeping up to cacheBytes
+// of tiles in memory. Zero tile and cacheBytes take the defaults; a
+// cacheBytes below one tile keeps none.
+func New(dir string, width, height, tile, cacheBytes int) (*Buffer, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("scratch: size %dx%d: must be positive", width, height)
+	}
+	if tile < 0 || cacheBytes < 0 {
+		return nil, fmt.Errorf("scratch: tile size %d and cache size %d: must not be negative", tile, cacheBytes)
+	}
+	if tile == 0 {
+		tile = DefaultTileSize
+	}
+	if cacheBytes == 0 {
+		cacheBytes = DefaultCacheBytes
+	}
+	f, err := os.CreateTemp(dir, "mandelbrot-*.scratch")
+	if err != nil {
+		return nil, err
+	}
+	hdr := make([]byte, headerLen)
+	copy(hdr, magic)
+	binary.BigEndian.PutUint32(hdr[16:], uint32(width))
+	binary.BigEndian.PutUint32(hdr[20:], uint32(height))
+	binary.BigEndian.PutUint32(hdr[24:], uint32(tile))
+	if _, err := f.Write(hdr); err == nil {
+		// the tiles' space, sparse until written
+		err = f.Truncate(headerLen + 4*int64(width)*int64(height))
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &Buffer{
+		f:          f,
+		width:      width,
+		height:     height,
+		tile:       tile,
+		cols:       (width + tile - 1) / tile,
+		rows:       (height + tile - 1) / tile,
+		cacheBytes: cacheBytes,
+		lru:        list.New(),
+		byIndex:    make(map[int]*list.Element),
+		bandY:      -1,
+	}, nil
+}
+
+// Path returns the name of the scratch file.
+func (b *Buffer) Path() string { return b.f.Name() }
+
+// NumTiles returns the number of tiles, indexed from 0 in row-major
+// order.
+func (b *Buffer) NumTiles() int { return b.cols * b.rows }
+
+// TileRect returns the pixels of tile i.
+func (b *Buffer) TileRect(i int) image.Rectangle {
+	x, y := i%b.cols*b.tile, i/b.cols*b.tile
+	return image.Rect(x, y, min(x+b.tile, b.width), min(y+b.tile, b.height))
+}
+
+// offset returns where tile i starts in the file.
+func (b *Buffer) offset(i int) int64 {
+	r := b.TileRect(i)
+	// whole tile rows above it, then the tiles to its left in its own row
+	return headerLen + 4*(int64(b.width)*int64(r.Min.Y)+int64(r.Dy())*int64(r.Min.X))
+}
+
+// WriteTile stores img as tile i. img must cover exactly TileRect(i).
+func (b *Buffer) WriteTile(i int, img *image.RGBA) error {
+	if i < 0 || i >= b.NumTiles() {
+		return fmt.Errorf("scratch: tile %d: out of range [0, %d)", i, b.NumTiles())
+	}
+	r := b.TileRect(i)
+	if img.Rect != r {
+		return fmt.Errorf("scratch: tile %d: image covers %v, not %v", i, img.Rect, r)
+	}
+	pix := make([]byte, 4*r.Dx()*r.Dy())
+	for y := range r.Dy() {
+		copy(pix[4*r.Dx()*y:4*r.Dx()*(y+1)], img.Pix[y*img.Stride:])
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.bandY >= 0 && b.bandY < r.Max.Y && b.bandY+bandRows > r.Min.Y {
+		b.bandY = -1
+	}
+	if len(pix) > b.cacheBytes {
+		return b.writeAt(i, pix)
+	}
+	if e, ok := b.byIndex[i]; ok {
+		b.cached -= len(e.Value.(*cachedTile).pix)
+		b.lru.Remove(e)
+	}
+	b.byIndex[i] = b.lru.PushFront(&cachedTile{i, pix, true})
+	b.cached += len(pix)
+	return b.evict()
+}
+
+// evict writes tiles out of the cache, least recently used first, until
+// it is within its limit. The caller holds b.mu.
+func (b *Buffer) evict() error {
+	for b.cached > b.cacheBytes {
+		e := b.lru.Back()
+		t := e.Value.(*cachedTile)
+		if t.dirty {
+			if err := b.writeAt(t.i, t.pix); err != nil {
+				return err
+			}
+		}
+		b.lru.Remove(e)
+		delete(b.byIndex, t.i)
+		b.cached -= len(t.pix)
+	}
+	return nil
+}
+
+func (b *Buffer) writeAt(i int, pix []byte) error {
+	if _, err := b.f.WriteAt(pix, b.offset(i)); err != nil {
+		return fmt.Errorf("scratch: writing tile %d: %w", i, err)
+	}
+	return nil
+}
+
+// ReadRow copies row y of the image, 4·width bytes of RGBA, into dst.
+// Tiles still in the cache are read from memory, the rest from the file
+// a tile's width at a time.
+func (b *Buffer) ReadRow(y int, dst []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.readRow(y, dst)
+}
+
+func (b *Buffer) readRow(y int, dst []byte) error {
+	if y < 0 || y >= b.height {
+		return fmt.Errorf("scratch: row %d: out of range [0, %d)", y, b.height)
+	}
+	if len(dst) < 4*b.width {
+		return fmt.Errorf("scratch: row buffer of %d bytes: want %d", len(dst), 4*b.width)
+	}
+	ty := y / b.tile
+	for tx := range b.cols {
+		i := ty*b.cols + tx
+		r := b.TileRect(i)
+		seg := dst[4*r.Min.X : 4*r.Max.X]
+		within := 4 * r.Dx() * (y - r.Min.Y)
+		if e, ok := b.byIndex[i]; ok {
+			b.lru.MoveToFront(e)
+			copy(seg, e.Value.(*cachedTile).pix[within:])
+			continue
+		}
+		if _, err := b.f.ReadAt(seg, b.offset(i)+int64(within)); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("scratch: reading row %d: %w", y, err)
+		}
+	}
+	return nil
+}
+
+// bandRows is how many rows At reads at once: the height of a JPEG
+// macroblock, which the JPEG encoder reads a block at a time.
+const bandRows = 16
+
+// Bounds, ColorModel and At make the Buffer an image.Image the encoders
+// can read. At reads bandRows rows at a time and keeps the last band, so
+// reading in row or macroblock order reads each pixel of the file once.
+func (b *Buffer) Bounds() image.Rectangle { return image.Rect(0, 0, b.width, b.height) }
+
+func (b *Buffer) ColorModel() color.Model { return color.RGBAModel }
+
+func (b *Buffer) At(x, y int) color.Color { return b.RGBAAt(x, y) }
+
+// RGBAAt returns the pixel at (x, y). A read error gives transparent
+// black and is kept for Err.
+func (b *Buffer) RGBAAt(x, y int) color.RGBA {
+	if !(image.Point{x, y}.In(b.Bounds())) {
+		return color.RGBA{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.bandY < 0 || y < b.bandY || y >= b.bandY+bandRows {
+		if b.band == nil {
+			b.band = make([]byte, 4*b.width*bandRows)
+		}
+		b.bandY = -1
+		y0 := y - y%bandRows
+		for r := y0; r < min(y0+bandRows, b.height); r++ {
+			if err := b.readRow(r, b.band[4*b.width*(r-y0):]); err != nil {
+				if b.err == nil {
+					b.err = err
+				}
+				return color.RGBA{}
+			}
+		}
+		b.bandY = y0
+	}
+	i := 4 * (b.width*(y-b.bandY) + x)
+	p := b.band[i : i+4 : i+4]
+	return color.RGBA{p[0], p[1], p[2], p[3]}
+}
+
+// Err returns the first error At ran into.
+func (b *Buffer) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+// Flush writes the tiles still in memory to the file, which then holds
+// the whole image.
+func (b *Buffer) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for e := b.lru.Front(); e != nil; e = e.Next() {
+		if t := e.Value.(*cachedTile); t.dirty {
+			if err := b.writeAt(t.i, t.pix); err != nil {
+				return err
+			}
+			t.dirty = false
+		}
+	}
+	return b.f.Sync()
+}
+
+// Close closes the scratch file and keeps it, for salvage after a failed
+// render; call Flush first for it to hold the cached tiles too.
+func (b *Buffer) Close() error { return b.f.Close() }
+
+// Remove closes and deletes the scratch file.
+func (b *Buffer) Remove() error {
+	b.f.Close()
+	return os.Remove(b.f.Name())
+}
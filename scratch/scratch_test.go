@@ -0,0 +1,301 @@
+package scratch
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// pattern is an image over r whose every pixel differs from its
+// neighbours, so a tile stored or read at the wrong place shows.
+func pattern(r image.Rectangle) *image.RGBA {
+	img := image.NewRGBA(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y), uint8(x ^ y), 0xff})
+		}
+	}
+	return img
+}
+
+// writeAll writes every tile of b, cut out of img, last tile first.
+func writeAll(t *testing.T, b *Buffer, img *image.RGBA) {
+	t.Helper()
+	for i := b.NumTiles() - 1; i >= 0; i-- {
+		tile := image.NewRGBA(b.TileRect(i))
+		for y := tile.Rect.Min.Y; y < tile.Rect.Max.Y; y++ {
+			for x := tile.Rect.Min.X; x < tile.Rect.Max.X; x++ {
+				tile.SetRGBA(x, y, img.RGBAAt(x, y))
+			}
+		}
+		if err := b.WriteTile(i, tile); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// sameRows reports the first row of b that differs from img, or -1.
+func sameRows(t *testing.T, b *Buffer, img *image.RGBA) int {
+	t.Helper()
+	row := make([]byte, 4*b.width)
+	for y := range b.height {
+		if err := b.ReadRow(y, row); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(row, img.Pix[y*img.Stride:y*img.Stride+4*b.width]) {
+			return y
+		}
+	}
+	return -1
+}
+
+func TestTileRect(t *testing.T) {
+	b, err := New(t.TempDir(), 10, 7, 4, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Remove()
+	want := []image.Rectangle{
+		image.Rect(0, 0, 4, 4), image.Rect(4, 0, 8, 4), image.Rect(8, 0, 10, 4),
+		image.Rect(0, 4, 4, 7), image.Rect(4, 4, 8, 7), image.Rect(8, 4, 10, 7),
+	}
+	if b.NumTiles() != len(want) {
+		t.Fatalf("%d tiles, want %d", b.NumTiles(), len(want))
+	}
+	for i, r := range want {
+		if got := b.TileRect(i); got != r {
+			t.Errorf("tile %d: %v, want %v", i, got, r)
+		}
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	img := pattern(image.Rect(0, 0, 37, 23))
+	for _, tc := range []struct {
+		name  string
+		tile  int
+		cache int
+	}{
+		{"no cache", 8, 1},
+		{"two tiles cached", 8, 2 * 4 * 8 * 8},
+		{"everything cached", 8, 1 << 20},
+		{"one tile", 64, 1},
+		{"one-pixel tiles", 1, 4 * 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := New(t.TempDir(), 37, 23, tc.tile, tc.cache)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer b.Remove()
+			writeAll(t, b, img)
+			if y := sameRows(t, b, img); y >= 0 {
+				t.Fatalf("row %d differs", y)
+			}
+			// At, in the JPEG encoder's order: 16×16 blocks
+			for by := 0; by < 23; by += 16 {
+				for bx := 0; bx < 37; bx += 16 {
+					for y := by; y < min(by+16, 23); y++ {
+						for x := bx; x < min(bx+16, 37); x++ {
+							if got, want := b.At(x, y), img.RGBAAt(x, y); got != want {
+								t.Fatalf("At(%d, %d) = %v, want %v", x, y, got, want)
+							}
+						}
+					}
+				}
+			}
+			if err := b.Err(); err != nil {
+				t.Fatal(err)
+			}
+			var got, want bytes.Buffer
+			if err := png.Encode(&got, b); err != nil {
+				t.Fatal(err)
+			}
+			png.Encode(&want, img)
+			if !bytes.Equal(got.Bytes(), want.Bytes()) {
+				t.Error("PNG from the buffer differs from the PNG of the image")
+			}
+		})
+	}
+}
+
+func TestBufferRewrite(t *testing.T) {
+	// a rewritten tile replaces the old one whether that is cached, in
+	// the file or in the band At last read
+	for _, cache := range []int{1, 1 << 20} {
+		b, err := New(t.TempDir(), 20, 20, 10, cache)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img := pattern(image.Rect(0, 0, 20, 20))
+		writeAll(t, b, img)
+		if got := b.RGBAAt(12, 3); got != img.RGBAAt(12, 3) {
+			t.Fatalf("cache %d: before: %v", cache, got)
+		}
+		tile := image.NewRGBA(b.TileRect(1))
+		for i := range tile.Pix {
+			tile.Pix[i] = 0x80
+		}
+		if err := b.WriteTile(1, tile); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.RGBAAt(12, 3); got != (color.RGBA{0x80, 0x80, 0x80, 0x80}) {
+			t.Errorf("cache %d: after rewriting: %v", cache, got)
+		}
+		if got := b.RGBAAt(3, 3); got != img.RGBAAt(3, 3) {
+			t.Errorf("cache %d: neighbouring tile: %v", cache, got)
+		}
+		b.Remove()
+	}
+}
+
+func TestBufferUnwritten(t *testing.T) {
+	b, err := New(t.TempDir(), 20, 20, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Remove()
+	tile := pattern(b.TileRect(3))
+	if err := b.WriteTile(3, tile); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.RGBAAt(5, 5); got != (color.RGBA{}) {
+		t.Errorf("unwritten tile: %v", got)
+	}
+	if got := b.RGBAAt(15, 15); got != tile.RGBAAt(15, 15) {
+		t.Errorf("written tile: %v", got)
+	}
+	if got := b.RGBAAt(-1, 25); got != (color.RGBA{}) {
+		t.Errorf("outside: %v", got)
+	}
+}
+
+func TestBufferKept(t *testing.T) {
+	dir := t.TempDir()
+	b, err := New(dir, 13, 9, 5, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := pattern(image.Rect(0, 0, 13, 9))
+	writeAll(t, b, img)
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(b.Path()) != dir {
+		t.Errorf("scratch file %s not in %s", b.Path(), dir)
+	}
+	data, err := os.ReadFile(b.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != headerLen+4*13*9 {
+		t.Fatalf("file of %d bytes", len(data))
+	}
+	if string(data[:len(magic)]) != magic {
+		t.Errorf("magic %q", data[:len(magic)])
+	}
+	for i, want := range []uint32{13, 9, 5} {
+		if got := binary.BigEndian.Uint32(data[16+4*i:]); got != want {
+			t.Errorf("header field %d = %d, want %d", i, got, want)
+		}
+	}
+	// tile 4, the second of the second row, is 5×4 and starts after the
+	// first row of tiles and the 5×4 tile to its left
+	off := headerLen + 4*(13*5+5*4)
+	for y := range 4 {
+		for x := range 5 {
+			p := data[off+4*(5*y+x):]
+			if got, want := (color.RGBA{p[0], p[1], p[2], p[3]}), img.RGBAAt(5+x, 5+y); got != want {
+				t.Fatalf("tile 4 (%d, %d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+
+	b, err = New(dir, 4, 4, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(b.Path()); !os.IsNotExist(err) {
+		t.Errorf("removed file: %v", err)
+	}
+}
+
+func TestBufferErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, size := range [][4]int{{0, 10, 0, 0}, {10, -1, 0, 0}, {10, 10, -1, 0}, {10, 10, 0, -1}} {
+		if _, err := New(dir, size[0], size[1], size[2], size[3]); err == nil {
+			t.Errorf("New%v: no error", size)
+		}
+	}
+	if _, err := New(filepath.Join(dir, "missing"), 10, 10, 0, 0); err == nil {
+		t.Error("missing directory: no error")
+	}
+
+	b, err := New(dir, 10, 10, 4, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Remove()
+	if err := b.WriteTile(9, pattern(image.Rect(0, 0, 4, 4))); err == nil {
+		t.Error("tile out of range: no error")
+	}
+	if err := b.WriteTile(2, pattern(image.Rect(8, 0, 12, 4))); err == nil {
+		t.Error("tile past the edge: no error")
+	}
+	row := make([]byte, 40)
+	if err := b.ReadRow(10, row); err == nil {
+		t.Error("row out of range: no error")
+	}
+	if err := b.ReadRow(0, row[:39]); err == nil {
+		t.Error("short row buffer: no error")
+	}
+}
+
+func TestBufferMatchesRender(t *testing.T) {
+	// a print-sized image through a buffer that caches no tiles, with
+	// tiles that don't divide it, rendered last to first
+	w, h := 8192, 8192
+	if testing.Short() {
+		w, h = 1000, 700
+	}
+	opts, err := render.New(render.WithSize(w, h), render.WithIterations(30), render.WithDiscardBuffers(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New(t.TempDir(), w, h, 300, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Remove()
+	ctx := context.Background()
+	for i := b.NumTiles() - 1; i >= 0; i-- {
+		tile, err := render.RenderRect(ctx, opts, b.TileRect(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := b.WriteTile(i, tile); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := render.Render(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y := sameRows(t, b, res.Image); y >= 0 {
+		t.Fatalf("row %d differs from the in-memory render", y)
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// server answers /render, /tiles and gRPC requests, running at most cap(slots)
+// renders at a time.
+type server struct {
+	slots     chan struct{}
+	procs     int           // workers per render
+	maxPixels int           // 0 means no limit
+	maxIters  int           // 0 means no limit
+	timeout   time.Duration // per render, waiting for a slot included; 0 means no limit
+	tiles     tileConfig
+	cache     *tileCache
+	metrics   *serverMetrics
+	bookmarks *bookmarkFile
+}
+
+// serveMain runs the "serve" subcommand.
+func serveMain(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := fs.String("listen", ":8080", "address to listen on")
+	grpcAddr := fs.String("grpc", "", "also serve the gRPC Renderer service on this address (e.g. :9090)")
+	concurrent := fs.Int("max-concurrent", runtime.NumCPU(), "renders allowed to run at once; the rest wait")
+	maxPixels := fs.Int("max-pixels", 4096*4096, "largest accepted w*h (0 = no limit)")
+	maxIters := fs.Int("max-iters", 20000, "largest accepted iters (0 = no limit)")
+	renderTimeout := fs.Duration("render-timeout", 30*time.Second, "longest a /render or /tiles request may wait and render before it is abandoned with a 503 (0 = no limit)")
+	tileCX := fs.Float64("tile-cx", -0.5, "real part of the tile root center")
+	tileCY := fs.Float64("tile-cy", 0, "imaginary part of the tile root center")
+	tileSpan := fs.Float64("tile-span", 4, "side length of the square shown by tile 0/0/0")
+	tileIters := fs.Int("tile-iters", 256, "iterations at tile zoom 0")
+	tileItersPerZoom := fs.Int("tile-iters-per-zoom", 128, "iterations added per tile zoom level")
+	cacheSize := fs.Int("tile-cache", 1024, "tiles kept in the in-memory cache")
+	bookmarks := fs.String("bookmarks", "bookmarks.txt", "bookmarks file read and appended by /bookmarks")
+	fs.Parse(args)
+
+	if *concurrent < 1 {
+		*concurrent = 1
+	}
+	s := &server{
+		slots:     make(chan struct{}, *concurrent),
+		procs:     max(1, runtime.NumCPU() / *concurrent),
+		maxPixels: *maxPixels,
+		maxIters:  *maxIters,
+		timeout:   *renderTimeout,
+		tiles: tileConfig{
+			root:         coords.Bounds{Xmin: *tileCX - *tileSpan/2, Xmax: *tileCX + *tileSpan/2, Ymin: *tileCY - *tileSpan/2, Ymax: *tileCY + *tileSpan/2},
+			iters:        *tileIters,
+			itersPerZoom: *tileItersPerZoom,
+		},
+		cache:     newTileCache(*cacheSize),
+		metrics:   newServerMetrics(),
+		bookmarks: &bookmarkFile{path: *bookmarks},
+	}
+	srv := &http.Server{Addr: *listen, Handler: s.handler()}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+	if *grpcAddr != "" {
+		go func() {
+			if err := s.serveGRPC(ctx, *grpcAddr); err != nil {
+				fail("", err)
+			}
+		}()
+	}
+
+	log.Printf("listening on %s", *listen)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fail("", err)
+	}
+}
+
+// handler routes the server's endpoints, logging and counting every
+// request.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /render", s.handleRender)
+	mux.HandleFunc("POST /render", s.handleRenderJSON)
+	mux.HandleFunc("GET /render/options", s.handleRenderOptions)
+	mux.HandleFunc("GET /tiles/{z}/{x}/{y}", s.handleTile)
+	mux.HandleFunc("GET /stream", s.handleStream)
+	mux.HandleFunc("GET /stream.html", handleStreamDemo)
+	mux.HandleFunc("GET /palettes", handlePalettes)
+	mux.HandleFunc("GET /palettes/{name}", handlePalette)
+	mux.HandleFunc("POST /palettes", handleAddPalette)
+	mux.HandleFunc("GET /bookmarks", s.handleBookmarks)
+	mux.HandleFunc("POST /bookmarks", s.handleAddBookmark)
+	mux.HandleFunc("GET /{$}", handleDemo)
+	mux.Handle("GET /metrics", s.metrics.handler())
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	return s.logRequests(mux)
+}
+
+// handleRender serves GET /render?cx=&cy=&zoom=&w=&h=&iters=&palette=&coloring=
+// as a PNG. The request context cancels the render if the client goes
+// away, and the server's timeout if it runs too long.
+func (s *server) handleRender(w http.ResponseWriter, r *http.Request) {
+	opts, err := s.parseRender(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	png, err := s.renderPNG(r.Context(), opts)
+	if err != nil {
+		s.renderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(png)))
+	w.Write(png)
+}
+
+// handleRenderJSON serves POST /render, whose body is the JSON form of
+// render.Options, as a PNG. The server's limits apply as for GET.
+func (s *server) handleRenderJSON(w http.ResponseWriter, r *http.Request) {
+	var opts render.Options
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&opts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errs := s.limitErrors(opts.Width, opts.Height, opts.MaxIter); len(errs) > 0 {
+		http.Error(w, errors.Join(errs...).Error(), http.StatusBadRequest)
+		return
+	}
+	opts.Procs = s.procs
+	opts.Metrics = s.metrics
+	opts.DiscardBuffers = true
+
+	png, err := s.renderPNG(r.Context(), opts)
+	if err != nil {
+		s.renderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(png)))
+	w.Write(png)
+}
+
+// handleRenderOptions serves GET /render/options, which takes the GET
+// /render query and answers with the options it stands for as JSON, ready
+// to be edited and sent to POST /render.
+func (s *server) handleRenderOptions(w http.ResponseWriter, r *http.Request) {
+	opts, err := s.parseRender(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, opts)
+}
+
+// errRenderTimeout is the cause of a render cut short by the server's
+// timeout, as opposed to one whose client went away.
+var errRenderTimeout = errors.New("render timed out")
+
+// renderPNG waits for a free slot, then renders opts and encodes it as PNG.
+// A ctx that ends while waiting or rendering yields an error matching
+// render.ErrCancelled; the server's timeout running out yields one that
+// matches errRenderTimeout as well.
+func (s *server) renderPNG(ctx context.Context, opts render.Options) (png []byte, err error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, s.timeout, errRenderTimeout)
+		defer cancel()
+		defer func() {
+			if err != nil && context.Cause(ctx) == errRenderTimeout {
+				err = fmt.Errorf("%w after %v: %w", errRenderTimeout, s.timeout, err)
+			}
+		}()
+	}
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return nil, &render.CancelledError{Total: opts.Height, Err: ctx.Err()}
+	}
+	res, err := render.Render(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := render.Encode(&buf, res.Image, "png"); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderError answers a request whose renderPNG failed: 503 if it ran
+// out of time, nothing if the client went away, as there is no one to
+// tell, and 500 for anything else.
+func (s *server) renderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errRenderTimeout):
+		http.Error(w, fmt.Sprintf("render took longer than %v", s.timeout), http.StatusServiceUnavailable)
+	case errors.Is(err, render.ErrCancelled):
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// parseRender builds render options from the query. Missing parameters
+// take the CLI defaults; the view is DefaultBounds fitted to w×h, then
+// centered on cx+cy·i and zoomed in by zoom.
+func (s *server) parseRender(q url.Values) (render.Options, error) {
+	def := render.DefaultBounds.Center()
+	var errs []error
+	cx := floatParam(q, "cx", real(def), &errs)
+	cy := floatParam(q, "cy", imag(def), &errs)
+	zoom := floatParam(q, "zoom", 1, &errs)
+	width := intParam(q, "w", 800, &errs)
+	height := intParam(q, "h", 600, &errs)
+	iters := intParam(q, "iters", render.DefaultMaxIter, &errs)
+	pal := q.Get("palette")
+	if pal == "" {
+		pal = render.DefaultPalette
+	}
+	coloring := render.Coloring(q.Get("coloring"))
+	if coloring == "" {
+		coloring = render.DefaultColoring
+	}
+	if !(zoom > 0) {
+		errs = append(errs, fmt.Errorf("%w: zoom %g: must be positive", render.ErrInvalidViewport, zoom))
+	}
+	errs = append(errs, s.limitErrors(width, height, iters)...)
+	if len(errs) > 0 {
+		return render.Options{}, errors.Join(errs...)
+	}
+
+	bounds := render.DefaultBounds
+	if width > 0 && height > 0 {
+		bounds = bounds.FitToImage(width, height).ZoomedTo(complex(cx, cy), zoom)
+	}
+	return render.New(
+		render.WithSize(width, height),
+		render.WithViewport(bounds),
+		render.WithIterations(iters),
+		render.WithPaletteName(pal),
+		render.WithColoring(coloring),
+		render.WithProcs(s.procs),
+		render.WithMetrics(s.metrics),
+		render.WithDiscardBuffers(true),
+	)
+}
+
+// limitErrors reports a size or iteration count above the server's limits.
+func (s *server) limitErrors(width, height, iters int) []error {
+	var errs []error
+	// width > maxPixels/height is width*height > maxPixels without the
+	// product, which a large enough query overflows; sizes of 0 or less
+	// are left to Validate
+	if s.maxPixels > 0 && width > 0 && height > 0 && width > s.maxPixels/height {
+		errs = append(errs, fmt.Errorf("%w: image size %dx%d: above the %d pixel limit", render.ErrInvalidOptions, width, height, s.maxPixels))
+	}
+	if s.maxIters > 0 && iters > s.maxIters {
+		errs = append(errs, fmt.Errorf("%w: iterations %d: above the limit of %d", render.ErrInvalidOptions, iters, s.maxIters))
+	}
+	return errs
+}
+
+// floatParam parses q[name], returning def when it is absent. A malformed
+// value is recorded in errs.
+func floatParam(q url.Values, name string, def float64, errs *[]error) float64 {
+	v := q.Get(name)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%w: %s=%q: not a number", render.ErrInvalidOptions, name, v))
+	}
+	return f
+}
+
+// intParam is floatParam for integers.
+func intParam(q url.Values, name string, def int, errs *[]error) int {
+	v := q.Get(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%w: %s=%q: not an integer", render.ErrInvalidOptions, name, v))
+	}
+	return n
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	sr.status = code
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Write(p []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(p)
+}
+
+// Hijack lets WebSocket upgrades through; the connection is logged as 101.
+func (sr *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := sr.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	sr.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (sr *statusRecorder) Unwrap() http.ResponseWriter { return sr.ResponseWriter }
+
+// logRequests logs and counts each request with its status and duration.
+// A request abandoned by the client before any response is logged as 499.
+func (s *server) logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sr := &statusRecorder{ResponseWriter: w}
+		h.ServeHTTP(sr, r)
+		status := sr.status
+		switch {
+		case status != 0:
+		case r.Context().Err() != nil:
+			status = 499
+		default:
+			status = http.StatusOK
+		}
+		elapsed := time.Since(start)
+		s.metrics.observeRequest(endpointLabel(r), httpOutcome(status), elapsed)
+		log.Printf("%s %s %d %v", r.Method, r.URL.RequestURI(), status, elapsed.Round(time.Millisecond))
+	})
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func TestLimitErrors(t *testing.T) {
+	s := &server{maxPixels: 4096 * 4096, maxIters: 20000}
+	for _, tc := range []struct {
+		name                 string
+		width, height, iters int
+		wantErrs             int
+	}{
+		{"within the limits", 1920, 1080, 1000, 0},
+		{"at the pixel limit", 4096, 4096, 20000, 0},
+		{"one row over", 4096, 4097, 1000, 1},
+		{"too many iterations", 64, 64, 20001, 1},
+		{"both", 8192, 8192, 1 << 20, 2},
+		// 2^32 squared wraps a 64-bit int to 0
+		{"product overflows", 1 << 32, 1 << 32, 1000, 1},
+		{"product overflows negative", 1 << 40, 1 << 30, 1000, 1},
+		// left to Validate
+		{"zero width", 0, 1 << 62, 1000, 0},
+		{"negative size", -1, -1, 1000, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := s.limitErrors(tc.width, tc.height, tc.iters)
+			if len(errs) != tc.wantErrs {
+				t.Fatalf("limitErrors(%d, %d, %d) = %v, want %d errors", tc.width, tc.height, tc.iters, errs, tc.wantErrs)
+			}
+			for _, err := range errs {
+				if !errors.Is(err, render.ErrInvalidOptions) {
+					t.Errorf("%v doesn't match ErrInvalidOptions", err)
+				}
+			}
+		})
+	}
+
+	unlimited := &server{}
+	if errs := unlimited.limitErrors(1<<32, 1<<32, 1<<40); len(errs) != 0 {
+		t.Errorf("no limits: got %v", errs)
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	srv := httptest.NewServer(testServer().handler())
+	defer srv.Close()
+	get := func(path string) []byte {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: %s: %s", path, resp.Status, body)
+		}
+		return body
+	}
+	post := func(body string) (int, []byte) {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/render", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, b
+	}
+
+	// the JSON for a query renders the same PNG as the query
+	const query = "?cx=-0.75&cy=0.1&zoom=40&w=64&h=48&iters=300&palette=ThermalHeat"
+	opts := get("/render/options" + query)
+	var o render.Options
+	if err := json.Unmarshal(opts, &o); err != nil {
+		t.Fatalf("%s: %v", opts, err)
+	}
+	if o.Width != 64 || o.Height != 48 || o.MaxIter != 300 || o.Palette.Keyword != "ThermalHeat" {
+		t.Errorf("options %s", opts)
+	}
+	status, png := post(string(opts))
+	if status != http.StatusOK {
+		t.Fatalf("POST: %d: %s", status, png)
+	}
+	if want := get("/render" + query); !bytes.Equal(png, want) {
+		t.Error("POST /render and GET /render gave different images")
+	}
+
+	for _, body := range []string{
+		`{"width": 8192, "height": 8192}`,
+		`{"maxIter": 1000000}`,
+		`{"palette": "NoSuchPalette"}`,
+		`{"width": `,
+	} {
+		if status, msg := post(body); status != http.StatusBadRequest {
+			t.Errorf("%s: %d: %s", body, status, msg)
+		}
+	}
+}
+
+// slowRender is a /render query for an image that takes seconds to
+// render, most of it the cardioid's interior at the iteration limit.
+const slowRender = "/render?cx=-0.3&cy=0&zoom=4&w=3000&h=3000&iters=20000"
+
+func TestRenderCancelled(t *testing.T) {
+	// A request whose context ends, as when the client goes away, stops
+	// its render and gives back its slot at once, writing nothing.
+	s := testServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequestWithContext(ctx, "GET", slowRender, nil)
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		s.handler().ServeHTTP(rec, req)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("handler still running 200ms after the request was cancelled")
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("cancelled request got a response: %d %s", rec.Code, rec.Body)
+	}
+	if n := len(s.slots); n != 0 {
+		t.Errorf("%d slots still held", n)
+	}
+}
+
+func TestRenderClientDisconnect(t *testing.T) {
+	// Over a real connection: the client giving up closes it, which ends
+	// the request context on the server and so the render.
+	s := testServer()
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL+slowRender, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := http.DefaultClient.Do(req); err == nil {
+		resp.Body.Close()
+		t.Fatalf("request finished with %s before the client gave up", resp.Status)
+	}
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for len(s.slots) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("render still holding its slot 200ms after the client went away")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRenderTimeout(t *testing.T) {
+	s := testServer()
+	s.timeout = 100 * time.Millisecond
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+	get := func(path string) (int, time.Duration) {
+		t.Helper()
+		start := time.Now()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return resp.StatusCode, time.Since(start)
+	}
+
+	// a render that runs too long
+	if status, took := get(slowRender); status != http.StatusServiceUnavailable || took > time.Second {
+		t.Errorf("slow render: %d after %v, want 503 after about 100ms", status, took)
+	}
+
+	// a request that can't get a slot in time
+	for range cap(s.slots) {
+		s.slots <- struct{}{}
+	}
+	if status, took := get("/render?w=64&h=48&iters=100"); status != http.StatusServiceUnavailable || took > time.Second {
+		t.Errorf("waiting for a slot: %d after %v, want 503 after about 100ms", status, took)
+	}
+	for range cap(s.slots) {
+		<-s.slots
+	}
+
+	// and one that fits
+	if status, _ := get("/render?w=64&h=48&iters=100"); status != http.StatusOK {
+		t.Errorf("small render: %d, want 200", status)
+	}
+}
@@ -0,0 +1,61 @@
+// Package shader loads pixel shaders, Go plugins that color each pixel of
+// a render in place of the palette.
+//
+// A shader is a package main built with
+//
+//	go build -buildmode=plugin -o myshader.so ./myshader
+//
+// that exports a variable named Shader holding a PixelShader. See
+// examples/shader for one that reproduces the built-in smooth coloring.
+// Plugins only load on the platforms the plugin package supports (Linux,
+// FreeBSD and macOS, with cgo), and must be built with the same Go
+// version and the same version of this module as the program loading
+// them.
+package shader
+
+import (
+	"fmt"
+	"image/color"
+	"plugin"
+)
+
+// SymbolName is the name of the variable Load looks up in a plugin.
+const SymbolName = "Shader"
+
+// PixelShader colors one pixel. x and y are its position in the
+// width×height image and c the point of the plane it samples. iter is
+// the iteration its orbit escaped at, or the iteration limit if it
+// didn't, and z the first iterate outside the escape radius or the last
+// one (0 for interior points caught without iterating). t is the
+// palette position the render's coloring gave the pixel, in [0,1].
+//
+// Shade is called from every render worker at once, so it must be safe
+// for concurrent use.
+type PixelShader interface {
+	Shade(x, y, width, height int, c complex128, iter int, z complex128, t float64) color.RGBA
+}
+
+// Load opens the plugin at path and returns its Shader. The variable may
+// be declared as a PixelShader or as any type implementing it.
+func Load(path string) (PixelShader, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("shader: %w", err)
+	}
+	sym, err := p.Lookup(SymbolName)
+	if err != nil {
+		return nil, fmt.Errorf("shader: %s: %w", path, err)
+	}
+	// Lookup returns a pointer to the variable; a *T also has T's methods
+	switch s := sym.(type) {
+	case *PixelShader:
+		if *s == nil {
+			return nil, fmt.Errorf("shader: %s: %s is nil", path, SymbolName)
+		}
+		return *s, nil
+	case PixelShader:
+		return s, nil
+	default:
+		return nil, fmt.Errorf("shader: %s: %s is a %T, not a PixelShader", path, SymbolName, sym)
+	}
+}
@@ -0,0 +1,63 @@
+package shader_test
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/render"
+	"github.com/whalelogic/mandlebrot/shader"
+)
+
+// buildExample builds examples/shader as a plugin and returns its path.
+func buildExample(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds a plugin")
+	}
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" && runtime.GOOS != "freebsd" {
+		t.Skipf("plugins aren't supported on %s", runtime.GOOS)
+	}
+	gobin := filepath.Join(runtime.GOROOT(), "bin", "go")
+	so := filepath.Join(t.TempDir(), "smooth.so")
+	cmd := exec.Command(gobin, "build", "-buildmode=plugin", "-o", so, "../examples/shader")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("can't build the plugin: %v\n%s", err, out)
+	}
+	return so
+}
+
+func TestExampleShader(t *testing.T) {
+	s, err := shader.Load(buildExample(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 100 pixels across the set and its boundary
+	render10 := func(opts ...render.Option) *render.Result {
+		o, err := render.New(append([]render.Option{render.WithSize(10, 10), render.WithIterations(300)}, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := render.Render(context.Background(), o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	want, got := render10(), render10(render.WithShader(s))
+	for y := range 10 {
+		for x := range 10 {
+			if a, b := got.Image.RGBAAt(x, y), want.Image.RGBAAt(x, y); a != b {
+				t.Errorf("(%d, %d): shader %v, smooth coloring %v", x, y, a, b)
+			}
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := shader.Load(filepath.Join(t.TempDir(), "missing.so")); err == nil {
+		t.Error("no error for a missing plugin")
+	}
+}
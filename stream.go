@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// previewScale is the downscaling of the first, coarse pass.
+const previewScale = 8
+
+var upgrader = websocket.Upgrader{ReadBufferSize: 4096, WriteBufferSize: 64 << 10}
+
+// streamMsg is a JSON text message sent on /stream.
+type streamMsg struct {
+	Type    string `json:"type"` // "start", "progress", "done" or "error"
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+	Done    int    `json:"done,omitempty"`
+	Total   int    `json:"total,omitempty"`
+	Elapsed string `json:"elapsed,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// handleStream renders progressively over a WebSocket. The client sends
+// one JSON object with the /render query parameters. The server answers
+// with a "start" message, a 1/8-resolution preview, then bands of
+// finished rows as they complete, interleaved with "progress" messages,
+// and finally "done" or "error".
+//
+// Pixel messages are binary: five little-endian uint32s (scale, x0, y0,
+// x1, y1) followed by the RGBA pixels of that rectangle. The rectangle is
+// in the coordinates of an image scale times smaller than the final one,
+// so the preview has scale 8 and full-resolution bands scale 1.
+//
+// Closing the connection cancels the render, and so does the server's
+// timeout, which ends the stream with an "error" saying so. A slow client never stalls
+// the workers: finished rows are collected in a buffer and whatever has
+// accumulated is sent as one band when the connection is ready again.
+func (s *server) handleStream(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade has replied
+	}
+	defer conn.Close()
+
+	var params map[string]any
+	if err := conn.ReadJSON(&params); err != nil {
+		return
+	}
+	q := url.Values{}
+	for k, v := range params {
+		q.Set(k, fmt.Sprint(v))
+	}
+	opts, err := s.parseRender(q)
+	if err != nil {
+		conn.WriteJSON(streamMsg{Type: "error", Message: err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	if s.timeout > 0 {
+		var stop context.CancelFunc
+		ctx, stop = context.WithTimeoutCause(ctx, s.timeout, errRenderTimeout)
+		defer stop()
+	}
+	// timedOut tells the client when it was the timeout, rather than the
+	// client going away, that ended the render
+	timedOut := func() {
+		if context.Cause(ctx) == errRenderTimeout {
+			conn.WriteJSON(streamMsg{Type: "error", Message: fmt.Sprintf("render took longer than %v", s.timeout)})
+		}
+	}
+	go func() {
+		// the client sends nothing more; a read error means it went away
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		timedOut()
+		return
+	}
+
+	start := time.Now()
+	if err := conn.WriteJSON(streamMsg{Type: "start", Width: opts.Width, Height: opts.Height}); err != nil {
+		return
+	}
+
+	preview := opts
+	preview.Width = max(1, opts.Width/previewScale)
+	preview.Height = max(1, opts.Height/previewScale)
+	res, err := render.Render(ctx, preview)
+	if err != nil {
+		timedOut()
+		return
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, pixelMsg(previewScale, res.Image.Rect, res.Image.Pix)); err != nil {
+		return
+	}
+
+	st := newStreamState(opts.Width, opts.Height)
+	opts.OnRegion = st.addRegion
+	opts.OnProgress = st.setProgress
+	opts.ProgressInterval = 250 * time.Millisecond
+	sent := make(chan error, 1)
+	go func() {
+		err := st.send(ctx, conn)
+		if err != nil {
+			cancel()
+		}
+		sent <- err
+	}()
+
+	_, err = render.Render(ctx, opts)
+	st.finish()
+	werr := <-sent
+	if ctx.Err() != nil {
+		timedOut()
+		return
+	}
+	if werr != nil {
+		return
+	}
+	if err != nil {
+		conn.WriteJSON(streamMsg{Type: "error", Message: err.Error()})
+		return
+	}
+	conn.WriteJSON(streamMsg{Type: "done", Elapsed: time.Since(start).Round(time.Millisecond).String()})
+}
+
+// streamState collects finished rows from the render for the sender.
+// The render side only ever takes the lock briefly and pokes wake, whose
+// single slot coalesces any number of updates into one wakeup.
+type streamState struct {
+	mu          sync.Mutex
+	width       int
+	pix         []byte // full-resolution RGBA
+	ready       []bool // row finished
+	sent        []bool // row sent
+	done, total int
+	progressed  bool // progress changed since last sent
+	finished    bool
+	wake        chan struct{}
+}
+
+func newStreamState(width, height int) *streamState {
+	return &streamState{
+		width: width,
+		pix:   make([]byte, 4*width*height),
+		ready: make([]bool, height),
+		sent:  make([]bool, height),
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+func (st *streamState) poke() {
+	select {
+	case st.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (st *streamState) addRegion(rect image.Rectangle, px []color.RGBA) {
+	st.mu.Lock()
+	for i, c := range px {
+		x, y := rect.Min.X+i%rect.Dx(), rect.Min.Y+i/rect.Dx()
+		o := 4 * (y*st.width + x)
+		st.pix[o], st.pix[o+1], st.pix[o+2], st.pix[o+3] = c.R, c.G, c.B, c.A
+	}
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		st.ready[y] = true
+	}
+	st.mu.Unlock()
+	st.poke()
+}
+
+func (st *streamState) setProgress(done, total int) {
+	st.mu.Lock()
+	st.done, st.total, st.progressed = done, total, true
+	st.mu.Unlock()
+	st.poke()
+}
+
+// finish tells the sender that no more rows will arrive.
+func (st *streamState) finish() {
+	st.mu.Lock()
+	st.finished = true
+	st.mu.Unlock()
+	st.poke()
+}
+
+// send writes progress and bands of contiguous finished rows to conn
+// until finish has been called and everything is out.
+func (st *streamState) send(ctx context.Context, conn *websocket.Conn) error {
+	for {
+		select {
+		case <-st.wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		st.mu.Lock()
+		var msgs [][]byte
+		for y := 0; y < len(st.ready); {
+			if !st.ready[y] || st.sent[y] {
+				y++
+				continue
+			}
+			y0 := y
+			for y < len(st.ready) && st.ready[y] && !st.sent[y] {
+				st.sent[y] = true
+				y++
+			}
+			rect := image.Rect(0, y0, st.width, y)
+			msgs = append(msgs, pixelMsg(1, rect, st.pix[4*y0*st.width:4*y*st.width]))
+		}
+		var progress *streamMsg
+		if st.progressed {
+			progress = &streamMsg{Type: "progress", Done: st.done, Total: st.total}
+			st.progressed = false
+		}
+		finished := st.finished
+		st.mu.Unlock()
+
+		for _, m := range msgs {
+			if err := conn.WriteMessage(websocket.BinaryMessage, m); err != nil {
+				return err
+			}
+		}
+		if progress != nil {
+			b, _ := json.Marshal(progress)
+			if err := conn.WriteMessage(websocket.TextMessage, b); err != nil {
+				return err
+			}
+		}
+		if finished {
+			return nil
+		}
+	}
+}
+
+// pixelMsg encodes a binary pixel message.
+func pixelMsg(scale int, rect image.Rectangle, pix []byte) []byte {
+	b := make([]byte, 20, 20+len(pix))
+	for i, v := range []int{scale, rect.Min.X, rect.Min.Y, rect.Max.X, rect.Max.Y} {
+		binary.LittleEndian.PutUint32(b[4*i:], uint32(v))
+	}
+	return append(b, pix...)
+}
+
+//go:embed web/stream.html
+var streamPage []byte
+
+// handleStreamDemo serves the example client for /stream.
+func handleStreamDemo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(streamPage)
+}
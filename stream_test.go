@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestStreamTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		busy bool // every slot taken, so the timeout runs out waiting
+	}{
+		{"waiting for a slot", true},
+		{"rendering", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &server{slots: make(chan struct{}, 1), timeout: 50 * time.Millisecond, metrics: newServerMetrics()}
+			if tc.busy {
+				s.slots <- struct{}{}
+			}
+			srv := httptest.NewServer(http.HandlerFunc(s.handleStream))
+			defer srv.Close()
+
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+			// far more work than fits in the timeout
+			if err := conn.WriteJSON(map[string]any{"w": 2000, "h": 2000, "iters": 20000}); err != nil {
+				t.Fatal(err)
+			}
+			for {
+				kind, b, err := conn.ReadMessage()
+				if err != nil {
+					t.Fatalf("stream ended without an error message: %v", err)
+				}
+				if kind != websocket.TextMessage {
+					continue
+				}
+				switch msg := string(b); {
+				case strings.Contains(msg, `"type":"error"`):
+					if !strings.Contains(msg, "longer than 50ms") {
+						t.Errorf("error message %s doesn't mention the timeout", msg)
+					}
+					return
+				case strings.Contains(msg, `"type":"done"`):
+					t.Fatalf("render finished within the timeout")
+				}
+			}
+		})
+	}
+}
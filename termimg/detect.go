@@ -0,0 +1,73 @@
+package termimg
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"strings"
+)
+
+// Protocol is a way of drawing an image on a terminal.
+type Protocol string
+
+const (
+	ANSI  Protocol = "ansi"  // half-block characters with ANSI colors
+	Sixel Protocol = "sixel" // DEC Sixel graphics
+	Kitty Protocol = "kitty" // Kitty graphics protocol
+)
+
+// Protocols lists the supported protocols by flag name.
+var Protocols = []Protocol{ANSI, Sixel, Kitty}
+
+// Detect guesses the best protocol the terminal supports from the
+// environment, falling back to ANSI. Terminals rarely advertise graphics
+// support, so this only recognizes ones known to implement it.
+func Detect() Protocol {
+	termName := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || termName == "xterm-kitty" || program == "ghostty":
+		return Kitty
+	case program == "WezTerm" || strings.HasPrefix(termName, "foot") ||
+		strings.HasPrefix(termName, "mlterm") || strings.Contains(termName, "sixel") ||
+		strings.Contains(os.Getenv("LC_TERMINAL"), "iTerm2"):
+		return Sixel
+	}
+	return ANSI
+}
+
+// Write draws img on the terminal with protocol p. For ANSI, img is
+// expected at CellPixels resolution; the raster protocols show it pixel
+// for pixel.
+func Write(w io.Writer, img image.Image, p Protocol) error {
+	switch p {
+	case ANSI:
+		return WriteANSI(w, img, TrueColor())
+	case Sixel:
+		return WriteSixel(w, img)
+	case Kitty:
+		return WriteKitty(w, img)
+	}
+	return fmt.Errorf("termimg: unknown protocol %q", p)
+}
+
+// Window is the size of a terminal window.
+type Window struct {
+	Cols, Rows    int
+	Width, Height int // in pixels, 0 when the terminal doesn't report them
+}
+
+// ImageSize returns the image size that fills w with protocol p, leaving
+// the last row free for the prompt. When the pixel size is unknown the
+// raster protocols assume 10×20 pixel cells.
+func (w Window) ImageSize(p Protocol) (width, height int) {
+	rows := max(w.Rows-1, 1)
+	if p == ANSI {
+		return CellPixels(w.Cols, rows)
+	}
+	if w.Width == 0 || w.Height == 0 {
+		return 10 * w.Cols, 20 * rows
+	}
+	return w.Width, w.Height * rows / w.Rows
+}
@@ -0,0 +1,43 @@
+package termimg
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// kittyChunk is the largest base64 payload the Kitty protocol accepts per
+// escape sequence.
+const kittyChunk = 4096
+
+// WriteKitty draws img with the Kitty graphics protocol, sending its
+// RGBA pixels base64-encoded in chunks.
+func WriteKitty(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	rgba, ok := img.(*image.RGBA)
+	if !ok || rgba.Stride != 4*b.Dx() {
+		rgba = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(rgba, rgba.Rect, img, b.Min, draw.Src)
+	}
+	data := base64.StdEncoding.EncodeToString(rgba.Pix[:4*b.Dx()*b.Dy()])
+
+	bw := bufio.NewWriter(w)
+	for i := 0; i < len(data); i += kittyChunk {
+		end := min(i+kittyChunk, len(data))
+		more := 0
+		if end < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			// a=T: transmit and display; f=32: 8-bit RGBA
+			fmt.Fprintf(bw, "\x1b_Ga=T,f=32,s=%d,v=%d,m=%d;%s\x1b\\", b.Dx(), b.Dy(), more, data[i:end])
+		} else {
+			fmt.Fprintf(bw, "\x1b_Gm=%d;%s\x1b\\", more, data[i:end])
+		}
+	}
+	bw.WriteString("\n")
+	return bw.Flush()
+}
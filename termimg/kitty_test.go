@@ -0,0 +1,68 @@
+package termimg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"regexp"
+	"testing"
+)
+
+func TestWriteKittyChunks(t *testing.T) {
+	// 40×40 RGBA is 6400 bytes, 8536 in base64: three chunks
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i * 7)
+	}
+	var buf bytes.Buffer
+	if err := WriteKitty(&buf, img.SubImage(img.Rect)); err != nil {
+		t.Fatal(err)
+	}
+	seqs := regexp.MustCompile("\x1b_G([^;]*);([^\x1b]*)\x1b\\\\").FindAllStringSubmatch(buf.String(), -1)
+	if len(seqs) != 3 {
+		t.Fatalf("%d escape sequences, want 3", len(seqs))
+	}
+	wantKeys := []string{"a=T,f=32,s=40,v=40,m=1", "m=1", "m=0"}
+	var data string
+	for i, s := range seqs {
+		if s[1] != wantKeys[i] {
+			t.Errorf("chunk %d: keys %q, want %q", i, s[1], wantKeys[i])
+		}
+		if len(s[2]) > kittyChunk {
+			t.Errorf("chunk %d: %d bytes of payload", i, len(s[2]))
+		}
+		data += s[2]
+	}
+	pix, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pix, img.Pix) {
+		t.Error("payload differs from the pixels")
+	}
+}
+
+func TestWriteKittySubImage(t *testing.T) {
+	// a sub-image's rows aren't contiguous, so they are copied out first
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i)
+	}
+	sub := img.SubImage(image.Rect(2, 3, 5, 5)).(*image.RGBA)
+	var buf bytes.Buffer
+	if err := WriteKitty(&buf, sub); err != nil {
+		t.Fatal(err)
+	}
+	m := regexp.MustCompile("s=3,v=2,m=0;([^\x1b]*)").FindStringSubmatch(buf.String())
+	if m == nil {
+		t.Fatalf("no single 3×2 chunk in %q", buf.String())
+	}
+	pix, _ := base64.StdEncoding.DecodeString(m[1])
+	var want []byte
+	for y := 3; y < 5; y++ {
+		want = append(want, img.Pix[img.PixOffset(2, y):img.PixOffset(5, y)]...)
+	}
+	if !bytes.Equal(pix, want) {
+		t.Errorf("payload %v, want %v", pix, want)
+	}
+}
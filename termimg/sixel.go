@@ -0,0 +1,97 @@
+package termimg
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"io"
+)
+
+// WriteSixel draws img as a DEC Sixel graphic. The image is dithered
+// onto the 216-color web-safe palette first, since Sixel allows at most
+// 256 color registers.
+func WriteSixel(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	pm := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), palette.WebSafe)
+	draw.FloydSteinberg.Draw(pm, pm.Rect, img, b.Min)
+	bw := bufio.NewWriter(w)
+	encodeSixel(bw, pm)
+	return bw.Flush()
+}
+
+// encodeSixel writes pm as Sixel. Each band of six rows is sent once per
+// color present in it: the color's register, then one character per
+// column whose low six bits mark the rows of that column in this color,
+// with runs compressed as !n<char>. '$' returns to the start of the band
+// for the next color and '-' moves to the next band.
+func encodeSixel(w *bufio.Writer, pm *image.Paletted) {
+	width, height := pm.Rect.Dx(), pm.Rect.Dy()
+	// P2=1: pixels left unset stay transparent rather than background
+	fmt.Fprintf(w, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	for i, c := range pm.Palette {
+		r, g, b, _ := c.RGBA()
+		// components are percentages
+		fmt.Fprintf(w, "#%d;2;%d;%d;%d", i, (r*100+0x7fff)/0xffff, (g*100+0x7fff)/0xffff, (b*100+0x7fff)/0xffff)
+	}
+
+	bits := make([]byte, width)
+	used := make([]bool, len(pm.Palette))
+	for y0 := 0; y0 < height; y0 += 6 {
+		y1 := min(y0+6, height)
+		clear(used)
+		for y := y0; y < y1; y++ {
+			for _, idx := range pm.Pix[y*pm.Stride : y*pm.Stride+width] {
+				used[idx] = true
+			}
+		}
+		first := true
+		for ci, ok := range used {
+			if !ok {
+				continue
+			}
+			for x := range width {
+				var v byte
+				for y := y0; y < y1; y++ {
+					if pm.Pix[y*pm.Stride+x] == uint8(ci) {
+						v |= 1 << (y - y0)
+					}
+				}
+				bits[x] = v
+			}
+			if !first {
+				w.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(w, "#%d", ci)
+			writeSixelRuns(w, bits)
+		}
+		w.WriteByte('-')
+	}
+	w.WriteString("\x1b\\")
+}
+
+// writeSixelRuns writes one color's sixel characters for a band,
+// run-length encoding repeats longer than three. Trailing empty columns
+// are left out.
+func writeSixelRuns(w *bufio.Writer, bits []byte) {
+	for len(bits) > 0 && bits[len(bits)-1] == 0 {
+		bits = bits[:len(bits)-1]
+	}
+	for x := 0; x < len(bits); {
+		run := 1
+		for x+run < len(bits) && bits[x+run] == bits[x] {
+			run++
+		}
+		ch := '?' + bits[x]
+		if run > 3 {
+			fmt.Fprintf(w, "!%d%c", run, ch)
+		} else {
+			for range run {
+				w.WriteByte(ch)
+			}
+		}
+		x += run
+	}
+}
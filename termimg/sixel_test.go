@@ -0,0 +1,149 @@
+package termimg
+
+import (
+	"bufio"
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteSixelRuns(t *testing.T) {
+	for _, tc := range []struct {
+		bits []byte
+		want string
+	}{
+		{[]byte{1, 1, 1, 1, 1, 2}, "!5@A"},
+		{[]byte{0, 3, 3, 3}, "?BBB"},       // a run of three is shorter spelled out
+		{[]byte{63, 0, 0, 0, 0, 0}, "~"},   // trailing empty columns are dropped
+		{[]byte{0, 0, 0, 0, 0, 1}, "!5?@"}, // leading ones aren't
+		{[]byte{0, 0}, ""},
+	} {
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		writeSixelRuns(w, tc.bits)
+		w.Flush()
+		if got := buf.String(); got != tc.want {
+			t.Errorf("writeSixelRuns(%v) = %q, want %q", tc.bits, got, tc.want)
+		}
+	}
+}
+
+func TestEncodeSixelKnownBytes(t *testing.T) {
+	pm := image.NewPaletted(image.Rect(0, 0, 2, 1), color.Palette{color.Black, color.White})
+	pm.Pix[1] = 1
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	encodeSixel(w, pm)
+	w.Flush()
+	want := "\x1bP0;1;0q\"1;1;2;1#0;2;0;0;0#1;2;100;100;100#0@$#1?@-\x1b\\"
+	if got := buf.String(); got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+// decodeSixel is a reference decoder for the subset of Sixel that
+// encodeSixel writes, returning the color register of every pixel of a
+// width×height image, or -1 where none was set.
+func decodeSixel(t *testing.T, data string, width, height int) []int {
+	t.Helper()
+	start := strings.Index(data, "q")
+	end := strings.LastIndex(data, "\x1b\\")
+	if start < 0 || end < start {
+		t.Fatalf("no sixel data in %q", data)
+	}
+	s := data[start+1 : end]
+	pix := make([]int, width*height)
+	for i := range pix {
+		pix[i] = -1
+	}
+	number := func() int {
+		n := 0
+		for len(s) > 0 && s[0] >= '0' && s[0] <= '9' {
+			n = 10*n + int(s[0]-'0')
+			s = s[1:]
+		}
+		return n
+	}
+	x, y, reg := 0, 0, 0
+	put := func(ch byte, run int) {
+		for range run {
+			for bit := range 6 {
+				if (ch-'?')&(1<<bit) != 0 {
+					if x >= width || y+bit >= height {
+						t.Fatalf("pixel (%d, %d) outside %dx%d", x, y+bit, width, height)
+					}
+					pix[(y+bit)*width+x] = reg
+				}
+			}
+			x++
+		}
+	}
+	for len(s) > 0 {
+		c := s[0]
+		s = s[1:]
+		switch {
+		case c == '"': // raster attributes
+			for range 4 {
+				number()
+				s = strings.TrimPrefix(s, ";")
+			}
+		case c == '#':
+			reg = number()
+			if strings.HasPrefix(s, ";") { // a definition, not a selection
+				for range 4 {
+					s = s[1:]
+					number()
+				}
+			}
+		case c == '!':
+			run := number()
+			put(s[0], run)
+			s = s[1:]
+		case c == '$':
+			x = 0
+		case c == '-':
+			x, y = 0, y+6
+		case c >= '?' && c <= '~':
+			put(c, 1)
+		default:
+			t.Fatalf("unexpected %q", c)
+		}
+	}
+	return pix
+}
+
+func TestEncodeSixelDecodes(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	pal := color.Palette{}
+	for i := range 12 {
+		pal = append(pal, color.RGBA{uint8(20 * i), 0, uint8(255 - 20*i), 0xff})
+	}
+	for _, size := range []image.Point{{1, 1}, {7, 13}, {40, 6}, {33, 25}} {
+		pm := image.NewPaletted(image.Rectangle{Max: size}, pal)
+		for i := range pm.Pix {
+			// runs, so the encoder compresses some
+			if i == 0 || r.IntN(3) == 0 {
+				pm.Pix[i] = uint8(r.IntN(len(pal)))
+			} else {
+				pm.Pix[i] = pm.Pix[i-1]
+			}
+		}
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		encodeSixel(w, pm)
+		w.Flush()
+		got := decodeSixel(t, buf.String(), size.X, size.Y)
+		for i, idx := range pm.Pix {
+			if got[i] != int(idx) {
+				t.Fatalf("%v: pixel (%d, %d) decodes as register %d, want %d", size, i%size.X, i/size.X, got[i], idx)
+			}
+		}
+		if !strings.HasPrefix(buf.String(), "\x1bP0;1;0q\"1;1;"+strconv.Itoa(size.X)+";"+strconv.Itoa(size.Y)) {
+			t.Errorf("%v: header %q", size, buf.String()[:20])
+		}
+	}
+}
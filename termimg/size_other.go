@@ -0,0 +1,15 @@
+//go:build !unix
+
+package termimg
+
+import "os"
+
+// WindowSize returns the size of the terminal on f. The pixel dimensions
+// are not available on this platform and are left 0.
+func WindowSize(f *os.File) (Window, error) {
+	cols, rows, err := Size(f)
+	if err != nil {
+		return Window{}, err
+	}
+	return Window{Cols: cols, Rows: rows}, nil
+}
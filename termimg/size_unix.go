@@ -0,0 +1,19 @@
+//go:build unix
+
+package termimg
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// WindowSize returns the size of the terminal on f, including its pixel
+// dimensions where the terminal reports them.
+func WindowSize(f *os.File) (Window, error) {
+	ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return Window{}, err
+	}
+	return Window{Cols: int(ws.Col), Rows: int(ws.Row), Width: int(ws.Xpixel), Height: int(ws.Ypixel)}, nil
+}
@@ -0,0 +1,92 @@
+// Package termimg draws images on terminals.
+package termimg
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Size returns the size of the terminal on f in character cells.
+func Size(f *os.File) (cols, rows int, err error) {
+	return term.GetSize(int(f.Fd()))
+}
+
+// TrueColor reports whether the terminal advertises 24-bit color through
+// COLORTERM.
+func TrueColor() bool {
+	v := os.Getenv("COLORTERM")
+	return v == "truecolor" || v == "24bit"
+}
+
+// CellPixels returns the image size that fills cols×rows character cells
+// with WriteANSI. Cells are taken to be twice as tall as they are wide, so
+// splitting each into two half-block pixels gives square pixels.
+func CellPixels(cols, rows int) (width, height int) {
+	return cols, 2 * rows
+}
+
+// WriteANSI draws img with the upper half block ▀, two pixels per
+// character cell: the top pixel is the foreground color and the bottom
+// the background. Without trueColor the colors are approximated from the
+// xterm 256-color palette. An odd last row is padded with black.
+func WriteANSI(w io.Writer, img image.Image, trueColor bool) error {
+	bw := bufio.NewWriter(w)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y += 2 {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			top := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			bottom := color.RGBA{A: 0xff}
+			if y+1 < b.Max.Y {
+				bottom = color.RGBAModel.Convert(img.At(x, y+1)).(color.RGBA)
+			}
+			if trueColor {
+				fmt.Fprintf(bw, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+			} else {
+				fmt.Fprintf(bw, "\x1b[38;5;%dm\x1b[48;5;%dm▀", Xterm256(top), Xterm256(bottom))
+			}
+		}
+		bw.WriteString("\x1b[0m\n")
+	}
+	return bw.Flush()
+}
+
+// cubeLevels are the channel values of the xterm 6×6×6 color cube.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// Xterm256 returns the xterm 256-color index closest to c, choosing
+// between the 6×6×6 cube (16-231) and the gray ramp (232-255).
+func Xterm256(c color.RGBA) uint8 {
+	r, g, b := int(c.R), int(c.G), int(c.B)
+	ri, gi, bi := cubeIndex(r), cubeIndex(g), cubeIndex(b)
+	cube := 16 + 36*ri + 6*gi + bi
+	cubeDist := sq(cubeLevels[ri]-r) + sq(cubeLevels[gi]-g) + sq(cubeLevels[bi]-b)
+
+	// gray ramp: 24 steps of 10 from 8 to 238
+	avg := (r + g + b) / 3
+	gi2 := min(max((avg-8+5)/10, 0), 23)
+	gv := 8 + 10*gi2
+	grayDist := sq(gv-r) + sq(gv-g) + sq(gv-b)
+	if grayDist < cubeDist {
+		return uint8(232 + gi2)
+	}
+	return uint8(cube)
+}
+
+// cubeIndex returns the index of the cube level closest to v.
+func cubeIndex(v int) int {
+	if v < 48 {
+		return 0
+	}
+	if v < 115 {
+		return 1
+	}
+	return min((v-35)/40, 5)
+}
+
+func sq(v int) int { return v * v }
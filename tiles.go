@@ -0,0 +1,184 @@
+package main
+
+import (
+	"container/list"
+	_ "embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/whalelogic/mandlebrot/coords"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+const (
+	tileSize    = 256
+	maxTileZoom = 45 // past this, float64 can't resolve a tile's pixels
+)
+
+// tileConfig is the XYZ tile pyramid: tile 0/0/0 shows root, and each
+// zoom level halves the tile side and adds itersPerZoom iterations.
+type tileConfig struct {
+	root         coords.Bounds
+	iters        int
+	itersPerZoom int
+}
+
+// bounds returns the window of tile x, y at zoom z. Tile rows run down
+// from root.Ymax at y = 0, matching the image row order within a tile.
+func (tc tileConfig) bounds(z, x, y int) coords.Bounds {
+	side := tc.root.Width() / float64(uint64(1)<<z)
+	xmin := tc.root.Xmin + float64(x)*side
+	ymax := tc.root.Ymax - float64(y)*side
+	return coords.Bounds{Xmin: xmin, Xmax: xmin + side, Ymin: ymax - side, Ymax: ymax}
+}
+
+// handleTile serves GET /tiles/{z}/{x}/{y}.png, accepting the palette and
+// coloring query parameters of /render. Tiles are deterministic, so they
+// are cached, long-lived and carry an ETag.
+func (s *server) handleTile(w http.ResponseWriter, r *http.Request) {
+	z, x, y, err := parseTile(r.PathValue("z"), r.PathValue("x"), r.PathValue("y"))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errNoTile) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	q := r.URL.Query()
+	pal := q.Get("palette")
+	if pal == "" {
+		pal = render.DefaultPalette
+	}
+	coloring := render.Coloring(q.Get("coloring"))
+	if coloring == "" {
+		coloring = render.DefaultColoring
+	}
+	iters := s.tiles.iters + z*s.tiles.itersPerZoom
+	if s.maxIters > 0 {
+		iters = min(iters, s.maxIters)
+	}
+
+	key := fmt.Sprintf("%d/%d/%d?palette=%s&coloring=%s&iters=%d", z, x, y, pal, coloring, iters)
+	ent, ok := s.cache.get(key)
+	s.metrics.tile(ok)
+	if !ok {
+		opts, err := render.New(
+			render.WithSize(tileSize, tileSize),
+			render.WithViewport(s.tiles.bounds(z, x, y)),
+			render.WithIterations(iters),
+			render.WithPaletteName(pal),
+			render.WithColoring(coloring),
+			render.WithProcs(s.procs),
+			render.WithMetrics(s.metrics),
+			render.WithDiscardBuffers(true),
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		png, err := s.renderPNG(r.Context(), opts)
+		if err != nil {
+			s.renderError(w, err)
+			return
+		}
+		ent = s.cache.add(key, png)
+	}
+
+	h := w.Header()
+	h.Set("Cache-Control", "public, max-age=86400, immutable")
+	h.Set("ETag", ent.etag)
+	if r.Header.Get("If-None-Match") == ent.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.Set("Content-Type", "image/png")
+	h.Set("Content-Length", strconv.Itoa(len(ent.png)))
+	w.Write(ent.png)
+}
+
+var errNoTile = errors.New("no such tile")
+
+// parseTile parses the z, x and y path segments; y carries the ".png"
+// suffix.
+func parseTile(zs, xs, ys string) (z, x, y int, err error) {
+	ys, ok := strings.CutSuffix(ys, ".png")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("tile %s/%s/%s: only .png tiles are served", zs, xs, ys)
+	}
+	z, err1 := strconv.Atoi(zs)
+	x, err2 := strconv.Atoi(xs)
+	y, err3 := strconv.Atoi(ys)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return 0, 0, 0, fmt.Errorf("tile %s/%s/%s: %w", zs, xs, ys, err)
+	}
+	if z < 0 || z > maxTileZoom || x < 0 || y < 0 || x >= 1<<z || y >= 1<<z {
+		return 0, 0, 0, fmt.Errorf("%w: %d/%d/%d", errNoTile, z, x, y)
+	}
+	return z, x, y, nil
+}
+
+// tileCache is a fixed-size LRU of encoded tiles.
+type tileCache struct {
+	mu    sync.Mutex
+	max   int
+	ll    *list.List // most recently used at the front
+	items map[string]*list.Element
+}
+
+type tileEntry struct {
+	key  string
+	png  []byte
+	etag string
+}
+
+func newTileCache(max int) *tileCache {
+	return &tileCache{max: max, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *tileCache) get(key string) (*tileEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*tileEntry), true
+	}
+	return nil, false
+}
+
+// add stores png under key, evicting the least recently used tile when
+// full, and returns the entry. With a size of 0 nothing is kept.
+func (c *tileCache) add(key string, png []byte) *tileEntry {
+	h := fnv.New64a()
+	h.Write(png)
+	ent := &tileEntry{key: key, png: png, etag: fmt.Sprintf(`"%x"`, h.Sum64())}
+	if c.max <= 0 {
+		return ent
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*tileEntry)
+	}
+	c.items[key] = c.ll.PushFront(ent)
+	for c.ll.Len() > c.max {
+		old := c.ll.Remove(c.ll.Back()).(*tileEntry)
+		delete(c.items, old.key)
+	}
+	return ent
+}
+
+//go:embed web/index.html
+var demoPage []byte
+
+// handleDemo serves the Leaflet viewer for the tile endpoint.
+func handleDemo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(demoPage)
+}
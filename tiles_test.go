@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func TestParseTile(t *testing.T) {
+	for _, tc := range []struct {
+		z, x, y string
+		want    [3]int
+		err     error // nil for a valid tile, errNoTile for a 404
+		bad     bool  // malformed: a 400
+	}{
+		{z: "0", x: "0", y: "0.png"},
+		{z: "1", x: "1", y: "0.png", want: [3]int{1, 1, 0}},
+		{z: "3", x: "7", y: "7.png", want: [3]int{3, 7, 7}},
+		{z: "45", x: "0", y: "35184372088831.png", want: [3]int{45, 0, 1<<45 - 1}},
+		{z: "1", x: "2", y: "0.png", err: errNoTile},
+		{z: "1", x: "0", y: "2.png", err: errNoTile},
+		{z: "-1", x: "0", y: "0.png", err: errNoTile},
+		{z: "2", x: "-1", y: "0.png", err: errNoTile},
+		{z: "2", x: "0", y: "-1.png", err: errNoTile},
+		{z: "46", x: "0", y: "0.png", err: errNoTile},
+		{z: "45", x: "35184372088832", y: "0.png", err: errNoTile},
+		{z: "a", x: "0", y: "0.png", bad: true},
+		{z: "0", x: "0", y: ".png", bad: true},
+		{z: "0", x: "0x0", y: "0.png", bad: true},
+		{z: "0", x: "0", y: "0.jpg", bad: true},
+		{z: "0", x: "0", y: "0", bad: true},
+	} {
+		z, x, y, err := parseTile(tc.z, tc.x, tc.y)
+		name := tc.z + "/" + tc.x + "/" + tc.y
+		switch {
+		case tc.bad:
+			if err == nil || errors.Is(err, errNoTile) {
+				t.Errorf("%s: got %v, want a malformed-tile error", name, err)
+			}
+		case tc.err != nil:
+			if !errors.Is(err, tc.err) {
+				t.Errorf("%s: got %v, want %v", name, err, tc.err)
+			}
+		case err != nil:
+			t.Errorf("%s: %v", name, err)
+		case [3]int{z, x, y} != tc.want:
+			t.Errorf("%s: got %d/%d/%d, want %v", name, z, x, y, tc.want)
+		}
+	}
+}
+
+func TestTileCache(t *testing.T) {
+	keys := func(c *tileCache) []string {
+		var ks []string
+		for e := c.ll.Front(); e != nil; e = e.Next() {
+			ks = append(ks, e.Value.(*tileEntry).key)
+		}
+		return ks
+	}
+	c := newTileCache(3)
+	for _, k := range []string{"a", "b", "c"} {
+		c.add(k, []byte(k))
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("a not cached")
+	}
+	c.add("d", []byte("d")) // evicts b, used least recently
+	if want := []string{"d", "a", "c"}; !slices.Equal(keys(c), want) {
+		t.Errorf("cache holds %v, want %v", keys(c), want)
+	}
+	if _, ok := c.get("b"); ok {
+		t.Error("b not evicted")
+	}
+	if len(c.items) != 3 {
+		t.Errorf("%d items indexed, want 3", len(c.items))
+	}
+
+	// adding a key again keeps the first entry and refreshes it
+	first, _ := c.get("c")
+	if got := c.add("c", []byte("other")); got != first {
+		t.Error("re-adding a key replaced its entry")
+	}
+	if want := []string{"c", "d", "a"}; !slices.Equal(keys(c), want) {
+		t.Errorf("cache holds %v, want %v", keys(c), want)
+	}
+
+	// the ETag follows the contents
+	if e1, e2 := c.add("x", []byte("same")), c.add("y", []byte("same")); e1.etag != e2.etag || e1.etag == first.etag {
+		t.Errorf("ETags %s, %s for the same bytes and %s for others", e1.etag, e2.etag, first.etag)
+	}
+
+	off := newTileCache(0)
+	if ent := off.add("a", []byte("a")); ent == nil || ent.etag == "" {
+		t.Error("uncached add returned no entry")
+	}
+	if _, ok := off.get("a"); ok {
+		t.Error("a cache of size 0 kept a tile")
+	}
+}
+
+func TestTileEndpoint(t *testing.T) {
+	s := testServer()
+	s.tiles = tileConfig{root: render.DefaultBounds, iters: 50, itersPerZoom: 10}
+	s.cache = newTileCache(8)
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+
+	get := func(path, etag string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest("GET", srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := get("/tiles/1/0/1.png", "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("status %s, type %q", resp.Status, resp.Header.Get("Content-Type"))
+	}
+	img, err := png.Decode(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != tileSize || b.Dy() != tileSize {
+		t.Errorf("tile is %v", b)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" || resp.Header.Get("Cache-Control") == "" {
+		t.Errorf("ETag %q, Cache-Control %q", etag, resp.Header.Get("Cache-Control"))
+	}
+	if _, ok := s.cache.get("1/0/1?palette=" + render.DefaultPalette + "&coloring=" + string(render.DefaultColoring) + "&iters=60"); !ok {
+		t.Errorf("tile not cached at 60 iterations: %v", s.cache.items)
+	}
+
+	// a revalidation with the ETag is a 304 with no body, a stale one
+	// gets the tile again, from the cache
+	resp = get("/tiles/1/0/1.png", etag)
+	if body, _ := io.ReadAll(resp.Body); resp.StatusCode != http.StatusNotModified || len(body) != 0 {
+		t.Errorf("If-None-Match: status %s, %d bytes", resp.Status, len(body))
+	}
+	if resp.Header.Get("ETag") != etag {
+		t.Errorf("304 ETag %q, want %q", resp.Header.Get("ETag"), etag)
+	}
+	resp = get("/tiles/1/0/1.png", `"stale"`)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") != etag {
+		t.Errorf("stale If-None-Match: status %s, ETag %q", resp.Status, resp.Header.Get("ETag"))
+	}
+	if n := s.cache.ll.Len(); n != 1 {
+		t.Errorf("%d tiles cached after three requests for one", n)
+	}
+
+	for path, want := range map[string]int{
+		"/tiles/1/2/0.png":                http.StatusNotFound,
+		"/tiles/-1/0/0.png":               http.StatusNotFound,
+		"/tiles/46/0/0.png":               http.StatusNotFound,
+		"/tiles/a/0/0.png":                http.StatusBadRequest,
+		"/tiles/0/0/0.jpg":                http.StatusBadRequest,
+		"/tiles/0/0/0.png?palette=nosuch": http.StatusBadRequest,
+	} {
+		if resp := get(path, ""); resp.StatusCode != want {
+			t.Errorf("%s: status %s, want %d", path, resp.Status, want)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/whalelogic/mandlebrot/anim"
+	"github.com/whalelogic/mandlebrot/fractal"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// timelineFile is an animate -timeline file: settings for the whole
+// animation and the keyframes it runs through.
+//
+//	{
+//	  "width": 1280, "height": 720, "fps": 30, "palette": "NebulaSpectre",
+//	  "keyframes": [
+//	    {"view": "default"},
+//	    {"seconds": 6, "ease": "smoothstep", "view": "seahorse", "palettePhase": 0.5}
+//	  ]
+//	}
+//
+// Settings the file leaves out keep the values of the matching flags.
+type timelineFile struct {
+	Width          int             `json:"width"`
+	Height         int             `json:"height"`
+	FPS            float64         `json:"fps"`
+	Palette        string          `json:"palette"`
+	Coloring       string          `json:"coloring"`
+	Iters          int             `json:"iters"`
+	ItersPerOctave int             `json:"itersPerOctave"`
+	Keyframes      []timelineFrame `json:"keyframes"`
+}
+
+// timelineFrame is one keyframe of a timeline file. Every keyframe after
+// the first says how long the segment leading up to it lasts, in frames
+// or in seconds at the file's fps, and may name the easing of that
+// segment. The rest are optional: a keyframe pins only the parameters it
+// gives, and takes the others from the keyframes either side of it in
+// proportion to time.
+type timelineFrame struct {
+	Frames  int     `json:"frames"`
+	Seconds float64 `json:"seconds"`
+	Ease    string  `json:"ease"`
+
+	// The camera: view sets both center and zoom, in any form -from
+	// takes, and center and zoom override it. Zoom is the magnification
+	// relative to the default view.
+	View     string      `json:"view"`
+	Center   *[2]float64 `json:"center"`
+	Zoom     *float64    `json:"zoom"`
+	Rotation *float64    `json:"rotation"`
+
+	Iters        *int        `json:"iters"`
+	PalettePhase *float64    `json:"palettePhase"`
+	Julia        *[2]float64 `json:"julia"` // Julia parameter; any keyframe with one makes every frame a Julia set
+}
+
+// timeline is a loaded timeline file, ready to give the render of each
+// frame.
+type timeline struct {
+	timelineFile
+	track  anim.Track
+	iters  []float64 // nil when no keyframe pins the iterations
+	phase  []float64
+	julia  []complex128 // nil when no keyframe pins a Julia parameter
+	frames int
+}
+
+// loadTimeline reads and checks the timeline file at path. defaults holds
+// the settings used where the file has none; its keyframes are ignored.
+func loadTimeline(path, bookmarks string, defaults timelineFile) (*timeline, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	f := defaults
+	f.Keyframes = nil
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("%w: timeline %s: %v", render.ErrInvalidOptions, path, err)
+	}
+	tl, err := newTimeline(f, bookmarks)
+	if err != nil {
+		return nil, fmt.Errorf("%w: timeline %s: %w", render.ErrInvalidOptions, path, err)
+	}
+	return tl, nil
+}
+
+// newTimeline checks f and resolves its keyframes into complete ones.
+func newTimeline(f timelineFile, bookmarks string) (*timeline, error) {
+	switch {
+	case f.Width < 1 || f.Height < 1:
+		return nil, fmt.Errorf("size %dx%d must be positive", f.Width, f.Height)
+	case !(f.FPS > 0) || math.IsInf(f.FPS, 0):
+		return nil, fmt.Errorf("fps %g must be positive", f.FPS)
+	case f.Iters < 1:
+		return nil, fmt.Errorf("iters %d must be positive", f.Iters)
+	case len(f.Keyframes) < 2:
+		return nil, fmt.Errorf("%d keyframes: need at least two", len(f.Keyframes))
+	}
+	n := len(f.Keyframes)
+	at := make([]int, n) // frame number of each keyframe
+	var re, im, logWidth, rot, iters, phase, kre, kim channel
+	for _, c := range []*channel{&re, &im, &logWidth, &rot, &iters, &phase, &kre, &kim} {
+		*c = newChannel(n)
+	}
+	defaultWidth := anim.ViewOf(render.DefaultBounds.FitToImage(f.Width, f.Height)).Width
+	var errs []error
+	for i, k := range f.Keyframes {
+		bad := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("keyframe %d: "+format, append([]any{i}, args...)...))
+		}
+		switch {
+		case i == 0 && (k.Frames != 0 || k.Seconds != 0 || k.Ease != ""):
+			bad("the first keyframe starts the animation and takes no frames, seconds or ease")
+		case i > 0 && k.Frames != 0 && k.Seconds != 0:
+			bad("give frames or seconds, not both")
+		case i > 0 && k.Frames > 0:
+			at[i] = at[i-1] + k.Frames
+		case i > 0 && k.Seconds > 0 && !math.IsInf(k.Seconds, 0):
+			at[i] = at[i-1] + max(int(math.Round(k.Seconds*f.FPS)), 1)
+		case i > 0:
+			bad("frames %d, seconds %g: the segment leading here needs a positive length", k.Frames, k.Seconds)
+		}
+		if k.Ease != "" {
+			if _, ok := anim.EasingByName(k.Ease); !ok {
+				bad("ease %q: not one of %s", k.Ease, strings.Join(anim.EasingNames, ", "))
+			}
+		}
+		if k.View != "" {
+			v, err := parseView(k.View, bookmarks, f.Width, f.Height)
+			if err != nil {
+				bad("%v", err)
+			} else {
+				re.pin(i, real(v.Center))
+				im.pin(i, imag(v.Center))
+				logWidth.pin(i, math.Log(v.Width))
+			}
+		}
+		if k.Center != nil {
+			re.pin(i, k.Center[0])
+			im.pin(i, k.Center[1])
+		}
+		if k.Zoom != nil {
+			if z := *k.Zoom; !(z > 0) || math.IsInf(z, 0) {
+				bad("zoom %g must be positive", z)
+			} else {
+				logWidth.pin(i, math.Log(defaultWidth/z))
+			}
+		}
+		if k.Rotation != nil {
+			rot.pin(i, *k.Rotation)
+		}
+		if k.Iters != nil {
+			if *k.Iters < 1 {
+				bad("iters %d must be positive", *k.Iters)
+			}
+			iters.pin(i, float64(*k.Iters))
+		}
+		if k.PalettePhase != nil {
+			phase.pin(i, *k.PalettePhase)
+		}
+		if k.Julia != nil {
+			kre.pin(i, k.Julia[0])
+			kim.pin(i, k.Julia[1])
+		}
+	}
+	if !re.any() {
+		errs = append(errs, errors.New("no keyframe gives a center or view"))
+	}
+	if !logWidth.any() {
+		errs = append(errs, errors.New("no keyframe gives a zoom or view"))
+	}
+	for _, c := range []*channel{&re, &im, &logWidth, &rot, &iters, &phase, &kre, &kim} {
+		if !c.finite() {
+			errs = append(errs, errors.New("values must be finite"))
+			break
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	tl := &timeline{timelineFile: f, frames: at[n-1] + 1}
+	for _, c := range []*channel{&re, &im, &logWidth, &rot, &iters, &phase, &kre, &kim} {
+		c.fill(at)
+	}
+	tl.track = make(anim.Track, n)
+	for i := range n {
+		tl.track[i] = anim.Keyframe{
+			Frame: at[i],
+			View: anim.View{
+				Center:   complex(re.vals[i], im.vals[i]),
+				Width:    math.Exp(logWidth.vals[i]),
+				Rotation: rot.vals[i],
+			},
+		}
+		if i+1 < n {
+			// a keyframe's ease shapes the segment leading up to it
+			tl.track[i].Ease, _ = anim.EasingByName(f.Keyframes[i+1].Ease)
+		}
+	}
+	if err := tl.track.Validate(); err != nil {
+		return nil, err
+	}
+	if iters.any() {
+		tl.iters = iters.vals
+	}
+	tl.phase = phase.vals
+	if kre.any() {
+		tl.julia = make([]complex128, n)
+		for i := range n {
+			tl.julia[i] = complex(kre.vals[i], kim.vals[i])
+		}
+	}
+	return tl, nil
+}
+
+// frame returns what to render for frame i.
+func (tl *timeline) frame(i int) animFrame {
+	v := tl.track.At(float64(i))
+	k, u := tl.track.Locate(float64(i))
+	lerp := func(vals []float64) float64 {
+		if k == len(vals)-1 {
+			return vals[k]
+		}
+		return anim.Lerp(vals[k], vals[k+1], u)
+	}
+	n := tl.Iters + int(math.Round(float64(tl.ItersPerOctave)*max(tl.track.Depth(v), 0)))
+	if tl.iters != nil {
+		n = max(int(math.Round(lerp(tl.iters))), 1)
+	}
+	phase := lerp(tl.phase)
+	f := animFrame{
+		vp:     v.Viewport(tl.Width, tl.Height),
+		opts:   []render.Option{render.WithIterations(n), render.WithPalettePhase(phase)},
+		detail: fmt.Sprintf("iters %d", n),
+	}
+	if phase != 0 {
+		f.detail += fmt.Sprintf("  phase %.3f", phase)
+	}
+	if tl.julia != nil {
+		c := tl.julia[k]
+		if k < len(tl.julia)-1 {
+			c += (tl.julia[k+1] - c) * complex(u, 0)
+		}
+		f.opts = append(f.opts, render.WithFractal(fractal.Julia{K: c}))
+		f.detail += fmt.Sprintf("  c %.6f%+.6fi", real(c), imag(c))
+		f.k = c
+	}
+	return f
+}
+
+// channel is one animated number across the keyframes of a timeline,
+// pinned at some of them.
+type channel struct {
+	vals   []float64
+	pinned []bool
+}
+
+func newChannel(n int) channel {
+	return channel{vals: make([]float64, n), pinned: make([]bool, n)}
+}
+
+func (c *channel) pin(i int, v float64) { c.vals[i], c.pinned[i] = v, true }
+
+// any reports whether any keyframe pins c.
+func (c *channel) any() bool {
+	for _, p := range c.pinned {
+		if p {
+			return true
+		}
+	}
+	return false
+}
+
+// finite reports whether every pinned value is finite.
+func (c *channel) finite() bool {
+	for i, v := range c.vals {
+		if c.pinned[i] && (math.IsNaN(v) || math.IsInf(v, 0)) {
+			return false
+		}
+	}
+	return true
+}
+
+// fill gives the keyframes that don't pin c a value: interpolated in time
+// between the pinned keyframes either side, or the nearest pinned value
+// before the first and after the last. A channel nobody pins stays 0.
+func (c *channel) fill(at []int) {
+	prev := -1
+	for i := range c.vals {
+		if !c.pinned[i] {
+			continue
+		}
+		if prev < 0 {
+			for j := range i {
+				c.vals[j] = c.vals[i]
+			}
+		} else {
+			for j := prev + 1; j < i; j++ {
+				u := float64(at[j]-at[prev]) / float64(at[i]-at[prev])
+				c.vals[j] = anim.Lerp(c.vals[prev], c.vals[i], u)
+			}
+		}
+		prev = i
+	}
+	if prev >= 0 {
+		for j := prev + 1; j < len(c.vals); j++ {
+			c.vals[j] = c.vals[prev]
+		}
+	}
+}
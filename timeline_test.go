@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/fractal"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// testTimelineDefaults are the flag defaults animate hands loadTimeline.
+var testTimelineDefaults = timelineFile{
+	Width: 40, Height: 30, FPS: 30,
+	Palette: render.DefaultPalette, Coloring: string(render.DefaultColoring),
+	Iters: 100, ItersPerOctave: 50,
+}
+
+// ptr returns a pointer to v, for the optional fields of a keyframe.
+func ptr[T any](v T) *T { return &v }
+
+// frameOptions returns the options frame f of tl renders with.
+func frameOptions(t *testing.T, tl *timeline, f animFrame) render.Options {
+	t.Helper()
+	o, err := render.New(append([]render.Option{
+		render.WithSize(tl.Width, tl.Height),
+		render.WithViewport(f.vp.Bounds),
+		render.WithRotation(f.vp.Rotation),
+	}, f.opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func TestTimelineExample(t *testing.T) {
+	tl, err := loadTimeline("examples/timeline.json", "", testTimelineDefaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 4, 3, 3 and 4 seconds at 30 fps, and the first frame
+	if tl.frames != 421 || tl.Width != 1280 || tl.Height != 720 {
+		t.Fatalf("%d frames of %dx%d, want 421 of 1280x720", tl.frames, tl.Width, tl.Height)
+	}
+	home, err := parseView("default", "", tl.Width, tl.Height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seahorse := namedViews["seahorse"].FitToImage(tl.Width, tl.Height)
+	deep := complex(-0.743643887037151, 0.13182590420533)
+	for _, tc := range []struct {
+		frame    int
+		center   complex128
+		width    float64
+		rotation float64
+		phase    float64
+		iters    int
+	}{
+		{0, home.Center, home.Width, 0, 0, 300},
+		// the rotation is pinned only at the first keyframe and the last,
+		// so turns from 0 to 180 over all 420 frames, and the palette
+		// phase only at the first and the fourth, so runs from 0 to 0.5
+		// over their 300
+		{120, seahorse.Center(), seahorse.Width(), 180 * 120 / 420.0, 0.2, 0},
+		{210, deep, home.Width / 1000, 90, 0.35, 300 + int(math.Round(60*math.Log2(1000)))},
+		{300, deep, home.Width / 10000, 180 * 300 / 420.0, 0.5, 0},
+		{420, deep, home.Width / 100000, 180, 0.5, 300 + int(math.Round(60*math.Log2(100000)))},
+	} {
+		f := tl.frame(tc.frame)
+		o := frameOptions(t, tl, f)
+		if got := o.Bounds.Center(); cmplx.Abs(got-tc.center) > 1e-9*tc.width {
+			t.Errorf("frame %d: center %v, want %v", tc.frame, got, tc.center)
+		}
+		if got := o.Bounds.Width(); math.Abs(got-tc.width) > 1e-9*tc.width {
+			t.Errorf("frame %d: width %g, want %g", tc.frame, got, tc.width)
+		}
+		if math.Abs(o.Rotation-tc.rotation) > 1e-9 {
+			t.Errorf("frame %d: rotation %g, want %g", tc.frame, o.Rotation, tc.rotation)
+		}
+		if math.Abs(o.PalettePhase-tc.phase) > 1e-12 {
+			t.Errorf("frame %d: palette phase %g, want %g", tc.frame, o.PalettePhase, tc.phase)
+		}
+		if tc.iters != 0 && o.MaxIter != tc.iters {
+			t.Errorf("frame %d: %d iterations, want %d", tc.frame, o.MaxIter, tc.iters)
+		}
+		if _, ok := o.Fractal.(fractal.Julia); ok {
+			t.Errorf("frame %d: fractal %v, want the Mandelbrot set", tc.frame, o.Fractal)
+		}
+	}
+}
+
+func TestTimelineJulia(t *testing.T) {
+	// Julia parameters and iterations pinned at some keyframes only: the
+	// keyframes between take them in proportion to time, and the frames
+	// render as the options say.
+	f := testTimelineDefaults
+	f.Keyframes = []timelineFrame{
+		{View: "julia", Julia: &[2]float64{-0.8, 0.156}, Iters: ptr(100)},
+		{Frames: 10, Rotation: ptr(90.0)},
+		{Frames: 30, Ease: "smoothstep", Julia: &[2]float64{0.285, 0.01}, Iters: ptr(500)},
+	}
+	tl, err := newTimeline(f, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tl.frames != 41 {
+		t.Fatalf("%d frames, want 41", tl.frames)
+	}
+	for _, tc := range []struct {
+		frame int
+		k     complex128
+		iters int
+	}{
+		{0, -0.8 + 0.156i, 100},
+		{10, -0.8 + 0.156i + (0.285+0.01i-(-0.8+0.156i))/4, 200},
+		{40, 0.285 + 0.01i, 500},
+	} {
+		fr := tl.frame(tc.frame)
+		if cmplx.Abs(fr.k-tc.k) > 1e-12 {
+			t.Errorf("frame %d: c %v, want %v", tc.frame, fr.k, tc.k)
+		}
+		o := frameOptions(t, tl, fr)
+		if j, ok := o.Fractal.(fractal.Julia); !ok || cmplx.Abs(j.K-tc.k) > 1e-12 {
+			t.Errorf("frame %d: fractal %v, want the Julia set of %v", tc.frame, o.Fractal, tc.k)
+		}
+		if o.MaxIter != tc.iters {
+			t.Errorf("frame %d: %d iterations, want %d", tc.frame, o.MaxIter, tc.iters)
+		}
+	}
+
+	// a frame rendered the way animate does matches a plain render of
+	// the same options
+	base, err := render.New(render.WithSize(tl.Width, tl.Height), render.WithIterations(tl.Iters))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := render.NewRenderer(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	fr := tl.frame(25)
+	got, err := r.Render(context.Background(), fr.vp, fr.opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := render.Render(context.Background(), frameOptions(t, tl, fr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Image.Pix) != string(want.Image.Pix) {
+		t.Error("timeline frame differs from a render of its options")
+	}
+}
+
+func TestTimelineInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		keyframes []timelineFrame
+		want      string
+	}{
+		{"one keyframe", []timelineFrame{{View: "default"}}, "need at least two"},
+		{"first with frames", []timelineFrame{{View: "default", Frames: 5}, {Frames: 5}}, "takes no frames"},
+		{"frames and seconds", []timelineFrame{{View: "default"}, {Frames: 5, Seconds: 1}}, "not both"},
+		{"no length", []timelineFrame{{View: "default"}, {View: "seahorse"}}, "positive length"},
+		{"negative length", []timelineFrame{{View: "default"}, {Frames: -3}}, "positive length"},
+		{"bad ease", []timelineFrame{{View: "default"}, {Frames: 5, Ease: "bounce"}}, `ease "bounce"`},
+		{"bad zoom", []timelineFrame{{View: "default"}, {Frames: 5, Zoom: ptr(0.0)}}, "zoom 0"},
+		{"bad view", []timelineFrame{{View: "nowhere"}, {Frames: 5}}, `view "nowhere"`},
+		{"no center", []timelineFrame{{Zoom: ptr(1.0)}, {Frames: 5}}, "no keyframe gives a center"},
+		{"no zoom", []timelineFrame{{Center: &[2]float64{0, 0}}, {Frames: 5}}, "no keyframe gives a zoom"},
+		{"infinite", []timelineFrame{{View: "default", Center: &[2]float64{math.Inf(1), 0}}, {Frames: 5}}, "finite"},
+	} {
+		f := testTimelineDefaults
+		f.Keyframes = tc.keyframes
+		if _, err := newTimeline(f, ""); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got %v, want an error mentioning %q", tc.name, err, tc.want)
+		}
+	}
+
+	// loadTimeline rejects fields it doesn't know, as a misspelling
+	path := filepath.Join(t.TempDir(), "timeline.json")
+	if err := os.WriteFile(path, []byte(`{"keyframes": [{"view": "default"}, {"frames": 5, "zom": 2}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadTimeline(path, "", testTimelineDefaults); !errors.Is(err, render.ErrInvalidOptions) || !strings.Contains(err.Error(), "zom") {
+		t.Errorf("got %v, want ErrInvalidOptions naming the unknown field", err)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// contentTypes maps render.Formats to their MIME types.
+var contentTypes = map[string]string{
+	"png":  "image/png",
+	"jpeg": "image/jpeg",
+}
+
+// uploadFile PUTs the file at path to url, such as a pre-signed S3 URL,
+// streaming it from disk with an explicit Content-Length (pre-signed PUTs
+// reject chunked bodies). Any non-2xx response is an error that includes
+// the start of the response body.
+func uploadFile(ctx context.Context, url, path, contentType string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("upload: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// uploadTemp uploads path, a file alone in a temporary directory, with
+// uploadFile and then removes the directory. If the upload fails the file
+// is kept for debugging.
+func uploadTemp(ctx context.Context, url, path, contentType string) error {
+	if err := uploadFile(ctx, url, path, contentType); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Dir(path))
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// tempPNG renders a small PNG into a fresh temporary directory, as the
+// CLI does before an upload, and returns its path.
+func tempPNG(t *testing.T) string {
+	t.Helper()
+	opts, err := render.New(render.WithSize(32, 24), render.WithIterations(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := render.Render(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := os.MkdirTemp(t.TempDir(), "mandelbrot-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "mandelbrot.png")
+	var buf bytes.Buffer
+	if err := render.Encode(&buf, res.Image, "png"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUploadTemp(t *testing.T) {
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Content-Type") != "image/png" {
+			http.Error(w, "want a PUT of image/png", http.StatusBadRequest)
+			return
+		}
+		if len(r.TransferEncoding) > 0 {
+			http.Error(w, "chunked upload", http.StatusNotImplemented)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	path := tempPNG(t)
+	if err := uploadTemp(context.Background(), srv.URL+"/bucket/key?X-Amz-Signature=abc", path, "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(bytes.NewReader(body)); err != nil {
+		t.Errorf("uploaded body isn't a PNG: %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("temporary directory still there after the upload: %v", err)
+	}
+}
+
+func TestUploadTempFailureKeepsFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	path := tempPNG(t)
+	err := uploadTemp(context.Background(), srv.URL, path, "image/png")
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("got %v, want the status and body of the response", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("image not kept after a failed upload: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/output"
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+// watchInterval is how often -watch checks the config file.
+const watchInterval = 500 * time.Millisecond
+
+// watchAndRender renders the options in the JSON file cfgPath (the form
+// of render.Options.MarshalJSON) to outPath, then polls the file's
+// modification time and size and renders again whenever they change,
+// until ctx ends. outPath may hold the -outfile verbs, expanded for each
+// render. A config that doesn't load or render is reported and skipped,
+// so saving a half-edited file doesn't end the watch; the output keeps
+// the last good render. Each frame goes through r, whose worker count
+// stays as it was created.
+func watchAndRender(ctx context.Context, cfgPath, outPath string, r *render.Renderer) error {
+	var last os.FileInfo
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		info, err := os.Stat(cfgPath)
+		switch {
+		case err != nil:
+			if last != nil || !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "watch: %v\n", err)
+			}
+			last = nil
+		case last == nil || !info.ModTime().Equal(last.ModTime()) || info.Size() != last.Size():
+			last = info
+			if err := renderConfig(ctx, cfgPath, outPath, r); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				fmt.Fprintf(os.Stderr, "watch: %s: %v\n", cfgPath, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// renderConfig renders the options in cfgPath once for watchAndRender.
+func renderConfig(ctx context.Context, cfgPath, outPath string, r *render.Renderer) error {
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return err
+	}
+	var cfg render.Options
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return err
+	}
+	path := output.ExpandFilenameTemplate(outPath, cfg)
+	format, err := render.FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	// every setting comes from the file; the Renderer only supplies its
+	// workers
+	res, err := r.Render(ctx, cfg.Viewport(), func(o *render.Options) error {
+		procs := o.Procs
+		*o = cfg
+		o.Procs = procs
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeFrame(ctx, path, res, format); err != nil {
+		return err
+	}
+	fmt.Printf("%s  rendered %s (%dx%d) in %v\n", time.Now().Format(time.TimeOnly), path, cfg.Width, cfg.Height, res.Stats.Elapsed.Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/whalelogic/mandlebrot/render"
+)
+
+func TestWatchAndRender(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath, outPath := filepath.Join(dir, "view.json"), filepath.Join(dir, "out.png")
+	writeConfig := func(s string) {
+		t.Helper()
+		if err := os.WriteFile(cfgPath, []byte(s), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// waitFor polls until the output differs from prev, for up to 2s
+	waitFor := func(prev []byte) []byte {
+		t.Helper()
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+			if b, err := os.ReadFile(outPath); err == nil && len(b) > 0 && !bytes.Equal(b, prev) {
+				return b
+			}
+		}
+		t.Fatal("the output was not updated within 2s")
+		return nil
+	}
+	writeConfig(`{"width": 32, "height": 24, "maxIter": 100, "palette": "ThermalHeat"}`)
+
+	opts, err := render.New(render.WithProcs(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := render.NewRenderer(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watchAndRender(ctx, cfgPath, outPath, r) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}()
+
+	first := waitFor(nil)
+	info, err := os.Stat(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(`{"width": 32, "height": 24, "maxIter": 100, "palette": "MonochromeSlate"}`)
+	second := waitFor(first)
+	if after, err := os.Stat(outPath); err != nil || !after.ModTime().After(info.ModTime()) {
+		t.Errorf("modification time %v, then %v (%v)", info.ModTime(), after.ModTime(), err)
+	}
+
+	// a broken config leaves the last good image in place
+	writeConfig(`{"width": 32, "height": `)
+	time.Sleep(3 * watchInterval)
+	if b, err := os.ReadFile(outPath); err != nil || !bytes.Equal(b, second) {
+		t.Fatalf("a broken config replaced the output (%v)", err)
+	}
+	writeConfig(`{"width": 32, "height": 24, "maxIter": 50, "palette": "ThermalHeat"}`)
+	waitFor(second)
+}